@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"log"
 	"log/slog"
@@ -14,15 +15,24 @@ import (
 )
 
 func main() {
+	// Usage: mcp-server [flags] [repl | replay <file>]
 	// CLI flags (override config file and env vars)
 	transport := flag.String("transport", "", "Transport type: stdio (default) or http")
 	port := flag.Int("port", 0, "HTTP port (overrides config)")
 	configPath := flag.String("config", "config.yaml", "Path to configuration file")
 	logLevel := flag.String("log-level", "info", "Log level: debug, info, warn, error")
+	replayWrites := flag.Bool("replay-writes", false, "replay: also call tools that change data")
 	flag.Parse()
+	command := flag.Arg(0)
+	switch {
+	case command == "replay" && flag.NArg() != 2:
+		log.Fatalf("Usage: mcp-server [flags] replay <file>")
+	case command != "" && command != "repl" && command != "replay":
+		log.Fatalf("Unknown command %q, the commands are repl and replay", command)
+	}
 
 	// Setup logger
-	logger := setupLogger(*logLevel)
+	logger := setupLogger(*logLevel, nil)
 
 	// Check if config file exists
 	configFileExists := false
@@ -39,7 +49,7 @@ func main() {
 		log.Printf("Config file not found, using environment variables and defaults")
 	}
 
-	config, err := fireflyMCP.LoadConfig(*configPath)
+	config, err := fireflyMCP.LoadConfigWithoutValidation(*configPath)
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
@@ -52,6 +62,11 @@ func main() {
 		config.HTTP.Port = *port
 	}
 
+	// A replay must not record itself
+	if command == "replay" {
+		config.Replay.Record = ""
+	}
+
 	// Validate config after CLI flags are applied
 	if err := fireflyMCP.ValidateConfig(config); err != nil {
 		log.Fatalf("Invalid configuration: %v", err)
@@ -59,11 +74,51 @@ func main() {
 
 	log.Printf("Configuration loaded successfully")
 
+	// Recreate the logger now that the redacted fields are known
+	logger = setupLogger(*logLevel, fireflyMCP.NewRedactor(config.Logging.RedactFields))
+	slog.SetDefault(logger)
+	logger.Info("effective configuration", "config", config)
+
+	// Verify that server.url points at the Firefly III API
+	if config.Server.CheckOnStartup {
+		checkServerURL(config, logger)
+	}
+
 	// Create MCP server
 	server, err := fireflyMCP.NewFireflyMCPServer(config)
 	if err != nil {
 		log.Fatalf("Failed to create MCP server: %v", err)
 	}
+	defer server.Close()
+	if plugins := fireflyMCP.RegisteredPlugins(); len(plugins) > 0 {
+		logger.Info("plugins loaded", "plugins", plugins)
+	}
+	// Mark the tools of optional APIs the instance lacks as unavailable up front;
+	// otherwise they are found on first use
+	if config.Server.CheckOnStartup {
+		if missing := server.ProbeCapabilities(context.Background()); len(missing) > 0 {
+			logger.Warn("optional Firefly III APIs unavailable", "capabilities", missing)
+		}
+	}
+
+	switch command {
+	case "repl":
+		runREPL(server)
+		return
+	case "replay":
+		runReplay(server, flag.Arg(1), *replayWrites)
+		return
+	}
+
+	// Prefetch common data so the first queries do not wait for cold caches
+	if config.Warmup.Enabled {
+		go server.Warmup(context.Background())
+	}
+
+	// Start the control API on its own port
+	if config.Admin.Enabled {
+		go runAdminServer(server, config, *configPath, logger)
+	}
 
 	// Run based on transport type
 	if config.HTTP.Enabled {
@@ -73,7 +128,21 @@ func main() {
 	}
 }
 
-func setupLogger(level string) *slog.Logger {
+func checkServerURL(config *fireflyMCP.Config, logger *slog.Logger) {
+	info, err := fireflyMCP.CheckServerURL(context.Background(), config)
+	switch {
+	case errors.Is(err, fireflyMCP.ErrServerUnreachable):
+		logger.Warn("Firefly III server check failed", "error", err)
+	case err != nil:
+		log.Fatalf("Invalid server URL: %v", err)
+	case info != nil:
+		logger.Info("connected to Firefly III", "version", info.Version, "api_version", info.ApiVersion)
+	default:
+		logger.Info("Firefly III API reachable", "url", config.Server.URL)
+	}
+}
+
+func setupLogger(level string, redactor *fireflyMCP.Redactor) *slog.Logger {
 	var logLevel slog.Level
 	switch level {
 	case "debug":
@@ -87,6 +156,9 @@ func setupLogger(level string) *slog.Logger {
 	}
 
 	opts := &slog.HandlerOptions{Level: logLevel}
+	if redactor != nil {
+		opts.ReplaceAttr = redactor.ReplaceAttr
+	}
 	handler := slog.NewJSONHandler(os.Stderr, opts)
 	return slog.New(handler)
 }
@@ -97,6 +169,41 @@ func runStdioServer(server *fireflyMCP.FireflyMCPServer) {
 	}
 }
 
+func runREPL(server *fireflyMCP.FireflyMCPServer) {
+	if err := fireflyMCP.RunREPL(context.Background(), server, os.Stdin, os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// runReplay calls the tools recorded in a replay file again and exits with
+// status 1 when a result differs, so it can drive git bisect run
+func runReplay(server *fireflyMCP.FireflyMCPServer, path string, writes bool) {
+	file, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("Failed to open replay file: %v", err)
+	}
+	calls, err := fireflyMCP.ReadReplayFile(file)
+	file.Close()
+	if err != nil {
+		log.Fatalf("Failed to read replay file: %v", err)
+	}
+	summary, err := fireflyMCP.RunReplay(context.Background(), server, calls, fireflyMCP.ReplayOptions{Writes: writes}, os.Stdout)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if summary.Differ > 0 {
+		server.Close()
+		os.Exit(1)
+	}
+}
+
+func runAdminServer(server *fireflyMCP.FireflyMCPServer, config *fireflyMCP.Config, configPath string, logger *slog.Logger) {
+	adminServer := fireflyMCP.NewAdminServer(server, config, configPath, logger)
+	if err := adminServer.Start(context.Background()); err != nil {
+		logger.Error("admin API stopped", "error", err)
+	}
+}
+
 func runHTTPServer(server *fireflyMCP.FireflyMCPServer, config *fireflyMCP.Config, logger *slog.Logger) {
 	// Create HTTP server
 	httpServer := fireflyMCP.NewHTTPServer(server, config, logger)
@@ -0,0 +1,196 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dezer32/mcp-firefly-iii/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Contract tests: each case serves a response in the shape Firefly III sends
+// and checks that the generated client calls the expected endpoint and decodes
+// the fields the MCP server relies on. They run after every regeneration.
+
+func TestSpecVersion(t *testing.T) {
+	spec, err := client.GetSwagger()
+	require.NoError(t, err)
+	assert.Equal(t, client.SpecVersion, spec.Info.Version, "update SpecVersion together with the spec in generate.go")
+}
+
+type contract struct {
+	name        string
+	method      string
+	path        string
+	contentType string
+	body        string
+	call        func(t *testing.T, c *client.ClientWithResponses)
+}
+
+func TestClientContracts(t *testing.T) {
+	ctx := context.Background()
+	contracts := []contract{
+		{
+			name: "list accounts", method: http.MethodGet, path: "/v1/accounts",
+			body: `{"data":[{"type":"accounts","id":"1","attributes":{"name":"Girokonto","type":"asset","active":true,` +
+				`"currency_code":"EUR","current_balance":"1234.56","notes":null}}],` +
+				`"meta":{"pagination":{"total":1,"count":1,"per_page":50,"current_page":1,"total_pages":1}}}`,
+			call: func(t *testing.T, c *client.ClientWithResponses) {
+				resp, err := c.ListAccountWithResponse(ctx, &client.ListAccountParams{})
+				require.NoError(t, err)
+				require.NotNil(t, resp.ApplicationvndApiJSON200)
+				account := resp.ApplicationvndApiJSON200.Data[0]
+				assert.Equal(t, "1", account.Id)
+				assert.Equal(t, "Girokonto", account.Attributes.Name)
+				assert.Equal(t, client.ShortAccountTypePropertyAsset, account.Attributes.Type)
+				assert.Equal(t, "EUR", *account.Attributes.CurrencyCode)
+				assert.Nil(t, account.Attributes.Notes)
+				assert.Equal(t, 1, *resp.ApplicationvndApiJSON200.Meta.Pagination.Total)
+			},
+		},
+		{
+			name: "get account", method: http.MethodGet, path: "/v1/accounts/7",
+			body: `{"data":{"type":"accounts","id":"7","attributes":{"name":"Shop","type":"expense"}}}`,
+			call: func(t *testing.T, c *client.ClientWithResponses) {
+				resp, err := c.GetAccountWithResponse(ctx, "7", &client.GetAccountParams{})
+				require.NoError(t, err)
+				require.NotNil(t, resp.ApplicationvndApiJSON200)
+				assert.Equal(t, client.ShortAccountTypePropertyExpense, resp.ApplicationvndApiJSON200.Data.Attributes.Type)
+			},
+		},
+		{
+			name: "list transactions", method: http.MethodGet, path: "/v1/transactions",
+			body: `{"data":[{"type":"transactions","id":"40","attributes":{"group_title":null,"transactions":[{` +
+				`"transaction_journal_id":"41","type":"withdrawal","date":"2024-03-01T00:00:00+01:00","amount":"12.50",` +
+				`"currency_code":"EUR","foreign_amount":"13.60","foreign_currency_code":"USD","description":"Bäckerei",` +
+				`"source_id":"1","source_name":"Girokonto","destination_id":"7","destination_name":"Shop",` +
+				`"category_name":"Food","budget_id":null,"tags":["frühstück"]}]}}],` +
+				`"meta":{"pagination":{"total":1,"count":1,"per_page":50,"current_page":1,"total_pages":1}}}`,
+			call: func(t *testing.T, c *client.ClientWithResponses) {
+				resp, err := c.ListTransactionWithResponse(ctx, &client.ListTransactionParams{})
+				require.NoError(t, err)
+				require.NotNil(t, resp.ApplicationvndApiJSON200)
+				split := resp.ApplicationvndApiJSON200.Data[0].Attributes.Transactions[0]
+				assert.Equal(t, "41", *split.TransactionJournalId)
+				assert.Equal(t, client.Withdrawal, split.Type)
+				assert.Equal(t, "12.50", split.Amount)
+				assert.Equal(t, "13.60", *split.ForeignAmount)
+				assert.Equal(t, "Bäckerei", split.Description)
+				assert.Equal(t, "7", *split.DestinationId)
+				assert.Nil(t, split.BudgetId)
+				assert.Equal(t, []string{"frühstück"}, *split.Tags)
+			},
+		},
+		{
+			name: "store transaction", method: http.MethodPost, path: "/v1/transactions",
+			body: `{"data":{"type":"transactions","id":"40","attributes":{"transactions":[{"transaction_journal_id":"41",` +
+				`"type":"deposit","date":"2024-03-01T00:00:00+00:00","amount":"2500.00","description":"Salary"}]}}}`,
+			call: func(t *testing.T, c *client.ClientWithResponses) {
+				sourceName := "Employer"
+				resp, err := c.StoreTransactionWithResponse(ctx, &client.StoreTransactionParams{}, client.TransactionStore{
+					Transactions: []client.TransactionSplitStore{{
+						Type: client.Deposit, Amount: "2500.00", Description: "Salary", SourceName: &sourceName,
+					}},
+				})
+				require.NoError(t, err)
+				require.NotNil(t, resp.ApplicationvndApiJSON200)
+				assert.Equal(t, "40", resp.ApplicationvndApiJSON200.Data.Id)
+			},
+		},
+		{
+			name: "list budget limits", method: http.MethodGet, path: "/v1/budget-limits",
+			body: `{"data":[{"type":"budget_limits","id":"5","attributes":{"budget_id":"2","amount":"400.00",` +
+				`"start":"2024-03-01T00:00:00+00:00","end":"2024-03-31T23:59:59+00:00","currency_code":"EUR",` +
+				`"spent":"-120.50"}}],` +
+				`"meta":{"pagination":{"total":1,"count":1,"per_page":50,"current_page":1,"total_pages":1}}}`,
+			call: func(t *testing.T, c *client.ClientWithResponses) {
+				resp, err := c.ListBudgetLimitWithResponse(ctx, &client.ListBudgetLimitParams{})
+				require.NoError(t, err)
+				require.NotNil(t, resp.ApplicationvndApiJSON200)
+				limit := resp.ApplicationvndApiJSON200.Data[0].Attributes
+				assert.Equal(t, "400.00", limit.Amount)
+				assert.Equal(t, "2", *limit.BudgetId)
+				assert.Equal(t, "-120.50", *limit.Spent)
+			},
+		},
+		{
+			name: "expense insight by category", method: http.MethodGet, path: "/v1/insight/expense/category",
+			contentType: "application/json",
+			body:        `[{"id":"3","name":"Food","difference":"-120.50","difference_float":-120.5,"currency_id":"1","currency_code":"EUR"}]`,
+			call: func(t *testing.T, c *client.ClientWithResponses) {
+				resp, err := c.InsightExpenseCategoryWithResponse(ctx, &client.InsightExpenseCategoryParams{})
+				require.NoError(t, err)
+				require.NotNil(t, resp.JSON200)
+				entry := (*resp.JSON200)[0]
+				assert.Equal(t, "Food", *entry.Name)
+				assert.Equal(t, "-120.50", *entry.Difference)
+				assert.Equal(t, "EUR", *entry.CurrencyCode)
+			},
+		},
+		{
+			name: "autocomplete accounts", method: http.MethodGet, path: "/v1/autocomplete/accounts",
+			contentType: "application/json",
+			body: `[{"id":"1","name":"Girokonto","name_with_balance":"Girokonto (€1,234.56)","type":"Asset account",` +
+				`"currency_id":"1","currency_name":"Euro","currency_code":"EUR","currency_symbol":"€","currency_decimal_places":2}]`,
+			call: func(t *testing.T, c *client.ClientWithResponses) {
+				resp, err := c.GetAccountsACWithResponse(ctx, &client.GetAccountsACParams{})
+				require.NoError(t, err)
+				require.NotNil(t, resp.JSON200)
+				assert.Equal(t, "Girokonto", (*resp.JSON200)[0].Name)
+				assert.Equal(t, "EUR", (*resp.JSON200)[0].CurrencyCode)
+			},
+		},
+		{
+			name: "basic summary", method: http.MethodGet, path: "/v1/summary/basic",
+			body: `{"balance-in-EUR":{"key":"balance-in-EUR","title":"Balance (€)","monetary_value":"1234.56","currency_code":"EUR"}}`,
+			call: func(t *testing.T, c *client.ClientWithResponses) {
+				resp, err := c.GetBasicSummaryWithResponse(ctx, &client.GetBasicSummaryParams{})
+				require.NoError(t, err)
+				require.NotNil(t, resp.ApplicationvndApiJSON200)
+				entry := (*resp.ApplicationvndApiJSON200)["balance-in-EUR"]
+				assert.Equal(t, "1234.56", *entry.MonetaryValue)
+				assert.Equal(t, "EUR", *entry.CurrencyCode)
+			},
+		},
+		{
+			name: "about", method: http.MethodGet, path: "/v1/about",
+			contentType: "application/json",
+			body:        `{"data":{"version":"6.2.21","api_version":"6.2.21","php_version":"8.3.0","os":"Linux","driver":"pgsql"}}`,
+			call: func(t *testing.T, c *client.ClientWithResponses) {
+				resp, err := c.GetAboutWithResponse(ctx, &client.GetAboutParams{})
+				require.NoError(t, err)
+				require.NotNil(t, resp.JSON200)
+				assert.Equal(t, "6.2.21", *resp.JSON200.Data.Version)
+			},
+		},
+	}
+
+	for _, tt := range contracts {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, tt.method, r.Method)
+				assert.Equal(t, tt.path, r.URL.Path)
+				if r.Method == http.MethodPost {
+					body, _ := io.ReadAll(r.Body)
+					assert.True(t, json.Valid(body), "request body must be JSON")
+				}
+				contentType := tt.contentType
+				if contentType == "" {
+					contentType = "application/vnd.api+json"
+				}
+				w.Header().Set("Content-Type", contentType)
+				w.Write([]byte(tt.body))
+			}))
+			defer ts.Close()
+
+			c, err := client.NewClientWithResponses(ts.URL)
+			require.NoError(t, err)
+			tt.call(t, c)
+		})
+	}
+}
@@ -1,6 +1,15 @@
-//go:generate go run github.com/oapi-codegen/oapi-codegen/v2/cmd/oapi-codegen -generate client,models,embedded-spec -package client -o client.go ../../resources/firefly-iii-6.2.21-v1.yaml
+//go:generate go run github.com/oapi-codegen/oapi-codegen/v2/cmd/oapi-codegen@v2.5.0 -generate client,models,embedded-spec -package client -o client.go ../../resources/firefly-iii-6.2.21-v1.yaml
 
 package client
 
 // This file contains the go:generate directive for regenerating the Firefly III client code.
-// To regenerate the client, run: go generate ./pkg/client
+// To regenerate the client, run: make client
+//
+// To track a new Firefly III release, add its OpenAPI spec to resources/,
+// point the directive above and SpecVersion at it and run make client. It
+// regenerates client.go and runs the compatibility suite: the build, the
+// mapper tests of pkg/fireflyMCP and the contract tests in this package, which
+// decode recorded Firefly III responses with the generated client.
+
+// SpecVersion is the Firefly III API version client.go is generated from
+const SpecVersion = "v6.2.21"
@@ -0,0 +1,95 @@
+package fireflyMCP
+
+import "strings"
+
+// normalizeAliasKey normalizes an alias for case- and whitespace-insensitive lookup
+func normalizeAliasKey(alias string) string {
+	return strings.Join(strings.Fields(strings.ToLower(alias)), " ")
+}
+
+// normalizeAccountAliases builds the alias lookup table from the configured aliases.
+// Entries with an empty alias or account ID are ignored.
+func normalizeAccountAliases(aliases map[string]string) map[string]string {
+	normalized := make(map[string]string, len(aliases))
+	for alias, id := range aliases {
+		key := normalizeAliasKey(alias)
+		id = strings.TrimSpace(id)
+		if key == "" || id == "" {
+			continue
+		}
+		normalized[key] = id
+	}
+	return normalized
+}
+
+// currentAccountAliases returns the alias lookup table in effect
+func (s *FireflyMCPServer) currentAccountAliases() map[string]string {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+	return s.accountAliases
+}
+
+// lookupAccountAlias returns the account ID configured for the given alias
+func (s *FireflyMCPServer) lookupAccountAlias(ref string) (string, bool) {
+	aliases := s.currentAccountAliases()
+	if len(aliases) == 0 {
+		return "", false
+	}
+	id, ok := aliases[normalizeAliasKey(ref)]
+	return id, ok
+}
+
+// resolveAccountRef returns the account ID for ref if it is a configured alias,
+// otherwise ref is returned unchanged.
+func (s *FireflyMCPServer) resolveAccountRef(ref string) string {
+	if id, ok := s.lookupAccountAlias(ref); ok {
+		return id
+	}
+	return ref
+}
+
+// resolveAccountRefs applies resolveAccountRef to every element of refs
+func (s *FireflyMCPServer) resolveAccountRefs(refs []string) []string {
+	if len(refs) == 0 {
+		return refs
+	}
+	resolved := make([]string, len(refs))
+	for i, ref := range refs {
+		resolved[i] = s.resolveAccountRef(ref)
+	}
+	return resolved
+}
+
+// applyAccountAliases returns a copy of the splits where source/destination
+// references matching a configured alias are replaced by the aliased account ID.
+// An alias given as a name is moved to the corresponding ID field so Firefly III
+// never tries to match (or create) an account by the alias text.
+func (s *FireflyMCPServer) applyAccountAliases(splits []TransactionSplitRequest) []TransactionSplitRequest {
+	if len(splits) == 0 || len(s.currentAccountAliases()) == 0 {
+		return splits
+	}
+
+	resolved := make([]TransactionSplitRequest, len(splits))
+	for i, split := range splits {
+		split.SourceId, split.SourceName = s.resolveAccountPair(split.SourceId, split.SourceName)
+		split.DestinationId, split.DestinationName = s.resolveAccountPair(split.DestinationId, split.DestinationName)
+		resolved[i] = split
+	}
+	return resolved
+}
+
+// resolveAccountPair resolves an (id, name) account reference pair against the alias table
+func (s *FireflyMCPServer) resolveAccountPair(id, name *string) (*string, *string) {
+	if id != nil {
+		if aliasID, ok := s.lookupAccountAlias(*id); ok {
+			return &aliasID, name
+		}
+		return id, name
+	}
+	if name != nil {
+		if aliasID, ok := s.lookupAccountAlias(*name); ok {
+			return &aliasID, nil
+		}
+	}
+	return id, name
+}
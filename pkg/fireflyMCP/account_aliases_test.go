@@ -0,0 +1,76 @@
+package fireflyMCP
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeAccountAliases(t *testing.T) {
+	aliases := normalizeAccountAliases(map[string]string{
+		"Joint  Card": "12",
+		"savings":     " 7 ",
+		"":            "3",
+		"empty":       "",
+	})
+
+	assert.Equal(t, map[string]string{
+		"joint card": "12",
+		"savings":    "7",
+	}, aliases)
+}
+
+func TestResolveAccountRef(t *testing.T) {
+	server := &FireflyMCPServer{
+		accountAliases: normalizeAccountAliases(map[string]string{"joint card": "12"}),
+	}
+
+	assert.Equal(t, "12", server.resolveAccountRef("Joint Card"))
+	assert.Equal(t, "12", server.resolveAccountRef("  joint   card "))
+	assert.Equal(t, "5", server.resolveAccountRef("5"))
+	assert.Equal(t, []string{"12", "5"}, server.resolveAccountRefs([]string{"joint card", "5"}))
+
+	// A server without aliases leaves references untouched
+	empty := &FireflyMCPServer{}
+	assert.Equal(t, "joint card", empty.resolveAccountRef("joint card"))
+}
+
+func TestApplyAccountAliases(t *testing.T) {
+	server := &FireflyMCPServer{
+		accountAliases: normalizeAccountAliases(map[string]string{
+			"joint card": "12",
+			"groceries":  "40",
+		}),
+	}
+
+	splits := []TransactionSplitRequest{
+		{
+			Type:            "withdrawal",
+			SourceName:      strPtr("Joint Card"),
+			DestinationName: strPtr("Corner Shop"),
+		},
+		{
+			Type:          "withdrawal",
+			SourceId:      strPtr("groceries"),
+			DestinationId: strPtr("99"),
+		},
+	}
+
+	resolved := server.applyAccountAliases(splits)
+	require.Len(t, resolved, 2)
+
+	// Alias given as a name is moved to the ID field
+	assert.Equal(t, "12", *resolved[0].SourceId)
+	assert.Nil(t, resolved[0].SourceName)
+	assert.Nil(t, resolved[0].DestinationId)
+	assert.Equal(t, "Corner Shop", *resolved[0].DestinationName)
+
+	// Alias given as an ID is replaced in place
+	assert.Equal(t, "40", *resolved[1].SourceId)
+	assert.Equal(t, "99", *resolved[1].DestinationId)
+
+	// The caller's slice is not modified
+	assert.Nil(t, splits[0].SourceId)
+	assert.Equal(t, "Joint Card", *splits[0].SourceName)
+}
@@ -0,0 +1,132 @@
+package fireflyMCP
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dezer32/mcp-firefly-iii/pkg/client"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultAccountMetadataTTL is used when accounts.metadata_ttl is not configured (seconds)
+const defaultAccountMetadataTTL = 300
+
+// accountMetadataCache keeps the type and currency of accounts per tenant, so
+// write tools can check and complete splits without fetching the accounts every time
+type accountMetadataCache struct {
+	mu      sync.Mutex
+	tenants map[string]map[string]accountMetadataEntry
+}
+
+type accountMetadataEntry struct {
+	name         string
+	accountType  string // Short account type, e.g. asset or expense
+	currencyCode string // Empty for accounts without a currency, e.g. most expense accounts
+	fetched      time.Time
+}
+
+func newAccountMetadataCache() *accountMetadataCache {
+	return &accountMetadataCache{tenants: make(map[string]map[string]accountMetadataEntry)}
+}
+
+// size returns how many accounts are cached
+func (c *accountMetadataCache) size() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	size := 0
+	for _, accounts := range c.tenants {
+		size += len(accounts)
+	}
+	return size
+}
+
+// accountMetadata returns the type and currency of an account, from the cache
+// while it is fresh. Reports false when the account cannot be read.
+func (s *FireflyMCPServer) accountMetadata(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	apiClient *client.ClientWithResponses,
+	accountID string,
+) (accountMetadataEntry, bool) {
+	tenant := s.tenantKey(req)
+	ttl := time.Duration(defaultAccountMetadataTTL) * time.Second
+	if config := s.currentConfig(); config != nil {
+		ttl = time.Duration(config.Accounts.MetadataTTL) * time.Second
+	}
+
+	if s.accountMetadataCache != nil {
+		s.accountMetadataCache.mu.Lock()
+		entry, ok := s.accountMetadataCache.tenants[tenant][accountID]
+		s.accountMetadataCache.mu.Unlock()
+		if ok && time.Since(entry.fetched) < ttl {
+			return entry, true
+		}
+	}
+
+	resp, err := apiClient.GetAccountWithResponse(ctx, accountID, &client.GetAccountParams{})
+	if err != nil || resp.StatusCode() != 200 || resp.ApplicationvndApiJSON200 == nil {
+		return accountMetadataEntry{}, false
+	}
+	account := resp.ApplicationvndApiJSON200.Data.Attributes
+	entry := accountMetadataEntry{
+		name:         account.Name,
+		accountType:  string(account.Type),
+		currencyCode: getStringValue(account.CurrencyCode),
+		fetched:      time.Now(),
+	}
+
+	if s.accountMetadataCache != nil && ttl > 0 {
+		s.accountMetadataCache.mu.Lock()
+		if s.accountMetadataCache.tenants[tenant] == nil {
+			s.accountMetadataCache.tenants[tenant] = make(map[string]accountMetadataEntry)
+		}
+		s.accountMetadataCache.tenants[tenant][accountID] = entry
+		s.accountMetadataCache.mu.Unlock()
+	}
+	return entry, true
+}
+
+// fillSplitCurrencies sets the currency of splits that have none to the
+// currency of their asset side: the source of withdrawals and transfers, the
+// destination of deposits. Only accounts given by ID are looked up. A currency
+// that differs from the account's is kept, with a warning, since Firefly III
+// usually rejects it unless a foreign amount is meant.
+func (s *FireflyMCPServer) fillSplitCurrencies(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	apiClient *client.ClientWithResponses,
+	splits []TransactionSplitRequest,
+) ([]TransactionSplitRequest, []string) {
+	filled := make([]TransactionSplitRequest, len(splits))
+	var warnings []string
+	for i, split := range splits {
+		filled[i] = split
+		accountID := getStringValue(split.SourceId)
+		if split.Type == "deposit" {
+			accountID = getStringValue(split.DestinationId)
+		}
+		if accountID == "" || getStringValue(split.CurrencyId) != "" {
+			continue
+		}
+		account, ok := s.accountMetadata(ctx, req, apiClient, accountID)
+		currencyCode := account.currencyCode
+		if !ok || currencyCode == "" {
+			continue
+		}
+		given := getStringValue(split.CurrencyCode)
+		switch {
+		case given == "":
+			filled[i].CurrencyCode = &currencyCode
+		case !strings.EqualFold(given, currencyCode):
+			warnings = append(warnings, fmt.Sprintf(
+				"transaction[%d].currency_code %s differs from the %s currency of account %s; "+
+					"to book an amount in another currency, set foreign_amount and foreign_currency_code instead",
+				i, given, currencyCode, accountID,
+			))
+		}
+	}
+	return filled, warnings
+}
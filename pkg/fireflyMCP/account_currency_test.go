@@ -0,0 +1,83 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreTransaction_FillsAccountCurrency(t *testing.T) {
+	var mu sync.Mutex
+	var stored []map[string]any
+	accountRequests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/v1/accounts/1":
+			accountRequests++
+			w.Write([]byte(`{"data":{"type":"accounts","id":"1","attributes":{"name":"Checking","type":"asset","currency_code":"EUR"}}}`))
+		case "/v1/accounts/5":
+			accountRequests++
+			w.Write([]byte(`{"data":{"type":"accounts","id":"5","attributes":{"name":"Shop","type":"expense"}}}`))
+		case "/v1/transactions":
+			body, _ := io.ReadAll(r.Body)
+			var request map[string]any
+			require.NoError(t, json.Unmarshal(body, &request))
+			stored = append(stored, request)
+			w.Write([]byte(`{"data":{"type":"transactions","id":"40","attributes":{"transactions":[` +
+				`{"transaction_journal_id":"41","type":"withdrawal","date":"2024-03-01T00:00:00Z","amount":"12.00","description":"Lunch"}]}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	config.Accounts.MetadataTTL = 300
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	checking, shop, usd := "1", "5", "USD"
+	result, _, err := server.handleStoreTransaction(ctx, nil, TransactionStoreRequest{
+		Transactions: []TransactionSplitRequest{
+			{Type: "withdrawal", Date: "2024-03-01", Amount: "12.00", Description: "Lunch", SourceId: &checking, DestinationId: &shop},
+			{Type: "deposit", Date: "2024-03-01", Amount: "3.00", Description: "Refund", SourceId: &checking, DestinationId: &shop},
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content[0].(*mcp.TextContent).Text)
+	assert.Nil(t, result.Meta)
+
+	require.Len(t, stored, 1)
+	splits := stored[0]["transactions"].([]any)
+	assert.Equal(t, "EUR", splits[0].(map[string]any)["currency_code"])
+	assert.Nil(t, splits[1].(map[string]any)["currency_code"])
+
+	result, _, err = server.handleStoreTransaction(ctx, nil, TransactionStoreRequest{
+		Transactions: []TransactionSplitRequest{
+			{Type: "withdrawal", Date: "2024-03-01", Amount: "12.00", Description: "Lunch", SourceId: &checking, CurrencyCode: &usd},
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	require.NotNil(t, result.Meta)
+	warnings := result.Meta["warnings"].([]string)
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "currency_code USD differs from the EUR currency of account 1")
+	assert.Equal(t, "USD", stored[1]["transactions"].([]any)[0].(map[string]any)["currency_code"])
+
+	// Account 1 is cached after the first store, account 5 was looked up once
+	assert.Equal(t, 2, accountRequests)
+}
@@ -0,0 +1,248 @@
+package fireflyMCP
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dezer32/mcp-firefly-iii/pkg/client"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// accountGroupRoles are the asset account roles accounts.groups may select
+var accountGroupRoles = []string{"defaultAsset", "sharedAsset", "savingAsset", "ccAsset", "cashWalletAsset"}
+
+// accountGroupType normalizes the account types accounts.groups may select:
+// asset, and liabilities in its singular and plural spelling
+func accountGroupType(accountType string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(accountType)) {
+	case "asset":
+		return "asset", true
+	case "liability", "liabilities":
+		return "liabilities", true
+	}
+	return "", false
+}
+
+// validateAccountGroups checks the accounts.groups configuration
+func validateAccountGroups(groups map[string]AccountGroup) error {
+	for name, group := range groups {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("accounts.groups: group names must not be empty")
+		}
+		if len(group.Accounts) == 0 && len(group.Roles) == 0 && len(group.Types) == 0 {
+			return fmt.Errorf("accounts.groups.%s must list accounts, roles or types", name)
+		}
+		for _, role := range group.Roles {
+			if !slices.Contains(accountGroupRoles, role) {
+				return fmt.Errorf("accounts.groups.%s: unknown role %q (one of %s)", name, role, strings.Join(accountGroupRoles, ", "))
+			}
+		}
+		for _, accountType := range group.Types {
+			if _, ok := accountGroupType(accountType); !ok {
+				return fmt.Errorf("accounts.groups.%s: type must be asset or liabilities, got %q", name, accountType)
+			}
+		}
+	}
+	return nil
+}
+
+// GroupedBalancesArgs represents the arguments for the grouped_balances tool
+type GroupedBalancesArgs struct {
+	Date            string   `json:"date,omitempty" jsonschema:"Balances at the end of this day (YYYY-MM-DD or today/yesterday, default: today)"`
+	Groups          []string `json:"groups,omitempty" jsonschema:"Names of the groups to return (default: all configured groups)"`
+	IncludeAccounts bool     `json:"include_accounts,omitempty" jsonschema:"List the accounts and their balances per group (default: false)"`
+}
+
+// GroupBalance is the balance of a group in one currency
+type GroupBalance struct {
+	CurrencyCode string `json:"currency_code"`
+	Balance      string `json:"balance"`
+}
+
+// GroupedAccount is an account of a group with its balance
+type GroupedAccount struct {
+	Id           string `json:"id"`
+	Name         string `json:"name"`
+	Type         string `json:"type"`
+	Role         string `json:"role,omitempty"`
+	CurrencyCode string `json:"currency_code"`
+	Balance      string `json:"balance"`
+}
+
+// AccountGroupBalance is the balance of an account group, per currency
+type AccountGroupBalance struct {
+	Name         string           `json:"name"`
+	AccountCount int              `json:"account_count"`
+	Balances     []GroupBalance   `json:"balances"`
+	Accounts     []GroupedAccount `json:"accounts,omitempty"`
+}
+
+// GroupedBalances is the result of grouped_balances
+type GroupedBalances struct {
+	Date      string                `json:"date"`
+	Groups    []AccountGroupBalance `json:"groups"`
+	Ungrouped *AccountGroupBalance  `json:"ungrouped,omitempty"` // Active asset and liability accounts in no group
+	Truncated bool                  `json:"truncated,omitempty"`
+}
+
+// handleGroupedBalances rolls up the balances of the active asset and
+// liability accounts per configured account group
+func (s *FireflyMCPServer) handleGroupedBalances(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args GroupedBalancesArgs,
+) (*mcp.CallToolResult, any, error) {
+	config := s.currentConfig()
+	if config == nil || len(config.Accounts.Groups) == 0 {
+		return newErrorResult("Error: no account groups are configured (accounts.groups)")
+	}
+	for _, name := range args.Groups {
+		if _, ok := config.Accounts.Groups[name]; !ok {
+			return newErrorResult(fmt.Sprintf("Error: unknown account group %q", name))
+		}
+	}
+	date := s.today()
+	if args.Date != "" {
+		parsed, err := resolveDate(args.Date, date)
+		if err != nil {
+			return newErrorResult(fmt.Sprintf("Error: invalid date: %v", err))
+		}
+		date = parsed
+	}
+
+	apiClient, err := s.getClient(ctx, req)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Failed to get API client: %v", err))
+	}
+	result := &GroupedBalances{Date: date.Format("2006-01-02")}
+	var accounts []GroupedAccount
+	for _, accountType := range []client.AccountTypeFilter{client.AccountTypeFilterAsset, client.AccountTypeFilterLiabilities} {
+		page, truncated, err := s.accountBalances(ctx, apiClient, accountType, date)
+		if err != nil {
+			return newErrorResult(err.Error())
+		}
+		accounts = append(accounts, page...)
+		result.Truncated = result.Truncated || truncated
+	}
+
+	names := args.Groups
+	if len(names) == 0 {
+		for name := range config.Accounts.Groups {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	grouped := make(map[string]bool)
+	for _, name := range names {
+		members := s.accountGroupMembers(config.Accounts.Groups[name], accounts)
+		for _, account := range members {
+			grouped[account.Id] = true
+		}
+		result.Groups = append(result.Groups, rollUpAccountGroup(name, members, args.IncludeAccounts))
+	}
+	if len(args.Groups) == 0 {
+		var ungrouped []GroupedAccount
+		for _, account := range accounts {
+			if !grouped[account.Id] {
+				ungrouped = append(ungrouped, account)
+			}
+		}
+		if len(ungrouped) > 0 {
+			group := rollUpAccountGroup("Ungrouped", ungrouped, args.IncludeAccounts)
+			result.Ungrouped = &group
+		}
+	}
+	return newSuccessResult(result)
+}
+
+// accountBalances lists the active accounts of a type with their balance at the end of date
+func (s *FireflyMCPServer) accountBalances(
+	ctx context.Context,
+	apiClient *client.ClientWithResponses,
+	accountType client.AccountTypeFilter,
+	date time.Time,
+) ([]GroupedAccount, bool, error) {
+	return fetchPages(ctx, s.pageParallelism(), func(ctx context.Context, page int) ([]GroupedAccount, int, error) {
+		limit := int32(compositePageSize)
+		pageNumber := int32(page)
+		resp, err := apiClient.ListAccountWithResponse(ctx, &client.ListAccountParams{
+			Limit: &limit,
+			Page:  &pageNumber,
+			Type:  &accountType,
+			Date:  &openapi_types.Date{Time: date},
+		})
+		if err != nil {
+			return nil, 0, fmt.Errorf("Error listing accounts: %v", err)
+		}
+		if resp.StatusCode() != 200 || resp.ApplicationvndApiJSON200 == nil {
+			return nil, 0, fmt.Errorf("API error: %d", resp.StatusCode())
+		}
+		var accounts []GroupedAccount
+		for _, account := range resp.ApplicationvndApiJSON200.Data {
+			attributes := account.Attributes
+			if attributes.Active != nil && !*attributes.Active {
+				continue
+			}
+			grouped := GroupedAccount{
+				Id:           account.Id,
+				Name:         attributes.Name,
+				Type:         string(accountType),
+				CurrencyCode: getStringValue(attributes.CurrencyCode),
+				Balance:      formatAmount(parseAmount(getStringValue(attributes.CurrentBalance))),
+			}
+			if attributes.AccountRole != nil {
+				grouped.Role = string(*attributes.AccountRole)
+			}
+			accounts = append(accounts, grouped)
+		}
+		totalPages := 1
+		if pagination := resp.ApplicationvndApiJSON200.Meta.Pagination; pagination != nil && pagination.TotalPages != nil {
+			totalPages = int(*pagination.TotalPages)
+		}
+		return accounts, totalPages, nil
+	})
+}
+
+// accountGroupMembers returns the accounts matching a group by ID or alias, role or type
+func (s *FireflyMCPServer) accountGroupMembers(group AccountGroup, accounts []GroupedAccount) []GroupedAccount {
+	ids := make(map[string]bool, len(group.Accounts))
+	for _, ref := range s.resolveAccountRefs(group.Accounts) {
+		ids[ref] = true
+	}
+	types := make(map[string]bool, len(group.Types))
+	for _, accountType := range group.Types {
+		normalized, _ := accountGroupType(accountType)
+		types[normalized] = true
+	}
+
+	var members []GroupedAccount
+	for _, account := range accounts {
+		if ids[account.Id] || types[account.Type] || (account.Role != "" && slices.Contains(group.Roles, account.Role)) {
+			members = append(members, account)
+		}
+	}
+	return members
+}
+
+// rollUpAccountGroup sums the balances of a group's accounts per currency
+func rollUpAccountGroup(name string, members []GroupedAccount, includeAccounts bool) AccountGroupBalance {
+	group := AccountGroupBalance{Name: name, AccountCount: len(members), Balances: []GroupBalance{}}
+	totals := make(map[string]decimal)
+	for _, account := range members {
+		totals[account.CurrencyCode] = totals[account.CurrencyCode].Add(parseAmount(account.Balance))
+	}
+	for currency, total := range totals {
+		group.Balances = append(group.Balances, GroupBalance{CurrencyCode: currency, Balance: formatAmount(total)})
+	}
+	sort.Slice(group.Balances, func(i, j int) bool { return group.Balances[i].CurrencyCode < group.Balances[j].CurrencyCode })
+	if includeAccounts {
+		group.Accounts = members
+		sort.Slice(group.Accounts, func(i, j int) bool { return group.Accounts[i].Name < group.Accounts[j].Name })
+	}
+	return group
+}
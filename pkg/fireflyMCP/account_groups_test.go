@@ -0,0 +1,119 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateAccountGroups(t *testing.T) {
+	assert.NoError(t, validateAccountGroups(nil))
+	assert.NoError(t, validateAccountGroups(map[string]AccountGroup{
+		"Cash": {Roles: []string{"defaultAsset", "cashWalletAsset"}},
+		"Debt": {Types: []string{"Liability"}},
+	}))
+	assert.ErrorContains(t, validateAccountGroups(map[string]AccountGroup{"Empty": {}}), "must list accounts, roles or types")
+	assert.ErrorContains(t, validateAccountGroups(map[string]AccountGroup{"Cash": {Roles: []string{"wallet"}}}), `unknown role "wallet"`)
+	assert.ErrorContains(t, validateAccountGroups(map[string]AccountGroup{"Spend": {Types: []string{"expense"}}}), "type must be asset or liabilities")
+}
+
+func TestGroupedBalances(t *testing.T) {
+	account := func(id, name, role, balance, currency string, active bool) string {
+		attributes := fmt.Sprintf(`"name":%q,"current_balance":%q,"currency_code":%q,"active":%t`, name, balance, currency, active)
+		if role != "" {
+			attributes += fmt.Sprintf(`,"account_role":%q`, role)
+		}
+		return fmt.Sprintf(`{"type":"accounts","id":%q,"attributes":{%s}}`, id, attributes)
+	}
+	accounts := map[string][]string{
+		"asset": {
+			account("1", "Checking", "defaultAsset", "1200.50", "EUR", true),
+			account("2", "Wallet", "cashWalletAsset", "40.00", "EUR", true),
+			account("3", "Old wallet", "cashWalletAsset", "5.00", "EUR", false),
+			account("7", "Brokerage", "savingAsset", "9000.00", "USD", true),
+			account("12", "Joint card", "ccAsset", "-300.00", "EUR", true),
+			account("20", "Savings", "savingAsset", "2500.00", "EUR", true),
+		},
+		"liabilities": {
+			account("45", "Car loan", "", "-8000.00", "EUR", true),
+		},
+	}
+
+	var dates []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/accounts" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		dates = append(dates, r.URL.Query().Get("date"))
+		data := accounts[r.URL.Query().Get("type")]
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		fmt.Fprintf(w, `{"data":[%s],"meta":{"pagination":{"total":%d,"count":%d,"per_page":50,"current_page":1,"total_pages":1}}}`,
+			strings.Join(data, ","), len(data), len(data))
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	config.Accounts.Groups = map[string]AccountGroup{
+		"Cash":        {Roles: []string{"defaultAsset", "cashWalletAsset"}, Accounts: []string{"joint card"}},
+		"Investments": {Accounts: []string{"7"}},
+		"Debt":        {Types: []string{"liabilities"}},
+	}
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	invoke := func(args string) (*mcp.CallToolResult, string) {
+		result, err := server.tools["grouped_balances"].invoke(context.Background(), nil, []byte(args))
+		require.NoError(t, err)
+		return result, result.Content[0].(*mcp.TextContent).Text
+	}
+
+	result, text := invoke(`{"date":"2024-06-30"}`)
+	require.False(t, result.IsError, text)
+	var balances GroupedBalances
+	require.NoError(t, json.Unmarshal([]byte(text), &balances))
+
+	assert.Equal(t, "2024-06-30", balances.Date)
+	assert.Equal(t, []string{"2024-06-30", "2024-06-30"}, dates)
+	require.Len(t, balances.Groups, 3)
+	assert.Equal(t, "Cash", balances.Groups[0].Name, "groups are sorted by name")
+	assert.Equal(t, 3, balances.Groups[0].AccountCount, "inactive accounts are skipped, aliases resolve")
+	assert.Equal(t, []GroupBalance{{CurrencyCode: "EUR", Balance: "940.50"}}, balances.Groups[0].Balances)
+	assert.Nil(t, balances.Groups[0].Accounts)
+	assert.Equal(t, "Debt", balances.Groups[1].Name)
+	assert.Equal(t, []GroupBalance{{CurrencyCode: "EUR", Balance: "-8000.00"}}, balances.Groups[1].Balances)
+	assert.Equal(t, []GroupBalance{{CurrencyCode: "USD", Balance: "9000.00"}}, balances.Groups[2].Balances)
+	require.NotNil(t, balances.Ungrouped)
+	assert.Equal(t, "Ungrouped", balances.Ungrouped.Name)
+	assert.Equal(t, 1, balances.Ungrouped.AccountCount)
+
+	result, text = invoke(`{"groups":["Cash"],"include_accounts":true}`)
+	require.False(t, result.IsError, text)
+	balances = GroupedBalances{}
+	require.NoError(t, json.Unmarshal([]byte(text), &balances))
+	require.Len(t, balances.Groups, 1)
+	assert.Nil(t, balances.Ungrouped, "only listed groups are returned")
+	names := []string{}
+	for _, account := range balances.Groups[0].Accounts {
+		names = append(names, account.Name)
+	}
+	assert.Equal(t, []string{"Checking", "Joint card", "Wallet"}, names)
+
+	result, text = invoke(`{"groups":["Crypto"]}`)
+	assert.True(t, result.IsError)
+	assert.Contains(t, text, `unknown account group "Crypto"`)
+
+	config.Accounts.Groups = nil
+	result, text = invoke(`{}`)
+	assert.True(t, result.IsError)
+	assert.Contains(t, text, "no account groups are configured")
+}
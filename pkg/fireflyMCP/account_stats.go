@@ -0,0 +1,274 @@
+package fireflyMCP
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/dezer32/mcp-firefly-iii/pkg/client"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// defaultAccountStatsMonths is the default averaging window of account_stats
+const defaultAccountStatsMonths = 12
+
+// AccountStatsArgs represents the arguments for the account_stats tool
+type AccountStatsArgs struct {
+	ID     string `json:"id" jsonschema:"Account ID or configured account alias"`
+	Months int    `json:"months,omitempty" jsonschema:"Number of recent months used for the monthly averages (default: 12)"`
+}
+
+// AccountStatsPeriod is the period used to compute monthly averages
+type AccountStatsPeriod struct {
+	Start  string `json:"start"`
+	End    string `json:"end"`
+	Months int    `json:"months"`
+}
+
+// AccountStats summarizes the activity of an account
+type AccountStats struct {
+	AccountId             string              `json:"account_id"`
+	AccountName           string              `json:"account_name"`
+	AccountType           string              `json:"account_type"`
+	Active                bool                `json:"active"`
+	CurrentBalance        string              `json:"current_balance"`
+	CurrencyCode          string              `json:"currency_code"`
+	TransactionCount      int                 `json:"transaction_count"`
+	FirstActivity         *string             `json:"first_activity"`
+	LastActivity          *string             `json:"last_activity"`
+	DaysSinceLastActivity *int                `json:"days_since_last_activity"`
+	AveragePeriod         *AccountStatsPeriod `json:"average_period,omitempty"`
+	AverageMonthlyInflow  []InsightTotalEntry `json:"average_monthly_inflow,omitempty"`
+	AverageMonthlyOutflow []InsightTotalEntry `json:"average_monthly_outflow,omitempty"`
+	Note                  string              `json:"note,omitempty"`
+}
+
+// handleAccountStats returns transaction count, first/last activity, balance and
+// average monthly inflow/outflow of an account
+func (s *FireflyMCPServer) handleAccountStats(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args AccountStatsArgs,
+) (*mcp.CallToolResult, any, error) {
+	if args.ID == "" {
+		return newErrorResult("Account ID is required")
+	}
+	months := args.Months
+	if months <= 0 {
+		months = defaultAccountStatsMonths
+	}
+	accountID := s.resolveAccountRef(args.ID)
+
+	apiClient, err := s.getClient(ctx, req)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Failed to get API client: %v", err))
+	}
+
+	accountResp, err := apiClient.GetAccountWithResponse(ctx, accountID, &client.GetAccountParams{})
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error getting account: %v", err))
+	}
+	if accountResp.StatusCode() != 200 || accountResp.ApplicationvndApiJSON200 == nil {
+		return newErrorResult(fmt.Sprintf("API error: %d", accountResp.StatusCode()))
+	}
+	attributes := accountResp.ApplicationvndApiJSON200.Data.Attributes
+
+	stats := &AccountStats{
+		AccountId:      accountResp.ApplicationvndApiJSON200.Data.Id,
+		AccountName:    attributes.Name,
+		AccountType:    string(attributes.Type),
+		Active:         attributes.Active != nil && *attributes.Active,
+		CurrentBalance: getStringValue(attributes.CurrentBalance),
+		CurrencyCode:   getStringValue(attributes.CurrencyCode),
+	}
+
+	// The newest transaction and the total count come from a single one-item page
+	newest, total, err := s.accountTransactionPage(ctx, apiClient, accountID, 1)
+	if err != nil {
+		return newErrorResult(err.Error())
+	}
+	stats.TransactionCount = total
+	if newest == nil {
+		stats.Note = "The account has no transactions"
+		return newSuccessResult(stats)
+	}
+
+	// Transactions are sorted newest first, so the last page holds the oldest one
+	oldest := newest
+	if total > 1 {
+		oldest, _, err = s.accountTransactionPage(ctx, apiClient, accountID, total)
+		if err != nil {
+			return newErrorResult(err.Error())
+		}
+		if oldest == nil {
+			oldest = newest
+		}
+	}
+
+	now := s.now()
+	first := oldest.Date.Format("2006-01-02")
+	last := newest.Date.Format("2006-01-02")
+	daysSince := int(now.Sub(newest.Date).Hours() / 24)
+	stats.FirstActivity = &first
+	stats.LastActivity = &last
+	stats.DaysSinceLastActivity = &daysSince
+
+	// Monthly averages use insight totals, which Firefly III only supports for
+	// asset accounts and liabilities
+	if !accountSupportsInsights(stats.AccountType) {
+		stats.Note = "Monthly averages are only available for asset and liability accounts"
+		return newSuccessResult(stats)
+	}
+
+	period := accountStatsPeriod(oldest.Date, now, months)
+	stats.AveragePeriod = period
+	inflow, outflow, err := s.accountInsightTotals(ctx, apiClient, accountID, period)
+	if err != nil {
+		return newErrorResult(err.Error())
+	}
+	stats.AverageMonthlyInflow = averagePerMonth(inflow, period.Months)
+	stats.AverageMonthlyOutflow = averagePerMonth(outflow, period.Months)
+	stats.Note = "Averages exclude transfers between own accounts"
+
+	return newSuccessResult(stats)
+}
+
+// accountTransactionPage fetches a single transaction (page of size one) of an
+// account and returns it together with the total number of transactions
+func (s *FireflyMCPServer) accountTransactionPage(
+	ctx context.Context,
+	apiClient *client.ClientWithResponses,
+	accountID string,
+	page int,
+) (*Transaction, int, error) {
+	limit := int32(1)
+	pageNumber := int32(page)
+	resp, err := apiClient.ListTransactionByAccountWithResponse(ctx, accountID, &client.ListTransactionByAccountParams{
+		Limit: &limit,
+		Page:  &pageNumber,
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("Error listing account transactions: %v", err)
+	}
+	if resp.StatusCode() != 200 {
+		return nil, 0, fmt.Errorf("API error: %d", resp.StatusCode())
+	}
+
+	list := mapTransactionArrayToTransactionList(resp.ApplicationvndApiJSON200)
+	if list == nil || len(list.Data) == 0 || len(list.Data[0].Transactions) == 0 {
+		return nil, 0, nil
+	}
+	return &list.Data[0].Transactions[0], list.Pagination.Total, nil
+}
+
+// accountInsightTotals returns the income and expense totals of an asset account for the period
+func (s *FireflyMCPServer) accountInsightTotals(
+	ctx context.Context,
+	apiClient *client.ClientWithResponses,
+	accountID string,
+	period *AccountStatsPeriod,
+) ([]InsightTotalEntry, []InsightTotalEntry, error) {
+	id, err := strconv.ParseInt(accountID, 10, 64)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Invalid account ID: %s", accountID)
+	}
+	start, _ := time.Parse("2006-01-02", period.Start)
+	end, _ := time.Parse("2006-01-02", period.End)
+	return insightTotals(ctx, apiClient, start, end, []int64{id})
+}
+
+// insightTotals returns the income and expense insight totals for the period,
+// limited to the given accounts when any are set
+func insightTotals(
+	ctx context.Context,
+	apiClient *client.ClientWithResponses,
+	start, end time.Time,
+	accountIDs []int64,
+) ([]InsightTotalEntry, []InsightTotalEntry, error) {
+	var accounts *[]int64
+	if len(accountIDs) > 0 {
+		accounts = &accountIDs
+	}
+
+	incomeResp, err := apiClient.InsightIncomeTotalWithResponse(ctx, &client.InsightIncomeTotalParams{
+		Start:    openapi_types.Date{Time: start},
+		End:      openapi_types.Date{Time: end},
+		Accounts: accounts,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error getting income insights: %v", err)
+	}
+	if incomeResp.StatusCode() != 200 {
+		return nil, nil, fmt.Errorf("API error: %d", incomeResp.StatusCode())
+	}
+
+	expenseResp, err := apiClient.InsightExpenseTotalWithResponse(ctx, &client.InsightExpenseTotalParams{
+		Start:    openapi_types.Date{Time: start},
+		End:      openapi_types.Date{Time: end},
+		Accounts: accounts,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error getting expense insights: %v", err)
+	}
+	if expenseResp.StatusCode() != 200 {
+		return nil, nil, fmt.Errorf("API error: %d", expenseResp.StatusCode())
+	}
+
+	return mapInsightTotalToDTO(incomeResp.JSON200).Entries, mapInsightTotalToDTO(expenseResp.JSON200).Entries, nil
+}
+
+// accountSupportsInsights reports whether Firefly III insight totals can be
+// filtered by an account of this type
+func accountSupportsInsights(accountType string) bool {
+	switch accountType {
+	case "asset", "liability", "liabilities":
+		return true
+	}
+	return false
+}
+
+// accountStatsPeriod returns the averaging window: the last months full months up
+// to now, shortened to start at the first activity for young accounts
+func accountStatsPeriod(firstActivity, now time.Time, months int) *AccountStatsPeriod {
+	start := now.AddDate(0, -months, 0)
+	if firstActivity.After(start) {
+		start = firstActivity
+		months = monthsBetween(start, now)
+	}
+	return &AccountStatsPeriod{
+		Start:  start.Format("2006-01-02"),
+		End:    now.Format("2006-01-02"),
+		Months: months,
+	}
+}
+
+// monthsBetween returns the number of started months between two dates (at least 1)
+func monthsBetween(start, end time.Time) int {
+	months := (end.Year()-start.Year())*12 + int(end.Month()) - int(start.Month())
+	if end.Day() >= start.Day() {
+		months++
+	}
+	if months < 1 {
+		return 1
+	}
+	return months
+}
+
+// averagePerMonth divides insight totals by the number of months.
+// Amounts are reported as absolute values with two decimals.
+func averagePerMonth(entries []InsightTotalEntry, months int) []InsightTotalEntry {
+	averages := make([]InsightTotalEntry, 0, len(entries))
+	for _, entry := range entries {
+		amount, ok := parseDecimal(entry.Amount)
+		if !ok {
+			continue
+		}
+		averages = append(averages, InsightTotalEntry{
+			Amount:       formatAmount(amount.Abs().Div(decimalFromInt(int64(months)))),
+			CurrencyCode: entry.CurrencyCode,
+		})
+	}
+	return averages
+}
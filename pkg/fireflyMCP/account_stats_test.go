@@ -0,0 +1,61 @@
+package fireflyMCP
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountStatsPeriod(t *testing.T) {
+	now := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+
+	period := accountStatsPeriod(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), now, 12)
+	assert.Equal(t, &AccountStatsPeriod{Start: "2023-06-15", End: "2024-06-15", Months: 12}, period)
+
+	// Young accounts are averaged over their lifetime only
+	period = accountStatsPeriod(time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC), now, 12)
+	assert.Equal(t, &AccountStatsPeriod{Start: "2024-03-20", End: "2024-06-15", Months: 3}, period)
+
+	period = accountStatsPeriod(time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC), now, 12)
+	assert.Equal(t, 1, period.Months)
+}
+
+func TestMonthsBetween(t *testing.T) {
+	tests := []struct {
+		start, end string
+		expected   int
+	}{
+		{"2024-01-01", "2024-01-01", 1},
+		{"2024-01-15", "2024-02-14", 1},
+		{"2024-01-15", "2024-02-15", 2},
+		{"2023-11-01", "2024-02-20", 4},
+	}
+	for _, tt := range tests {
+		start, _ := time.Parse("2006-01-02", tt.start)
+		end, _ := time.Parse("2006-01-02", tt.end)
+		assert.Equal(t, tt.expected, monthsBetween(start, end), tt.start+" - "+tt.end)
+	}
+}
+
+func TestAveragePerMonth(t *testing.T) {
+	entries := []InsightTotalEntry{
+		{Amount: "-1200.00", CurrencyCode: "EUR"},
+		{Amount: "100", CurrencyCode: "USD"},
+		{Amount: "invalid", CurrencyCode: "GBP"},
+	}
+
+	averages := averagePerMonth(entries, 12)
+	assert.Equal(t, []InsightTotalEntry{
+		{Amount: "100.00", CurrencyCode: "EUR"},
+		{Amount: "8.33", CurrencyCode: "USD"},
+	}, averages)
+	assert.Empty(t, averagePerMonth(nil, 12))
+}
+
+func TestAccountSupportsInsights(t *testing.T) {
+	assert.True(t, accountSupportsInsights("asset"))
+	assert.True(t, accountSupportsInsights("liabilities"))
+	assert.False(t, accountSupportsInsights("expense"))
+	assert.False(t, accountSupportsInsights("revenue"))
+}
@@ -0,0 +1,122 @@
+package fireflyMCP
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// reloadableSections lists the configuration sections ReloadConfig applies; the
+// others are only read at startup and need a restart
+var reloadableSections = []struct {
+	name string
+	get  func(c *Config) any
+	set  func(dst, src *Config)
+}{
+	{"limits", func(c *Config) any { return c.Limits }, func(dst, src *Config) { dst.Limits = src.Limits }},
+	{"accounts", func(c *Config) any { return c.Accounts }, func(dst, src *Config) { dst.Accounts = src.Accounts }},
+	{"budgets", func(c *Config) any { return c.Budgets }, func(dst, src *Config) { dst.Budgets = src.Budgets }},
+	{"categories", func(c *Config) any { return c.Categories }, func(dst, src *Config) { dst.Categories = src.Categories }},
+	{"formatting", func(c *Config) any { return c.Formatting }, func(dst, src *Config) { dst.Formatting = src.Formatting }},
+	{"responses", func(c *Config) any { return c.Responses }, func(dst, src *Config) { dst.Responses = src.Responses }},
+	{"tenants", func(c *Config) any { return c.Tenants }, func(dst, src *Config) { dst.Tenants = src.Tenants }},
+	{"trends", func(c *Config) any { return c.Trends }, func(dst, src *Config) { dst.Trends = src.Trends }},
+	{"catalogs", func(c *Config) any { return c.Catalogs }, func(dst, src *Config) { dst.Catalogs = src.Catalogs }},
+	{"client.error_body_limit", func(c *Config) any { return c.Client.ErrorBodyLimit }, func(dst, src *Config) {
+		dst.Client.ErrorBodyLimit = src.Client.ErrorBodyLimit
+	}},
+}
+
+// AdminSession describes a connected MCP session
+type AdminSession struct {
+	ID            string `json:"id"`
+	ClientName    string `json:"client_name,omitempty"`
+	ClientVersion string `json:"client_version,omitempty"`
+}
+
+// ReloadConfig reads the configuration file again and applies the sections that
+// can change at runtime (see reloadableSections). It returns the names of the
+// sections that changed. Tool descriptions keep the limits of the startup configuration.
+func (s *FireflyMCPServer) ReloadConfig(filename string) ([]string, error) {
+	loaded, err := LoadConfig(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	s.configMu.Lock()
+	updated := *s.config
+	changed := []string{}
+	for _, section := range reloadableSections {
+		if !reflect.DeepEqual(section.get(&updated), section.get(loaded)) {
+			section.set(&updated, loaded)
+			changed = append(changed, section.name)
+		}
+	}
+	s.config = &updated
+	s.accountAliases = normalizeAccountAliases(updated.Accounts.Aliases)
+	if s.hiddenAccounts != nil {
+		s.hiddenAccounts.set(&updated)
+	}
+	s.configMu.Unlock()
+
+	// Cached hints and tool results may have been made with other settings
+	s.FlushCaches()
+	return changed, nil
+}
+
+// FlushCaches drops all cached Firefly III data and returns the number of
+// entries removed
+func (s *FireflyMCPServer) FlushCaches() int {
+	flushed := 0
+	if s.toolCache != nil {
+		flushed += s.toolCache.flush()
+	}
+	if s.catalogs != nil {
+		flushed += s.catalogs.flush()
+	}
+	if s.formatting != nil {
+		flushed += s.formatting.flush()
+	}
+	return flushed
+}
+
+// SetReadOnly enables or disables read-only mode, in which every tool that is
+// not annotated as read-only fails without calling Firefly III
+func (s *FireflyMCPServer) SetReadOnly(enabled bool) {
+	s.readOnly.Store(enabled)
+}
+
+// ReadOnly reports whether read-only mode is enabled
+func (s *FireflyMCPServer) ReadOnly() bool {
+	return s.readOnly.Load()
+}
+
+// Sessions returns the connected MCP sessions
+func (s *FireflyMCPServer) Sessions() []AdminSession {
+	sessions := []AdminSession{}
+	for session := range s.server.Sessions() {
+		entry := AdminSession{ID: session.ID()}
+		if params := session.InitializeParams(); params != nil && params.ClientInfo != nil {
+			entry.ClientName = params.ClientInfo.Name
+			entry.ClientVersion = params.ClientInfo.Version
+		}
+		sessions = append(sessions, entry)
+	}
+	return sessions
+}
+
+// withReadOnlyGuard rejects calls of a tool that changes data while read-only
+// mode is enabled
+func withReadOnlyGuard[In any](s *FireflyMCPServer, tool *mcp.Tool, handler mcp.ToolHandlerFor[In, any]) mcp.ToolHandlerFor[In, any] {
+	if tool.Annotations != nil && tool.Annotations.ReadOnlyHint {
+		return handler
+	}
+	return func(ctx context.Context, req *mcp.CallToolRequest, args In) (*mcp.CallToolResult, any, error) {
+		if s.ReadOnly() {
+			return newErrorResult(fmt.Sprintf("Error: %s is disabled because the server is in read-only mode", tool.Name))
+		}
+		return handler(ctx, req, args)
+	}
+}
@@ -0,0 +1,182 @@
+package fireflyMCP
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// AdminServer serves the control API: operational actions on a running server,
+// on its own port and protected by admin.token, separate from the MCP endpoint.
+type AdminServer struct {
+	mcpServer  *FireflyMCPServer
+	httpServer *http.Server
+	config     *Config
+	configPath string
+	logger     *slog.Logger
+}
+
+// NewAdminServer creates the control API server. configPath is the file read
+// again by POST /config/reload.
+func NewAdminServer(mcpServer *FireflyMCPServer, config *Config, configPath string, logger *slog.Logger) *AdminServer {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &AdminServer{
+		mcpServer:  mcpServer,
+		config:     config,
+		configPath: configPath,
+		logger:     logger,
+	}
+}
+
+// Handler returns the control API routes, wrapped in admin token authentication.
+func (s *AdminServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /sessions", s.handleSessions)
+	mux.HandleFunc("POST /config/reload", s.handleReloadConfig)
+	mux.HandleFunc("POST /cache/flush", s.handleFlushCache)
+	mux.HandleFunc("GET /read-only", s.handleGetReadOnly)
+	mux.HandleFunc("PUT /read-only", s.handleSetReadOnly)
+	mux.HandleFunc("GET /status", s.handleStatus)
+	mux.HandleFunc("GET /dashboard", s.handleDashboard)
+	return AdminAuthMiddleware(s.config.Admin.Token, s.logger)(mux)
+}
+
+// Start starts the control API and blocks until the context is cancelled.
+func (s *AdminServer) Start(ctx context.Context) error {
+	addr := fmt.Sprintf("%s:%d", s.config.Admin.Host, s.config.Admin.Port)
+	s.httpServer = &http.Server{
+		Addr:         addr,
+		Handler:      RequestLoggingMiddleware(s.logger)(s.Handler()),
+		ReadTimeout:  30 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+
+	s.logger.Info("starting admin API", "addr", addr)
+
+	errChan := make(chan error, 1)
+	go func() {
+		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errChan <- err
+		}
+		close(errChan)
+	}()
+
+	select {
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return s.httpServer.Shutdown(shutdownCtx)
+	case err := <-errChan:
+		return err
+	}
+}
+
+// basicAuthRoutes are the read-only routes browsers may open with basic
+// authentication. Browsers resend basic credentials on their own, so mutating
+// routes accept only the bearer token to stay safe from cross-site requests.
+var basicAuthRoutes = map[string]bool{
+	"/status":    true,
+	"/dashboard": true,
+}
+
+// AdminAuthMiddleware requires Authorization: Bearer <admin token> on every
+// request. Browsers may send the token as the password of basic authentication
+// instead on GET /status and GET /dashboard, which they prompt for, so the
+// dashboard can be opened directly.
+func AdminAuthMiddleware(token string, logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			basicAllowed := r.Method == http.MethodGet && basicAuthRoutes[r.URL.Path]
+			auth := r.Header.Get("Authorization")
+			given := ""
+			if len(auth) >= 7 && strings.EqualFold(auth[:7], "bearer ") {
+				given = strings.TrimSpace(auth[7:])
+			} else if _, password, ok := r.BasicAuth(); ok && basicAllowed {
+				given = password
+			}
+			if token == "" || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+				logger.Warn("rejected admin request",
+					"remote_addr", r.RemoteAddr,
+					"path", r.URL.Path)
+				if basicAllowed {
+					w.Header().Set("WWW-Authenticate", `Basic realm="admin", charset="UTF-8"`)
+				}
+				http.Error(w, "Authorization: Bearer <admin-token> required", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// handleSessions lists the connected MCP sessions.
+func (s *AdminServer) handleSessions(w http.ResponseWriter, r *http.Request) {
+	writeAdminJSON(w, http.StatusOK, map[string]any{"sessions": s.mcpServer.Sessions()})
+}
+
+// handleReloadConfig reads the configuration file again and applies the
+// sections that can change at runtime.
+func (s *AdminServer) handleReloadConfig(w http.ResponseWriter, r *http.Request) {
+	changed, err := s.mcpServer.ReloadConfig(s.configPath)
+	if err != nil {
+		s.logger.Warn("config reload failed", "error", err)
+		writeAdminJSON(w, http.StatusUnprocessableEntity, map[string]any{"error": err.Error()})
+		return
+	}
+	s.logger.Info("config reloaded", "changed", changed)
+	writeAdminJSON(w, http.StatusOK, map[string]any{"changed": changed})
+}
+
+// handleFlushCache drops all cached Firefly III data.
+func (s *AdminServer) handleFlushCache(w http.ResponseWriter, r *http.Request) {
+	flushed := s.mcpServer.FlushCaches()
+	s.logger.Info("caches flushed", "entries", flushed)
+	writeAdminJSON(w, http.StatusOK, map[string]any{"flushed": flushed})
+}
+
+// handleGetReadOnly reports whether read-only mode is enabled.
+func (s *AdminServer) handleGetReadOnly(w http.ResponseWriter, r *http.Request) {
+	writeAdminJSON(w, http.StatusOK, map[string]any{"enabled": s.mcpServer.ReadOnly()})
+}
+
+// handleSetReadOnly enables or disables read-only mode from a {"enabled": bool} body.
+func (s *AdminServer) handleSetReadOnly(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Enabled *bool `json:"enabled"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Enabled == nil {
+		writeAdminJSON(w, http.StatusBadRequest, map[string]any{"error": `body must be {"enabled": true|false}`})
+		return
+	}
+	s.mcpServer.SetReadOnly(*body.Enabled)
+	s.logger.Info("read-only mode changed", "enabled", *body.Enabled)
+	writeAdminJSON(w, http.StatusOK, map[string]any{"enabled": *body.Enabled})
+}
+
+// handleStatus returns the state of the server and its connection to Firefly III.
+func (s *AdminServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	writeAdminJSON(w, http.StatusOK, s.mcpServer.Status(r.Context()))
+}
+
+// handleDashboard renders the status as an HTML page for operators.
+func (s *AdminServer) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := dashboardTemplate.Execute(w, s.mcpServer.Status(r.Context())); err != nil {
+		s.logger.Warn("dashboard rendering failed", "error", err)
+	}
+}
+
+func writeAdminJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,174 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAdminTestServer(t *testing.T, configPath string) (*FireflyMCPServer, http.Handler) {
+	config := newPluginTestConfig()
+	config.Admin.Token = "admin-secret"
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+	return server, NewAdminServer(server, config, configPath, testLogger()).Handler()
+}
+
+func adminRequest(handler http.Handler, method, path, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestAdminServer_RequiresToken(t *testing.T) {
+	_, handler := newAdminTestServer(t, "")
+
+	assert.Equal(t, http.StatusUnauthorized, adminRequest(handler, http.MethodGet, "/sessions", "", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, adminRequest(handler, http.MethodGet, "/sessions", "token", "").Code)
+
+	rr := adminRequest(handler, http.MethodGet, "/sessions", "admin-secret", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"sessions":[]}`, rr.Body.String())
+}
+
+func TestAdminServer_ReadOnlyMode(t *testing.T) {
+	server, handler := newAdminTestServer(t, "")
+
+	rr := adminRequest(handler, http.MethodPut, "/read-only", "admin-secret", `{"enabled":true}`)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.True(t, server.ReadOnly())
+
+	result, err := server.tools["delete_rule"].invoke(context.Background(), nil, json.RawMessage(`{"id":"1"}`))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "read-only mode")
+
+	// Read-only tools keep working
+	result, err = server.tools["explain_tool"].invoke(context.Background(), nil, json.RawMessage(`{"name":"list_accounts"}`))
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+
+	assert.Equal(t, http.StatusBadRequest, adminRequest(handler, http.MethodPut, "/read-only", "admin-secret", `{}`).Code)
+	adminRequest(handler, http.MethodPut, "/read-only", "admin-secret", `{"enabled":false}`)
+	assert.JSONEq(t, `{"enabled":false}`, adminRequest(handler, http.MethodGet, "/read-only", "admin-secret", "").Body.String())
+}
+
+func TestAdminServer_ReloadConfig(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(`
+server:
+  url: "https://firefly.example.com"
+api:
+  token: "token"
+limits:
+  accounts: 7
+accounts:
+  aliases:
+    savings: "42"
+`), 0644))
+	server, handler := newAdminTestServer(t, configFile)
+	server.formatting.entries[""] = formattingCacheEntry{}
+
+	rr := adminRequest(handler, http.MethodPost, "/config/reload", "admin-secret", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	var body struct {
+		Changed []string `json:"changed"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Contains(t, body.Changed, "limits")
+	assert.Contains(t, body.Changed, "accounts")
+
+	assert.Equal(t, 7, server.defaultLimit("list_accounts"))
+	assert.Equal(t, "42", server.resolveAccountRef("Savings"))
+	assert.Equal(t, "admin-secret", server.Config().Admin.Token, "sections that need a restart are kept")
+	assert.Empty(t, server.formatting.entries)
+
+	require.NoError(t, os.WriteFile(configFile, []byte("limits:\n  accounts: -1\n"), 0644))
+	rr = adminRequest(handler, http.MethodPost, "/config/reload", "admin-secret", "")
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	assert.Equal(t, 7, server.defaultLimit("list_accounts"))
+}
+
+func TestAdminServer_FlushCache(t *testing.T) {
+	server, handler := newAdminTestServer(t, "")
+	server.formatting.entries["a"] = formattingCacheEntry{}
+	server.formatting.entries["b"] = formattingCacheEntry{}
+
+	rr := adminRequest(handler, http.MethodPost, "/cache/flush", "admin-secret", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"flushed":2}`, rr.Body.String())
+	assert.Empty(t, server.formatting.entries)
+}
+
+func TestAdminServer_StatusAndDashboard(t *testing.T) {
+	firefly := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"version":"6.1.0","api_version":"2.1.0"}}`))
+	}))
+	defer firefly.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = firefly.URL
+	config.Admin.Token = "admin-secret"
+	config.MCP.Name = "firefly-iii-mcp"
+	config.MCP.Version = "1.2.3"
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+	handler := NewAdminServer(server, config, "", testLogger()).Handler()
+	server.formatting.entries["a"] = formattingCacheEntry{}
+	server.sessionStats.record(server, "", "explain_tool", false, 12*time.Millisecond, &mcp.CallToolResult{}, nil)
+
+	rr := adminRequest(handler, http.MethodGet, "/status", "admin-secret", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	var status ServerStatus
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &status))
+	assert.Equal(t, "1.2.3", status.Version)
+	assert.Equal(t, "stdio", status.Transport)
+	assert.True(t, status.Firefly.Reachable)
+	assert.Equal(t, "6.1.0", status.Firefly.Version)
+	assert.Equal(t, 1, status.Caches.FormattingHints)
+	require.Len(t, status.RecentCalls, 1)
+	assert.Equal(t, RecentToolCall{Time: status.RecentCalls[0].Time, Tool: "explain_tool", DurationMs: 12}, status.RecentCalls[0])
+
+	// Browsers authenticate with the token as basic auth password
+	rr = adminRequest(handler, http.MethodGet, "/dashboard", "", "")
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Contains(t, rr.Header().Get("WWW-Authenticate"), "Basic")
+
+	req := httptest.NewRequest(http.MethodGet, "/dashboard", nil)
+	req.SetBasicAuth("operator", "admin-secret")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Header().Get("Content-Type"), "text/html")
+	body := rr.Body.String()
+	assert.Contains(t, body, "firefly-iii-mcp 1.2.3")
+	assert.Contains(t, body, "6.1.0 (API 2.1.0)")
+	assert.Contains(t, body, "<td>explain_tool</td>")
+
+	// Mutating routes accept only the bearer token
+	rr = adminRequest(handler, http.MethodPost, "/cache/flush", "", "")
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Empty(t, rr.Header().Get("WWW-Authenticate"))
+
+	req = httptest.NewRequest(http.MethodPost, "/cache/flush", nil)
+	req.SetBasicAuth("operator", "admin-secret")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Len(t, server.formatting.entries, 1)
+}
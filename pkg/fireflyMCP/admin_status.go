@@ -0,0 +1,186 @@
+package fireflyMCP
+
+import (
+	"context"
+	"html/template"
+	"time"
+)
+
+// statusCheckTimeout bounds the Firefly III connectivity check of Status
+const statusCheckTimeout = 5 * time.Second
+
+// ServerStatus is the state of a running server, as shown by GET /status and
+// the dashboard of the admin API
+type ServerStatus struct {
+	Name        string           `json:"name"`
+	Version     string           `json:"version"`
+	Transport   string           `json:"transport"` // http or stdio
+	StartedAt   time.Time        `json:"started_at"`
+	Uptime      string           `json:"uptime"`
+	ReadOnly    bool             `json:"read_only"`
+	Sessions    []SessionStatus  `json:"sessions"`
+	RecentCalls []RecentToolCall `json:"recent_calls"` // Newest first
+	Caches      CacheStats       `json:"caches"`
+	Firefly     FireflyStatus    `json:"firefly"`
+}
+
+// SessionStatus is a connected MCP session with its tool call statistics
+type SessionStatus struct {
+	AdminSession
+	StartedAt string `json:"started_at,omitempty"` // First tool call
+	ToolCalls int    `json:"tool_calls"`
+	Errors    int    `json:"errors"`
+	Writes    int    `json:"writes"`
+}
+
+// CacheStats counts the entries of the in-memory caches
+type CacheStats struct {
+	ToolResults     int `json:"tool_results"`
+	Catalogs        int `json:"catalogs"`
+	FormattingHints int `json:"formatting_hints"`
+	AccountMetadata int `json:"account_metadata"`
+}
+
+// FireflyStatus is the connectivity of the Firefly III instance
+type FireflyStatus struct {
+	URL             string   `json:"url"`
+	Reachable       bool     `json:"reachable"`
+	Version         string   `json:"version,omitempty"` // Only known with a configured API token
+	ApiVersion      string   `json:"api_version,omitempty"`
+	Error           string   `json:"error,omitempty"`
+	ClockSkew       string   `json:"clock_skew,omitempty"`       // Firefly III time minus server time
+	UnavailableAPIs []string `json:"unavailable_apis,omitempty"` // Optional APIs found missing
+}
+
+// Status collects the state of the server and checks that Firefly III is reachable
+func (s *FireflyMCPServer) Status(ctx context.Context) ServerStatus {
+	config := s.currentConfig()
+	status := ServerStatus{
+		Name:        config.MCP.Name,
+		Version:     config.MCP.Version,
+		Transport:   "stdio",
+		StartedAt:   s.started.UTC(),
+		Uptime:      time.Since(s.started).Round(time.Second).String(),
+		ReadOnly:    s.ReadOnly(),
+		Sessions:    []SessionStatus{},
+		RecentCalls: []RecentToolCall{},
+	}
+	if config.HTTP.Enabled {
+		status.Transport = "http"
+	}
+
+	for _, session := range s.Sessions() {
+		entry := SessionStatus{AdminSession: session}
+		if s.sessionStats != nil {
+			stats := s.sessionStats.get(session.ID)
+			entry.StartedAt = stats.StartedAt
+			entry.ToolCalls = stats.ToolCalls
+			entry.Errors = stats.Errors
+			entry.Writes = stats.Writes
+		}
+		status.Sessions = append(status.Sessions, entry)
+	}
+	if s.sessionStats != nil {
+		status.RecentCalls = s.sessionStats.recentCalls()
+	}
+
+	if s.toolCache != nil {
+		status.Caches.ToolResults = s.toolCache.size()
+	}
+	if s.catalogs != nil {
+		status.Caches.Catalogs = s.catalogs.size()
+	}
+	if s.formatting != nil {
+		status.Caches.FormattingHints = s.formatting.size()
+	}
+	if s.accountMetadataCache != nil {
+		status.Caches.AccountMetadata = s.accountMetadataCache.size()
+	}
+
+	status.Firefly = s.fireflyStatus(ctx, config)
+	return status
+}
+
+// fireflyStatus checks the connection to Firefly III, within statusCheckTimeout
+func (s *FireflyMCPServer) fireflyStatus(ctx context.Context, config *Config) FireflyStatus {
+	status := FireflyStatus{URL: config.Server.URL}
+	checkConfig := *config
+	if checkConfig.Client.Timeout <= 0 || time.Duration(checkConfig.Client.Timeout)*time.Second > statusCheckTimeout {
+		checkConfig.Client.Timeout = int(statusCheckTimeout / time.Second)
+	}
+	info, err := CheckServerURL(ctx, &checkConfig)
+	if err != nil {
+		status.Error = err.Error()
+	} else {
+		status.Reachable = true
+		if info != nil {
+			status.Version = info.Version
+			status.ApiVersion = info.ApiVersion
+		}
+	}
+	if s.clock != nil {
+		if skew, known := s.clock.offset(); known {
+			status.ClockSkew = skew.Round(time.Second).String()
+		}
+	}
+	if s.capabilities != nil {
+		status.UnavailableAPIs = s.capabilities.unavailableNames()
+	}
+	return status
+}
+
+// dashboardTemplate renders a ServerStatus as the admin dashboard page
+var dashboardTemplate = template.Must(template.New("dashboard").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="30">
+<title>{{.Name}} status</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; }
+th { background: #f3f3f3; }
+.ok { color: #1a7f37; }
+.error { color: #cf222e; }
+</style>
+</head>
+<body>
+<h1>{{.Name}} {{.Version}}</h1>
+<table>
+<tr><th>Transport</th><td>{{.Transport}}</td></tr>
+<tr><th>Started</th><td>{{.StartedAt.Format "2006-01-02 15:04:05 MST"}} (up {{.Uptime}})</td></tr>
+<tr><th>Read-only mode</th><td>{{if .ReadOnly}}on{{else}}off{{end}}</td></tr>
+</table>
+
+<h2>Firefly III</h2>
+<table>
+<tr><th>URL</th><td>{{.Firefly.URL}}</td></tr>
+<tr><th>Connectivity</th><td>{{if .Firefly.Reachable}}<span class="ok">reachable</span>{{else}}<span class="error">{{.Firefly.Error}}</span>{{end}}</td></tr>
+{{if .Firefly.Version}}<tr><th>Version</th><td>{{.Firefly.Version}} (API {{.Firefly.ApiVersion}})</td></tr>{{end}}
+{{if .Firefly.ClockSkew}}<tr><th>Clock skew</th><td>{{.Firefly.ClockSkew}}</td></tr>{{end}}
+{{if .Firefly.UnavailableAPIs}}<tr><th>Unavailable APIs</th><td>{{range $i, $name := .Firefly.UnavailableAPIs}}{{if $i}}, {{end}}{{$name}}{{end}}</td></tr>{{end}}
+</table>
+
+<h2>Sessions ({{len .Sessions}})</h2>
+{{if .Sessions}}<table>
+<tr><th>ID</th><th>Client</th><th>First call</th><th>Tool calls</th><th>Errors</th><th>Writes</th></tr>
+{{range .Sessions}}<tr><td>{{.ID}}</td><td>{{.ClientName}} {{.ClientVersion}}</td><td>{{.StartedAt}}</td><td>{{.ToolCalls}}</td><td>{{.Errors}}</td><td>{{.Writes}}</td></tr>
+{{end}}</table>{{else}}<p>No connected sessions.</p>{{end}}
+
+<h2>Recent tool calls</h2>
+{{if .RecentCalls}}<table>
+<tr><th>Time</th><th>Tool</th><th>Session</th><th>Duration</th><th>Result</th></tr>
+{{range .RecentCalls}}<tr><td>{{.Time.Format "15:04:05"}}</td><td>{{.Tool}}</td><td>{{.SessionId}}</td><td>{{.DurationMs}} ms</td><td>{{if .Error}}<span class="error">error</span>{{else}}<span class="ok">ok</span>{{end}}</td></tr>
+{{end}}</table>{{else}}<p>No tool calls yet.</p>{{end}}
+
+<h2>Caches</h2>
+<table>
+<tr><th>Tool results</th><td>{{.Caches.ToolResults}}</td></tr>
+<tr><th>Name catalogs</th><td>{{.Caches.Catalogs}}</td></tr>
+<tr><th>Formatting hints</th><td>{{.Caches.FormattingHints}}</td></tr>
+<tr><th>Account metadata</th><td>{{.Caches.AccountMetadata}}</td></tr>
+</table>
+</body>
+</html>
+`))
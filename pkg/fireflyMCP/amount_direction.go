@@ -0,0 +1,173 @@
+package fireflyMCP
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Directions of money flows (formatting.amount_direction)
+const (
+	directionIn  = "in"
+	directionOut = "out"
+)
+
+// signedAmountLists are the keys of lists whose entries carry signed amounts:
+// insight entries and totals (expenses negative, income positive), net changes
+// per day and the spent of budgets (negative)
+var signedAmountLists = map[string]bool{
+	"entries": true, "totals": true, "net": true, "spent": true, "spent_totals": true,
+}
+
+// signedAmountShapes are the fields of insight entries and of budget spent; only
+// list entries with no other fields are rewritten
+var signedAmountShapes = []map[string]bool{
+	{"id": true, "name": true, "amount": true, "currency_code": true, "share": true},
+	{"sum": true, "currency_code": true, "currency_symbol": true},
+}
+
+// amountDirectionEnabled reports whether results carry absolute amounts with a direction
+func (s *FireflyMCPServer) amountDirectionEnabled() bool {
+	config := s.currentConfig()
+	return config != nil && config.Formatting.AmountDirection
+}
+
+// withAmountDirections rewrites the results of a read-only tool to the unified
+// amount convention when formatting.amount_direction is on: every money flow is
+// an absolute amount with direction in or out. Without it, insights keep signed
+// differences, budgets a negative spent and transactions an unsigned amount with
+// a type, as Firefly III returns them.
+func withAmountDirections[In any](s *FireflyMCPServer, tool *mcp.Tool, handler mcp.ToolHandlerFor[In, any]) mcp.ToolHandlerFor[In, any] {
+	if tool.Annotations == nil || !tool.Annotations.ReadOnlyHint {
+		return handler
+	}
+	return func(ctx context.Context, req *mcp.CallToolRequest, args In) (*mcp.CallToolResult, any, error) {
+		result, out, err := handler(ctx, req, args)
+		if err != nil || result == nil || result.IsError || !s.amountDirectionEnabled() {
+			return result, out, err
+		}
+		for _, content := range result.Content {
+			if text, ok := content.(*mcp.TextContent); ok {
+				text.Text = directAmountsJSON(text.Text)
+			}
+		}
+		return result, out, err
+	}
+}
+
+// directAmountsJSON applies the amount convention to a JSON document. Text that
+// is not JSON, or has no money flows, is returned unchanged.
+func directAmountsJSON(text string) string {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(text)))
+	decoder.UseNumber()
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return text
+	}
+	if !directAmounts(document, false) {
+		return text
+	}
+	encoded, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return text
+	}
+	return string(encoded)
+}
+
+// directAmounts rewrites decoded JSON in place and reports whether anything
+// changed. signed is set for the entries of lists with signed amounts.
+func directAmounts(value any, signed bool) bool {
+	changed := false
+	switch v := value.(type) {
+	case map[string]any:
+		if signed && hasSignedAmountShape(v) {
+			field := "amount"
+			if _, ok := v["sum"]; ok {
+				field = "sum"
+			}
+			changed = directBySign(v, field) || changed
+		}
+		changed = directTransaction(v) || changed
+		changed = directSummary(v) || changed
+		for key, item := range v {
+			changed = directAmounts(item, signedAmountLists[key]) || changed
+		}
+	case []any:
+		for _, item := range v {
+			changed = directAmounts(item, signed) || changed
+		}
+	}
+	return changed
+}
+
+// hasSignedAmountShape reports whether an object has only the fields of an
+// insight entry or of a budget spent
+func hasSignedAmountShape(object map[string]any) bool {
+	for _, shape := range signedAmountShapes {
+		matches := true
+		for key := range object {
+			if !shape[key] && key != "direction" {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+// directTransaction sets the direction of withdrawals (out) and deposits (in).
+// Transfers, opening balances and reconciliations move money between the
+// user's own accounts and get no direction.
+func directTransaction(object map[string]any) bool {
+	kind, _ := object["type"].(string)
+	_, directed := object["direction"]
+	if _, ok := object["amount"].(string); !ok || directed {
+		return false
+	}
+	switch kind {
+	case "withdrawal":
+		object["direction"] = directionOut
+	case "deposit":
+		object["direction"] = directionIn
+	default:
+		return false
+	}
+	object["amount"] = strings.TrimPrefix(object["amount"].(string), "-")
+	return true
+}
+
+// directSummary sets the direction of the spent (out) and earned (in) entries
+// of the basic summary. Balances, net worth and left-to-spend are not flows.
+func directSummary(object map[string]any) bool {
+	key, _ := object["key"].(string)
+	if _, ok := object["monetary_value"].(string); !ok ||
+		!(strings.HasPrefix(key, "spent-") || strings.HasPrefix(key, "earned-")) {
+		return false
+	}
+	return directBySign(object, "monetary_value")
+}
+
+// directBySign makes a signed amount absolute and sets its direction: negative
+// amounts flow out, positive ones in, and zero has no direction. Amounts that
+// already have a direction, e.g. in results of tools called by a report, are kept.
+func directBySign(object map[string]any, field string) bool {
+	amount, ok := object[field].(string)
+	if _, directed := object["direction"]; !ok || directed || strings.Trim(amount, "+-0.") == "" {
+		return false
+	}
+	switch {
+	case strings.HasPrefix(amount, "-"):
+		object[field] = strings.TrimPrefix(amount, "-")
+		object["direction"] = directionOut
+	default:
+		object[field] = strings.TrimPrefix(amount, "+")
+		object["direction"] = directionIn
+	}
+	return true
+}
@@ -0,0 +1,92 @@
+package fireflyMCP
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirectAmountsJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			"transactions",
+			`{"data":[{"id":"1","transactions":[` +
+				`{"type":"withdrawal","amount":"12.50"},{"type":"deposit","amount":"100.00"},{"type":"transfer","amount":"5.00"}]}]}`,
+			`{"data":[{"id":"1","transactions":[` +
+				`{"type":"withdrawal","amount":"12.50","direction":"out"},{"type":"deposit","amount":"100.00","direction":"in"},` +
+				`{"type":"transfer","amount":"5.00"}]}]}`,
+		},
+		{
+			"insights",
+			`{"entries":[{"id":"3","name":"Food","amount":"-300.00","currency_code":"EUR","share":"75.00"},` +
+				`{"id":"4","name":"Refunds","amount":"0.00","currency_code":"EUR"}],` +
+				`"totals":[{"amount":"-300.00","currency_code":"EUR"}]}`,
+			`{"entries":[{"id":"3","name":"Food","amount":"300.00","currency_code":"EUR","share":"75.00","direction":"out"},` +
+				`{"id":"4","name":"Refunds","amount":"0.00","currency_code":"EUR"}],` +
+				`"totals":[{"amount":"300.00","currency_code":"EUR","direction":"out"}]}`,
+		},
+		{
+			"budget spent",
+			`{"data":[{"id":"1","name":"Groceries","spent":[{"sum":"-42.10","currency_code":"EUR","currency_symbol":"€"}]}]}`,
+			`{"data":[{"id":"1","name":"Groceries","spent":[{"sum":"42.10","currency_code":"EUR","currency_symbol":"€","direction":"out"}]}]}`,
+		},
+		{
+			"summary",
+			`{"data":[{"key":"spent-in-EUR","monetary_value":"-80.00"},{"key":"earned-in-EUR","monetary_value":"2000.00"},` +
+				`{"key":"balance-in-EUR","monetary_value":"-12.00"}],"trend":[{"date":"2024-03-01","net":[{"amount":"-5.00","currency_code":"EUR"}]}]}`,
+			`{"data":[{"key":"spent-in-EUR","monetary_value":"80.00","direction":"out"},` +
+				`{"key":"earned-in-EUR","monetary_value":"2000.00","direction":"in"},{"key":"balance-in-EUR","monetary_value":"-12.00"}],` +
+				`"trend":[{"date":"2024-03-01","net":[{"amount":"5.00","currency_code":"EUR","direction":"out"}]}]}`,
+		},
+		{
+			"other amounts and applied twice",
+			`{"entries":[{"budget_id":"1","amount":"-5.00","currency_code":"EUR"}],` +
+				`"totals":[{"amount":"5.00","currency_code":"EUR","direction":"out"}],"limit":{"amount":"200.00"}}`,
+			`{"entries":[{"budget_id":"1","amount":"-5.00","currency_code":"EUR"}],` +
+				`"totals":[{"amount":"5.00","currency_code":"EUR","direction":"out"}],"limit":{"amount":"200.00"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.JSONEq(t, tt.expected, directAmountsJSON(tt.input))
+		})
+	}
+	assert.Equal(t, "not json", directAmountsJSON("not json"))
+}
+
+func TestAmountDirection_Tools(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"id":"1","name":"Food","difference":"-300.00","difference_float":-300,"currency_code":"EUR"}]`))
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	call := func() string {
+		result, err := server.tools["expense_category_insights"].invoke(context.Background(), nil,
+			[]byte(`{"start":"2024-03-01","end":"2024-03-31"}`))
+		require.NoError(t, err)
+		require.False(t, result.IsError, result.Content[0].(*mcp.TextContent).Text)
+		return result.Content[0].(*mcp.TextContent).Text
+	}
+	assert.Contains(t, call(), `"amount": "-300.00"`, "signed amounts without the setting")
+	assert.NotContains(t, call(), "direction")
+
+	config.Formatting.AmountDirection = true
+	text := call()
+	assert.Contains(t, text, `"amount": "300.00"`)
+	assert.Contains(t, text, `"direction": "out"`)
+}
@@ -0,0 +1,45 @@
+package fireflyMCP
+
+import "github.com/modelcontextprotocol/go-sdk/mcp"
+
+// Tool annotations tell clients how a tool affects Firefly III so they can apply
+// their own confirmation UX (e.g. asking before destructive calls). All tools
+// only talk to the configured Firefly III instance, so none is open-world.
+
+// readOnlyAnnotations describes a tool that does not modify any data
+func readOnlyAnnotations() *mcp.ToolAnnotations {
+	return &mcp.ToolAnnotations{
+		ReadOnlyHint:  true,
+		OpenWorldHint: boolPtr(false),
+	}
+}
+
+// additiveAnnotations describes a tool that creates new data without touching
+// existing data. Calling it twice creates duplicates.
+func additiveAnnotations() *mcp.ToolAnnotations {
+	return &mcp.ToolAnnotations{
+		DestructiveHint: boolPtr(false),
+		OpenWorldHint:   boolPtr(false),
+	}
+}
+
+// destructiveAnnotations describes a tool that modifies or deletes existing data.
+// idempotent reports whether repeating the call with the same arguments has no
+// additional effect.
+func destructiveAnnotations(idempotent bool) *mcp.ToolAnnotations {
+	return &mcp.ToolAnnotations{
+		DestructiveHint: boolPtr(true),
+		IdempotentHint:  idempotent,
+		OpenWorldHint:   boolPtr(false),
+	}
+}
+
+// isReadOnlyTool reports whether a registered tool is annotated as read-only
+func (s *FireflyMCPServer) isReadOnlyTool(name string) bool {
+	registered, ok := s.tools[name]
+	return ok && registered.tool.Annotations != nil && registered.tool.Annotations.ReadOnlyHint
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,102 @@
+package fireflyMCP
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolAnnotations(t *testing.T) {
+	withPlugins(t)
+	config := newPluginTestConfig()
+	config.Trash.Enabled = true
+	config.Demo.Enabled = true
+	config.Proxy.Enabled = true
+	config.Sampling.Enabled = true
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	writeTools := map[string]struct {
+		destructive bool
+		idempotent  bool
+	}{
+		"store_transaction":            {destructive: false, idempotent: false},
+		"store_transactions_bulk":      {destructive: false, idempotent: false},
+		"transfer_to_piggy":            {destructive: false, idempotent: false},
+		"plan_savings_goal":            {destructive: false, idempotent: false},
+		"store_planned_transaction":    {destructive: false, idempotent: false},
+		"confirm_planned":              {destructive: true, idempotent: true},
+		"update_transaction":           {destructive: true, idempotent: true},
+		"tag_transactions":             {destructive: true, idempotent: true},
+		"create_rule_group":            {destructive: false, idempotent: false},
+		"update_rule_group":            {destructive: true, idempotent: true},
+		"delete_rule_group":            {destructive: true, idempotent: true},
+		"trigger_rule_group":           {destructive: true, idempotent: false},
+		"create_rule":                  {destructive: false, idempotent: false},
+		"update_rule":                  {destructive: true, idempotent: true},
+		"delete_rule":                  {destructive: true, idempotent: true},
+		"trigger_rule":                 {destructive: true, idempotent: false},
+		"budget_rollover":              {destructive: true, idempotent: true},
+		"seed_budget_limits":           {destructive: true, idempotent: true},
+		"allocate_remaining":           {destructive: true, idempotent: false},
+		"move_budget_amount":           {destructive: true, idempotent: false},
+		"store_budget":                 {destructive: false, idempotent: false},
+		"update_budget":                {destructive: true, idempotent: true},
+		"link_transaction_to_bill":     {destructive: true, idempotent: true},
+		"unlink_transaction_from_bill": {destructive: true, idempotent: true},
+		"restore_from_trash":           {destructive: true, idempotent: true},
+		"purge_trash":                  {destructive: true, idempotent: true},
+		"generate_demo_data":           {destructive: false, idempotent: false},
+	}
+
+	for name, registered := range server.tools {
+		t.Run(name, func(t *testing.T) {
+			annotations := registered.tool.Annotations
+			require.NotNil(t, annotations, "every tool must be annotated")
+			require.NotNil(t, annotations.OpenWorldHint)
+			assert.False(t, *annotations.OpenWorldHint)
+
+			expected, isWrite := writeTools[name]
+			if !isWrite {
+				assert.True(t, annotations.ReadOnlyHint, "tool should be read-only")
+				return
+			}
+			assert.False(t, annotations.ReadOnlyHint)
+			require.NotNil(t, annotations.DestructiveHint)
+			assert.Equal(t, expected.destructive, *annotations.DestructiveHint)
+			assert.Equal(t, expected.idempotent, annotations.IdempotentHint)
+		})
+	}
+
+	for name := range writeTools {
+		assert.True(t, server.hasTool(name), name)
+	}
+}
+
+func TestReadOnlyToolsHaveReadOnlyNames(t *testing.T) {
+	withPlugins(t)
+	server, err := NewFireflyMCPServer(newPluginTestConfig())
+	require.NoError(t, err)
+
+	for name := range server.tools {
+		if strings.HasPrefix(name, "delete_") || strings.HasPrefix(name, "update_") ||
+			strings.HasPrefix(name, "store_") || strings.HasPrefix(name, "create_") {
+			assert.False(t, server.isReadOnlyTool(name), name)
+		}
+	}
+}
+
+func TestReportAnnotations(t *testing.T) {
+	withPlugins(t)
+	server, err := NewFireflyMCPServer(newReportTestConfig(
+		ReportDefinition{Name: "read_report", Steps: []ReportStep{{Tool: "get_summary"}}},
+		ReportDefinition{Name: "write_report", Steps: []ReportStep{{Tool: "get_summary"}, {Tool: "trigger_rule"}}},
+	))
+	require.NoError(t, err)
+
+	assert.True(t, server.isReadOnlyTool("read_report"))
+	assert.False(t, server.isReadOnlyTool("write_report"))
+	assert.True(t, *server.tools["write_report"].tool.Annotations.DestructiveHint)
+}
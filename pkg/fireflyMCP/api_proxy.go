@@ -0,0 +1,134 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/dezer32/mcp-firefly-iii/pkg/client"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxProxyResponseSize is the largest response firefly_api_request returns
+const maxProxyResponseSize = 5 << 20
+
+// defaultProxyAllowedPaths are the endpoints firefly_api_request may read when
+// proxy.allowed_paths is not set: the financial data, without users,
+// preferences, webhooks or data exports
+var defaultProxyAllowedPaths = []string{
+	"/v1/about", "/v1/accounts", "/v1/attachments", "/v1/autocomplete", "/v1/available-budgets",
+	"/v1/bills", "/v1/budgets", "/v1/budget-limits", "/v1/categories", "/v1/chart", "/v1/currencies",
+	"/v1/exchange-rates", "/v1/insight", "/v1/object-groups", "/v1/piggy-banks", "/v1/recurrences",
+	"/v1/rule-groups", "/v1/rules", "/v1/search", "/v1/summary", "/v1/tags", "/v1/transactions",
+}
+
+// FireflyAPIRequestArgs represents the arguments for the firefly_api_request tool
+type FireflyAPIRequestArgs struct {
+	Path  string            `json:"path" jsonschema:"API path below the Firefly III API URL, e.g. /v1/accounts/1/piggy-banks (required)"`
+	Query map[string]string `json:"query,omitempty" jsonschema:"Query parameters, e.g. {\"page\": \"2\"}"`
+}
+
+// handleFireflyAPIRequest sends a GET request to an allowed Firefly III API
+// path and returns the JSON response as it is
+func (s *FireflyMCPServer) handleFireflyAPIRequest(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args FireflyAPIRequestArgs,
+) (*mcp.CallToolResult, any, error) {
+	apiPath, err := s.checkProxyPath(args.Path)
+	if err != nil {
+		return newErrorResult(err.Error())
+	}
+
+	apiClient, err := s.getClient(ctx, req)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Failed to get API client: %v", err))
+	}
+	raw, ok := apiClient.ClientInterface.(*client.Client)
+	if !ok {
+		return newErrorResult("Error: the API client does not support raw requests")
+	}
+
+	target, err := url.Parse(raw.Server)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error: invalid server URL: %v", err))
+	}
+	target = target.JoinPath(apiPath)
+	query := url.Values{}
+	for key, value := range args.Query {
+		query.Set(key, value)
+	}
+	target.RawQuery = query.Encode()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error creating request: %v", err))
+	}
+	for _, edit := range raw.RequestEditors {
+		if err := edit(ctx, httpReq); err != nil {
+			return newErrorResult(fmt.Sprintf("Error creating request: %v", err))
+		}
+	}
+	resp, err := raw.Client.Do(httpReq)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error requesting %s: %v", apiPath, err))
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxProxyResponseSize+1))
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error reading response: %v", err))
+	}
+
+	if !isSuccessStatus(resp.StatusCode) {
+		return newErrorResult(fmt.Sprintf("API error %d: %s", resp.StatusCode, s.upstreamError(body)))
+	}
+	if len(body) > maxProxyResponseSize {
+		return newErrorResult(fmt.Sprintf("Error: the response is larger than %d bytes; narrow it down with query parameters such as limit", maxProxyResponseSize))
+	}
+	if !json.Valid(body) {
+		return newErrorResult(fmt.Sprintf("Error: %s did not return JSON (content type %s)", apiPath, resp.Header.Get("Content-Type")))
+	}
+	return newSuccessResult(json.RawMessage(body))
+}
+
+// checkProxyPath returns the decoded and cleaned API path, or an error if it is
+// not below one of the allowed paths (proxy.allowed_paths). Percent-encoding is
+// decoded first, so encoded dot segments such as %2e%2e cannot escape the
+// allowed paths.
+func (s *FireflyMCPServer) checkProxyPath(apiPath string) (string, error) {
+	if apiPath == "" {
+		return "", fmt.Errorf("Error: path is required")
+	}
+	decoded, err := url.PathUnescape(apiPath)
+	if err != nil {
+		return "", fmt.Errorf("Error: invalid path %s: %v", apiPath, err)
+	}
+	apiPath = decoded
+	if strings.ContainsAny(apiPath, "?#") {
+		return "", fmt.Errorf("Error: pass query parameters in query, not in path")
+	}
+	if !strings.HasPrefix(apiPath, "/") {
+		apiPath = "/" + apiPath
+	}
+	cleaned := path.Clean(apiPath)
+	if cleaned != strings.TrimSuffix(apiPath, "/") {
+		return "", fmt.Errorf("Error: path %s must not contain . or .. segments", apiPath)
+	}
+
+	allowed := defaultProxyAllowedPaths
+	if config := s.currentConfig(); config != nil && len(config.Proxy.AllowedPaths) > 0 {
+		allowed = config.Proxy.AllowedPaths
+	}
+	for _, prefix := range allowed {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if cleaned == prefix || strings.HasPrefix(cleaned, prefix+"/") {
+			return cleaned, nil
+		}
+	}
+	return "", fmt.Errorf("Error: path %s is not allowed (proxy.allowed_paths: %s)", cleaned, strings.Join(allowed, ", "))
+}
@@ -0,0 +1,77 @@
+package fireflyMCP
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFireflyAPIRequest(t *testing.T) {
+	var requests []*http.Request
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		switch r.URL.Path {
+		case "/api/v1/accounts/1/piggy-banks":
+			w.Header().Set("Content-Type", "application/vnd.api+json")
+			w.Write([]byte(`{"data":[{"type":"piggy_banks","id":"7","attributes":{"name":"Holiday"}}]}`))
+		case "/api/v1/about":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<html>login</html>`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"Resource not found"}`))
+		}
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL + "/api"
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+	assert.NotContains(t, server.tools, "firefly_api_request", "disabled by default")
+
+	config.Proxy.Enabled = true
+	server, err = NewFireflyMCPServer(config)
+	require.NoError(t, err)
+	call := func(arguments string) (string, bool) {
+		result, err := server.tools["firefly_api_request"].invoke(context.Background(), nil, []byte(arguments))
+		require.NoError(t, err)
+		return result.Content[0].(*mcp.TextContent).Text, result.IsError
+	}
+
+	text, isError := call(`{"path":"/v1/accounts/1/piggy-banks","query":{"page":"2"}}`)
+	require.False(t, isError, text)
+	assert.JSONEq(t, `{"data":[{"type":"piggy_banks","id":"7","attributes":{"name":"Holiday"}}]}`, text)
+	require.Len(t, requests, 1)
+	assert.Equal(t, http.MethodGet, requests[0].Method)
+	assert.Equal(t, "2", requests[0].URL.Query().Get("page"))
+	assert.Equal(t, "Bearer token", requests[0].Header.Get("Authorization"))
+
+	text, isError = call(`{"path":"/v1/accounts/99"}`)
+	assert.True(t, isError)
+	assert.Contains(t, text, "API error 404")
+
+	text, isError = call(`{"path":"/v1/about"}`)
+	assert.True(t, isError)
+	assert.Contains(t, text, "did not return JSON")
+
+	for _, path := range []string{"/v1/users", "/v1/data/export/transactions", "/v1/accounts/../users", "/v1/accounts/%2e%2e/users",
+		"/v1/accounts/%2E%2E%2Fusers", "/v1/accounts/%zz", "/v1/accountsx", "/v1/accounts?page=2"} {
+		text, isError = call(`{"path":"` + path + `"}`)
+		assert.True(t, isError, path)
+		assert.Contains(t, text, "path", path)
+	}
+	assert.Len(t, requests, 3, "refused paths are not requested")
+
+	config.Proxy.AllowedPaths = []string{"/v1/users/"}
+	text, isError = call(`{"path":"v1/users"}`)
+	assert.True(t, isError)
+	assert.Contains(t, text, "API error 404", "allowed paths are configurable")
+	text, _ = call(`{"path":"/v1/accounts/1/piggy-banks"}`)
+	assert.Contains(t, text, "not allowed")
+}
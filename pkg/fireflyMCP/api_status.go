@@ -0,0 +1,65 @@
+package fireflyMCP
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+)
+
+// isSuccessStatus reports whether a Firefly III write succeeded. Deletes and
+// some updates answer 204 without a body, others 200 with the object; proxies
+// may also turn a 204 into a 200 with an empty body.
+func isSuccessStatus(status int) bool {
+	return status >= http.StatusOK && status < http.StatusMultipleChoices
+}
+
+// isEmptyBody reports whether a response carries no content to decode
+func isEmptyBody(status int, body []byte) bool {
+	return status == http.StatusNoContent || len(bytes.TrimSpace(body)) == 0
+}
+
+// writeResponseBody returns the object of a successful write response. The
+// generated client only decodes status 200, so other 2xx bodies such as a 201
+// are decoded here. It returns nil for an empty body.
+func writeResponseBody[T any](status int, parsed *T, body []byte) (*T, error) {
+	if parsed != nil {
+		return parsed, nil
+	}
+	if isEmptyBody(status, body) {
+		return nil, nil
+	}
+	var decoded T
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return nil, err
+	}
+	return &decoded, nil
+}
+
+// WriteResult is the result of a write tool whose Firefly III response has no
+// body to return, such as a delete
+type WriteResult struct {
+	Id        string `json:"id"`
+	WebURL    string `json:"web_url,omitempty" jsonschema:"With formatting.web_links: page of the entity in the Firefly III web UI"`
+	Status    string `json:"status"` // created, deleted, updated or triggered
+	Created   bool   `json:"created,omitempty"`
+	Deleted   bool   `json:"deleted,omitempty"`
+	Updated   bool   `json:"updated,omitempty"`
+	Triggered bool   `json:"triggered,omitempty"`
+	Message   string `json:"message,omitempty"`
+}
+
+func createdResult() WriteResult {
+	return WriteResult{Status: "created", Created: true}
+}
+
+func deletedResult(id string) WriteResult {
+	return WriteResult{Id: id, Status: "deleted", Deleted: true}
+}
+
+func updatedResult(id string) WriteResult {
+	return WriteResult{Id: id, Status: "updated", Updated: true}
+}
+
+func triggeredResult(id, message string) WriteResult {
+	return WriteResult{Id: id, Status: "triggered", Triggered: true, Message: message}
+}
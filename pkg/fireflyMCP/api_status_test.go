@@ -0,0 +1,110 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsSuccessStatus(t *testing.T) {
+	assert.True(t, isSuccessStatus(200))
+	assert.True(t, isSuccessStatus(204))
+	assert.False(t, isSuccessStatus(302))
+	assert.False(t, isSuccessStatus(404))
+
+	assert.True(t, isEmptyBody(204, []byte("ignored")))
+	assert.True(t, isEmptyBody(200, []byte(" \n")))
+	assert.False(t, isEmptyBody(200, []byte(`{"data":{}}`)))
+}
+
+func TestWriteTools_EmptyBodyResponses(t *testing.T) {
+	for _, status := range []int{http.StatusNoContent, http.StatusOK} {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// No body, as for deletes or a proxy rewriting 204 to 200
+			w.WriteHeader(status)
+		}))
+		config := newPluginTestConfig()
+		config.Server.URL = ts.URL
+		server, err := NewFireflyMCPServer(config)
+		require.NoError(t, err)
+		ctx := context.Background()
+
+		decode := func(result *mcp.CallToolResult) WriteResult {
+			require.False(t, result.IsError, result.Content[0].(*mcp.TextContent).Text)
+			var written WriteResult
+			require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &written))
+			return written
+		}
+
+		result, _, err := server.handleDeleteRule(ctx, nil, DeleteRuleArgs{ID: "3"})
+		require.NoError(t, err)
+		assert.Equal(t, WriteResult{Id: "3", Status: "deleted", Deleted: true}, decode(result), "status %d", status)
+
+		result, _, err = server.handleUpdateRule(ctx, nil, UpdateRuleArgs{ID: "3", RuleUpdateRequest: RuleUpdateRequest{Title: ptr("Renamed")}})
+		require.NoError(t, err)
+		assert.Equal(t, WriteResult{Id: "3", Status: "updated", Updated: true}, decode(result), "status %d", status)
+
+		result, _, err = server.handleTriggerRule(ctx, nil, TriggerRuleArgs{ID: "3"})
+		require.NoError(t, err)
+		assert.True(t, decode(result).Triggered, "status %d", status)
+
+		result, _, err = server.handleUpdateTransaction(ctx, nil, UpdateTransactionArgs{ID: "40", TransactionUpdateRequest: TransactionUpdateRequest{
+			Transactions: []TransactionSplitRequest{{Description: "Renamed"}},
+		}})
+		require.NoError(t, err)
+		assert.Equal(t, WriteResult{Id: "40", Status: "updated", Updated: true}, decode(result), "status %d", status)
+		ts.Close()
+	}
+}
+
+func TestWriteTools_CreatedResponses(t *testing.T) {
+	body := ""
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if body == "" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(body))
+	}))
+	defer ts.Close()
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+	ctx := context.Background()
+	text := func(result *mcp.CallToolResult) string {
+		require.False(t, result.IsError, result.Content[0].(*mcp.TextContent).Text)
+		return result.Content[0].(*mcp.TextContent).Text
+	}
+
+	// 201 with the object, which the generated client leaves undecoded
+	body = `{"data":{"type":"budgets","id":"8","attributes":{"name":"Travel"}}}`
+	result, _, err := server.handleStoreBudget(ctx, nil, StoreBudgetArgs{BudgetStoreRequest{Name: "Travel"}})
+	require.NoError(t, err)
+	var budget Budget
+	require.NoError(t, json.Unmarshal([]byte(text(result)), &budget))
+	assert.Equal(t, "8", budget.Id)
+
+	body = `{"data":{"type":"rule_groups","id":"4","attributes":{"title":"Imports"}}}`
+	result, _, err = server.handleCreateRuleGroup(ctx, nil, CreateRuleGroupArgs{RuleGroupStoreRequest{Title: "Imports"}})
+	require.NoError(t, err)
+	var group RuleGroup
+	require.NoError(t, json.Unmarshal([]byte(text(result)), &group))
+	assert.Equal(t, "4", group.Id)
+
+	// 204 without a body
+	body = ""
+	result, _, err = server.handleStoreBudget(ctx, nil, StoreBudgetArgs{BudgetStoreRequest{Name: "Travel"}})
+	require.NoError(t, err)
+	var written WriteResult
+	require.NoError(t, json.Unmarshal([]byte(text(result)), &written))
+	assert.Equal(t, WriteResult{Status: "created", Created: true}, written)
+}
@@ -0,0 +1,111 @@
+package fireflyMCP
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dezer32/mcp-firefly-iii/pkg/client"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// AssetInsightsArgs represents the arguments for the expense_asset_insights and
+// income_asset_insights tools
+type AssetInsightsArgs struct {
+	Accounts []string `json:"accounts,omitempty" jsonschema:"Asset account or liability IDs (or configured account aliases) to include; all when empty"`
+	DateRange
+}
+
+// insightAccountIDs converts account IDs (or configured aliases) to the int64
+// IDs of the insight endpoints
+func (s *FireflyMCPServer) insightAccountIDs(refs []string) (*[]int64, error) {
+	if len(refs) == 0 {
+		return nil, nil
+	}
+	accounts := make([]int64, len(refs))
+	for i, ref := range s.resolveAccountRefs(refs) {
+		if _, err := fmt.Sscanf(ref, "%d", &accounts[i]); err != nil {
+			return nil, fmt.Errorf("Invalid account ID: %s", ref)
+		}
+	}
+	return &accounts, nil
+}
+
+// handleExpenseAssetInsights returns expenses grouped by the asset account or
+// liability they were paid from
+func (s *FireflyMCPServer) handleExpenseAssetInsights(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args AssetInsightsArgs,
+) (*mcp.CallToolResult, any, error) {
+	dateRange, err := s.resolveFiscalPeriod(ctx, req, args.DateRange)
+	if err != nil {
+		return newErrorResult(err.Error())
+	}
+	dates, err := s.resolveDateRange(dateRange, dateRangeRequired)
+	if err != nil {
+		return newErrorResult(err.Error())
+	}
+	accounts, err := s.insightAccountIDs(args.Accounts)
+	if err != nil {
+		return newErrorResult(err.Error())
+	}
+
+	apiClient, err := s.getClient(ctx, req)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Failed to get API client: %v", err))
+	}
+
+	resp, err := apiClient.InsightExpenseAssetWithResponse(ctx, &client.InsightExpenseAssetParams{
+		Start:    *dates.Start,
+		End:      *dates.End,
+		Accounts: accounts,
+	})
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error getting expense asset insights: %v", err))
+	}
+	if resp.StatusCode() != 200 {
+		return newErrorResult(fmt.Sprintf("API error: %d", resp.StatusCode()))
+	}
+
+	return newSuccessResult(mapInsightGroupToDTO(resp.JSON200))
+}
+
+// handleIncomeAssetInsights returns income grouped by the asset account or
+// liability it was paid into
+func (s *FireflyMCPServer) handleIncomeAssetInsights(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args AssetInsightsArgs,
+) (*mcp.CallToolResult, any, error) {
+	dateRange, err := s.resolveFiscalPeriod(ctx, req, args.DateRange)
+	if err != nil {
+		return newErrorResult(err.Error())
+	}
+	dates, err := s.resolveDateRange(dateRange, dateRangeRequired)
+	if err != nil {
+		return newErrorResult(err.Error())
+	}
+	accounts, err := s.insightAccountIDs(args.Accounts)
+	if err != nil {
+		return newErrorResult(err.Error())
+	}
+
+	apiClient, err := s.getClient(ctx, req)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Failed to get API client: %v", err))
+	}
+
+	resp, err := apiClient.InsightIncomeAssetWithResponse(ctx, &client.InsightIncomeAssetParams{
+		Start:    *dates.Start,
+		End:      *dates.End,
+		Accounts: accounts,
+	})
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error getting income asset insights: %v", err))
+	}
+	if resp.StatusCode() != 200 {
+		return newErrorResult(fmt.Sprintf("API error: %d", resp.StatusCode()))
+	}
+
+	return newSuccessResult(mapInsightGroupToDTO(resp.JSON200))
+}
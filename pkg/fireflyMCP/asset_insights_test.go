@@ -0,0 +1,59 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssetInsights(t *testing.T) {
+	var paths, accounts []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		accounts = r.URL.Query()["accounts[]"]
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[` +
+			`{"id":"1","name":"Checking","difference":"-300.00","difference_float":-300,"currency_code":"EUR"},` +
+			`{"id":"4","name":"Credit card","difference":"-100.00","difference_float":-100,"currency_code":"EUR"}]`))
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	config.Accounts.Aliases = map[string]string{"card": "4"}
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	result, _, err := server.handleExpenseAssetInsights(ctx, nil, AssetInsightsArgs{
+		Accounts:  []string{"1", "card"},
+		DateRange: DateRange{Period: "this_month"},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content[0].(*mcp.TextContent).Text)
+	var insights InsightCategoryResponse
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &insights))
+	require.Len(t, insights.Entries, 2)
+	assert.Equal(t, "Checking", insights.Entries[0].Name)
+	assert.Equal(t, "75.00", insights.Entries[0].Share)
+	assert.Equal(t, []string{"1", "4"}, accounts)
+
+	_, _, err = server.handleIncomeAssetInsights(ctx, nil, AssetInsightsArgs{DateRange: DateRange{Period: "this_month"}})
+	require.NoError(t, err)
+	assert.Empty(t, accounts)
+	assert.Equal(t, []string{"/v1/insight/expense/asset", "/v1/insight/income/asset"}, paths)
+
+	result, _, err = server.handleExpenseAssetInsights(ctx, nil, AssetInsightsArgs{
+		Accounts:  []string{"checking"},
+		DateRange: DateRange{Period: "this_month"},
+	})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "Invalid account ID: checking")
+}
@@ -0,0 +1,142 @@
+package fireflyMCP
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dezer32/mcp-firefly-iii/pkg/client"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// autocompleteTypes lists the values accepted for the type argument of autocomplete
+var autocompleteTypes = []string{"bills", "tags", "piggy_banks", "transaction_types", "currencies", "rules"}
+
+// AutocompleteArgs represents the arguments for the autocomplete tool
+type AutocompleteArgs struct {
+	Type  string `json:"type" jsonschema:"What to autocomplete: bills, tags, piggy_banks, transaction_types, currencies or rules (required)"`
+	Query string `json:"query,omitempty" jsonschema:"Text the names should match (default: return the first items)"`
+	Limit int    `json:"limit,omitempty" jsonschema:"Maximum number of items to return"`
+}
+
+// AutocompleteItem is a normalized autocomplete match
+type AutocompleteItem struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// AutocompleteResult is the result of the autocomplete tool
+type AutocompleteResult struct {
+	Type  string             `json:"type"`
+	Items []AutocompleteItem `json:"items"`
+}
+
+// autocompleteResponse is the part of the generated autocomplete responses the tool needs
+type autocompleteResponse interface {
+	StatusCode() int
+}
+
+// handleAutocomplete looks up names of Firefly III objects through the
+// autocomplete endpoints and normalizes the matches to id/name pairs
+func (s *FireflyMCPServer) handleAutocomplete(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args AutocompleteArgs,
+) (*mcp.CallToolResult, any, error) {
+	if args.Type == "" {
+		return newErrorResult(fmt.Sprintf("Error: type is required (%s)", strings.Join(autocompleteTypes, ", ")))
+	}
+
+	apiClient, err := s.getClient(ctx, req)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Failed to get API client: %v", err))
+	}
+
+	var query *string
+	if args.Query != "" {
+		query = &args.Query
+	}
+	limit := s.limitParam("autocomplete", args.Limit)
+
+	var (
+		resp  autocompleteResponse
+		body  []byte
+		items []AutocompleteItem
+	)
+	switch args.Type {
+	case "bills":
+		r, err := apiClient.GetBillsACWithResponse(ctx, &client.GetBillsACParams{Query: query, Limit: limit})
+		if err != nil {
+			return newErrorResult(fmt.Sprintf("Error autocompleting bills: %v", err))
+		}
+		resp, body = r, r.Body
+		items = autocompleteItems(r.JSON200, func(bill client.AutocompleteBill) AutocompleteItem {
+			return AutocompleteItem{Id: bill.Id, Name: bill.Name}
+		})
+	case "tags":
+		r, err := apiClient.GetTagACWithResponse(ctx, &client.GetTagACParams{Query: query, Limit: limit})
+		if err != nil {
+			return newErrorResult(fmt.Sprintf("Error autocompleting tags: %v", err))
+		}
+		resp, body = r, r.Body
+		items = autocompleteItems(r.JSON200, func(tag client.AutocompleteTag) AutocompleteItem {
+			return AutocompleteItem{Id: tag.Id, Name: tag.Name}
+		})
+	case "piggy_banks":
+		r, err := apiClient.GetPiggiesACWithResponse(ctx, &client.GetPiggiesACParams{Query: query, Limit: limit})
+		if err != nil {
+			return newErrorResult(fmt.Sprintf("Error autocompleting piggy banks: %v", err))
+		}
+		resp, body = r, r.Body
+		items = autocompleteItems(r.JSON200, func(piggy client.AutocompletePiggy) AutocompleteItem {
+			return AutocompleteItem{Id: piggy.Id, Name: piggy.Name}
+		})
+	case "transaction_types":
+		r, err := apiClient.GetTransactionTypesACWithResponse(ctx, &client.GetTransactionTypesACParams{Query: query, Limit: limit})
+		if err != nil {
+			return newErrorResult(fmt.Sprintf("Error autocompleting transaction types: %v", err))
+		}
+		resp, body = r, r.Body
+		items = autocompleteItems(r.JSON200, func(transactionType client.AutocompleteTransactionType) AutocompleteItem {
+			return AutocompleteItem{Id: transactionType.Id, Name: transactionType.Name}
+		})
+	case "currencies":
+		r, err := apiClient.GetCurrenciesACWithResponse(ctx, &client.GetCurrenciesACParams{Query: query, Limit: limit})
+		if err != nil {
+			return newErrorResult(fmt.Sprintf("Error autocompleting currencies: %v", err))
+		}
+		resp, body = r, r.Body
+		items = autocompleteItems(r.JSON200, func(currency client.AutocompleteCurrency) AutocompleteItem {
+			return AutocompleteItem{Id: currency.Id, Name: fmt.Sprintf("%s (%s)", currency.Name, currency.Code)}
+		})
+	case "rules":
+		r, err := apiClient.GetRulesACWithResponse(ctx, &client.GetRulesACParams{Query: query, Limit: limit})
+		if err != nil {
+			return newErrorResult(fmt.Sprintf("Error autocompleting rules: %v", err))
+		}
+		resp, body = r, r.Body
+		items = autocompleteItems(r.JSON200, func(rule client.AutocompleteRule) AutocompleteItem {
+			return AutocompleteItem{Id: rule.Id, Name: rule.Name}
+		})
+	default:
+		return newErrorResult(fmt.Sprintf("Error: unknown type %q (expected one of: %s)",
+			args.Type, strings.Join(autocompleteTypes, ", ")))
+	}
+
+	if resp.StatusCode() != 200 {
+		return newErrorResult(fmt.Sprintf("API error %d: %s", resp.StatusCode(), s.upstreamError(body)))
+	}
+	return newSuccessResult(&AutocompleteResult{Type: args.Type, Items: items})
+}
+
+// autocompleteItems maps the matches of an autocomplete endpoint to id/name pairs
+func autocompleteItems[T any](matches *[]T, item func(T) AutocompleteItem) []AutocompleteItem {
+	items := []AutocompleteItem{}
+	if matches == nil {
+		return items
+	}
+	for _, match := range *matches {
+		items = append(items, item(match))
+	}
+	return items
+}
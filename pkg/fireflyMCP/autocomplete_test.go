@@ -0,0 +1,69 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutocomplete(t *testing.T) {
+	var limits []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limits = append(limits, r.URL.Query().Get("limit"))
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/autocomplete/tags":
+			assert.Equal(t, "hol", r.URL.Query().Get("query"))
+			w.Write([]byte(`[{"id":"4","name":"holiday","tag":"holiday"}]`))
+		case "/v1/autocomplete/currencies":
+			w.Write([]byte(`[{"id":"1","name":"Euro","code":"EUR","symbol":"€","decimal_places":2}]`))
+		case "/v1/autocomplete/rules":
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"message":"boom"}`))
+		default:
+			w.Write([]byte(`[]`))
+		}
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	config.Limits.Search = 25
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	autocomplete := func(args AutocompleteArgs) (*mcp.CallToolResult, AutocompleteResult) {
+		result, _, err := server.handleAutocomplete(context.Background(), nil, args)
+		require.NoError(t, err)
+		var decoded AutocompleteResult
+		if !result.IsError {
+			require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &decoded))
+		}
+		return result, decoded
+	}
+
+	_, tags := autocomplete(AutocompleteArgs{Type: "tags", Query: "hol", Limit: 5})
+	assert.Equal(t, []AutocompleteItem{{Id: "4", Name: "holiday"}}, tags.Items)
+
+	_, currencies := autocomplete(AutocompleteArgs{Type: "currencies"})
+	assert.Equal(t, []AutocompleteItem{{Id: "1", Name: "Euro (EUR)"}}, currencies.Items)
+	assert.Equal(t, []string{"5", "25"}, limits)
+
+	_, bills := autocomplete(AutocompleteArgs{Type: "bills"})
+	assert.NotNil(t, bills.Items)
+	assert.Empty(t, bills.Items)
+
+	result, _ := autocomplete(AutocompleteArgs{Type: "rules"})
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "500")
+
+	result, _ = autocomplete(AutocompleteArgs{Type: "planets"})
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "piggy_banks")
+}
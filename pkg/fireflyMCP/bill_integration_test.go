@@ -70,10 +70,9 @@ func TestIntegration_ListBills(t *testing.T) {
 
 		// Create tool call arguments with date range
 		args := ListBillsArgs{
-			Start: "2024-01-01",
-			End:   "2024-12-31",
-			Limit: 10,
-			Page:  1,
+			DateRange: DateRange{Start: "2024-01-01", End: "2024-12-31"},
+			Limit:     10,
+			Page:      1,
 		}
 
 		ctx, cancel := context.WithTimeout(context.Background(), testConfig.Timeout)
@@ -119,7 +118,7 @@ func TestIntegration_GetBill(t *testing.T) {
 	}
 
 	if len(billList.Data) == 0 {
-		t.Skip("No bills found in the system")
+		skipWithoutFixtures(t, "No bills found in the system")
 	}
 
 	billID := billList.Data[0].Id
@@ -212,7 +211,7 @@ func TestIntegration_ListBillTransactions(t *testing.T) {
 	}
 
 	if len(billList.Data) == 0 {
-		t.Skip("No bills found in the system")
+		skipWithoutFixtures(t, "No bills found in the system")
 	}
 
 	billID := billList.Data[0].Id
@@ -259,12 +258,11 @@ func TestIntegration_ListBillTransactions(t *testing.T) {
 
 		// Create tool call arguments with filters
 		args := ListBillTransactionsArgs{
-			ID:    billID,
-			Type:  "withdrawal",
-			Start: "2024-01-01",
-			End:   "2024-12-31",
-			Limit: 10,
-			Page:  1,
+			ID:        billID,
+			Type:      "withdrawal",
+			DateRange: DateRange{Start: "2024-01-01", End: "2024-12-31"},
+			Limit:     10,
+			Page:      1,
 		}
 
 		ctx, cancel := context.WithTimeout(context.Background(), testConfig.Timeout)
@@ -0,0 +1,158 @@
+package fireflyMCP
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dezer32/mcp-firefly-iii/pkg/client"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// LinkTransactionToBillArgs represents the arguments for the link_transaction_to_bill tool
+type LinkTransactionToBillArgs struct {
+	TransactionId string `json:"transaction_id" jsonschema:"Transaction (group) ID (required)"`
+	BillId        string `json:"bill_id" jsonschema:"Bill ID to link the transaction to (required)"`
+	JournalId     string `json:"journal_id,omitempty" jsonschema:"Only link this split (transaction journal ID; default: all splits)"`
+}
+
+// UnlinkTransactionFromBillArgs represents the arguments for the unlink_transaction_from_bill tool
+type UnlinkTransactionFromBillArgs struct {
+	TransactionId string `json:"transaction_id" jsonschema:"Transaction (group) ID (required)"`
+	JournalId     string `json:"journal_id,omitempty" jsonschema:"Only unlink this split (transaction journal ID; default: all splits)"`
+}
+
+func (s *FireflyMCPServer) handleLinkTransactionToBill(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args LinkTransactionToBillArgs,
+) (*mcp.CallToolResult, any, error) {
+	if args.TransactionId == "" || args.BillId == "" {
+		return newErrorResult("Error: transaction_id and bill_id are required")
+	}
+
+	apiClient, err := s.getClient(ctx, req)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Failed to get API client: %v", err))
+	}
+
+	billResp, err := apiClient.GetBillWithResponse(ctx, args.BillId, &client.GetBillParams{})
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error getting bill: %v", err))
+	}
+	if billResp.StatusCode() == 404 {
+		return newErrorResult("Bill not found")
+	}
+	if billResp.StatusCode() != 200 {
+		return newErrorResult(fmt.Sprintf("API error: %d - %s", billResp.StatusCode(), s.upstreamError(billResp.Body)))
+	}
+
+	return s.setTransactionBill(ctx, req, "link_transaction_to_bill", args.TransactionId, args.JournalId, args.BillId)
+}
+
+func (s *FireflyMCPServer) handleUnlinkTransactionFromBill(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args UnlinkTransactionFromBillArgs,
+) (*mcp.CallToolResult, any, error) {
+	if args.TransactionId == "" {
+		return newErrorResult("Error: transaction_id is required")
+	}
+	// Firefly III removes the bill link when bill_id does not match a bill
+	return s.setTransactionBill(ctx, req, "unlink_transaction_from_bill", args.TransactionId, args.JournalId, "0")
+}
+
+// setTransactionBill sets bill_id on the splits of a transaction and leaves all
+// other fields untouched. Every split is sent with its journal ID, because
+// Firefly III deletes the splits missing from an update.
+func (s *FireflyMCPServer) setTransactionBill(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	tool, groupID, journalID, billID string,
+) (*mcp.CallToolResult, any, error) {
+	current, err := callTool[GetTransactionArgs, TransactionGroup](ctx, req, s.handleGetTransaction, GetTransactionArgs{ID: groupID})
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error getting transaction: %v", err))
+	}
+
+	var (
+		splits  []map[string]any
+		changed []TransactionSplitRequest
+		found   bool
+		ids     []string
+	)
+	for _, split := range current.Transactions {
+		ids = append(ids, split.Id)
+		update := map[string]any{"transaction_journal_id": split.Id}
+		recorded := TransactionSplitRequest{}
+		if journalID == "" || journalID == split.Id {
+			update["bill_id"] = billID
+			recorded.BillId = &billID
+			found = true
+		}
+		splits = append(splits, update)
+		changed = append(changed, recorded)
+	}
+	if !found {
+		return newErrorResult(fmt.Sprintf("Error: journal_id %s is not a split of transaction %s (splits: %s)",
+			journalID, groupID, strings.Join(ids, ", ")))
+	}
+
+	body := map[string]any{"transactions": splits}
+	if current.GroupTitle != "" {
+		body["group_title"] = current.GroupTitle
+	}
+	return s.sendTransactionUpdate(ctx, req, tool, groupID, body, "", changed)
+}
+
+// sendTransactionUpdate sends a composed update body for a transaction group and
+// records the change. It is used by tools that change some fields of existing
+// splits, so they send exactly the fields they change.
+func (s *FireflyMCPServer) sendTransactionUpdate(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	tool, groupID string,
+	body map[string]any,
+	groupTitle string,
+	changed []TransactionSplitRequest,
+) (*mcp.CallToolResult, any, error) {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error encoding update: %v", err))
+	}
+
+	apiClient, err := s.getClient(ctx, req)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Failed to get API client: %v", err))
+	}
+	resp, err := apiClient.UpdateTransactionWithBodyWithResponse(
+		ctx, groupID, &client.UpdateTransactionParams{}, "application/json", bytes.NewReader(raw))
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error updating transaction: %v", err))
+	}
+
+	switch status := resp.StatusCode(); {
+	case status == 404:
+		return newErrorResult("Error: Transaction not found")
+	case status == 422:
+		return newErrorResult(fmt.Sprintf("Validation error: %s", s.upstreamError(resp.Body)))
+	case !isSuccessStatus(status):
+		return newErrorResult(fmt.Sprintf("API error: %d - %s", status, s.upstreamError(resp.Body)))
+	case isEmptyBody(status, resp.Body):
+		s.recordTransactionChange(req, groupID, tool, "updated", groupTitle, changed)
+		return newSuccessResult(updatedResult(groupID))
+	}
+
+	var transactionSingle client.TransactionSingle
+	if resp.ApplicationvndApiJSON200 != nil {
+		transactionSingle = *resp.ApplicationvndApiJSON200
+	} else if err := json.Unmarshal(resp.Body, &transactionSingle); err != nil {
+		return newErrorResult(fmt.Sprintf("Error parsing response: %v (body: %s)", err, s.upstreamError(resp.Body)))
+	}
+
+	transactionGroup := mapTransactionReadToTransactionGroup(&transactionSingle.Data)
+	s.recordTransactionChange(req, transactionGroup.Id, tool, "updated", groupTitle, changed)
+	return newSuccessResult(transactionGroup)
+}
@@ -0,0 +1,100 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const billLinkGroupJSON = `{"data":{"type":"transactions","id":"40","attributes":{"group_title":"Utilities","transactions":[` +
+	`{"transaction_journal_id":"41","type":"withdrawal","date":"2024-03-01T00:00:00Z","amount":"60.00","description":"Power"},` +
+	`{"transaction_journal_id":"42","type":"withdrawal","date":"2024-03-01T00:00:00Z","amount":"30.00","description":"Water"}]}}}`
+
+func newBillLinkTestServer(t *testing.T, updates *[]map[string]any) *FireflyMCPServer {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch {
+		case r.URL.Path == "/v1/bills/3":
+			w.Write([]byte(`{"data":{"type":"bills","id":"3","attributes":{"name":"Power company"}}}`))
+		case r.URL.Path == "/v1/transactions/40" && r.Method == http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			var update map[string]any
+			require.NoError(t, json.Unmarshal(body, &update))
+			*updates = append(*updates, update)
+			w.Write([]byte(billLinkGroupJSON))
+		case r.URL.Path == "/v1/transactions/40":
+			w.Write([]byte(billLinkGroupJSON))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"Resource not found"}`))
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+	return server
+}
+
+func TestLinkTransactionToBill(t *testing.T) {
+	var updates []map[string]any
+	server := newBillLinkTestServer(t, &updates)
+	ctx := context.Background()
+
+	result, _, err := server.handleLinkTransactionToBill(ctx, nil, LinkTransactionToBillArgs{
+		TransactionId: "40", BillId: "3", JournalId: "41",
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content[0].(*mcp.TextContent).Text)
+
+	require.Len(t, updates, 1)
+	assert.Equal(t, map[string]any{
+		"group_title": "Utilities",
+		"transactions": []any{
+			map[string]any{"transaction_journal_id": "41", "bill_id": "3"},
+			map[string]any{"transaction_journal_id": "42"},
+		},
+	}, updates[0])
+
+	history := server.changes.get(server.tenantKey(nil), "40")
+	require.Len(t, history, 1)
+	assert.Equal(t, "link_transaction_to_bill", history[0].Tool)
+	assert.Equal(t, map[string]string{"transactions[0].bill_id": "3"}, history[0].Fields)
+
+	result, _, err = server.handleUnlinkTransactionFromBill(ctx, nil, UnlinkTransactionFromBillArgs{TransactionId: "40"})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	require.Len(t, updates, 2)
+	assert.Equal(t, []any{
+		map[string]any{"transaction_journal_id": "41", "bill_id": "0"},
+		map[string]any{"transaction_journal_id": "42", "bill_id": "0"},
+	}, updates[1]["transactions"])
+}
+
+func TestLinkTransactionToBill_Errors(t *testing.T) {
+	var updates []map[string]any
+	server := newBillLinkTestServer(t, &updates)
+	ctx := context.Background()
+
+	result, _, err := server.handleLinkTransactionToBill(ctx, nil, LinkTransactionToBillArgs{TransactionId: "40", BillId: "9"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "Bill not found")
+
+	result, _, err = server.handleLinkTransactionToBill(ctx, nil, LinkTransactionToBillArgs{
+		TransactionId: "40", BillId: "3", JournalId: "99",
+	})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "41, 42")
+	assert.Empty(t, updates)
+}
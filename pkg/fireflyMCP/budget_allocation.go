@@ -0,0 +1,336 @@
+package fireflyMCP
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dezer32/mcp-firefly-iii/pkg/client"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// Allocation strategies of allocate_remaining
+const (
+	allocationEqual        = "equal"        // Same amount for every budget
+	allocationProportional = "proportional" // In proportion to what each budget spent in the previous months
+)
+
+// defaultAllocationHistoryMonths is how many months the proportional strategy looks back
+const defaultAllocationHistoryMonths = 3
+
+// AllocateRemainingArgs represents the arguments for the allocate_remaining tool
+type AllocateRemainingArgs struct {
+	Month         string   `json:"month,omitempty" jsonschema:"Month to allocate (YYYY-MM, default: current month)"`
+	Strategy      string   `json:"strategy,omitempty" jsonschema:"Distribution: equal or proportional to past spending (default: equal)"`
+	HistoryMonths int      `json:"history_months,omitempty" jsonschema:"For the proportional strategy: months of spending before the month to weigh by (default: 3)"`
+	Budgets       []string `json:"budgets,omitempty" jsonschema:"Budget IDs to distribute to (default: all active budgets)"`
+	CurrencyCode  string   `json:"currency_code,omitempty" jsonschema:"Only allocate income in this currency (default: every currency with income)"`
+	Apply         bool     `json:"apply,omitempty" jsonschema:"Create or raise the budget limits of the month (default: false, only propose)"`
+}
+
+// BudgetAllocationCurrency is the income of the month in one currency and how
+// much of it budget limits already allocate
+type BudgetAllocationCurrency struct {
+	CurrencyCode string `json:"currency_code"`
+	Income       string `json:"income"`
+	Allocated    string `json:"allocated"`   // Sum of the budget limits of the month
+	Unallocated  string `json:"unallocated"` // Income minus allocated; only positive amounts are distributed
+}
+
+// BudgetAllocationEntry is the proposed limit change of one budget and currency
+type BudgetAllocationEntry struct {
+	BudgetId       string `json:"budget_id"`
+	BudgetName     string `json:"budget_name,omitempty"`
+	CurrencyCode   string `json:"currency_code"`
+	Weight         string `json:"weight,omitempty"` // Past spending the proportional strategy weighs by
+	CurrentLimitId string `json:"current_limit_id,omitempty"`
+	CurrentAmount  string `json:"current_amount,omitempty"`
+	Allocation     string `json:"allocation"`
+	NewAmount      string `json:"new_amount"`
+	Action         string `json:"action"` // create or update
+	Applied        bool   `json:"applied"`
+	Error          string `json:"error,omitempty"`
+
+	currentNotes *string // Notes of the current limit, kept when it is raised
+}
+
+// BudgetAllocationReport is the result of allocate_remaining
+type BudgetAllocationReport struct {
+	Month        string                     `json:"month"`
+	Strategy     string                     `json:"strategy"`
+	HistoryStart string                     `json:"history_start,omitempty"`
+	HistoryEnd   string                     `json:"history_end,omitempty"`
+	Apply        bool                       `json:"apply"`
+	Currencies   []BudgetAllocationCurrency `json:"currencies"`
+	Entries      []BudgetAllocationEntry    `json:"entries"`
+	Notes        []string                   `json:"notes,omitempty"`
+}
+
+// handleAllocateRemaining computes the income of the month that no budget limit
+// allocates yet and distributes it across budgets (zero-based budgeting). The
+// proposed limits are only applied when apply is set.
+func (s *FireflyMCPServer) handleAllocateRemaining(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args AllocateRemainingArgs,
+) (*mcp.CallToolResult, any, error) {
+	strategy := strings.ToLower(strings.TrimSpace(args.Strategy))
+	if strategy == "" {
+		strategy = allocationEqual
+	}
+	if strategy != allocationEqual && strategy != allocationProportional {
+		return newErrorResult("Error: strategy must be one of equal, proportional")
+	}
+	historyMonths := args.HistoryMonths
+	if historyMonths < 0 {
+		return newErrorResult("Error: history_months must not be negative")
+	}
+	if historyMonths == 0 {
+		historyMonths = defaultAllocationHistoryMonths
+	}
+
+	start, err := s.parseRolloverMonth(args.Month)
+	if err != nil {
+		return newErrorResult(err.Error())
+	}
+	end := start.AddDate(0, 1, -1)
+
+	apiClient, err := s.getClient(ctx, req)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Failed to get API client: %v", err))
+	}
+	income, _, err := insightTotals(ctx, apiClient, start, end, nil)
+	if err != nil {
+		return newErrorResult(err.Error())
+	}
+	limits, err := s.fetchBudgetLimits(ctx, req, start, end)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error listing budget limits of %s: %v", start.Format("2006-01"), err))
+	}
+
+	report := &BudgetAllocationReport{Month: start.Format("2006-01"), Strategy: strategy, Apply: args.Apply}
+	budgetRange := DateRange{Start: start.Format("2006-01-02"), End: end.Format("2006-01-02")}
+	if strategy == allocationProportional {
+		report.HistoryStart = start.AddDate(0, -historyMonths, 0).Format("2006-01-02")
+		report.HistoryEnd = start.AddDate(0, 0, -1).Format("2006-01-02")
+		budgetRange = DateRange{Start: report.HistoryStart, End: report.HistoryEnd}
+	}
+	budgets, err := s.fetchBudgets(ctx, req, budgetRange)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error listing budgets: %v", err))
+	}
+	selected, err := selectAllocationBudgets(budgets, args.Budgets)
+	if err != nil {
+		return newErrorResult(err.Error())
+	}
+
+	buildBudgetAllocation(report, income, limitsWithin(limits, start, end), selected, strings.ToUpper(args.CurrencyCode))
+
+	if args.Apply {
+		for i := range report.Entries {
+			s.applyBudgetAllocation(ctx, apiClient, &report.Entries[i], start, end)
+		}
+	}
+	return newSuccessResult(report)
+}
+
+// fetchBudgets pages through list_budgets, with spending in the given range
+func (s *FireflyMCPServer) fetchBudgets(ctx context.Context, req *mcp.CallToolRequest, dates DateRange) ([]Budget, error) {
+	budgets, _, err := fetchPages(ctx, s.pageParallelism(), func(ctx context.Context, page int) ([]Budget, int, error) {
+		list, err := callTool[ListBudgetsArgs, BudgetList](
+			ctx, req, s.handleListBudgets, ListBudgetsArgs{Limit: compositePageSize, Page: page, DateRange: dates},
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		return list.Data, list.Pagination.TotalPages, nil
+	})
+	return budgets, err
+}
+
+// selectAllocationBudgets returns the budgets with the given IDs, or all active
+// budgets when none are given
+func selectAllocationBudgets(budgets []Budget, ids []string) ([]Budget, error) {
+	if len(ids) == 0 {
+		var active []Budget
+		for _, budget := range budgets {
+			if budget.Active {
+				active = append(active, budget)
+			}
+		}
+		if len(active) == 0 {
+			return nil, fmt.Errorf("Error: there are no active budgets to allocate to")
+		}
+		return active, nil
+	}
+
+	byID := make(map[string]Budget, len(budgets))
+	for _, budget := range budgets {
+		byID[budget.Id] = budget
+	}
+	selected := make([]Budget, 0, len(ids))
+	for _, id := range ids {
+		budget, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("Error: budget %s not found", id)
+		}
+		selected = append(selected, budget)
+	}
+	return selected, nil
+}
+
+// buildBudgetAllocation fills in the unallocated income per currency and the
+// proposed limit of every selected budget. Shares are rounded to cents; the
+// rounding difference goes to the last budget, so allocations add up exactly.
+func buildBudgetAllocation(
+	report *BudgetAllocationReport,
+	income []InsightTotalEntry,
+	limits []BudgetLimit,
+	budgets []Budget,
+	currencyCode string,
+) {
+	report.Currencies = []BudgetAllocationCurrency{}
+	report.Entries = []BudgetAllocationEntry{}
+
+	allocated := make(map[string]decimal)
+	limitByKey := make(map[string]BudgetLimit, len(limits))
+	for _, limit := range limits {
+		allocated[limit.CurrencyCode] = allocated[limit.CurrencyCode].Add(parseAmount(limit.Amount))
+		limitByKey[limit.BudgetId+"/"+limit.CurrencyCode] = limit
+	}
+
+	sort.Slice(income, func(i, j int) bool { return income[i].CurrencyCode < income[j].CurrencyCode })
+	for _, entry := range income {
+		if currencyCode != "" && entry.CurrencyCode != currencyCode {
+			continue
+		}
+		total := parseAmount(entry.Amount).Abs()
+		unallocated := total.Sub(allocated[entry.CurrencyCode])
+		report.Currencies = append(report.Currencies, BudgetAllocationCurrency{
+			CurrencyCode: entry.CurrencyCode,
+			Income:       formatAmount(total),
+			Allocated:    formatAmount(allocated[entry.CurrencyCode]),
+			Unallocated:  formatAmount(unallocated),
+		})
+		if unallocated.Sign() <= 0 {
+			report.Notes = append(report.Notes, fmt.Sprintf(
+				"Nothing to allocate in %s: budget limits already allocate %s of %s income",
+				entry.CurrencyCode, formatAmount(allocated[entry.CurrencyCode]), formatAmount(total),
+			))
+			continue
+		}
+
+		weights := make([]decimal, len(budgets))
+		weightTotal := decimal{}
+		if report.Strategy == allocationProportional {
+			for i, budget := range budgets {
+				for _, spent := range budget.Spent {
+					if spent.CurrencyCode == entry.CurrencyCode {
+						weights[i] = weights[i].Add(parseAmount(spent.Sum).Abs())
+					}
+				}
+				weightTotal = weightTotal.Add(weights[i])
+			}
+			if weightTotal.Sign() == 0 {
+				report.Notes = append(report.Notes, fmt.Sprintf(
+					"No %s spending in the selected budgets from %s to %s, %s is allocated equally",
+					entry.CurrencyCode, report.HistoryStart, report.HistoryEnd, entry.CurrencyCode,
+				))
+			}
+		}
+		equal := weightTotal.Sign() == 0
+
+		remaining := unallocated
+		for i, budget := range budgets {
+			var share decimal
+			switch {
+			case i == len(budgets)-1:
+				share = remaining
+			case equal:
+				share = roundCents(unallocated.Div(decimalFromInt(int64(len(budgets)))))
+			default:
+				share = roundCents(unallocated.Mul(weights[i]).Div(weightTotal))
+			}
+			remaining = remaining.Sub(share)
+
+			allocation := BudgetAllocationEntry{
+				BudgetId:     budget.Id,
+				BudgetName:   budget.Name,
+				CurrencyCode: entry.CurrencyCode,
+				Allocation:   formatAmount(share),
+				NewAmount:    formatAmount(share),
+				Action:       rolloverActionCreate,
+			}
+			if !equal {
+				allocation.Weight = formatAmount(weights[i])
+			}
+			if limit, ok := limitByKey[budget.Id+"/"+entry.CurrencyCode]; ok {
+				allocation.CurrentLimitId = limit.Id
+				allocation.CurrentAmount = limit.Amount
+				allocation.NewAmount = formatAmount(parseAmount(limit.Amount).Add(share))
+				allocation.Action = rolloverActionUpdate
+				allocation.currentNotes = limit.Notes
+			}
+			report.Entries = append(report.Entries, allocation)
+		}
+	}
+}
+
+// roundCents rounds an amount to two decimals
+func roundCents(amount decimal) decimal {
+	return parseAmount(formatAmount(amount))
+}
+
+// applyBudgetAllocation creates or raises the limit of an entry and records the outcome
+func (s *FireflyMCPServer) applyBudgetAllocation(
+	ctx context.Context,
+	apiClient *client.ClientWithResponses,
+	entry *BudgetAllocationEntry,
+	start, end time.Time,
+) {
+	if parseAmount(entry.Allocation).Sign() == 0 {
+		return
+	}
+
+	var (
+		status int
+		body   []byte
+	)
+	if entry.Action == rolloverActionCreate {
+		currencyCode := entry.CurrencyCode
+		resp, err := apiClient.StoreBudgetLimitWithResponse(ctx, entry.BudgetId, &client.StoreBudgetLimitParams{},
+			client.StoreBudgetLimitJSONRequestBody{
+				Amount:       entry.NewAmount,
+				CurrencyCode: &currencyCode,
+				Start:        openapi_types.Date{Time: start},
+				End:          openapi_types.Date{Time: end},
+			})
+		if err != nil {
+			entry.Error = fmt.Sprintf("Error creating budget limit: %v", err)
+			return
+		}
+		status, body = resp.StatusCode(), resp.Body
+	} else {
+		resp, err := apiClient.UpdateBudgetLimitWithResponse(ctx, entry.BudgetId, entry.CurrentLimitId,
+			&client.UpdateBudgetLimitParams{},
+			client.UpdateBudgetLimitJSONRequestBody{
+				Amount: entry.NewAmount,
+				Start:  start,
+				End:    end,
+				Notes:  entry.currentNotes,
+			})
+		if err != nil {
+			entry.Error = fmt.Sprintf("Error updating budget limit: %v", err)
+			return
+		}
+		status, body = resp.StatusCode(), resp.Body
+	}
+	if !isSuccessStatus(status) {
+		entry.Error = fmt.Sprintf("API error %d: %s", status, s.upstreamError(body))
+		return
+	}
+	entry.Applied = true
+}
@@ -0,0 +1,154 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildBudgetAllocation(t *testing.T) {
+	income := []InsightTotalEntry{{Amount: "1000.00", CurrencyCode: "EUR"}, {Amount: "50.00", CurrencyCode: "USD"}}
+	limits := []BudgetLimit{
+		{Id: "7", BudgetId: "1", Amount: "600.00", CurrencyCode: "EUR"},
+		{Id: "8", BudgetId: "2", Amount: "100.00", CurrencyCode: "USD"},
+	}
+	budgets := []Budget{
+		{Id: "1", Name: "Groceries", Spent: []BudgetSpent{{Sum: "-300.00", CurrencyCode: "EUR"}}},
+		{Id: "2", Name: "Fuel", Spent: []BudgetSpent{{Sum: "-100.00", CurrencyCode: "EUR"}}},
+		{Id: "3", Name: "Fun"},
+	}
+
+	t.Run("equal", func(t *testing.T) {
+		report := &BudgetAllocationReport{Strategy: allocationEqual}
+		buildBudgetAllocation(report, income, limits, budgets, "")
+
+		assert.Equal(t, []BudgetAllocationCurrency{
+			{CurrencyCode: "EUR", Income: "1000.00", Allocated: "600.00", Unallocated: "400.00"},
+			{CurrencyCode: "USD", Income: "50.00", Allocated: "100.00", Unallocated: "-50.00"},
+		}, report.Currencies)
+		require.Len(t, report.Notes, 1)
+		assert.Contains(t, report.Notes[0], "Nothing to allocate in USD")
+
+		require.Len(t, report.Entries, 3)
+		assert.Equal(t, "133.33", report.Entries[0].Allocation)
+		assert.Equal(t, "733.33", report.Entries[0].NewAmount)
+		assert.Equal(t, rolloverActionUpdate, report.Entries[0].Action)
+		assert.Equal(t, "7", report.Entries[0].CurrentLimitId)
+		assert.Equal(t, "133.33", report.Entries[1].Allocation)
+		assert.Equal(t, rolloverActionCreate, report.Entries[1].Action)
+		assert.Equal(t, "133.34", report.Entries[2].Allocation)
+	})
+
+	t.Run("proportional", func(t *testing.T) {
+		report := &BudgetAllocationReport{Strategy: allocationProportional}
+		buildBudgetAllocation(report, income, limits, budgets, "EUR")
+
+		require.Len(t, report.Currencies, 1)
+		assert.Empty(t, report.Notes)
+		require.Len(t, report.Entries, 3)
+		assert.Equal(t, "300.00", report.Entries[0].Allocation)
+		assert.Equal(t, "300.00", report.Entries[0].Weight)
+		assert.Equal(t, "100.00", report.Entries[1].Allocation)
+		assert.Equal(t, "0.00", report.Entries[2].Allocation)
+	})
+
+	t.Run("proportional without history", func(t *testing.T) {
+		report := &BudgetAllocationReport{Strategy: allocationProportional}
+		buildBudgetAllocation(report, income, limits, budgets[2:], "EUR")
+
+		require.Len(t, report.Notes, 1)
+		assert.Contains(t, report.Notes[0], "allocated equally")
+		require.Len(t, report.Entries, 1)
+		assert.Equal(t, "400.00", report.Entries[0].Allocation)
+	})
+}
+
+func TestSelectAllocationBudgets(t *testing.T) {
+	budgets := []Budget{{Id: "1", Active: true}, {Id: "2"}, {Id: "3", Active: true}}
+
+	selected, err := selectAllocationBudgets(budgets, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []Budget{budgets[0], budgets[2]}, selected)
+
+	selected, err = selectAllocationBudgets(budgets, []string{"2"})
+	require.NoError(t, err)
+	assert.Equal(t, []Budget{budgets[1]}, selected)
+
+	_, err = selectAllocationBudgets(budgets, []string{"9"})
+	assert.ErrorContains(t, err, "budget 9 not found")
+	_, err = selectAllocationBudgets(budgets[1:2], nil)
+	assert.ErrorContains(t, err, "no active budgets")
+}
+
+func TestAllocateRemaining_Apply(t *testing.T) {
+	var mu sync.Mutex
+	var stored []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/budgets/10/limits":
+			body, _ := io.ReadAll(r.Body)
+			mu.Lock()
+			stored = append(stored, string(body))
+			mu.Unlock()
+			w.Write([]byte(`{"data":{"type":"budget_limits","id":"99","attributes":{"amount":"250.00",` +
+				`"start":"2024-02-01T00:00:00Z","end":"2024-02-29T00:00:00Z"}}}`))
+		case r.URL.Path == "/v1/insight/income/total":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[{"currency_code":"EUR","difference":"2000.00"}]`))
+		case r.URL.Path == "/v1/insight/expense/total":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[]`))
+		case r.URL.Path == "/v1/budget-limits":
+			w.Write([]byte(`{"data":[{"type":"budget_limits","id":"1","attributes":{"amount":"1750.00",` +
+				`"budget_id":"11","currency_code":"EUR","start":"2024-02-01T00:00:00Z","end":"2024-02-29T00:00:00Z"}}],"meta":{}}`))
+		case r.URL.Path == "/v1/budgets":
+			w.Write([]byte(`{"data":[{"type":"budgets","id":"10","attributes":{"name":"Groceries","active":true}},` +
+				`{"type":"budgets","id":"11","attributes":{"name":"Rent","active":true}}],` +
+				`"meta":{"pagination":{"total_pages":1}}}`))
+		default:
+			w.Write([]byte(`{"data":[],"meta":{}}`))
+		}
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	result, _, err := server.handleAllocateRemaining(context.Background(), nil, AllocateRemainingArgs{
+		Month:   "2024-02",
+		Budgets: []string{"10"},
+		Apply:   true,
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content[0].(*mcp.TextContent).Text)
+
+	var report BudgetAllocationReport
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &report))
+	require.Len(t, report.Currencies, 1)
+	assert.Equal(t, "250.00", report.Currencies[0].Unallocated)
+	require.Len(t, report.Entries, 1)
+	assert.Equal(t, "Groceries", report.Entries[0].BudgetName)
+	assert.True(t, report.Entries[0].Applied, report.Entries[0].Error)
+
+	require.Len(t, stored, 1)
+	assert.Contains(t, stored[0], `"amount":"250.00"`)
+	assert.Contains(t, stored[0], `"start":"2024-02-01"`)
+}
+
+func TestAllocateRemaining_InvalidStrategy(t *testing.T) {
+	server := &FireflyMCPServer{}
+	result, _, err := server.handleAllocateRemaining(context.Background(), nil, AllocateRemainingArgs{Strategy: "random"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
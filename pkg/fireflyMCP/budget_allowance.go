@@ -0,0 +1,56 @@
+package fireflyMCP
+
+import "time"
+
+// addBudgetAllowances fills in what is left of each budget limit as of today:
+// the remaining amount, the days left in the period and the amount that can be
+// spent per day for the rest of it
+func (s *FireflyMCPServer) addBudgetAllowances(list *BudgetLimitList) {
+	if list == nil {
+		return
+	}
+	today := s.now()
+	list.AsOf = today.Format("2006-01-02")
+	for i := range list.Data {
+		setBudgetAllowance(&list.Data[i], today)
+	}
+}
+
+// setBudgetAllowance computes the remaining amount, days and daily allowance of
+// a limit. Spending is summed in the currency of the limit; Firefly III reports
+// it as a negative amount. Days are counted in calendar days including today,
+// or over the whole period when it has not started yet.
+func setBudgetAllowance(limit *BudgetLimit, today time.Time) {
+	spent := decimalFromInt(0)
+	for _, entry := range limit.Spent {
+		if entry.CurrencyCode == "" || entry.CurrencyCode == limit.CurrencyCode {
+			spent = spent.Add(parseAmount(entry.Sum).Abs())
+		}
+	}
+	remaining := parseAmount(limit.Amount).Sub(spent)
+	limit.Remaining = formatAmount(remaining)
+
+	from := calendarDay(today)
+	if start := calendarDay(limit.Start); start.After(from) {
+		from = start
+	}
+	days := 0
+	if end := calendarDay(limit.End); !end.Before(from) {
+		days = int(end.Sub(from).Hours()/24) + 1
+	}
+	limit.DaysRemaining = &days
+	switch {
+	case days == 0:
+		limit.DailyAllowance = ""
+	case remaining.Sign() <= 0:
+		limit.DailyAllowance = formatAmount(decimalFromInt(0))
+	default:
+		limit.DailyAllowance = formatAmount(remaining.Div(decimalFromInt(int64(days))))
+	}
+}
+
+// calendarDay returns the date of t, in its own timezone, as midnight UTC so
+// that days can be counted without daylight saving shifts
+func calendarDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,50 @@
+package fireflyMCP
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetBudgetAllowance(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, berlin)
+	end := time.Date(2024, 3, 31, 23, 59, 59, 0, berlin)
+	spent := func(sums ...string) []BudgetSpent {
+		entries := make([]BudgetSpent, len(sums))
+		for i, sum := range sums {
+			entries[i] = BudgetSpent{Sum: sum, CurrencyCode: "EUR"}
+		}
+		return entries
+	}
+
+	tests := []struct {
+		name      string
+		amount    string
+		spent     []BudgetSpent
+		today     time.Time
+		remaining string
+		days      int
+		allowance string
+	}{
+		{"mid-month", "400.00", spent("-120.50"), time.Date(2024, 3, 18, 22, 0, 0, 0, berlin), "279.50", 14, "19.96"},
+		{"last day", "400.00", spent("-380.00"), time.Date(2024, 3, 31, 8, 0, 0, 0, berlin), "20.00", 1, "20.00"},
+		{"overspent", "400.00", spent("-450.00"), time.Date(2024, 3, 18, 8, 0, 0, 0, berlin), "-50.00", 14, "0.00"},
+		{"not started", "310.00", nil, time.Date(2024, 2, 20, 8, 0, 0, 0, berlin), "310.00", 31, "10.00"},
+		{"ended", "400.00", spent("-100.00"), time.Date(2024, 4, 2, 8, 0, 0, 0, berlin), "300.00", 0, ""},
+		{"other currency ignored", "400.00", []BudgetSpent{{Sum: "-9000", CurrencyCode: "JPY"}}, time.Date(2024, 3, 31, 8, 0, 0, 0, berlin), "400.00", 1, "400.00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit := BudgetLimit{Amount: tt.amount, CurrencyCode: "EUR", Start: start, End: end, Spent: tt.spent}
+			setBudgetAllowance(&limit, tt.today)
+			assert.Equal(t, tt.remaining, limit.Remaining)
+			require.NotNil(t, limit.DaysRemaining)
+			assert.Equal(t, tt.days, *limit.DaysRemaining)
+			assert.Equal(t, tt.allowance, limit.DailyAllowance)
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package fireflyMCP
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dezer32/mcp-firefly-iii/pkg/client"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type StoreBudgetArgs struct {
+	BudgetStoreRequest
+}
+
+type UpdateBudgetArgs struct {
+	ID string `json:"id" jsonschema:"Budget ID (required)"`
+	BudgetUpdateRequest
+}
+
+// validAutoBudgetPeriods lists the auto-budget periods Firefly III accepts
+var validAutoBudgetPeriods = map[string]bool{
+	string(client.AutoBudgetPeriodDaily):     true,
+	string(client.AutoBudgetPeriodWeekly):    true,
+	string(client.AutoBudgetPeriodMonthly):   true,
+	string(client.AutoBudgetPeriodQuarterly): true,
+	string(client.AutoBudgetPeriodHalfYear):  true,
+	string(client.AutoBudgetPeriodYearly):    true,
+}
+
+func (s *FireflyMCPServer) handleStoreBudget(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args StoreBudgetArgs,
+) (*mcp.CallToolResult, any, error) {
+	if args.Name == "" {
+		return newErrorResult("Budget name is required")
+	}
+
+	body := client.BudgetStore{
+		Name:   args.Name,
+		Active: args.Active,
+		Notes:  args.Notes,
+	}
+	autoBudget := autoBudgetFields{
+		Type:         args.AutoBudgetType,
+		Amount:       args.AutoBudgetAmount,
+		Period:       args.AutoBudgetPeriod,
+		CurrencyCode: args.AutoBudgetCurrencyCode,
+	}
+	if err := autoBudget.validate(); err != nil {
+		return newErrorResult(fmt.Sprintf("Error: %v", err))
+	}
+	body.AutoBudgetType, body.AutoBudgetAmount, body.AutoBudgetPeriod, body.AutoBudgetCurrencyCode = autoBudget.toAPI()
+
+	apiClient, err := s.getClient(ctx, req)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Failed to get API client: %v", err))
+	}
+
+	resp, err := apiClient.StoreBudgetWithResponse(ctx, &client.StoreBudgetParams{}, body)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error creating budget: %v", err))
+	}
+
+	if resp.StatusCode() == 422 {
+		return newErrorResult(fmt.Sprintf("Validation error: %s", s.upstreamError(resp.Body)))
+	}
+
+	if !isSuccessStatus(resp.StatusCode()) {
+		return newErrorResult(fmt.Sprintf("API error: %d - %s", resp.StatusCode(), s.upstreamError(resp.Body)))
+	}
+	single, err := writeResponseBody(resp.StatusCode(), resp.ApplicationvndApiJSON200, resp.Body)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error parsing response: %v (body: %s)", err, s.upstreamError(resp.Body)))
+	}
+	if single == nil {
+		return newSuccessResult(createdResult())
+	}
+	budget := mapBudgetReadToBudget(single.Data)
+	return newSuccessResult(&budget)
+}
+
+// handleUpdateBudget updates a budget. Firefly III replaces the auto-budget
+// settings on every update, so the current budget is read first and only the
+// fields set in the arguments are changed.
+func (s *FireflyMCPServer) handleUpdateBudget(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args UpdateBudgetArgs,
+) (*mcp.CallToolResult, any, error) {
+	if args.ID == "" {
+		return newErrorResult("Budget ID is required")
+	}
+
+	apiClient, err := s.getClient(ctx, req)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Failed to get API client: %v", err))
+	}
+
+	currentResp, err := apiClient.GetBudgetWithResponse(ctx, args.ID, &client.GetBudgetParams{})
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error getting budget: %v", err))
+	}
+	if currentResp.StatusCode() == 404 {
+		return newErrorResult("Budget not found")
+	}
+	if currentResp.StatusCode() != 200 || currentResp.ApplicationvndApiJSON200 == nil {
+		return newErrorResult(fmt.Sprintf("API error: %d - %s", currentResp.StatusCode(), s.upstreamError(currentResp.Body)))
+	}
+	current := mapBudgetReadToBudget(currentResp.ApplicationvndApiJSON200.Data)
+
+	body := client.BudgetUpdate{
+		Name:   current.Name,
+		Active: &current.Active,
+		Notes:  current.Notes,
+	}
+	if args.Name != nil {
+		body.Name = *args.Name
+	}
+	if args.Active != nil {
+		body.Active = args.Active
+	}
+	if args.Notes != nil {
+		body.Notes = args.Notes
+	}
+
+	autoBudget := autoBudgetFields{
+		Type:         current.AutoBudgetType,
+		Amount:       current.AutoBudgetAmount,
+		Period:       current.AutoBudgetPeriod,
+		CurrencyCode: current.AutoBudgetCurrencyCode,
+	}
+	if args.AutoBudgetType != nil {
+		autoBudget.Type = args.AutoBudgetType
+	}
+	if args.AutoBudgetAmount != nil {
+		autoBudget.Amount = args.AutoBudgetAmount
+	}
+	if args.AutoBudgetPeriod != nil {
+		autoBudget.Period = args.AutoBudgetPeriod
+	}
+	if args.AutoBudgetCurrencyCode != nil {
+		autoBudget.CurrencyCode = args.AutoBudgetCurrencyCode
+	}
+	if err := autoBudget.validate(); err != nil {
+		return newErrorResult(fmt.Sprintf("Error: %v", err))
+	}
+	body.AutoBudgetType, body.AutoBudgetAmount, body.AutoBudgetPeriod, body.AutoBudgetCurrencyCode = autoBudget.toAPI()
+
+	resp, err := apiClient.UpdateBudgetWithResponse(ctx, args.ID, &client.UpdateBudgetParams{}, body)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error updating budget: %v", err))
+	}
+
+	if resp.StatusCode() == 404 {
+		return newErrorResult("Budget not found")
+	}
+
+	if resp.StatusCode() == 422 {
+		return newErrorResult(fmt.Sprintf("Validation error: %s", s.upstreamError(resp.Body)))
+	}
+
+	if !isSuccessStatus(resp.StatusCode()) {
+		return newErrorResult(fmt.Sprintf("API error: %d - %s", resp.StatusCode(), s.upstreamError(resp.Body)))
+	}
+	single, err := writeResponseBody(resp.StatusCode(), resp.ApplicationvndApiJSON200, resp.Body)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error parsing response: %v (body: %s)", err, s.upstreamError(resp.Body)))
+	}
+	if single == nil {
+		return newSuccessResult(updatedResult(args.ID))
+	}
+	budget := mapBudgetReadToBudget(single.Data)
+	return newSuccessResult(&budget)
+}
+
+// autoBudgetFields are the auto-budget settings of a budget
+type autoBudgetFields struct {
+	Type         *string
+	Amount       *string
+	Period       *string
+	CurrencyCode *string
+}
+
+// validate checks the type and period, and that reset and rollover auto-budgets
+// have an amount and period
+func (f autoBudgetFields) validate() error {
+	if f.Type == nil || *f.Type == string(client.AutoBudgetTypeNone) {
+		return nil
+	}
+	if *f.Type != string(client.AutoBudgetTypeReset) && *f.Type != string(client.AutoBudgetTypeRollover) {
+		return fmt.Errorf("auto_budget_type must be one of: none, reset, rollover")
+	}
+	if f.Amount == nil || *f.Amount == "" {
+		return fmt.Errorf("auto_budget_amount is required for a %s auto-budget", *f.Type)
+	}
+	if f.Period == nil || !validAutoBudgetPeriods[*f.Period] {
+		return fmt.Errorf("auto_budget_period must be one of: daily, weekly, monthly, quarterly, half-year, yearly")
+	}
+	return nil
+}
+
+// toAPI returns the auto-budget settings as API fields. Without a type, or with
+// type none, the auto-budget is removed.
+func (f autoBudgetFields) toAPI() (*client.AutoBudgetType, *string, *client.AutoBudgetPeriod, *string) {
+	if f.Type == nil || *f.Type == string(client.AutoBudgetTypeNone) {
+		none := client.AutoBudgetTypeNone
+		return &none, nil, nil, nil
+	}
+	autoBudgetType := client.AutoBudgetType(*f.Type)
+	period := client.AutoBudgetPeriod(*f.Period)
+	return &autoBudgetType, f.Amount, &period, f.CurrencyCode
+}
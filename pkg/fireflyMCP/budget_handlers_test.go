@@ -0,0 +1,118 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dezer32/mcp-firefly-iii/pkg/client"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapBudgetReadToBudget_AutoBudget(t *testing.T) {
+	reset := client.AutoBudgetTypeReset
+	monthly := client.AutoBudgetPeriodMonthly
+	budget := mapBudgetReadToBudget(client.BudgetRead{
+		Id: "4",
+		Attributes: client.Budget{
+			Name:             "Groceries",
+			AutoBudgetType:   &reset,
+			AutoBudgetAmount: strPtr("400.00"),
+			AutoBudgetPeriod: &monthly,
+			CurrencyCode:     strPtr("EUR"),
+		},
+	})
+	assert.Equal(t, "reset", *budget.AutoBudgetType)
+	assert.Equal(t, "400.00", *budget.AutoBudgetAmount)
+	assert.Equal(t, "monthly", *budget.AutoBudgetPeriod)
+	assert.Equal(t, "EUR", *budget.AutoBudgetCurrencyCode)
+
+	none := client.AutoBudgetTypeNone
+	budget = mapBudgetReadToBudget(client.BudgetRead{
+		Id:         "5",
+		Attributes: client.Budget{Name: "Fun", AutoBudgetType: &none, CurrencyCode: strPtr("EUR")},
+	})
+	assert.Nil(t, budget.AutoBudgetType)
+	assert.Nil(t, budget.AutoBudgetCurrencyCode)
+}
+
+func TestAutoBudgetFields_Validate(t *testing.T) {
+	assert.NoError(t, autoBudgetFields{}.validate())
+	assert.NoError(t, autoBudgetFields{Type: strPtr("none")}.validate())
+	assert.NoError(t, autoBudgetFields{Type: strPtr("rollover"), Amount: strPtr("50"), Period: strPtr("weekly")}.validate())
+
+	err := autoBudgetFields{Type: strPtr("monthly")}.validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "auto_budget_type")
+
+	err = autoBudgetFields{Type: strPtr("reset"), Period: strPtr("monthly")}.validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "auto_budget_amount")
+
+	err = autoBudgetFields{Type: strPtr("reset"), Amount: strPtr("50"), Period: strPtr("fortnightly")}.validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "auto_budget_period")
+}
+
+func TestUpdateBudget_KeepsUnsetFields(t *testing.T) {
+	var updated map[string]any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		if r.Method == http.MethodPut {
+			body, _ := io.ReadAll(r.Body)
+			require.NoError(t, json.Unmarshal(body, &updated))
+		}
+		w.Write([]byte(`{"data":{"type":"budgets","id":"4","attributes":{"name":"Groceries","active":true,` +
+			`"notes":"Food only","auto_budget_type":"reset","auto_budget_amount":"400.00",` +
+			`"auto_budget_period":"monthly","currency_code":"EUR"}}}`))
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	result, _, err := server.handleUpdateBudget(context.Background(), nil, UpdateBudgetArgs{
+		ID:                  "4",
+		BudgetUpdateRequest: BudgetUpdateRequest{AutoBudgetAmount: strPtr("450.00")},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content[0].(*mcp.TextContent).Text)
+
+	assert.Equal(t, "Groceries", updated["name"])
+	assert.Equal(t, "Food only", updated["notes"])
+	assert.Equal(t, "reset", updated["auto_budget_type"])
+	assert.Equal(t, "450.00", updated["auto_budget_amount"])
+	assert.Equal(t, "monthly", updated["auto_budget_period"])
+	assert.Equal(t, "EUR", updated["auto_budget_currency_code"])
+
+	result, _, err = server.handleUpdateBudget(context.Background(), nil, UpdateBudgetArgs{
+		ID:                  "4",
+		BudgetUpdateRequest: BudgetUpdateRequest{AutoBudgetType: strPtr("none")},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Equal(t, "none", updated["auto_budget_type"])
+	assert.Nil(t, updated["auto_budget_amount"])
+}
+
+func TestStoreBudget_Validation(t *testing.T) {
+	server := &FireflyMCPServer{}
+
+	result, _, err := server.handleStoreBudget(context.Background(), nil, StoreBudgetArgs{})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	result, _, err = server.handleStoreBudget(context.Background(), nil, StoreBudgetArgs{
+		BudgetStoreRequest: BudgetStoreRequest{Name: "Groceries", AutoBudgetType: strPtr("reset")},
+	})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "auto_budget_amount")
+}
@@ -0,0 +1,221 @@
+package fireflyMCP
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dezer32/mcp-firefly-iii/pkg/client"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// budgetMoveRollbackTimeout bounds restoring the source limit after a failed move
+const budgetMoveRollbackTimeout = 10 * time.Second
+
+// MoveBudgetAmountArgs represents the arguments for the move_budget_amount tool
+type MoveBudgetAmountArgs struct {
+	FromBudgetId string `json:"from_budget_id" jsonschema:"Budget ID to take the amount from (required)"`
+	ToBudgetId   string `json:"to_budget_id" jsonschema:"Budget ID to give the amount to (required)"`
+	Amount       string `json:"amount" jsonschema:"Amount to move (required, at most the limit of the source budget)"`
+	Month        string `json:"month,omitempty" jsonschema:"Month of the budget limits (YYYY-MM, default: current month)"`
+	CurrencyCode string `json:"currency_code,omitempty" jsonschema:"Currency of the limits (default: the currency of the source budget's only limit)"`
+}
+
+// BudgetMoveSide is the limit of one budget before and after a move
+type BudgetMoveSide struct {
+	BudgetId       string `json:"budget_id"`
+	BudgetName     string `json:"budget_name,omitempty"`
+	LimitId        string `json:"limit_id,omitempty"`
+	PreviousAmount string `json:"previous_amount"`
+	NewAmount      string `json:"new_amount"`
+}
+
+// BudgetMoveResult is the result of move_budget_amount
+type BudgetMoveResult struct {
+	Month        string         `json:"month"`
+	Amount       string         `json:"amount"`
+	CurrencyCode string         `json:"currency_code"`
+	From         BudgetMoveSide `json:"from"`
+	To           BudgetMoveSide `json:"to"`
+}
+
+// handleMoveBudgetAmount moves an amount between the limits of two budgets in
+// the same month (envelope budgeting). The source limit is lowered first; if
+// the destination cannot be raised, the source limit is restored, so either
+// both limits change or neither does.
+func (s *FireflyMCPServer) handleMoveBudgetAmount(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args MoveBudgetAmountArgs,
+) (*mcp.CallToolResult, any, error) {
+	if args.FromBudgetId == "" || args.ToBudgetId == "" || args.Amount == "" {
+		return newErrorResult("Error: from_budget_id, to_budget_id and amount are required")
+	}
+	if args.FromBudgetId == args.ToBudgetId {
+		return newErrorResult("Error: from_budget_id and to_budget_id must be different")
+	}
+	amount, ok := parseDecimal(args.Amount)
+	if !ok || amount.Sign() <= 0 {
+		return newErrorResult("Error: amount must be a positive number")
+	}
+
+	start, err := s.parseRolloverMonth(args.Month)
+	if err != nil {
+		return newErrorResult(err.Error())
+	}
+	end := start.AddDate(0, 1, -1)
+	list, err := s.fetchBudgetLimits(ctx, req, start, end)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error listing budget limits of %s: %v", start.Format("2006-01"), err))
+	}
+	limits := limitsWithin(list, start, end)
+
+	from, err := budgetMoveSource(limits, args.FromBudgetId, strings.ToUpper(args.CurrencyCode), start.Format("2006-01"))
+	if err != nil {
+		return newErrorResult(err.Error())
+	}
+	fromAmount := parseAmount(from.Amount)
+	if amount.Cmp(fromAmount) > 0 {
+		return newErrorResult(fmt.Sprintf(
+			"Error: amount %s exceeds the %s %s limit of budget %s", formatAmount(amount), from.Amount, from.CurrencyCode, from.BudgetId,
+		))
+	}
+
+	names := s.catalogNames(ctx, req, catalogBudgets)
+	result := &BudgetMoveResult{
+		Month:        start.Format("2006-01"),
+		Amount:       formatAmount(amount),
+		CurrencyCode: from.CurrencyCode,
+		From: BudgetMoveSide{
+			BudgetId:       from.BudgetId,
+			BudgetName:     names[from.BudgetId],
+			LimitId:        from.Id,
+			PreviousAmount: formatAmount(fromAmount),
+			NewAmount:      formatAmount(fromAmount.Sub(amount)),
+		},
+		To: BudgetMoveSide{
+			BudgetId:       args.ToBudgetId,
+			BudgetName:     names[args.ToBudgetId],
+			PreviousAmount: formatAmount(decimal{}),
+			NewAmount:      formatAmount(amount),
+		},
+	}
+	var to *BudgetLimit
+	for i := range limits {
+		if limits[i].BudgetId == args.ToBudgetId && limits[i].CurrencyCode == from.CurrencyCode {
+			to = &limits[i]
+			result.To.LimitId = to.Id
+			result.To.PreviousAmount = formatAmount(parseAmount(to.Amount))
+			result.To.NewAmount = formatAmount(parseAmount(to.Amount).Add(amount))
+		}
+	}
+
+	apiClient, err := s.getClient(ctx, req)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Failed to get API client: %v", err))
+	}
+	if err := s.updateBudgetLimitAmount(ctx, apiClient, from, result.From.NewAmount); err != nil {
+		return newErrorResult(fmt.Sprintf("Error lowering the limit of budget %s, nothing was changed: %v", from.BudgetId, err))
+	}
+
+	if to != nil {
+		err = s.updateBudgetLimitAmount(ctx, apiClient, *to, result.To.NewAmount)
+	} else {
+		result.To.LimitId, err = s.storeBudgetLimit(ctx, apiClient, args.ToBudgetId, from.CurrencyCode, result.To.NewAmount, start, end)
+	}
+	if err != nil {
+		// The request context may be what failed, e.g. the tool timeout, so the
+		// source limit is restored on a context of its own
+		rollbackCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), budgetMoveRollbackTimeout)
+		defer cancel()
+		if rollbackErr := s.updateBudgetLimitAmount(rollbackCtx, apiClient, from, from.Amount); rollbackErr != nil {
+			return newErrorResult(fmt.Sprintf(
+				"Error raising the limit of budget %s: %v. Restoring the limit of budget %s to %s failed too: %v",
+				args.ToBudgetId, err, from.BudgetId, from.Amount, rollbackErr,
+			))
+		}
+		return newErrorResult(fmt.Sprintf(
+			"Error raising the limit of budget %s, the limit of budget %s was restored: %v", args.ToBudgetId, from.BudgetId, err,
+		))
+	}
+	return newSuccessResult(result)
+}
+
+// budgetMoveSource returns the limit of the month an amount is moved from
+func budgetMoveSource(limits []BudgetLimit, budgetID, currencyCode, month string) (BudgetLimit, error) {
+	var candidates []BudgetLimit
+	for _, limit := range limits {
+		if limit.BudgetId == budgetID && (currencyCode == "" || limit.CurrencyCode == currencyCode) {
+			candidates = append(candidates, limit)
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		if currencyCode != "" {
+			return BudgetLimit{}, fmt.Errorf("Error: budget %s has no %s limit in %s", budgetID, currencyCode, month)
+		}
+		return BudgetLimit{}, fmt.Errorf("Error: budget %s has no limit in %s", budgetID, month)
+	case 1:
+		return candidates[0], nil
+	default:
+		currencies := make([]string, len(candidates))
+		for i, limit := range candidates {
+			currencies[i] = limit.CurrencyCode
+		}
+		return BudgetLimit{}, fmt.Errorf("Error: budget %s has limits in several currencies in %s, set currency_code to one of: %s",
+			budgetID, month, strings.Join(currencies, ", "))
+	}
+}
+
+// updateBudgetLimitAmount changes the amount of a budget limit, keeping its period and notes
+func (s *FireflyMCPServer) updateBudgetLimitAmount(
+	ctx context.Context,
+	apiClient *client.ClientWithResponses,
+	limit BudgetLimit,
+	amount string,
+) error {
+	resp, err := apiClient.UpdateBudgetLimitWithResponse(ctx, limit.BudgetId, limit.Id, &client.UpdateBudgetLimitParams{},
+		client.UpdateBudgetLimitJSONRequestBody{
+			Amount: amount,
+			Start:  limit.Start,
+			End:    limit.End,
+			Notes:  limit.Notes,
+		})
+	if err != nil {
+		return err
+	}
+	if !isSuccessStatus(resp.StatusCode()) {
+		return fmt.Errorf("API error %d: %s", resp.StatusCode(), s.upstreamError(resp.Body))
+	}
+	return nil
+}
+
+// storeBudgetLimit creates a budget limit and returns its ID
+func (s *FireflyMCPServer) storeBudgetLimit(
+	ctx context.Context,
+	apiClient *client.ClientWithResponses,
+	budgetID, currencyCode, amount string,
+	start, end time.Time,
+) (string, error) {
+	resp, err := apiClient.StoreBudgetLimitWithResponse(ctx, budgetID, &client.StoreBudgetLimitParams{},
+		client.StoreBudgetLimitJSONRequestBody{
+			Amount:       amount,
+			CurrencyCode: &currencyCode,
+			Start:        openapi_types.Date{Time: start},
+			End:          openapi_types.Date{Time: end},
+		})
+	if err != nil {
+		return "", err
+	}
+	if !isSuccessStatus(resp.StatusCode()) {
+		return "", fmt.Errorf("API error %d: %s", resp.StatusCode(), s.upstreamError(resp.Body))
+	}
+	single, err := writeResponseBody(resp.StatusCode(), resp.ApplicationvndApiJSON200, resp.Body)
+	if err != nil || single == nil {
+		// The limit was created, only its ID is unknown
+		return "", nil
+	}
+	return single.Data.Id, nil
+}
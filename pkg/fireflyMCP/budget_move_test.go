@@ -0,0 +1,160 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newBudgetMoveTestServer serves February limits of 200 EUR for budget 1 and
+// 300 EUR for budget 2; budget 3 has none. Writes are recorded as
+// "METHOD path amount" and fail for paths in failing.
+func newBudgetMoveTestServer(t *testing.T, writes *[]string, failing map[string]bool) *FireflyMCPServer {
+	var mu sync.Mutex
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch {
+		case r.Method == http.MethodPut || r.Method == http.MethodPost:
+			body, _ := io.ReadAll(r.Body)
+			var limit map[string]any
+			require.NoError(t, json.Unmarshal(body, &limit))
+			mu.Lock()
+			*writes = append(*writes, r.Method+" "+r.URL.Path+" "+limit["amount"].(string))
+			mu.Unlock()
+			if failing[r.URL.Path] {
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(`{"message":"Server error"}`))
+				return
+			}
+			w.Write([]byte(`{"data":{"type":"budget_limits","id":"30","attributes":{"amount":"50.00",` +
+				`"start":"2024-02-01T00:00:00Z","end":"2024-02-29T00:00:00Z"}}}`))
+		case r.URL.Path == "/v1/budget-limits":
+			w.Write([]byte(`{"data":[` +
+				`{"type":"budget_limits","id":"10","attributes":{"amount":"200.00","budget_id":"1","currency_code":"EUR",` +
+				`"start":"2024-02-01T00:00:00Z","end":"2024-02-29T00:00:00Z","notes":"Dining out"}},` +
+				`{"type":"budget_limits","id":"20","attributes":{"amount":"300.00","budget_id":"2","currency_code":"EUR",` +
+				`"start":"2024-02-01T00:00:00Z","end":"2024-02-29T00:00:00Z"}}],"meta":{}}`))
+		case r.URL.Path == "/v1/budgets":
+			w.Write([]byte(`{"data":[{"type":"budgets","id":"1","attributes":{"name":"Dining"}},` +
+				`{"type":"budgets","id":"2","attributes":{"name":"Groceries"}}],"meta":{"pagination":{"total_pages":1}}}`))
+		default:
+			w.Write([]byte(`{"data":[],"meta":{}}`))
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+	return server
+}
+
+func TestMoveBudgetAmount(t *testing.T) {
+	var writes []string
+	server := newBudgetMoveTestServer(t, &writes, nil)
+
+	result, _, err := server.handleMoveBudgetAmount(context.Background(), nil, MoveBudgetAmountArgs{
+		FromBudgetId: "1", ToBudgetId: "2", Amount: "50", Month: "2024-02",
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content[0].(*mcp.TextContent).Text)
+
+	var move BudgetMoveResult
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &move))
+	assert.Equal(t, BudgetMoveSide{BudgetId: "1", BudgetName: "Dining", LimitId: "10", PreviousAmount: "200.00", NewAmount: "150.00"}, move.From)
+	assert.Equal(t, BudgetMoveSide{BudgetId: "2", BudgetName: "Groceries", LimitId: "20", PreviousAmount: "300.00", NewAmount: "350.00"}, move.To)
+	assert.Equal(t, []string{"PUT /v1/budgets/1/limits/10 150.00", "PUT /v1/budgets/2/limits/20 350.00"}, writes)
+}
+
+func TestMoveBudgetAmount_CreatesLimit(t *testing.T) {
+	var writes []string
+	server := newBudgetMoveTestServer(t, &writes, nil)
+
+	result, _, err := server.handleMoveBudgetAmount(context.Background(), nil, MoveBudgetAmountArgs{
+		FromBudgetId: "1", ToBudgetId: "3", Amount: "50", Month: "2024-02",
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content[0].(*mcp.TextContent).Text)
+	assert.Equal(t, []string{"PUT /v1/budgets/1/limits/10 150.00", "POST /v1/budgets/3/limits 50.00"}, writes)
+}
+
+func TestMoveBudgetAmount_RollsBack(t *testing.T) {
+	var writes []string
+	server := newBudgetMoveTestServer(t, &writes, map[string]bool{"/v1/budgets/2/limits/20": true})
+
+	result, _, err := server.handleMoveBudgetAmount(context.Background(), nil, MoveBudgetAmountArgs{
+		FromBudgetId: "1", ToBudgetId: "2", Amount: "50", Month: "2024-02",
+	})
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "the limit of budget 1 was restored")
+	assert.Equal(t, []string{
+		"PUT /v1/budgets/1/limits/10 150.00", "PUT /v1/budgets/2/limits/20 350.00", "PUT /v1/budgets/1/limits/10 200.00",
+	}, writes)
+}
+
+// cancellingTransport cancels the request context when a request reaches path
+type cancellingTransport struct {
+	base   http.RoundTripper
+	path   string
+	cancel context.CancelFunc
+}
+
+func (t *cancellingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Path == t.path {
+		t.cancel()
+		return nil, req.Context().Err()
+	}
+	return t.base.RoundTrip(req)
+}
+
+func TestMoveBudgetAmount_RollsBackCancelled(t *testing.T) {
+	var writes []string
+	server := newBudgetMoveTestServer(t, &writes, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server.httpClient.Transport = &cancellingTransport{base: server.httpClient.Transport, path: "/v1/budgets/2/limits/20", cancel: cancel}
+
+	result, _, err := server.handleMoveBudgetAmount(ctx, nil, MoveBudgetAmountArgs{
+		FromBudgetId: "1", ToBudgetId: "2", Amount: "50", Month: "2024-02",
+	})
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "the limit of budget 1 was restored")
+	assert.Equal(t, []string{"PUT /v1/budgets/1/limits/10 150.00", "PUT /v1/budgets/1/limits/10 200.00"}, writes)
+}
+
+func TestMoveBudgetAmount_Errors(t *testing.T) {
+	var writes []string
+	server := newBudgetMoveTestServer(t, &writes, nil)
+
+	tests := []struct {
+		name  string
+		args  MoveBudgetAmountArgs
+		error string
+	}{
+		{"missing fields", MoveBudgetAmountArgs{FromBudgetId: "1"}, "are required"},
+		{"same budget", MoveBudgetAmountArgs{FromBudgetId: "1", ToBudgetId: "1", Amount: "5"}, "must be different"},
+		{"negative amount", MoveBudgetAmountArgs{FromBudgetId: "1", ToBudgetId: "2", Amount: "-5"}, "positive number"},
+		{"no source limit", MoveBudgetAmountArgs{FromBudgetId: "3", ToBudgetId: "2", Amount: "5", Month: "2024-02"}, "has no limit in 2024-02"},
+		{"exceeds limit", MoveBudgetAmountArgs{FromBudgetId: "1", ToBudgetId: "2", Amount: "250", Month: "2024-02"}, "exceeds the 200.00 EUR limit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := server.handleMoveBudgetAmount(context.Background(), nil, tt.args)
+			require.NoError(t, err)
+			require.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, tt.error)
+		})
+	}
+	assert.Empty(t, writes)
+}
@@ -0,0 +1,360 @@
+package fireflyMCP
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dezer32/mcp-firefly-iii/pkg/client"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// Rollover strategies of budget_rollover
+const (
+	rolloverFull   = "full"   // Carry over the whole unspent amount
+	rolloverCapped = "capped" // Carry over at most rollover_cap_percent of the base limit
+	rolloverNone   = "none"   // Carry nothing over
+)
+
+// Rollover actions reported per budget limit
+const (
+	rolloverActionCreate  = "create"
+	rolloverActionUpdate  = "update"
+	rolloverActionApplied = "already_applied"
+	rolloverActionNone    = "none"
+)
+
+// rolloverNotePattern matches the marker budget_rollover writes into the notes
+// of limits it creates or adjusts, e.g. "Rollover from 2024-01: 25.00"
+var rolloverNotePattern = regexp.MustCompile(`Rollover from (\d{4}-\d{2}): (-?[0-9]+(?:\.[0-9]+)?)`)
+
+func isRolloverStrategy(strategy string) bool {
+	switch strategy {
+	case rolloverFull, rolloverCapped, rolloverNone:
+		return true
+	}
+	return false
+}
+
+// BudgetRolloverArgs represents the arguments for the budget_rollover tool
+type BudgetRolloverArgs struct {
+	Month      string   `json:"month,omitempty" jsonschema:"Month receiving the carryover (YYYY-MM, default: current month)"`
+	Strategy   string   `json:"strategy,omitempty" jsonschema:"Carryover strategy: full, capped or none (default: configured budgets.rollover_strategy)"`
+	CapPercent *int     `json:"cap_percent,omitempty" jsonschema:"For the capped strategy: maximum carryover as percent of the previous base limit (default: configured budgets.rollover_cap_percent)"`
+	Budgets    []string `json:"budgets,omitempty" jsonschema:"Budget IDs to roll over (default: all budgets with a limit in the previous month)"`
+	Apply      bool     `json:"apply,omitempty" jsonschema:"Create or adjust the budget limits of the month (default: false, only compute)"`
+}
+
+// BudgetRolloverEntry is the rollover of one budget limit (budget and currency)
+type BudgetRolloverEntry struct {
+	BudgetId       string `json:"budget_id"`
+	BudgetName     string `json:"budget_name,omitempty"`
+	CurrencyCode   string `json:"currency_code"`
+	PreviousLimit  string `json:"previous_limit"`
+	PreviousSpent  string `json:"previous_spent"`
+	Unspent        string `json:"unspent"`
+	Carryover      string `json:"carryover"`
+	CurrentLimitId string `json:"current_limit_id,omitempty"`
+	CurrentAmount  string `json:"current_amount,omitempty"`
+	NewAmount      string `json:"new_amount,omitempty"`
+	Action         string `json:"action"`
+	Applied        bool   `json:"applied"`
+	Error          string `json:"error,omitempty"`
+
+	currentNotes *string // Notes of the current limit, kept when it is adjusted
+}
+
+// BudgetRolloverReport is the result of budget_rollover
+type BudgetRolloverReport struct {
+	Month         string                `json:"month"`
+	PreviousMonth string                `json:"previous_month"`
+	Strategy      string                `json:"strategy"`
+	CapPercent    int                   `json:"cap_percent,omitempty"`
+	Apply         bool                  `json:"apply"`
+	Entries       []BudgetRolloverEntry `json:"entries"`
+}
+
+// handleBudgetRollover computes the unspent amounts of the previous month per
+// budget and optionally carries them over into the limits of the month
+func (s *FireflyMCPServer) handleBudgetRollover(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args BudgetRolloverArgs,
+) (*mcp.CallToolResult, any, error) {
+	strategy, capPercent := s.rolloverSettings(args)
+	if !isRolloverStrategy(strategy) {
+		return newErrorResult("Error: strategy must be one of full, capped, none")
+	}
+	if capPercent < 0 {
+		return newErrorResult("Error: cap_percent must not be negative")
+	}
+
+	start, err := s.parseRolloverMonth(args.Month)
+	if err != nil {
+		return newErrorResult(err.Error())
+	}
+	end := start.AddDate(0, 1, -1)
+	previousStart := start.AddDate(0, -1, 0)
+	previousEnd := start.AddDate(0, 0, -1)
+
+	previousLimits, err := s.fetchBudgetLimits(ctx, req, previousStart, previousEnd)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error listing budget limits of %s: %v", previousStart.Format("2006-01"), err))
+	}
+	currentLimits, err := s.fetchBudgetLimits(ctx, req, start, end)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error listing budget limits of %s: %v", start.Format("2006-01"), err))
+	}
+
+	report := &BudgetRolloverReport{
+		Month:         start.Format("2006-01"),
+		PreviousMonth: previousStart.Format("2006-01"),
+		Strategy:      strategy,
+		Apply:         args.Apply,
+	}
+	if strategy == rolloverCapped {
+		report.CapPercent = capPercent
+	}
+	report.Entries = buildBudgetRollover(
+		limitsWithin(previousLimits, previousStart, previousEnd),
+		limitsWithin(currentLimits, start, end),
+		report, args.Budgets,
+	)
+
+	names := s.catalogNames(ctx, req, catalogBudgets)
+	for i := range report.Entries {
+		report.Entries[i].BudgetName = names[report.Entries[i].BudgetId]
+	}
+
+	if args.Apply {
+		apiClient, err := s.getClient(ctx, req)
+		if err != nil {
+			return newErrorResult(fmt.Sprintf("Failed to get API client: %v", err))
+		}
+		for i := range report.Entries {
+			s.applyBudgetRollover(ctx, apiClient, &report.Entries[i], report.PreviousMonth, start, end)
+		}
+	}
+
+	return newSuccessResult(report)
+}
+
+// rolloverSettings returns the strategy and cap, falling back to the configuration
+func (s *FireflyMCPServer) rolloverSettings(args BudgetRolloverArgs) (string, int) {
+	strategy, capPercent := rolloverFull, 50
+	if config := s.currentConfig(); config != nil {
+		if config.Budgets.RolloverStrategy != "" {
+			strategy = config.Budgets.RolloverStrategy
+		}
+		capPercent = config.Budgets.RolloverCapPercent
+	}
+	if args.Strategy != "" {
+		strategy = strings.ToLower(args.Strategy)
+	}
+	if args.CapPercent != nil {
+		capPercent = *args.CapPercent
+	}
+	return strategy, capPercent
+}
+
+// parseRolloverMonth returns the first day of the month receiving the carryover.
+// An empty month selects the current month.
+func (s *FireflyMCPServer) parseRolloverMonth(month string) (time.Time, error) {
+	if month == "" {
+		now := s.now()
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC), nil
+	}
+	parsed, err := time.Parse("2006-01", month)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Error: month must be in format YYYY-MM")
+	}
+	return parsed, nil
+}
+
+// limitsWithin returns the budget limits lying entirely within the period.
+// Firefly III also returns limits that merely overlap it (e.g. yearly limits).
+func limitsWithin(list *BudgetLimitList, start, end time.Time) []BudgetLimit {
+	if list == nil {
+		return nil
+	}
+	first, last := start.Format("2006-01-02"), end.Format("2006-01-02")
+	var limits []BudgetLimit
+	for _, limit := range list.Data {
+		if limit.Start.Format("2006-01-02") >= first && limit.End.Format("2006-01-02") <= last {
+			limits = append(limits, limit)
+		}
+	}
+	return limits
+}
+
+// buildBudgetRollover computes the carryover of every previous limit and the
+// resulting action on the limits of the month
+func buildBudgetRollover(
+	previous, current []BudgetLimit,
+	report *BudgetRolloverReport,
+	budgets []string,
+) []BudgetRolloverEntry {
+	selected := make(map[string]bool, len(budgets))
+	for _, id := range budgets {
+		selected[id] = true
+	}
+	currentByKey := make(map[string]BudgetLimit, len(current))
+	for _, limit := range current {
+		currentByKey[limit.BudgetId+"/"+limit.CurrencyCode] = limit
+	}
+
+	entries := make([]BudgetRolloverEntry, 0, len(previous))
+	for _, limit := range previous {
+		if len(selected) > 0 && !selected[limit.BudgetId] {
+			continue
+		}
+
+		amount := parseAmount(limit.Amount)
+		spent := decimal{}
+		for _, s := range limit.Spent {
+			spent = spent.Add(parseAmount(s.Sum).Abs())
+		}
+		unspent := amount.Sub(spent)
+		base := amount.Sub(previousCarryover(limit.Notes))
+		carryover := rolloverAmount(unspent, base, report.Strategy, report.CapPercent)
+
+		entry := BudgetRolloverEntry{
+			BudgetId:      limit.BudgetId,
+			CurrencyCode:  limit.CurrencyCode,
+			PreviousLimit: formatAmount(amount),
+			PreviousSpent: formatAmount(spent),
+			Unspent:       formatAmount(unspent),
+			Carryover:     formatAmount(carryover),
+		}
+
+		currentLimit, hasCurrent := currentByKey[limit.BudgetId+"/"+limit.CurrencyCode]
+		switch {
+		case hasCurrent && hasRolloverMarker(currentLimit.Notes, report.PreviousMonth):
+			entry.Action = rolloverActionApplied
+		case carryover.Sign() <= 0:
+			entry.Action = rolloverActionNone
+		case hasCurrent:
+			entry.Action = rolloverActionUpdate
+			entry.NewAmount = formatAmount(parseAmount(currentLimit.Amount).Add(carryover))
+		default:
+			entry.Action = rolloverActionCreate
+			entry.NewAmount = formatAmount(base.Add(carryover))
+		}
+		if hasCurrent {
+			entry.CurrentLimitId = currentLimit.Id
+			entry.CurrentAmount = currentLimit.Amount
+			entry.currentNotes = currentLimit.Notes
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].BudgetId != entries[j].BudgetId {
+			return entries[i].BudgetId < entries[j].BudgetId
+		}
+		return entries[i].CurrencyCode < entries[j].CurrencyCode
+	})
+	return entries
+}
+
+// rolloverAmount returns the carryover of an unspent amount for a strategy
+func rolloverAmount(unspent, base decimal, strategy string, capPercent int) decimal {
+	if unspent.Sign() <= 0 || strategy == rolloverNone {
+		return decimal{}
+	}
+	if strategy == rolloverCapped {
+		if base.Sign() < 0 {
+			base = decimal{}
+		}
+		return unspent.Min(base.Mul(decimalFromInt(int64(capPercent))).Div(decimalFromInt(100)))
+	}
+	return unspent
+}
+
+// previousCarryover returns the carryover recorded in a limit's notes, so the
+// base limit can be told apart from an earlier rollover
+func previousCarryover(notes *string) decimal {
+	total := decimal{}
+	if notes == nil {
+		return total
+	}
+	for _, match := range rolloverNotePattern.FindAllStringSubmatch(*notes, -1) {
+		total = total.Add(parseAmount(match[2]))
+	}
+	return total
+}
+
+// hasRolloverMarker reports whether the notes record a rollover from the month
+func hasRolloverMarker(notes *string, month string) bool {
+	if notes == nil {
+		return false
+	}
+	for _, match := range rolloverNotePattern.FindAllStringSubmatch(*notes, -1) {
+		if match[1] == month {
+			return true
+		}
+	}
+	return false
+}
+
+// applyBudgetRollover creates or updates the limit of an entry and records the outcome
+func (s *FireflyMCPServer) applyBudgetRollover(
+	ctx context.Context,
+	apiClient *client.ClientWithResponses,
+	entry *BudgetRolloverEntry,
+	previousMonth string,
+	start, end time.Time,
+) {
+	if entry.Action != rolloverActionCreate && entry.Action != rolloverActionUpdate {
+		return
+	}
+	marker := fmt.Sprintf("Rollover from %s: %s", previousMonth, entry.Carryover)
+
+	if entry.Action == rolloverActionCreate {
+		currencyCode := entry.CurrencyCode
+		resp, err := apiClient.StoreBudgetLimitWithResponse(ctx, entry.BudgetId, &client.StoreBudgetLimitParams{},
+			client.StoreBudgetLimitJSONRequestBody{
+				Amount:       entry.NewAmount,
+				CurrencyCode: &currencyCode,
+				Start:        openapi_types.Date{Time: start},
+				End:          openapi_types.Date{Time: end},
+				Notes:        &marker,
+			})
+		if err != nil {
+			entry.Error = fmt.Sprintf("Error creating budget limit: %v", err)
+			return
+		}
+		if !isSuccessStatus(resp.StatusCode()) {
+			entry.Error = fmt.Sprintf("API error %d: %s", resp.StatusCode(), s.upstreamError(resp.Body))
+			return
+		}
+		entry.Applied = true
+		return
+	}
+
+	notes := marker
+	if existing := getStringValue(entry.currentNotes); existing != "" {
+		notes = existing + "\n" + marker
+	}
+	resp, err := apiClient.UpdateBudgetLimitWithResponse(ctx, entry.BudgetId, entry.CurrentLimitId,
+		&client.UpdateBudgetLimitParams{},
+		client.UpdateBudgetLimitJSONRequestBody{
+			Amount: entry.NewAmount,
+			Start:  start,
+			End:    end,
+			Notes:  &notes,
+		})
+	if err != nil {
+		entry.Error = fmt.Sprintf("Error updating budget limit: %v", err)
+		return
+	}
+	if !isSuccessStatus(resp.StatusCode()) {
+		entry.Error = fmt.Sprintf("API error %d: %s", resp.StatusCode(), s.upstreamError(resp.Body))
+		return
+	}
+	entry.Applied = true
+}
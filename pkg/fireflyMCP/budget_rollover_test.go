@@ -0,0 +1,156 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rolloverLimit(id, budgetID, amount, spent string, month time.Time, notes *string) BudgetLimit {
+	return BudgetLimit{
+		Id:           id,
+		BudgetId:     budgetID,
+		Amount:       amount,
+		CurrencyCode: "EUR",
+		Start:        month,
+		End:          month.AddDate(0, 1, -1),
+		Notes:        notes,
+		Spent:        []BudgetSpent{{Sum: spent, CurrencyCode: "EUR"}},
+	}
+}
+
+func TestBuildBudgetRollover(t *testing.T) {
+	january := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	february := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	previous := []BudgetLimit{
+		rolloverLimit("1", "10", "300.00", "-200.00", january, nil),
+		rolloverLimit("2", "20", "100.00", "-150.00", january, nil),
+		rolloverLimit("3", "30", "250.00", "-100.00", january, strPtr("Rollover from 2023-12: 50.00")),
+		rolloverLimit("4", "40", "100.00", "0", january, nil),
+	}
+	current := []BudgetLimit{
+		rolloverLimit("11", "10", "300.00", "0", february, nil),
+		rolloverLimit("14", "40", "150.00", "0", february, strPtr("Rollover from 2024-01: 50.00")),
+	}
+
+	report := &BudgetRolloverReport{PreviousMonth: "2024-01", Strategy: rolloverFull}
+	entries := buildBudgetRollover(previous, current, report, nil)
+	require.Len(t, entries, 4)
+
+	assert.Equal(t, "100.00", entries[0].Unspent)
+	assert.Equal(t, rolloverActionUpdate, entries[0].Action)
+	assert.Equal(t, "11", entries[0].CurrentLimitId)
+	assert.Equal(t, "400.00", entries[0].NewAmount)
+
+	assert.Equal(t, "-50.00", entries[1].Unspent, "overspent budgets carry nothing")
+	assert.Equal(t, rolloverActionNone, entries[1].Action)
+
+	// The base excludes the carryover from December
+	assert.Equal(t, rolloverActionCreate, entries[2].Action)
+	assert.Equal(t, "150.00", entries[2].Carryover)
+	assert.Equal(t, "350.00", entries[2].NewAmount)
+
+	assert.Equal(t, rolloverActionApplied, entries[3].Action)
+
+	capped := &BudgetRolloverReport{PreviousMonth: "2024-01", Strategy: rolloverCapped, CapPercent: 10}
+	entries = buildBudgetRollover(previous, current, capped, []string{"30"})
+	require.Len(t, entries, 1)
+	assert.Equal(t, "20.00", entries[0].Carryover, "10% of the 200.00 base")
+}
+
+func TestRolloverAmount(t *testing.T) {
+	unspent, base := parseAmount("80"), parseAmount("200")
+	assert.Equal(t, "80.00", formatAmount(rolloverAmount(unspent, base, rolloverFull, 0)))
+	assert.Equal(t, "50.00", formatAmount(rolloverAmount(unspent, base, rolloverCapped, 25)))
+	assert.Equal(t, "80.00", formatAmount(rolloverAmount(unspent, base, rolloverCapped, 100)))
+	assert.Equal(t, "0.00", formatAmount(rolloverAmount(unspent, base, rolloverNone, 0)))
+	assert.Equal(t, "0.00", formatAmount(rolloverAmount(parseAmount("-10"), base, rolloverFull, 0)))
+}
+
+func TestLimitsWithin(t *testing.T) {
+	january := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	yearly := BudgetLimit{Id: "year", Start: january, End: time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)}
+	monthly := BudgetLimit{Id: "month", Start: january, End: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)}
+
+	limits := limitsWithin(&BudgetLimitList{Data: []BudgetLimit{yearly, monthly}}, january, january.AddDate(0, 1, -1))
+	require.Len(t, limits, 1)
+	assert.Equal(t, "month", limits[0].Id)
+	assert.Nil(t, limitsWithin(nil, january, january))
+}
+
+func TestBudgetRollover_Apply(t *testing.T) {
+	var mu sync.Mutex
+	var stored []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/budgets/10/limits":
+			body, _ := io.ReadAll(r.Body)
+			mu.Lock()
+			stored = append(stored, string(body))
+			mu.Unlock()
+			w.Write([]byte(`{"data":{"type":"budget_limits","id":"99","attributes":{"amount":"350.00",` +
+				`"start":"2024-02-01T00:00:00Z","end":"2024-02-29T00:00:00Z"}}}`))
+		case r.URL.Path == "/v1/budget-limits" && r.URL.Query().Get("start") == "2024-01-01":
+			w.Write([]byte(`{"data":[{"type":"budget_limits","id":"1","attributes":{"amount":"300.00",` +
+				`"budget_id":"10","currency_code":"EUR","spent":"-50.00",` +
+				`"start":"2024-01-01T00:00:00Z","end":"2024-01-31T00:00:00Z"}}],"meta":{}}`))
+		case r.URL.Path == "/v1/budgets":
+			w.Write([]byte(`{"data":[{"type":"budgets","id":"10","attributes":{"name":"Groceries"}}],` +
+				`"meta":{"pagination":{"total_pages":1}}}`))
+		default:
+			w.Write([]byte(`{"data":[],"meta":{}}`))
+		}
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	result, _, err := server.handleBudgetRollover(context.Background(), nil, BudgetRolloverArgs{
+		Month:    "2024-02",
+		Strategy: "full",
+		Apply:    true,
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content[0].(*mcp.TextContent).Text)
+
+	var report BudgetRolloverReport
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &report))
+	require.Len(t, report.Entries, 1)
+	entry := report.Entries[0]
+	assert.Equal(t, "Groceries", entry.BudgetName)
+	assert.Equal(t, rolloverActionCreate, entry.Action)
+	assert.Equal(t, "550.00", entry.NewAmount)
+	assert.True(t, entry.Applied, entry.Error)
+
+	require.Len(t, stored, 1)
+	assert.True(t, strings.Contains(stored[0], `"notes":"Rollover from 2024-01: 250.00"`), stored[0])
+	assert.True(t, strings.Contains(stored[0], `"start":"2024-02-01"`), stored[0])
+}
+
+func TestBudgetRollover_InvalidArguments(t *testing.T) {
+	server := &FireflyMCPServer{}
+
+	result, _, err := server.handleBudgetRollover(context.Background(), nil, BudgetRolloverArgs{Strategy: "double"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	result, _, err = server.handleBudgetRollover(context.Background(), nil, BudgetRolloverArgs{Month: "February"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "YYYY-MM")
+}
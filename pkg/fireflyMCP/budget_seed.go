@@ -0,0 +1,292 @@
+package fireflyMCP
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/dezer32/mcp-firefly-iii/pkg/client"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// maxSeedMonths is the largest number of months seed_budget_limits fills at once
+const maxSeedMonths = 24
+
+// Seed actions reported per budget limit and month
+const (
+	seedActionCreate = "create"
+	seedActionUpdate = "update"
+	seedActionKeep   = "keep" // The month already has a limit and overwrite is off
+	seedActionNone   = "none" // The month already has a limit of the same amount
+)
+
+// SeedBudgetLimitsArgs represents the arguments for the seed_budget_limits tool
+type SeedBudgetLimitsArgs struct {
+	From      string   `json:"from" jsonschema:"Month whose budget limits are copied (YYYY-MM, required)"`
+	Start     string   `json:"start" jsonschema:"First month receiving the limits (YYYY-MM, required)"`
+	End       string   `json:"end,omitempty" jsonschema:"Last month receiving the limits (YYYY-MM, default: start, at most 24 months)"`
+	Scale     string   `json:"scale,omitempty" jsonschema:"Factor the amounts are multiplied with, e.g. 1.05 for 5% more (default: 1)"`
+	Budgets   []string `json:"budgets,omitempty" jsonschema:"Budget IDs to copy (default: all budgets with a limit in the from month)"`
+	Overwrite bool     `json:"overwrite,omitempty" jsonschema:"Change the amount of limits the months already have (default: false, keep them)"`
+	Apply     bool     `json:"apply,omitempty" jsonschema:"Create or change the budget limits (default: false, only preview)"`
+}
+
+// BudgetSeedEntry is the limit of one budget, currency and month
+type BudgetSeedEntry struct {
+	Month          string `json:"month"`
+	BudgetId       string `json:"budget_id"`
+	BudgetName     string `json:"budget_name,omitempty"`
+	CurrencyCode   string `json:"currency_code"`
+	SourceAmount   string `json:"source_amount"`
+	Amount         string `json:"amount"`
+	ExistingId     string `json:"existing_id,omitempty"`
+	ExistingAmount string `json:"existing_amount,omitempty"`
+	Action         string `json:"action"`
+	Applied        bool   `json:"applied"`
+	Error          string `json:"error,omitempty"`
+
+	existingNotes *string // Notes of the existing limit, kept when it is changed
+}
+
+// BudgetSeedReport is the result of seed_budget_limits
+type BudgetSeedReport struct {
+	From      string            `json:"from"`
+	Start     string            `json:"start"`
+	End       string            `json:"end"`
+	Scale     string            `json:"scale"`
+	Overwrite bool              `json:"overwrite"`
+	Apply     bool              `json:"apply"`
+	Entries   []BudgetSeedEntry `json:"entries"`
+	Created   int               `json:"created"`
+	Updated   int               `json:"updated"`
+	Failed    int               `json:"failed,omitempty"`
+}
+
+// handleSeedBudgetLimits copies the monthly budget limits of one month to a
+// range of months, optionally scaled
+func (s *FireflyMCPServer) handleSeedBudgetLimits(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args SeedBudgetLimitsArgs,
+) (*mcp.CallToolResult, any, error) {
+	if args.From == "" || args.Start == "" {
+		return newErrorResult("Error: from and start are required")
+	}
+	from, err := parseSeedMonth("from", args.From)
+	if err != nil {
+		return newErrorResult(err.Error())
+	}
+	start, err := parseSeedMonth("start", args.Start)
+	if err != nil {
+		return newErrorResult(err.Error())
+	}
+	end := start
+	if args.End != "" {
+		if end, err = parseSeedMonth("end", args.End); err != nil {
+			return newErrorResult(err.Error())
+		}
+	}
+	if end.Before(start) {
+		return newErrorResult("Error: end must not be before start")
+	}
+	months := monthsBetween(start, end)
+	if months > maxSeedMonths {
+		return newErrorResult(fmt.Sprintf("Error: at most %d months can be seeded at once", maxSeedMonths))
+	}
+	if !from.Before(start) && !from.After(end) {
+		return newErrorResult("Error: the from month must not be one of the months receiving the limits")
+	}
+	if args.Scale == "" {
+		args.Scale = "1"
+	}
+	scale, ok := parseDecimal(args.Scale)
+	if !ok || scale.Sign() <= 0 {
+		return newErrorResult("Error: scale must be a positive number")
+	}
+
+	sourceLimits, err := s.fetchBudgetLimits(ctx, req, from, from.AddDate(0, 1, -1))
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error listing budget limits of %s: %v", from.Format("2006-01"), err))
+	}
+	targetLimits, err := s.fetchBudgetLimits(ctx, req, start, end.AddDate(0, 1, -1))
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error listing budget limits of %s to %s: %v",
+			start.Format("2006-01"), end.Format("2006-01"), err))
+	}
+
+	report := &BudgetSeedReport{
+		From:      from.Format("2006-01"),
+		Start:     start.Format("2006-01"),
+		End:       end.Format("2006-01"),
+		Scale:     args.Scale,
+		Overwrite: args.Overwrite,
+		Apply:     args.Apply,
+	}
+	report.Entries = buildBudgetSeed(
+		limitsWithin(sourceLimits, from, from.AddDate(0, 1, -1)),
+		targetLimits, start, months, scale, args.Budgets, args.Overwrite,
+	)
+
+	names := s.catalogNames(ctx, req, catalogBudgets)
+	for i := range report.Entries {
+		report.Entries[i].BudgetName = names[report.Entries[i].BudgetId]
+	}
+
+	if args.Apply {
+		apiClient, err := s.getClient(ctx, req)
+		if err != nil {
+			return newErrorResult(fmt.Sprintf("Failed to get API client: %v", err))
+		}
+		for i := range report.Entries {
+			entry := &report.Entries[i]
+			s.applyBudgetSeed(ctx, apiClient, entry, report.From)
+			switch {
+			case entry.Error != "":
+				report.Failed++
+			case entry.Applied && entry.Action == seedActionCreate:
+				report.Created++
+			case entry.Applied:
+				report.Updated++
+			}
+		}
+	}
+
+	return newSuccessResult(report)
+}
+
+// parseSeedMonth parses a YYYY-MM month argument to its first day
+func parseSeedMonth(name, month string) (time.Time, error) {
+	parsed, err := time.Parse("2006-01", month)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Error: %s must be in format YYYY-MM", name)
+	}
+	return parsed, nil
+}
+
+// buildBudgetSeed computes the limit of every source limit in every month and
+// the action on the limit the month may already have
+func buildBudgetSeed(
+	source []BudgetLimit,
+	targets *BudgetLimitList,
+	start time.Time,
+	months int,
+	scale decimal,
+	budgets []string,
+	overwrite bool,
+) []BudgetSeedEntry {
+	selected := make(map[string]bool, len(budgets))
+	for _, id := range budgets {
+		selected[id] = true
+	}
+
+	entries := []BudgetSeedEntry{}
+	for i := 0; i < months; i++ {
+		monthStart := start.AddDate(0, i, 0)
+		monthEnd := monthStart.AddDate(0, 1, -1)
+		existing := make(map[string]BudgetLimit)
+		for _, limit := range limitsWithin(targets, monthStart, monthEnd) {
+			existing[limit.BudgetId+"/"+limit.CurrencyCode] = limit
+		}
+
+		for _, limit := range source {
+			if len(selected) > 0 && !selected[limit.BudgetId] {
+				continue
+			}
+			amount := parseAmount(limit.Amount).Mul(scale)
+			entry := BudgetSeedEntry{
+				Month:        monthStart.Format("2006-01"),
+				BudgetId:     limit.BudgetId,
+				CurrencyCode: limit.CurrencyCode,
+				SourceAmount: formatAmount(parseAmount(limit.Amount)),
+				Amount:       formatAmount(amount),
+				Action:       seedActionCreate,
+			}
+			if current, ok := existing[limit.BudgetId+"/"+limit.CurrencyCode]; ok {
+				entry.ExistingId = current.Id
+				entry.ExistingAmount = formatAmount(parseAmount(current.Amount))
+				entry.existingNotes = current.Notes
+				switch {
+				case entry.ExistingAmount == entry.Amount:
+					entry.Action = seedActionNone
+				case overwrite:
+					entry.Action = seedActionUpdate
+				default:
+					entry.Action = seedActionKeep
+				}
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Month != entries[j].Month {
+			return entries[i].Month < entries[j].Month
+		}
+		if entries[i].BudgetId != entries[j].BudgetId {
+			return entries[i].BudgetId < entries[j].BudgetId
+		}
+		return entries[i].CurrencyCode < entries[j].CurrencyCode
+	})
+	return entries
+}
+
+// applyBudgetSeed creates or updates the limit of an entry and records the outcome
+func (s *FireflyMCPServer) applyBudgetSeed(
+	ctx context.Context,
+	apiClient *client.ClientWithResponses,
+	entry *BudgetSeedEntry,
+	from string,
+) {
+	if entry.Action != seedActionCreate && entry.Action != seedActionUpdate {
+		return
+	}
+	start, _ := time.Parse("2006-01", entry.Month)
+	end := start.AddDate(0, 1, -1)
+	marker := fmt.Sprintf("Seeded from %s", from)
+
+	if entry.Action == seedActionCreate {
+		currencyCode := entry.CurrencyCode
+		resp, err := apiClient.StoreBudgetLimitWithResponse(ctx, entry.BudgetId, &client.StoreBudgetLimitParams{},
+			client.StoreBudgetLimitJSONRequestBody{
+				Amount:       entry.Amount,
+				CurrencyCode: &currencyCode,
+				Start:        openapi_types.Date{Time: start},
+				End:          openapi_types.Date{Time: end},
+				Notes:        &marker,
+			})
+		if err != nil {
+			entry.Error = fmt.Sprintf("Error creating budget limit: %v", err)
+			return
+		}
+		if !isSuccessStatus(resp.StatusCode()) {
+			entry.Error = fmt.Sprintf("API error %d: %s", resp.StatusCode(), s.upstreamError(resp.Body))
+			return
+		}
+		entry.Applied = true
+		return
+	}
+
+	notes := marker
+	if existing := getStringValue(entry.existingNotes); existing != "" {
+		notes = existing + "\n" + marker
+	}
+	resp, err := apiClient.UpdateBudgetLimitWithResponse(ctx, entry.BudgetId, entry.ExistingId,
+		&client.UpdateBudgetLimitParams{},
+		client.UpdateBudgetLimitJSONRequestBody{
+			Amount: entry.Amount,
+			Start:  start,
+			End:    end,
+			Notes:  &notes,
+		})
+	if err != nil {
+		entry.Error = fmt.Sprintf("Error updating budget limit: %v", err)
+		return
+	}
+	if !isSuccessStatus(resp.StatusCode()) {
+		entry.Error = fmt.Sprintf("API error %d: %s", resp.StatusCode(), s.upstreamError(resp.Body))
+		return
+	}
+	entry.Applied = true
+}
@@ -0,0 +1,124 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildBudgetSeed(t *testing.T) {
+	january := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	february := january.AddDate(0, 1, 0)
+	march := january.AddDate(0, 2, 0)
+	source := []BudgetLimit{
+		rolloverLimit("1", "10", "300", "0", january, nil),
+		rolloverLimit("2", "20", "100", "0", january, nil),
+	}
+	targets := &BudgetLimitList{Data: []BudgetLimit{
+		rolloverLimit("5", "10", "250.00", "0", february, nil),
+		rolloverLimit("6", "10", "315.00", "0", march, nil),
+	}}
+
+	entries := buildBudgetSeed(source, targets, february, 2, parseAmount("1.05"), nil, false)
+	require.Len(t, entries, 4)
+	assert.Equal(t, BudgetSeedEntry{Month: "2024-02", BudgetId: "10", CurrencyCode: "EUR", SourceAmount: "300.00", Amount: "315.00",
+		ExistingId: "5", ExistingAmount: "250.00", Action: seedActionKeep}, entries[0])
+	assert.Equal(t, seedActionCreate, entries[1].Action)
+	assert.Equal(t, "105.00", entries[1].Amount)
+	assert.Equal(t, "2024-03", entries[2].Month)
+	assert.Equal(t, seedActionNone, entries[2].Action, "the month already has the amount")
+	assert.Equal(t, seedActionCreate, entries[3].Action)
+
+	entries = buildBudgetSeed(source, targets, february, 2, decimalFromInt(1), []string{"10"}, true)
+	require.Len(t, entries, 2)
+	assert.Equal(t, seedActionUpdate, entries[0].Action)
+	assert.Equal(t, seedActionUpdate, entries[1].Action)
+}
+
+func TestSeedBudgetLimits_Apply(t *testing.T) {
+	var mu sync.Mutex
+	var stored []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/budgets/10/limits":
+			body, _ := io.ReadAll(r.Body)
+			mu.Lock()
+			stored = append(stored, string(body))
+			mu.Unlock()
+			w.Write([]byte(`{"data":{"type":"budget_limits","id":"99","attributes":{"amount":"300.00"}}}`))
+		case r.URL.Path == "/v1/budget-limits" && r.URL.Query().Get("start") == "2024-01-01":
+			w.Write([]byte(`{"data":[{"type":"budget_limits","id":"1","attributes":{"amount":"300.00",` +
+				`"budget_id":"10","currency_code":"EUR","start":"2024-01-01T00:00:00Z","end":"2024-01-31T00:00:00Z"}}],"meta":{}}`))
+		case r.URL.Path == "/v1/budget-limits":
+			w.Write([]byte(`{"data":[{"type":"budget_limits","id":"3","attributes":{"amount":"280.00",` +
+				`"budget_id":"10","currency_code":"EUR","start":"2024-03-01T00:00:00Z","end":"2024-03-31T00:00:00Z"}}],"meta":{}}`))
+		case r.URL.Path == "/v1/budgets":
+			w.Write([]byte(`{"data":[{"type":"budgets","id":"10","attributes":{"name":"Groceries"}}],` +
+				`"meta":{"pagination":{"total_pages":1}}}`))
+		default:
+			w.Write([]byte(`{"data":[],"meta":{}}`))
+		}
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	seed := func(apply bool) BudgetSeedReport {
+		result, _, err := server.handleSeedBudgetLimits(context.Background(), nil, SeedBudgetLimitsArgs{
+			From: "2024-01", Start: "2024-02", End: "2024-04", Apply: apply,
+		})
+		require.NoError(t, err)
+		require.False(t, result.IsError, result.Content[0].(*mcp.TextContent).Text)
+		var report BudgetSeedReport
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &report))
+		return report
+	}
+
+	report := seed(false)
+	require.Len(t, report.Entries, 3)
+	assert.Equal(t, "Groceries", report.Entries[0].BudgetName)
+	assert.Equal(t, seedActionKeep, report.Entries[1].Action)
+	assert.Empty(t, stored, "nothing is created without apply")
+
+	report = seed(true)
+	assert.Equal(t, 2, report.Created)
+	assert.Zero(t, report.Updated)
+	require.Len(t, stored, 2)
+	assert.Contains(t, stored[0], `"start":"2024-02-01"`)
+	assert.Contains(t, stored[0], `"notes":"Seeded from 2024-01"`)
+	assert.Contains(t, stored[1], `"end":"2024-04-30"`)
+}
+
+func TestSeedBudgetLimits_InvalidArguments(t *testing.T) {
+	server := &FireflyMCPServer{}
+	tests := []struct {
+		args     SeedBudgetLimitsArgs
+		expected string
+	}{
+		{SeedBudgetLimitsArgs{Start: "2024-02"}, "required"},
+		{SeedBudgetLimitsArgs{From: "January", Start: "2024-02"}, "YYYY-MM"},
+		{SeedBudgetLimitsArgs{From: "2024-01", Start: "2024-02", End: "2026-02"}, "at most 24 months"},
+		{SeedBudgetLimitsArgs{From: "2024-01", Start: "2024-02", End: "2024-01"}, "end must not be before start"},
+		{SeedBudgetLimitsArgs{From: "2024-03", Start: "2024-02", End: "2024-12"}, "must not be one of"},
+		{SeedBudgetLimitsArgs{From: "2024-01", Start: "2024-02", Scale: "-1"}, "scale"},
+	}
+	for _, tt := range tests {
+		result, _, err := server.handleSeedBudgetLimits(context.Background(), nil, tt.args)
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, tt.expected)
+	}
+}
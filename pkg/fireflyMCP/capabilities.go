@@ -0,0 +1,200 @@
+package fireflyMCP
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// capability is an optional part of the Firefly III API that some builds or
+// configurations disable, with the tools that depend on it
+type capability struct {
+	name  string
+	path  string // Path of its endpoints, below server.url
+	probe string // Cheap GET request that fails with 404 when it is missing
+	tools []string
+}
+
+// capabilities lists the optional APIs tools depend on
+var capabilities = []capability{
+	{
+		name:  "insights",
+		path:  "/v1/insight/",
+		probe: "/v1/insight/expense/total?start=2000-01-01&end=2000-01-01",
+		tools: []string{
+			"expense_category_insights", "expense_total_insights", "category_rollup_insights",
+			"expense_asset_insights", "income_asset_insights",
+			"account_stats", "allocate_remaining", "verify_consistency", "income_expense_trend",
+		},
+	},
+}
+
+// capabilityTracker remembers which capabilities the Firefly III instance lacks
+type capabilityTracker struct {
+	mu          sync.Mutex
+	unavailable map[string]int                 // Capability name -> status it answered with
+	onMissing   func(c capability, status int) // Called once for every capability found missing
+}
+
+func newCapabilityTracker() *capabilityTracker {
+	return &capabilityTracker{unavailable: make(map[string]int)}
+}
+
+// missing records that a capability answered with a status meaning it is not there
+func (t *capabilityTracker) missing(c capability, status int) {
+	t.mu.Lock()
+	_, known := t.unavailable[c.name]
+	t.unavailable[c.name] = status
+	onMissing := t.onMissing
+	t.mu.Unlock()
+	if !known && onMissing != nil {
+		onMissing(c, status)
+	}
+}
+
+// status returns the status a capability answered with when it is unavailable
+func (t *capabilityTracker) status(name string) (int, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	status, ok := t.unavailable[name]
+	return status, ok
+}
+
+// unavailableNames returns the names of the capabilities found missing, in order
+func (t *capabilityTracker) unavailableNames() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	names := make([]string, 0, len(t.unavailable))
+	for name := range t.unavailable {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// capabilityOf returns the capability a request is made to
+func capabilityOf(req *http.Request) (capability, bool) {
+	for _, c := range capabilities {
+		if strings.Contains(req.URL.Path, c.path) {
+			return c, true
+		}
+	}
+	return capability{}, false
+}
+
+// capabilityTransport marks a capability unavailable when Firefly III answers a
+// request to it with 404 (route not registered) or 501 (not implemented)
+type capabilityTransport struct {
+	base    http.RoundTripper
+	tracker *capabilityTracker
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *capabilityTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusNotImplemented {
+		if c, ok := capabilityOf(req); ok {
+			t.tracker.missing(c, resp.StatusCode)
+		}
+	}
+	return resp, nil
+}
+
+// toolCapability returns the capability a tool depends on
+func toolCapability(tool string) (capability, bool) {
+	for _, c := range capabilities {
+		if slices.Contains(c.tools, tool) {
+			return c, true
+		}
+	}
+	return capability{}, false
+}
+
+// capabilityError explains that a tool cannot work on this Firefly III instance
+func capabilityError(tool string, c capability, status int) string {
+	return fmt.Sprintf(
+		"Error: %s is unavailable: this Firefly III instance does not provide the %s API (%s answered %d). "+
+			"It may be disabled in its configuration or missing from its version.",
+		tool, c.name, c.path, status,
+	)
+}
+
+// withCapabilityGuard returns a capability-specific error for tools whose
+// capability is missing, instead of the API error of the failing request
+func withCapabilityGuard[In any](s *FireflyMCPServer, name string, handler mcp.ToolHandlerFor[In, any]) mcp.ToolHandlerFor[In, any] {
+	c, ok := toolCapability(name)
+	if !ok {
+		return handler
+	}
+	return func(ctx context.Context, req *mcp.CallToolRequest, args In) (*mcp.CallToolResult, any, error) {
+		if status, missing := s.capabilities.status(c.name); missing {
+			return newErrorResult(capabilityError(name, c, status))
+		}
+		result, out, err := handler(ctx, req, args)
+		// The call itself may have found the capability missing
+		if result != nil && result.IsError {
+			if status, missing := s.capabilities.status(c.name); missing {
+				return newErrorResult(capabilityError(name, c, status))
+			}
+		}
+		return result, out, err
+	}
+}
+
+// markToolsUnavailable lists the tools of a missing capability again with a
+// description saying they are unavailable, which notifies clients of the change
+func (s *FireflyMCPServer) markToolsUnavailable(c capability, status int) {
+	slog.Warn("Firefly III API unavailable, its tools are marked unavailable",
+		"capability", c.name, "status", status, "tools", c.tools)
+	for name, registered := range s.tools {
+		toolName := name
+		if registered.aliasOf != "" {
+			toolName = registered.aliasOf
+		}
+		if !slices.Contains(c.tools, toolName) || registered.relist == nil {
+			continue
+		}
+		tool := *registered.tool
+		tool.Description = fmt.Sprintf("UNAVAILABLE on this Firefly III instance (no %s API). %s", c.name, tool.Description)
+		registered.relist(&tool)
+	}
+}
+
+// ProbeCapabilities requests every optional API once, so tools depending on a
+// missing one are marked unavailable before they are first called. It returns
+// the names of the missing capabilities. Without an API token nothing is probed.
+func (s *FireflyMCPServer) ProbeCapabilities(ctx context.Context) []string {
+	config := s.currentConfig()
+	if config.API.Token == "" {
+		return nil
+	}
+	var missing []string
+	for _, c := range capabilities {
+		httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(config.Server.URL, "/")+c.probe, nil)
+		if err != nil {
+			continue
+		}
+		httpReq.Header.Set("Authorization", "Bearer "+config.API.Token)
+		httpReq.Header.Set("Accept", "application/json")
+		resp, err := s.httpClient.Do(httpReq)
+		if err != nil {
+			slog.Warn("Firefly III capability probe failed", "capability", c.name, "error", err)
+			continue
+		}
+		resp.Body.Close()
+		if _, ok := s.capabilities.status(c.name); ok {
+			missing = append(missing, c.name)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,114 @@
+package fireflyMCP
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCapabilityTestServer serves a Firefly III build without the insight API
+func newCapabilityTestServer(t *testing.T) (*FireflyMCPServer, *mcp.ClientSession) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/v1/insight/") {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"Resource not found","exception":"NotFoundHttpException"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		w.Write([]byte(`{"data":[],"meta":{"pagination":{"total":0,"count":0,"per_page":50,"current_page":1,"total_pages":1}}}`))
+	}))
+	t.Cleanup(ts.Close)
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.MCPServer().Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { serverSession.Close() })
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { session.Close() })
+	return server, session
+}
+
+func toolDescription(t *testing.T, session *mcp.ClientSession, name string) string {
+	for tool, err := range session.Tools(context.Background(), nil) {
+		require.NoError(t, err)
+		if tool.Name == name {
+			return tool.Description
+		}
+	}
+	t.Fatalf("tool %s not listed", name)
+	return ""
+}
+
+func TestCapabilities_MissingOnFirstUse(t *testing.T) {
+	_, session := newCapabilityTestServer(t)
+	ctx := context.Background()
+	assert.NotContains(t, toolDescription(t, session, "expense_total_insights"), "UNAVAILABLE")
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "expense_total_insights", Arguments: map[string]any{"period": "this_month"}})
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	text := result.Content[0].(*mcp.TextContent).Text
+	assert.Contains(t, text, "expense_total_insights is unavailable")
+	assert.Contains(t, text, "insights API (/v1/insight/ answered 404)")
+
+	// The other tools of the capability fail without calling Firefly III
+	result, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "expense_category_insights", Arguments: map[string]any{"period": "this_month"}})
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "expense_category_insights is unavailable")
+
+	assert.True(t, strings.HasPrefix(toolDescription(t, session, "category_rollup_insights"), "UNAVAILABLE"))
+	assert.NotContains(t, toolDescription(t, session, "list_accounts"), "UNAVAILABLE")
+
+	result, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "list_accounts", Arguments: map[string]any{}})
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+}
+
+func TestCapabilities_ProbeOnStartup(t *testing.T) {
+	server, session := newCapabilityTestServer(t)
+	assert.Equal(t, []string{"insights"}, server.ProbeCapabilities(context.Background()))
+	assert.True(t, strings.HasPrefix(toolDescription(t, session, "expense_total_insights"), "UNAVAILABLE"))
+
+	config := newPluginTestConfig()
+	config.API.Token = ""
+	config.HTTP.Enabled = true
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+	assert.Empty(t, server.ProbeCapabilities(context.Background()), "nothing is probed without a token")
+}
+
+func TestCapabilityOf(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "https://example.com/firefly/api/v1/insight/expense/category", nil)
+	c, ok := capabilityOf(req)
+	assert.True(t, ok)
+	assert.Equal(t, "insights", c.name)
+
+	_, ok = capabilityOf(httptest.NewRequest(http.MethodGet, "https://example.com/api/v1/accounts/9", nil))
+	assert.False(t, ok)
+
+	server, err := NewFireflyMCPServer(newPluginTestConfig())
+	require.NoError(t, err)
+	for _, c := range capabilities {
+		for _, tool := range c.tools {
+			assert.True(t, server.hasTool(tool), "capability %s lists unknown tool %s", c.name, tool)
+			found, ok := toolCapability(tool)
+			assert.True(t, ok)
+			assert.Equal(t, c.name, found.name)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package fireflyMCP
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultCategoryDelimiter separates parent and child in category names ("Food: Groceries")
+const defaultCategoryDelimiter = ":"
+
+// CategoryTreeArgs represents the arguments for the category_tree tool
+type CategoryTreeArgs struct{}
+
+// CategoryNode is a node of the category hierarchy. Parent nodes that do not
+// exist as categories in Firefly III have no id.
+type CategoryNode struct {
+	Name     string              `json:"name"`
+	FullName string              `json:"full_name"`
+	Id       *string             `json:"id,omitempty"`
+	Totals   []InsightTotalEntry `json:"totals,omitempty"`
+	Children []*CategoryNode     `json:"children,omitempty"`
+}
+
+// CategoryTree is the nested category hierarchy
+type CategoryTree struct {
+	Delimiter  string          `json:"delimiter"`
+	Categories []*CategoryNode `json:"categories"`
+}
+
+// categoryDelimiter returns the configured hierarchy delimiter. An empty
+// delimiter disables the hierarchy, so every category is a root.
+func (s *FireflyMCPServer) categoryDelimiter() string {
+	config := s.currentConfig()
+	if config == nil {
+		return defaultCategoryDelimiter
+	}
+	return config.Categories.Delimiter
+}
+
+// handleCategoryTree returns all categories as a tree built from their names
+func (s *FireflyMCPServer) handleCategoryTree(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args CategoryTreeArgs,
+) (*mcp.CallToolResult, any, error) {
+	apiClient, err := s.getClient(ctx, req)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Failed to get API client: %v", err))
+	}
+	categories, err := s.catalog(ctx, req, apiClient, catalogCategories)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error: %v", err))
+	}
+
+	tree := newCategoryTreeBuilder(s.categoryDelimiter())
+	for _, category := range categories {
+		id := category.Id
+		tree.node(category.Name).Id = &id
+	}
+	return newSuccessResult(tree.build())
+}
+
+// handleCategoryRollupInsights returns expense category insights aggregated to
+// every level of the category hierarchy
+func (s *FireflyMCPServer) handleCategoryRollupInsights(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args ExpenseCategoryInsightsArgs,
+) (*mcp.CallToolResult, any, error) {
+	insights, err := callTool[ExpenseCategoryInsightsArgs, InsightCategoryResponse](
+		ctx, req, s.handleExpenseCategoryInsights, args,
+	)
+	if err != nil {
+		return newErrorResult(err.Error())
+	}
+
+	tree := newCategoryTreeBuilder(s.categoryDelimiter())
+	for _, entry := range insights.Entries {
+		amount, ok := parseDecimal(entry.Amount)
+		if !ok {
+			continue
+		}
+		id := entry.Id
+		tree.node(entry.Name).Id = &id
+		tree.add(entry.Name, entry.CurrencyCode, amount)
+	}
+	return newSuccessResult(tree.build())
+}
+
+// categoryTreeBuilder builds the category hierarchy from delimited names and
+// rolls amounts up to all ancestors
+type categoryTreeBuilder struct {
+	delimiter string
+	roots     []*CategoryNode
+	nodes     map[string]*CategoryNode             // By normalized full name
+	totals    map[*CategoryNode]map[string]decimal // Per currency
+	order     map[*CategoryNode][]string           // Currency order of first appearance
+}
+
+func newCategoryTreeBuilder(delimiter string) *categoryTreeBuilder {
+	return &categoryTreeBuilder{
+		delimiter: delimiter,
+		nodes:     make(map[string]*CategoryNode),
+		totals:    make(map[*CategoryNode]map[string]decimal),
+		order:     make(map[*CategoryNode][]string),
+	}
+}
+
+// splitCategoryName splits a category name into its trimmed hierarchy segments
+func splitCategoryName(name, delimiter string) []string {
+	if delimiter == "" {
+		return []string{strings.TrimSpace(name)}
+	}
+	var segments []string
+	for _, segment := range strings.Split(name, delimiter) {
+		if segment = strings.TrimSpace(segment); segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	if len(segments) == 0 {
+		return []string{strings.TrimSpace(name)}
+	}
+	return segments
+}
+
+// path returns the nodes from the root to the category, creating missing ones
+func (b *categoryTreeBuilder) path(name string) []*CategoryNode {
+	segments := splitCategoryName(name, b.delimiter)
+	path := make([]*CategoryNode, 0, len(segments))
+	var parent *CategoryNode
+	for i, segment := range segments {
+		fullName := strings.Join(segments[:i+1], b.delimiter+" ")
+		node, ok := b.nodes[fullName]
+		if !ok {
+			node = &CategoryNode{Name: segment, FullName: fullName}
+			b.nodes[fullName] = node
+			if parent == nil {
+				b.roots = append(b.roots, node)
+			} else {
+				parent.Children = append(parent.Children, node)
+			}
+		}
+		path = append(path, node)
+		parent = node
+	}
+	return path
+}
+
+// node returns the node of an existing category, creating it and its ancestors
+// if needed. The node keeps the category's name as it is stored in Firefly III.
+func (b *categoryTreeBuilder) node(name string) *CategoryNode {
+	path := b.path(name)
+	node := path[len(path)-1]
+	node.FullName = name
+	return node
+}
+
+// add adds an amount to a category and all its ancestors
+func (b *categoryTreeBuilder) add(name, currencyCode string, amount decimal) {
+	for _, node := range b.path(name) {
+		if b.totals[node] == nil {
+			b.totals[node] = make(map[string]decimal)
+		}
+		if _, ok := b.totals[node][currencyCode]; !ok {
+			b.order[node] = append(b.order[node], currencyCode)
+		}
+		b.totals[node][currencyCode] = b.totals[node][currencyCode].Add(amount)
+	}
+}
+
+// build returns the finished tree, sorted by name at every level. Every total
+// carries its share of the sum of all root categories in the same currency.
+func (b *categoryTreeBuilder) build() *CategoryTree {
+	grandTotals := make(map[string]decimal)
+	for _, root := range b.roots {
+		for currencyCode, amount := range b.totals[root] {
+			grandTotals[currencyCode] = grandTotals[currencyCode].Add(amount)
+		}
+	}
+	for node, totals := range b.totals {
+		for _, currencyCode := range b.order[node] {
+			node.Totals = append(node.Totals, InsightTotalEntry{
+				Amount:       formatAmount(totals[currencyCode]),
+				CurrencyCode: currencyCode,
+				Share:        formatShare(totals[currencyCode], grandTotals[currencyCode]),
+			})
+		}
+	}
+	sortCategoryNodes(b.roots)
+	roots := b.roots
+	if roots == nil {
+		roots = []*CategoryNode{}
+	}
+	return &CategoryTree{Delimiter: b.delimiter, Categories: roots}
+}
+
+func sortCategoryNodes(nodes []*CategoryNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return strings.ToLower(nodes[i].Name) < strings.ToLower(nodes[j].Name)
+	})
+	for _, node := range nodes {
+		sortCategoryNodes(node.Children)
+	}
+}
@@ -0,0 +1,75 @@
+package fireflyMCP
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitCategoryName(t *testing.T) {
+	assert.Equal(t, []string{"Food", "Groceries"}, splitCategoryName("Food: Groceries", ":"))
+	assert.Equal(t, []string{"Food", "Groceries", "Organic"}, splitCategoryName("Food:Groceries : Organic", ":"))
+	assert.Equal(t, []string{"Food: Groceries"}, splitCategoryName("Food: Groceries", ""))
+	assert.Equal(t, []string{"Food"}, splitCategoryName("Food:", ":"))
+	assert.Equal(t, []string{":"}, splitCategoryName(":", ":"))
+}
+
+func TestCategoryTreeBuilder(t *testing.T) {
+	builder := newCategoryTreeBuilder(":")
+	for _, name := range []string{"Transport: Fuel", "Food:Groceries", "Food: Eating out", "Food", "Bills"} {
+		id := name
+		builder.node(name).Id = &id
+	}
+	tree := builder.build()
+
+	require.Len(t, tree.Categories, 3)
+	assert.Equal(t, "Bills", tree.Categories[0].Name)
+
+	food := tree.Categories[1]
+	assert.Equal(t, "Food", food.Name)
+	require.NotNil(t, food.Id)
+	require.Len(t, food.Children, 2)
+	assert.Equal(t, "Eating out", food.Children[0].Name)
+	assert.Equal(t, "Groceries", food.Children[1].Name)
+	assert.Equal(t, "Food:Groceries", food.Children[1].FullName, "keeps the name stored in Firefly III")
+
+	transport := tree.Categories[2]
+	assert.Nil(t, transport.Id, "parent without own category")
+	assert.Equal(t, "Transport", transport.FullName)
+	assert.Equal(t, "Transport: Fuel", transport.Children[0].FullName)
+}
+
+func TestCategoryTreeBuilder_Rollup(t *testing.T) {
+	builder := newCategoryTreeBuilder(":")
+	builder.add("Food: Groceries", "EUR", parseAmount("-100.25"))
+	builder.add("Food: Eating out", "EUR", parseAmount("-50"))
+	builder.add("Food: Eating out", "USD", parseAmount("-20"))
+	builder.add("Food", "EUR", parseAmount("-10"))
+	tree := builder.build()
+
+	require.Len(t, tree.Categories, 1)
+	food := tree.Categories[0]
+	assert.Equal(t, []InsightTotalEntry{
+		{Amount: "-160.25", CurrencyCode: "EUR", Share: "100.00"},
+		{Amount: "-20.00", CurrencyCode: "USD", Share: "100.00"},
+	}, food.Totals)
+	assert.Equal(t, []InsightTotalEntry{
+		{Amount: "-50.00", CurrencyCode: "EUR", Share: "31.20"},
+		{Amount: "-20.00", CurrencyCode: "USD", Share: "100.00"},
+	}, food.Children[0].Totals)
+}
+
+func TestCategoryDelimiter(t *testing.T) {
+	assert.Equal(t, ":", (&FireflyMCPServer{}).categoryDelimiter())
+
+	config := newPluginTestConfig()
+	config.Categories.Delimiter = "/"
+	assert.Equal(t, "/", (&FireflyMCPServer{config: config}).categoryDelimiter())
+}
+
+func TestCategoryTreeBuilder_Empty(t *testing.T) {
+	tree := newCategoryTreeBuilder(":").build()
+	assert.NotNil(t, tree.Categories)
+	assert.Empty(t, tree.Categories)
+}
@@ -0,0 +1,260 @@
+package fireflyMCP
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// GetChangeHistoryArgs represents the arguments for the get_change_history tool
+type GetChangeHistoryArgs struct {
+	TransactionId string `json:"transaction_id" jsonschema:"Transaction (group) ID (required)"`
+}
+
+// ChangeRecord is one write this server made to a transaction
+type ChangeRecord struct {
+	At     string            `json:"at"`
+	Tool   string            `json:"tool"`
+	Action string            `json:"action"` // created or updated
+	Actor  string            `json:"actor"`
+	Fields map[string]string `json:"fields"` // Field values sent to Firefly III, e.g. transactions[0].category_name
+}
+
+// ChangeHistory is the result of get_change_history
+type ChangeHistory struct {
+	TransactionId string         `json:"transaction_id"`
+	Changes       []ChangeRecord `json:"changes"`
+	Note          string         `json:"note"`
+}
+
+// changeLog keeps, per tenant and transaction, the writes made through this
+// server, written through to the storage backend. Every tenant keeps the
+// histories of at most tenants.change_histories transactions; those of its
+// least recently changed ones are forgotten. Shared storage is read again
+// before every access, so changes made through other replicas are included.
+type changeLog struct {
+	mu      sync.Mutex
+	tenants map[string]*tenantChanges
+	storage Storage
+	seq     uint64 // Incremented on every change, orders histories when loaded
+}
+
+// tenantChanges are the change histories of one tenant
+type tenantChanges struct {
+	records map[string][]ChangeRecord // By transaction group ID
+	order   []string                  // Group IDs, least recently changed first
+}
+
+// storedChanges is the history of one transaction as kept in the storage,
+// under the key tenant/group ID
+type storedChanges struct {
+	Seq     uint64         `json:"seq"`
+	Records []ChangeRecord `json:"records"`
+}
+
+// newChangeLog loads the histories kept in a storage, which may be nil
+func newChangeLog(storage Storage) *changeLog {
+	l := &changeLog{tenants: make(map[string]*tenantChanges), storage: storage}
+	l.load()
+	return l
+}
+
+// load replaces the histories with those kept in the storage. The caller must
+// hold the lock once the log is in use.
+func (l *changeLog) load() {
+	if l.storage == nil {
+		return
+	}
+	items, err := l.storage.List(storageBucketChanges)
+	if err != nil {
+		slog.Warn("Failed to load change histories from storage", "error", err)
+		return
+	}
+	l.tenants = make(map[string]*tenantChanges)
+
+	type loaded struct {
+		tenant, groupID string
+		stored          storedChanges
+	}
+	var histories []loaded
+	for _, item := range items {
+		tenant, groupID, ok := strings.Cut(item.Key, "/")
+		var stored storedChanges
+		if !ok || json.Unmarshal(item.Value, &stored) != nil {
+			slog.Warn("Skipping unreadable change history in storage", "key", item.Key)
+			continue
+		}
+		histories = append(histories, loaded{tenant: tenant, groupID: groupID, stored: stored})
+	}
+	sort.Slice(histories, func(i, j int) bool { return histories[i].stored.Seq < histories[j].stored.Seq })
+	for _, history := range histories {
+		changes := l.tenants[history.tenant]
+		if changes == nil {
+			changes = &tenantChanges{records: make(map[string][]ChangeRecord)}
+			l.tenants[history.tenant] = changes
+		}
+		changes.records[history.groupID] = history.stored.Records
+		changes.order = append(changes.order, history.groupID)
+		l.seq = max(l.seq, history.stored.Seq)
+	}
+}
+
+// add appends a record to the history of a transaction of a tenant
+func (l *changeLog) add(tenant, groupID string, record ChangeRecord, limit int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if isShared(l.storage) {
+		l.load()
+	}
+	changes := l.tenants[tenant]
+	if changes == nil {
+		changes = &tenantChanges{records: make(map[string][]ChangeRecord)}
+		l.tenants[tenant] = changes
+	}
+	if _, ok := changes.records[groupID]; ok {
+		for i, id := range changes.order {
+			if id == groupID {
+				changes.order = append(changes.order[:i], changes.order[i+1:]...)
+				break
+			}
+		}
+	}
+	changes.records[groupID] = append(changes.records[groupID], record)
+	changes.order = append(changes.order, groupID)
+	l.seq++
+	l.persist(tenant, groupID, storedChanges{Seq: l.seq, Records: changes.records[groupID]})
+	for len(changes.order) > limit {
+		delete(changes.records, changes.order[0])
+		l.persist(tenant, changes.order[0], storedChanges{})
+		changes.order = changes.order[1:]
+	}
+}
+
+// persist writes the history of a transaction to the storage, or deletes it
+// when it has no records. History is informational, so failures are only logged.
+func (l *changeLog) persist(tenant, groupID string, history storedChanges) {
+	if l.storage == nil {
+		return
+	}
+	key := tenant + "/" + groupID
+	var err error
+	if len(history.Records) == 0 {
+		err = l.storage.Delete(storageBucketChanges, key)
+	} else {
+		var value []byte
+		if value, err = json.Marshal(history); err == nil {
+			err = l.storage.Put(storageBucketChanges, key, value)
+		}
+	}
+	if err != nil {
+		slog.Warn("Failed to write change history to storage", "transaction_id", groupID, "error", err)
+	}
+}
+
+// get returns a copy of the history of a transaction of a tenant, oldest change first
+func (l *changeLog) get(tenant, groupID string) []ChangeRecord {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if isShared(l.storage) {
+		l.load()
+	}
+	if changes := l.tenants[tenant]; changes != nil {
+		return append([]ChangeRecord{}, changes.records[groupID]...)
+	}
+	return []ChangeRecord{}
+}
+
+// recordTransactionChange adds a write of a transaction group to its change history
+func (s *FireflyMCPServer) recordTransactionChange(
+	req *mcp.CallToolRequest,
+	groupID, tool, action, groupTitle string,
+	splits []TransactionSplitRequest,
+) {
+	if s.changes == nil || groupID == "" {
+		return
+	}
+	s.changes.add(s.tenantKey(req), groupID, ChangeRecord{
+		At:     time.Now().UTC().Format(time.RFC3339),
+		Tool:   tool,
+		Action: action,
+		Actor:  changeActor(req),
+		Fields: transactionChangeFields(groupTitle, splits),
+	}, s.tenantChangeHistories())
+}
+
+// changeActor describes who made a change: the MCP client and, in HTTP mode, a
+// fingerprint of the API token (never the token itself)
+func changeActor(req *mcp.CallToolRequest) string {
+	actor := "unknown client"
+	if req != nil && req.Session != nil {
+		if params := req.Session.InitializeParams(); params != nil && params.ClientInfo != nil {
+			actor = params.ClientInfo.Name
+			if params.ClientInfo.Version != "" {
+				actor += " " + params.ClientInfo.Version
+			}
+		}
+	}
+	if token := requestTokenKey(req); token != "" {
+		sum := sha256.Sum256([]byte(token))
+		actor += " (token sha256:" + hex.EncodeToString(sum[:4]) + ")"
+	}
+	return actor
+}
+
+// transactionChangeFields flattens the fields set in a store or update request,
+// keyed like transactions[0].category_name
+func transactionChangeFields(groupTitle string, splits []TransactionSplitRequest) map[string]string {
+	fields := map[string]string{}
+	if groupTitle != "" {
+		fields["group_title"] = groupTitle
+	}
+	for i, split := range splits {
+		raw, err := json.Marshal(split)
+		if err != nil {
+			continue
+		}
+		var values map[string]any
+		if err := json.Unmarshal(raw, &values); err != nil {
+			continue
+		}
+		for name, value := range values {
+			if value == nil || value == "" {
+				continue
+			}
+			key := fmt.Sprintf("transactions[%d].%s", i, name)
+			if text, ok := value.(string); ok {
+				fields[key] = text
+				continue
+			}
+			encoded, _ := json.Marshal(value)
+			fields[key] = string(encoded)
+		}
+	}
+	return fields
+}
+
+// handleGetChangeHistory returns the writes this server made to a transaction
+func (s *FireflyMCPServer) handleGetChangeHistory(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args GetChangeHistoryArgs,
+) (*mcp.CallToolResult, any, error) {
+	if args.TransactionId == "" {
+		return newErrorResult("Error: transaction_id is required")
+	}
+	return newSuccessResult(&ChangeHistory{
+		TransactionId: args.TransactionId,
+		Changes:       s.changes.get(s.tenantKey(req), args.TransactionId),
+		Note: "Only changes made through this server since it started are recorded; " +
+			"edits in the Firefly III UI or other clients are not included",
+	})
+}
@@ -0,0 +1,94 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionChangeFields(t *testing.T) {
+	fields := transactionChangeFields("Receipt", []TransactionSplitRequest{
+		{Type: "withdrawal", Amount: "12.50", CategoryName: strPtr("Food"), Tags: []string{"card"}, Reconciled: ptr(true)},
+	})
+	assert.Equal(t, map[string]string{
+		"group_title":                   "Receipt",
+		"transactions[0].type":          "withdrawal",
+		"transactions[0].amount":        "12.50",
+		"transactions[0].category_name": "Food",
+		"transactions[0].tags":          `["card"]`,
+		"transactions[0].reconciled":    "true",
+	}, fields)
+}
+
+func TestChangeLog_ForgetsLeastRecentlyChanged(t *testing.T) {
+	log := newChangeLog(nil)
+	for i := 0; i <= 10; i++ {
+		log.add("tenant", strconv.Itoa(i), ChangeRecord{Tool: "update_transaction"}, 10)
+	}
+	log.add("other", "0", ChangeRecord{Tool: "update_transaction"}, 10)
+
+	assert.Len(t, log.tenants["tenant"].records, 10)
+	assert.Empty(t, log.get("tenant", "0"))
+	assert.Len(t, log.get("tenant", "1"), 1)
+	assert.Len(t, log.get("other", "0"), 1, "tenants are partitioned")
+}
+
+func TestGetChangeHistory(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		w.Write([]byte(`{"data":{"type":"transactions","id":"40","attributes":{"transactions":[` +
+			`{"transaction_journal_id":"41","type":"withdrawal","date":"2024-03-01T00:00:00Z","amount":"12.50",` +
+			`"description":"Groceries","source_id":"1","destination_id":"5"}]}}}`))
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	result, _, err := server.handleStoreTransaction(ctx, nil, TransactionStoreRequest{
+		Transactions: []TransactionSplitRequest{
+			{Type: "withdrawal", Date: "2024-03-01", Amount: "12.50", Description: "Groceries", SourceId: strPtr("1")},
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	result, _, err = server.handleUpdateTransaction(ctx, nil, UpdateTransactionArgs{
+		ID: "40",
+		TransactionUpdateRequest: TransactionUpdateRequest{
+			Transactions: []TransactionSplitRequest{{CategoryName: strPtr("Food")}},
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	result, _, err = server.handleGetChangeHistory(ctx, nil, GetChangeHistoryArgs{TransactionId: "40"})
+	require.NoError(t, err)
+	var history ChangeHistory
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &history))
+
+	require.Len(t, history.Changes, 2)
+	assert.Equal(t, "created", history.Changes[0].Action)
+	assert.Equal(t, "store_transaction", history.Changes[0].Tool)
+	assert.Equal(t, "Groceries", history.Changes[0].Fields["transactions[0].description"])
+	assert.Equal(t, "updated", history.Changes[1].Action)
+	assert.Equal(t, map[string]string{"transactions[0].category_name": "Food"}, history.Changes[1].Fields)
+	assert.Equal(t, "unknown client", history.Changes[1].Actor)
+	assert.NotEmpty(t, history.Note)
+
+	result, _, err = server.handleGetChangeHistory(ctx, nil, GetChangeHistoryArgs{TransactionId: "99"})
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &history))
+	assert.NotNil(t, history.Changes)
+	assert.Empty(t, history.Changes)
+}
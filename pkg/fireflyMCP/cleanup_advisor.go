@@ -0,0 +1,412 @@
+package fireflyMCP
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/dezer32/mcp-firefly-iii/pkg/client"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultCleanupMonths is the period without transactions after which
+// cleanup_advisor reports categories, tags and budgets as unused
+const defaultCleanupMonths = 12
+
+// maxCleanupMonths bounds the period cleanup_advisor analyzes
+const maxCleanupMonths = 120
+
+// Cleanup actions proposed by cleanup_advisor
+const (
+	cleanupActionDelete = "delete"
+	cleanupActionMerge  = "merge"
+)
+
+// CleanupAdvisorArgs represents the arguments for the cleanup_advisor tool
+type CleanupAdvisorArgs struct {
+	Months int `json:"months,omitempty" jsonschema:"Categories, tags and budgets without transactions in this many months are unused (default: 12, max: 120)"`
+}
+
+// CleanupItem is one proposed cleanup of a category, tag, budget or account
+type CleanupItem struct {
+	Kind          string `json:"kind"` // category, tag, budget or account
+	Id            string `json:"id"`
+	Name          string `json:"name"`
+	Action        string `json:"action"` // delete or merge
+	Reason        string `json:"reason"`
+	MergeIntoId   string `json:"merge_into_id,omitempty"`
+	MergeIntoName string `json:"merge_into_name,omitempty"`
+	Request       string `json:"request"` // Firefly III API call executing the action
+	Caution       string `json:"caution,omitempty"`
+}
+
+// CleanupPlan is the result of the cleanup_advisor tool
+type CleanupPlan struct {
+	Months                int                   `json:"months"`
+	Start                 string                `json:"start"`
+	End                   string                `json:"end"`
+	TransactionCount      int                   `json:"transaction_count"`
+	TransactionsTruncated bool                  `json:"transactions_truncated,omitempty"`
+	Items                 []CleanupItem         `json:"items"`
+	Warnings              []string              `json:"warnings"`
+	Steps                 []CompositeStepStatus `json:"steps"`
+}
+
+// cleanupAccount is an account as cleanup_advisor needs it
+type cleanupAccount struct {
+	Id      string
+	Name    string
+	Type    client.ShortAccountTypeProperty
+	Active  bool
+	Balance decimal
+}
+
+// cleanupUsage holds what the transactions of the period used
+type cleanupUsage struct {
+	categories map[string]bool
+	tags       map[string]bool
+	budgets    map[string]bool
+	accounts   map[string]int // Splits per account ID
+}
+
+// handleCleanupAdvisor finds unused categories and tags, empty budgets,
+// inactive accounts without balance and duplicate-looking expense accounts,
+// and returns them as a cleanup plan. Nothing is changed.
+func (s *FireflyMCPServer) handleCleanupAdvisor(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args CleanupAdvisorArgs,
+) (*mcp.CallToolResult, any, error) {
+	months := args.Months
+	if months == 0 {
+		months = defaultCleanupMonths
+	}
+	if months < 1 || months > maxCleanupMonths {
+		return newErrorResult(fmt.Sprintf("Error: months must be between 1 and %d", maxCleanupMonths))
+	}
+	end := s.today()
+	start := end.AddDate(0, -months, 0)
+	startStr, endStr := start.Format("2006-01-02"), end.Format("2006-01-02")
+
+	apiClient, err := s.getClient(ctx, req)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Failed to get API client: %v", err))
+	}
+
+	run := newCompositeRun(ctx, 6)
+
+	var transactions []Transaction
+	truncated := false
+	transactionsOK := run.step("transactions", func(ctx context.Context) error {
+		transactions, truncated, err = s.fetchTransactions(ctx, req, ListTransactionsArgs{DateRange: DateRange{Start: startStr, End: endStr}})
+		return err
+	})
+
+	var categories, budgets []catalogItem
+	run.step("categories", func(ctx context.Context) error {
+		categories, err = s.catalog(ctx, req, apiClient, catalogCategories)
+		return err
+	})
+
+	var tags []Tag
+	run.step("tags", func(ctx context.Context) error {
+		tags, _, err = fetchPages(ctx, s.pageParallelism(), func(ctx context.Context, page int) ([]Tag, int, error) {
+			return fetchCleanupTags(ctx, apiClient, page)
+		})
+		return err
+	})
+
+	run.step("budgets", func(ctx context.Context) error {
+		budgets, err = s.catalog(ctx, req, apiClient, catalogBudgets)
+		return err
+	})
+
+	var limits *BudgetLimitList
+	limitsOK := run.step("budget_limits", func(ctx context.Context) error {
+		limits, err = s.fetchBudgetLimits(ctx, req, start, end)
+		return err
+	})
+
+	var accounts []cleanupAccount
+	run.step("accounts", func(ctx context.Context) error {
+		accounts, _, err = fetchPages(ctx, s.pageParallelism(), func(ctx context.Context, page int) ([]cleanupAccount, int, error) {
+			return fetchCleanupAccounts(ctx, apiClient, page)
+		})
+		return err
+	})
+
+	plan := &CleanupPlan{
+		Months:                months,
+		Start:                 startStr,
+		End:                   endStr,
+		TransactionCount:      len(transactions),
+		TransactionsTruncated: truncated,
+		Items:                 []CleanupItem{},
+		Warnings:              []string{},
+	}
+	usage := cleanupUsageOf(transactions)
+	switch {
+	case !transactionsOK:
+		plan.Warnings = append(plan.Warnings, "Transactions could not be listed; unused categories, tags and budgets are not reported")
+	case truncated:
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf(
+			"More than %d transactions in the period; unused categories, tags and budgets are not reported, use fewer months",
+			maxCompositeTransactions,
+		))
+	default:
+		plan.Items = append(plan.Items, unusedCategories(categories, usage, months)...)
+		plan.Items = append(plan.Items, unusedTags(tags, usage, months)...)
+		if limitsOK {
+			plan.Items = append(plan.Items, emptyBudgets(budgets, limits, usage, months)...)
+		}
+	}
+	plan.Items = append(plan.Items, inactiveAccounts(accounts)...)
+	plan.Items = append(plan.Items, duplicateExpenseAccounts(accounts, usage)...)
+	plan.Steps = run.Steps
+
+	if failed := run.failed(); len(failed) > 0 {
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf(
+			"Some steps failed (%s); the plan is incomplete", strings.Join(failed, ", "),
+		))
+	}
+	return newSuccessResult(plan)
+}
+
+// fetchCleanupTags lists one page of tags
+func fetchCleanupTags(ctx context.Context, apiClient *client.ClientWithResponses, page int) ([]Tag, int, error) {
+	limit, pageParam := int32(compositePageSize), int32(page)
+	resp, err := apiClient.ListTagWithResponse(ctx, &client.ListTagParams{Limit: &limit, Page: &pageParam})
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.StatusCode() != 200 || resp.ApplicationvndApiJSON200 == nil {
+		return nil, 0, fmt.Errorf("API error %d", resp.StatusCode())
+	}
+	list := mapTagArrayToTagList(resp.ApplicationvndApiJSON200)
+	return list.Data, list.Pagination.TotalPages, nil
+}
+
+// fetchCleanupAccounts lists one page of accounts of all types
+func fetchCleanupAccounts(ctx context.Context, apiClient *client.ClientWithResponses, page int) ([]cleanupAccount, int, error) {
+	limit, pageParam := int32(compositePageSize), int32(page)
+	resp, err := apiClient.ListAccountWithResponse(ctx, &client.ListAccountParams{Limit: &limit, Page: &pageParam})
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.StatusCode() != 200 || resp.ApplicationvndApiJSON200 == nil {
+		return nil, 0, fmt.Errorf("API error %d", resp.StatusCode())
+	}
+	var accounts []cleanupAccount
+	for _, account := range resp.ApplicationvndApiJSON200.Data {
+		attributes := account.Attributes
+		accounts = append(accounts, cleanupAccount{
+			Id:      account.Id,
+			Name:    attributes.Name,
+			Type:    accountTypeKind(string(attributes.Type)),
+			Active:  attributes.Active == nil || *attributes.Active,
+			Balance: parseAmount(getStringValue(attributes.CurrentBalance)),
+		})
+	}
+	totalPages := 1
+	if pagination := resp.ApplicationvndApiJSON200.Meta.Pagination; pagination != nil && pagination.TotalPages != nil {
+		totalPages = *pagination.TotalPages
+	}
+	return accounts, totalPages, nil
+}
+
+// cleanupUsageOf collects the categories, tags, budgets and accounts the transactions use
+func cleanupUsageOf(transactions []Transaction) *cleanupUsage {
+	usage := &cleanupUsage{
+		categories: make(map[string]bool),
+		tags:       make(map[string]bool),
+		budgets:    make(map[string]bool),
+		accounts:   make(map[string]int),
+	}
+	for _, txn := range transactions {
+		if id := getStringValue(txn.CategoryId); id != "" {
+			usage.categories[id] = true
+		}
+		if id := getStringValue(txn.BudgetId); id != "" {
+			usage.budgets[id] = true
+		}
+		for _, tag := range txn.Tags {
+			usage.tags[strings.ToLower(tag)] = true
+		}
+		usage.accounts[txn.SourceId]++
+		usage.accounts[txn.DestinationId]++
+	}
+	return usage
+}
+
+// unusedCategories proposes to delete the categories no transaction of the period uses
+func unusedCategories(categories []catalogItem, usage *cleanupUsage, months int) []CleanupItem {
+	items := []CleanupItem{}
+	for _, category := range categories {
+		if usage.categories[category.Id] {
+			continue
+		}
+		items = append(items, CleanupItem{
+			Kind:    "category",
+			Id:      category.Id,
+			Name:    category.Name,
+			Action:  cleanupActionDelete,
+			Reason:  fmt.Sprintf("No transactions in the last %d months", months),
+			Request: "DELETE /v1/categories/" + category.Id,
+			Caution: "Older transactions of the category lose their category",
+		})
+	}
+	sortCleanupItems(items)
+	return items
+}
+
+// unusedTags proposes to delete the tags no transaction of the period uses
+func unusedTags(tags []Tag, usage *cleanupUsage, months int) []CleanupItem {
+	items := []CleanupItem{}
+	for _, tag := range tags {
+		if usage.tags[strings.ToLower(tag.Tag)] {
+			continue
+		}
+		items = append(items, CleanupItem{
+			Kind:    "tag",
+			Id:      tag.Id,
+			Name:    tag.Tag,
+			Action:  cleanupActionDelete,
+			Reason:  fmt.Sprintf("No transactions in the last %d months", months),
+			Request: "DELETE /v1/tags/" + tag.Id,
+			Caution: "Older transactions lose the tag",
+		})
+	}
+	sortCleanupItems(items)
+	return items
+}
+
+// emptyBudgets proposes to delete the budgets without transactions and
+// without limits in the period
+func emptyBudgets(budgets []catalogItem, limits *BudgetLimitList, usage *cleanupUsage, months int) []CleanupItem {
+	limited := make(map[string]bool)
+	if limits != nil {
+		for _, limit := range limits.Data {
+			limited[limit.BudgetId] = true
+		}
+	}
+	items := []CleanupItem{}
+	for _, budget := range budgets {
+		if usage.budgets[budget.Id] || limited[budget.Id] {
+			continue
+		}
+		items = append(items, CleanupItem{
+			Kind:    "budget",
+			Id:      budget.Id,
+			Name:    budget.Name,
+			Action:  cleanupActionDelete,
+			Reason:  fmt.Sprintf("No transactions and no limits in the last %d months", months),
+			Request: "DELETE /v1/budgets/" + budget.Id,
+			Caution: "Older transactions of the budget lose their budget; its limits are deleted",
+		})
+	}
+	sortCleanupItems(items)
+	return items
+}
+
+// inactiveAccounts proposes to delete the inactive accounts without balance
+func inactiveAccounts(accounts []cleanupAccount) []CleanupItem {
+	items := []CleanupItem{}
+	for _, account := range accounts {
+		if account.Active || account.Balance.Sign() != 0 {
+			continue
+		}
+		items = append(items, CleanupItem{
+			Kind:    "account",
+			Id:      account.Id,
+			Name:    account.Name,
+			Action:  cleanupActionDelete,
+			Reason:  fmt.Sprintf("Inactive %s account with a zero balance", account.Type),
+			Request: "DELETE /v1/accounts/" + account.Id,
+			Caution: "Deleting an account also deletes all its transactions; keep it inactive to preserve the history",
+		})
+	}
+	sortCleanupItems(items)
+	return items
+}
+
+// duplicateExpenseAccounts proposes to merge expense accounts whose names only
+// differ in case, spacing or punctuation into the most used one of them
+func duplicateExpenseAccounts(accounts []cleanupAccount, usage *cleanupUsage) []CleanupItem {
+	groups := make(map[string][]cleanupAccount)
+	for _, account := range accounts {
+		if account.Type != client.ShortAccountTypePropertyExpense {
+			continue
+		}
+		key := duplicateNameKey(account.Name)
+		if key == "" {
+			continue
+		}
+		groups[key] = append(groups[key], account)
+	}
+
+	items := []CleanupItem{}
+	for _, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+		sort.Slice(group, func(i, j int) bool {
+			if usage.accounts[group[i].Id] != usage.accounts[group[j].Id] {
+				return usage.accounts[group[i].Id] > usage.accounts[group[j].Id]
+			}
+			return lessNumericId(group[i].Id, group[j].Id)
+		})
+		keep := group[0]
+		for _, duplicate := range group[1:] {
+			items = append(items, CleanupItem{
+				Kind:          "account",
+				Id:            duplicate.Id,
+				Name:          duplicate.Name,
+				Action:        cleanupActionMerge,
+				Reason:        fmt.Sprintf("Expense account name looks like a duplicate of %q", keep.Name),
+				MergeIntoId:   keep.Id,
+				MergeIntoName: keep.Name,
+				Request:       "DELETE /v1/accounts/" + duplicate.Id,
+				Caution: fmt.Sprintf(
+					"Move its transactions to account %s with update_transaction (destination_id) before deleting it, "+
+						"or they are deleted too", keep.Id,
+				),
+			})
+		}
+	}
+	sortCleanupItems(items)
+	return items
+}
+
+// duplicateNameKey reduces an account name to its lower-case letters and digits
+func duplicateNameKey(name string) string {
+	var key strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			key.WriteRune(r)
+		}
+	}
+	return key.String()
+}
+
+// lessNumericId orders Firefly III IDs numerically, falling back to text
+func lessNumericId(a, b string) bool {
+	x, errA := strconv.Atoi(a)
+	y, errB := strconv.Atoi(b)
+	if errA != nil || errB != nil {
+		return a < b
+	}
+	return x < y
+}
+
+// sortCleanupItems orders items by name, then ID
+func sortCleanupItems(items []CleanupItem) {
+	sort.Slice(items, func(i, j int) bool {
+		if !strings.EqualFold(items[i].Name, items[j].Name) {
+			return strings.ToLower(items[i].Name) < strings.ToLower(items[j].Name)
+		}
+		return lessNumericId(items[i].Id, items[j].Id)
+	})
+}
@@ -0,0 +1,98 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCleanupAdvisor(t *testing.T) {
+	var transactionsStart string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch r.URL.Path {
+		case "/v1/transactions":
+			transactionsStart = r.URL.Query().Get("start")
+			w.Write([]byte(`{"data":[{"type":"transactions","id":"1","attributes":{"transactions":[` +
+				`{"type":"withdrawal","date":"2024-05-02T00:00:00+00:00","amount":"12.00","currency_code":"EUR",` +
+				`"description":"Lunch","source_id":"1","destination_id":"31","category_id":"5","budget_id":"10","tags":["Work"]},` +
+				`{"type":"withdrawal","date":"2024-05-03T00:00:00+00:00","amount":"8.00","currency_code":"EUR",` +
+				`"description":"Coffee","source_id":"1","destination_id":"31"}]}}],` +
+				`"meta":{"pagination":{"total":1,"count":1,"per_page":200,"current_page":1,"total_pages":1}}}`))
+		case "/v1/categories":
+			w.Write([]byte(`{"data":[{"type":"categories","id":"5","attributes":{"name":"Food"}},` +
+				`{"type":"categories","id":"6","attributes":{"name":"Hobbies"}}],"meta":{}}`))
+		case "/v1/tags":
+			w.Write([]byte(`{"data":[{"type":"tags","id":"1","attributes":{"tag":"work"}},` +
+				`{"type":"tags","id":"2","attributes":{"tag":"holiday 2019"}}],"meta":{}}`))
+		case "/v1/budgets":
+			w.Write([]byte(`{"data":[{"type":"budgets","id":"10","attributes":{"name":"Groceries"}},` +
+				`{"type":"budgets","id":"11","attributes":{"name":"Rent"}},` +
+				`{"type":"budgets","id":"12","attributes":{"name":"Wedding"}}],"meta":{}}`))
+		case "/v1/budget-limits":
+			w.Write([]byte(`{"data":[{"type":"budget_limits","id":"3","attributes":{"amount":"900.00",` +
+				`"budget_id":"11","currency_code":"EUR","start":"2024-05-01T00:00:00Z","end":"2024-05-31T00:00:00Z"}}],"meta":{}}`))
+		case "/v1/accounts":
+			w.Write([]byte(`{"data":[` +
+				`{"type":"accounts","id":"1","attributes":{"name":"Checking","type":"asset","active":true,"current_balance":"100.00"}},` +
+				`{"type":"accounts","id":"2","attributes":{"name":"Old savings","type":"asset","active":false,"current_balance":"0.00"}},` +
+				`{"type":"accounts","id":"3","attributes":{"name":"Old card","type":"asset","active":false,"current_balance":"-5.00"}},` +
+				`{"type":"accounts","id":"30","attributes":{"name":"Cafe Luna","type":"expense","active":true}},` +
+				`{"type":"accounts","id":"31","attributes":{"name":"CAFE-LUNA","type":"expense","active":true}},` +
+				`{"type":"accounts","id":"32","attributes":{"name":"cafe luna.","type":"expense","active":true}},` +
+				`{"type":"accounts","id":"40","attributes":{"name":"Bakery","type":"expense","active":true}}],"meta":{}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	result, err := server.tools["cleanup_advisor"].invoke(context.Background(), nil, []byte(`{"months":6}`))
+	require.NoError(t, err)
+	text := result.Content[0].(*mcp.TextContent).Text
+	require.False(t, result.IsError, text)
+	var plan CleanupPlan
+	require.NoError(t, json.Unmarshal([]byte(text), &plan))
+
+	assert.Equal(t, 6, plan.Months)
+	assert.Equal(t, plan.Start, transactionsStart)
+	assert.Equal(t, 2, plan.TransactionCount)
+	assert.Empty(t, plan.Warnings)
+
+	type proposal struct{ Kind, Id, Action, MergeIntoId string }
+	var proposals []proposal
+	for _, item := range plan.Items {
+		proposals = append(proposals, proposal{item.Kind, item.Id, item.Action, item.MergeIntoId})
+	}
+	assert.Equal(t, []proposal{
+		{"category", "6", cleanupActionDelete, ""},
+		{"tag", "2", cleanupActionDelete, ""},
+		{"budget", "12", cleanupActionDelete, ""},
+		{"account", "2", cleanupActionDelete, ""},
+		{"account", "30", cleanupActionMerge, "31"},
+		{"account", "32", cleanupActionMerge, "31"},
+	}, proposals, "used, limited, funded and unique entities are kept; the most used duplicate is merged into")
+	assert.Equal(t, "DELETE /v1/categories/6", plan.Items[0].Request)
+	assert.Contains(t, plan.Items[3].Caution, "deletes all its transactions")
+
+	result, err = server.tools["cleanup_advisor"].invoke(context.Background(), nil, []byte(`{"months":121}`))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestDuplicateNameKey(t *testing.T) {
+	assert.Equal(t, "cafeluna", duplicateNameKey("Cafe  Luna!"))
+	assert.Equal(t, duplicateNameKey("AMAZON.COM"), duplicateNameKey("amazon com"))
+	assert.Empty(t, duplicateNameKey("--"))
+}
@@ -0,0 +1,378 @@
+package fireflyMCP
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dezer32/mcp-firefly-iii/pkg/client"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// CloseMonthArgs represents the arguments for the close_month tool
+type CloseMonthArgs struct {
+	Month string `json:"month,omitempty" jsonschema:"Month to close (YYYY-MM, default: previous month)"`
+}
+
+// ReconcileHint lists an asset account with unreconciled transactions in the month
+type ReconcileHint struct {
+	AccountId         string `json:"account_id"`
+	AccountName       string `json:"account_name"`
+	UnreconciledCount int    `json:"unreconciled_count"`
+}
+
+// MonthCloseBudget summarizes one budget for the closed month
+type MonthCloseBudget struct {
+	Id           string `json:"id"`
+	Name         string `json:"name"`
+	Limit        string `json:"limit,omitempty"`
+	Spent        string `json:"spent"`
+	CurrencyCode string `json:"currency_code"`
+	Remaining    string `json:"remaining,omitempty"`
+	Overspent    bool   `json:"overspent"`
+}
+
+// MonthCloseReport is the result of the close_month tool
+type MonthCloseReport struct {
+	Month                     string                `json:"month"`
+	Start                     string                `json:"start"`
+	End                       string                `json:"end"`
+	TransactionCount          int                   `json:"transaction_count"`
+	TransactionsTruncated     bool                  `json:"transactions_truncated,omitempty"`
+	ReconcileHints            []ReconcileHint       `json:"reconcile_hints"`
+	UncategorizedTransactions []Transaction         `json:"uncategorized_transactions"`
+	Budgets                   []MonthCloseBudget    `json:"budgets"`
+	NetWorth                  []BasicSummary        `json:"net_worth"`
+	Anomalies                 []string              `json:"anomalies"`
+	Suggestions               []string              `json:"suggestions"`
+	Steps                     []CompositeStepStatus `json:"steps"`
+}
+
+// handleCloseMonth runs the month close workflow: reconcile hints, uncategorized
+// transactions, budget report and net worth snapshot, combined with detected
+// anomalies and follow-up suggestions into a single report
+func (s *FireflyMCPServer) handleCloseMonth(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args CloseMonthArgs,
+) (*mcp.CallToolResult, any, error) {
+	start, end, err := s.parseCloseMonth(args.Month)
+	if err != nil {
+		return newErrorResult(err.Error())
+	}
+	startStr := start.Format("2006-01-02")
+	endStr := end.Format("2006-01-02")
+
+	run := newCompositeRun(ctx, 4)
+
+	var transactions []Transaction
+	truncated := false
+	run.step("transactions", func(ctx context.Context) error {
+		transactions, truncated, err = s.fetchMonthTransactions(ctx, req, startStr, endStr)
+		return err
+	})
+
+	var budgets *BudgetList
+	run.step("budgets", func(ctx context.Context) error {
+		budgets, err = callTool[ListBudgetsArgs, BudgetList](
+			ctx, req, s.handleListBudgets, ListBudgetsArgs{DateRange: DateRange{Start: startStr, End: endStr}},
+		)
+		return err
+	})
+
+	var limits *BudgetLimitList
+	run.step("budget_limits", func(ctx context.Context) error {
+		limits, err = s.fetchBudgetLimits(ctx, req, start, end)
+		return err
+	})
+
+	var summary *BasicSummaryList
+	run.step("net_worth", func(ctx context.Context) error {
+		summary, err = callTool[GetSummaryArgs, BasicSummaryList](
+			ctx, req, s.handleGetSummary, GetSummaryArgs{DateRange: DateRange{Start: startStr, End: endStr}},
+		)
+		return err
+	})
+
+	report := buildMonthCloseReport(start.Format("2006-01"), transactions, budgets, limits, summary)
+	report.Start = startStr
+	report.End = endStr
+	report.TransactionsTruncated = truncated
+	report.Steps = run.Steps
+
+	if truncated {
+		report.Anomalies = append(report.Anomalies, fmt.Sprintf(
+			"More than %d transactions in the month; only the first %d were analyzed",
+			maxCompositeTransactions, maxCompositeTransactions,
+		))
+	}
+	if failed := run.failed(); len(failed) > 0 {
+		report.Suggestions = append(report.Suggestions, fmt.Sprintf(
+			"Some steps failed (%s); re-run close_month or use the individual tools",
+			strings.Join(failed, ", "),
+		))
+	}
+
+	return newSuccessResult(report)
+}
+
+// parseCloseMonth returns the first and last day of the month to close.
+// An empty month selects the month before the current one.
+func (s *FireflyMCPServer) parseCloseMonth(month string) (time.Time, time.Time, error) {
+	var start time.Time
+	if month == "" {
+		now := s.now()
+		start = time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, time.UTC)
+	} else {
+		parsed, err := time.Parse("2006-01", month)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("Error: month must be in format YYYY-MM")
+		}
+		start = parsed
+	}
+	end := start.AddDate(0, 1, -1)
+	return start, end, nil
+}
+
+// fetchMonthTransactions lists all transactions of the period.
+// Reports whether the result was truncated at maxCompositeTransactions.
+func (s *FireflyMCPServer) fetchMonthTransactions(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	start, end string,
+) ([]Transaction, bool, error) {
+	return s.fetchTransactions(ctx, req, ListTransactionsArgs{DateRange: DateRange{Start: start, End: end}})
+}
+
+// fetchBudgetLimits lists the budget limits of all budgets for the period
+func (s *FireflyMCPServer) fetchBudgetLimits(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	start, end time.Time,
+) (*BudgetLimitList, error) {
+	apiClient, err := s.getClient(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API client: %w", err)
+	}
+
+	resp, err := apiClient.ListBudgetLimitWithResponse(ctx, &client.ListBudgetLimitParams{
+		Start: openapi_types.Date{Time: start},
+		End:   openapi_types.Date{Time: end},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing budget limits: %w", err)
+	}
+	if resp.StatusCode() != 200 {
+		return nil, fmt.Errorf("API error: %d", resp.StatusCode())
+	}
+	return mapBudgetLimitArrayToBudgetLimitList(resp.ApplicationvndApiJSON200), nil
+}
+
+// buildMonthCloseReport analyzes the fetched data. Any of the inputs may be nil
+// when the corresponding step failed.
+func buildMonthCloseReport(
+	month string,
+	transactions []Transaction,
+	budgets *BudgetList,
+	limits *BudgetLimitList,
+	summary *BasicSummaryList,
+) *MonthCloseReport {
+	report := &MonthCloseReport{
+		Month:                     month,
+		TransactionCount:          len(transactions),
+		ReconcileHints:            buildReconcileHints(transactions),
+		UncategorizedTransactions: []Transaction{},
+		Budgets:                   buildMonthCloseBudgets(budgets, limits),
+		NetWorth:                  []BasicSummary{},
+		Anomalies:                 []string{},
+		Suggestions:               []string{},
+	}
+
+	withoutBudget := 0
+	for _, txn := range transactions {
+		if txn.Type == "transfer" {
+			continue
+		}
+		if txn.CategoryId == nil || *txn.CategoryId == "" {
+			report.UncategorizedTransactions = append(report.UncategorizedTransactions, txn)
+		}
+		if txn.Type == "withdrawal" && (txn.BudgetId == nil || *txn.BudgetId == "") {
+			withoutBudget++
+		}
+	}
+
+	if summary != nil {
+		for _, entry := range summary.Data {
+			if strings.HasPrefix(entry.Key, "net-worth-in-") {
+				report.NetWorth = append(report.NetWorth, entry)
+			}
+		}
+	}
+
+	// Anomalies and the matching follow-up suggestions
+	if n := len(report.UncategorizedTransactions); n > 0 {
+		report.Anomalies = append(report.Anomalies, fmt.Sprintf("%d transactions have no category", n))
+		report.Suggestions = append(report.Suggestions,
+			"Assign categories to the uncategorized transactions with update_transaction")
+	}
+	if withoutBudget > 0 && len(report.Budgets) > 0 {
+		report.Anomalies = append(report.Anomalies, fmt.Sprintf("%d withdrawals have no budget", withoutBudget))
+		report.Suggestions = append(report.Suggestions,
+			"Assign budgets to withdrawals without a budget so the budget report is complete")
+	}
+	for _, budget := range report.Budgets {
+		if budget.Overspent {
+			report.Anomalies = append(report.Anomalies, fmt.Sprintf(
+				"Budget %q is overspent: spent %s of %s %s",
+				budget.Name, budget.Spent, budget.Limit, budget.CurrencyCode,
+			))
+			report.Suggestions = append(report.Suggestions, fmt.Sprintf(
+				"Review spending in budget %q (list_budget_transactions id %s) or raise its limit",
+				budget.Name, budget.Id,
+			))
+		}
+	}
+	for _, duplicate := range findPossibleDuplicates(transactions) {
+		report.Anomalies = append(report.Anomalies, fmt.Sprintf(
+			"Possible duplicate: %q %s %s on %s appears %d times",
+			duplicate.Description, duplicate.Amount, duplicate.CurrencyCode,
+			duplicate.Date.Format("2006-01-02"), duplicate.count,
+		))
+	}
+	if len(report.ReconcileHints) > 0 {
+		report.Suggestions = append(report.Suggestions,
+			"Reconcile the listed asset accounts against your bank statements in Firefly III")
+	}
+
+	return report
+}
+
+// buildReconcileHints counts unreconciled transactions per asset account.
+// The asset side is the source of withdrawals, the destination of deposits and
+// both sides of transfers.
+func buildReconcileHints(transactions []Transaction) []ReconcileHint {
+	counts := make(map[string]*ReconcileHint)
+	add := func(id, name string) {
+		if id == "" {
+			return
+		}
+		hint, ok := counts[id]
+		if !ok {
+			hint = &ReconcileHint{AccountId: id, AccountName: name}
+			counts[id] = hint
+		}
+		hint.UnreconciledCount++
+	}
+
+	for _, txn := range transactions {
+		if txn.Reconciled {
+			continue
+		}
+		switch txn.Type {
+		case "withdrawal":
+			add(txn.SourceId, txn.SourceName)
+		case "deposit":
+			add(txn.DestinationId, txn.DestinationName)
+		case "transfer":
+			add(txn.SourceId, txn.SourceName)
+			add(txn.DestinationId, txn.DestinationName)
+		}
+	}
+
+	hints := make([]ReconcileHint, 0, len(counts))
+	for _, hint := range counts {
+		hints = append(hints, *hint)
+	}
+	sort.Slice(hints, func(i, j int) bool {
+		if hints[i].UnreconciledCount != hints[j].UnreconciledCount {
+			return hints[i].UnreconciledCount > hints[j].UnreconciledCount
+		}
+		return hints[i].AccountName < hints[j].AccountName
+	})
+	return hints
+}
+
+// buildMonthCloseBudgets combines budget spending with the limits of the period
+func buildMonthCloseBudgets(budgets *BudgetList, limits *BudgetLimitList) []MonthCloseBudget {
+	result := []MonthCloseBudget{}
+	if budgets == nil {
+		return result
+	}
+
+	limitByBudget := make(map[string]decimal)
+	hasLimit := make(map[string]bool)
+	if limits != nil {
+		for _, limit := range limits.Data {
+			amount, ok := parseDecimal(limit.Amount)
+			if !ok {
+				continue
+			}
+			limitByBudget[limit.BudgetId] = limitByBudget[limit.BudgetId].Add(amount)
+			hasLimit[limit.BudgetId] = true
+		}
+	}
+
+	for _, budget := range budgets.Data {
+		if !budget.Active {
+			continue
+		}
+		entry := MonthCloseBudget{
+			Id:   budget.Id,
+			Name: budget.Name,
+		}
+		if len(budget.Spent) > 0 {
+			entry.Spent = budget.Spent[0].Sum
+			entry.CurrencyCode = budget.Spent[0].CurrencyCode
+		}
+		if entry.Spent == "" {
+			entry.Spent = "0"
+		}
+		if hasLimit[budget.Id] {
+			// Firefly III reports spending as a negative amount
+			limit := limitByBudget[budget.Id]
+			remaining := limit.Sub(parseAmount(entry.Spent).Abs())
+			entry.Limit = formatAmount(limit)
+			entry.Remaining = formatAmount(remaining)
+			entry.Overspent = remaining.Sign() < 0
+		}
+		result = append(result, entry)
+	}
+	return result
+}
+
+// possibleDuplicate is a transaction that occurs more than once with the same
+// date, amount, description and source account
+type possibleDuplicate struct {
+	Transaction
+	count int
+}
+
+// findPossibleDuplicates returns transactions that look like duplicates
+func findPossibleDuplicates(transactions []Transaction) []possibleDuplicate {
+	type key struct {
+		date, amount, description, source string
+	}
+	counts := make(map[key]int)
+	first := make(map[key]Transaction)
+	var order []key
+
+	for _, txn := range transactions {
+		k := key{txn.Date.Format("2006-01-02"), txn.Amount, strings.ToLower(txn.Description), txn.SourceId}
+		if counts[k] == 0 {
+			first[k] = txn
+			order = append(order, k)
+		}
+		counts[k]++
+	}
+
+	var duplicates []possibleDuplicate
+	for _, k := range order {
+		if counts[k] > 1 {
+			duplicates = append(duplicates, possibleDuplicate{Transaction: first[k], count: counts[k]})
+		}
+	}
+	return duplicates
+}
@@ -0,0 +1,151 @@
+package fireflyMCP
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallTool(t *testing.T) {
+	handler := func(ctx context.Context, req *mcp.CallToolRequest, args GetSummaryArgs) (*mcp.CallToolResult, any, error) {
+		if args.Start == "" {
+			return newErrorResult("start is required")
+		}
+		return newSuccessResult(BasicSummaryList{Data: []BasicSummary{{Key: "net-worth-in-EUR", MonetaryValue: "10.00"}}})
+	}
+
+	summary, err := callTool[GetSummaryArgs, BasicSummaryList](context.Background(), nil, handler, GetSummaryArgs{DateRange: DateRange{Start: "2024-01-01"}})
+	require.NoError(t, err)
+	require.Len(t, summary.Data, 1)
+	assert.Equal(t, "10.00", summary.Data[0].MonetaryValue)
+
+	_, err = callTool[GetSummaryArgs, BasicSummaryList](context.Background(), nil, handler, GetSummaryArgs{})
+	require.Error(t, err)
+	assert.Equal(t, "start is required", err.Error())
+}
+
+func TestCompositeRun(t *testing.T) {
+	run := &compositeRun{}
+	assert.True(t, run.step("ok", func(ctx context.Context) error { return nil }))
+	assert.False(t, run.step("broken", func(ctx context.Context) error { return errors.New("boom") }))
+
+	require.Len(t, run.Steps, 2)
+	assert.Equal(t, "boom", run.Steps[1].Error)
+	assert.Equal(t, []string{"broken"}, run.failed())
+}
+
+func TestCompositeRun_LatencyBudget(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	run := newCompositeRun(ctx, 3)
+
+	var budget time.Duration
+	assert.False(t, run.step("slow", func(ctx context.Context) error {
+		deadline, ok := ctx.Deadline()
+		require.True(t, ok)
+		budget = time.Until(deadline)
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+	assert.LessOrEqual(t, budget, 100*time.Millisecond, "a third of the budget")
+	assert.Contains(t, run.Steps[0].Error, "latency budget")
+
+	assert.True(t, run.step("fast", func(ctx context.Context) error { return nil }))
+
+	<-ctx.Done()
+	assert.False(t, run.step("late", func(ctx context.Context) error {
+		t.Fatal("must not run after the budget is exhausted")
+		return nil
+	}))
+	assert.True(t, run.Steps[2].Skipped)
+	assert.Equal(t, []string{"slow", "late"}, run.failed())
+}
+
+func TestParseCloseMonth(t *testing.T) {
+	s := &FireflyMCPServer{location: time.UTC}
+
+	start, end, err := s.parseCloseMonth("2024-02")
+	require.NoError(t, err)
+	assert.Equal(t, "2024-02-01", start.Format("2006-01-02"))
+	assert.Equal(t, "2024-02-29", end.Format("2006-01-02"))
+
+	start, _, err = s.parseCloseMonth("")
+	require.NoError(t, err)
+	now := time.Now().UTC()
+	assert.Equal(t, time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, time.UTC), start)
+
+	_, _, err = s.parseCloseMonth("February")
+	assert.Error(t, err)
+}
+
+func TestBuildMonthCloseReport(t *testing.T) {
+	date := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	transactions := []Transaction{
+		{
+			Id: "1", Type: "withdrawal", Amount: "12.50", Description: "Lunch", Date: date,
+			SourceId: "1", SourceName: "Checking", CategoryId: strPtr("5"), BudgetId: strPtr("3"),
+		},
+		{
+			Id: "2", Type: "withdrawal", Amount: "40.00", Description: "Groceries", Date: date,
+			SourceId: "1", SourceName: "Checking",
+		},
+		{
+			Id: "3", Type: "withdrawal", Amount: "40.00", Description: "groceries", Date: date,
+			SourceId: "1", SourceName: "Checking",
+		},
+		{
+			Id: "4", Type: "transfer", Amount: "100.00", Description: "Savings", Date: date, Reconciled: true,
+			SourceId: "1", SourceName: "Checking", DestinationId: "2", DestinationName: "Savings",
+		},
+	}
+	budgets := &BudgetList{Data: []Budget{
+		{Id: "3", Name: "Food", Active: true, Spent: []BudgetSpent{{Sum: "-150.00", CurrencyCode: "EUR"}}},
+		{Id: "4", Name: "Fun", Active: true},
+		{Id: "5", Name: "Old", Active: false},
+	}}
+	limits := &BudgetLimitList{Data: []BudgetLimit{{BudgetId: "3", Amount: "100.00"}}}
+	summary := &BasicSummaryList{Data: []BasicSummary{
+		{Key: "net-worth-in-EUR", MonetaryValue: "1000.00", CurrencyCode: "EUR"},
+		{Key: "spent-in-EUR", MonetaryValue: "-92.50", CurrencyCode: "EUR"},
+	}}
+
+	report := buildMonthCloseReport("2024-01", transactions, budgets, limits, summary)
+
+	assert.Equal(t, 4, report.TransactionCount)
+	require.Len(t, report.ReconcileHints, 1)
+	assert.Equal(t, ReconcileHint{AccountId: "1", AccountName: "Checking", UnreconciledCount: 3}, report.ReconcileHints[0])
+
+	require.Len(t, report.UncategorizedTransactions, 2)
+	assert.Equal(t, "2", report.UncategorizedTransactions[0].Id)
+
+	require.Len(t, report.Budgets, 2)
+	assert.Equal(t, "100.00", report.Budgets[0].Limit)
+	assert.Equal(t, "-50.00", report.Budgets[0].Remaining)
+	assert.True(t, report.Budgets[0].Overspent)
+	assert.Equal(t, "0", report.Budgets[1].Spent)
+	assert.False(t, report.Budgets[1].Overspent)
+
+	require.Len(t, report.NetWorth, 1)
+	assert.Equal(t, "1000.00", report.NetWorth[0].MonetaryValue)
+
+	assert.Contains(t, report.Anomalies, "2 transactions have no category")
+	assert.Contains(t, report.Anomalies, "2 withdrawals have no budget")
+	assert.Contains(t, report.Anomalies, `Budget "Food" is overspent: spent -150.00 of 100.00 EUR`)
+	assert.Contains(t, report.Anomalies, `Possible duplicate: "Groceries" 40.00  on 2024-01-10 appears 2 times`)
+	assert.NotEmpty(t, report.Suggestions)
+}
+
+func TestBuildMonthCloseReportWithFailedSteps(t *testing.T) {
+	report := buildMonthCloseReport("2024-01", nil, nil, nil, nil)
+
+	assert.Equal(t, 0, report.TransactionCount)
+	assert.Empty(t, report.ReconcileHints)
+	assert.Empty(t, report.Budgets)
+	assert.Empty(t, report.NetWorth)
+	assert.Empty(t, report.Anomalies)
+}
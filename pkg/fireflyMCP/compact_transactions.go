@@ -0,0 +1,92 @@
+package fireflyMCP
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// newTransactionListResult returns a transaction list as tool result. The compact
+// form is also encoded without indentation.
+func newTransactionListResult(list *TransactionList, compact bool) (*mcp.CallToolResult, any, error) {
+	if !compact || list == nil {
+		return newSuccessResult(list)
+	}
+	result, err := json.Marshal(compactTransactionList(list))
+	if err != nil {
+		return newErrorResult("Failed to marshal response: " + err.Error())
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: string(result)},
+		},
+	}, nil, nil
+}
+
+// compactTransactionList converts a transaction list to its compact form
+func compactTransactionList(list *TransactionList) *CompactTransactionList {
+	compact := &CompactTransactionList{
+		Data:       make([]CompactTransactionGroup, len(list.Data)),
+		Pagination: list.Pagination,
+	}
+	for i, group := range list.Data {
+		compact.Data[i] = compactTransactionGroup(group)
+	}
+	return compact
+}
+
+// compactTransactionGroup flattens single-split groups and drops group titles,
+// which Firefly III only sets for groups with several splits
+func compactTransactionGroup(group TransactionGroup) CompactTransactionGroup {
+	compact := CompactTransactionGroup{Id: group.Id}
+	if group.CreatedAt != nil {
+		compact.CreatedAt = group.CreatedAt.Format(time.RFC3339)
+	}
+	if len(group.Transactions) == 1 {
+		split := compactTransaction(group.Transactions[0])
+		compact.CompactTransaction = &split
+		return compact
+	}
+	compact.GroupTitle = group.GroupTitle
+	compact.Transactions = make([]CompactTransaction, len(group.Transactions))
+	for i, transaction := range group.Transactions {
+		compact.Transactions[i] = compactTransaction(transaction)
+	}
+	return compact
+}
+
+func compactTransaction(transaction Transaction) CompactTransaction {
+	return CompactTransaction{
+		JournalId:       transaction.JournalId,
+		Order:           transaction.Order,
+		Type:            transaction.Type,
+		Date:            compactDate(transaction.Date),
+		Amount:          transaction.Amount,
+		CurrencyCode:    transaction.CurrencyCode,
+		Description:     transaction.Description,
+		SourceId:        transaction.SourceId,
+		SourceName:      transaction.SourceName,
+		DestinationId:   transaction.DestinationId,
+		DestinationName: transaction.DestinationName,
+		DestinationType: transaction.DestinationType,
+		CategoryName:    getStringValue(transaction.CategoryName),
+		BudgetName:      getStringValue(transaction.BudgetName),
+		BillName:        getStringValue(transaction.BillName),
+		Tags:            transaction.Tags,
+		Notes:           getStringValue(transaction.Notes),
+		Reconciled:      transaction.Reconciled,
+		RecurrenceId:    getStringValue(transaction.RecurrenceId),
+	}
+}
+
+// compactDate formats a date as YYYY-MM-DD when it has no time of day
+func compactDate(date time.Time) string {
+	if date.IsZero() {
+		return ""
+	}
+	if date.Hour() == 0 && date.Minute() == 0 && date.Second() == 0 {
+		return date.Format("2006-01-02")
+	}
+	return date.Format(time.RFC3339)
+}
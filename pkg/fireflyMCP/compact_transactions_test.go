@@ -0,0 +1,87 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompactTransactionList(t *testing.T) {
+	category := "Groceries"
+	list := &TransactionList{
+		Data: []TransactionGroup{
+			{
+				Id: "1",
+				Transactions: []Transaction{{
+					Id: "11", JournalId: "11", Type: "withdrawal", Amount: "12.50", CurrencyCode: "EUR", Description: "Bakery",
+					Date:         time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+					CategoryName: &category, SourceId: "2", SourceName: "Checking", Tags: []string{},
+				}},
+			},
+			{
+				Id:         "2",
+				GroupTitle: "Split",
+				Transactions: []Transaction{
+					{Id: "21", JournalId: "21", Type: "withdrawal", Amount: "5.00", Date: time.Date(2024, 3, 2, 14, 30, 0, 0, time.UTC)},
+					{Id: "22", JournalId: "22", Order: 1, Type: "withdrawal", Amount: "7.00", Date: time.Date(2024, 3, 2, 14, 30, 0, 0, time.UTC)},
+				},
+			},
+		},
+		Pagination: Pagination{Count: 2, Total: 2, CurrentPage: 1, PerPage: 50, TotalPages: 1},
+	}
+
+	raw, err := json.Marshal(compactTransactionList(list))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"data": [
+			{"id": "1", "journal_id": "11", "type": "withdrawal", "date": "2024-03-01", "amount": "12.50",
+			 "currency_code": "EUR", "description": "Bakery", "source_id": "2", "source_name": "Checking",
+			 "category_name": "Groceries"},
+			{"id": "2", "group_title": "Split", "transactions": [
+				{"journal_id": "21", "type": "withdrawal", "date": "2024-03-02T14:30:00Z", "amount": "5.00"},
+				{"journal_id": "22", "order": 1, "type": "withdrawal", "date": "2024-03-02T14:30:00Z", "amount": "7.00"}
+			]}
+		],
+		"pagination": {"count": 2, "total": 2, "current_page": 1, "per_page": 50, "total_pages": 1}
+	}`, string(raw))
+}
+
+func TestListTransactionsCompact(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		w.Write([]byte(`{"data":[{"type":"transactions","id":"5","attributes":{"group_title":null,"transactions":[` +
+			`{"transaction_journal_id":"51","type":"withdrawal","date":"2024-03-01T00:00:00+01:00","amount":"9.99",` +
+			`"description":"Streaming","source_id":"1","source_name":"Checking","destination_id":"8",` +
+			`"destination_name":"Streamly","destination_type":"Expense account","currency_code":"EUR",` +
+			`"category_id":null,"category_name":null,"budget_id":null,"budget_name":null,"bill_id":null,` +
+			`"bill_name":null,"notes":null,"tags":[],"reconciled":false}]}}],` +
+			`"meta":{"pagination":{"total":1,"count":1,"per_page":50,"current_page":1,"total_pages":1}}}`))
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	full, _, err := server.handleListTransactions(context.Background(), nil, ListTransactionsArgs{})
+	require.NoError(t, err)
+	require.False(t, full.IsError)
+	compact, _, err := server.handleListTransactions(context.Background(), nil, ListTransactionsArgs{Compact: true})
+	require.NoError(t, err)
+	require.False(t, compact.IsError)
+
+	fullText := full.Content[0].(*mcp.TextContent).Text
+	compactText := compact.Content[0].(*mcp.TextContent).Text
+	assert.NotContains(t, compactText, "null")
+	assert.NotContains(t, compactText, `"transactions"`)
+	assert.Contains(t, compactText, `"date":"2024-03-01"`)
+	assert.Less(t, len(compactText)*2, len(fullText), "compact output should be less than half the size")
+}
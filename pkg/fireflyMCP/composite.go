@@ -0,0 +1,223 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// compositePageSize and compositeMaxPages bound how many transactions composite
+// tools fetch (page size x pages)
+const (
+	compositePageSize        = 200
+	compositeMaxPages        = 10
+	maxCompositeTransactions = compositePageSize * compositeMaxPages
+	defaultPageParallelism   = 4
+)
+
+// CompositeStepStatus reports the outcome of one step of a composite tool
+type CompositeStepStatus struct {
+	Name    string `json:"name"`
+	Success bool   `json:"success"`
+	Skipped bool   `json:"skipped,omitempty"` // Not run because the latency budget was exhausted
+	Error   string `json:"error,omitempty"`
+}
+
+// compositeRun records the steps of a composite tool. A failing step is recorded
+// and the remaining steps still run, so one unavailable endpoint degrades the
+// report instead of failing the whole tool.
+//
+// If the context has a deadline (e.g. a configured tool timeout), it is the
+// latency budget of the run: every step gets an equal share of the time left for
+// the steps not run yet, and steps are skipped once the budget is exhausted, so
+// the tool returns partial results instead of timing out with nothing.
+type compositeRun struct {
+	Steps   []CompositeStepStatus
+	ctx     context.Context
+	pending int // Steps not run yet
+}
+
+// newCompositeRun starts a run of the given number of steps within ctx
+func newCompositeRun(ctx context.Context, steps int) *compositeRun {
+	return &compositeRun{ctx: ctx, pending: steps}
+}
+
+// step runs fn as the named step and reports whether it succeeded
+func (r *compositeRun) step(name string, fn func(ctx context.Context) error) bool {
+	ctx := r.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	status := CompositeStepStatus{Name: name, Success: true}
+	if err := ctx.Err(); err != nil {
+		status.Success = false
+		status.Skipped = true
+		status.Error = fmt.Sprintf("skipped, latency budget exhausted: %v", err)
+	} else {
+		stepCtx, cancel := r.stepContext(ctx)
+		err := fn(stepCtx)
+		if err != nil {
+			status.Success = false
+			status.Error = err.Error()
+			if errors.Is(stepCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+				status.Error = "timed out after its share of the latency budget: " + status.Error
+			}
+		}
+		cancel()
+	}
+	if r.pending > 0 {
+		r.pending--
+	}
+	r.Steps = append(r.Steps, status)
+	return status.Success
+}
+
+// stepContext returns the context of the next step, limited to its share of the
+// time left until the run's deadline
+func (r *compositeRun) stepContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok || r.pending <= 1 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, time.Until(deadline)/time.Duration(r.pending))
+}
+
+// failed returns the names of the failed steps
+func (r *compositeRun) failed() []string {
+	var names []string
+	for _, step := range r.Steps {
+		if !step.Success {
+			names = append(names, step.Name)
+		}
+	}
+	return names
+}
+
+// callTool invokes a tool handler and decodes its JSON result into Out. Composite
+// tools use it to build on existing tools, so defaults, alias resolution and
+// mapping stay identical to calling the tools one by one.
+func callTool[In, Out any](
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	handler mcp.ToolHandlerFor[In, any],
+	args In,
+) (*Out, error) {
+	result, _, err := handler(ctx, req, args)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil || len(result.Content) == 0 {
+		return nil, errors.New("empty tool result")
+	}
+
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	if !ok {
+		return nil, errors.New("unexpected tool result content")
+	}
+	if result.IsError {
+		return nil, errors.New(textContent.Text)
+	}
+
+	var out Out
+	if err := json.Unmarshal([]byte(textContent.Text), &out); err != nil {
+		return nil, fmt.Errorf("failed to decode tool result: %w", err)
+	}
+	return &out, nil
+}
+
+// pageParallelism returns how many pages composite tools fetch at the same time
+func (s *FireflyMCPServer) pageParallelism() int {
+	if config := s.currentConfig(); config != nil && config.Client.PageParallelism > 0 {
+		return config.Client.PageParallelism
+	}
+	return defaultPageParallelism
+}
+
+// fetchPages fetches up to compositeMaxPages pages and returns their items in
+// page order. fetch returns the items of a page and the total number of pages.
+// The first page is fetched alone for that total; the rest are fetched with up
+// to pageParallelism requests at a time. Reports whether pages were left out.
+func fetchPages[T any](
+	ctx context.Context,
+	parallelism int,
+	fetch func(ctx context.Context, page int) ([]T, int, error),
+) ([]T, bool, error) {
+	items, totalPages, err := fetch(ctx, 1)
+	if err != nil {
+		return nil, false, err
+	}
+	pages := min(totalPages, compositeMaxPages)
+	if pages <= 1 {
+		return items, totalPages > compositeMaxPages, nil
+	}
+
+	// The first failure cancels the pages still waiting for a slot
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	results := make([][]T, pages+1)
+	slots := make(chan struct{}, max(parallelism, 1))
+	for page := 2; page <= pages; page++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			if ctx.Err() != nil {
+				return
+			}
+			pageItems, _, err := fetch(ctx, page)
+			if err != nil {
+				once.Do(func() { firstErr = err })
+				cancel()
+				return
+			}
+			results[page] = pageItems
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, false, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+
+	for _, pageItems := range results[2:] {
+		items = append(items, pageItems...)
+	}
+	return items, totalPages > compositeMaxPages, nil
+}
+
+// fetchTransactions pages through list_transactions with the given filters
+// (Limit and Page are ignored). Reports whether the result was truncated at
+// maxCompositeTransactions.
+func (s *FireflyMCPServer) fetchTransactions(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	filters ListTransactionsArgs,
+) ([]Transaction, bool, error) {
+	filters.Limit = compositePageSize
+	return fetchPages(ctx, s.pageParallelism(), func(ctx context.Context, page int) ([]Transaction, int, error) {
+		pageFilters := filters
+		pageFilters.Page = page
+		list, err := callTool[ListTransactionsArgs, TransactionList](ctx, req, s.handleListTransactions, pageFilters)
+		if err != nil {
+			return nil, 0, err
+		}
+		var transactions []Transaction
+		for _, group := range list.Data {
+			transactions = append(transactions, group.Transactions...)
+		}
+		return transactions, list.Pagination.TotalPages, nil
+	})
+}
@@ -0,0 +1,88 @@
+package fireflyMCP
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchPages(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("keeps page order and bounds concurrency", func(t *testing.T) {
+		var running, peak atomic.Int32
+		items, truncated, err := fetchPages(ctx, 3, func(ctx context.Context, page int) ([]int, int, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			// Later pages answer first
+			time.Sleep(time.Duration(10-page) * time.Millisecond)
+			return []int{page * 10, page*10 + 1}, 6, nil
+		})
+		require.NoError(t, err)
+		assert.False(t, truncated)
+		assert.Equal(t, []int{10, 11, 20, 21, 30, 31, 40, 41, 50, 51, 60, 61}, items)
+		assert.LessOrEqual(t, peak.Load(), int32(3))
+	})
+
+	t.Run("single page", func(t *testing.T) {
+		var calls atomic.Int32
+		items, truncated, err := fetchPages(ctx, 4, func(ctx context.Context, page int) ([]string, int, error) {
+			calls.Add(1)
+			return []string{"a"}, 1, nil
+		})
+		require.NoError(t, err)
+		assert.False(t, truncated)
+		assert.Equal(t, []string{"a"}, items)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("stops at compositeMaxPages", func(t *testing.T) {
+		var calls atomic.Int32
+		items, truncated, err := fetchPages(ctx, 4, func(ctx context.Context, page int) ([]int, int, error) {
+			calls.Add(1)
+			return []int{page}, compositeMaxPages + 5, nil
+		})
+		require.NoError(t, err)
+		assert.True(t, truncated)
+		assert.Len(t, items, compositeMaxPages)
+		assert.Equal(t, int32(compositeMaxPages), calls.Load())
+	})
+
+	t.Run("returns the first error", func(t *testing.T) {
+		_, _, err := fetchPages(ctx, 2, func(ctx context.Context, page int) ([]int, int, error) {
+			if page == 3 {
+				return nil, 0, errors.New("API error 500")
+			}
+			return []int{page}, 8, nil
+		})
+		assert.EqualError(t, err, "API error 500")
+
+		_, _, err = fetchPages(ctx, 2, func(ctx context.Context, page int) ([]int, int, error) {
+			return nil, 0, errors.New("unauthorized")
+		})
+		assert.EqualError(t, err, "unauthorized")
+	})
+}
+
+func TestPageParallelism(t *testing.T) {
+	config := newPluginTestConfig()
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+	assert.Equal(t, defaultPageParallelism, server.pageParallelism())
+
+	config.Client.PageParallelism = 8
+	server, err = NewFireflyMCPServer(config)
+	require.NoError(t, err)
+	assert.Equal(t, 8, server.pageParallelism())
+}
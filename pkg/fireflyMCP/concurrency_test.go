@@ -0,0 +1,173 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bearerTransport authenticates every request of an MCP client with its own token
+type bearerTransport struct {
+	token string
+}
+
+// RoundTrip implements http.RoundTripper
+func (t bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+t.token)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// TestConcurrentHTTPToolCalls runs tool calls of several users over the
+// streamable HTTP transport at once, while caches are flushed and read-only
+// mode is toggled. Run with -race to check the shared state.
+func TestConcurrentHTTPToolCalls(t *testing.T) {
+	var fireflyRequests atomic.Int64
+	firefly := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fireflyRequests.Add(1)
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch r.URL.Path {
+		case "/v1/accounts":
+			w.Write([]byte(`{"data":[{"type":"accounts","id":"1","attributes":{"name":"Checking","type":"asset"}}],` +
+				`"meta":{"pagination":{"total":1,"count":1,"per_page":50,"current_page":1,"total_pages":1}}}`))
+		case "/v1/categories":
+			w.Write([]byte(`{"data":[{"type":"categories","id":"5","attributes":{"name":"Food:Groceries"}}],"meta":{}}`))
+		case "/v1/currencies/native":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"data":{"id":"1","type":"currencies","attributes":{"code":"EUR","symbol":"€","name":"Euro","decimal_places":2}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer firefly.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = firefly.URL
+	config.API.Token = ""
+	config.HTTP.Enabled = true
+	config.HTTP.RateLimit = 1000
+	config.HTTP.RateBurst = 1000
+	config.Formatting.Hints = true
+	config.Formatting.CacheTTL = 300
+	config.Catalogs.TTL = 300
+	config.Categories.Delimiter = ":"
+	config.Tools = map[string]ToolOverride{"list_accounts": {CacheTTL: 300}}
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+	ts := httptest.NewServer(NewHTTPServer(server, config, testLogger()).Handler())
+	defer ts.Close()
+
+	const users, callsPerUser = 4, 6
+	stop := make(chan struct{})
+	var admin sync.WaitGroup
+	admin.Add(1)
+	go func() {
+		defer admin.Done()
+		for readOnly := false; ; readOnly = !readOnly {
+			select {
+			case <-stop:
+				return
+			case <-time.After(time.Millisecond):
+				server.FlushCaches()
+				server.SetReadOnly(readOnly)
+				server.Sessions()
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for user := range users {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client := mcp.NewClient(&mcp.Implementation{Name: fmt.Sprintf("user-%d", user)}, nil)
+			session, err := client.Connect(context.Background(), &mcp.StreamableClientTransport{
+				Endpoint:   ts.URL,
+				HTTPClient: &http.Client{Transport: bearerTransport{token: fmt.Sprintf("token-%d", user)}},
+			}, nil)
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer session.Close()
+
+			var calls sync.WaitGroup
+			for call := range callsPerUser {
+				calls.Add(1)
+				go func() {
+					defer calls.Done()
+					name := []string{"list_accounts", "category_tree"}[call%2]
+					result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: name, Arguments: map[string]any{}})
+					if assert.NoError(t, err, name) {
+						assert.False(t, result.IsError, "%s: %v", name, result.Content)
+					}
+				}()
+			}
+			calls.Wait()
+
+			result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "get_session_stats", Arguments: map[string]any{}})
+			if !assert.NoError(t, err) {
+				return
+			}
+			var stats SessionStats
+			require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &stats))
+			assert.Equal(t, callsPerUser, stats.ToolCalls, "every session counts its own calls only")
+			assert.Zero(t, stats.Errors)
+		}()
+	}
+	wg.Wait()
+	close(stop)
+	admin.Wait()
+	assert.Positive(t, fireflyRequests.Load())
+}
+
+func TestNameCatalog_InvalidatedWhileFetching(t *testing.T) {
+	catalog := newNameCatalog()
+	_, generation, ok := catalog.get("a", catalogBudgets, time.Minute)
+	require.False(t, ok)
+
+	// Another session writes while the listing is in flight
+	catalog.invalidate("a")
+	catalog.put("a", catalogBudgets, generation, []catalogItem{{Id: "1", Name: "Renamed before"}})
+	_, _, ok = catalog.get("a", catalogBudgets, time.Minute)
+	assert.False(t, ok, "a listing started before the write is not kept")
+
+	_, generation, _ = catalog.get("a", catalogBudgets, time.Minute)
+	catalog.invalidate("b")
+	catalog.put("a", catalogBudgets, generation, []catalogItem{{Id: "1", Name: "Groceries"}})
+	items, _, ok := catalog.get("a", catalogBudgets, time.Minute)
+	require.True(t, ok, "writes of other tenants do not matter")
+	assert.Equal(t, "Groceries", items[0].Name)
+
+	_, generation, _ = catalog.get("b", catalogBudgets, time.Minute)
+	catalog.flush()
+	catalog.put("b", catalogBudgets, generation, []catalogItem{{Id: "2"}})
+	_, _, ok = catalog.get("b", catalogBudgets, time.Minute)
+	assert.False(t, ok, "a listing started before a flush is not kept")
+}
+
+func TestCaches_FlushedWhileFetching(t *testing.T) {
+	formatting := newFormattingCache()
+	_, generation, ok := formatting.get("a", time.Minute)
+	require.False(t, ok)
+	formatting.flush()
+	formatting.put("a", generation, &FormattingHints{CurrencyCode: "EUR"})
+	_, _, ok = formatting.get("a", time.Minute)
+	assert.False(t, ok, "hints fetched with the settings before a reload are not kept")
+
+	results := newToolResultCache(nil)
+	generation = results.generation()
+	results.flush()
+	results.put("a", "list_accounts", toolResultCacheEntry{expires: time.Now().Add(time.Minute), generation: generation}, 10)
+	_, ok = results.get("a", "list_accounts")
+	assert.False(t, ok, "results computed before a flush are not kept")
+}
@@ -2,6 +2,8 @@ package fireflyMCP
 
 import (
 	"fmt"
+	"log/slog"
+	"net/url"
 	"os"
 	"strings"
 	"time"
@@ -12,20 +14,120 @@ import (
 // Config represents the MCP server configuration
 type Config struct {
 	Server struct {
-		URL string `yaml:"url" mapstructure:"url"`
+		URL            string `yaml:"url" mapstructure:"url"`
+		CheckOnStartup bool   `yaml:"check_on_startup" mapstructure:"check_on_startup"`
 	} `yaml:"server" mapstructure:"server"`
 	API struct {
 		Token string `yaml:"token" mapstructure:"token"`
 	} `yaml:"api" mapstructure:"api"`
 	Client struct {
-		Timeout int `yaml:"timeout" mapstructure:"timeout"`
+		Timeout             int    `yaml:"timeout" mapstructure:"timeout"`
+		ErrorBodyLimit      int    `yaml:"error_body_limit" mapstructure:"error_body_limit"`
+		MaintenanceCooldown int    `yaml:"maintenance_cooldown" mapstructure:"maintenance_cooldown"`
+		SerializeWrites     bool   `yaml:"serialize_writes" mapstructure:"serialize_writes"`
+		WriteInterval       int    `yaml:"write_interval" mapstructure:"write_interval"`
+		AcceptLanguage      string `yaml:"accept_language" mapstructure:"accept_language"`   // Sent as Accept-Language, e.g. "de-DE"
+		RetryAttempts       int    `yaml:"retry_attempts" mapstructure:"retry_attempts"`     // Retries of reads after transient network errors
+		RetryBackoff        int    `yaml:"retry_backoff" mapstructure:"retry_backoff"`       // Milliseconds before the first retry, doubled per retry
+		PageParallelism     int    `yaml:"page_parallelism" mapstructure:"page_parallelism"` // Pages composite tools fetch at the same time
 	} `yaml:"client" mapstructure:"client"`
 	Limits struct {
 		Accounts     int `yaml:"accounts" mapstructure:"accounts"`
 		Transactions int `yaml:"transactions" mapstructure:"transactions"`
 		Categories   int `yaml:"categories" mapstructure:"categories"`
 		Budgets      int `yaml:"budgets" mapstructure:"budgets"`
+		Tags         int `yaml:"tags" mapstructure:"tags"`
+		Bills        int `yaml:"bills" mapstructure:"bills"`
+		Recurrences  int `yaml:"recurrences" mapstructure:"recurrences"`
+		Rules        int `yaml:"rules" mapstructure:"rules"`
+		Search       int `yaml:"search" mapstructure:"search"`
 	} `yaml:"limits" mapstructure:"limits"`
+	Accounts struct {
+		Aliases         map[string]string       `yaml:"aliases" mapstructure:"aliases"`
+		MetadataTTL     int                     `yaml:"metadata_ttl" mapstructure:"metadata_ttl"`         // Seconds account currencies are cached for write tools
+		AllowAutocreate bool                    `yaml:"allow_autocreate" mapstructure:"allow_autocreate"` // Let Firefly III create expense/revenue accounts from unknown names
+		Groups          map[string]AccountGroup `yaml:"groups" mapstructure:"groups"`                     // Named groups of grouped_balances
+		Hidden          []string                `yaml:"hidden" mapstructure:"hidden"`                     // Account IDs or aliases no tool may see
+	} `yaml:"accounts" mapstructure:"accounts"`
+	Writes struct {
+		RequireIDs bool `yaml:"require_ids" mapstructure:"require_ids"` // Reject account/category/budget names in write tools
+	} `yaml:"writes" mapstructure:"writes"`
+	Budgets struct {
+		RolloverStrategy   string `yaml:"rollover_strategy" mapstructure:"rollover_strategy"`
+		RolloverCapPercent int    `yaml:"rollover_cap_percent" mapstructure:"rollover_cap_percent"`
+	} `yaml:"budgets" mapstructure:"budgets"`
+	Categories struct {
+		Delimiter string `yaml:"delimiter" mapstructure:"delimiter"`
+	} `yaml:"categories" mapstructure:"categories"`
+	Dates struct {
+		Timezone      string `yaml:"timezone" mapstructure:"timezone"`
+		UseServerTime bool   `yaml:"use_server_time" mapstructure:"use_server_time"`
+		MaxSkewHours  int    `yaml:"max_skew_hours" mapstructure:"max_skew_hours"`
+	} `yaml:"dates" mapstructure:"dates"`
+	Reports    []ReportDefinition `yaml:"reports" mapstructure:"reports"`
+	Formatting struct {
+		Hints           bool `yaml:"hints" mapstructure:"hints"`
+		CacheTTL        int  `yaml:"cache_ttl" mapstructure:"cache_ttl"`               // Seconds
+		Summaries       bool `yaml:"summaries" mapstructure:"summaries"`               // Built-in textual summaries after the JSON of results
+		AmountDirection bool `yaml:"amount_direction" mapstructure:"amount_direction"` // Absolute amounts with direction in/out instead of signs
+		WebLinks        bool `yaml:"web_links" mapstructure:"web_links"`               // web_url of returned entities in the Firefly III web UI
+	} `yaml:"formatting" mapstructure:"formatting"`
+	Trash struct {
+		Enabled     bool `yaml:"enabled" mapstructure:"enabled"`
+		GracePeriod int  `yaml:"grace_period" mapstructure:"grace_period"` // Hours, 0 keeps items until purge_trash
+	} `yaml:"trash" mapstructure:"trash"`
+	Demo struct {
+		Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	} `yaml:"demo" mapstructure:"demo"`
+	Proxy struct {
+		Enabled      bool     `yaml:"enabled" mapstructure:"enabled"`             // Register firefly_api_request
+		AllowedPaths []string `yaml:"allowed_paths" mapstructure:"allowed_paths"` // API paths it may read, empty for the built-in list
+	} `yaml:"proxy" mapstructure:"proxy"`
+	Sampling struct {
+		Enabled   bool `yaml:"enabled" mapstructure:"enabled"`       // Register summarize_transactions, which uses MCP sampling
+		MaxTokens int  `yaml:"max_tokens" mapstructure:"max_tokens"` // Tokens the client's model may use per sampling request
+	} `yaml:"sampling" mapstructure:"sampling"`
+	Household struct {
+		TagPrefix string   `yaml:"tag_prefix" mapstructure:"tag_prefix"` // Prefix of member tags, e.g. "member:" for member:alice
+		Members   []string `yaml:"members" mapstructure:"members"`       // Known members; when set, other names are rejected
+	} `yaml:"household" mapstructure:"household"`
+	Tax struct {
+		Categories map[string]string `yaml:"categories" mapstructure:"categories"` // Category name -> VAT rate in percent
+		Tags       map[string]string `yaml:"tags" mapstructure:"tags"`             // Tag -> VAT rate in percent
+	} `yaml:"tax" mapstructure:"tax"`
+	Journal struct {
+		Path string `yaml:"path" mapstructure:"path"` // Write-ahead journal of bulk stores, empty disables it
+	} `yaml:"journal" mapstructure:"journal"`
+	Replay struct {
+		Record string `yaml:"record" mapstructure:"record"` // File client tool calls and results are appended to, empty disables recording
+	} `yaml:"replay" mapstructure:"replay"`
+	Storage struct {
+		Backend string `yaml:"backend" mapstructure:"backend"` // memory, bolt, sqlite or redis
+		Path    string `yaml:"path" mapstructure:"path"`       // Database file of the bolt and sqlite backends
+		URL     string `yaml:"url" mapstructure:"url"`         // Server of the redis backend, e.g. redis://localhost:6379/0
+	} `yaml:"storage" mapstructure:"storage"`
+	Tenants struct {
+		CacheEntries    int `yaml:"cache_entries" mapstructure:"cache_entries"`       // Cached tool results per tenant
+		ChangeHistories int `yaml:"change_histories" mapstructure:"change_histories"` // Transactions with a change history per tenant
+	} `yaml:"tenants" mapstructure:"tenants"`
+	Trends struct {
+		MaxPoints int `yaml:"max_points" mapstructure:"max_points"` // Points of a trend before its granularity is coarsened
+	} `yaml:"trends" mapstructure:"trends"`
+	Warmup struct {
+		Enabled  bool `yaml:"enabled" mapstructure:"enabled"`   // Prefetch common data for the configured API token
+		Delay    int  `yaml:"delay" mapstructure:"delay"`       // Seconds after startup
+		Interval int  `yaml:"interval" mapstructure:"interval"` // Milliseconds between two warmup requests
+	} `yaml:"warmup" mapstructure:"warmup"`
+	Catalogs struct {
+		TTL int `yaml:"ttl" mapstructure:"ttl"` // Seconds account, budget and category names are cached for name resolution
+	} `yaml:"catalogs" mapstructure:"catalogs"`
+	Responses struct {
+		RedactFields []string `yaml:"redact_fields" mapstructure:"redact_fields"`
+		RedactMode   string   `yaml:"redact_mode" mapstructure:"redact_mode"` // none, strip or hash
+	} `yaml:"responses" mapstructure:"responses"`
+	Logging struct {
+		RedactFields []string `yaml:"redact_fields" mapstructure:"redact_fields"`
+	} `yaml:"logging" mapstructure:"logging"`
 	MCP struct {
 		Name         string `yaml:"name" mapstructure:"name"`
 		Version      string `yaml:"version" mapstructure:"version"`
@@ -42,7 +144,61 @@ type Config struct {
 		AllowedOrigins []string `yaml:"allowed_origins" mapstructure:"allowed_origins"`
 		RateLimit      float64  `yaml:"rate_limit" mapstructure:"rate_limit"`
 		RateBurst      int      `yaml:"rate_burst" mapstructure:"rate_burst"`
+		Stateless      bool     `yaml:"stateless" mapstructure:"stateless"` // No MCP sessions, so any replica can serve any request
 	} `yaml:"http" mapstructure:"http"`
+	Admin struct {
+		Enabled bool   `yaml:"enabled" mapstructure:"enabled"`
+		Host    string `yaml:"host" mapstructure:"host"`
+		Port    int    `yaml:"port" mapstructure:"port"`
+		Token   string `yaml:"token" mapstructure:"token"`
+	} `yaml:"admin" mapstructure:"admin"`
+	Tools map[string]ToolOverride `yaml:"tools" mapstructure:"tools"` // Per-tool overrides by tool name
+}
+
+// AccountGroup selects the accounts of a named group (accounts.groups.<name>).
+// An account belongs to the group if it matches any of the lists.
+type AccountGroup struct {
+	Accounts []string `yaml:"accounts" mapstructure:"accounts"` // Account IDs or aliases
+	Roles    []string `yaml:"roles" mapstructure:"roles"`       // Asset account roles, e.g. savingAsset
+	Types    []string `yaml:"types" mapstructure:"types"`       // asset or liabilities
+}
+
+// ToolOverride adjusts the defaults of a single tool (tools.<name>)
+type ToolOverride struct {
+	Enabled           *bool    `yaml:"enabled" mapstructure:"enabled"`     // false removes the tool
+	Timeout           int      `yaml:"timeout" mapstructure:"timeout"`     // Seconds for the whole call, 0 for no limit
+	CacheTTL          int      `yaml:"cache_ttl" mapstructure:"cache_ttl"` // Seconds, 0 disables caching (read-only tools only)
+	MaxLimit          int      `yaml:"max_limit" mapstructure:"max_limit"` // Upper bound of the limit argument, 0 for none
+	Aliases           []string `yaml:"aliases" mapstructure:"aliases"`
+	DescriptionSuffix string   `yaml:"description_suffix" mapstructure:"description_suffix"`
+	Summary           string   `yaml:"summary" mapstructure:"summary"` // Go template of a textual summary added to results
+}
+
+// ReportDefinition defines a custom report tool: a sequence of calls to existing
+// tools whose results are combined by a Go text/template
+type ReportDefinition struct {
+	Name        string           `yaml:"name" mapstructure:"name"`
+	Description string           `yaml:"description" mapstructure:"description"`
+	Arguments   []ReportArgument `yaml:"arguments" mapstructure:"arguments"`
+	Steps       []ReportStep     `yaml:"steps" mapstructure:"steps"`
+	Template    string           `yaml:"template" mapstructure:"template"`
+}
+
+// ReportArgument declares a string argument of a custom report tool
+type ReportArgument struct {
+	Name        string `yaml:"name" mapstructure:"name"`
+	Description string `yaml:"description" mapstructure:"description"`
+	Type        string `yaml:"type" mapstructure:"type"` // string (default) or date
+	Required    bool   `yaml:"required" mapstructure:"required"`
+	Default     string `yaml:"default" mapstructure:"default"`
+}
+
+// ReportStep calls an existing tool. String argument values are templates
+// rendered with the report arguments and the results of earlier steps.
+type ReportStep struct {
+	Name string         `yaml:"name" mapstructure:"name"`
+	Tool string         `yaml:"tool" mapstructure:"tool"`
+	Args map[string]any `yaml:"args" mapstructure:"args"`
 }
 
 // LoadConfig loads configuration from YAML file and environment variables
@@ -50,7 +206,26 @@ type Config struct {
 // Environment variables use the prefix FIREFLY_MCP_ and follow the pattern:
 //
 //	FIREFLY_MCP_SERVER_URL, FIREFLY_MCP_API_TOKEN, etc.
+//
+// The server URL is normalized and the resulting configuration is validated.
+// Callers that need to apply further overrides (e.g. CLI flags) before
+// validation should use LoadConfigWithoutValidation and call ValidateConfig.
 func LoadConfig(filename string) (*Config, error) {
+	config, err := LoadConfigWithoutValidation(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ValidateConfig(config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// LoadConfigWithoutValidation loads and normalizes configuration like LoadConfig,
+// but leaves validation to the caller.
+func LoadConfigWithoutValidation(filename string) (*Config, error) {
 	v := viper.New()
 
 	// Set default values
@@ -85,29 +260,139 @@ func LoadConfig(filename string) (*Config, error) {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
-	// Note: Validation is deferred to after CLI flags are applied
-	// Call ValidateConfig() after modifying config with CLI flags
+	serverURL, err := normalizeServerURL(config.Server.URL)
+	if err != nil {
+		return nil, err
+	}
+	config.Server.URL = serverURL
+	config.API.Token = strings.TrimSpace(config.API.Token)
+	config.Admin.Token = strings.TrimSpace(config.Admin.Token)
+	config.Client.AcceptLanguage = strings.TrimSpace(config.Client.AcceptLanguage)
 
 	return &config, nil
 }
 
+// normalizeServerURL normalizes the configured Firefly III API URL so that it
+// always points at the API root expected by the generated client
+// (e.g. https://firefly.example.com/api). A trailing slash or /v1 suffix is
+// removed and a missing /api suffix is added, since a URL without it makes
+// every API call fail with 404. Subdirectory installs keep their base path,
+// so https://example.com/firefly/api/v1 becomes https://example.com/firefly/api.
+// Returns an empty string for empty input so required-field validation can report it.
+func normalizeServerURL(raw string) (string, error) {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		return "", nil
+	}
+
+	u, err := url.Parse(trimmed)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("server.url %q is not a valid absolute URL", raw)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("server.url %q must use http or https", raw)
+	}
+
+	path := strings.TrimRight(u.Path, "/")
+	path = strings.TrimSuffix(path, "/v1")
+	if !strings.HasSuffix(path, "/api") {
+		path += "/api"
+	}
+
+	u.Path = path
+	u.RawPath = ""
+	u.RawQuery = ""
+	u.Fragment = ""
+
+	return u.String(), nil
+}
+
 // bindEnvVars explicitly binds environment variables to config keys
 // This is needed because Viper's AutomaticEnv doesn't automatically bind nested struct fields
 func bindEnvVars(v *viper.Viper) {
 	// Server config
 	v.BindEnv("server.url")
+	v.BindEnv("server.check_on_startup")
 
 	// API config
 	v.BindEnv("api.token")
 
 	// Client config
 	v.BindEnv("client.timeout")
+	v.BindEnv("client.error_body_limit")
+	v.BindEnv("client.maintenance_cooldown")
+	v.BindEnv("client.serialize_writes")
+	v.BindEnv("client.write_interval")
+	v.BindEnv("client.accept_language")
+	v.BindEnv("client.retry_attempts")
+	v.BindEnv("client.retry_backoff")
+	v.BindEnv("client.page_parallelism")
 
 	// Limits config
 	v.BindEnv("limits.accounts")
 	v.BindEnv("limits.transactions")
 	v.BindEnv("limits.categories")
 	v.BindEnv("limits.budgets")
+	v.BindEnv("limits.tags")
+	v.BindEnv("limits.bills")
+	v.BindEnv("limits.recurrences")
+	v.BindEnv("limits.rules")
+	v.BindEnv("limits.search")
+
+	// Budgets config
+	v.BindEnv("budgets.rollover_strategy")
+	v.BindEnv("budgets.rollover_cap_percent")
+
+	// Accounts config
+	v.BindEnv("accounts.metadata_ttl")
+	v.BindEnv("accounts.allow_autocreate")
+	v.BindEnv("accounts.hidden")
+
+	// Writes config
+	v.BindEnv("writes.require_ids")
+
+	// Categories config
+	v.BindEnv("categories.delimiter")
+
+	// Dates config
+	v.BindEnv("dates.timezone")
+	v.BindEnv("dates.use_server_time")
+	v.BindEnv("dates.max_skew_hours")
+
+	// Formatting config
+	v.BindEnv("formatting.hints")
+	v.BindEnv("formatting.cache_ttl")
+	v.BindEnv("formatting.summaries")
+	v.BindEnv("formatting.amount_direction")
+	v.BindEnv("formatting.web_links")
+	v.BindEnv("trash.enabled")
+	v.BindEnv("trash.grace_period")
+	v.BindEnv("demo.enabled")
+	v.BindEnv("proxy.enabled")
+	v.BindEnv("proxy.allowed_paths")
+	v.BindEnv("sampling.enabled")
+	v.BindEnv("sampling.max_tokens")
+	v.BindEnv("household.tag_prefix")
+	v.BindEnv("household.members")
+	v.BindEnv("journal.path")
+	v.BindEnv("replay.record")
+	v.BindEnv("storage.backend")
+	v.BindEnv("storage.path")
+	v.BindEnv("storage.url")
+	v.BindEnv("tenants.cache_entries")
+	v.BindEnv("tenants.change_histories")
+	v.BindEnv("trends.max_points")
+	v.BindEnv("catalogs.ttl")
+	v.BindEnv("warmup.enabled")
+	v.BindEnv("warmup.delay")
+	v.BindEnv("warmup.interval")
+
+	// Responses config
+	v.BindEnv("responses.redact_fields")
+	v.BindEnv("responses.redact_mode")
+
+	// Logging config
+	v.BindEnv("logging.redact_fields")
 
 	// MCP config
 	v.BindEnv("mcp.name")
@@ -125,18 +410,91 @@ func bindEnvVars(v *viper.Viper) {
 	v.BindEnv("http.allowed_origins")
 	v.BindEnv("http.rate_limit")
 	v.BindEnv("http.rate_burst")
+	v.BindEnv("http.stateless")
+
+	// Admin config
+	v.BindEnv("admin.enabled")
+	v.BindEnv("admin.host")
+	v.BindEnv("admin.port")
+	v.BindEnv("admin.token")
 }
 
 // setDefaults configures default values for all configuration options
 func setDefaults(v *viper.Viper) {
+	// Server defaults
+	v.SetDefault("server.check_on_startup", true)
+
 	// Client defaults
 	v.SetDefault("client.timeout", 30)
+	v.SetDefault("client.error_body_limit", defaultErrorBodyLimit)
+	v.SetDefault("client.maintenance_cooldown", defaultMaintenanceCooldown)
+	v.SetDefault("client.serialize_writes", false)
+	v.SetDefault("client.write_interval", defaultWriteInterval)
+	v.SetDefault("client.accept_language", "")
+	v.SetDefault("client.retry_attempts", defaultRetryAttempts)
+	v.SetDefault("client.retry_backoff", defaultRetryBackoff)
+	v.SetDefault("client.page_parallelism", defaultPageParallelism)
 
-	// Limits defaults
+	// Limits defaults (per tool family, tuned to typical intent)
 	v.SetDefault("limits.accounts", 100)
-	v.SetDefault("limits.transactions", 100)
-	v.SetDefault("limits.categories", 100)
+	v.SetDefault("limits.transactions", 50)
+	v.SetDefault("limits.categories", 1000)
 	v.SetDefault("limits.budgets", 100)
+	v.SetDefault("limits.tags", 500)
+	v.SetDefault("limits.bills", 100)
+	v.SetDefault("limits.recurrences", 100)
+	v.SetDefault("limits.rules", 100)
+	v.SetDefault("limits.search", 25)
+
+	// Budgets defaults
+	v.SetDefault("budgets.rollover_strategy", rolloverFull)
+	v.SetDefault("budgets.rollover_cap_percent", 50)
+
+	// Categories defaults
+	v.SetDefault("accounts.metadata_ttl", defaultAccountMetadataTTL)
+	v.SetDefault("accounts.allow_autocreate", true)
+	v.SetDefault("accounts.hidden", []string{})
+	v.SetDefault("writes.require_ids", false)
+	v.SetDefault("categories.delimiter", defaultCategoryDelimiter)
+
+	// Dates defaults
+	v.SetDefault("dates.use_server_time", false)
+	v.SetDefault("dates.max_skew_hours", 2)
+
+	// Formatting defaults
+	v.SetDefault("formatting.hints", true)
+	v.SetDefault("formatting.cache_ttl", 600)
+	v.SetDefault("formatting.summaries", false)
+	v.SetDefault("formatting.amount_direction", false)
+	v.SetDefault("formatting.web_links", true)
+	v.SetDefault("trash.enabled", false)
+	v.SetDefault("trash.grace_period", 24)
+	v.SetDefault("demo.enabled", false)
+	v.SetDefault("proxy.enabled", false)
+	v.SetDefault("proxy.allowed_paths", []string{})
+	v.SetDefault("sampling.enabled", false)
+	v.SetDefault("sampling.max_tokens", 1024)
+	v.SetDefault("household.tag_prefix", "member:")
+	v.SetDefault("household.members", []string{})
+	v.SetDefault("journal.path", "")
+	v.SetDefault("replay.record", "")
+	v.SetDefault("storage.backend", StorageMemory)
+	v.SetDefault("storage.path", "")
+	v.SetDefault("storage.url", "")
+	v.SetDefault("tenants.cache_entries", defaultTenantCacheEntries)
+	v.SetDefault("tenants.change_histories", defaultTenantChangeHistories)
+	v.SetDefault("trends.max_points", defaultTrendMaxPoints)
+	v.SetDefault("catalogs.ttl", defaultCatalogTTL)
+	v.SetDefault("warmup.enabled", false)
+	v.SetDefault("warmup.delay", defaultWarmupDelay)
+	v.SetDefault("warmup.interval", defaultWarmupInterval)
+
+	// Responses defaults
+	v.SetDefault("responses.redact_fields", defaultResponseRedactFields)
+	v.SetDefault("responses.redact_mode", responseRedactNone)
+
+	// Logging defaults
+	v.SetDefault("logging.redact_fields", defaultRedactFields)
 
 	// MCP defaults
 	v.SetDefault("mcp.name", "firefly-iii-mcp")
@@ -154,6 +512,12 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("http.allowed_origins", []string{"*"})
 	v.SetDefault("http.rate_limit", 10.0)
 	v.SetDefault("http.rate_burst", 20)
+	v.SetDefault("http.stateless", false)
+
+	// Admin defaults
+	v.SetDefault("admin.enabled", false)
+	v.SetDefault("admin.host", "127.0.0.1")
+	v.SetDefault("admin.port", 8081)
 }
 
 // ValidateConfig validates that required configuration fields are set
@@ -169,6 +533,27 @@ func ValidateConfig(config *Config) error {
 	if config.Client.Timeout <= 0 {
 		return fmt.Errorf("client.timeout must be positive")
 	}
+	if config.Client.ErrorBodyLimit <= 0 {
+		return fmt.Errorf("client.error_body_limit must be positive")
+	}
+	if config.Client.MaintenanceCooldown < 0 {
+		return fmt.Errorf("client.maintenance_cooldown must not be negative")
+	}
+	if config.Client.WriteInterval < 0 {
+		return fmt.Errorf("client.write_interval must not be negative")
+	}
+	if strings.ContainsAny(config.Client.AcceptLanguage, "\r\n") {
+		return fmt.Errorf("client.accept_language must be a single line")
+	}
+	if config.Client.RetryAttempts < 0 {
+		return fmt.Errorf("client.retry_attempts must not be negative")
+	}
+	if config.Client.RetryBackoff < 0 {
+		return fmt.Errorf("client.retry_backoff must not be negative")
+	}
+	if config.Client.PageParallelism <= 0 {
+		return fmt.Errorf("client.page_parallelism must be positive")
+	}
 	if config.Limits.Accounts <= 0 {
 		return fmt.Errorf("limits.accounts must be positive")
 	}
@@ -181,6 +566,108 @@ func ValidateConfig(config *Config) error {
 	if config.Limits.Budgets <= 0 {
 		return fmt.Errorf("limits.budgets must be positive")
 	}
+	if config.Limits.Tags <= 0 {
+		return fmt.Errorf("limits.tags must be positive")
+	}
+	if config.Limits.Bills <= 0 {
+		return fmt.Errorf("limits.bills must be positive")
+	}
+	if config.Limits.Recurrences <= 0 {
+		return fmt.Errorf("limits.recurrences must be positive")
+	}
+	if config.Limits.Rules <= 0 {
+		return fmt.Errorf("limits.rules must be positive")
+	}
+	if config.Limits.Search <= 0 {
+		return fmt.Errorf("limits.search must be positive")
+	}
+	if _, err := loadDateLocation(config); err != nil {
+		return fmt.Errorf("dates.timezone %q is not a valid IANA timezone", config.Dates.Timezone)
+	}
+	if config.Dates.MaxSkewHours < 0 {
+		return fmt.Errorf("dates.max_skew_hours must not be negative")
+	}
+	if !isRolloverStrategy(config.Budgets.RolloverStrategy) {
+		return fmt.Errorf("budgets.rollover_strategy must be one of full, capped, none")
+	}
+	if config.Budgets.RolloverCapPercent < 0 {
+		return fmt.Errorf("budgets.rollover_cap_percent must not be negative")
+	}
+	if config.Accounts.MetadataTTL < 0 {
+		return fmt.Errorf("accounts.metadata_ttl must not be negative")
+	}
+	if err := validateAccountGroups(config.Accounts.Groups); err != nil {
+		return err
+	}
+	if config.Formatting.CacheTTL < 0 {
+		return fmt.Errorf("formatting.cache_ttl must not be negative")
+	}
+	if config.Sampling.Enabled && config.Sampling.MaxTokens <= 0 {
+		return fmt.Errorf("sampling.max_tokens must be positive")
+	}
+	if config.Trash.GracePeriod < 0 {
+		return fmt.Errorf("trash.grace_period must not be negative")
+	}
+	if strings.TrimSpace(config.Household.TagPrefix) == "" {
+		return fmt.Errorf("household.tag_prefix must not be empty")
+	}
+	if config.Tenants.CacheEntries <= 0 {
+		return fmt.Errorf("tenants.cache_entries must be positive")
+	}
+	if config.Tenants.ChangeHistories <= 0 {
+		return fmt.Errorf("tenants.change_histories must be positive")
+	}
+	if config.Trends.MaxPoints <= 0 {
+		return fmt.Errorf("trends.max_points must be positive")
+	}
+	switch strings.ToLower(config.Storage.Backend) {
+	case "", StorageMemory:
+	case StorageBolt, StorageSQLite:
+		if config.Storage.Path == "" {
+			return fmt.Errorf("storage.path is required for the %s storage backend", config.Storage.Backend)
+		}
+	case StorageRedis:
+		if config.Storage.URL == "" {
+			return fmt.Errorf("storage.url is required for the %s storage backend", config.Storage.Backend)
+		}
+	default:
+		return fmt.Errorf("storage.backend must be one of memory, bolt, sqlite or redis, got %q", config.Storage.Backend)
+	}
+	if config.Catalogs.TTL < 0 {
+		return fmt.Errorf("catalogs.ttl must not be negative")
+	}
+	if config.Warmup.Delay < 0 {
+		return fmt.Errorf("warmup.delay must not be negative")
+	}
+	if config.Warmup.Interval < 0 {
+		return fmt.Errorf("warmup.interval must not be negative")
+	}
+	if err := validateTaxRates("tax.categories", config.Tax.Categories); err != nil {
+		return err
+	}
+	if err := validateTaxRates("tax.tags", config.Tax.Tags); err != nil {
+		return err
+	}
+	if config.Responses.RedactMode != "" && !isResponseRedactMode(config.Responses.RedactMode) {
+		return fmt.Errorf("responses.redact_mode must be one of none, strip, hash")
+	}
+	if config.Admin.Enabled {
+		if config.Admin.Token == "" {
+			return fmt.Errorf("admin.token is required when the admin API is enabled (set via config file or FIREFLY_MCP_ADMIN_TOKEN)")
+		}
+		if config.Admin.Port <= 0 {
+			return fmt.Errorf("admin.port must be positive")
+		}
+		if config.HTTP.Enabled && config.Admin.Port == config.HTTP.Port {
+			return fmt.Errorf("admin.port must differ from http.port")
+		}
+	}
+	if err := validateReports(config.Reports); err != nil {
+		return err
+	}
+	if err := validateToolOverrides(config.Tools); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -188,3 +675,85 @@ func ValidateConfig(config *Config) error {
 func (c *Config) GetTimeout() time.Duration {
 	return time.Duration(c.Client.Timeout) * time.Second
 }
+
+// redactURL hides the password of a URL for logging
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return maskSecret(raw)
+	}
+	return u.Redacted()
+}
+
+// maskSecret masks a secret for logging, keeping only a short prefix and suffix
+// of long values so different tokens can still be told apart.
+func maskSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	if len(secret) <= 12 {
+		return "****"
+	}
+	return secret[:4] + "****" + secret[len(secret)-4:]
+}
+
+// LogValue implements slog.LogValuer so the configuration can be logged
+// without leaking the API token.
+func (c *Config) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("server_url", c.Server.URL),
+		slog.String("api_token", maskSecret(c.API.Token)),
+		slog.Int("client_timeout", c.Client.Timeout),
+		slog.String("client_accept_language", c.Client.AcceptLanguage),
+		slog.Int("client_retry_attempts", c.Client.RetryAttempts),
+		slog.Int("client_page_parallelism", c.Client.PageParallelism),
+		slog.String("mcp_name", c.MCP.Name),
+		slog.String("mcp_version", c.MCP.Version),
+		slog.Bool("http_enabled", c.HTTP.Enabled),
+		slog.String("http_host", c.HTTP.Host),
+		slog.Int("http_port", c.HTTP.Port),
+		slog.Bool("http_stateless", c.HTTP.Stateless),
+		slog.Int("account_aliases", len(c.Accounts.Aliases)),
+		slog.Int("account_groups", len(c.Accounts.Groups)),
+		slog.Int("accounts_metadata_ttl", c.Accounts.MetadataTTL),
+		slog.Bool("accounts_allow_autocreate", c.Accounts.AllowAutocreate),
+		slog.Int("hidden_accounts", len(c.Accounts.Hidden)),
+		slog.Bool("writes_require_ids", c.Writes.RequireIDs),
+		slog.String("categories_delimiter", c.Categories.Delimiter),
+		slog.String("dates_timezone", c.Dates.Timezone),
+		slog.Bool("dates_use_server_time", c.Dates.UseServerTime),
+		slog.Bool("formatting_hints", c.Formatting.Hints),
+		slog.Bool("formatting_summaries", c.Formatting.Summaries),
+		slog.Bool("formatting_amount_direction", c.Formatting.AmountDirection),
+		slog.Bool("formatting_web_links", c.Formatting.WebLinks),
+		slog.Bool("trash_enabled", c.Trash.Enabled),
+		slog.Bool("demo_enabled", c.Demo.Enabled),
+		slog.Bool("proxy_enabled", c.Proxy.Enabled),
+		slog.Any("proxy_allowed_paths", c.Proxy.AllowedPaths),
+		slog.Bool("sampling_enabled", c.Sampling.Enabled),
+		slog.Int("sampling_max_tokens", c.Sampling.MaxTokens),
+		slog.String("household_tag_prefix", c.Household.TagPrefix),
+		slog.Any("household_members", c.Household.Members),
+		slog.Int("tax_categories", len(c.Tax.Categories)),
+		slog.Int("tax_tags", len(c.Tax.Tags)),
+		slog.String("journal_path", c.Journal.Path),
+		slog.String("replay_record", c.Replay.Record),
+		slog.String("storage_backend", c.Storage.Backend),
+		slog.String("storage_path", c.Storage.Path),
+		slog.String("storage_url", redactURL(c.Storage.URL)),
+		slog.Int("tenants_cache_entries", c.Tenants.CacheEntries),
+		slog.Int("tenants_change_histories", c.Tenants.ChangeHistories),
+		slog.Int("trends_max_points", c.Trends.MaxPoints),
+		slog.Int("catalogs_ttl", c.Catalogs.TTL),
+		slog.Bool("warmup_enabled", c.Warmup.Enabled),
+		slog.Int("warmup_delay", c.Warmup.Delay),
+		slog.Int("warmup_interval", c.Warmup.Interval),
+		slog.Bool("admin_enabled", c.Admin.Enabled),
+		slog.Int("admin_port", c.Admin.Port),
+		slog.String("admin_token", maskSecret(c.Admin.Token)),
+		slog.String("responses_redact_mode", c.Responses.RedactMode),
+		slog.Any("responses_redact_fields", c.Responses.RedactFields),
+		slog.Any("logging_redact_fields", c.Logging.RedactFields),
+		slog.Int("tool_overrides", len(c.Tools)),
+	)
+}
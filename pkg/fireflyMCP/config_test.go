@@ -71,11 +71,28 @@ api:
 	require.NotNil(t, config)
 
 	// Check defaults are applied
+	assert.True(t, config.Server.CheckOnStartup)
+	assert.Equal(t, "", config.Dates.Timezone)
+	assert.False(t, config.Dates.UseServerTime)
+	assert.Equal(t, 2, config.Dates.MaxSkewHours)
 	assert.Equal(t, 30, config.Client.Timeout)
+	assert.Equal(t, 300, config.Client.ErrorBodyLimit)
+	assert.Equal(t, 60, config.Client.MaintenanceCooldown)
+	assert.False(t, config.Client.SerializeWrites)
+	assert.Equal(t, 100, config.Client.WriteInterval)
+	assert.Equal(t, ":", config.Categories.Delimiter)
+	assert.Equal(t, "full", config.Budgets.RolloverStrategy)
+	assert.Equal(t, 50, config.Budgets.RolloverCapPercent)
+	assert.Equal(t, []string{"iban", "bic", "account_number", "notes"}, config.Logging.RedactFields)
 	assert.Equal(t, 100, config.Limits.Accounts)
-	assert.Equal(t, 100, config.Limits.Transactions)
-	assert.Equal(t, 100, config.Limits.Categories)
+	assert.Equal(t, 50, config.Limits.Transactions)
+	assert.Equal(t, 1000, config.Limits.Categories)
 	assert.Equal(t, 100, config.Limits.Budgets)
+	assert.Equal(t, 500, config.Limits.Tags)
+	assert.Equal(t, 100, config.Limits.Bills)
+	assert.Equal(t, 100, config.Limits.Recurrences)
+	assert.Equal(t, 100, config.Limits.Rules)
+	assert.Equal(t, 25, config.Limits.Search)
 	assert.Equal(t, "firefly-iii-mcp", config.MCP.Name)
 	assert.Equal(t, "1.0.0", config.MCP.Version)
 	assert.Equal(t, "MCP server for Firefly III personal finance management", config.MCP.Instructions)
@@ -84,16 +101,17 @@ api:
 func TestLoadConfigFromEnvVars(t *testing.T) {
 	// Set environment variables
 	envVars := map[string]string{
-		"FIREFLY_MCP_SERVER_URL":          "https://env.firefly.com/api",
-		"FIREFLY_MCP_API_TOKEN":           "env-token-456",
-		"FIREFLY_MCP_CLIENT_TIMEOUT":      "90",
-		"FIREFLY_MCP_LIMITS_ACCOUNTS":     "250",
-		"FIREFLY_MCP_LIMITS_TRANSACTIONS": "350",
-		"FIREFLY_MCP_LIMITS_CATEGORIES":   "175",
-		"FIREFLY_MCP_LIMITS_BUDGETS":      "75",
-		"FIREFLY_MCP_MCP_NAME":            "env-mcp",
-		"FIREFLY_MCP_MCP_VERSION":         "3.0.0",
-		"FIREFLY_MCP_MCP_INSTRUCTIONS":    "Env instructions",
+		"FIREFLY_MCP_SERVER_URL":            "https://env.firefly.com/api",
+		"FIREFLY_MCP_API_TOKEN":             "env-token-456",
+		"FIREFLY_MCP_CLIENT_TIMEOUT":        "90",
+		"FIREFLY_MCP_LIMITS_ACCOUNTS":       "250",
+		"FIREFLY_MCP_LIMITS_TRANSACTIONS":   "350",
+		"FIREFLY_MCP_LIMITS_CATEGORIES":     "175",
+		"FIREFLY_MCP_LIMITS_BUDGETS":        "75",
+		"FIREFLY_MCP_MCP_NAME":              "env-mcp",
+		"FIREFLY_MCP_MCP_VERSION":           "3.0.0",
+		"FIREFLY_MCP_MCP_INSTRUCTIONS":      "Env instructions",
+		"FIREFLY_MCP_LOGGING_REDACT_FIELDS": "iban,description",
 	}
 
 	// Set env vars and clean up after test
@@ -117,6 +135,7 @@ func TestLoadConfigFromEnvVars(t *testing.T) {
 	assert.Equal(t, "env-mcp", config.MCP.Name)
 	assert.Equal(t, "3.0.0", config.MCP.Version)
 	assert.Equal(t, "Env instructions", config.MCP.Instructions)
+	assert.Equal(t, []string{"iban", "description"}, config.Logging.RedactFields)
 }
 
 func TestLoadConfigEnvOverridesYAML(t *testing.T) {
@@ -214,6 +233,42 @@ client:
 `,
 			errorString: "client.timeout must be positive",
 		},
+		{
+			name: "negative retry attempts",
+			configYAML: `
+server:
+  url: https://test.firefly.com/api
+api:
+  token: test-token
+client:
+  retry_attempts: -1
+`,
+			errorString: "client.retry_attempts must not be negative",
+		},
+		{
+			name: "zero tenant cache entries",
+			configYAML: `
+server:
+  url: https://test.firefly.com/api
+api:
+  token: test-token
+tenants:
+  cache_entries: 0
+`,
+			errorString: "tenants.cache_entries must be positive",
+		},
+		{
+			name: "zero trend points",
+			configYAML: `
+server:
+  url: https://test.firefly.com/api
+api:
+  token: test-token
+trends:
+  max_points: 0
+`,
+			errorString: "trends.max_points must be positive",
+		},
 		{
 			name: "negative accounts limit",
 			configYAML: `
@@ -226,6 +281,54 @@ limits:
 `,
 			errorString: "limits.accounts must be positive",
 		},
+		{
+			name: "invalid timezone",
+			configYAML: `
+server:
+  url: https://test.firefly.com/api
+api:
+  token: test-token
+dates:
+  timezone: Mars/Olympus_Mons
+`,
+			errorString: "dates.timezone",
+		},
+		{
+			name: "invalid rollover strategy",
+			configYAML: `
+server:
+  url: https://test.firefly.com/api
+api:
+  token: test-token
+budgets:
+  rollover_strategy: double
+`,
+			errorString: "budgets.rollover_strategy",
+		},
+		{
+			name: "admin API without token",
+			configYAML: `
+server:
+  url: https://test.firefly.com/api
+api:
+  token: test-token
+admin:
+  enabled: true
+`,
+			errorString: "admin.token is required",
+		},
+		{
+			name: "invalid response redaction mode",
+			configYAML: `
+server:
+  url: https://test.firefly.com/api
+api:
+  token: test-token
+responses:
+  redact_mode: encrypt
+`,
+			errorString: "responses.redact_mode",
+		},
 	}
 
 	for _, tt := range tests {
@@ -319,6 +422,179 @@ limits:
 	assert.Equal(t, 30, config.Client.Timeout)                         // From YAML
 	assert.Equal(t, 150, config.Limits.Accounts)                       // From env
 	assert.Equal(t, 75, config.Limits.Transactions)                    // From YAML
-	assert.Equal(t, 100, config.Limits.Categories)                     // Default
+	assert.Equal(t, 1000, config.Limits.Categories)                    // Default
 	assert.Equal(t, 100, config.Limits.Budgets)                        // Default
 }
+
+func TestLoadConfigAccountAliases(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+
+	configContent := `
+server:
+  url: https://test.firefly.com/api
+api:
+  token: test-token
+accounts:
+  aliases:
+    joint card: 12
+    savings: "7"
+`
+
+	err := os.WriteFile(configFile, []byte(configContent), 0644)
+	require.NoError(t, err)
+
+	config, err := LoadConfig(configFile)
+	require.NoError(t, err)
+	require.NotNil(t, config)
+
+	assert.Equal(t, "12", config.Accounts.Aliases["joint card"])
+	assert.Equal(t, "7", config.Accounts.Aliases["savings"])
+}
+
+func TestLoadConfigToolOverrides(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+
+	configContent := `
+server:
+  url: https://test.firefly.com/api
+api:
+  token: test-token
+tools:
+  list_transactions:
+    max_limit: 100
+    timeout: 20
+  list_categories:
+    cache_ttl: 300
+    aliases: [categories]
+    description_suffix: Nested categories use ":"
+  delete_rule:
+    enabled: false
+`
+
+	err := os.WriteFile(configFile, []byte(configContent), 0644)
+	require.NoError(t, err)
+
+	config, err := LoadConfig(configFile)
+	require.NoError(t, err)
+
+	assert.Equal(t, 100, config.Tools["list_transactions"].MaxLimit)
+	assert.Equal(t, 20, config.Tools["list_transactions"].Timeout)
+	assert.Nil(t, config.Tools["list_transactions"].Enabled)
+	assert.Equal(t, 300, config.Tools["list_categories"].CacheTTL)
+	assert.Equal(t, []string{"categories"}, config.Tools["list_categories"].Aliases)
+	assert.Equal(t, `Nested categories use ":"`, config.Tools["list_categories"].DescriptionSuffix)
+	require.NotNil(t, config.Tools["delete_rule"].Enabled)
+	assert.False(t, *config.Tools["delete_rule"].Enabled)
+}
+
+func TestLoadConfigTax(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+
+	configContent := `
+server:
+  url: https://test.firefly.com/api
+api:
+  token: test-token
+tax:
+  categories:
+    Consulting: 19
+    Books: 7
+  tags:
+    reduced-rate: 5.5
+`
+
+	err := os.WriteFile(configFile, []byte(configContent), 0644)
+	require.NoError(t, err)
+
+	config, err := LoadConfig(configFile)
+	require.NoError(t, err)
+
+	// Map keys are lowercased by the config loader; tax_report matches them without case
+	assert.Equal(t, map[string]string{"consulting": "19", "books": "7"}, config.Tax.Categories)
+	assert.Equal(t, map[string]string{"reduced-rate": "5.5"}, config.Tax.Tags)
+}
+
+func TestNormalizeServerURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "already normalized", input: "https://firefly.example.com/api", expected: "https://firefly.example.com/api"},
+		{name: "trailing slash", input: "https://firefly.example.com/api/", expected: "https://firefly.example.com/api"},
+		{name: "missing api suffix", input: "https://firefly.example.com", expected: "https://firefly.example.com/api"},
+		{name: "missing api suffix with slash", input: "https://firefly.example.com/", expected: "https://firefly.example.com/api"},
+		{name: "version suffix", input: "https://firefly.example.com/api/v1/", expected: "https://firefly.example.com/api"},
+		{name: "surrounding whitespace", input: "  http://localhost:8080/api  ", expected: "http://localhost:8080/api"},
+		{name: "empty", input: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normalized, err := normalizeServerURL(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, normalized)
+		})
+	}
+}
+
+func TestNormalizeServerURLInvalid(t *testing.T) {
+	_, err := normalizeServerURL("firefly.example.com/api")
+	assert.Error(t, err)
+
+	_, err = normalizeServerURL("ftp://firefly.example.com/api")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "must use http or https")
+}
+
+func TestLoadConfigNormalizesServerURL(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+
+	configContent := `
+server:
+  url: https://test.firefly.com/
+api:
+  token: " test-token "
+`
+
+	err := os.WriteFile(configFile, []byte(configContent), 0644)
+	require.NoError(t, err)
+
+	config, err := LoadConfig(configFile)
+	require.NoError(t, err)
+	assert.Equal(t, "https://test.firefly.com/api", config.Server.URL)
+	assert.Equal(t, "test-token", config.API.Token)
+}
+
+func TestLoadConfigWithoutValidation(t *testing.T) {
+	// Missing token is allowed until the caller validates (e.g. after CLI flags)
+	config, err := LoadConfigWithoutValidation("/non/existent/config.yaml")
+	require.NoError(t, err)
+	require.NotNil(t, config)
+	assert.Error(t, ValidateConfig(config))
+
+	config.Server.URL = "https://test.firefly.com/api"
+	config.HTTP.Enabled = true
+	assert.NoError(t, ValidateConfig(config))
+}
+
+func TestMaskSecret(t *testing.T) {
+	assert.Equal(t, "", maskSecret(""))
+	assert.Equal(t, "****", maskSecret("short-token"))
+	assert.Equal(t, "eyJ0****wxyz", maskSecret("eyJ0eXAiOiJKV1QiLCJhbGciwxyz"))
+}
+
+func TestConfigLogValueMasksToken(t *testing.T) {
+	config := &Config{}
+	config.Server.URL = "https://test.firefly.com/api"
+	config.API.Token = "eyJ0eXAiOiJKV1QiLCJhbGciwxyz"
+
+	logged := config.LogValue().String()
+	assert.Contains(t, logged, "https://test.firefly.com/api")
+	assert.Contains(t, logged, "eyJ0****wxyz")
+	assert.NotContains(t, logged, config.API.Token)
+}
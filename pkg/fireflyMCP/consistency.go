@@ -0,0 +1,237 @@
+package fireflyMCP
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dezer32/mcp-firefly-iii/pkg/client"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// consistencyTolerance is the largest difference still reported as consistent,
+// to absorb rounding of amounts with more than two decimals
+var consistencyTolerance = parseAmount("0.01")
+
+// VerifyConsistencyArgs represents the arguments for the verify_consistency tool
+type VerifyConsistencyArgs struct {
+	DateRange
+}
+
+// ConsistencyCheck compares one reported total with the sum of the matching transactions
+type ConsistencyCheck struct {
+	Source       string `json:"source"` // summary or insight
+	Metric       string `json:"metric"` // spent or earned
+	CurrencyCode string `json:"currency_code"`
+	Reported     string `json:"reported"`
+	Aggregated   string `json:"aggregated"`
+	Difference   string `json:"difference"`
+	Consistent   bool   `json:"consistent"`
+}
+
+// ConsistencyReport is the result of the verify_consistency tool
+type ConsistencyReport struct {
+	Start                 string                `json:"start"`
+	End                   string                `json:"end"`
+	TransactionCount      int                   `json:"transaction_count"`
+	TransactionsTruncated bool                  `json:"transactions_truncated,omitempty"`
+	Checks                []ConsistencyCheck    `json:"checks"`
+	Discrepancies         int                   `json:"discrepancies"`
+	Hints                 []string              `json:"hints"`
+	Steps                 []CompositeStepStatus `json:"steps"`
+}
+
+// transactionAggregate holds the locally summed transactions of a period per currency
+type transactionAggregate struct {
+	spent  map[string]decimal
+	earned map[string]decimal
+
+	transferCount int
+	// Deposits into accounts other than asset accounts, which insight totals do not count
+	nonAssetCount int
+}
+
+// handleVerifyConsistency compares the summary and insight totals of a period
+// with the sums of the period's transactions and explains likely causes of
+// differences, so reported numbers can be trusted or questioned
+func (s *FireflyMCPServer) handleVerifyConsistency(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args VerifyConsistencyArgs,
+) (*mcp.CallToolResult, any, error) {
+	dates, err := s.resolveDateRange(args.DateRange, dateRangeRequired)
+	if err != nil {
+		return newErrorResult(err.Error())
+	}
+	start, end := dates.Start.Time, dates.End.Time
+	startStr, endStr := dates.StartString(), dates.EndString()
+
+	apiClient, err := s.getClient(ctx, req)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Failed to get API client: %v", err))
+	}
+
+	transactions, truncated, err := s.fetchTransactions(ctx, req, ListTransactionsArgs{DateRange: DateRange{Start: startStr, End: endStr}})
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error listing transactions: %v", err))
+	}
+
+	run := newCompositeRun(ctx, 2)
+
+	var summary *BasicSummaryList
+	run.step("summary", func(ctx context.Context) error {
+		summary, err = callTool[GetSummaryArgs, BasicSummaryList](
+			ctx, req, s.handleGetSummary, GetSummaryArgs{DateRange: DateRange{Start: startStr, End: endStr}},
+		)
+		return err
+	})
+
+	var income, expense []InsightTotalEntry
+	insightsOK := run.step("insights", func(ctx context.Context) error {
+		income, expense, err = insightTotals(ctx, apiClient, start, end, nil)
+		return err
+	})
+
+	aggregate := aggregateTransactions(transactions)
+	report := &ConsistencyReport{
+		Start:                 startStr,
+		End:                   endStr,
+		TransactionCount:      len(transactions),
+		TransactionsTruncated: truncated,
+		Checks:                []ConsistencyCheck{},
+		Hints:                 []string{},
+	}
+	if summary != nil {
+		report.Checks = append(report.Checks, summaryChecks(summary, aggregate)...)
+	}
+	if insightsOK {
+		report.Checks = append(report.Checks, insightChecks("spent", expense, aggregate.spent)...)
+		report.Checks = append(report.Checks, insightChecks("earned", income, aggregate.earned)...)
+	}
+	for _, check := range report.Checks {
+		if !check.Consistent {
+			report.Discrepancies++
+		}
+	}
+	report.Hints = consistencyHints(report, aggregate)
+	report.Steps = run.Steps
+
+	return newSuccessResult(report)
+}
+
+// aggregateTransactions sums withdrawals and deposits per currency and counts transfers
+func aggregateTransactions(transactions []Transaction) *transactionAggregate {
+	aggregate := &transactionAggregate{
+		spent:  make(map[string]decimal),
+		earned: make(map[string]decimal),
+	}
+	for _, transaction := range transactions {
+		amount := parseAmount(transaction.Amount).Abs()
+		switch transaction.Type {
+		case "withdrawal":
+			aggregate.spent[transaction.CurrencyCode] = aggregate.spent[transaction.CurrencyCode].Add(amount)
+		case "deposit":
+			aggregate.earned[transaction.CurrencyCode] = aggregate.earned[transaction.CurrencyCode].Add(amount)
+			if kind := accountTypeKind(transaction.DestinationType); kind != "" && kind != client.ShortAccountTypePropertyAsset {
+				aggregate.nonAssetCount++
+			}
+		case "transfer":
+			aggregate.transferCount++
+		}
+	}
+	return aggregate
+}
+
+// summaryChecks compares the spent-in-XXX and earned-in-XXX entries of the basic
+// summary with the aggregated withdrawals and deposits
+func summaryChecks(summary *BasicSummaryList, aggregate *transactionAggregate) []ConsistencyCheck {
+	reported := map[string]map[string]decimal{"spent": {}, "earned": {}}
+	for _, entry := range summary.Data {
+		for metric := range reported {
+			if currency, ok := strings.CutPrefix(entry.Key, metric+"-in-"); ok {
+				reported[metric][currency] = parseAmount(entry.MonetaryValue)
+			}
+		}
+	}
+
+	var checks []ConsistencyCheck
+	checks = append(checks, compareTotals("summary", "spent", reported["spent"], aggregate.spent)...)
+	checks = append(checks, compareTotals("summary", "earned", reported["earned"], aggregate.earned)...)
+	return checks
+}
+
+// insightChecks compares insight totals with the aggregated amounts of the same metric
+func insightChecks(metric string, entries []InsightTotalEntry, aggregated map[string]decimal) []ConsistencyCheck {
+	reported := make(map[string]decimal, len(entries))
+	for _, entry := range entries {
+		reported[entry.CurrencyCode] = reported[entry.CurrencyCode].Add(parseAmount(entry.Amount))
+	}
+	return compareTotals("insight", metric, reported, aggregated)
+}
+
+// compareTotals builds one check per currency present on either side. Firefly III
+// reports spent amounts as negative numbers, so absolute values are compared.
+func compareTotals(source, metric string, reported, aggregated map[string]decimal) []ConsistencyCheck {
+	currencies := make(map[string]bool)
+	for currency := range reported {
+		currencies[currency] = true
+	}
+	for currency := range aggregated {
+		currencies[currency] = true
+	}
+	codes := make([]string, 0, len(currencies))
+	for currency := range currencies {
+		codes = append(codes, currency)
+	}
+	sort.Strings(codes)
+
+	checks := make([]ConsistencyCheck, 0, len(codes))
+	for _, currency := range codes {
+		want := reported[currency].Abs()
+		got := aggregated[currency]
+		difference := want.Sub(got)
+		checks = append(checks, ConsistencyCheck{
+			Source:       source,
+			Metric:       metric,
+			CurrencyCode: currency,
+			Reported:     formatAmount(want),
+			Aggregated:   formatAmount(got),
+			Difference:   formatAmount(difference),
+			Consistent:   difference.Abs().Cmp(consistencyTolerance) < 0,
+		})
+	}
+	return checks
+}
+
+// consistencyHints explains the usual causes of the discrepancies in the report
+func consistencyHints(report *ConsistencyReport, aggregate *transactionAggregate) []string {
+	hints := []string{}
+	if report.TransactionsTruncated {
+		hints = append(hints, fmt.Sprintf(
+			"Only the first %d transactions were aggregated; narrow the period for an exact comparison",
+			maxCompositeTransactions,
+		))
+	}
+	if report.Discrepancies == 0 {
+		return hints
+	}
+
+	if aggregate.transferCount > 0 {
+		hints = append(hints, fmt.Sprintf(
+			"%d transfers were left out of the aggregation; transfers to or from liabilities and accounts "+
+				"excluded from net worth can be counted as spent or earned by Firefly III",
+			aggregate.transferCount,
+		))
+	}
+	if aggregate.nonAssetCount > 0 {
+		hints = append(hints, fmt.Sprintf(
+			"%d deposits went to accounts other than asset accounts, which insight totals do not include",
+			aggregate.nonAssetCount,
+		))
+	}
+	hints = append(hints,
+		"Summary totals skip accounts that are inactive or excluded from net worth; "+
+			"check the account settings when only the summary differs")
+	return hints
+}
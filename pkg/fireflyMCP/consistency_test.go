@@ -0,0 +1,135 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregateTransactions(t *testing.T) {
+	aggregate := aggregateTransactions([]Transaction{
+		{Type: "withdrawal", Amount: "10.50", CurrencyCode: "EUR"},
+		{Type: "withdrawal", Amount: "4.50", CurrencyCode: "EUR"},
+		{Type: "withdrawal", Amount: "3.00", CurrencyCode: "USD"},
+		{Type: "deposit", Amount: "100.00", CurrencyCode: "EUR", DestinationType: "Asset account"},
+		{Type: "deposit", Amount: "20.00", CurrencyCode: "EUR", DestinationType: "Loan"},
+		{Type: "deposit", Amount: "10.00", CurrencyCode: "EUR", DestinationType: "Bestandskonto"},
+		{Type: "transfer", Amount: "50.00", CurrencyCode: "EUR"},
+	})
+
+	require.Len(t, aggregate.spent, 2)
+	assert.Equal(t, "15.00", formatAmount(aggregate.spent["EUR"]))
+	assert.Equal(t, "3.00", formatAmount(aggregate.spent["USD"]))
+	require.Len(t, aggregate.earned, 1)
+	assert.Equal(t, "130.00", formatAmount(aggregate.earned["EUR"]))
+	assert.Equal(t, 1, aggregate.transferCount)
+	assert.Equal(t, 1, aggregate.nonAssetCount)
+}
+
+func TestCompareTotals(t *testing.T) {
+	checks := compareTotals("summary", "spent",
+		map[string]decimal{"EUR": parseAmount("-15.004"), "USD": parseAmount("-5")},
+		map[string]decimal{"EUR": parseAmount("15"), "GBP": parseAmount("2")},
+	)
+	require.Len(t, checks, 3)
+
+	assert.Equal(t, "EUR", checks[0].CurrencyCode)
+	assert.True(t, checks[0].Consistent, "differences below a cent are rounding")
+
+	assert.Equal(t, "GBP", checks[1].CurrencyCode)
+	assert.False(t, checks[1].Consistent)
+	assert.Equal(t, "0.00", checks[1].Reported)
+	assert.Equal(t, "-2.00", checks[1].Difference)
+
+	assert.Equal(t, "USD", checks[2].CurrencyCode)
+	assert.Equal(t, "5.00", checks[2].Difference)
+}
+
+func TestSummaryChecks(t *testing.T) {
+	summary := &BasicSummaryList{Data: []BasicSummary{
+		{Key: "spent-in-EUR", MonetaryValue: "-15.00"},
+		{Key: "earned-in-EUR", MonetaryValue: "100.00"},
+		{Key: "net-worth-in-EUR", MonetaryValue: "5000.00"},
+	}}
+	aggregate := aggregateTransactions([]Transaction{
+		{Type: "withdrawal", Amount: "15.00", CurrencyCode: "EUR"},
+		{Type: "deposit", Amount: "80.00", CurrencyCode: "EUR"},
+	})
+
+	checks := summaryChecks(summary, aggregate)
+	require.Len(t, checks, 2)
+	assert.Equal(t, "spent", checks[0].Metric)
+	assert.True(t, checks[0].Consistent)
+	assert.Equal(t, "earned", checks[1].Metric)
+	assert.False(t, checks[1].Consistent)
+	assert.Equal(t, "20.00", checks[1].Difference)
+}
+
+func TestVerifyConsistency(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/transactions":
+			w.Header().Set("Content-Type", "application/vnd.api+json")
+			w.Write([]byte(`{"data":[{"type":"transactions","id":"1","attributes":{"transactions":[` +
+				`{"transaction_journal_id":"1","type":"withdrawal","date":"2024-01-10T00:00:00Z","amount":"40.00",` +
+				`"currency_code":"EUR","description":"Market","source_id":"1","destination_id":"2"},` +
+				`{"transaction_journal_id":"2","type":"transfer","date":"2024-01-12T00:00:00Z","amount":"25.00",` +
+				`"currency_code":"EUR","description":"Credit card","source_id":"1","destination_id":"3"}]}}],` +
+				`"meta":{"pagination":{"total":1,"count":1,"per_page":200,"current_page":1,"total_pages":1}}}`))
+		case "/v1/summary/basic":
+			w.Header().Set("Content-Type", "application/vnd.api+json")
+			w.Write([]byte(`{"spent-in-EUR":{"key":"spent-in-EUR","currency_code":"EUR","monetary_value":"-65.00"}}`))
+		case "/v1/insight/expense/total":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[{"currency_code":"EUR","difference":"-40.00"}]`))
+		case "/v1/insight/income/total":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	result, _, err := server.handleVerifyConsistency(context.Background(), nil, VerifyConsistencyArgs{
+		DateRange: DateRange{Start: "2024-01-01", End: "2024-01-31"},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content[0].(*mcp.TextContent).Text)
+
+	var report ConsistencyReport
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &report))
+	assert.Equal(t, 2, report.TransactionCount)
+	require.Len(t, report.Checks, 2)
+
+	assert.Equal(t, "summary", report.Checks[0].Source)
+	assert.False(t, report.Checks[0].Consistent)
+	assert.Equal(t, "25.00", report.Checks[0].Difference)
+	assert.Equal(t, "insight", report.Checks[1].Source)
+	assert.True(t, report.Checks[1].Consistent)
+
+	assert.Equal(t, 1, report.Discrepancies)
+	require.NotEmpty(t, report.Hints)
+	assert.Contains(t, report.Hints[0], "1 transfers")
+	for _, step := range report.Steps {
+		assert.True(t, step.Success, step.Error)
+	}
+}
+
+func TestVerifyConsistency_RequiresDates(t *testing.T) {
+	server := &FireflyMCPServer{}
+	result, _, err := server.handleVerifyConsistency(context.Background(), nil, VerifyConsistencyArgs{DateRange: DateRange{Start: "2024-01-01"}})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
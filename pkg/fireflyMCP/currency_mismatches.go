@@ -0,0 +1,399 @@
+package fireflyMCP
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dezer32/mcp-firefly-iii/pkg/client"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxMismatchTransactionIds bounds the transaction IDs listed per bill or budget limit
+const maxMismatchTransactionIds = 20
+
+// Kinds of mismatches reported by find_currency_mismatches
+const (
+	mismatchKindBill        = "bill"
+	mismatchKindBudgetLimit = "budget_limit"
+	mismatchKindTransaction = "transaction"
+)
+
+// FindCurrencyMismatchesArgs represents the arguments for the find_currency_mismatches tool
+type FindCurrencyMismatchesArgs struct {
+	DateRange
+}
+
+// CurrencyMismatch is a bill, budget limit or transaction split whose currency
+// differs from the currency of what it is booked against
+type CurrencyMismatch struct {
+	Kind             string   `json:"kind"` // bill, budget_limit or transaction
+	Id               string   `json:"id"`   // Bill, budget limit or transaction group ID
+	Name             string   `json:"name"`
+	ExpectedCurrency string   `json:"expected_currency"` // Of the bill, the budget limit or the account
+	FoundCurrency    string   `json:"found_currency"`    // Of the transactions
+	TransactionCount int      `json:"transaction_count"`
+	Amount           string   `json:"amount"` // Sum of the mismatching transactions in found_currency
+	TransactionIds   []string `json:"transaction_ids"`
+	Fix              string   `json:"fix"`
+	Request          string   `json:"request"` // Tool or Firefly III API call executing the fix
+}
+
+// CurrencyMismatchReport is the result of the find_currency_mismatches tool
+type CurrencyMismatchReport struct {
+	Start                 string                `json:"start"`
+	End                   string                `json:"end"`
+	TransactionCount      int                   `json:"transaction_count"`
+	TransactionsTruncated bool                  `json:"transactions_truncated,omitempty"`
+	Mismatches            []CurrencyMismatch    `json:"mismatches"`
+	Warnings              []string              `json:"warnings"`
+	Steps                 []CompositeStepStatus `json:"steps"`
+}
+
+// currencyAccount is the name and currency of an account
+type currencyAccount struct {
+	Id           string
+	Name         string
+	CurrencyCode string // Empty for accounts without a currency, e.g. most expense accounts
+}
+
+// handleFindCurrencyMismatches flags bills and budget limits whose currency
+// differs from the transactions booked against them, and transactions whose
+// currency differs from the currency of their account. Such mismatches make
+// Firefly III leave amounts out of totals or sum up different currencies.
+func (s *FireflyMCPServer) handleFindCurrencyMismatches(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args FindCurrencyMismatchesArgs,
+) (*mcp.CallToolResult, any, error) {
+	dates, err := s.resolveDateRange(args.DateRange, dateRangeCurrentMonth)
+	if err != nil {
+		return newErrorResult(err.Error())
+	}
+	apiClient, err := s.getClient(ctx, req)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Failed to get API client: %v", err))
+	}
+
+	run := newCompositeRun(ctx, 5)
+
+	var groups []TransactionGroup
+	truncated := false
+	transactionsOK := run.step("transactions", func(ctx context.Context) error {
+		groups, truncated, err = s.fetchTransactionGroups(ctx, req, ListTransactionsArgs{
+			DateRange: DateRange{Start: dates.StartString(), End: dates.EndString()},
+		})
+		return err
+	})
+
+	var bills []Bill
+	billsOK := run.step("bills", func(ctx context.Context) error {
+		bills, _, err = fetchPages(ctx, s.pageParallelism(), func(ctx context.Context, page int) ([]Bill, int, error) {
+			list, err := callTool[ListBillsArgs, BillList](ctx, req, s.handleListBills, ListBillsArgs{Limit: compositePageSize, Page: page})
+			if err != nil {
+				return nil, 0, err
+			}
+			return list.Data, list.Pagination.TotalPages, nil
+		})
+		return err
+	})
+
+	var limits *BudgetLimitList
+	limitsOK := run.step("budget_limits", func(ctx context.Context) error {
+		limits, err = s.fetchBudgetLimits(ctx, req, dates.Start.Time, dates.End.Time)
+		return err
+	})
+
+	var budgets []catalogItem
+	run.step("budgets", func(ctx context.Context) error {
+		budgets, err = s.catalog(ctx, req, apiClient, catalogBudgets)
+		return err
+	})
+
+	var accounts []currencyAccount
+	accountsOK := run.step("accounts", func(ctx context.Context) error {
+		accounts, _, err = fetchPages(ctx, s.pageParallelism(), func(ctx context.Context, page int) ([]currencyAccount, int, error) {
+			return fetchCurrencyAccounts(ctx, apiClient, page)
+		})
+		return err
+	})
+
+	report := &CurrencyMismatchReport{
+		Start:                 dates.StartString(),
+		End:                   dates.EndString(),
+		TransactionsTruncated: truncated,
+		Mismatches:            []CurrencyMismatch{},
+		Warnings:              []string{},
+	}
+	for _, group := range groups {
+		report.TransactionCount += len(group.Transactions)
+	}
+	if truncated {
+		report.Warnings = append(report.Warnings, fmt.Sprintf(
+			"More than %d transactions in the period; only the first are checked, use a shorter period",
+			maxCompositeTransactions,
+		))
+	}
+	if transactionsOK {
+		if billsOK {
+			report.Mismatches = append(report.Mismatches, billCurrencyMismatches(bills, groups)...)
+		}
+		if limitsOK {
+			report.Mismatches = append(report.Mismatches, budgetLimitCurrencyMismatches(limits, budgets, groups)...)
+		}
+		if accountsOK {
+			report.Mismatches = append(report.Mismatches, accountCurrencyMismatches(accounts, groups)...)
+		}
+	}
+	report.Steps = run.Steps
+
+	if failed := run.failed(); len(failed) > 0 {
+		report.Warnings = append(report.Warnings, fmt.Sprintf(
+			"Some steps failed (%s); mismatches depending on them are not reported", strings.Join(failed, ", "),
+		))
+	}
+	return newSuccessResult(report)
+}
+
+// fetchCurrencyAccounts lists one page of accounts of all types with their currency
+func fetchCurrencyAccounts(ctx context.Context, apiClient *client.ClientWithResponses, page int) ([]currencyAccount, int, error) {
+	limit, pageParam := int32(compositePageSize), int32(page)
+	resp, err := apiClient.ListAccountWithResponse(ctx, &client.ListAccountParams{Limit: &limit, Page: &pageParam})
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.StatusCode() != 200 || resp.ApplicationvndApiJSON200 == nil {
+		return nil, 0, fmt.Errorf("API error %d", resp.StatusCode())
+	}
+	var accounts []currencyAccount
+	for _, account := range resp.ApplicationvndApiJSON200.Data {
+		accounts = append(accounts, currencyAccount{
+			Id:           account.Id,
+			Name:         account.Attributes.Name,
+			CurrencyCode: getStringValue(account.Attributes.CurrencyCode),
+		})
+	}
+	totalPages := 1
+	if pagination := resp.ApplicationvndApiJSON200.Meta.Pagination; pagination != nil && pagination.TotalPages != nil {
+		totalPages = *pagination.TotalPages
+	}
+	return accounts, totalPages, nil
+}
+
+// mismatchTotals sums up the mismatching transactions of one bill or budget limit per currency
+type mismatchTotals struct {
+	count  int
+	amount decimal
+	groups []string
+}
+
+// add counts a split of a transaction group
+func (t *mismatchTotals) add(groupId string, split Transaction) {
+	t.count++
+	t.amount = t.amount.Add(parseAmount(split.Amount).Abs())
+	if !slices.Contains(t.groups, groupId) {
+		t.groups = append(t.groups, groupId)
+	}
+}
+
+// mismatch returns the totals as a mismatch, listing at most maxMismatchTransactionIds groups
+func (t *mismatchTotals) mismatch(kind, id, name, expected, found string) CurrencyMismatch {
+	groups := append([]string(nil), t.groups...)
+	sort.Slice(groups, func(i, j int) bool { return lessNumericId(groups[i], groups[j]) })
+	return CurrencyMismatch{
+		Kind:             kind,
+		Id:               id,
+		Name:             name,
+		ExpectedCurrency: expected,
+		FoundCurrency:    found,
+		TransactionCount: t.count,
+		Amount:           formatAmount(t.amount),
+		TransactionIds:   groups[:min(len(groups), maxMismatchTransactionIds)],
+	}
+}
+
+// billCurrencyMismatches flags bills with linked transactions in another currency.
+// Firefly III compares them to the bill amounts as if they were in the bill's currency.
+func billCurrencyMismatches(bills []Bill, groups []TransactionGroup) []CurrencyMismatch {
+	byBill := make(map[string]map[string]*mismatchTotals)
+	for _, group := range groups {
+		for _, split := range group.Transactions {
+			billId := getStringValue(split.BillId)
+			if billId == "" || split.CurrencyCode == "" {
+				continue
+			}
+			if byBill[billId] == nil {
+				byBill[billId] = make(map[string]*mismatchTotals)
+			}
+			if byBill[billId][split.CurrencyCode] == nil {
+				byBill[billId][split.CurrencyCode] = &mismatchTotals{}
+			}
+			byBill[billId][split.CurrencyCode].add(group.Id, split)
+		}
+	}
+
+	mismatches := []CurrencyMismatch{}
+	for _, bill := range bills {
+		for _, currency := range sortedKeys(byBill[bill.Id]) {
+			if bill.CurrencyCode == "" || strings.EqualFold(currency, bill.CurrencyCode) {
+				continue
+			}
+			mismatch := byBill[bill.Id][currency].mismatch(mismatchKindBill, bill.Id, bill.Name, bill.CurrencyCode, currency)
+			mismatch.Fix = fmt.Sprintf(
+				"If the bill is paid in %s, change its currency to %s and adjust amount_min and amount_max; "+
+					"otherwise unlink the transactions with unlink_transaction_from_bill", currency, currency,
+			)
+			mismatch.Request = "PUT /v1/bills/" + bill.Id
+			mismatches = append(mismatches, mismatch)
+		}
+	}
+	sortCurrencyMismatches(mismatches)
+	return mismatches
+}
+
+// budgetLimitCurrencyMismatches flags budget limits next to which the budget
+// has transactions in a currency no limit of the period covers. Those
+// transactions do not count against any limit. Transactions of budgets without
+// a limit on their date are not currency mismatches and are left out.
+func budgetLimitCurrencyMismatches(limits *BudgetLimitList, budgets []catalogItem, groups []TransactionGroup) []CurrencyMismatch {
+	if limits == nil {
+		return []CurrencyMismatch{}
+	}
+	names := make(map[string]string, len(budgets))
+	for _, budget := range budgets {
+		names[budget.Id] = budget.Name
+	}
+	byId := make(map[string]BudgetLimit, len(limits.Data))
+	for _, limit := range limits.Data {
+		byId[limit.Id] = limit
+	}
+
+	byLimit := make(map[string]map[string]*mismatchTotals)
+	for _, group := range groups {
+		for _, split := range group.Transactions {
+			budgetId := getStringValue(split.BudgetId)
+			if budgetId == "" || split.CurrencyCode == "" {
+				continue
+			}
+			covering := limitsOn(limits, budgetId, split.Date)
+			if len(covering) == 0 || slices.ContainsFunc(covering, func(limit BudgetLimit) bool {
+				return strings.EqualFold(limit.CurrencyCode, split.CurrencyCode)
+			}) {
+				continue
+			}
+			limitId := covering[0].Id
+			if byLimit[limitId] == nil {
+				byLimit[limitId] = make(map[string]*mismatchTotals)
+			}
+			if byLimit[limitId][split.CurrencyCode] == nil {
+				byLimit[limitId][split.CurrencyCode] = &mismatchTotals{}
+			}
+			byLimit[limitId][split.CurrencyCode].add(group.Id, split)
+		}
+	}
+
+	mismatches := []CurrencyMismatch{}
+	for limitId, byCurrency := range byLimit {
+		limit := byId[limitId]
+		name := names[limit.BudgetId]
+		if name == "" {
+			name = "Budget " + limit.BudgetId
+		}
+		for _, currency := range sortedKeys(byCurrency) {
+			mismatch := byCurrency[currency].mismatch(mismatchKindBudgetLimit, limit.Id, name, limit.CurrencyCode, currency)
+			mismatch.Fix = fmt.Sprintf(
+				"Add a %s limit to budget %s for %s to %s, so these transactions count against a limit",
+				currency, name, limit.Start.Format("2006-01-02"), limit.End.Format("2006-01-02"),
+			)
+			mismatch.Request = "POST /v1/budgets/" + limit.BudgetId + "/limits"
+			mismatches = append(mismatches, mismatch)
+		}
+	}
+	sortCurrencyMismatches(mismatches)
+	return mismatches
+}
+
+// limitsOn returns the limits of a budget whose period contains a date, by ID
+func limitsOn(limits *BudgetLimitList, budgetId string, date time.Time) []BudgetLimit {
+	day := date.Format("2006-01-02")
+	var covering []BudgetLimit
+	for _, limit := range limits.Data {
+		if limit.BudgetId == budgetId && day >= limit.Start.Format("2006-01-02") && day <= limit.End.Format("2006-01-02") {
+			covering = append(covering, limit)
+		}
+	}
+	sort.Slice(covering, func(i, j int) bool { return lessNumericId(covering[i].Id, covering[j].Id) })
+	return covering
+}
+
+// accountCurrencyMismatches flags splits whose currency differs from the
+// currency of their asset side: the source of withdrawals and transfers, the
+// destination of deposits. Usually the account currency was changed after
+// they were booked, so balances mix currencies.
+func accountCurrencyMismatches(accounts []currencyAccount, groups []TransactionGroup) []CurrencyMismatch {
+	byId := make(map[string]currencyAccount, len(accounts))
+	for _, account := range accounts {
+		byId[account.Id] = account
+	}
+
+	mismatches := []CurrencyMismatch{}
+	for _, group := range groups {
+		for _, split := range group.Transactions {
+			accountId := split.SourceId
+			if split.Type == "deposit" {
+				accountId = split.DestinationId
+			}
+			account, ok := byId[accountId]
+			if !ok || account.CurrencyCode == "" || split.CurrencyCode == "" ||
+				strings.EqualFold(split.CurrencyCode, account.CurrencyCode) {
+				continue
+			}
+			name := split.Description
+			if group.GroupTitle != "" {
+				name = group.GroupTitle
+			}
+			mismatches = append(mismatches, CurrencyMismatch{
+				Kind:             mismatchKindTransaction,
+				Id:               group.Id,
+				Name:             name,
+				ExpectedCurrency: account.CurrencyCode,
+				FoundCurrency:    split.CurrencyCode,
+				TransactionCount: 1,
+				Amount:           formatAmount(parseAmount(split.Amount).Abs()),
+				TransactionIds:   []string{group.Id},
+				Fix: fmt.Sprintf(
+					"Account %s is in %s: set currency_code %s and the amount in %s on split %s, "+
+						"keeping the %s amount as foreign_amount with foreign_currency_code %s",
+					account.Name, account.CurrencyCode, account.CurrencyCode, account.CurrencyCode,
+					split.JournalId, split.CurrencyCode, split.CurrencyCode,
+				),
+				Request: "update_transaction " + group.Id,
+			})
+		}
+	}
+	sortCurrencyMismatches(mismatches)
+	return mismatches
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortCurrencyMismatches orders mismatches by name, then ID
+func sortCurrencyMismatches(mismatches []CurrencyMismatch) {
+	sort.SliceStable(mismatches, func(i, j int) bool {
+		if !strings.EqualFold(mismatches[i].Name, mismatches[j].Name) {
+			return strings.ToLower(mismatches[i].Name) < strings.ToLower(mismatches[j].Name)
+		}
+		return lessNumericId(mismatches[i].Id, mismatches[j].Id)
+	})
+}
@@ -0,0 +1,111 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindCurrencyMismatches(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch r.URL.Path {
+		case "/v1/transactions":
+			w.Write([]byte(`{"data":[` +
+				`{"type":"transactions","id":"1","attributes":{"transactions":[{"type":"withdrawal","journal_id":"11",` +
+				`"date":"2024-05-02T00:00:00+00:00","amount":"9.99","currency_code":"USD","description":"Netflix",` +
+				`"source_id":"1","destination_id":"30","bill_id":"7","budget_id":"10"}]}},` +
+				`{"type":"transactions","id":"2","attributes":{"transactions":[{"type":"withdrawal","journal_id":"12",` +
+				`"date":"2024-05-03T00:00:00+00:00","amount":"9.99","currency_code":"EUR","description":"Netflix",` +
+				`"source_id":"2","destination_id":"30","bill_id":"7","budget_id":"10"}]}},` +
+				`{"type":"transactions","id":"3","attributes":{"transactions":[{"type":"deposit","journal_id":"13",` +
+				`"date":"2024-05-04T00:00:00+00:00","amount":"1500.00","currency_code":"EUR","description":"Salary",` +
+				`"source_id":"40","destination_id":"2"}]}}],` +
+				`"meta":{"pagination":{"total":3,"count":3,"per_page":200,"current_page":1,"total_pages":1}}}`))
+		case "/v1/bills":
+			w.Write([]byte(`{"data":[{"type":"bills","id":"7","attributes":{"name":"Netflix","currency_code":"EUR",` +
+				`"amount_min":"9.99","amount_max":"9.99","repeat_freq":"monthly"}}],` +
+				`"meta":{"pagination":{"total":1,"count":1,"per_page":200,"current_page":1,"total_pages":1}}}`))
+		case "/v1/budget-limits":
+			w.Write([]byte(`{"data":[{"type":"budget_limits","id":"3","attributes":{"amount":"50.00",` +
+				`"budget_id":"10","currency_code":"EUR","start":"2024-05-01T00:00:00Z","end":"2024-05-31T00:00:00Z"}}],"meta":{}}`))
+		case "/v1/budgets":
+			w.Write([]byte(`{"data":[{"type":"budgets","id":"10","attributes":{"name":"Subscriptions"}}],"meta":{}}`))
+		case "/v1/accounts":
+			w.Write([]byte(`{"data":[` +
+				`{"type":"accounts","id":"1","attributes":{"name":"US card","type":"asset","currency_code":"USD"}},` +
+				`{"type":"accounts","id":"2","attributes":{"name":"Checking","type":"asset","currency_code":"USD"}},` +
+				`{"type":"accounts","id":"30","attributes":{"name":"Netflix","type":"expense"}}],"meta":{}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	result, err := server.tools["find_currency_mismatches"].invoke(context.Background(), nil,
+		[]byte(`{"start":"2024-05-01","end":"2024-05-31"}`))
+	require.NoError(t, err)
+	text := result.Content[0].(*mcp.TextContent).Text
+	require.False(t, result.IsError, text)
+	var report CurrencyMismatchReport
+	require.NoError(t, json.Unmarshal([]byte(text), &report))
+
+	assert.Equal(t, 3, report.TransactionCount)
+	assert.Empty(t, report.Warnings)
+
+	type mismatch struct{ Kind, Id, Expected, Found string }
+	var mismatches []mismatch
+	for _, m := range report.Mismatches {
+		mismatches = append(mismatches, mismatch{m.Kind, m.Id, m.ExpectedCurrency, m.FoundCurrency})
+	}
+	assert.Equal(t, []mismatch{
+		{mismatchKindBill, "7", "EUR", "USD"},
+		{mismatchKindBudgetLimit, "3", "EUR", "USD"},
+		{mismatchKindTransaction, "2", "USD", "EUR"},
+		{mismatchKindTransaction, "3", "USD", "EUR"},
+	}, mismatches, "EUR transactions match the bill and limit; USD ones match the US card")
+
+	bill := report.Mismatches[0]
+	assert.Equal(t, []string{"1"}, bill.TransactionIds)
+	assert.Equal(t, "9.99", bill.Amount)
+	assert.Equal(t, "PUT /v1/bills/7", bill.Request)
+	assert.Equal(t, "Subscriptions", report.Mismatches[1].Name)
+	assert.Contains(t, report.Mismatches[1].Fix, "Add a USD limit to budget Subscriptions for 2024-05-01 to 2024-05-31")
+	assert.Equal(t, "update_transaction 3", report.Mismatches[3].Request)
+	assert.Contains(t, report.Mismatches[3].Fix, "Account Checking is in USD", "deposits are checked against their destination")
+}
+
+func TestBudgetLimitCurrencyMismatches_CoveredByAnotherLimit(t *testing.T) {
+	date := func(value string) time.Time {
+		parsed, err := time.Parse("2006-01-02", value)
+		require.NoError(t, err)
+		return parsed
+	}
+	limits := &BudgetLimitList{Data: []BudgetLimit{
+		{Id: "1", BudgetId: "10", CurrencyCode: "EUR", Start: date("2024-05-01"), End: date("2024-05-31")},
+		{Id: "2", BudgetId: "10", CurrencyCode: "USD", Start: date("2024-05-01"), End: date("2024-05-31")},
+	}}
+	groups := []TransactionGroup{
+		{Id: "1", Transactions: []Transaction{{BudgetId: strPtr("10"), CurrencyCode: "USD", Amount: "5", Date: date("2024-05-02")}}},
+		{Id: "2", Transactions: []Transaction{{BudgetId: strPtr("10"), CurrencyCode: "GBP", Amount: "5", Date: date("2024-05-02")}}},
+		{Id: "3", Transactions: []Transaction{{BudgetId: strPtr("10"), CurrencyCode: "GBP", Amount: "5", Date: date("2024-06-02")}}},
+	}
+
+	mismatches := budgetLimitCurrencyMismatches(limits, nil, groups)
+	require.Len(t, mismatches, 1, "covered currencies and dates without limits are fine; GBP is reported once")
+	assert.Equal(t, "1", mismatches[0].Id)
+	assert.Equal(t, "GBP", mismatches[0].FoundCurrency)
+	assert.Equal(t, []string{"2"}, mismatches[0].TransactionIds)
+}
@@ -0,0 +1,194 @@
+package fireflyMCP
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// DateRange is the date range argument shared by tools that filter by date.
+// Start and end take dates or relative dates; period selects a named range instead.
+type DateRange struct {
+	Start  string `json:"start,omitempty" jsonschema:"Start date (YYYY-MM-DD or relative: today, yesterday, start_of_month, start_of_last_month, start_of_year, ...)"`
+	End    string `json:"end,omitempty" jsonschema:"End date (YYYY-MM-DD or relative: today, end_of_month, end_of_last_month, end_of_year, ...)"`
+	Period string `json:"period,omitempty" jsonschema:"Named period instead of start/end: this_week, last_week, this_month, last_month, this_year, last_year, year_to_date, last_N_days/weeks/months (e.g. last_90_days); summary, budget and insight tools also take fiscal_year, last_fiscal_year and fiscal_year_to_date (fiscal year start from the Firefly III preferences)"`
+}
+
+// dateRangeDefault selects what resolveDateRange does with bounds that are not given
+type dateRangeDefault int
+
+const (
+	dateRangeOpen         dateRangeDefault = iota // Missing bounds stay open
+	dateRangeCurrentMonth                         // Missing bounds default to the current month
+	dateRangeRequired                             // Start and end (or a period) are required
+)
+
+// resolvedDateRange is a resolved DateRange; nil bounds are open
+type resolvedDateRange struct {
+	Start *openapi_types.Date
+	End   *openapi_types.Date
+}
+
+// StartString returns the start as YYYY-MM-DD, or "" when open
+func (r resolvedDateRange) StartString() string {
+	if r.Start == nil {
+		return ""
+	}
+	return r.Start.Format("2006-01-02")
+}
+
+// EndString returns the end as YYYY-MM-DD, or "" when open
+func (r resolvedDateRange) EndString() string {
+	if r.End == nil {
+		return ""
+	}
+	return r.End.Format("2006-01-02")
+}
+
+// rollingPeriodPattern matches periods such as last_30_days
+var rollingPeriodPattern = regexp.MustCompile(`^last_(\d+)_(days|weeks|months)$`)
+
+// resolveDateRange resolves the dates of a DateRange in the configured timezone
+func (s *FireflyMCPServer) resolveDateRange(r DateRange, fallback dateRangeDefault) (resolvedDateRange, error) {
+	today := s.today()
+	var start, end *time.Time
+
+	if period := strings.ToLower(strings.TrimSpace(r.Period)); period != "" {
+		if r.Start != "" || r.End != "" {
+			return resolvedDateRange{}, fmt.Errorf("use either period or start/end, not both")
+		}
+		periodStart, periodEnd, err := namedPeriod(period, today)
+		if err != nil {
+			return resolvedDateRange{}, err
+		}
+		start, end = &periodStart, &periodEnd
+	} else {
+		if fallback == dateRangeRequired && (r.Start == "" || r.End == "") {
+			return resolvedDateRange{}, fmt.Errorf("Start and End dates are required")
+		}
+		if r.Start != "" {
+			date, err := resolveDate(r.Start, today)
+			if err != nil {
+				return resolvedDateRange{}, fmt.Errorf("Invalid start date format: %v", err)
+			}
+			start = &date
+		}
+		if r.End != "" {
+			date, err := resolveDate(r.End, today)
+			if err != nil {
+				return resolvedDateRange{}, fmt.Errorf("Invalid end date format: %v", err)
+			}
+			end = &date
+		}
+	}
+
+	if fallback == dateRangeCurrentMonth {
+		monthStart, monthEnd, _ := namedPeriod("this_month", today)
+		if start == nil {
+			start = &monthStart
+		}
+		if end == nil {
+			end = &monthEnd
+		}
+	}
+	if start != nil && end != nil && end.Before(*start) {
+		return resolvedDateRange{}, fmt.Errorf("End date %s is before start date %s",
+			end.Format("2006-01-02"), start.Format("2006-01-02"))
+	}
+
+	resolved := resolvedDateRange{}
+	if start != nil {
+		resolved.Start = &openapi_types.Date{Time: *start}
+	}
+	if end != nil {
+		resolved.End = &openapi_types.Date{Time: *end}
+	}
+	return resolved, nil
+}
+
+// today returns the current date in the configured timezone, as a UTC midnight
+// like the dates parsed from YYYY-MM-DD
+func (s *FireflyMCPServer) today() time.Time {
+	now := s.now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// resolveDate parses a YYYY-MM-DD date or a relative date keyword
+func resolveDate(value string, today time.Time) (time.Time, error) {
+	keyword := strings.ToLower(strings.TrimSpace(value))
+	if offset, ok := relativeDateOffsets[keyword]; ok {
+		return today.AddDate(0, 0, offset), nil
+	}
+	monthStart := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	yearStart := time.Date(today.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	switch keyword {
+	case "start_of_week":
+		return startOfWeek(today), nil
+	case "end_of_week":
+		return startOfWeek(today).AddDate(0, 0, 6), nil
+	case "start_of_month":
+		return monthStart, nil
+	case "end_of_month":
+		return monthStart.AddDate(0, 1, -1), nil
+	case "start_of_last_month":
+		return monthStart.AddDate(0, -1, 0), nil
+	case "end_of_last_month":
+		return monthStart.AddDate(0, 0, -1), nil
+	case "start_of_year":
+		return yearStart, nil
+	case "end_of_year":
+		return yearStart.AddDate(1, 0, -1), nil
+	}
+	return time.Parse("2006-01-02", strings.TrimSpace(value))
+}
+
+// namedPeriod returns the first and last day of a named period
+func namedPeriod(period string, today time.Time) (time.Time, time.Time, error) {
+	monthStart := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	yearStart := time.Date(today.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	switch period {
+	case "this_week":
+		return startOfWeek(today), startOfWeek(today).AddDate(0, 0, 6), nil
+	case "last_week":
+		return startOfWeek(today).AddDate(0, 0, -7), startOfWeek(today).AddDate(0, 0, -1), nil
+	case "this_month":
+		return monthStart, monthStart.AddDate(0, 1, -1), nil
+	case "last_month":
+		return monthStart.AddDate(0, -1, 0), monthStart.AddDate(0, 0, -1), nil
+	case "this_year":
+		return yearStart, yearStart.AddDate(1, 0, -1), nil
+	case "last_year":
+		return yearStart.AddDate(-1, 0, 0), yearStart.AddDate(0, 0, -1), nil
+	case "year_to_date":
+		return yearStart, today, nil
+	case periodFiscalYear, periodLastFiscalYear, periodFiscalYearToDate:
+		return time.Time{}, time.Time{}, fmt.Errorf("period %q is only supported by the summary, budget and insight tools", period)
+	}
+
+	match := rollingPeriodPattern.FindStringSubmatch(period)
+	if match == nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("unknown period %q", period)
+	}
+	n, err := strconv.Atoi(match[1])
+	if err != nil || n < 1 || n > 3660 {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid period %q", period)
+	}
+	switch match[2] {
+	case "days":
+		return today.AddDate(0, 0, -(n - 1)), today, nil
+	case "weeks":
+		return today.AddDate(0, 0, -7*n+1), today, nil
+	default:
+		return today.AddDate(0, -n, 1), today, nil
+	}
+}
+
+// startOfWeek returns the Monday of the week of a date
+func startOfWeek(date time.Time) time.Time {
+	offset := (int(date.Weekday()) + 6) % 7
+	return date.AddDate(0, 0, -offset)
+}
@@ -0,0 +1,139 @@
+package fireflyMCP
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamedPeriod(t *testing.T) {
+	// A Wednesday
+	today := time.Date(2024, 3, 13, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		period string
+		start  string
+		end    string
+	}{
+		{"this_week", "2024-03-11", "2024-03-17"},
+		{"last_week", "2024-03-04", "2024-03-10"},
+		{"this_month", "2024-03-01", "2024-03-31"},
+		{"last_month", "2024-02-01", "2024-02-29"},
+		{"this_year", "2024-01-01", "2024-12-31"},
+		{"last_year", "2023-01-01", "2023-12-31"},
+		{"year_to_date", "2024-01-01", "2024-03-13"},
+		{"last_7_days", "2024-03-07", "2024-03-13"},
+		{"last_2_weeks", "2024-02-29", "2024-03-13"},
+		{"last_3_months", "2023-12-14", "2024-03-13"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.period, func(t *testing.T) {
+			start, end, err := namedPeriod(tt.period, today)
+			require.NoError(t, err)
+			assert.Equal(t, tt.start, start.Format("2006-01-02"))
+			assert.Equal(t, tt.end, end.Format("2006-01-02"))
+		})
+	}
+
+	for _, period := range []string{"next_month", "last_0_days", "last_x_days"} {
+		_, _, err := namedPeriod(period, today)
+		assert.Error(t, err, period)
+	}
+}
+
+func TestResolveDate(t *testing.T) {
+	today := time.Date(2024, 3, 13, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]string{
+		"today":               "2024-03-13",
+		"Yesterday":           "2024-03-12",
+		"start_of_week":       "2024-03-11",
+		"end_of_week":         "2024-03-17",
+		"start_of_month":      "2024-03-01",
+		"end_of_month":        "2024-03-31",
+		"start_of_last_month": "2024-02-01",
+		"end_of_last_month":   "2024-02-29",
+		"start_of_year":       "2024-01-01",
+		"end_of_year":         "2024-12-31",
+		"2023-07-04":          "2023-07-04",
+	}
+	for value, want := range tests {
+		date, err := resolveDate(value, today)
+		require.NoError(t, err, value)
+		assert.Equal(t, want, date.Format("2006-01-02"), value)
+	}
+
+	_, err := resolveDate("04/07/2023", today)
+	assert.Error(t, err)
+}
+
+func TestResolveDateRange(t *testing.T) {
+	server, err := NewFireflyMCPServer(newPluginTestConfig())
+	require.NoError(t, err)
+	today := server.today()
+	monthStart, monthEnd, _ := namedPeriod("this_month", today)
+
+	t.Run("open", func(t *testing.T) {
+		dates, err := server.resolveDateRange(DateRange{Start: "2024-01-01"}, dateRangeOpen)
+		require.NoError(t, err)
+		assert.Equal(t, "2024-01-01", dates.StartString())
+		assert.Nil(t, dates.End)
+		assert.Equal(t, "", dates.EndString())
+	})
+
+	t.Run("current month default", func(t *testing.T) {
+		dates, err := server.resolveDateRange(DateRange{}, dateRangeCurrentMonth)
+		require.NoError(t, err)
+		assert.Equal(t, monthStart.Format("2006-01-02"), dates.StartString())
+		assert.Equal(t, monthEnd.Format("2006-01-02"), dates.EndString())
+	})
+
+	t.Run("relative dates", func(t *testing.T) {
+		dates, err := server.resolveDateRange(DateRange{Start: "start_of_month", End: "today"}, dateRangeRequired)
+		require.NoError(t, err)
+		assert.Equal(t, monthStart.Format("2006-01-02"), dates.StartString())
+		assert.Equal(t, today.Format("2006-01-02"), dates.EndString())
+	})
+
+	t.Run("period", func(t *testing.T) {
+		dates, err := server.resolveDateRange(DateRange{Period: "This_Month"}, dateRangeRequired)
+		require.NoError(t, err)
+		assert.Equal(t, monthStart.Format("2006-01-02"), dates.StartString())
+		assert.Equal(t, monthEnd.Format("2006-01-02"), dates.EndString())
+	})
+
+	errorTests := []struct {
+		name     string
+		dates    DateRange
+		fallback dateRangeDefault
+		want     string
+	}{
+		{"required", DateRange{Start: "2024-01-01"}, dateRangeRequired, "Start and End dates are required"},
+		{"period and start", DateRange{Start: "2024-01-01", Period: "last_month"}, dateRangeOpen, "either period or start/end"},
+		{"unknown period", DateRange{Period: "someday"}, dateRangeOpen, "unknown period"},
+		{"invalid start", DateRange{Start: "01/01/2024"}, dateRangeOpen, "Invalid start date format"},
+		{"invalid end", DateRange{End: "31-01-2024"}, dateRangeOpen, "Invalid end date format"},
+		{"end before start", DateRange{Start: "2024-02-01", End: "2024-01-31"}, dateRangeOpen, "is before start date"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := server.resolveDateRange(tt.dates, tt.fallback)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestTodayUsesConfiguredTimezone(t *testing.T) {
+	config := newPluginTestConfig()
+	config.Dates.Timezone = "Pacific/Kiritimati"
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	location, err := time.LoadLocation("Pacific/Kiritimati")
+	require.NoError(t, err)
+	assert.Equal(t, time.Now().In(location).Format("2006-01-02"), server.today().Format("2006-01-02"))
+	assert.Equal(t, time.UTC, server.today().Location())
+}
@@ -0,0 +1,125 @@
+package fireflyMCP
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// relativeDateOffsets maps the relative date keywords accepted for transaction
+// dates to their offset in days from today
+var relativeDateOffsets = map[string]int{
+	"today":     0,
+	"yesterday": -1,
+	"tomorrow":  1,
+}
+
+// serverClock tracks the difference between the local clock and the clock of the
+// Firefly III server, as observed from the Date header of API responses
+type serverClock struct {
+	mu      sync.Mutex
+	skew    time.Duration // Server time minus local time
+	known   bool
+	warned  bool
+	maxSkew time.Duration // Skew above which a warning is logged (0 disables the warning)
+}
+
+// observe records the server time reported by a response received at local time now
+func (c *serverClock) observe(serverTime, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.skew = serverTime.Sub(now)
+	c.known = true
+
+	if c.maxSkew > 0 && !c.warned && absDuration(c.skew) > c.maxSkew {
+		c.warned = true
+		slog.Warn(
+			"clock skew between MCP server and Firefly III detected",
+			"skew", c.skew.Round(time.Second).String(),
+			"threshold", c.maxSkew.String(),
+		)
+	}
+}
+
+// offset returns the last observed skew, and whether any skew has been observed
+func (c *serverClock) offset() (time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.skew, c.known
+}
+
+// clockSkewTransport records the Date header of every Firefly III response
+type clockSkewTransport struct {
+	base  http.RoundTripper
+	clock *serverClock
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *clockSkewTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	if serverTime, err := http.ParseTime(resp.Header.Get("Date")); err == nil {
+		t.clock.observe(serverTime, time.Now())
+	}
+	return resp, nil
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// loadDateLocation returns the location configured in dates.timezone, or the
+// local timezone of the host if none is configured
+func loadDateLocation(config *Config) (*time.Location, error) {
+	if config == nil || config.Dates.Timezone == "" {
+		return time.Local, nil
+	}
+	return time.LoadLocation(config.Dates.Timezone)
+}
+
+// now returns the current time in the configured timezone. When dates.use_server_time
+// is enabled, the local clock is corrected by the skew observed from Firefly III.
+func (s *FireflyMCPServer) now() time.Time {
+	now := time.Now()
+	if config := s.currentConfig(); config != nil && config.Dates.UseServerTime && s.clock != nil {
+		if skew, ok := s.clock.offset(); ok {
+			now = now.Add(skew)
+		}
+	}
+	if s.location != nil {
+		now = now.In(s.location)
+	}
+	return now
+}
+
+// resolveRelativeDate converts the keywords "today", "yesterday" and "tomorrow"
+// (case-insensitive) to a YYYY-MM-DD date in the configured timezone.
+// Any other value is returned unchanged.
+func (s *FireflyMCPServer) resolveRelativeDate(value string) string {
+	offset, ok := relativeDateOffsets[strings.ToLower(strings.TrimSpace(value))]
+	if !ok {
+		return value
+	}
+	return s.now().AddDate(0, 0, offset).Format("2006-01-02")
+}
+
+// resolveSplitDates returns a copy of the splits with relative dates resolved
+func (s *FireflyMCPServer) resolveSplitDates(splits []TransactionSplitRequest) []TransactionSplitRequest {
+	if len(splits) == 0 {
+		return splits
+	}
+	resolved := make([]TransactionSplitRequest, len(splits))
+	for i, split := range splits {
+		split.Date = s.resolveRelativeDate(split.Date)
+		resolved[i] = split
+	}
+	return resolved
+}
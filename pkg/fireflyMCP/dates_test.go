@@ -0,0 +1,85 @@
+package fireflyMCP
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveRelativeDate(t *testing.T) {
+	loc, err := time.LoadLocation("Pacific/Auckland")
+	require.NoError(t, err)
+	s := &FireflyMCPServer{location: loc}
+
+	today := time.Now().In(loc)
+	assert.Equal(t, today.Format("2006-01-02"), s.resolveRelativeDate("today"))
+	assert.Equal(t, today.Format("2006-01-02"), s.resolveRelativeDate(" Today "))
+	assert.Equal(t, today.AddDate(0, 0, -1).Format("2006-01-02"), s.resolveRelativeDate("yesterday"))
+	assert.Equal(t, today.AddDate(0, 0, 1).Format("2006-01-02"), s.resolveRelativeDate("TOMORROW"))
+	assert.Equal(t, "2024-01-15", s.resolveRelativeDate("2024-01-15"))
+	assert.Equal(t, "", s.resolveRelativeDate(""))
+}
+
+func TestResolveSplitDates(t *testing.T) {
+	s := &FireflyMCPServer{location: time.UTC}
+	splits := []TransactionSplitRequest{
+		{Date: "today", Description: "coffee"},
+		{Date: "2024-01-15", Description: "rent"},
+	}
+
+	resolved := s.resolveSplitDates(splits)
+
+	require.Len(t, resolved, 2)
+	assert.Equal(t, time.Now().UTC().Format("2006-01-02"), resolved[0].Date)
+	assert.Equal(t, "2024-01-15", resolved[1].Date)
+	assert.Equal(t, "today", splits[0].Date, "input must not be modified")
+}
+
+func TestServerNowUsesServerTime(t *testing.T) {
+	config := &Config{}
+	config.Dates.UseServerTime = true
+	clock := &serverClock{}
+	clock.observe(time.Now().Add(48*time.Hour), time.Now())
+
+	s := &FireflyMCPServer{config: config, location: time.UTC, clock: clock}
+	assert.Equal(t, time.Now().UTC().AddDate(0, 0, 2).Format("2006-01-02"), s.resolveRelativeDate("today"))
+
+	config.Dates.UseServerTime = false
+	assert.Equal(t, time.Now().UTC().Format("2006-01-02"), s.resolveRelativeDate("today"))
+}
+
+func TestClockSkewTransport(t *testing.T) {
+	serverTime := time.Now().Add(-5 * time.Hour).UTC()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", serverTime.Format(http.TimeFormat))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	clock := &serverClock{maxSkew: 2 * time.Hour}
+	httpClient := &http.Client{Transport: &clockSkewTransport{base: http.DefaultTransport, clock: clock}}
+
+	resp, err := httpClient.Get(ts.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	skew, ok := clock.offset()
+	require.True(t, ok)
+	assert.InDelta(t, (-5 * time.Hour).Seconds(), skew.Seconds(), 5)
+	assert.True(t, clock.warned, "skew above threshold should be reported")
+}
+
+func TestServerClockBelowThreshold(t *testing.T) {
+	clock := &serverClock{maxSkew: 2 * time.Hour}
+	now := time.Now()
+	clock.observe(now.Add(30*time.Minute), now)
+
+	skew, ok := clock.offset()
+	require.True(t, ok)
+	assert.Equal(t, 30*time.Minute, skew)
+	assert.False(t, clock.warned)
+}
@@ -0,0 +1,130 @@
+package fireflyMCP
+
+import (
+	"math/big"
+	"strings"
+)
+
+// decimal is an exact decimal number for money arithmetic. Amounts are kept as
+// rationals, so sums of many amounts never drift by cents the way float64 sums
+// do. The zero value is 0; values are immutable.
+type decimal struct {
+	value *big.Rat
+}
+
+// parseDecimal parses a decimal amount such as "-12.34" or "1e3"
+func parseDecimal(amount string) (decimal, bool) {
+	amount = strings.TrimSpace(amount)
+	if amount == "" || strings.Contains(amount, "/") {
+		return decimal{}, false
+	}
+	value, ok := new(big.Rat).SetString(amount)
+	if !ok {
+		return decimal{}, false
+	}
+	return decimal{value: value}, true
+}
+
+// parseAmount parses a Firefly III amount, treating invalid values as zero
+func parseAmount(amount string) decimal {
+	value, _ := parseDecimal(amount)
+	return value
+}
+
+// formatAmount formats an amount with two decimals
+func formatAmount(amount decimal) string {
+	return amount.StringFixed(2)
+}
+
+// formatShare formats part as a percentage of total with two decimals. Signs
+// are ignored, so shares of expenses are positive; a zero total has no share.
+func formatShare(part, total decimal) string {
+	if total.Sign() == 0 {
+		return ""
+	}
+	return formatAmount(part.Abs().Mul(decimalFromInt(100)).Div(total.Abs()))
+}
+
+// decimalFromInt returns n as a decimal
+func decimalFromInt(n int64) decimal {
+	return decimal{value: new(big.Rat).SetInt64(n)}
+}
+
+func (d decimal) rat() *big.Rat {
+	if d.value == nil {
+		return new(big.Rat)
+	}
+	return d.value
+}
+
+// Add returns d + other
+func (d decimal) Add(other decimal) decimal {
+	return decimal{value: new(big.Rat).Add(d.rat(), other.rat())}
+}
+
+// Sub returns d - other
+func (d decimal) Sub(other decimal) decimal {
+	return decimal{value: new(big.Rat).Sub(d.rat(), other.rat())}
+}
+
+// Mul returns d * other
+func (d decimal) Mul(other decimal) decimal {
+	return decimal{value: new(big.Rat).Mul(d.rat(), other.rat())}
+}
+
+// Div returns d / other, or 0 when other is 0
+func (d decimal) Div(other decimal) decimal {
+	if other.Sign() == 0 {
+		return decimal{}
+	}
+	return decimal{value: new(big.Rat).Quo(d.rat(), other.rat())}
+}
+
+// Neg returns -d
+func (d decimal) Neg() decimal {
+	return decimal{value: new(big.Rat).Neg(d.rat())}
+}
+
+// Abs returns |d|
+func (d decimal) Abs() decimal {
+	return decimal{value: new(big.Rat).Abs(d.rat())}
+}
+
+// Sign returns -1, 0 or +1
+func (d decimal) Sign() int {
+	return d.rat().Sign()
+}
+
+// Cmp compares d and other and returns -1, 0 or +1
+func (d decimal) Cmp(other decimal) int {
+	return d.rat().Cmp(other.rat())
+}
+
+// Min returns the smaller of d and other
+func (d decimal) Min(other decimal) decimal {
+	if d.Cmp(other) <= 0 {
+		return d
+	}
+	return other
+}
+
+// StringFixed formats d with the given number of decimals, rounding halves away
+// from zero. Negative zero is formatted as zero.
+func (d decimal) StringFixed(places int) string {
+	formatted := d.rat().FloatString(places)
+	if strings.HasPrefix(formatted, "-") && strings.Trim(formatted, "-0.") == "" {
+		return formatted[1:]
+	}
+	return formatted
+}
+
+// String formats d with two decimals
+func (d decimal) String() string {
+	return d.StringFixed(2)
+}
+
+// Float64 returns the nearest float64 value of d
+func (d decimal) Float64() float64 {
+	value, _ := d.rat().Float64()
+	return value
+}
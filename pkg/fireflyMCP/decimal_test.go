@@ -0,0 +1,58 @@
+package fireflyMCP
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecimal_SumsDoNotDrift(t *testing.T) {
+	var floatSum float64
+	sum := decimal{}
+	for i := 0; i < 1000; i++ {
+		floatSum += 0.1
+		sum = sum.Add(parseAmount("0.10"))
+	}
+	assert.NotEqual(t, 100.0, floatSum)
+	assert.Equal(t, 0, sum.Cmp(parseAmount("100")))
+	assert.Equal(t, "100.00", formatAmount(sum))
+}
+
+func TestDecimal_Parse(t *testing.T) {
+	for _, valid := range []string{"12.34", "-0.5", " 7 ", "1e3"} {
+		_, ok := parseDecimal(valid)
+		assert.True(t, ok, valid)
+	}
+	for _, invalid := range []string{"", "abc", "1/3", "12,34"} {
+		_, ok := parseDecimal(invalid)
+		assert.False(t, ok, invalid)
+	}
+	assert.Equal(t, "0.00", formatAmount(parseAmount("invalid")))
+}
+
+func TestDecimal_Arithmetic(t *testing.T) {
+	a, b := parseAmount("10.05"), parseAmount("-3.10")
+	assert.Equal(t, "6.95", a.Add(b).String())
+	assert.Equal(t, "13.15", a.Sub(b).String())
+	assert.Equal(t, "-31.16", a.Mul(b).String())
+	assert.Equal(t, "3.35", a.Div(decimalFromInt(3)).String())
+	assert.Equal(t, "0.00", a.Div(decimal{}).String(), "division by zero is zero")
+	assert.Equal(t, "3.10", b.Abs().String())
+	assert.Equal(t, "-10.05", a.Neg().String())
+	assert.Equal(t, b, a.Min(b))
+	assert.Equal(t, 0, decimal{}.Sign())
+}
+
+func TestDecimal_StringFixed(t *testing.T) {
+	assert.Equal(t, "0.13", parseAmount("0.125").String(), "halves round away from zero")
+	assert.Equal(t, "-0.13", parseAmount("-0.125").String())
+	assert.Equal(t, "0.00", parseAmount("-0.001").String(), "no negative zero")
+	assert.Equal(t, "1.235", parseAmount("1.2345").StringFixed(3))
+	assert.Equal(t, "12", parseAmount("12.4").StringFixed(0))
+}
+
+func TestFormatShare(t *testing.T) {
+	assert.Equal(t, "25.00", formatShare(parseAmount("-25"), parseAmount("-100")))
+	assert.Equal(t, "33.33", formatShare(parseAmount("1"), parseAmount("3")))
+	assert.Equal(t, "", formatShare(parseAmount("5"), decimal{}), "no share of a zero total")
+}
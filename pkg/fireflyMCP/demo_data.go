@@ -0,0 +1,360 @@
+package fireflyMCP
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/dezer32/mcp-firefly-iii/pkg/client"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+const (
+	defaultDemoMonths = 3
+	maxDemoMonths     = 24
+	demoTag           = "demo"
+)
+
+// GenerateDemoDataArgs represents the arguments for the generate_demo_data tool
+type GenerateDemoDataArgs struct {
+	Seed   int64  `json:"seed,omitempty" jsonschema:"Random seed; the same seed, months and end date create the same data (default: 1)"`
+	Months int    `json:"months,omitempty" jsonschema:"Number of months of transactions, ending with the month of end (default: 3, max: 24)"`
+	End    string `json:"end,omitempty" jsonschema:"Last date of generated transactions (YYYY-MM-DD, default: today)"`
+	Force  bool   `json:"force,omitempty" jsonschema:"Generate data even if the instance already has transactions"`
+}
+
+// DemoDataResult is the result of generate_demo_data
+type DemoDataResult struct {
+	Seed         int64    `json:"seed"`
+	Start        string   `json:"start"`
+	End          string   `json:"end"`
+	Accounts     int      `json:"accounts"`
+	Budgets      int      `json:"budgets"`
+	BudgetLimits int      `json:"budget_limits"`
+	Bills        int      `json:"bills"`
+	Categories   []string `json:"categories"`
+	Transactions int      `json:"transactions"`
+	Errors       []string `json:"errors,omitempty"`
+}
+
+// demoPlan is the data generate_demo_data creates. Transactions refer to
+// accounts, budgets and bills by name.
+type demoPlan struct {
+	Start        time.Time
+	End          time.Time
+	Accounts     []demoAccount
+	Budgets      []demoBudget
+	Bills        []demoBill
+	Transactions []demoTransaction
+}
+
+type demoAccount struct {
+	Name           string
+	Role           string
+	OpeningBalance string
+}
+
+type demoBudget struct {
+	Name  string
+	Limit string // Monthly limit
+}
+
+type demoBill struct {
+	Name   string
+	Amount string
+	Day    int
+}
+
+type demoTransaction struct {
+	Type        string
+	Date        time.Time
+	Amount      string
+	Description string
+	Source      string // Asset account for withdrawals and transfers, revenue account for deposits
+	Destination string // Expense account for withdrawals, asset account for deposits and transfers
+	Category    string
+	Budget      string
+	Bill        string
+}
+
+// demoSpending describes the recurring variable expenses of the demo household
+var demoSpending = []struct {
+	category string
+	budget   string
+	perMonth int
+	min, max float64
+	payees   []string
+}{
+	{"Groceries", "Groceries", 8, 18, 115, []string{"FreshMart", "Corner Grocer", "Organic Market", "SuperSave"}},
+	{"Dining Out", "Dining Out", 4, 12, 65, []string{"Luigi's Pizza", "Sushi Bar", "Cafe Central", "Burger House"}},
+	{"Transport", "Transport", 5, 8, 60, []string{"City Transit", "Shell Station", "Taxi Co"}},
+	{"Entertainment", "Entertainment", 2, 10, 45, []string{"Cinema Plaza", "Bookstore", "Concert Hall"}},
+	{"Health", "", 1, 15, 80, []string{"Pharmacy", "Dental Clinic"}},
+}
+
+// buildDemoPlan generates the demo data for the months up to end. The plan only
+// depends on its arguments.
+func buildDemoPlan(seed int64, months int, end time.Time) *demoPlan {
+	random := rand.New(rand.NewSource(seed))
+	firstMonth := time.Date(end.Year(), end.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -(months - 1), 0)
+	plan := &demoPlan{
+		Start: firstMonth,
+		End:   end,
+		Accounts: []demoAccount{
+			{Name: "Checking Account", Role: "defaultAsset", OpeningBalance: "2500.00"},
+			{Name: "Savings Account", Role: "savingAsset", OpeningBalance: "8000.00"},
+		},
+		Budgets: []demoBudget{
+			{Name: "Groceries", Limit: "450.00"},
+			{Name: "Dining Out", Limit: "150.00"},
+			{Name: "Transport", Limit: "120.00"},
+			{Name: "Entertainment", Limit: "80.00"},
+		},
+		Bills: []demoBill{
+			{Name: "Rent", Amount: "1150.00", Day: 1},
+			{Name: "Internet", Amount: "39.99", Day: 5},
+			{Name: "Mobile Phone", Amount: "24.50", Day: 12},
+			{Name: "Streaming Service", Amount: "12.99", Day: 18},
+		},
+	}
+
+	for month := firstMonth; !month.After(end); month = month.AddDate(0, 1, 0) {
+		days := month.AddDate(0, 1, -1).Day()
+		add := func(transaction demoTransaction) {
+			if !transaction.Date.After(end) {
+				plan.Transactions = append(plan.Transactions, transaction)
+			}
+		}
+
+		add(demoTransaction{
+			Type: "deposit", Date: month.AddDate(0, 0, 24), Amount: "3200.00", Description: "Salary",
+			Source: "Acme Corp", Destination: "Checking Account", Category: "Salary",
+		})
+		add(demoTransaction{
+			Type: "transfer", Date: month.AddDate(0, 0, 25), Amount: "300.00", Description: "Monthly savings",
+			Source: "Checking Account", Destination: "Savings Account",
+		})
+		for _, bill := range plan.Bills {
+			add(demoTransaction{
+				Type: "withdrawal", Date: month.AddDate(0, 0, bill.Day-1), Amount: bill.Amount, Description: bill.Name,
+				Source: "Checking Account", Destination: bill.Name + " Provider", Category: "Bills", Bill: bill.Name,
+			})
+		}
+		for _, spending := range demoSpending {
+			for i := 0; i < spending.perMonth; i++ {
+				payee := spending.payees[random.Intn(len(spending.payees))]
+				amount := spending.min + random.Float64()*(spending.max-spending.min)
+				add(demoTransaction{
+					Type: "withdrawal", Date: month.AddDate(0, 0, random.Intn(days)), Amount: fmt.Sprintf("%.2f", amount),
+					Description: payee, Source: "Checking Account", Destination: payee,
+					Category: spending.category, Budget: spending.budget,
+				})
+			}
+		}
+	}
+	return plan
+}
+
+// handleGenerateDemoData creates demo accounts, budgets with monthly limits,
+// bills and transactions. Everything created is tagged or named so it can be
+// recognized; failed objects are reported and skipped.
+func (s *FireflyMCPServer) handleGenerateDemoData(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args GenerateDemoDataArgs,
+) (*mcp.CallToolResult, any, error) {
+	months := args.Months
+	if months <= 0 {
+		months = defaultDemoMonths
+	}
+	if months > maxDemoMonths {
+		return newErrorResult(fmt.Sprintf("Error: months must be at most %d", maxDemoMonths))
+	}
+	seed := args.Seed
+	if seed == 0 {
+		seed = 1
+	}
+	end := s.today()
+	if args.End != "" {
+		parsed, err := resolveDate(args.End, end)
+		if err != nil {
+			return newErrorResult(fmt.Sprintf("Invalid end date: %v", err))
+		}
+		end = parsed
+	}
+
+	apiClient, err := s.getClient(ctx, req)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Failed to get API client: %v", err))
+	}
+	if !args.Force {
+		_, total, err := s.transactionPage(ctx, apiClient, 1)
+		if err != nil {
+			return newErrorResult(fmt.Sprintf("Error checking existing transactions: %v", err))
+		}
+		if total > 0 {
+			return newErrorResult(fmt.Sprintf("Error: the instance already has %d transactions; "+
+				"demo data is meant for empty instances (set force to add it anyway)", total))
+		}
+	}
+
+	plan := buildDemoPlan(seed, months, end)
+	result := &DemoDataResult{
+		Seed:       seed,
+		Start:      plan.Start.Format("2006-01-02"),
+		End:        plan.End.Format("2006-01-02"),
+		Categories: []string{},
+	}
+	fail := func(format string, args ...any) {
+		result.Errors = append(result.Errors, fmt.Sprintf(format, args...))
+	}
+
+	accountIDs := map[string]string{}
+	for _, account := range plan.Accounts {
+		resp, err := apiClient.StoreAccountWithBodyWithResponse(ctx, &client.StoreAccountParams{}, "application/json",
+			demoBody(map[string]any{
+				"name": account.Name, "type": "asset", "account_role": account.Role,
+				"opening_balance": account.OpeningBalance, "opening_balance_date": plan.Start.Format("2006-01-02"),
+			}))
+		id, err := s.createdID(resp, err)
+		if err != nil {
+			fail("account %s: %v", account.Name, err)
+			continue
+		}
+		accountIDs[account.Name] = id
+		result.Accounts++
+	}
+
+	budgetIDs := map[string]string{}
+	for _, budget := range plan.Budgets {
+		resp, err := apiClient.StoreBudgetWithResponse(ctx, &client.StoreBudgetParams{}, client.BudgetStore{Name: budget.Name})
+		id, err := s.createdID(resp, err)
+		if err != nil {
+			fail("budget %s: %v", budget.Name, err)
+			continue
+		}
+		budgetIDs[budget.Name] = id
+		result.Budgets++
+
+		for month := plan.Start; !month.After(plan.End); month = month.AddDate(0, 1, 0) {
+			resp, err := apiClient.StoreBudgetLimitWithResponse(ctx, id, &client.StoreBudgetLimitParams{},
+				client.StoreBudgetLimitJSONRequestBody{
+					Amount: budget.Limit,
+					Start:  openapi_types.Date{Time: month},
+					End:    openapi_types.Date{Time: month.AddDate(0, 1, -1)},
+				})
+			if _, err := s.createdID(resp, err); err != nil {
+				fail("budget limit %s %s: %v", budget.Name, month.Format("2006-01"), err)
+				continue
+			}
+			result.BudgetLimits++
+		}
+	}
+
+	billIDs := map[string]string{}
+	for _, bill := range plan.Bills {
+		resp, err := apiClient.StoreBillWithBodyWithResponse(ctx, &client.StoreBillParams{}, "application/json",
+			demoBody(map[string]any{
+				"name": bill.Name, "amount_min": bill.Amount, "amount_max": bill.Amount, "repeat_freq": "monthly",
+				"date": plan.Start.AddDate(0, 0, bill.Day-1).Format("2006-01-02"),
+			}))
+		id, err := s.createdID(resp, err)
+		if err != nil {
+			fail("bill %s: %v", bill.Name, err)
+			continue
+		}
+		billIDs[bill.Name] = id
+		result.Bills++
+	}
+
+	categories := map[string]bool{}
+	allowAutocreate := true // The demo's shops and employers are created from their names
+	for _, transaction := range plan.Transactions {
+		split := TransactionSplitRequest{
+			Type:        transaction.Type,
+			Date:        transaction.Date.Format("2006-01-02"),
+			Amount:      transaction.Amount,
+			Description: transaction.Description,
+			Tags:        []string{demoTag},
+		}
+		source, destination := transaction.Source, transaction.Destination
+		if id, ok := accountIDs[source]; ok {
+			split.SourceId = &id
+		} else {
+			split.SourceName = &source
+		}
+		if id, ok := accountIDs[destination]; ok {
+			split.DestinationId = &id
+		} else {
+			split.DestinationName = &destination
+		}
+		if transaction.Category != "" {
+			category := transaction.Category
+			split.CategoryName = &category
+		}
+		if id, ok := budgetIDs[transaction.Budget]; ok {
+			split.BudgetId = &id
+		}
+		if id, ok := billIDs[transaction.Bill]; ok {
+			split.BillId = &id
+		}
+
+		if _, err := callTool[TransactionStoreRequest, TransactionGroup](ctx, req, s.handleStoreTransaction,
+			TransactionStoreRequest{AllowAccountAutocreate: &allowAutocreate, Transactions: []TransactionSplitRequest{split}}); err != nil {
+			fail("transaction %s on %s: %v", transaction.Description, split.Date, err)
+			continue
+		}
+		result.Transactions++
+		if transaction.Category != "" && !categories[transaction.Category] {
+			categories[transaction.Category] = true
+			result.Categories = append(result.Categories, transaction.Category)
+		}
+	}
+
+	return newSuccessResult(result)
+}
+
+// demoBody encodes a request body
+func demoBody(body map[string]any) *bytes.Reader {
+	raw, _ := json.Marshal(body)
+	return bytes.NewReader(raw)
+}
+
+// createdID returns the ID of an object created by a store call
+func (s *FireflyMCPServer) createdID(resp interface {
+	StatusCode() int
+}, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	var body []byte
+	switch r := resp.(type) {
+	case *client.StoreAccountResponse:
+		body = r.Body
+	case *client.StoreBudgetResponse:
+		body = r.Body
+	case *client.StoreBudgetLimitResponse:
+		body = r.Body
+	case *client.StoreBillResponse:
+		body = r.Body
+	}
+	if !isSuccessStatus(resp.StatusCode()) {
+		return "", fmt.Errorf("API error %d - %s", resp.StatusCode(), s.upstreamError(body))
+	}
+	if isEmptyBody(resp.StatusCode(), body) {
+		return "", fmt.Errorf("no ID in empty response (status %d)", resp.StatusCode())
+	}
+	var created struct {
+		Data struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &created); err != nil || created.Data.ID == "" {
+		return "", fmt.Errorf("no ID in response: %s", s.upstreamError(body))
+	}
+	return created.Data.ID, nil
+}
@@ -0,0 +1,129 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildDemoPlan(t *testing.T) {
+	end := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+	plan := buildDemoPlan(7, 3, end)
+
+	assert.Equal(t, plan, buildDemoPlan(7, 3, end), "the same seed must create the same data")
+	assert.NotEqual(t, plan.Transactions, buildDemoPlan(8, 3, end).Transactions)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), plan.Start)
+
+	salaries := 0
+	for _, transaction := range plan.Transactions {
+		assert.False(t, transaction.Date.Before(plan.Start))
+		assert.False(t, transaction.Date.After(end), "no transactions after the end date")
+		if transaction.Description == "Salary" {
+			salaries++
+		}
+	}
+	// March's salary on the 25th is after the end date
+	assert.Equal(t, 2, salaries)
+}
+
+func TestGenerateDemoData(t *testing.T) {
+	var mu sync.Mutex
+	posts := map[string]int{}
+	existing := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		if r.Method == http.MethodGet && r.URL.Path == "/v1/transactions" {
+			if existing == 0 {
+				fmt.Fprint(w, `{"data":[],"meta":{"pagination":{"total":0}}}`)
+				return
+			}
+			fmt.Fprintf(w, `{"data":[{"type":"transactions","id":"1","attributes":{"transactions":[`+
+				`{"type":"withdrawal","date":"2024-01-01T00:00:00Z","amount":"1","description":"x","source_id":"1","destination_id":"2"}]}}],`+
+				`"meta":{"pagination":{"total":%d}}}`, existing)
+			return
+		}
+		if r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/v1/accounts/") {
+			// Currency lookups of store_transaction
+			fmt.Fprint(w, `{"data":{"type":"accounts","id":"1","attributes":{"name":"Checking","type":"asset"}}}`)
+			return
+		}
+		require.Equal(t, http.MethodPost, r.Method)
+		body, _ := io.ReadAll(r.Body)
+		path := r.URL.Path
+		if strings.HasSuffix(path, "/limits") {
+			path = "/v1/budgets/{id}/limits"
+		}
+		posts[path]++
+		id := posts[path]
+
+		if path == "/v1/transactions" {
+			var stored TransactionStoreRequest
+			require.NoError(t, json.Unmarshal(body, &stored))
+			assert.Equal(t, []string{demoTag}, stored.Transactions[0].Tags)
+			split, _ := json.Marshal(stored.Transactions[0])
+			fmt.Fprintf(w, `{"data":{"type":"transactions","id":"%d","attributes":{"transactions":[%s]}}}`, id, split)
+			return
+		}
+		if path == "/v1/bills" && id == 2 {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, `{"message":"The name has already been taken."}`)
+			return
+		}
+		fmt.Fprintf(w, `{"data":{"type":"objects","id":"%d","attributes":{}}}`, id)
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	config.Demo.Enabled = true
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+	require.Contains(t, server.tools, "generate_demo_data")
+
+	args := GenerateDemoDataArgs{Seed: 3, Months: 2, End: "2024-02-29"}
+	result, _, err := server.handleGenerateDemoData(context.Background(), nil, args)
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content[0].(*mcp.TextContent).Text)
+
+	var generated DemoDataResult
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &generated))
+	plan := buildDemoPlan(3, 2, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, "2024-01-01", generated.Start)
+	assert.Equal(t, 2, generated.Accounts)
+	assert.Equal(t, 4, generated.Budgets)
+	assert.Equal(t, 8, generated.BudgetLimits)
+	assert.Equal(t, 3, generated.Bills)
+	assert.Equal(t, len(plan.Transactions), generated.Transactions)
+	assert.Contains(t, generated.Categories, "Groceries")
+	require.Len(t, generated.Errors, 1)
+	assert.Contains(t, generated.Errors[0], "bill Internet")
+
+	existing = 5
+	result, _, err = server.handleGenerateDemoData(context.Background(), nil, args)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "force")
+
+	result, _, err = server.handleGenerateDemoData(context.Background(), nil, GenerateDemoDataArgs{Months: 25})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestGenerateDemoData_DisabledByDefault(t *testing.T) {
+	server, err := NewFireflyMCPServer(newPluginTestConfig())
+	require.NoError(t, err)
+	assert.NotContains(t, server.tools, "generate_demo_data")
+}
@@ -0,0 +1,280 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// snapshotResourcePrefix is the URI prefix of transaction snapshot resources
+const snapshotResourcePrefix = "firefly://snapshots/"
+
+// maxTrackedSnapshots is the number of snapshots kept in memory; older ones are forgotten
+const maxTrackedSnapshots = 20
+
+// DiffSource selects the transactions of one side of a diff: either a snapshot
+// returned by an earlier diff_periods call, or filters for a fresh listing
+type DiffSource struct {
+	URI  string `json:"uri,omitempty" jsonschema:"Snapshot URI (firefly://snapshots/{id}) returned by an earlier diff_periods call"`
+	Type string `json:"type,omitempty" jsonschema:"Filter by transaction type, used when uri is not set"`
+	DateRange
+}
+
+// DiffPeriodsArgs represents the arguments for the diff_periods tool
+type DiffPeriodsArgs struct {
+	Before DiffSource `json:"before" jsonschema:"Transactions before the change (snapshot URI or filters)"`
+	After  DiffSource `json:"after" jsonschema:"Transactions after the change (snapshot URI or filters)"`
+}
+
+// TransactionSnapshot is the content of a snapshot resource
+type TransactionSnapshot struct {
+	ID           string        `json:"id"`
+	URI          string        `json:"uri"`
+	Filters      DiffSource    `json:"filters"`
+	CreatedAt    string        `json:"created_at"`
+	Truncated    bool          `json:"truncated"`
+	Transactions []Transaction `json:"transactions"`
+}
+
+// FieldChange is the before and after value of a changed transaction field
+type FieldChange struct {
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// TransactionChange lists the changed fields of a transaction present on both sides
+type TransactionChange struct {
+	Id          string                 `json:"id"`
+	Description string                 `json:"description"`
+	Changes     map[string]FieldChange `json:"changes"`
+}
+
+// TransactionDiff is the result of diff_periods
+type TransactionDiff struct {
+	BeforeURI   string              `json:"before_uri"`
+	AfterURI    string              `json:"after_uri"`
+	BeforeCount int                 `json:"before_count"`
+	AfterCount  int                 `json:"after_count"`
+	Added       []Transaction       `json:"added"`
+	Removed     []Transaction       `json:"removed"`
+	Changed     []TransactionChange `json:"changed"`
+	Unchanged   int                 `json:"unchanged"`
+	Truncated   bool                `json:"truncated"`
+}
+
+// snapshotStore keeps recent transaction snapshots in memory
+type snapshotStore struct {
+	mu        sync.Mutex
+	snapshots map[string]*TransactionSnapshot
+	order     []string // Snapshot IDs, oldest first
+}
+
+func newSnapshotStore() *snapshotStore {
+	return &snapshotStore{snapshots: make(map[string]*TransactionSnapshot)}
+}
+
+// save stores a new snapshot and forgets the oldest ones beyond maxTrackedSnapshots
+func (st *snapshotStore) save(filters DiffSource, transactions []Transaction, truncated bool) (*TransactionSnapshot, error) {
+	id, err := newResourceID()
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &TransactionSnapshot{
+		ID:           id,
+		URI:          snapshotResourcePrefix + id,
+		Filters:      filters,
+		CreatedAt:    time.Now().UTC().Format(time.RFC3339),
+		Truncated:    truncated,
+		Transactions: transactions,
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.snapshots[id] = snapshot
+	st.order = append(st.order, id)
+	for len(st.order) > maxTrackedSnapshots {
+		delete(st.snapshots, st.order[0])
+		st.order = st.order[1:]
+	}
+	return snapshot, nil
+}
+
+// get returns the snapshot with the given URI. Snapshots are never modified
+// after they are saved, so the stored pointer can be shared.
+func (st *snapshotStore) get(uri string) (*TransactionSnapshot, bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	snapshot, ok := st.snapshots[strings.TrimPrefix(uri, snapshotResourcePrefix)]
+	return snapshot, ok
+}
+
+// registerSnapshotResources registers the transaction snapshot resource template
+func (s *FireflyMCPServer) registerSnapshotResources() {
+	s.server.AddResourceTemplate(
+		&mcp.ResourceTemplate{
+			Name:        "transaction_snapshot",
+			Title:       "Transaction snapshot",
+			Description: "Transactions captured by diff_periods, to compare against later",
+			URITemplate: snapshotResourcePrefix + "{id}",
+			MIMEType:    "application/json",
+		}, s.handleSnapshotResource,
+	)
+}
+
+// handleSnapshotResource returns a stored transaction snapshot
+func (s *FireflyMCPServer) handleSnapshotResource(
+	ctx context.Context,
+	req *mcp.ReadResourceRequest,
+) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	snapshot, ok := s.snapshots.get(uri)
+	if !ok {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{URI: uri, MIMEType: "application/json", Text: string(data)},
+		},
+	}, nil
+}
+
+// handleDiffPeriods compares two sets of transactions by transaction ID. Sides
+// given as filters are fetched and saved as snapshots, so a snapshot taken
+// before a bulk operation can be compared against a fresh listing afterwards.
+func (s *FireflyMCPServer) handleDiffPeriods(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args DiffPeriodsArgs,
+) (*mcp.CallToolResult, any, error) {
+	before, err := s.resolveDiffSource(ctx, req, args.Before)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error: before: %v", err))
+	}
+	after, err := s.resolveDiffSource(ctx, req, args.After)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error: after: %v", err))
+	}
+
+	return newSuccessResult(diffTransactions(before, after))
+}
+
+// resolveDiffSource loads a snapshot by URI, or fetches the transactions
+// matching the filters and saves them as a new snapshot
+func (s *FireflyMCPServer) resolveDiffSource(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	source DiffSource,
+) (*TransactionSnapshot, error) {
+	if source.URI != "" {
+		snapshot, ok := s.snapshots.get(source.URI)
+		if !ok {
+			return nil, fmt.Errorf("snapshot %s not found (snapshots are kept in memory and lost on restart)", source.URI)
+		}
+		return snapshot, nil
+	}
+
+	if (source.Start == "" || source.End == "") && source.Period == "" {
+		return nil, fmt.Errorf("either uri or start and end are required")
+	}
+	dates, err := s.resolveDateRange(source.DateRange, dateRangeRequired)
+	if err != nil {
+		return nil, err
+	}
+	// Snapshots record the resolved dates, so a later diff compares the same period
+	source.DateRange = DateRange{Start: dates.StartString(), End: dates.EndString()}
+
+	transactions, truncated, err := s.fetchTransactions(ctx, req, ListTransactionsArgs{
+		Type:      source.Type,
+		DateRange: source.DateRange,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.snapshots.save(source, transactions, truncated)
+}
+
+// diffTransactions compares two snapshots by transaction (journal) ID
+func diffTransactions(before, after *TransactionSnapshot) *TransactionDiff {
+	diff := &TransactionDiff{
+		BeforeURI:   before.URI,
+		AfterURI:    after.URI,
+		BeforeCount: len(before.Transactions),
+		AfterCount:  len(after.Transactions),
+		Added:       []Transaction{},
+		Removed:     []Transaction{},
+		Changed:     []TransactionChange{},
+		Truncated:   before.Truncated || after.Truncated,
+	}
+
+	beforeByID := make(map[string]Transaction, len(before.Transactions))
+	for _, transaction := range before.Transactions {
+		beforeByID[transaction.Id] = transaction
+	}
+
+	seen := make(map[string]bool, len(after.Transactions))
+	for _, transaction := range after.Transactions {
+		seen[transaction.Id] = true
+		previous, ok := beforeByID[transaction.Id]
+		if !ok {
+			diff.Added = append(diff.Added, transaction)
+			continue
+		}
+		if changes := compareTransactions(previous, transaction); len(changes) > 0 {
+			diff.Changed = append(diff.Changed, TransactionChange{
+				Id:          transaction.Id,
+				Description: transaction.Description,
+				Changes:     changes,
+			})
+		} else {
+			diff.Unchanged++
+		}
+	}
+	for _, transaction := range before.Transactions {
+		if !seen[transaction.Id] {
+			diff.Removed = append(diff.Removed, transaction)
+		}
+	}
+	return diff
+}
+
+// compareTransactions returns the fields that differ between two versions of a transaction
+func compareTransactions(before, after Transaction) map[string]FieldChange {
+	fields := map[string][2]string{
+		"amount":           {before.Amount, after.Amount},
+		"date":             {before.Date.Format("2006-01-02"), after.Date.Format("2006-01-02")},
+		"description":      {before.Description, after.Description},
+		"type":             {before.Type, after.Type},
+		"category_name":    {getStringValue(before.CategoryName), getStringValue(after.CategoryName)},
+		"budget_name":      {getStringValue(before.BudgetName), getStringValue(after.BudgetName)},
+		"bill_name":        {getStringValue(before.BillName), getStringValue(after.BillName)},
+		"source_name":      {before.SourceName, after.SourceName},
+		"destination_name": {before.DestinationName, after.DestinationName},
+		"tags":             {joinSortedTags(before.Tags), joinSortedTags(after.Tags)},
+	}
+
+	changes := make(map[string]FieldChange)
+	for name, values := range fields {
+		if values[0] != values[1] {
+			changes[name] = FieldChange{Before: values[0], After: values[1]}
+		}
+	}
+	return changes
+}
+
+// joinSortedTags joins tags in a stable order, so reordering is not reported as a change
+func joinSortedTags(tags []string) string {
+	sorted := append([]string(nil), tags...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ", ")
+}
@@ -0,0 +1,124 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffTransactions(t *testing.T) {
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	before := &TransactionSnapshot{URI: "firefly://snapshots/a", Transactions: []Transaction{
+		{Id: "1", Description: "Coffee", Amount: "3.50", Date: date, CategoryName: strPtr("Food")},
+		{Id: "2", Description: "Rent", Amount: "900.00", Date: date, Tags: []string{"home", "fixed"}},
+		{Id: "3", Description: "Deleted", Amount: "1.00", Date: date},
+	}}
+	after := &TransactionSnapshot{URI: "firefly://snapshots/b", Truncated: true, Transactions: []Transaction{
+		{Id: "1", Description: "Coffee", Amount: "3.50", Date: date, CategoryName: strPtr("Eating out")},
+		{Id: "2", Description: "Rent", Amount: "900.00", Date: date, Tags: []string{"fixed", "home"}},
+		{Id: "4", Description: "New", Amount: "5.00", Date: date},
+	}}
+
+	diff := diffTransactions(before, after)
+
+	assert.Equal(t, 3, diff.BeforeCount)
+	assert.Equal(t, 3, diff.AfterCount)
+	assert.True(t, diff.Truncated)
+	require.Len(t, diff.Added, 1)
+	assert.Equal(t, "4", diff.Added[0].Id)
+	require.Len(t, diff.Removed, 1)
+	assert.Equal(t, "3", diff.Removed[0].Id)
+	require.Len(t, diff.Changed, 1)
+	assert.Equal(t, map[string]FieldChange{
+		"category_name": {Before: "Food", After: "Eating out"},
+	}, diff.Changed[0].Changes)
+	assert.Equal(t, 1, diff.Unchanged, "reordered tags are not a change")
+}
+
+func TestSnapshotStore(t *testing.T) {
+	store := newSnapshotStore()
+
+	first, err := store.save(DiffSource{DateRange: DateRange{Start: "2024-01-01"}}, nil, false)
+	require.NoError(t, err)
+	got, ok := store.get(first.URI)
+	require.True(t, ok)
+	assert.Equal(t, "2024-01-01", got.Filters.Start)
+
+	for i := 0; i < maxTrackedSnapshots; i++ {
+		_, err := store.save(DiffSource{}, nil, false)
+		require.NoError(t, err)
+	}
+	_, ok = store.get(first.URI)
+	assert.False(t, ok, "oldest snapshot should be forgotten")
+	assert.Len(t, store.snapshots, maxTrackedSnapshots)
+}
+
+func TestDiffPeriods_SnapshotThenCompare(t *testing.T) {
+	category := "Groceries"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		w.Write([]byte(`{"data":[{"type":"transactions","id":"10","attributes":{"transactions":[` +
+			`{"transaction_journal_id":"11","type":"withdrawal","date":"2024-01-15T00:00:00Z","amount":"12.00",` +
+			`"description":"Market","source_id":"1","destination_id":"2","category_name":"` + category + `"}]}}],` +
+			`"meta":{"pagination":{"total":1,"count":1,"per_page":200,"current_page":1,"total_pages":1}}}`))
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	filters := DiffSource{DateRange: DateRange{Start: "2024-01-01", End: "2024-01-31"}}
+	diff := callDiffPeriods(t, server, DiffPeriodsArgs{Before: filters, After: filters})
+	assert.Empty(t, diff.Changed)
+	assert.Equal(t, 1, diff.Unchanged)
+
+	// A bulk operation recategorizes the transaction
+	category = "Food"
+	diff = callDiffPeriods(t, server, DiffPeriodsArgs{Before: DiffSource{URI: diff.BeforeURI}, After: filters})
+	require.Len(t, diff.Changed, 1)
+	assert.Equal(t, "11", diff.Changed[0].Id)
+	assert.Equal(t, FieldChange{Before: "Groceries", After: "Food"}, diff.Changed[0].Changes["category_name"])
+
+	resource, err := server.handleSnapshotResource(context.Background(), &mcp.ReadResourceRequest{
+		Params: &mcp.ReadResourceParams{URI: diff.AfterURI},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, resource.Contents[0].Text, `"category_name": "Food"`)
+}
+
+func TestDiffPeriods_Errors(t *testing.T) {
+	server := &FireflyMCPServer{snapshots: newSnapshotStore()}
+
+	result, _, err := server.handleDiffPeriods(context.Background(), nil, DiffPeriodsArgs{
+		Before: DiffSource{URI: "firefly://snapshots/missing"},
+	})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "before: snapshot firefly://snapshots/missing not found")
+
+	result, _, err = server.handleDiffPeriods(context.Background(), nil, DiffPeriodsArgs{
+		Before: DiffSource{DateRange: DateRange{Start: "2024-01-01"}},
+	})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "either uri or start and end are required")
+}
+
+func callDiffPeriods(t *testing.T, server *FireflyMCPServer, args DiffPeriodsArgs) *TransactionDiff {
+	result, _, err := server.handleDiffPeriods(context.Background(), nil, args)
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content[0].(*mcp.TextContent).Text)
+
+	var diff TransactionDiff
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &diff))
+	return &diff
+}
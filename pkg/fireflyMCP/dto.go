@@ -9,27 +9,29 @@ type Pagination struct {
 	PerPage     int `json:"per_page"`
 	TotalPages  int `json:"total_pages"`
 }
-type Spent struct {
-	Sum          string `json:"sum"`
-	CurrencyCode string `json:"currency_code"`
-}
-
 type Budget struct {
-	Id     string  `json:"id"`
-	Active bool    `json:"active"`
-	Name   string  `json:"name"`
-	Notes  *string `json:"notes"`
-	Spent  Spent   `json:"spent"`
+	Id                     string        `json:"id"`
+	WebURL                 string        `json:"web_url,omitempty" jsonschema:"With formatting.web_links: page of the entity in the Firefly III web UI"`
+	Active                 bool          `json:"active"`
+	Name                   string        `json:"name"`
+	Notes                  *string       `json:"notes"`
+	Spent                  []BudgetSpent `json:"spent"` // One entry per currency
+	AutoBudgetType         *string       `json:"auto_budget_type,omitempty"`
+	AutoBudgetAmount       *string       `json:"auto_budget_amount,omitempty"`
+	AutoBudgetPeriod       *string       `json:"auto_budget_period,omitempty"`
+	AutoBudgetCurrencyCode *string       `json:"auto_budget_currency_code,omitempty"`
 }
 type BudgetList struct {
-	Data       []Budget   `json:"data"`
-	Pagination Pagination `json:"pagination"`
+	Data        []Budget      `json:"data"`
+	SpentTotals []BudgetSpent `json:"spent_totals"` // Spent of the listed budgets, summed per currency
+	Pagination  Pagination    `json:"pagination"`
 }
 
 type Category struct {
-	Id    string  `json:"id"`
-	Name  string  `json:"name"`
-	Notes *string `json:"notes"`
+	Id     string  `json:"id"`
+	WebURL string  `json:"web_url,omitempty" jsonschema:"With formatting.web_links: page of the entity in the Firefly III web UI"`
+	Name   string  `json:"name"`
+	Notes  *string `json:"notes"`
 }
 
 type CategoryList struct {
@@ -38,11 +40,16 @@ type CategoryList struct {
 }
 
 type Account struct {
-	Id     string  `json:"id"`
-	Active bool    `json:"active"`
-	Name   string  `json:"name"`
-	Notes  *string `json:"notes"`
-	Type   string  `json:"type"`
+	Id                 string     `json:"id"`
+	WebURL             string     `json:"web_url,omitempty" jsonschema:"With formatting.web_links: page of the entity in the Firefly III web UI"`
+	Active             bool       `json:"active"`
+	Name               string     `json:"name"`
+	Notes              *string    `json:"notes"`
+	Type               string     `json:"type"`
+	OpeningBalance     *string    `json:"opening_balance,omitempty"`
+	OpeningBalanceDate *time.Time `json:"opening_balance_date,omitempty"`
+	Interest           *string    `json:"interest,omitempty"`        // Interest percentage
+	InterestPeriod     *string    `json:"interest_period,omitempty"` // daily, monthly, yearly...
 }
 
 type AccountList struct {
@@ -51,7 +58,9 @@ type AccountList struct {
 }
 
 type Transaction struct {
-	Id              string    `json:"id"`
+	Id              string    `json:"id"`         // Journal ID of the split, kept for compatibility
+	JournalId       string    `json:"journal_id"` // Identifies the split in update_transaction
+	Order           int       `json:"order"`      // Position of the split in its group, starting at 0
 	Amount          string    `json:"amount"`
 	BillId          *string   `json:"bill_id"`
 	BillName        *string   `json:"bill_name"`
@@ -71,12 +80,25 @@ type Transaction struct {
 	SourceName      string    `json:"source_name"`
 	Tags            []string  `json:"tags"`
 	Type            string    `json:"type"`
+	Direction       string    `json:"direction,omitempty" jsonschema:"With formatting.amount_direction: out for withdrawals, in for deposits; amount is then always absolute"`
+
+	// Import and recurrence metadata, left out when Firefly III has none
+	ExternalId        *string `json:"external_id,omitempty"`
+	InternalReference *string `json:"internal_reference,omitempty"`
+	OriginalSource    *string `json:"original_source,omitempty"` // Tool and version that created the split, e.g. ff3-v6.1.0 or the data importer
+	ImportHashV2      *string `json:"import_hash_v2,omitempty"`  // Hash Firefly III uses to detect duplicate imports
+	RecurrenceId      *string `json:"recurrence_id,omitempty"`   // Recurrence that created the split
+	RecurrenceCount   *int    `json:"recurrence_count,omitempty"`
+	RecurrenceTotal   *int    `json:"recurrence_total,omitempty"` // 0 for recurrences without end
 }
 
 type TransactionGroup struct {
 	Id           string        `json:"id"`
+	WebURL       string        `json:"web_url,omitempty" jsonschema:"With formatting.web_links: page of the entity in the Firefly III web UI"`
 	GroupTitle   string        `json:"group_title"`
 	Transactions []Transaction `json:"transactions"`
+	CreatedAt    *time.Time    `json:"created_at,omitempty"`
+	UpdatedAt    *time.Time    `json:"updated_at,omitempty"`
 }
 
 type TransactionList struct {
@@ -84,15 +106,66 @@ type TransactionList struct {
 	Pagination Pagination         `json:"pagination"`
 }
 
+// CompactTransaction is a split without empty fields, returned when compact is set.
+// Dates without a time of day are shortened to YYYY-MM-DD.
+type CompactTransaction struct {
+	JournalId       string   `json:"journal_id,omitempty"`
+	Order           int      `json:"order,omitempty"`
+	Type            string   `json:"type,omitempty"`
+	Date            string   `json:"date,omitempty"`
+	Amount          string   `json:"amount,omitempty"`
+	CurrencyCode    string   `json:"currency_code,omitempty"`
+	Description     string   `json:"description,omitempty"`
+	SourceId        string   `json:"source_id,omitempty"`
+	SourceName      string   `json:"source_name,omitempty"`
+	DestinationId   string   `json:"destination_id,omitempty"`
+	DestinationName string   `json:"destination_name,omitempty"`
+	DestinationType string   `json:"destination_type,omitempty"`
+	CategoryName    string   `json:"category_name,omitempty"`
+	BudgetName      string   `json:"budget_name,omitempty"`
+	BillName        string   `json:"bill_name,omitempty"`
+	Tags            []string `json:"tags,omitempty"`
+	Notes           string   `json:"notes,omitempty"`
+	Reconciled      bool     `json:"reconciled,omitempty"`
+	RecurrenceId    string   `json:"recurrence_id,omitempty"`
+	Direction       string   `json:"direction,omitempty" jsonschema:"With formatting.amount_direction: out for withdrawals, in for deposits; amount is then always absolute"`
+}
+
+// CompactTransactionGroup is a transaction group in compact form. The split of a
+// single-split group is flattened into the group; groups with several splits
+// list them in transactions.
+type CompactTransactionGroup struct {
+	Id         string `json:"id"`
+	WebURL     string `json:"web_url,omitempty" jsonschema:"With formatting.web_links: page of the entity in the Firefly III web UI"`
+	GroupTitle string `json:"group_title,omitempty"`
+	CreatedAt  string `json:"created_at,omitempty"`
+	*CompactTransaction
+	Transactions []CompactTransaction `json:"transactions,omitempty"`
+}
+
+type CompactTransactionList struct {
+	Data       []CompactTransactionGroup `json:"data"`
+	Pagination Pagination                `json:"pagination"`
+}
+
 type BasicSummary struct {
 	Key           string `json:"key"`
 	Title         string `json:"title"`
 	CurrencyCode  string `json:"currency_code"`
 	MonetaryValue string `json:"monetary_value"`
+	Direction     string `json:"direction,omitempty" jsonschema:"With formatting.amount_direction: out for spent, in for earned; monetary_value is then absolute"`
 }
 
 type BasicSummaryList struct {
-	Data []BasicSummary `json:"data"`
+	Data      []BasicSummary `json:"data"`
+	Trend     []DailyNet     `json:"trend,omitempty"`      // Net change per day, with with_trend
+	TrendNote string         `json:"trend_note,omitempty"` // Why the trend was left out
+}
+
+// DailyNet is the income minus expenses of one day per currency
+type DailyNet struct {
+	Date string              `json:"date"`
+	Net  []InsightTotalEntry `json:"net"`
 }
 
 type InsightCategoryEntry struct {
@@ -100,15 +173,20 @@ type InsightCategoryEntry struct {
 	Name         string `json:"name"`
 	Amount       string `json:"amount"`
 	CurrencyCode string `json:"currency_code"`
+	Share        string `json:"share,omitempty"` // Percent of the total in the same currency
+	Direction    string `json:"direction,omitempty" jsonschema:"With formatting.amount_direction: out for negative, in for positive amounts; amount is then absolute"`
 }
 
 type InsightTotalEntry struct {
 	Amount       string `json:"amount"`
 	CurrencyCode string `json:"currency_code"`
+	Share        string `json:"share,omitempty"` // Percent of the total in the same currency, where applicable
+	Direction    string `json:"direction,omitempty" jsonschema:"With formatting.amount_direction: out for negative, in for positive amounts; amount is then absolute"`
 }
 
 type InsightCategoryResponse struct {
 	Entries []InsightCategoryEntry `json:"entries"`
+	Totals  []InsightTotalEntry    `json:"totals,omitempty"` // Sum of all entries per currency
 }
 
 type InsightTotalResponse struct {
@@ -119,6 +197,7 @@ type BudgetSpent struct {
 	Sum            string `json:"sum"`
 	CurrencyCode   string `json:"currency_code"`
 	CurrencySymbol string `json:"currency_symbol"`
+	Direction      string `json:"direction,omitempty" jsonschema:"With formatting.amount_direction: out; sum is then absolute"`
 }
 
 type BudgetLimit struct {
@@ -129,16 +208,22 @@ type BudgetLimit struct {
 	BudgetId       string        `json:"budget_id"`
 	CurrencyCode   string        `json:"currency_code"`
 	CurrencySymbol string        `json:"currency_symbol"`
+	Notes          *string       `json:"notes,omitempty"`
 	Spent          []BudgetSpent `json:"spent"`
+	Remaining      string        `json:"remaining,omitempty"`       // Amount minus spent, negative when overspent
+	DaysRemaining  *int          `json:"days_remaining,omitempty"`  // Days left in the period including today, all of them before it starts
+	DailyAllowance string        `json:"daily_allowance,omitempty"` // Remaining per remaining day, 0.00 when overspent; empty after the period
 }
 
 type BudgetLimitList struct {
 	Data       []BudgetLimit `json:"data"`
+	AsOf       string        `json:"as_of,omitempty"` // Day the remaining days are counted from
 	Pagination Pagination    `json:"pagination"`
 }
 
 type Tag struct {
 	Id          string  `json:"id"`
+	WebURL      string  `json:"web_url,omitempty" jsonschema:"With formatting.web_links: page of the entity in the Firefly III web UI"`
 	Tag         string  `json:"tag"`
 	Description *string `json:"description"`
 }
@@ -156,6 +241,7 @@ type PaidDate struct {
 
 type Bill struct {
 	Id                string     `json:"id"`
+	WebURL            string     `json:"web_url,omitempty" jsonschema:"With formatting.web_links: page of the entity in the Firefly III web UI"`
 	Active            bool       `json:"active"`
 	Name              string     `json:"name"`
 	AmountMin         string     `json:"amount_min"`
@@ -200,6 +286,7 @@ type RecurrenceTransaction struct {
 
 type Recurrence struct {
 	Id              string                  `json:"id"`
+	WebURL          string                  `json:"web_url,omitempty" jsonschema:"With formatting.web_links: page of the entity in the Firefly III web UI"`
 	Type            string                  `json:"type"`
 	Title           string                  `json:"title"`
 	Description     string                  `json:"description"`
@@ -221,40 +308,43 @@ type RecurrenceList struct {
 
 // TransactionStoreRequest represents the request body for creating a new transaction
 type TransactionStoreRequest struct {
-	ErrorIfDuplicateHash bool                      `json:"error_if_duplicate_hash,omitempty" jsonschema:"Break if transaction with same hash already exists (default: false)"` // Break if transaction already exists
-	ApplyRules           bool                      `json:"apply_rules,omitempty" jsonschema:"Whether to apply processing rules when creating transaction (default: false)"`    // Whether to apply rules when submitting
-	FireWebhooks         bool                      `json:"fire_webhooks,omitempty" jsonschema:"Whether to fire webhooks for this transaction (default: true)"`                 // Whether to fire webhooks (default: true)
-	GroupTitle           string                    `json:"group_title,omitempty" jsonschema:"Title for the transaction group (for split transactions)"`                        // Title for split transactions
-	Transactions         []TransactionSplitRequest `json:"transactions" jsonschema:"Array of transactions to create (required, at least one)"`                                 // Array of transactions (required)
+	ErrorIfDuplicateHash   bool                      `json:"error_if_duplicate_hash,omitempty" jsonschema:"Break if transaction with same hash already exists (default: false)"`                                                                                    // Break if transaction already exists
+	ApplyRules             bool                      `json:"apply_rules,omitempty" jsonschema:"Whether to apply processing rules when creating transaction (default: false)"`                                                                                       // Whether to apply rules when submitting
+	FireWebhooks           bool                      `json:"fire_webhooks,omitempty" jsonschema:"Whether to fire webhooks for this transaction (default: true)"`                                                                                                    // Whether to fire webhooks (default: true)
+	GroupTitle             string                    `json:"group_title,omitempty" jsonschema:"Title for the transaction group (for split transactions)"`                                                                                                           // Title for split transactions
+	Member                 string                    `json:"member,omitempty" jsonschema:"Household member; adds a member tag (e.g. member:alice) to every split"`                                                                                                  // Household member, stored as a tag
+	AllowAccountAutocreate *bool                     `json:"allow_account_autocreate,omitempty" jsonschema:"Let Firefly III create expense/revenue accounts from unknown destination/source names; false fails with suggestions instead (default: server setting)"` // Overrides accounts.allow_autocreate
+	Transactions           []TransactionSplitRequest `json:"transactions" jsonschema:"Array of transactions to create (required, at least one)"`                                                                                                                    // Array of transactions (required)
 }
 
 // TransactionSplitRequest represents a single transaction in a transaction group
 type TransactionSplitRequest struct {
-	Type                string   `json:"type" jsonschema:"Transaction type: withdrawal, deposit, transfer (required)"`                                     // Transaction type: withdrawal, deposit, transfer (required)
-	Date                string   `json:"date" jsonschema:"Transaction date in RFC3339 format, e.g. 2024-01-15T00:00:00Z (required)"`                       // Transaction date in RFC3339 format (required)
-	Amount              string   `json:"amount" jsonschema:"Transaction amount as string (e.g. '100.00') (required)"`                                      // Transaction amount (required)
-	Description         string   `json:"description" jsonschema:"Transaction description (required)"`                                                      // Transaction description (required)
-	SourceId            *string  `json:"source_id,omitempty" jsonschema:"Source account ID (use either source_id or source_name)"`                         // Source account ID
-	SourceName          *string  `json:"source_name,omitempty" jsonschema:"Source account name (use either source_id or source_name)"`                     // Source account name
-	DestinationId       *string  `json:"destination_id,omitempty" jsonschema:"Destination account ID (use either destination_id or destination_name)"`     // Destination account ID
-	DestinationName     *string  `json:"destination_name,omitempty" jsonschema:"Destination account name (use either destination_id or destination_name)"` // Destination account name
-	CategoryId          *string  `json:"category_id,omitempty" jsonschema:"Category ID (use either category_id or category_name)"`                         // Category ID
-	CategoryName        *string  `json:"category_name,omitempty" jsonschema:"Category name (use either category_id or category_name)"`                     // Category name
-	BudgetId            *string  `json:"budget_id,omitempty" jsonschema:"Budget ID (use either budget_id or budget_name)"`                                 // Budget ID
-	BudgetName          *string  `json:"budget_name,omitempty" jsonschema:"Budget name (use either budget_id or budget_name)"`                             // Budget name
-	Tags                []string `json:"tags,omitempty" jsonschema:"Array of tag names to attach to transaction"`                                          // Transaction tags
-	CurrencyId          *string  `json:"currency_id,omitempty" jsonschema:"Currency ID for the transaction"`                                               // Currency ID
-	CurrencyCode        *string  `json:"currency_code,omitempty" jsonschema:"Currency code (e.g. 'USD', 'EUR')"`                                           // Currency code
-	ForeignAmount       *string  `json:"foreign_amount,omitempty" jsonschema:"Amount in foreign currency as string"`                                       // Amount in foreign currency
-	ForeignCurrencyId   *string  `json:"foreign_currency_id,omitempty" jsonschema:"Foreign currency ID"`                                                   // Foreign currency ID
-	ForeignCurrencyCode *string  `json:"foreign_currency_code,omitempty" jsonschema:"Foreign currency code (e.g. 'USD', 'EUR')"`                           // Foreign currency code
-	BillId              *string  `json:"bill_id,omitempty" jsonschema:"Bill ID to link this transaction to"`                                               // Bill ID
-	BillName            *string  `json:"bill_name,omitempty" jsonschema:"Bill name to link this transaction to"`                                           // Bill name
-	PiggyBankId         *string  `json:"piggy_bank_id,omitempty" jsonschema:"Piggy bank ID for savings transfers"`                                         // Piggy bank ID
-	PiggyBankName       *string  `json:"piggy_bank_name,omitempty" jsonschema:"Piggy bank name for savings transfers"`                                     // Piggy bank name
-	Notes               *string  `json:"notes,omitempty" jsonschema:"Additional notes or comments for the transaction"`                                    // Transaction notes
-	Reconciled          *bool    `json:"reconciled,omitempty" jsonschema:"Whether the transaction has been reconciled (default: false)"`                   // Whether transaction is reconciled
-	Order               *int     `json:"order,omitempty" jsonschema:"Order of this split in the transaction group"`                                        // Order in the list
+	Type                string   `json:"type" jsonschema:"Transaction type: withdrawal, deposit, transfer (required)"`                                             // Transaction type: withdrawal, deposit, transfer (required)
+	Date                string   `json:"date" jsonschema:"Transaction date (YYYY-MM-DD, RFC3339, or today/yesterday/tomorrow) (required)"`                         // Transaction date (required)
+	Amount              string   `json:"amount" jsonschema:"Transaction amount as string (e.g. '100.00') (required)"`                                              // Transaction amount (required)
+	Description         string   `json:"description" jsonschema:"Transaction description (required)"`                                                              // Transaction description (required)
+	SourceId            *string  `json:"source_id,omitempty" jsonschema:"Source account ID (use either source_id or source_name)"`                                 // Source account ID
+	SourceName          *string  `json:"source_name,omitempty" jsonschema:"Source account name (use either source_id or source_name)"`                             // Source account name
+	DestinationId       *string  `json:"destination_id,omitempty" jsonschema:"Destination account ID (use either destination_id or destination_name)"`             // Destination account ID
+	DestinationName     *string  `json:"destination_name,omitempty" jsonschema:"Destination account name (use either destination_id or destination_name)"`         // Destination account name
+	CategoryId          *string  `json:"category_id,omitempty" jsonschema:"Category ID (use either category_id or category_name)"`                                 // Category ID
+	CategoryName        *string  `json:"category_name,omitempty" jsonschema:"Category name (use either category_id or category_name)"`                             // Category name
+	BudgetId            *string  `json:"budget_id,omitempty" jsonschema:"Budget ID (use either budget_id or budget_name)"`                                         // Budget ID
+	BudgetName          *string  `json:"budget_name,omitempty" jsonschema:"Budget name (use either budget_id or budget_name)"`                                     // Budget name
+	Tags                []string `json:"tags,omitempty" jsonschema:"Array of tag names to attach to transaction"`                                                  // Transaction tags
+	CurrencyId          *string  `json:"currency_id,omitempty" jsonschema:"Currency ID for the transaction"`                                                       // Currency ID
+	CurrencyCode        *string  `json:"currency_code,omitempty" jsonschema:"Currency code (e.g. 'USD', 'EUR')"`                                                   // Currency code
+	ForeignAmount       *string  `json:"foreign_amount,omitempty" jsonschema:"Amount in foreign currency as string"`                                               // Amount in foreign currency
+	ForeignCurrencyId   *string  `json:"foreign_currency_id,omitempty" jsonschema:"Foreign currency ID"`                                                           // Foreign currency ID
+	ForeignCurrencyCode *string  `json:"foreign_currency_code,omitempty" jsonschema:"Foreign currency code (e.g. 'USD', 'EUR')"`                                   // Foreign currency code
+	BillId              *string  `json:"bill_id,omitempty" jsonschema:"Bill ID to link this transaction to"`                                                       // Bill ID
+	BillName            *string  `json:"bill_name,omitempty" jsonschema:"Bill name to link this transaction to"`                                                   // Bill name
+	PiggyBankId         *string  `json:"piggy_bank_id,omitempty" jsonschema:"Piggy bank ID for savings transfers"`                                                 // Piggy bank ID
+	PiggyBankName       *string  `json:"piggy_bank_name,omitempty" jsonschema:"Piggy bank name for savings transfers"`                                             // Piggy bank name
+	Notes               *string  `json:"notes,omitempty" jsonschema:"Additional notes or comments for the transaction"`                                            // Transaction notes
+	Reconciled          *bool    `json:"reconciled,omitempty" jsonschema:"Whether the transaction has been reconciled (default: false)"`                           // Whether transaction is reconciled
+	Order               *int     `json:"order,omitempty" jsonschema:"Order of this split in the transaction group"`                                                // Order in the list
+	JournalId           *string  `json:"journal_id,omitempty" jsonschema:"Journal ID of the split to change (update_transaction only, see journal_id in results)"` // Split to update
 }
 
 // TransactionUpdateRequest represents the request body for updating an existing transaction
@@ -268,6 +358,7 @@ type TransactionUpdateRequest struct {
 // RuleGroup represents a simplified rule group for MCP responses
 type RuleGroup struct {
 	Id          string  `json:"id"`
+	WebURL      string  `json:"web_url,omitempty" jsonschema:"With formatting.web_links: page of the entity in the Firefly III web UI"`
 	Title       string  `json:"title"`
 	Description *string `json:"description"`
 	Order       int     `json:"order"`
@@ -304,6 +395,7 @@ type RuleAction struct {
 // Rule represents a simplified rule for MCP responses
 type Rule struct {
 	Id             string        `json:"id"`
+	WebURL         string        `json:"web_url,omitempty" jsonschema:"With formatting.web_links: page of the entity in the Firefly III web UI"`
 	Title          string        `json:"title"`
 	Description    *string       `json:"description"`
 	RuleGroupId    string        `json:"rule_group_id"`
@@ -367,6 +459,29 @@ type RuleUpdateRequest struct {
 	Actions        []RuleActionRequest  `json:"actions,omitempty" jsonschema:"Array of actions to perform"`
 }
 
+// BudgetStoreRequest represents the request body for creating a budget
+type BudgetStoreRequest struct {
+	Name                   string  `json:"name" jsonschema:"Name of the budget (required)"`
+	Active                 *bool   `json:"active,omitempty" jsonschema:"Whether the budget is active (default: true)"`
+	Notes                  *string `json:"notes,omitempty" jsonschema:"Notes for the budget"`
+	AutoBudgetType         *string `json:"auto_budget_type,omitempty" jsonschema:"Auto-budget: none, reset (same amount every period) or rollover (add the amount to what is left)"`
+	AutoBudgetAmount       *string `json:"auto_budget_amount,omitempty" jsonschema:"Auto-budget amount per period (required for reset and rollover)"`
+	AutoBudgetPeriod       *string `json:"auto_budget_period,omitempty" jsonschema:"Auto-budget period: daily, weekly, monthly, quarterly, half-year or yearly (required for reset and rollover)"`
+	AutoBudgetCurrencyCode *string `json:"auto_budget_currency_code,omitempty" jsonschema:"Currency of the auto-budget amount (default: the default currency)"`
+}
+
+// BudgetUpdateRequest represents the request body for updating a budget; unset
+// fields keep their current value
+type BudgetUpdateRequest struct {
+	Name                   *string `json:"name,omitempty" jsonschema:"New name of the budget"`
+	Active                 *bool   `json:"active,omitempty" jsonschema:"Whether the budget is active"`
+	Notes                  *string `json:"notes,omitempty" jsonschema:"Notes for the budget"`
+	AutoBudgetType         *string `json:"auto_budget_type,omitempty" jsonschema:"Auto-budget: none (removes it), reset or rollover"`
+	AutoBudgetAmount       *string `json:"auto_budget_amount,omitempty" jsonschema:"Auto-budget amount per period"`
+	AutoBudgetPeriod       *string `json:"auto_budget_period,omitempty" jsonschema:"Auto-budget period: daily, weekly, monthly, quarterly, half-year or yearly"`
+	AutoBudgetCurrencyCode *string `json:"auto_budget_currency_code,omitempty" jsonschema:"Currency of the auto-budget amount"`
+}
+
 // RuleGroupStoreRequest represents the request body for creating a rule group
 type RuleGroupStoreRequest struct {
 	Title       string  `json:"title" jsonschema:"Title for the rule group (required)"`
@@ -0,0 +1,228 @@
+package fireflyMCP
+
+import (
+	"encoding/json"
+	"flag"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// dtoGoldenFixtures are the DTO values whose JSON is pinned in
+// testdata/golden/<name>.json. MCP clients parse these shapes, so a changed
+// golden file is a breaking change unless only fields were added.
+func dtoGoldenFixtures() map[string]any {
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	berlin := time.Date(2024, 3, 31, 23, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+	pagination := Pagination{Count: 2, Total: 3, CurrentPage: 1, PerPage: 2, TotalPages: 2}
+
+	return map[string]any{
+		"account_list": AccountList{
+			Data: []Account{
+				{Id: "1", Active: true, Name: "Girokonto Müller", Notes: ptr("Gemeinsames Konto 💶"), Type: "asset",
+					OpeningBalance: ptr("1500.00"), OpeningBalanceDate: &date},
+				{Id: "2", Name: "住宅ローン", Type: "liabilities", Interest: ptr("3.25"), InterestPeriod: ptr("yearly")},
+			},
+			Pagination: pagination,
+		},
+		"account_list_nulls": AccountList{},
+		"budget_list": BudgetList{
+			Data: []Budget{
+				{Id: "1", Active: true, Name: "Épicerie", Notes: ptr("Courses & marché"),
+					Spent:          []BudgetSpent{{Sum: "-120.50", CurrencyCode: "EUR", CurrencySymbol: "€"}, {Sum: "-30.00", CurrencyCode: "USD", CurrencySymbol: "$"}},
+					AutoBudgetType: ptr("reset"), AutoBudgetAmount: ptr("400.00"), AutoBudgetPeriod: ptr("monthly"), AutoBudgetCurrencyCode: ptr("EUR")},
+				{Id: "2", Name: "Travel"},
+			},
+			SpentTotals: []BudgetSpent{{Sum: "-120.50", CurrencyCode: "EUR", CurrencySymbol: "€"}, {Sum: "-30.00", CurrencyCode: "USD", CurrencySymbol: "$"}},
+			Pagination:  pagination,
+		},
+		"budget_limit_list": BudgetLimitList{
+			Data: []BudgetLimit{
+				{Id: "7", Amount: "400.00", Start: date, End: berlin, BudgetId: "1", CurrencyCode: "EUR", CurrencySymbol: "€",
+					Notes: ptr("Ostern 🐣"), Spent: []BudgetSpent{{Sum: "-120.50", CurrencyCode: "EUR", CurrencySymbol: "€"}}},
+				{Id: "8", Amount: "50000", Start: date, End: date, BudgetId: "1", CurrencyCode: "JPY", CurrencySymbol: "¥"},
+			},
+			Pagination: pagination,
+		},
+		"category_list": CategoryList{
+			Data:       []Category{{Id: "1", Name: "Café ☕", Notes: ptr("Line one\nline \"two\"")}, {Id: "2", Name: "Rent"}},
+			Pagination: pagination,
+		},
+		"tag_list": TagList{
+			Data:       []Tag{{Id: "1", Tag: "member:zoë", Description: ptr("<b>not HTML</b> & co")}, {Id: "2", Tag: "planned"}},
+			Pagination: pagination,
+		},
+		"transaction_list": TransactionList{
+			Data: []TransactionGroup{
+				{Id: "40", GroupTitle: "Reise nach Zürich", Transactions: []Transaction{
+					{Id: "41", JournalId: "41", Amount: "89.90", BudgetId: ptr("2"), BudgetName: ptr("Travel"),
+						CategoryId: ptr("3"), CategoryName: ptr("Hôtel"), CurrencyCode: "CHF", Date: berlin,
+						Description: "Übernachtung 🛏", DestinationId: "9", DestinationName: "Hotel Bären", DestinationType: "Expense account",
+						Notes: ptr("Rechnung #4711"), Reconciled: true, SourceId: "1", SourceName: "Girokonto Müller",
+						Tags: []string{"trip:zürich", "member:zoë"}, Type: "withdrawal"},
+					{Id: "42", JournalId: "42", Order: 1, Amount: "12.00", BillId: ptr("5"), BillName: ptr("SBB GA"),
+						CurrencyCode: "EUR", Date: berlin, Description: "Zug", DestinationId: "10", DestinationName: "SBB",
+						DestinationType: "Expense account", SourceId: "1", SourceName: "Girokonto Müller", Tags: []string{}, Type: "withdrawal",
+						ExternalId: ptr("SBB-2024-03"), InternalReference: ptr("GA 2024"), OriginalSource: ptr("ff3-v6.1.0"),
+						ImportHashV2: ptr("4f2a9c"), RecurrenceId: ptr("3"), RecurrenceCount: ptr(2), RecurrenceTotal: ptr(0)},
+				}, CreatedAt: &date, UpdatedAt: &berlin},
+			},
+			Pagination: pagination,
+		},
+		"transaction_list_nulls": TransactionList{
+			Data: []TransactionGroup{{Id: "43", Transactions: []Transaction{{Id: "44", JournalId: "44", Amount: "1.00",
+				CurrencyCode: "EUR", Date: date, Description: "Cash", Type: "withdrawal"}}}},
+		},
+		"compact_transaction_list": CompactTransactionList{
+			Data: []CompactTransactionGroup{
+				{Id: "40", CompactTransaction: &CompactTransaction{JournalId: "41", Type: "deposit", Date: "2024-03-01", Amount: "2500.00",
+					CurrencyCode: "EUR", Description: "Gehalt März", SourceName: "Arbeitgeber GmbH", DestinationId: "1",
+					DestinationName: "Girokonto Müller", CategoryName: "Salary", Tags: []string{"member:zoë"}, RecurrenceId: "3"},
+					CreatedAt: "2024-03-01T00:00:00Z"},
+				{Id: "45", GroupTitle: "Split", Transactions: []CompactTransaction{
+					{JournalId: "46", Type: "withdrawal", Date: "2024-03-31T23:30:00+02:00", Amount: "5000", CurrencyCode: "JPY", Description: "ラーメン"},
+					{JournalId: "47", Order: 1, Type: "withdrawal", Date: "2024-03-31", Amount: "4.50", CurrencyCode: "USD", Description: "Tip", Reconciled: true},
+				}},
+			},
+			Pagination: pagination,
+		},
+		"basic_summary_list": BasicSummaryList{Data: []BasicSummary{
+			{Key: "balance-in-EUR", Title: "Balance (€)", CurrencyCode: "EUR", MonetaryValue: "1234.56"},
+			{Key: "balance-in-JPY", Title: "Balance (¥)", CurrencyCode: "JPY", MonetaryValue: "-5000"},
+		}},
+		"insight_category_response": InsightCategoryResponse{
+			Entries: []InsightCategoryEntry{
+				{Id: "3", Name: "Hôtel", Amount: "-89.90", CurrencyCode: "CHF", Share: "100.0"},
+				{Id: "4", Name: "食費", Amount: "-5000", CurrencyCode: "JPY", Share: "100.0"},
+			},
+			Totals: []InsightTotalEntry{{Amount: "-89.90", CurrencyCode: "CHF"}, {Amount: "-5000", CurrencyCode: "JPY"}},
+		},
+		"insight_total_response": InsightTotalResponse{Entries: []InsightTotalEntry{
+			{Amount: "2500.00", CurrencyCode: "EUR", Share: "80.0"},
+			{Amount: "625.00", CurrencyCode: "USD", Share: "20.0"},
+		}},
+		"bill_list": BillList{
+			Data: []Bill{
+				{Id: "5", Active: true, Name: "SBB GA", AmountMin: "340.00", AmountMax: "340.00", Date: date, RepeatFreq: "yearly",
+					CurrencyCode: "CHF", Notes: ptr("Abo 🚆"), NextExpectedMatch: &berlin,
+					PaidDates: []PaidDate{{Date: &date, TransactionGroupId: ptr("40"), TransactionJournalId: ptr("42")}, {}}},
+				{Id: "6", Name: "Netflix", AmountMin: "9.99", AmountMax: "12.99", Date: date, RepeatFreq: "monthly", Skip: 1, CurrencyCode: "USD"},
+			},
+			Pagination: pagination,
+		},
+		"recurrence_list": RecurrenceList{
+			Data: []Recurrence{{
+				Id: "3", Type: "withdrawal", Title: "Miete", Description: "Wohnung Straße 1", FirstDate: date, LatestDate: &berlin,
+				NrOfRepetitions: ptr(12), ApplyRules: true, Active: true, Notes: ptr("Kaltmiete + NK"),
+				Repetitions: []RecurrenceRepetition{{Id: "1", Type: "monthly", Moment: "1", Description: ptr("Jeden Monat am 1.")}},
+				Transactions: []RecurrenceTransaction{{Id: "1", Description: "Miete", Amount: "950.00", CurrencyCode: "EUR",
+					CategoryId: ptr("8"), CategoryName: ptr("Wohnen"), SourceId: "1", SourceName: "Girokonto Müller",
+					DestinationId: "11", DestinationName: "Vermieter Ölmann"}},
+			}, {Id: "4", Type: "deposit", Title: "Refund", FirstDate: date, RepeatUntil: &date}},
+			Pagination: pagination,
+		},
+		"rule_group_list": RuleGroupList{
+			Data:       []RuleGroup{{Id: "1", Title: "Ünterkünfte", Description: ptr("Hotels & more"), Order: 1, Active: true}, {Id: "2", Title: "Misc"}},
+			Pagination: pagination,
+		},
+		"rule_list": RuleList{
+			Data: []Rule{{
+				Id: "9", Title: "Café → Coffee", Description: ptr("Kategorisiert Cafés"), RuleGroupId: "1", RuleGroupTitle: ptr("Ünterkünfte"),
+				Order: 2, Trigger: "store-journal", Active: true, Strict: true,
+				Triggers: []RuleTrigger{{Id: "1", Type: "description_contains", Value: "café", Active: true}, {Type: "amount_more", Value: "5", Prohibited: true, StopProcessing: true, Order: 1}},
+				Actions:  []RuleAction{{Id: "1", Type: "set_category", Value: ptr("Café ☕"), Active: true}, {Type: "clear_budget", Order: 1}},
+			}},
+			Pagination: pagination,
+		},
+		"transaction_store_request": TransactionStoreRequest{
+			ApplyRules: true, GroupTitle: "Einkauf", Member: "zoë", AllowAccountAutocreate: ptr(false),
+			Transactions: []TransactionSplitRequest{{
+				Type: "withdrawal", Date: "2024-03-01", Amount: "12.50", Description: "Bäckerei", SourceId: ptr("1"),
+				DestinationName: ptr("Bäcker Öztürk"), CategoryName: ptr("Lebensmittel"), BudgetId: ptr("1"), Tags: []string{"frühstück"},
+				CurrencyCode: ptr("EUR"), ForeignAmount: ptr("13.60"), ForeignCurrencyCode: ptr("USD"), Notes: ptr("🥐"),
+				Reconciled: ptr(false), Order: ptr(0),
+			}},
+		},
+		"transaction_update_request": TransactionUpdateRequest{
+			FireWebhooks: true,
+			Transactions: []TransactionSplitRequest{{JournalId: ptr("41"), SourceName: ptr("Kasse"), BillId: ptr("5"),
+				CurrencyId: ptr("1"), ForeignCurrencyId: ptr("2"), BillName: ptr("SBB GA"), PiggyBankId: ptr("3"),
+				PiggyBankName: ptr("Urlaub"), CategoryId: ptr("3"), BudgetName: ptr("Travel"), DestinationId: ptr("9")}},
+		},
+		"rule_store_request": RuleStoreRequest{
+			Title: "Café", RuleGroupId: "1", Trigger: "store-journal", Active: ptr(true),
+			Triggers: []RuleTriggerRequest{{Type: "description_contains", Value: "café", Prohibited: ptr(false)}},
+			Actions:  []RuleActionRequest{{Type: "set_category", Value: ptr("Café ☕"), StopProcessing: ptr(true)}},
+		},
+		"rule_update_request":       RuleUpdateRequest{Title: ptr("Café (neu)"), Strict: ptr(false)},
+		"budget_store_request":      BudgetStoreRequest{Name: "Épicerie", AutoBudgetType: ptr("rollover"), AutoBudgetAmount: ptr("50000"), AutoBudgetPeriod: ptr("monthly"), AutoBudgetCurrencyCode: ptr("JPY")},
+		"budget_update_request":     BudgetUpdateRequest{Notes: ptr("")},
+		"rule_group_store_request":  RuleGroupStoreRequest{Title: "Ünterkünfte", Description: ptr("Hotels & more")},
+		"rule_group_update_request": RuleGroupUpdateRequest{Active: ptr(false)},
+	}
+}
+
+func TestDTOGoldenFiles(t *testing.T) {
+	for name, fixture := range dtoGoldenFixtures() {
+		t.Run(name, func(t *testing.T) {
+			data, err := json.MarshalIndent(fixture, "", "  ")
+			require.NoError(t, err)
+			data = append(data, '\n')
+
+			path := filepath.Join("testdata", "golden", name+".json")
+			if *updateGolden {
+				require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+				require.NoError(t, os.WriteFile(path, data, 0o644))
+			}
+			golden, err := os.ReadFile(path)
+			require.NoError(t, err, "run go test -run TestDTOGoldenFiles -update to create it")
+			assert.Equal(t, string(golden), string(data), "JSON of %T changed; review the diff, then run with -update", fixture)
+		})
+	}
+}
+
+// TestDTOGoldenFiles_CoverEveryDTO fails when a type of dto.go is not part of
+// any golden fixture, so new DTOs get a golden file too
+func TestDTOGoldenFiles_CoverEveryDTO(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "dto.go", nil, 0)
+	require.NoError(t, err)
+
+	covered := map[string]bool{}
+	var visit func(reflect.Type)
+	visit = func(typ reflect.Type) {
+		for typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Slice {
+			typ = typ.Elem()
+		}
+		if typ.Kind() != reflect.Struct || covered[typ.Name()] {
+			return
+		}
+		covered[typ.Name()] = true
+		for i := 0; i < typ.NumField(); i++ {
+			visit(typ.Field(i).Type)
+		}
+	}
+	for _, fixture := range dtoGoldenFixtures() {
+		visit(reflect.TypeOf(fixture))
+	}
+
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			name := spec.(*ast.TypeSpec).Name.Name
+			assert.True(t, covered[name], "%s has no golden fixture in dtoGoldenFixtures", name)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// e2eDemoSeed is the generate_demo_data seed of the e2e fixtures
+const e2eDemoSeed = 42
+
+// TestMain seeds the Firefly III instance of test/e2e before the integration
+// tests run. Without FIREFLY_E2E=1 the tests run unchanged.
+func TestMain(m *testing.M) {
+	if e2eEnabled() {
+		if err := seedE2EFixtures(os.Getenv("FIREFLY_MCP_SERVER_URL"), os.Getenv("FIREFLY_MCP_API_TOKEN")); err != nil {
+			fmt.Fprintf(os.Stderr, "seeding e2e fixtures: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	os.Exit(m.Run())
+}
+
+// seedE2EFixtures creates the accounts, budgets, categories, bills and
+// transactions the integration tests expect with generate_demo_data
+func seedE2EFixtures(serverURL, token string) error {
+	if serverURL == "" || token == "" {
+		return fmt.Errorf("FIREFLY_MCP_SERVER_URL and FIREFLY_MCP_API_TOKEN are required")
+	}
+	config := newPluginTestConfig()
+	config.Server.URL = serverURL
+	config.API.Token = token
+	config.Client.Timeout = 30
+	config.Demo.Enabled = true
+	server, err := NewFireflyMCPServer(config)
+	if err != nil {
+		return err
+	}
+
+	result, _, err := server.handleGenerateDemoData(context.Background(), nil, GenerateDemoDataArgs{Seed: e2eDemoSeed})
+	if err != nil {
+		return err
+	}
+	text := result.Content[0].(*mcp.TextContent).Text
+	if result.IsError {
+		return fmt.Errorf("%s", text)
+	}
+	var generated DemoDataResult
+	if err := json.Unmarshal([]byte(text), &generated); err != nil {
+		return err
+	}
+	if len(generated.Errors) > 0 {
+		return fmt.Errorf("generate_demo_data: %s", strings.Join(generated.Errors, "; "))
+	}
+	return nil
+}
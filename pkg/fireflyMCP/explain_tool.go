@@ -0,0 +1,226 @@
+package fireflyMCP
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ExplainToolArgs represents the arguments for the explain_tool tool
+type ExplainToolArgs struct {
+	Name string `json:"name" jsonschema:"Name of the tool to explain (required)"`
+}
+
+// ToolParameter is a concise description of one tool argument
+type ToolParameter struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Required    bool   `json:"required"`
+	Description string `json:"description,omitempty"`
+}
+
+// ToolExplanation is the result of explain_tool
+type ToolExplanation struct {
+	Name         string          `json:"name"`
+	Description  string          `json:"description"`
+	Behavior     string          `json:"behavior"`
+	Parameters   []ToolParameter `json:"parameters"`
+	Defaults     map[string]any  `json:"defaults,omitempty"`
+	Examples     any             `json:"examples,omitempty"`
+	InputSchema  any             `json:"input_schema"`
+	OutputSchema any             `json:"output_schema,omitempty"`
+}
+
+// toolOutputTypes maps tools to the DTO their successful result is encoded from.
+// Tools not listed here (including the recursive category trees, which cannot be
+// expressed by an inferred schema) return JSON described by their tool description.
+var toolOutputTypes = map[string]reflect.Type{
+	"list_accounts":             reflect.TypeFor[AccountList](),
+	"get_account":               reflect.TypeFor[Account](),
+	"account_stats":             reflect.TypeFor[AccountStats](),
+	"liability_schedule":        reflect.TypeFor[LiabilitySchedule](),
+	"grouped_balances":          reflect.TypeFor[GroupedBalances](),
+	"search_accounts":           reflect.TypeFor[AccountList](),
+	"list_transactions":         reflect.TypeFor[TransactionList](),
+	"get_transaction":           reflect.TypeFor[TransactionGroup](),
+	"search_transactions":       reflect.TypeFor[TransactionList](),
+	"store_transaction":         reflect.TypeFor[TransactionGroup](),
+	"store_transactions_bulk":   reflect.TypeFor[BulkTransactionStoreResponse](),
+	"transfer_to_piggy":         reflect.TypeFor[PiggyTransferResult](),
+	"plan_savings_goal":         reflect.TypeFor[SavingsGoalPlan](),
+	"get_schema_changelog":      reflect.TypeFor[SchemaChangelog](),
+	"update_transaction":        reflect.TypeFor[TransactionGroup](),
+	"tag_transactions":          reflect.TypeFor[TagTransactionsReport](),
+	"store_planned_transaction": reflect.TypeFor[TransactionGroup](),
+	"list_planned_transactions": reflect.TypeFor[PlannedTransactions](),
+	"confirm_planned":           reflect.TypeFor[TransactionGroup](),
+	"list_budgets":              reflect.TypeFor[BudgetList](),
+	"list_budget_limits":        reflect.TypeFor[BudgetLimitList](),
+	"list_budget_transactions":  reflect.TypeFor[TransactionList](),
+	"budget_rollover":           reflect.TypeFor[BudgetRolloverReport](),
+	"seed_budget_limits":        reflect.TypeFor[BudgetSeedReport](),
+	"allocate_remaining":        reflect.TypeFor[BudgetAllocationReport](),
+	"move_budget_amount":        reflect.TypeFor[BudgetMoveResult](),
+	"list_categories":           reflect.TypeFor[CategoryList](),
+	"list_tags":                 reflect.TypeFor[TagList](),
+	"get_summary":               reflect.TypeFor[BasicSummaryList](),
+	"expense_category_insights": reflect.TypeFor[InsightCategoryResponse](),
+	"expense_total_insights":    reflect.TypeFor[InsightTotalResponse](),
+	"expense_asset_insights":    reflect.TypeFor[InsightCategoryResponse](),
+	"income_asset_insights":     reflect.TypeFor[InsightCategoryResponse](),
+	"income_expense_trend":      reflect.TypeFor[IncomeExpenseTrend](),
+	"list_bills":                reflect.TypeFor[BillList](),
+	"get_bill":                  reflect.TypeFor[Bill](),
+	"list_bill_transactions":    reflect.TypeFor[TransactionList](),
+	"list_recurrences":          reflect.TypeFor[RecurrenceList](),
+	"get_recurrence":            reflect.TypeFor[Recurrence](),
+	"list_rule_groups":          reflect.TypeFor[RuleGroupList](),
+	"get_rule_group":            reflect.TypeFor[RuleGroup](),
+	"list_rules_by_group":       reflect.TypeFor[RuleList](),
+	"list_rules":                reflect.TypeFor[RuleList](),
+	"get_rule":                  reflect.TypeFor[Rule](),
+	"close_month":               reflect.TypeFor[MonthCloseReport](),
+	"diff_periods":              reflect.TypeFor[TransactionDiff](),
+	"verify_consistency":        reflect.TypeFor[ConsistencyReport](),
+	"cleanup_advisor":           reflect.TypeFor[CleanupPlan](),
+	"find_currency_mismatches":  reflect.TypeFor[CurrencyMismatchReport](),
+	"summarize_transactions":    reflect.TypeFor[TransactionSummary](),
+	"autocomplete":              reflect.TypeFor[AutocompleteResult](),
+}
+
+// handleExplainTool returns the arguments, schemas, examples and effective
+// config-derived defaults of a registered tool
+func (s *FireflyMCPServer) handleExplainTool(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args ExplainToolArgs,
+) (*mcp.CallToolResult, any, error) {
+	if args.Name == "" {
+		return newErrorResult("Error: name is required")
+	}
+	registered, ok := s.tools[args.Name]
+	if !ok {
+		names := make([]string, 0, len(s.tools))
+		for name := range s.tools {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return newErrorResult(fmt.Sprintf("Error: unknown tool %q (available: %s)", args.Name, strings.Join(names, ", ")))
+	}
+
+	tool := registered.tool
+	explanation := &ToolExplanation{
+		Name:        tool.Name,
+		Description: tool.Description,
+		Behavior:    describeBehavior(tool.Annotations),
+		Parameters:  []ToolParameter{},
+		Defaults:    s.toolDefaults(tool.Name),
+		Examples:    tool.Meta["examples"],
+		InputSchema: tool.InputSchema,
+	}
+	if schema, ok := tool.InputSchema.(*jsonschema.Schema); ok {
+		explanation.Parameters = schemaParameters(schema)
+	}
+
+	explanation.OutputSchema = tool.OutputSchema
+	if outputType, ok := toolOutputTypes[tool.Name]; ok && explanation.OutputSchema == nil {
+		schema, err := jsonschema.ForType(outputType, &jsonschema.ForOptions{})
+		if err != nil {
+			return newErrorResult(fmt.Sprintf("Error building output schema: %v", err))
+		}
+		explanation.OutputSchema = schema
+	}
+
+	return newSuccessResult(explanation)
+}
+
+// describeBehavior summarizes tool annotations as a short phrase
+func describeBehavior(annotations *mcp.ToolAnnotations) string {
+	switch {
+	case annotations == nil:
+		return "unknown"
+	case annotations.ReadOnlyHint:
+		return "read-only"
+	case annotations.DestructiveHint != nil && !*annotations.DestructiveHint:
+		return "creates data"
+	case annotations.IdempotentHint:
+		return "modifies or deletes data (idempotent)"
+	default:
+		return "modifies or deletes data"
+	}
+}
+
+// toolDefaults returns the defaults of a tool that come from the configuration
+func (s *FireflyMCPServer) toolDefaults(name string) map[string]any {
+	defaults := make(map[string]any)
+	if limit := s.defaultLimit(name); limit > 0 {
+		defaults["limit"] = limit
+	}
+	if config := s.currentConfig(); config != nil {
+		switch name {
+		case "budget_rollover":
+			defaults["strategy"] = config.Budgets.RolloverStrategy
+			defaults["cap_percent"] = config.Budgets.RolloverCapPercent
+		case "category_tree", "category_rollup_insights":
+			defaults["delimiter"] = s.categoryDelimiter()
+		}
+	}
+	if len(defaults) == 0 {
+		return nil
+	}
+	return defaults
+}
+
+// schemaParameters lists the top-level properties of an input schema, required ones first
+func schemaParameters(schema *jsonschema.Schema) []ToolParameter {
+	required := make(map[string]bool, len(schema.Required))
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+
+	parameters := make([]ToolParameter, 0, len(schema.Properties))
+	for name, property := range schema.Properties {
+		parameters = append(parameters, ToolParameter{
+			Name:        name,
+			Type:        schemaTypeName(property),
+			Required:    required[name],
+			Description: property.Description,
+		})
+	}
+	sort.Slice(parameters, func(i, j int) bool {
+		if parameters[i].Required != parameters[j].Required {
+			return parameters[i].Required
+		}
+		return parameters[i].Name < parameters[j].Name
+	})
+	return parameters
+}
+
+// schemaTypeName renders the type of a schema, e.g. "string" or "array of object"
+func schemaTypeName(schema *jsonschema.Schema) string {
+	if schema == nil {
+		return "any"
+	}
+	typeName := schema.Type
+	if typeName == "" {
+		var types []string
+		for _, t := range schema.Types {
+			if t != "null" {
+				types = append(types, t)
+			}
+		}
+		typeName = strings.Join(types, " or ")
+	}
+	if typeName == "" {
+		return "any"
+	}
+	if typeName == "array" {
+		return "array of " + schemaTypeName(schema.Items)
+	}
+	return typeName
+}
@@ -0,0 +1,90 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func explainTool(t *testing.T, server *FireflyMCPServer, name string) map[string]any {
+	t.Helper()
+	result, _, err := server.handleExplainTool(context.Background(), nil, ExplainToolArgs{Name: name})
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content[0].(*mcp.TextContent).Text)
+
+	var explanation map[string]any
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &explanation))
+	return explanation
+}
+
+func TestExplainTool(t *testing.T) {
+	config := newPluginTestConfig()
+	config.Limits.Transactions = 25
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	explanation := explainTool(t, server, "list_transactions")
+	assert.Equal(t, "read-only", explanation["behavior"])
+	assert.Equal(t, map[string]any{"limit": float64(25)}, explanation["defaults"])
+	assert.NotNil(t, explanation["input_schema"])
+	assert.NotNil(t, explanation["output_schema"])
+
+	parameters := explanation["parameters"].([]any)
+	require.NotEmpty(t, parameters)
+	names := make([]string, 0, len(parameters))
+	for _, parameter := range parameters {
+		names = append(names, parameter.(map[string]any)["name"].(string))
+	}
+	assert.Contains(t, names, "reconciled")
+	assert.Len(t, explanation["examples"], len(toolExamples()["list_transactions"]))
+
+	explanation = explainTool(t, server, "budget_rollover")
+	assert.Equal(t, "modifies or deletes data (idempotent)", explanation["behavior"])
+	defaults := explanation["defaults"].(map[string]any)
+	assert.Equal(t, server.config.Budgets.RolloverStrategy, defaults["strategy"])
+
+	explanation = explainTool(t, server, "get_account")
+	parameters = explanation["parameters"].([]any)
+	require.Len(t, parameters, 1)
+	assert.Equal(t, map[string]any{
+		"name": "id", "type": "string", "required": true, "description": "Account ID or configured account alias",
+	}, parameters[0])
+}
+
+func TestExplainTool_UnknownTool(t *testing.T) {
+	server, err := NewFireflyMCPServer(newPluginTestConfig())
+	require.NoError(t, err)
+
+	result, _, err := server.handleExplainTool(context.Background(), nil, ExplainToolArgs{Name: "list_everything"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "list_accounts")
+}
+
+func TestToolOutputTypes(t *testing.T) {
+	config := newPluginTestConfig()
+	config.Sampling.Enabled = true
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	for name, outputType := range toolOutputTypes {
+		assert.True(t, server.hasTool(name), "output type listed for unknown tool %s", name)
+		_, err := jsonschema.ForType(outputType, &jsonschema.ForOptions{})
+		assert.NoError(t, err, name)
+	}
+	for name, registered := range server.tools {
+		assert.NotNil(t, registered.tool.InputSchema, "tool %s has no input schema", name)
+	}
+}
+
+func TestSchemaTypeName(t *testing.T) {
+	assert.Equal(t, "string", schemaTypeName(&jsonschema.Schema{Type: "string"}))
+	assert.Equal(t, "boolean", schemaTypeName(&jsonschema.Schema{Types: []string{"null", "boolean"}}))
+	assert.Equal(t, "array of object", schemaTypeName(&jsonschema.Schema{Type: "array", Items: &jsonschema.Schema{Type: "object"}}))
+	assert.Equal(t, "any", schemaTypeName(nil))
+}
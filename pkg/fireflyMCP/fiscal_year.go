@@ -0,0 +1,135 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dezer32/mcp-firefly-iii/pkg/client"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Fiscal year periods, resolved with the fiscal year preferences of the user
+const (
+	periodFiscalYear       = "fiscal_year"
+	periodLastFiscalYear   = "last_fiscal_year"
+	periodFiscalYearToDate = "fiscal_year_to_date"
+)
+
+// Firefly III preferences that define a custom fiscal year
+const (
+	preferenceCustomFiscalYear = "customFiscalYear"
+	preferenceFiscalYearStart  = "fiscalYearStart" // MM-DD
+)
+
+func isFiscalPeriod(period string) bool {
+	switch period {
+	case periodFiscalYear, periodLastFiscalYear, periodFiscalYearToDate:
+		return true
+	}
+	return false
+}
+
+// resolveFiscalPeriod replaces a fiscal year period of a date range by its
+// start and end, using the fiscal year start set in Firefly III. Other date
+// ranges are returned unchanged.
+func (s *FireflyMCPServer) resolveFiscalPeriod(ctx context.Context, req *mcp.CallToolRequest, r DateRange) (DateRange, error) {
+	period := strings.ToLower(strings.TrimSpace(r.Period))
+	if !isFiscalPeriod(period) {
+		return r, nil
+	}
+	if r.Start != "" || r.End != "" {
+		return r, fmt.Errorf("use either period or start/end, not both")
+	}
+
+	apiClient, err := s.getClient(ctx, req)
+	if err != nil {
+		return r, fmt.Errorf("Failed to get API client: %v", err)
+	}
+	month, day, err := s.fiscalYearStart(ctx, apiClient)
+	if err != nil {
+		return r, err
+	}
+	start, end := fiscalYearPeriod(period, s.today(), month, day)
+	return DateRange{Start: start.Format("2006-01-02"), End: end.Format("2006-01-02")}, nil
+}
+
+// fiscalYearStart returns the first month and day of the fiscal year of the
+// user; January 1st unless a custom fiscal year is enabled
+func (s *FireflyMCPServer) fiscalYearStart(ctx context.Context, apiClient *client.ClientWithResponses) (time.Month, int, error) {
+	var custom any
+	found, err := s.preference(ctx, apiClient, preferenceCustomFiscalYear, &custom)
+	if err != nil || !found {
+		return time.January, 1, err
+	}
+	if !preferenceEnabled(custom) {
+		return time.January, 1, nil
+	}
+
+	var start string
+	found, err = s.preference(ctx, apiClient, preferenceFiscalYearStart, &start)
+	if err != nil || !found || start == "" {
+		return time.January, 1, err
+	}
+	date, err := time.Parse("01-02", start)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid %s preference %q: expected MM-DD", preferenceFiscalYearStart, start)
+	}
+	return date.Month(), date.Day(), nil
+}
+
+// preference decodes the value of a Firefly III preference. Reports false when
+// the preference is not set.
+func (s *FireflyMCPServer) preference(ctx context.Context, apiClient *client.ClientWithResponses, name string, value any) (bool, error) {
+	resp, err := apiClient.GetPreferenceWithResponse(ctx, name, &client.GetPreferenceParams{})
+	if err != nil {
+		return false, fmt.Errorf("error reading preference %s: %v", name, err)
+	}
+	if resp.StatusCode() == 404 {
+		return false, nil
+	}
+	if resp.StatusCode() != 200 || resp.ApplicationvndApiJSON200 == nil {
+		return false, fmt.Errorf("API error %d reading preference %s: %s", resp.StatusCode(), name, s.upstreamError(resp.Body))
+	}
+	raw, err := json.Marshal(resp.ApplicationvndApiJSON200.Data.Attributes.Data)
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(raw, value); err != nil {
+		return false, fmt.Errorf("unexpected value of preference %s: %s", name, raw)
+	}
+	return true, nil
+}
+
+// preferenceEnabled reports whether a boolean preference is on; older Firefly
+// III versions store booleans as "1" or 1
+func preferenceEnabled(value any) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "1" || v == "true"
+	case float64:
+		return v == 1
+	}
+	return false
+}
+
+// fiscalYearPeriod returns the first and last day of a fiscal year period for
+// fiscal years starting on the given month and day
+func fiscalYearPeriod(period string, today time.Time, month time.Month, day int) (time.Time, time.Time) {
+	start := time.Date(today.Year(), month, day, 0, 0, 0, 0, time.UTC)
+	if start.After(today) {
+		start = start.AddDate(-1, 0, 0)
+	}
+	switch period {
+	case periodLastFiscalYear:
+		return start.AddDate(-1, 0, 0), start.AddDate(0, 0, -1)
+	case periodFiscalYearToDate:
+		return start, today
+	default:
+		return start, start.AddDate(1, 0, -1)
+	}
+}
@@ -0,0 +1,81 @@
+package fireflyMCP
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFiscalYearPeriod(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name       string
+		period     string
+		today      time.Time
+		month      time.Month
+		day        int
+		start, end time.Time
+	}{
+		{"calendar year", periodFiscalYear, date(2024, 5, 10), time.January, 1, date(2024, 1, 1), date(2024, 12, 31)},
+		{"after the start", periodFiscalYear, date(2024, 5, 10), time.April, 6, date(2024, 4, 6), date(2025, 4, 5)},
+		{"before the start", periodFiscalYear, date(2024, 2, 10), time.April, 6, date(2023, 4, 6), date(2024, 4, 5)},
+		{"on the start", periodFiscalYear, date(2024, 4, 6), time.April, 6, date(2024, 4, 6), date(2025, 4, 5)},
+		{"last", periodLastFiscalYear, date(2024, 2, 10), time.July, 1, date(2022, 7, 1), date(2023, 6, 30)},
+		{"to date", periodFiscalYearToDate, date(2024, 2, 10), time.October, 1, date(2023, 10, 1), date(2024, 2, 10)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := fiscalYearPeriod(tt.period, tt.today, tt.month, tt.day)
+			assert.Equal(t, tt.start, start)
+			assert.Equal(t, tt.end, end)
+		})
+	}
+}
+
+func TestFiscalYearPeriodArgument(t *testing.T) {
+	var summaryQuery string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch r.URL.Path {
+		case "/v1/preferences/customFiscalYear":
+			fmt.Fprint(w, `{"data":{"type":"preferences","id":"1","attributes":{"name":"customFiscalYear","data":"1"}}}`)
+		case "/v1/preferences/fiscalYearStart":
+			fmt.Fprint(w, `{"data":{"type":"preferences","id":"2","attributes":{"name":"fiscalYearStart","data":"04-01"}}}`)
+		case "/v1/summary/basic":
+			summaryQuery = r.URL.Query().Get("start") + "/" + r.URL.Query().Get("end")
+			fmt.Fprint(w, `{}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	result, _, err := server.handleGetSummary(ctx, nil, GetSummaryArgs{DateRange: DateRange{Period: "fiscal_year"}})
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content[0].(*mcp.TextContent).Text)
+	start, end := fiscalYearPeriod(periodFiscalYear, server.today(), time.April, 1)
+	assert.Equal(t, start.Format("2006-01-02")+"/"+end.Format("2006-01-02"), summaryQuery)
+
+	result, _, err = server.handleGetSummary(ctx, nil, GetSummaryArgs{DateRange: DateRange{Period: "fiscal_year", Start: "2024-01-01"}})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	// Tools without fiscal year support reject the period
+	_, err = server.resolveDateRange(DateRange{Period: "fiscal_year"}, dateRangeOpen)
+	assert.ErrorContains(t, err, "only supported by the summary, budget and insight tools")
+}
@@ -0,0 +1,189 @@
+package fireflyMCP
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dezer32/mcp-firefly-iii/pkg/client"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// FormattingHints tells the model how the user sees amounts in the Firefly III UI.
+// They are attached to tool results as _meta.formatting.
+type FormattingHints struct {
+	CurrencyCode       string `json:"currency_code,omitempty"`
+	CurrencySymbol     string `json:"currency_symbol,omitempty"`
+	DecimalPlaces      int    `json:"decimal_places,omitempty"`
+	Locale             string `json:"locale,omitempty"`
+	Language           string `json:"language,omitempty"`
+	DecimalSeparator   string `json:"decimal_separator,omitempty"`
+	ThousandsSeparator string `json:"thousands_separator,omitempty"`
+}
+
+// Languages writing a decimal comma, with the thousands separator they use.
+// Everything else is formatted like en_US.
+var decimalCommaLanguages = map[string]string{
+	"bg": " ", "ca": ".", "cs": " ", "da": ".", "de": ".", "el": ".", "es": ".", "et": " ",
+	"fi": " ", "fr": " ", "hr": ".", "hu": " ", "id": ".", "it": ".", "lt": " ", "lv": " ",
+	"nb": " ", "nl": ".", "no": " ", "pl": " ", "pt": ".", "ro": ".", "ru": " ", "sk": " ",
+	"sl": ".", "sv": " ", "tr": ".", "uk": " ", "vi": ".",
+}
+
+// formattingCache keeps the formatting hints per tenant, so they are fetched
+// once per user and cache period rather than on every tool call
+type formattingCache struct {
+	mu         sync.Mutex
+	entries    map[string]formattingCacheEntry
+	generation uint64 // Bumped by flush, so hints fetched before are not kept
+}
+
+type formattingCacheEntry struct {
+	hints   *FormattingHints // nil if the preferences could not be read
+	fetched time.Time
+}
+
+func newFormattingCache() *formattingCache {
+	return &formattingCache{entries: make(map[string]formattingCacheEntry)}
+}
+
+// get returns the hints of a tenant fetched less than ttl ago, and else the
+// generation to pass to put with the hints fetched instead
+func (c *formattingCache) get(tenant string, ttl time.Duration) (*FormattingHints, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[tenant]
+	if ok && time.Since(entry.fetched) < ttl {
+		return entry.hints, 0, true
+	}
+	return nil, c.generation, false
+}
+
+// put keeps the hints of a tenant unless the cache was flushed since get
+// returned generation
+func (c *formattingCache) put(tenant string, generation uint64, hints *FormattingHints) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation == c.generation {
+		c.entries[tenant] = formattingCacheEntry{hints: hints, fetched: time.Now()}
+	}
+}
+
+// size returns how many tenants have cached hints
+func (c *formattingCache) size() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// flush drops all hints and returns how many were dropped
+func (c *formattingCache) flush() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	flushed := len(c.entries)
+	c.entries = make(map[string]formattingCacheEntry)
+	c.generation++
+	return flushed
+}
+
+// withFormattingHints attaches the formatting hints of the calling user to
+// successful tool results
+func withFormattingHints[In any](s *FireflyMCPServer, handler mcp.ToolHandlerFor[In, any]) mcp.ToolHandlerFor[In, any] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, args In) (*mcp.CallToolResult, any, error) {
+		result, out, err := handler(ctx, req, args)
+		if err != nil || result == nil || result.IsError {
+			return result, out, err
+		}
+		if hints := s.formattingHints(ctx, req); hints != nil {
+			if result.Meta == nil {
+				result.Meta = mcp.Meta{}
+			}
+			result.Meta["formatting"] = hints
+		}
+		return result, out, err
+	}
+}
+
+// formattingHints returns the cached formatting hints of the calling user,
+// fetching them when missing or expired. Returns nil when hints are disabled or
+// Firefly III did not return any of the preferences.
+func (s *FireflyMCPServer) formattingHints(ctx context.Context, req *mcp.CallToolRequest) *FormattingHints {
+	config := s.currentConfig()
+	if config == nil || !config.Formatting.Hints || s.formatting == nil {
+		return nil
+	}
+	key := s.tenantKey(req)
+	ttl := time.Duration(config.Formatting.CacheTTL) * time.Second
+
+	cached, generation, ok := s.formatting.get(key, ttl)
+	if ok {
+		return cached
+	}
+
+	apiClient, err := s.getClient(ctx, req)
+	if err != nil {
+		return nil
+	}
+	hints := fetchFormattingHints(ctx, apiClient)
+
+	s.formatting.put(key, generation, hints)
+	return hints
+}
+
+// fetchFormattingHints reads the native currency and the language and locale
+// preferences. Failed lookups are left out of the hints.
+func fetchFormattingHints(ctx context.Context, apiClient *client.ClientWithResponses) *FormattingHints {
+	hints := &FormattingHints{}
+	found := false
+
+	if resp, err := apiClient.GetNativeCurrencyWithResponse(ctx, &client.GetNativeCurrencyParams{}); err == nil &&
+		resp.StatusCode() == 200 && resp.JSON200 != nil {
+		currency := resp.JSON200.Data.Attributes
+		hints.CurrencyCode = currency.Code
+		hints.CurrencySymbol = currency.Symbol
+		if currency.DecimalPlaces != nil {
+			hints.DecimalPlaces = int(*currency.DecimalPlaces)
+		}
+		found = true
+	}
+
+	hints.Language = stringPreference(ctx, apiClient, "language")
+	hints.Locale = stringPreference(ctx, apiClient, "locale")
+	// Firefly III stores "equal" when the locale follows the language
+	if hints.Locale == "" || hints.Locale == "equal" {
+		hints.Locale = hints.Language
+	}
+	if hints.Locale != "" {
+		hints.DecimalSeparator, hints.ThousandsSeparator = localeSeparators(hints.Locale)
+		found = true
+	}
+
+	if !found {
+		return nil
+	}
+	return hints
+}
+
+// stringPreference returns a string preference of the user, or "" if it is not
+// set or not a string
+func stringPreference(ctx context.Context, apiClient *client.ClientWithResponses, name string) string {
+	resp, err := apiClient.GetPreferenceWithResponse(ctx, name, &client.GetPreferenceParams{})
+	if err != nil || resp.StatusCode() != 200 || resp.ApplicationvndApiJSON200 == nil {
+		return ""
+	}
+	value, err := resp.ApplicationvndApiJSON200.Data.Attributes.Data.AsPolymorphicProperty1()
+	if err != nil {
+		return ""
+	}
+	return value
+}
+
+// localeSeparators returns the decimal and thousands separator of a locale such as "de_DE"
+func localeSeparators(locale string) (string, string) {
+	language, _, _ := strings.Cut(strings.ReplaceAll(locale, "-", "_"), "_")
+	if thousands, ok := decimalCommaLanguages[strings.ToLower(language)]; ok {
+		return ",", thousands
+	}
+	return ".", ","
+}
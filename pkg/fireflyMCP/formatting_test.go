@@ -0,0 +1,104 @@
+package fireflyMCP
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocaleSeparators(t *testing.T) {
+	tests := []struct {
+		locale, decimal, thousands string
+	}{
+		{"en_US", ".", ","},
+		{"de_DE", ",", "."},
+		{"fr-FR", ",", " "},
+		{"nl", ",", "."},
+		{"ja_JP", ".", ","},
+	}
+	for _, tt := range tests {
+		decimal, thousands := localeSeparators(tt.locale)
+		assert.Equal(t, tt.decimal, decimal, tt.locale)
+		assert.Equal(t, tt.thousands, thousands, tt.locale)
+	}
+}
+
+func TestFormattingHints(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch r.URL.Path {
+		case "/v1/currencies/native":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"data":{"id":"1","type":"currencies","attributes":{"code":"EUR","symbol":"€","name":"Euro","decimal_places":2}}}`))
+		case "/v1/preferences/language":
+			w.Write([]byte(`{"data":{"id":"1","type":"preferences","attributes":{"name":"language","data":"de_DE"}}}`))
+		case "/v1/preferences/locale":
+			w.Write([]byte(`{"data":{"id":"2","type":"preferences","attributes":{"name":"locale","data":"equal"}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	config.Formatting.Hints = true
+	config.Formatting.CacheTTL = 600
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	handler := withFormattingHints(server, func(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+		return newSuccessResult(map[string]string{"ok": "yes"})
+	})
+
+	result, _, err := handler(context.Background(), nil, struct{}{})
+	require.NoError(t, err)
+	assert.Equal(t, &FormattingHints{
+		CurrencyCode:       "EUR",
+		CurrencySymbol:     "€",
+		DecimalPlaces:      2,
+		Locale:             "de_DE",
+		Language:           "de_DE",
+		DecimalSeparator:   ",",
+		ThousandsSeparator: ".",
+	}, result.Meta["formatting"])
+	assert.Equal(t, 3, requests)
+
+	// Cached for the next call
+	result, _, err = handler(context.Background(), nil, struct{}{})
+	require.NoError(t, err)
+	assert.NotNil(t, result.Meta["formatting"])
+	assert.Equal(t, 3, requests)
+
+	// Not attached to errors
+	failing := withFormattingHints(server, func(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+		return newErrorResult("Error: boom")
+	})
+	result, _, err = failing(context.Background(), nil, struct{}{})
+	require.NoError(t, err)
+	assert.Nil(t, result.Meta["formatting"])
+}
+
+func TestFormattingHints_Unavailable(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	config.Formatting.Hints = true
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	assert.Nil(t, server.formattingHints(context.Background(), nil))
+
+	assert.Nil(t, (&FireflyMCPServer{}).formattingHints(context.Background(), nil))
+}
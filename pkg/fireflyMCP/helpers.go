@@ -2,8 +2,10 @@ package fireflyMCP
 
 import (
 	"encoding/json"
+	"strings"
 	"time"
 
+	"github.com/dezer32/mcp-firefly-iii/pkg/client"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	openapi_types "github.com/oapi-codegen/runtime/types"
 )
@@ -45,3 +47,55 @@ func parseOptionalDate(dateStr string) (*openapi_types.Date, error) {
 	}
 	return &openapi_types.Date{Time: parsed}, nil
 }
+
+// transactionFilterQuery expresses type and date filters in Firefly III search
+// syntax, for filters only the search endpoint supports
+func transactionFilterQuery(transactionType string, dates resolvedDateRange) string {
+	var parts []string
+	if transactionType != "" && transactionType != "all" {
+		parts = append(parts, "type:"+transactionType)
+	}
+	if dates.Start != nil {
+		parts = append(parts, "date_after:"+dates.StartString())
+	}
+	if dates.End != nil {
+		parts = append(parts, "date_before:"+dates.EndString())
+	}
+	return strings.Join(parts, " ")
+}
+
+// accountTypeKinds maps the account type labels of transaction splits to short
+// account types. Labels are compared case-insensitively.
+var accountTypeKinds = map[string]client.ShortAccountTypeProperty{
+	strings.ToLower(string(client.AccountTypePropertyAssetAccount)):          client.ShortAccountTypePropertyAsset,
+	strings.ToLower(string(client.AccountTypePropertyDefaultAccount)):        client.ShortAccountTypePropertyAsset,
+	strings.ToLower(string(client.AccountTypePropertyCashAccount)):           client.ShortAccountTypePropertyCash,
+	strings.ToLower(string(client.AccountTypePropertyExpenseAccount)):        client.ShortAccountTypePropertyExpense,
+	strings.ToLower(string(client.AccountTypePropertyBeneficiaryAccount)):    client.ShortAccountTypePropertyExpense,
+	strings.ToLower(string(client.AccountTypePropertyRevenueAccount)):        client.ShortAccountTypePropertyRevenue,
+	strings.ToLower(string(client.AccountTypePropertyImportAccount)):         client.ShortAccountTypePropertyImport,
+	strings.ToLower(string(client.AccountTypePropertyInitialBalanceAccount)): client.ShortAccountTypePropertyInitialBalance,
+	strings.ToLower(string(client.AccountTypePropertyReconciliationAccount)): client.ShortAccountTypePropertyReconciliation,
+	strings.ToLower(string(client.AccountTypePropertyDebt)):                  client.ShortAccountTypePropertyLiability,
+	strings.ToLower(string(client.AccountTypePropertyLoan)):                  client.ShortAccountTypePropertyLiability,
+	strings.ToLower(string(client.AccountTypePropertyMortgage)):              client.ShortAccountTypePropertyLiability,
+}
+
+// accountTypeKind returns the short account type of an account type label, which
+// may also be a short type already. Labels that are not known, such as labels
+// localized through Accept-Language, return "" so callers do not guess.
+func accountTypeKind(label string) client.ShortAccountTypeProperty {
+	normalized := strings.ToLower(strings.TrimSpace(label))
+	if kind, ok := accountTypeKinds[normalized]; ok {
+		return kind
+	}
+	switch kind := client.ShortAccountTypeProperty(normalized); kind {
+	case client.ShortAccountTypePropertyAsset, client.ShortAccountTypePropertyCash, client.ShortAccountTypePropertyExpense,
+		client.ShortAccountTypePropertyRevenue, client.ShortAccountTypePropertyImport, client.ShortAccountTypePropertyInitialBalance,
+		client.ShortAccountTypePropertyReconciliation, client.ShortAccountTypePropertyLiability:
+		return kind
+	case client.ShortAccountTypePropertyLiabilities:
+		return client.ShortAccountTypePropertyLiability
+	}
+	return ""
+}
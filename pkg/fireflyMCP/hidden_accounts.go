@@ -0,0 +1,354 @@
+package fireflyMCP
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// hiddenAccounts is the set of account IDs configured in accounts.hidden
+type hiddenAccounts struct {
+	mu  sync.RWMutex
+	ids map[string]bool
+}
+
+// newHiddenAccounts returns the hidden accounts of a configuration
+func newHiddenAccounts(config *Config) *hiddenAccounts {
+	h := &hiddenAccounts{}
+	h.set(config)
+	return h
+}
+
+// set replaces the hidden accounts by those of a configuration. Entries may be
+// account IDs or aliases.
+func (h *hiddenAccounts) set(config *Config) {
+	aliases := normalizeAccountAliases(config.Accounts.Aliases)
+	ids := make(map[string]bool, len(config.Accounts.Hidden))
+	for _, ref := range config.Accounts.Hidden {
+		ref = strings.TrimSpace(ref)
+		if id, ok := aliases[normalizeAliasKey(ref)]; ok {
+			ref = id
+		}
+		if ref != "" {
+			ids[ref] = true
+		}
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.ids = ids
+}
+
+// contains reports whether the account with the given ID is hidden
+func (h *hiddenAccounts) contains(id string) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.ids[id]
+}
+
+// empty reports whether no account is hidden
+func (h *hiddenAccounts) empty() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.ids) == 0
+}
+
+// accountPathPattern matches requests to a single account, e.g. /v1/accounts/7/transactions
+var accountPathPattern = regexp.MustCompile(`/v1/accounts/([^/]+)`)
+
+// accountListPathPattern matches requests answering a plain list of accounts:
+// the accounts autocomplete and the insights grouped by asset account
+var accountListPathPattern = regexp.MustCompile(`/v1/(autocomplete/accounts|insight/[^/]+/asset)$`)
+
+// errHiddenAccountTotals refuses requests for totals Firefly III computes over
+// all accounts without an account filter, which would include hidden accounts
+var errHiddenAccountTotals = errors.New(
+	"accounts.hidden is set and Firefly III cannot leave hidden accounts out of summary and chart totals; " +
+		"use the insight tools, which are limited to the visible accounts",
+)
+
+// hiddenTotalsPathPattern matches the summary and chart endpoints, which take no account filter
+var hiddenTotalsPathPattern = regexp.MustCompile(`/v1/(summary|chart)/`)
+
+// insightPath marks the insight endpoints, which all take an accounts[] filter
+const insightPath = "/v1/insight/"
+
+// hiddenAccountsTransport keeps hidden accounts away from every tool: requests
+// to or filtering by a hidden account answer 404, insight requests without an
+// account filter are limited to the visible asset accounts and liabilities,
+// summary and chart requests are refused, and hidden accounts, transactions and
+// recurrences touching them and their piggy banks are removed from the
+// responses of list and show requests.
+type hiddenAccountsTransport struct {
+	base   http.RoundTripper
+	hidden *hiddenAccounts
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *hiddenAccountsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.hidden.empty() {
+		return t.base.RoundTrip(req)
+	}
+	if match := accountPathPattern.FindStringSubmatch(req.URL.Path); match != nil && t.hidden.contains(match[1]) {
+		return notFoundResponse(req), nil
+	}
+	if t.queriesHidden(req.URL.Query()) {
+		return notFoundResponse(req), nil
+	}
+	if hiddenTotalsPathPattern.MatchString(req.URL.Path) {
+		return nil, errHiddenAccountTotals
+	}
+	if strings.Contains(req.URL.Path, insightPath) && !hasAccountFilter(req.URL.Query()) {
+		limited, err := t.limitToVisibleAccounts(req)
+		if err != nil {
+			return nil, err
+		}
+		req = limited
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || req.Method != http.MethodGet || resp.StatusCode != http.StatusOK ||
+		!strings.Contains(resp.Header.Get("Content-Type"), "json") {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	filtered, found, changed := t.filter(body, accountListPathPattern.MatchString(req.URL.Path))
+	if !found {
+		return notFoundResponse(req), nil
+	}
+	if changed {
+		body = filtered
+		resp.Header.Del("Content-Length")
+		resp.ContentLength = int64(len(body))
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+// queriesHidden reports whether the accounts query parameter of a request, as
+// used by the insight and export endpoints, names a hidden account
+func (t *hiddenAccountsTransport) queriesHidden(query url.Values) bool {
+	for _, key := range []string{"accounts[]", "accounts"} {
+		for _, value := range query[key] {
+			for _, id := range strings.Split(value, ",") {
+				if t.hidden.contains(strings.TrimSpace(id)) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// hasAccountFilter reports whether a query already limits the accounts
+func hasAccountFilter(query url.Values) bool {
+	return len(query["accounts[]"]) > 0 || len(query["accounts"]) > 0
+}
+
+// limitToVisibleAccounts returns a copy of an insight request filtered by the
+// visible asset accounts and liabilities, so its totals leave hidden accounts out
+func (t *hiddenAccountsTransport) limitToVisibleAccounts(req *http.Request) (*http.Request, error) {
+	ids, err := t.visibleAccounts(req)
+	if err != nil {
+		return nil, fmt.Errorf("listing the visible accounts for %s: %w", req.URL.Path, err)
+	}
+	if len(ids) == 0 {
+		return nil, errors.New("accounts.hidden hides every asset account and liability")
+	}
+	limited := req.Clone(req.Context())
+	query := limited.URL.Query()
+	query["accounts[]"] = ids
+	limited.URL.RawQuery = query.Encode()
+	return limited, nil
+}
+
+// visibleAccounts lists the IDs of the asset accounts and liabilities that are
+// not hidden, with the credentials of req
+func (t *hiddenAccountsTransport) visibleAccounts(req *http.Request) ([]string, error) {
+	prefix := req.URL.Path[:strings.Index(req.URL.Path, insightPath)]
+	var ids []string
+	for _, accountType := range []string{"asset", "liabilities"} {
+		for page := 1; ; page++ {
+			listURL := *req.URL
+			listURL.Path = prefix + "/v1/accounts"
+			listURL.RawPath = ""
+			listURL.RawQuery = url.Values{"type": {accountType}, "page": {strconv.Itoa(page)}}.Encode()
+			listReq, err := http.NewRequestWithContext(req.Context(), http.MethodGet, listURL.String(), nil)
+			if err != nil {
+				return nil, err
+			}
+			listReq.Header = req.Header.Clone()
+
+			resp, err := t.base.RoundTrip(listReq)
+			if err != nil {
+				return nil, err
+			}
+			var list struct {
+				Data []struct {
+					Id string `json:"id"`
+				} `json:"data"`
+				Meta struct {
+					Pagination struct {
+						TotalPages int `json:"total_pages"`
+					} `json:"pagination"`
+				} `json:"meta"`
+			}
+			err = json.NewDecoder(resp.Body).Decode(&list)
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return nil, fmt.Errorf("API error: %d", resp.StatusCode)
+			}
+			if err != nil {
+				return nil, err
+			}
+			for _, account := range list.Data {
+				if !t.hidden.contains(account.Id) {
+					ids = append(ids, account.Id)
+				}
+			}
+			if page >= list.Meta.Pagination.TotalPages {
+				break
+			}
+		}
+	}
+	return ids, nil
+}
+
+// filter removes hidden resources from a JSON:API document, or hidden accounts
+// from a plain account list. It reports false when the document is a single
+// hidden resource, and whether anything was removed.
+func (t *hiddenAccountsTransport) filter(body []byte, accountList bool) ([]byte, bool, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return body, true, false
+	}
+
+	removed := 0
+	switch v := document.(type) {
+	case []any:
+		if !accountList {
+			return body, true, false
+		}
+		kept := v[:0]
+		for _, item := range v {
+			if entry, ok := item.(map[string]any); ok && t.hidden.contains(jsonString(entry["id"])) {
+				removed++
+				continue
+			}
+			kept = append(kept, item)
+		}
+		document = kept
+	case map[string]any:
+		switch data := v["data"].(type) {
+		case map[string]any:
+			if t.hiddenResource(data) {
+				return nil, false, false
+			}
+		case []any:
+			kept := data[:0]
+			for _, item := range data {
+				if resource, ok := item.(map[string]any); ok && t.hiddenResource(resource) {
+					removed++
+					continue
+				}
+				kept = append(kept, item)
+			}
+			v["data"] = kept
+			adjustPagination(v, removed)
+		}
+	}
+	if removed == 0 {
+		return body, true, false
+	}
+	encoded, err := json.Marshal(document)
+	if err != nil {
+		return body, true, false
+	}
+	return encoded, true, true
+}
+
+// hiddenAccountFields are the account ID fields of the nested lists of a
+// resource: the splits of transactions and recurrences, the accounts of piggy banks
+var hiddenAccountFields = map[string][]string{
+	"transactions": {"source_id", "destination_id"},
+	"accounts":     {"id", "account_id"},
+}
+
+// hiddenResource reports whether a JSON:API resource is a hidden account or
+// touches one: transactions and recurrences by the source or destination of a
+// split, piggy banks by their accounts
+func (t *hiddenAccountsTransport) hiddenResource(resource map[string]any) bool {
+	if resource["type"] == "accounts" && t.hidden.contains(jsonString(resource["id"])) {
+		return true
+	}
+	attributes, _ := resource["attributes"].(map[string]any)
+	if attributes == nil {
+		return false
+	}
+	if t.hidden.contains(jsonString(attributes["account_id"])) {
+		return true
+	}
+	for key, fields := range hiddenAccountFields {
+		items, _ := attributes[key].([]any)
+		for _, item := range items {
+			entry, _ := item.(map[string]any)
+			for _, field := range fields {
+				if t.hidden.contains(jsonString(entry[field])) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// adjustPagination lowers the count of a page by the removed resources. The
+// total is left as Firefly III reported it: the hidden resources on other
+// pages are unknown, so it cannot be corrected without reading every page.
+func adjustPagination(document map[string]any, removed int) {
+	meta, _ := document["meta"].(map[string]any)
+	pagination, _ := meta["pagination"].(map[string]any)
+	if value, err := strconv.Atoi(jsonString(pagination["count"])); err == nil {
+		pagination["count"] = max(value-removed, 0)
+	}
+}
+
+// jsonString returns a decoded JSON string or number as string
+func jsonString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	}
+	return ""
+}
+
+// notFoundResponse answers a request like Firefly III does for an unknown resource
+func notFoundResponse(req *http.Request) *http.Response {
+	body := `{"message":"Resource not found","exception":"NotFoundHttpException"}`
+	return &http.Response{
+		Status:        "404 Not Found",
+		StatusCode:    http.StatusNotFound,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
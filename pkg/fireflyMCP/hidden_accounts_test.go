@@ -0,0 +1,124 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHiddenAccounts(t *testing.T) {
+	var accountRequests, insightRequests int
+	var insightAccounts [][]string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch r.URL.Path {
+		case "/v1/accounts":
+			if r.URL.Query().Get("type") == "liabilities" {
+				w.Write([]byte(`{"data":[],"meta":{"pagination":{"total":0,"count":0,"per_page":50,"current_page":1,"total_pages":1}}}`))
+				return
+			}
+			w.Write([]byte(`{"data":[` +
+				`{"type":"accounts","id":"1","attributes":{"name":"Checking","type":"asset"}},` +
+				`{"type":"accounts","id":"2","attributes":{"name":"Partner checking","type":"asset"}}],` +
+				`"meta":{"pagination":{"total":2,"count":2,"per_page":50,"current_page":1,"total_pages":1}}}`))
+		case "/v1/accounts/2":
+			accountRequests++
+			w.Write([]byte(`{"data":{"type":"accounts","id":"2","attributes":{"name":"Partner checking","type":"asset"}}}`))
+		case "/v1/transactions":
+			w.Write([]byte(`{"data":[` +
+				`{"type":"transactions","id":"10","attributes":{"transactions":[{"type":"withdrawal","journal_id":"11",` +
+				`"amount":"5.00","description":"Coffee","source_id":"1","destination_id":"30"}]}},` +
+				`{"type":"transactions","id":"20","attributes":{"transactions":[{"type":"transfer","journal_id":"21",` +
+				`"amount":"100.00","description":"Gift","source_id":"1","destination_id":"2"}]}}],` +
+				`"meta":{"pagination":{"total":2,"count":2,"per_page":50,"current_page":1,"total_pages":1}}}`))
+		case "/v1/transactions/20":
+			w.Write([]byte(`{"data":{"type":"transactions","id":"20","attributes":{"transactions":[{"type":"transfer",` +
+				`"journal_id":"21","amount":"100.00","source_id":"1","destination_id":"2"}]}}}`))
+		case "/v1/autocomplete/accounts":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[{"id":"1","name":"Checking"},{"id":"2","name":"Partner checking"}]`))
+		case "/v1/insight/expense/asset":
+			insightRequests++
+			insightAccounts = append(insightAccounts, r.URL.Query()["accounts[]"])
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[{"id":"1","name":"Checking","difference":"-5.00","currency_code":"EUR"},` +
+				`{"id":"2","name":"Partner checking","difference":"-100.00","currency_code":"EUR"}]`))
+		case "/v1/insight/expense/category":
+			insightAccounts = append(insightAccounts, r.URL.Query()["accounts[]"])
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	config.Accounts.Aliases = map[string]string{"partner": "2"}
+	config.Accounts.Hidden = []string{"Partner"}
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	call := func(name, args string) *mcp.CallToolResult {
+		result, err := server.tools[name].invoke(context.Background(), nil, []byte(args))
+		require.NoError(t, err)
+		return result
+	}
+	text := func(result *mcp.CallToolResult) string {
+		return result.Content[0].(*mcp.TextContent).Text
+	}
+
+	var accounts AccountList
+	require.NoError(t, json.Unmarshal([]byte(text(call("list_accounts", `{}`))), &accounts))
+	require.Len(t, accounts.Data, 1)
+	assert.Equal(t, "1", accounts.Data[0].Id)
+	assert.Equal(t, 1, accounts.Pagination.Count)
+	assert.Equal(t, 2, accounts.Pagination.Total, "the total of all pages is left as reported")
+
+	assert.True(t, call("get_account", `{"id":"2"}`).IsError)
+	assert.Zero(t, accountRequests, "requests to hidden accounts never reach Firefly III")
+
+	var transactions TransactionList
+	require.NoError(t, json.Unmarshal([]byte(text(call("list_transactions", `{}`))), &transactions))
+	require.Len(t, transactions.Data, 1, "transfers to the hidden account are left out")
+	assert.Equal(t, "10", transactions.Data[0].Id)
+
+	assert.True(t, call("get_transaction", `{"id":"20"}`).IsError)
+
+	resp, err := server.httpClient.Get(ts.URL + "/v1/autocomplete/accounts")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var matches []map[string]string
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&matches))
+	assert.Equal(t, []map[string]string{{"id": "1", "name": "Checking"}}, matches)
+
+	var insights InsightCategoryResponse
+	result := call("expense_asset_insights", `{"start":"2024-01-01","end":"2024-01-31"}`)
+	require.False(t, result.IsError, text(result))
+	require.NoError(t, json.Unmarshal([]byte(text(result)), &insights))
+	require.Len(t, insights.Entries, 1)
+	assert.Equal(t, "1", insights.Entries[0].Id)
+
+	assert.True(t, call("expense_asset_insights", `{"start":"2024-01-01","end":"2024-01-31","accounts":["1","2"]}`).IsError)
+	assert.Equal(t, 1, insightRequests, "insights filtered by a hidden account never reach Firefly III")
+
+	result = call("expense_category_insights", `{"start":"2024-01-01","end":"2024-01-31"}`)
+	require.False(t, result.IsError, text(result))
+	assert.Equal(t, [][]string{{"1"}, {"1"}}, insightAccounts, "insights are limited to the visible accounts")
+
+	result = call("get_summary", `{"start":"2024-01-01","end":"2024-01-31"}`)
+	assert.True(t, result.IsError)
+	assert.Contains(t, text(result), "accounts.hidden")
+
+	config.Accounts.Hidden = nil
+	server.hiddenAccounts.set(config)
+	require.NoError(t, json.Unmarshal([]byte(text(call("list_accounts", `{}`))), &accounts))
+	assert.Len(t, accounts.Data, 2)
+}
@@ -0,0 +1,202 @@
+package fireflyMCP
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultMemberTagPrefix is used when household.tag_prefix is not configured
+const defaultMemberTagPrefix = "member:"
+
+// SpendByMemberArgs represents the arguments for the spend_by_member tool
+type SpendByMemberArgs struct {
+	DateRange
+}
+
+// MemberSpend is what one household member spent in one currency
+type MemberSpend struct {
+	Member       string        `json:"member,omitempty"` // Empty for spending without a member tag
+	CurrencyCode string        `json:"currency_code"`
+	Total        string        `json:"total"`
+	Count        int           `json:"count"`
+	Shared       int           `json:"shared"` // Withdrawals tagged with several members, split evenly between them
+	ByCategory   []ProjectLine `json:"by_category"`
+}
+
+// SpendByMemberReport is the result of the spend_by_member tool
+type SpendByMemberReport struct {
+	Start                 string        `json:"start"`
+	End                   string        `json:"end"`
+	TagPrefix             string        `json:"tag_prefix"`
+	Members               []MemberSpend `json:"members"`
+	Unattributed          []MemberSpend `json:"unattributed"`
+	TransactionsTruncated bool          `json:"transactions_truncated,omitempty"`
+}
+
+// memberTagPrefix returns the configured prefix of member tags
+func (s *FireflyMCPServer) memberTagPrefix() string {
+	if config := s.currentConfig(); config != nil && strings.TrimSpace(config.Household.TagPrefix) != "" {
+		return config.Household.TagPrefix
+	}
+	return defaultMemberTagPrefix
+}
+
+// memberTag returns the tag attributing a transaction to a household member.
+// Member names are lowercased so the same member always gets the same tag.
+func (s *FireflyMCPServer) memberTag(member string) (string, error) {
+	member = strings.ToLower(strings.TrimSpace(member))
+	if member == "" {
+		return "", fmt.Errorf("member must not be empty")
+	}
+	if config := s.currentConfig(); config != nil && len(config.Household.Members) > 0 {
+		known := false
+		for _, name := range config.Household.Members {
+			if strings.EqualFold(strings.TrimSpace(name), member) {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return "", fmt.Errorf("unknown household member %q (known: %s)", member, strings.Join(config.Household.Members, ", "))
+		}
+	}
+	return s.memberTagPrefix() + member, nil
+}
+
+// addSplitTag adds a tag to every split that does not carry it yet
+func addSplitTag(splits []TransactionSplitRequest, tag string) []TransactionSplitRequest {
+	tagged := make([]TransactionSplitRequest, len(splits))
+	for i, split := range splits {
+		present := false
+		for _, existing := range split.Tags {
+			if strings.EqualFold(existing, tag) {
+				present = true
+				break
+			}
+		}
+		if !present {
+			split.Tags = append(append([]string(nil), split.Tags...), tag)
+		}
+		tagged[i] = split
+	}
+	return tagged
+}
+
+// transactionMembers returns the members a transaction is attributed to by its tags
+func transactionMembers(tags []string, prefix string) []string {
+	var members []string
+	for _, tag := range tags {
+		if len(tag) > len(prefix) && strings.EqualFold(tag[:len(prefix)], prefix) {
+			members = append(members, strings.ToLower(tag[len(prefix):]))
+		}
+	}
+	return members
+}
+
+// handleSpendByMember reports the withdrawals of a period per household member
+func (s *FireflyMCPServer) handleSpendByMember(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args SpendByMemberArgs,
+) (*mcp.CallToolResult, any, error) {
+	dates, err := s.resolveDateRange(args.DateRange, dateRangeCurrentMonth)
+	if err != nil {
+		return newErrorResult(err.Error())
+	}
+
+	transactions, truncated, err := s.fetchTransactions(ctx, req, ListTransactionsArgs{
+		Type:      "withdrawal",
+		DateRange: DateRange{Start: dates.StartString(), End: dates.EndString()},
+	})
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error listing transactions: %v", err))
+	}
+
+	report := buildSpendByMember(transactions, s.memberTagPrefix())
+	report.Start = dates.StartString()
+	report.End = dates.EndString()
+	report.TransactionsTruncated = truncated
+	return newSuccessResult(report)
+}
+
+// buildSpendByMember sums withdrawals per member tag and currency. A withdrawal
+// tagged with several members is split evenly between them.
+func buildSpendByMember(transactions []Transaction, prefix string) *SpendByMemberReport {
+	type spendKey struct {
+		member   string
+		currency string
+	}
+	type spendTotals struct {
+		total      decimal
+		count      int
+		shared     int
+		byCategory map[string]*projectLineTotal
+	}
+	totals := make(map[spendKey]*spendTotals)
+
+	for _, transaction := range transactions {
+		if transaction.Type != "withdrawal" {
+			continue
+		}
+		members := transactionMembers(transaction.Tags, prefix)
+		share := parseAmount(transaction.Amount).Abs()
+		if len(members) > 1 {
+			share = share.Div(decimalFromInt(int64(len(members))))
+		}
+		if len(members) == 0 {
+			members = []string{""}
+		}
+		category := noCategoryLabel
+		if transaction.CategoryName != nil && *transaction.CategoryName != "" {
+			category = *transaction.CategoryName
+		}
+		for _, member := range members {
+			key := spendKey{member: member, currency: transaction.CurrencyCode}
+			entry := totals[key]
+			if entry == nil {
+				entry = &spendTotals{byCategory: make(map[string]*projectLineTotal)}
+				totals[key] = entry
+			}
+			entry.total = entry.total.Add(share)
+			entry.count++
+			if len(members) > 1 {
+				entry.shared++
+			}
+			addProjectLine(entry.byCategory, category, share)
+		}
+	}
+
+	keys := make([]spendKey, 0, len(totals))
+	for key := range totals {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(a, b int) bool {
+		if keys[a].member != keys[b].member {
+			return keys[a].member < keys[b].member
+		}
+		return keys[a].currency < keys[b].currency
+	})
+
+	report := &SpendByMemberReport{TagPrefix: prefix, Members: []MemberSpend{}, Unattributed: []MemberSpend{}}
+	for _, key := range keys {
+		entry := totals[key]
+		spend := MemberSpend{
+			Member:       key.member,
+			CurrencyCode: key.currency,
+			Total:        formatAmount(entry.total),
+			Count:        entry.count,
+			Shared:       entry.shared,
+			ByCategory:   sortedProjectLines(entry.byCategory),
+		}
+		if key.member == "" {
+			report.Unattributed = append(report.Unattributed, spend)
+		} else {
+			report.Members = append(report.Members, spend)
+		}
+	}
+	return report
+}
@@ -0,0 +1,119 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemberTag(t *testing.T) {
+	config := newPluginTestConfig()
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	tag, err := server.memberTag(" Alice ")
+	require.NoError(t, err)
+	assert.Equal(t, "member:alice", tag)
+
+	config.Household.TagPrefix = "who/"
+	config.Household.Members = []string{"Alice", "Bob"}
+	tag, err = server.memberTag("bob")
+	require.NoError(t, err)
+	assert.Equal(t, "who/bob", tag)
+
+	_, err = server.memberTag("carol")
+	assert.ErrorContains(t, err, "unknown household member")
+}
+
+func TestAddSplitTag(t *testing.T) {
+	original := []TransactionSplitRequest{{Tags: []string{"card"}}, {Tags: []string{"Member:Alice"}}}
+	tagged := addSplitTag(original, "member:alice")
+
+	assert.Equal(t, []string{"card", "member:alice"}, tagged[0].Tags)
+	assert.Equal(t, []string{"Member:Alice"}, tagged[1].Tags)
+	assert.Equal(t, []string{"card"}, original[0].Tags, "the request must not be modified")
+}
+
+func TestBuildSpendByMember(t *testing.T) {
+	groceries := "Groceries"
+	report := buildSpendByMember([]Transaction{
+		{Type: "withdrawal", Amount: "30.00", CurrencyCode: "EUR", Tags: []string{"member:alice"}, CategoryName: &groceries},
+		{Type: "withdrawal", Amount: "90.00", CurrencyCode: "EUR", Tags: []string{"member:Alice", "member:bob"}},
+		{Type: "withdrawal", Amount: "5.00", CurrencyCode: "EUR", Tags: []string{"card"}},
+		{Type: "deposit", Amount: "1000.00", CurrencyCode: "EUR", Tags: []string{"member:bob"}},
+	}, "member:")
+
+	require.Len(t, report.Members, 2)
+	assert.Equal(t, MemberSpend{
+		Member: "alice", CurrencyCode: "EUR", Total: "75.00", Count: 2, Shared: 1,
+		ByCategory: []ProjectLine{{Name: noCategoryLabel, Amount: "45.00", Count: 1}, {Name: "Groceries", Amount: "30.00", Count: 1}},
+	}, report.Members[0])
+	assert.Equal(t, "bob", report.Members[1].Member)
+	assert.Equal(t, "45.00", report.Members[1].Total)
+	require.Len(t, report.Unattributed, 1)
+	assert.Equal(t, "5.00", report.Unattributed[0].Total)
+}
+
+func TestStoreTransactionWithMember(t *testing.T) {
+	var body map[string]any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(data, &body))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"message":"The given data was invalid."}`))
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	_, _, err = server.handleStoreTransaction(context.Background(), nil, TransactionStoreRequest{
+		Member: "Alice",
+		Transactions: []TransactionSplitRequest{
+			{Type: "withdrawal", Date: "2024-01-15", Amount: "10.00", Description: "Coffee", Tags: []string{"card"}},
+		},
+	})
+	require.NoError(t, err)
+	splits := body["transactions"].([]any)
+	assert.Equal(t, []any{"card", "member:alice"}, splits[0].(map[string]any)["tags"])
+}
+
+func TestHandleSpendByMember(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "withdrawal", r.URL.Query().Get("type"))
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		w.Write([]byte(`{"data":[{"type":"transactions","id":"1","attributes":{"transactions":[` +
+			`{"transaction_journal_id":"1","type":"withdrawal","date":"2024-01-10T00:00:00Z","amount":"12.50",` +
+			`"currency_code":"EUR","description":"Cinema","source_id":"1","destination_id":"2","tags":["member:bob"]}]}}],` +
+			`"meta":{"pagination":{"total":1,"count":1,"per_page":200,"current_page":1,"total_pages":1}}}`))
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	result, _, err := server.handleSpendByMember(context.Background(), nil, SpendByMemberArgs{
+		DateRange: DateRange{Start: "2024-01-01", End: "2024-01-31"},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var report SpendByMemberReport
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &report))
+	assert.Equal(t, "member:", report.TagPrefix)
+	require.Len(t, report.Members, 1)
+	assert.Equal(t, "bob", report.Members[0].Member)
+	assert.Equal(t, "12.50", report.Members[0].Total)
+}
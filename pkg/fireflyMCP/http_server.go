@@ -32,8 +32,8 @@ func NewHTTPServer(mcpServer *FireflyMCPServer, config *Config, logger *slog.Log
 	}
 }
 
-// Start starts the HTTP server and blocks until the context is cancelled.
-func (s *HTTPServer) Start(ctx context.Context) error {
+// Handler returns the MCP endpoint with its middleware and the health endpoints.
+func (s *HTTPServer) Handler() http.Handler {
 	// Create Streamable HTTP handler from MCP SDK
 	handler := mcp.NewStreamableHTTPHandler(
 		func(r *http.Request) *mcp.Server {
@@ -41,6 +41,7 @@ func (s *HTTPServer) Start(ctx context.Context) error {
 		},
 		&mcp.StreamableHTTPOptions{
 			SessionTimeout: time.Duration(s.config.HTTP.SessionTimeout) * time.Second,
+			Stateless:      s.config.HTTP.Stateless,
 			Logger:         s.logger,
 		},
 	)
@@ -64,11 +65,15 @@ func (s *HTTPServer) Start(ctx context.Context) error {
 	mux.HandleFunc("/health", s.handleHealth)
 	mux.HandleFunc("/ready", s.handleReady)
 	mux.Handle("/", h)
+	return mux
+}
 
+// Start starts the HTTP server and blocks until the context is cancelled.
+func (s *HTTPServer) Start(ctx context.Context) error {
 	addr := fmt.Sprintf("%s:%d", s.config.HTTP.Host, s.config.HTTP.Port)
 	s.httpServer = &http.Server{
 		Addr:         addr,
-		Handler:      mux,
+		Handler:      s.Handler(),
 		ReadTimeout:  time.Duration(s.config.HTTP.ReadTimeout) * time.Second,
 		WriteTimeout: time.Duration(s.config.HTTP.WriteTimeout) * time.Second,
 		IdleTimeout:  time.Duration(s.config.HTTP.IdleTimeout) * time.Second,
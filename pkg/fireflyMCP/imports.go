@@ -0,0 +1,222 @@
+package fireflyMCP
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// importResourcePrefix is the URI prefix of import progress resources
+const importResourcePrefix = "firefly://imports/"
+
+// maxTrackedImports is the number of imports kept in memory. When exceeded,
+// the oldest finished imports are forgotten.
+const maxTrackedImports = 50
+
+// Import statuses reported by ImportProgress
+const (
+	ImportStatusRunning   = "running"
+	ImportStatusCompleted = "completed"
+)
+
+// ImportProgress is the content of an import progress resource
+type ImportProgress struct {
+	ID         string                   `json:"id"`
+	URI        string                   `json:"uri"`
+	Status     string                   `json:"status"`
+	Summary    BulkSummary              `json:"summary"`
+	Processed  int                      `json:"processed"`
+	StartedAt  string                   `json:"started_at"`
+	FinishedAt string                   `json:"finished_at,omitempty"`
+	Results    []TransactionGroupResult `json:"results"`
+}
+
+// importTracker keeps the progress of running and recently finished imports
+type importTracker struct {
+	mu    sync.Mutex
+	jobs  map[string]*ImportProgress
+	order []string // Import IDs, oldest first
+}
+
+func newImportTracker() *importTracker {
+	return &importTracker{jobs: make(map[string]*ImportProgress)}
+}
+
+// start registers a new import of total items and returns its progress
+func (t *importTracker) start(total int) (ImportProgress, error) {
+	id, err := newResourceID()
+	if err != nil {
+		return ImportProgress{}, err
+	}
+
+	job := &ImportProgress{
+		ID:        id,
+		URI:       importResourcePrefix + id,
+		Status:    ImportStatusRunning,
+		Summary:   BulkSummary{Total: total},
+		StartedAt: time.Now().UTC().Format(time.RFC3339),
+		Results:   make([]TransactionGroupResult, 0, total),
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.jobs[id] = job
+	t.order = append(t.order, id)
+	t.prune()
+	return *job, nil
+}
+
+// record adds the result of one item to an import
+func (t *importTracker) record(id string, result TransactionGroupResult) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	job, ok := t.jobs[id]
+	if !ok {
+		return
+	}
+	job.Results = append(job.Results, result)
+	job.Processed++
+	if result.Success {
+		job.Summary.Successful++
+	} else {
+		job.Summary.Failed++
+	}
+}
+
+// finish marks an import as completed
+func (t *importTracker) finish(id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if job, ok := t.jobs[id]; ok {
+		job.Status = ImportStatusCompleted
+		job.FinishedAt = time.Now().UTC().Format(time.RFC3339)
+	}
+}
+
+// get returns a snapshot of an import's progress
+func (t *importTracker) get(id string) (ImportProgress, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	job, ok := t.jobs[id]
+	if !ok {
+		return ImportProgress{}, false
+	}
+	snapshot := *job
+	snapshot.Results = append([]TransactionGroupResult(nil), job.Results...)
+	return snapshot, true
+}
+
+// prune forgets the oldest finished imports while more than maxTrackedImports
+// are tracked. Running imports are never pruned. Must be called with t.mu held.
+func (t *importTracker) prune() {
+	for i := 0; len(t.order) > maxTrackedImports && i < len(t.order); {
+		id := t.order[i]
+		if t.jobs[id].Status == ImportStatusRunning {
+			i++
+			continue
+		}
+		delete(t.jobs, id)
+		t.order = append(t.order[:i], t.order[i+1:]...)
+	}
+}
+
+// newResourceID returns a random, unguessable ID for an in-memory resource, so
+// clients sharing an HTTP server cannot enumerate each other's resources
+func newResourceID() (string, error) {
+	idBytes := make([]byte, 8)
+	if _, err := rand.Read(idBytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(idBytes), nil
+}
+
+// registerImportResources registers the import progress resource template
+func (s *FireflyMCPServer) registerImportResources() {
+	s.server.AddResourceTemplate(
+		&mcp.ResourceTemplate{
+			Name:        "import_progress",
+			Title:       "Import progress",
+			Description: "Progress of an asynchronous store_transactions_bulk import, updated as transaction groups are stored",
+			URITemplate: importResourcePrefix + "{id}",
+			MIMEType:    "application/json",
+		}, s.handleImportResource,
+	)
+}
+
+// handleImportResource returns the current progress of an import
+func (s *FireflyMCPServer) handleImportResource(
+	ctx context.Context,
+	req *mcp.ReadResourceRequest,
+) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	progress, ok := s.imports.get(strings.TrimPrefix(uri, importResourcePrefix))
+	if !ok {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	data, err := json.MarshalIndent(progress, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal import progress: %w", err)
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{URI: uri, MIMEType: "application/json", Text: string(data)},
+		},
+	}, nil
+}
+
+// startBulkImport stores transaction groups in the background and returns the
+// URI of the progress resource immediately. Subscribed clients are notified
+// after every stored group.
+func (s *FireflyMCPServer) startBulkImport(
+	req *mcp.CallToolRequest,
+	groups []TransactionStoreRequest,
+	delayMs int,
+) (*mcp.CallToolResult, any, error) {
+	progress, err := s.imports.start(len(groups))
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Failed to start import: %v", err))
+	}
+	// The journal records the import under the import ID
+	if err := s.journal.begin(progress.ID, s.tenantKey(req), "store_transactions_bulk", groups); err != nil {
+		s.imports.finish(progress.ID)
+		return newErrorResult(fmt.Sprintf("Error: failed to write journal: %v", err))
+	}
+
+	go s.runBulkImport(progress, req, groups, delayMs)
+
+	return newSuccessResult(progress)
+}
+
+// runBulkImport stores the groups of an import one by one, recording progress.
+// It runs detached from the tool call, so it is not cancelled when the call returns.
+func (s *FireflyMCPServer) runBulkImport(
+	progress ImportProgress,
+	req *mcp.CallToolRequest,
+	groups []TransactionStoreRequest,
+	delayMs int,
+) {
+	ctx := context.Background()
+	for i, group := range groups {
+		if i > 0 && delayMs > 0 {
+			time.Sleep(time.Duration(delayMs) * time.Millisecond)
+		}
+		s.imports.record(progress.ID, s.storeJournaledGroup(ctx, req, progress.ID, i, group))
+		s.notifyImportUpdated(progress.URI)
+	}
+	s.finishJournaledBatch(progress.ID)
+	s.imports.finish(progress.ID)
+	s.notifyImportUpdated(progress.URI)
+}
+
+// notifyImportUpdated notifies clients subscribed to an import progress resource
+func (s *FireflyMCPServer) notifyImportUpdated(uri string) {
+	_ = s.server.ResourceUpdated(context.Background(), &mcp.ResourceUpdatedNotificationParams{URI: uri})
+}
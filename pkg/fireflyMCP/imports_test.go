@@ -0,0 +1,108 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportTracker(t *testing.T) {
+	tracker := newImportTracker()
+
+	progress, err := tracker.start(2)
+	require.NoError(t, err)
+	assert.Equal(t, importResourcePrefix+progress.ID, progress.URI)
+	assert.Equal(t, ImportStatusRunning, progress.Status)
+
+	tracker.record(progress.ID, TransactionGroupResult{Index: 0, Success: true})
+	tracker.record(progress.ID, TransactionGroupResult{Index: 1, Error: "invalid"})
+	tracker.finish(progress.ID)
+
+	snapshot, ok := tracker.get(progress.ID)
+	require.True(t, ok)
+	assert.Equal(t, ImportStatusCompleted, snapshot.Status)
+	assert.Equal(t, 2, snapshot.Processed)
+	assert.Equal(t, BulkSummary{Total: 2, Successful: 1, Failed: 1}, snapshot.Summary)
+	assert.Len(t, snapshot.Results, 2)
+	assert.NotEmpty(t, snapshot.FinishedAt)
+
+	_, ok = tracker.get("unknown")
+	assert.False(t, ok)
+}
+
+func TestImportTracker_PrunesFinishedImports(t *testing.T) {
+	tracker := newImportTracker()
+
+	running, err := tracker.start(1)
+	require.NoError(t, err)
+	for i := 0; i < maxTrackedImports+5; i++ {
+		progress, err := tracker.start(1)
+		require.NoError(t, err)
+		tracker.finish(progress.ID)
+	}
+
+	assert.Len(t, tracker.jobs, maxTrackedImports)
+	_, ok := tracker.get(running.ID)
+	assert.True(t, ok, "running imports must not be pruned")
+}
+
+func TestAsyncBulkImport(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"message":"The given data was invalid."}`))
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	group := TransactionStoreRequest{
+		Transactions: []TransactionSplitRequest{
+			{Type: "withdrawal", Date: "2024-01-15", Amount: "10.00", Description: "Coffee"},
+		},
+	}
+	result, _, err := server.handleStoreTransactionsBulk(context.Background(), nil, BulkTransactionStoreRequest{
+		TransactionGroups: []TransactionStoreRequest{group, group},
+		DelayMs:           1,
+		Async:             true,
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var started ImportProgress
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &started))
+	assert.True(t, strings.HasPrefix(started.URI, "firefly://imports/"))
+
+	var progress ImportProgress
+	require.Eventually(t, func() bool {
+		resource, err := server.handleImportResource(context.Background(), &mcp.ReadResourceRequest{
+			Params: &mcp.ReadResourceParams{URI: started.URI},
+		})
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal([]byte(resource.Contents[0].Text), &progress))
+		return progress.Status == ImportStatusCompleted
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, BulkSummary{Total: 2, Failed: 2}, progress.Summary)
+	assert.Contains(t, progress.Results[0].Error, "The given data was invalid.")
+}
+
+func TestImportResourceNotFound(t *testing.T) {
+	server := &FireflyMCPServer{imports: newImportTracker()}
+
+	_, err := server.handleImportResource(context.Background(), &mcp.ReadResourceRequest{
+		Params: &mcp.ReadResourceParams{URI: "firefly://imports/missing"},
+	})
+	assert.Error(t, err)
+}
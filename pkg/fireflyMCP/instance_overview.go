@@ -0,0 +1,165 @@
+package fireflyMCP
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dezer32/mcp-firefly-iii/pkg/client"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// largeInstanceTransactions is the transaction count from which instance_overview
+// advises against fetching all transactions
+const largeInstanceTransactions = 10000
+
+// InstanceOverviewArgs represents the arguments for the instance_overview tool
+type InstanceOverviewArgs struct{}
+
+// InstanceOverview describes the size of a Firefly III instance. Counts that
+// could not be read are null and explained in Warnings.
+type InstanceOverview struct {
+	Accounts          *int     `json:"accounts"`
+	Transactions      *int     `json:"transactions"`
+	Budgets           *int     `json:"budgets"`
+	Rules             *int     `json:"rules"`
+	Bills             *int     `json:"bills"`
+	OldestTransaction *string  `json:"oldest_transaction"`
+	NewestTransaction *string  `json:"newest_transaction"`
+	Note              string   `json:"note,omitempty"`
+	Warnings          []string `json:"warnings,omitempty"`
+}
+
+// handleInstanceOverview counts the main objects of the instance using one-item
+// pages, whose pagination meta holds the total
+func (s *FireflyMCPServer) handleInstanceOverview(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args InstanceOverviewArgs,
+) (*mcp.CallToolResult, any, error) {
+	apiClient, err := s.getClient(ctx, req)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Failed to get API client: %v", err))
+	}
+
+	overview := &InstanceOverview{}
+	one := int32(1)
+	counts := []struct {
+		name   string
+		target **int
+		fetch  func() (*client.Meta, error)
+	}{
+		{"accounts", &overview.Accounts, func() (*client.Meta, error) {
+			resp, err := apiClient.ListAccountWithResponse(ctx, &client.ListAccountParams{Limit: &one})
+			if err != nil {
+				return nil, err
+			}
+			if resp.StatusCode() != 200 || resp.ApplicationvndApiJSON200 == nil {
+				return nil, fmt.Errorf("API error %d - %s", resp.StatusCode(), s.upstreamError(resp.Body))
+			}
+			return &resp.ApplicationvndApiJSON200.Meta, nil
+		}},
+		{"budgets", &overview.Budgets, func() (*client.Meta, error) {
+			resp, err := apiClient.ListBudgetWithResponse(ctx, &client.ListBudgetParams{Limit: &one})
+			if err != nil {
+				return nil, err
+			}
+			if resp.StatusCode() != 200 || resp.ApplicationvndApiJSON200 == nil {
+				return nil, fmt.Errorf("API error %d - %s", resp.StatusCode(), s.upstreamError(resp.Body))
+			}
+			return &resp.ApplicationvndApiJSON200.Meta, nil
+		}},
+		{"rules", &overview.Rules, func() (*client.Meta, error) {
+			resp, err := apiClient.ListRuleWithResponse(ctx, &client.ListRuleParams{Limit: &one})
+			if err != nil {
+				return nil, err
+			}
+			if resp.StatusCode() != 200 || resp.ApplicationvndApiJSON200 == nil {
+				return nil, fmt.Errorf("API error %d - %s", resp.StatusCode(), s.upstreamError(resp.Body))
+			}
+			return &resp.ApplicationvndApiJSON200.Meta, nil
+		}},
+		{"bills", &overview.Bills, func() (*client.Meta, error) {
+			resp, err := apiClient.ListBillWithResponse(ctx, &client.ListBillParams{Limit: &one})
+			if err != nil {
+				return nil, err
+			}
+			if resp.StatusCode() != 200 || resp.ApplicationvndApiJSON200 == nil {
+				return nil, fmt.Errorf("API error %d - %s", resp.StatusCode(), s.upstreamError(resp.Body))
+			}
+			return &resp.ApplicationvndApiJSON200.Meta, nil
+		}},
+	}
+	for _, count := range counts {
+		meta, err := count.fetch()
+		if err != nil {
+			overview.Warnings = append(overview.Warnings, fmt.Sprintf("Error counting %s: %v", count.name, err))
+			continue
+		}
+		*count.target = paginationTotal(meta)
+	}
+
+	// Transactions are sorted newest first: the first one-item page holds the
+	// newest transaction and the total, the last page the oldest one
+	newest, total, err := s.transactionPage(ctx, apiClient, 1)
+	if err != nil {
+		overview.Warnings = append(overview.Warnings, fmt.Sprintf("Error counting transactions: %v", err))
+		return newSuccessResult(overview)
+	}
+	overview.Transactions = &total
+	if newest != nil {
+		date := newest.Date.Format("2006-01-02")
+		overview.NewestTransaction = &date
+		overview.OldestTransaction = &date
+	}
+	if total > 1 {
+		oldest, _, err := s.transactionPage(ctx, apiClient, total)
+		if err != nil {
+			overview.Warnings = append(overview.Warnings, fmt.Sprintf("Error reading the oldest transaction: %v", err))
+		} else if oldest != nil {
+			date := oldest.Date.Format("2006-01-02")
+			overview.OldestTransaction = &date
+		}
+	}
+
+	if total >= largeInstanceTransactions {
+		overview.Note = fmt.Sprintf("Large instance (%d transactions): use date ranges, search_transactions or "+
+			"insight tools instead of paging through all transactions", total)
+	}
+	return newSuccessResult(overview)
+}
+
+// paginationTotal returns the total number of items of a list response
+func paginationTotal(meta *client.Meta) *int {
+	if meta == nil || meta.Pagination == nil || meta.Pagination.Total == nil {
+		return nil
+	}
+	total := *meta.Pagination.Total
+	return &total
+}
+
+// transactionPage fetches a single transaction (page of size one) of all
+// transactions and returns it together with the total number of transactions
+func (s *FireflyMCPServer) transactionPage(
+	ctx context.Context,
+	apiClient *client.ClientWithResponses,
+	page int,
+) (*Transaction, int, error) {
+	limit := int32(1)
+	pageNumber := int32(page)
+	resp, err := apiClient.ListTransactionWithResponse(ctx, &client.ListTransactionParams{
+		Limit: &limit,
+		Page:  &pageNumber,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.StatusCode() != 200 {
+		return nil, 0, fmt.Errorf("API error %d - %s", resp.StatusCode(), s.upstreamError(resp.Body))
+	}
+
+	list := mapTransactionArrayToTransactionList(resp.ApplicationvndApiJSON200)
+	if list == nil || len(list.Data) == 0 || len(list.Data[0].Transactions) == 0 {
+		return nil, 0, nil
+	}
+	return &list.Data[0].Transactions[0], list.Pagination.Total, nil
+}
@@ -0,0 +1,65 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstanceOverview(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "1", r.URL.Query().Get("limit"))
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch r.URL.Path {
+		case "/v1/accounts":
+			fmt.Fprint(w, `{"data":[],"meta":{"pagination":{"total":12}}}`)
+		case "/v1/budgets":
+			fmt.Fprint(w, `{"data":[],"meta":{"pagination":{"total":4}}}`)
+		case "/v1/rules":
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"message":"boom"}`)
+		case "/v1/bills":
+			fmt.Fprint(w, `{"data":[],"meta":{"pagination":{"total":0}}}`)
+		case "/v1/transactions":
+			date := "2024-03-01"
+			if r.URL.Query().Get("page") == "25000" {
+				date = "2015-01-02"
+			}
+			fmt.Fprintf(w, `{"data":[{"type":"transactions","id":"1","attributes":{"transactions":[`+
+				`{"type":"withdrawal","date":"%sT00:00:00Z","amount":"1","description":"x","source_id":"1","destination_id":"2"}]}}],`+
+				`"meta":{"pagination":{"total":25000}}}`, date)
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	result, _, err := server.handleInstanceOverview(context.Background(), nil, InstanceOverviewArgs{})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	var overview InstanceOverview
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &overview))
+
+	assert.Equal(t, ptr(12), overview.Accounts)
+	assert.Equal(t, ptr(4), overview.Budgets)
+	assert.Equal(t, ptr(0), overview.Bills)
+	assert.Nil(t, overview.Rules)
+	assert.Equal(t, ptr(25000), overview.Transactions)
+	assert.Equal(t, strPtr("2024-03-01"), overview.NewestTransaction)
+	assert.Equal(t, strPtr("2015-01-02"), overview.OldestTransaction)
+	require.Len(t, overview.Warnings, 1)
+	assert.Contains(t, overview.Warnings[0], "Error counting rules")
+	assert.Contains(t, overview.Note, "Large instance")
+}
@@ -0,0 +1,265 @@
+package fireflyMCP
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/dezer32/mcp-firefly-iii/pkg/client"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// Limits of the liability_schedule projection
+const (
+	defaultLiabilityMonths       = 12   // Months of payment history the average is taken over
+	maxLiabilityProjectionMonths = 1200 // Projections end after 100 years
+	maxLiabilityProjectionShown  = 120  // Projected months listed in the result
+)
+
+// LiabilityScheduleArgs represents the arguments for the liability_schedule tool
+type LiabilityScheduleArgs struct {
+	ID     string `json:"id" jsonschema:"Liability account ID or configured account alias"`
+	Months int    `json:"months,omitempty" jsonschema:"Number of recent months of payments the average payment is taken over (default: 12)"`
+}
+
+// LiabilityPayment is a payment into a liability
+type LiabilityPayment struct {
+	Date         string `json:"date"`
+	Amount       string `json:"amount"`
+	CurrencyCode string `json:"currency_code"`
+	SourceName   string `json:"source_name"`
+	Description  string `json:"description"`
+	JournalId    string `json:"journal_id"`
+}
+
+// LiabilityMonth is the sum of the payments of one month
+type LiabilityMonth struct {
+	Month  string `json:"month"` // YYYY-MM
+	Amount string `json:"amount"`
+	Count  int    `json:"count"`
+}
+
+// LiabilityProjection is a projected payment and the outstanding amount after it
+type LiabilityProjection struct {
+	Month       string `json:"month"` // YYYY-MM
+	Payment     string `json:"payment"`
+	Outstanding string `json:"outstanding"`
+}
+
+// LiabilitySchedule is the result of the liability_schedule tool
+type LiabilitySchedule struct {
+	AccountId           string                `json:"account_id"`
+	AccountName         string                `json:"account_name"`
+	LiabilityType       string                `json:"liability_type,omitempty"`
+	LiabilityDirection  string                `json:"liability_direction,omitempty"`
+	Interest            string                `json:"interest,omitempty"`
+	InterestPeriod      string                `json:"interest_period,omitempty"`
+	CurrencyCode        string                `json:"currency_code"`
+	CurrentBalance      string                `json:"current_balance"`
+	Outstanding         string                `json:"outstanding"` // Absolute current balance
+	Period              *AccountStatsPeriod   `json:"period"`
+	Payments            []LiabilityPayment    `json:"payments"`
+	PaymentsTruncated   bool                  `json:"payments_truncated,omitempty"`
+	MonthlyPayments     []LiabilityMonth      `json:"monthly_payments"`
+	AveragePayment      string                `json:"average_payment"` // Payments of the period divided by its months
+	RemainingPayments   *int                  `json:"remaining_payments,omitempty"`
+	PayoffMonth         string                `json:"payoff_month,omitempty"` // YYYY-MM of the last projected payment
+	Projection          []LiabilityProjection `json:"projection,omitempty"`
+	ProjectionTruncated bool                  `json:"projection_truncated,omitempty"`
+	Notes               []string              `json:"notes,omitempty"`
+}
+
+// handleLiabilitySchedule lists the payments into a liability over the last
+// months and projects the remaining payments at the average monthly payment
+func (s *FireflyMCPServer) handleLiabilitySchedule(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args LiabilityScheduleArgs,
+) (*mcp.CallToolResult, any, error) {
+	if args.ID == "" {
+		return newErrorResult("Account ID is required")
+	}
+	months := args.Months
+	if months <= 0 {
+		months = defaultLiabilityMonths
+	}
+	accountID := s.resolveAccountRef(args.ID)
+
+	apiClient, err := s.getClient(ctx, req)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Failed to get API client: %v", err))
+	}
+
+	accountResp, err := apiClient.GetAccountWithResponse(ctx, accountID, &client.GetAccountParams{})
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error getting account: %v", err))
+	}
+	if accountResp.StatusCode() != 200 || accountResp.ApplicationvndApiJSON200 == nil {
+		return newErrorResult(fmt.Sprintf("API error: %d", accountResp.StatusCode()))
+	}
+	attributes := accountResp.ApplicationvndApiJSON200.Data.Attributes
+	if attributes.Type != client.ShortAccountTypePropertyLiability && attributes.Type != "liabilities" {
+		return newErrorResult(fmt.Sprintf("Account %s is a %s account, not a liability", accountID, attributes.Type))
+	}
+
+	schedule := &LiabilitySchedule{
+		AccountId:      accountResp.ApplicationvndApiJSON200.Data.Id,
+		AccountName:    attributes.Name,
+		Interest:       getStringValue(attributes.Interest),
+		CurrencyCode:   getStringValue(attributes.CurrencyCode),
+		CurrentBalance: getStringValue(attributes.CurrentBalance),
+	}
+	if attributes.LiabilityType != nil {
+		schedule.LiabilityType = string(*attributes.LiabilityType)
+	}
+	if attributes.LiabilityDirection != nil {
+		schedule.LiabilityDirection = string(*attributes.LiabilityDirection)
+	}
+	if attributes.InterestPeriod != nil {
+		schedule.InterestPeriod = string(*attributes.InterestPeriod)
+	}
+	outstanding := parseAmount(schedule.CurrentBalance).Abs()
+	schedule.Outstanding = formatAmount(outstanding)
+
+	// A young liability is averaged over its lifetime, which starts with its oldest transaction
+	now := s.now()
+	firstActivity := now
+	if newest, count, err := s.accountTransactionPage(ctx, apiClient, accountID, 1); err != nil {
+		return newErrorResult(err.Error())
+	} else if newest != nil {
+		oldest := newest
+		if count > 1 {
+			if oldest, _, err = s.accountTransactionPage(ctx, apiClient, accountID, count); err != nil {
+				return newErrorResult(err.Error())
+			}
+		}
+		if oldest != nil {
+			firstActivity = oldest.Date
+		}
+	}
+	schedule.Period = accountStatsPeriod(firstActivity, now, months)
+	periodStart, _ := time.ParseInLocation("2006-01-02", schedule.Period.Start, now.Location())
+	transactions, truncated, err := s.accountTransactionsBetween(ctx, apiClient, accountID, periodStart, now)
+	if err != nil {
+		return newErrorResult(err.Error())
+	}
+	schedule.PaymentsTruncated = truncated
+
+	total := decimalFromInt(0)
+	byMonth := map[string]*LiabilityMonth{}
+	otherCurrencies := 0
+	schedule.Payments = []LiabilityPayment{}
+	for _, transaction := range transactions {
+		if transaction.DestinationId != schedule.AccountId || transaction.SourceId == schedule.AccountId ||
+			transaction.Type == "opening balance" || transaction.Type == "reconciliation" {
+			continue
+		}
+		if schedule.CurrencyCode != "" && transaction.CurrencyCode != "" && transaction.CurrencyCode != schedule.CurrencyCode {
+			otherCurrencies++
+			continue
+		}
+		amount := parseAmount(transaction.Amount).Abs()
+		schedule.Payments = append(schedule.Payments, LiabilityPayment{
+			Date:         transaction.Date.Format("2006-01-02"),
+			Amount:       formatAmount(amount),
+			CurrencyCode: transaction.CurrencyCode,
+			SourceName:   transaction.SourceName,
+			Description:  transaction.Description,
+			JournalId:    transaction.JournalId,
+		})
+		total = total.Add(amount)
+		month := transaction.Date.Format("2006-01")
+		if byMonth[month] == nil {
+			byMonth[month] = &LiabilityMonth{Month: month, Amount: "0"}
+		}
+		byMonth[month].Amount = formatAmount(parseAmount(byMonth[month].Amount).Add(amount))
+		byMonth[month].Count++
+	}
+	sort.Slice(schedule.Payments, func(i, j int) bool { return schedule.Payments[i].Date < schedule.Payments[j].Date })
+	schedule.MonthlyPayments = make([]LiabilityMonth, 0, len(byMonth))
+	for _, month := range byMonth {
+		schedule.MonthlyPayments = append(schedule.MonthlyPayments, *month)
+	}
+	sort.Slice(schedule.MonthlyPayments, func(i, j int) bool {
+		return schedule.MonthlyPayments[i].Month < schedule.MonthlyPayments[j].Month
+	})
+	if otherCurrencies > 0 {
+		schedule.Notes = append(schedule.Notes,
+			fmt.Sprintf("%d payments in other currencies than %s are left out", otherCurrencies, schedule.CurrencyCode))
+	}
+	if truncated {
+		schedule.Notes = append(schedule.Notes, "Only the newest transactions were read; the average may be too low")
+	}
+
+	average := total.Div(decimalFromInt(int64(schedule.Period.Months)))
+	schedule.AveragePayment = formatAmount(average)
+	switch {
+	case outstanding.Sign() == 0:
+		schedule.Notes = append(schedule.Notes, "Nothing is outstanding")
+		return newSuccessResult(schedule)
+	case average.Sign() == 0:
+		schedule.Notes = append(schedule.Notes, "No payments in the period, so no payoff can be projected")
+		return newSuccessResult(schedule)
+	}
+
+	// Payments continue from next month at the average payment; interest is not modelled
+	remaining := outstanding
+	count := 0
+	for ; remaining.Sign() > 0 && count < maxLiabilityProjectionMonths; count++ {
+		payment := average.Min(remaining)
+		remaining = remaining.Sub(payment)
+		month := now.AddDate(0, count+1, 1-now.Day()).Format("2006-01")
+		schedule.PayoffMonth = month
+		if count < maxLiabilityProjectionShown {
+			schedule.Projection = append(schedule.Projection, LiabilityProjection{
+				Month: month, Payment: formatAmount(payment), Outstanding: formatAmount(remaining),
+			})
+		}
+	}
+	if remaining.Sign() > 0 {
+		schedule.PayoffMonth = ""
+		schedule.Notes = append(schedule.Notes, "Not paid off within 100 years at the average payment")
+		return newSuccessResult(schedule)
+	}
+	schedule.RemainingPayments = &count
+	schedule.ProjectionTruncated = count > maxLiabilityProjectionShown
+	schedule.Notes = append(schedule.Notes, "The projection ignores interest and assumes the average payment continues every month")
+	return newSuccessResult(schedule)
+}
+
+// accountTransactionsBetween returns the splits of an account's transactions
+// in a date range, newest first, and whether the listing was truncated
+func (s *FireflyMCPServer) accountTransactionsBetween(
+	ctx context.Context,
+	apiClient *client.ClientWithResponses,
+	accountID string,
+	start, end time.Time,
+) ([]Transaction, bool, error) {
+	return fetchPages(ctx, s.pageParallelism(), func(ctx context.Context, page int) ([]Transaction, int, error) {
+		limit := int32(compositePageSize)
+		pageNumber := int32(page)
+		resp, err := apiClient.ListTransactionByAccountWithResponse(ctx, accountID, &client.ListTransactionByAccountParams{
+			Limit: &limit,
+			Page:  &pageNumber,
+			Start: &openapi_types.Date{Time: start},
+			End:   &openapi_types.Date{Time: end},
+		})
+		if err != nil {
+			return nil, 0, fmt.Errorf("Error listing account transactions: %v", err)
+		}
+		if resp.StatusCode() != 200 {
+			return nil, 0, fmt.Errorf("API error: %d", resp.StatusCode())
+		}
+		list := mapTransactionArrayToTransactionList(resp.ApplicationvndApiJSON200)
+		if list == nil {
+			return nil, 0, nil
+		}
+		var transactions []Transaction
+		for _, group := range list.Data {
+			transactions = append(transactions, group.Transactions...)
+		}
+		return transactions, list.Pagination.TotalPages, nil
+	})
+}
@@ -0,0 +1,129 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLiabilitySchedule(t *testing.T) {
+	now := time.Now()
+	base := time.Date(now.Year(), now.Month(), min(now.Day(), 28), 0, 0, 0, 0, now.Location())
+	day := func(months int) string { return base.AddDate(0, months, 0).Format("2006-01-02") }
+	split := func(kind, date, amount, currency, source, destination string) string {
+		return fmt.Sprintf(`{"type":%q,"date":"%sT12:00:00+00:00","amount":%q,"currency_code":%q,"description":"Loan",`+
+			`"source_id":%q,"source_name":"Account %s","destination_id":%q,"destination_name":"Account %s"}`,
+			kind, date, amount, currency, source, source, destination, destination)
+	}
+	splits := []string{
+		split("transfer", day(0), "200.00", "EUR", "1", "45"),
+		split("withdrawal", day(0), "15.00", "EUR", "45", "9"),
+		split("transfer", day(-1), "200.00", "EUR", "1", "45"),
+		split("transfer", day(-1), "50.00", "USD", "2", "45"),
+		split("deposit", day(-2), "200.00", "EUR", "8", "45"),
+		split("opening balance", day(-2), "1500.00", "EUR", "45", "10"),
+	}
+	page := func(splits []string, total int) string {
+		groups := make([]string, len(splits))
+		for i, s := range splits {
+			groups[i] = fmt.Sprintf(`{"type":"transactions","id":"%d","attributes":{"transactions":[%s]}}`, i+1, s)
+		}
+		return fmt.Sprintf(`{"data":[%s],"meta":{"pagination":{"total":%d,"count":%d,"per_page":50,"current_page":1,"total_pages":1}}}`,
+			strings.Join(groups, ","), total, len(splits))
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch r.URL.Path {
+		case "/v1/accounts/45":
+			w.Write([]byte(`{"data":{"type":"accounts","id":"45","attributes":{"name":"Car loan","type":"liabilities",` +
+				`"liability_type":"loan","liability_direction":"credit","current_balance":"-1000.00","currency_code":"EUR"}}}`))
+		case "/v1/accounts/1":
+			w.Write([]byte(`{"data":{"type":"accounts","id":"1","attributes":{"name":"Checking","type":"asset"}}}`))
+		case "/v1/accounts/45/transactions":
+			switch {
+			case r.URL.Query().Get("limit") != "1":
+				w.Write([]byte(page(splits, len(splits))))
+			case r.URL.Query().Get("page") == "1":
+				w.Write([]byte(page(splits[:1], len(splits))))
+			default:
+				w.Write([]byte(page(splits[len(splits)-1:], len(splits))))
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	result, err := server.tools["liability_schedule"].invoke(context.Background(), nil, []byte(`{"id":"45"}`))
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content[0].(*mcp.TextContent).Text)
+	var schedule LiabilitySchedule
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &schedule))
+
+	assert.Equal(t, "loan", schedule.LiabilityType)
+	assert.Equal(t, "1000.00", schedule.Outstanding)
+	assert.Equal(t, day(-2), schedule.Period.Start, "young liabilities are averaged over their lifetime")
+	assert.Equal(t, 3, schedule.Period.Months)
+	require.Len(t, schedule.Payments, 3, "withdrawals, opening balances and other currencies are not payments")
+	assert.Equal(t, day(-2), schedule.Payments[0].Date)
+	assert.Len(t, schedule.MonthlyPayments, 3)
+	assert.Equal(t, "200.00", schedule.AveragePayment)
+	require.NotNil(t, schedule.RemainingPayments)
+	assert.Equal(t, 5, *schedule.RemainingPayments)
+	assert.Equal(t, now.AddDate(0, 5, 1-now.Day()).Format("2006-01"), schedule.PayoffMonth)
+	require.Len(t, schedule.Projection, 5)
+	assert.Equal(t, "800.00", schedule.Projection[0].Outstanding)
+	assert.Equal(t, "0.00", schedule.Projection[4].Outstanding)
+	assert.Contains(t, strings.Join(schedule.Notes, "\n"), "1 payments in other currencies")
+
+	result, err = server.tools["liability_schedule"].invoke(context.Background(), nil, []byte(`{"id":"1"}`))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "not a liability")
+}
+
+func TestLiabilitySchedule_NoPayments(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch r.URL.Path {
+		case "/v1/accounts/45":
+			w.Write([]byte(`{"data":{"type":"accounts","id":"45","attributes":{"name":"Mortgage","type":"liabilities",` +
+				`"current_balance":"-250000.00","currency_code":"EUR"}}}`))
+		case "/v1/accounts/45/transactions":
+			w.Write([]byte(`{"data":[],"meta":{"pagination":{"total":0,"count":0,"per_page":50,"current_page":1,"total_pages":1}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	result, err := server.tools["liability_schedule"].invoke(context.Background(), nil, []byte(`{"id":"45"}`))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	var schedule LiabilitySchedule
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &schedule))
+	assert.Equal(t, "0.00", schedule.AveragePayment)
+	assert.Nil(t, schedule.RemainingPayments)
+	assert.Empty(t, schedule.PayoffMonth)
+	assert.Contains(t, schedule.Notes, "No payments in the period, so no payoff can be projected")
+}
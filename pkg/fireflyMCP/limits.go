@@ -0,0 +1,66 @@
+package fireflyMCP
+
+// toolDefaultLimits maps list and search tools to the configured default page size.
+// Each tool family gets a default matching its typical intent: small catalogs
+// (categories, tags) are fetched almost completely, while potentially huge
+// result sets (transactions, search) are kept small to save context.
+var toolDefaultLimits = map[string]func(c *Config) int{
+	"list_accounts":                func(c *Config) int { return c.Limits.Accounts },
+	"search_accounts":              func(c *Config) int { return c.Limits.Search },
+	"list_transactions":            func(c *Config) int { return c.Limits.Transactions },
+	"search_transactions":          func(c *Config) int { return c.Limits.Search },
+	"list_budgets":                 func(c *Config) int { return c.Limits.Budgets },
+	"list_budget_transactions":     func(c *Config) int { return c.Limits.Transactions },
+	"list_categories":              func(c *Config) int { return c.Limits.Categories },
+	"list_tags":                    func(c *Config) int { return c.Limits.Tags },
+	"list_bills":                   func(c *Config) int { return c.Limits.Bills },
+	"list_bill_transactions":       func(c *Config) int { return c.Limits.Transactions },
+	"list_recurrences":             func(c *Config) int { return c.Limits.Recurrences },
+	"list_recurrence_transactions": func(c *Config) int { return c.Limits.Transactions },
+	"list_rule_groups":             func(c *Config) int { return c.Limits.Rules },
+	"list_rules_by_group":          func(c *Config) int { return c.Limits.Rules },
+	"list_rules":                   func(c *Config) int { return c.Limits.Rules },
+	"autocomplete":                 func(c *Config) int { return c.Limits.Search },
+}
+
+// defaultLimit returns the configured default page size for a tool, or 0 if the
+// tool has no default (in which case Firefly III's own default applies).
+// The default is capped by tools.<name>.max_limit.
+func (s *FireflyMCPServer) defaultLimit(tool string) int {
+	config := s.currentConfig()
+	if config == nil {
+		return 0
+	}
+	if get, ok := toolDefaultLimits[tool]; ok {
+		return s.capLimit(tool, get(config))
+	}
+	return 0
+}
+
+// limitFor returns the requested limit if set, otherwise the tool's default
+// limit, capped by tools.<name>.max_limit
+func (s *FireflyMCPServer) limitFor(tool string, requested int) int {
+	if requested > 0 {
+		return s.capLimit(tool, requested)
+	}
+	return s.defaultLimit(tool)
+}
+
+// capLimit lowers a limit to the configured tools.<name>.max_limit
+func (s *FireflyMCPServer) capLimit(tool string, limit int) int {
+	if override, ok := s.toolOverride(tool); ok && override.MaxLimit > 0 && limit > override.MaxLimit {
+		return override.MaxLimit
+	}
+	return limit
+}
+
+// limitParam returns the effective limit for a tool as an API parameter.
+// Returns nil if neither a requested nor a default limit is available.
+func (s *FireflyMCPServer) limitParam(tool string, requested int) *int32 {
+	limit := s.limitFor(tool, requested)
+	if limit <= 0 {
+		return nil
+	}
+	limit32 := int32(limit)
+	return &limit32
+}
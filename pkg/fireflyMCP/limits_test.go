@@ -0,0 +1,67 @@
+package fireflyMCP
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimitFor(t *testing.T) {
+	config := &Config{}
+	config.Limits.Transactions = 50
+	config.Limits.Categories = 1000
+	config.Limits.Search = 25
+	server := &FireflyMCPServer{config: config}
+
+	// Configured defaults are used when no limit is requested
+	assert.Equal(t, 50, server.limitFor("list_transactions", 0))
+	assert.Equal(t, 50, server.limitFor("list_budget_transactions", 0))
+	assert.Equal(t, 1000, server.limitFor("list_categories", 0))
+	assert.Equal(t, 25, server.limitFor("search_transactions", 0))
+
+	// An explicit limit always wins
+	assert.Equal(t, 7, server.limitFor("list_transactions", 7))
+
+	// Tools without a default fall back to Firefly III's own default
+	assert.Equal(t, 0, server.limitFor("get_account", 0))
+	assert.Nil(t, server.limitParam("get_account", 0))
+
+	limit := server.limitParam("search_accounts", 0)
+	require.NotNil(t, limit)
+	assert.Equal(t, int32(25), *limit)
+}
+
+func TestLimitForWithoutConfig(t *testing.T) {
+	server := &FireflyMCPServer{}
+
+	assert.Equal(t, 0, server.limitFor("list_transactions", 0))
+	assert.Equal(t, 10, server.limitFor("list_transactions", 10))
+}
+
+func TestDescribeToolDocumentsDefaultLimit(t *testing.T) {
+	config := &Config{}
+	config.Limits.Tags = 500
+	server := &FireflyMCPServer{config: config}
+
+	assert.Equal(t,
+		"List all tags in Firefly III (returns up to 500 items per page unless limit is set)",
+		server.describeTool("list_tags", "List all tags in Firefly III"))
+	assert.Equal(t, "Get details of a specific account",
+		server.describeTool("get_account", "Get details of a specific account"))
+}
+
+func TestLimitForCappedByMaxLimit(t *testing.T) {
+	config := &Config{}
+	config.Limits.Transactions = 50
+	config.Tools = map[string]ToolOverride{"list_transactions": {MaxLimit: 20}}
+	server := &FireflyMCPServer{config: config}
+
+	assert.Equal(t, 20, server.limitFor("list_transactions", 0))
+	assert.Equal(t, 20, server.limitFor("list_transactions", 500))
+	assert.Equal(t, 7, server.limitFor("list_transactions", 7))
+	assert.Equal(t, 50, server.limitFor("list_budget_transactions", 0))
+	assert.Equal(t,
+		"List transactions (returns up to 20 items per page unless limit is set) (limit is capped at 20)",
+		server.describeTool("list_transactions", "List transactions"))
+}
@@ -0,0 +1,163 @@
+package fireflyMCP
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultMaintenanceCooldown is used when no client.maintenance_cooldown is configured (seconds)
+const defaultMaintenanceCooldown = 60
+
+// maxMaintenanceCooldown caps the cooldown requested by a Retry-After header
+const maxMaintenanceCooldown = 15 * time.Minute
+
+// maintenancePageLimit is the number of bytes of an HTML response searched for the maintenance page
+const maintenancePageLimit = 64 * 1024
+
+// MaintenanceError is returned for requests to a Firefly III instance in maintenance mode,
+// and for requests skipped during the cooldown that follows
+type MaintenanceError struct {
+	RetryAfter time.Duration // Time until the instance should be tried again (0 if unknown)
+}
+
+// Error implements error
+func (e *MaintenanceError) Error() string {
+	if e.RetryAfter <= 0 {
+		return "Firefly III instance is in maintenance mode, try again later"
+	}
+	return fmt.Sprintf(
+		"Firefly III instance is in maintenance mode, retry after %s",
+		e.RetryAfter.Round(time.Second),
+	)
+}
+
+// maintenanceGuard remembers until when requests to Firefly III are short-circuited
+type maintenanceGuard struct {
+	mu       sync.Mutex
+	until    time.Time
+	cooldown time.Duration // Cooldown used when Firefly III sends no Retry-After (0 disables short-circuiting)
+}
+
+// check returns a MaintenanceError while the cooldown is active
+func (g *maintenanceGuard) check(now time.Time) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if now.Before(g.until) {
+		return &MaintenanceError{RetryAfter: g.until.Sub(now)}
+	}
+	return nil
+}
+
+// trip records a maintenance response and starts the cooldown.
+// retryAfter is the delay requested by Firefly III, or 0 if none was sent.
+func (g *maintenanceGuard) trip(now time.Time, retryAfter time.Duration) *MaintenanceError {
+	if g.cooldown <= 0 {
+		return &MaintenanceError{RetryAfter: retryAfter}
+	}
+	if retryAfter <= 0 {
+		retryAfter = g.cooldown
+	}
+	if retryAfter > maxMaintenanceCooldown {
+		retryAfter = maxMaintenanceCooldown
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.until = now.Add(retryAfter)
+	return &MaintenanceError{RetryAfter: retryAfter}
+}
+
+// maintenanceTransport detects maintenance responses of Firefly III and skips
+// requests during the following cooldown, so a recovering server is not hammered
+type maintenanceTransport struct {
+	base  http.RoundTripper
+	guard *maintenanceGuard
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *maintenanceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.guard.check(time.Now()); err != nil {
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	maintenance, err := isMaintenanceResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+	if !maintenance {
+		return resp, nil
+	}
+	resp.Body.Close()
+	return nil, t.guard.trip(time.Now(), parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()))
+}
+
+// isMaintenanceResponse reports whether a response signals maintenance mode: a 503
+// status, or the HTML maintenance page served instead of an API response.
+// The body of HTML responses is inspected and restored for the caller.
+func isMaintenanceResponse(resp *http.Response) (bool, error) {
+	if resp.StatusCode == http.StatusServiceUnavailable {
+		return true, nil
+	}
+	if !strings.HasPrefix(strings.ToLower(resp.Header.Get("Content-Type")), "text/html") {
+		return false, nil
+	}
+
+	head, err := io.ReadAll(io.LimitReader(resp.Body, maintenancePageLimit))
+	if err != nil {
+		resp.Body.Close()
+		return false, err
+	}
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), resp.Body), resp.Body}
+
+	page := strings.ToLower(string(head))
+	return strings.Contains(page, "maintenance") || strings.Contains(page, "be right back"), nil
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date.
+// It returns 0 if the header is missing or invalid.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
+
+// maintenanceErrorDoer unwraps maintenance errors from the *url.Error added by
+// http.Client, so tool results show the maintenance message without the request URL
+type maintenanceErrorDoer struct {
+	client *http.Client
+}
+
+// Do implements client.HttpRequestDoer
+func (d maintenanceErrorDoer) Do(req *http.Request) (*http.Response, error) {
+	resp, err := d.client.Do(req)
+	var maintenanceErr *MaintenanceError
+	if err != nil && errors.As(err, &maintenanceErr) {
+		return nil, maintenanceErr
+	}
+	return resp, err
+}
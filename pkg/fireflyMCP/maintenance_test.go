@@ -0,0 +1,109 @@
+package fireflyMCP
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dezer32/mcp-firefly-iii/pkg/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMaintenanceTestClient(cooldown time.Duration) *http.Client {
+	return &http.Client{
+		Transport: &maintenanceTransport{
+			base:  http.DefaultTransport,
+			guard: &maintenanceGuard{cooldown: cooldown},
+		},
+	}
+}
+
+func TestMaintenanceTransport_ShortCircuitsDuringCooldown(t *testing.T) {
+	var requests atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	apiClient, err := newAPIClient(ts.URL, newMaintenanceTestClient(time.Minute), "token", "")
+	require.NoError(t, err)
+
+	_, err = apiClient.GetAboutWithResponse(context.Background(), &client.GetAboutParams{})
+	var maintenanceErr *MaintenanceError
+	require.ErrorAs(t, err, &maintenanceErr)
+	assert.Equal(t, 2*time.Minute, maintenanceErr.RetryAfter)
+	assert.Equal(t, "Firefly III instance is in maintenance mode, retry after 2m0s", err.Error())
+
+	// The second call fails without reaching the server
+	_, err = apiClient.GetAboutWithResponse(context.Background(), &client.GetAboutParams{})
+	require.ErrorAs(t, err, &maintenanceErr)
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestMaintenanceTransport_DetectsMaintenancePage(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=UTF-8")
+		w.Write([]byte("<html><title>Maintenance</title><body>Firefly III is down for maintenance</body></html>"))
+	}))
+	defer ts.Close()
+
+	_, err := newMaintenanceTestClient(0).Get(ts.URL)
+	var maintenanceErr *MaintenanceError
+	require.ErrorAs(t, err, &maintenanceErr)
+	assert.Zero(t, maintenanceErr.RetryAfter)
+}
+
+func TestMaintenanceTransport_PassesOtherResponses(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("<html><body>Page not found</body></html>"))
+	}))
+	defer ts.Close()
+
+	httpClient := newMaintenanceTestClient(time.Minute)
+	for i := 0; i < 2; i++ {
+		resp, err := httpClient.Get(ts.URL)
+		require.NoError(t, err)
+		body := make([]byte, 64)
+		n, _ := resp.Body.Read(body)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		assert.Contains(t, string(body[:n]), "Page not found")
+	}
+}
+
+func TestMaintenanceGuard(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	guard := &maintenanceGuard{cooldown: time.Minute}
+	assert.NoError(t, guard.check(now))
+	assert.Equal(t, time.Minute, guard.trip(now, 0).RetryAfter)
+	assert.Error(t, guard.check(now.Add(30*time.Second)))
+	assert.NoError(t, guard.check(now.Add(time.Minute)))
+
+	// Retry-After is capped
+	assert.Equal(t, maxMaintenanceCooldown, guard.trip(now, 24*time.Hour).RetryAfter)
+
+	// Without cooldown, maintenance is reported but never short-circuited
+	disabled := &maintenanceGuard{}
+	assert.Equal(t, 30*time.Second, disabled.trip(now, 30*time.Second).RetryAfter)
+	assert.NoError(t, disabled.check(now))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 30*time.Second, parseRetryAfter("30", now))
+	assert.Equal(t, 90*time.Second, parseRetryAfter("Mon, 01 Jan 2024 12:01:30 GMT", now))
+	assert.Zero(t, parseRetryAfter("", now))
+	assert.Zero(t, parseRetryAfter("-5", now))
+	assert.Zero(t, parseRetryAfter("soon", now))
+	assert.Zero(t, parseRetryAfter("Mon, 01 Jan 2024 11:00:00 GMT", now))
+}
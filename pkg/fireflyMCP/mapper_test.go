@@ -7,6 +7,7 @@ import (
 	"github.com/dezer32/mcp-firefly-iii/pkg/client"
 	openapi_types "github.com/oapi-codegen/runtime/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMapBudgetArrayToBudgetList(t *testing.T) {
@@ -82,8 +83,9 @@ func TestMapBudgetArrayToBudgetList(t *testing.T) {
 	assert.Equal(t, &notes, budget.Notes)
 
 	// Verify spent data
-	assert.Equal(t, sum, budget.Spent.Sum)
-	assert.Equal(t, currencyCode, budget.Spent.CurrencyCode)
+	assert.Len(t, budget.Spent, 1)
+	assert.Equal(t, sum, budget.Spent[0].Sum)
+	assert.Equal(t, currencyCode, budget.Spent[0].CurrencyCode)
 
 	// Verify pagination
 	assert.Equal(t, count, result.Pagination.Count)
@@ -94,10 +96,11 @@ func TestMapBudgetArrayToBudgetList(t *testing.T) {
 }
 
 func TestMapBudgetArrayToBudgetList_MultipleSpentItems(t *testing.T) {
-	// Test that only the first spent item is used when multiple exist
+	// Test that every currency is kept and totalled per currency
 	active := true
 	firstSum := "100.50"
 	secondSum := "200.75"
+	thirdSum := "50.00"
 	firstCurrency := "USD"
 	secondCurrency := "EUR"
 
@@ -121,22 +124,36 @@ func TestMapBudgetArrayToBudgetList_MultipleSpentItems(t *testing.T) {
 				},
 				Type: "budgets",
 			},
+			{
+				Id: "2",
+				Attributes: client.Budget{
+					Name:  "Other Budget",
+					Spent: &[]client.BudgetSpent{{Sum: &thirdSum, CurrencyCode: &firstCurrency}},
+				},
+				Type: "budgets",
+			},
 		},
 		Meta: client.Meta{},
 	}
 
 	result := mapBudgetArrayToBudgetList(budgetArray)
 
-	// Verify only the first spent item is used
+	// Verify both spent items are kept
 	assert.NotNil(t, result)
-	assert.Len(t, result.Data, 1)
+	assert.Len(t, result.Data, 2)
 
 	budget := result.Data[0]
-	assert.Equal(t, firstSum, budget.Spent.Sum)
-	assert.Equal(t, firstCurrency, budget.Spent.CurrencyCode)
-	// Verify second item is NOT used
-	assert.NotEqual(t, secondSum, budget.Spent.Sum)
-	assert.NotEqual(t, secondCurrency, budget.Spent.CurrencyCode)
+	assert.Len(t, budget.Spent, 2)
+	assert.Equal(t, firstSum, budget.Spent[0].Sum)
+	assert.Equal(t, firstCurrency, budget.Spent[0].CurrencyCode)
+	assert.Equal(t, secondSum, budget.Spent[1].Sum)
+	assert.Equal(t, secondCurrency, budget.Spent[1].CurrencyCode)
+
+	// Verify totals per currency across budgets
+	assert.Equal(t, []BudgetSpent{
+		{Sum: "150.50", CurrencyCode: firstCurrency},
+		{Sum: "200.75", CurrencyCode: secondCurrency},
+	}, result.SpentTotals)
 }
 
 func TestGetStringValue(t *testing.T) {
@@ -378,6 +395,38 @@ func TestMapAccountSingleToAccount_NilActiveField(t *testing.T) {
 	assert.Equal(t, "expense", result.Type)
 }
 
+func TestMapAccountSingleToAccount_OpeningBalanceAndInterest(t *testing.T) {
+	openingDate := time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)
+	openingBalance := "1000.00"
+	interest := "2.5"
+	period := client.InterestPeriodProperty("monthly")
+
+	accountSingle := &client.AccountSingle{
+		Data: client.AccountRead{
+			Id: "4",
+			Attributes: client.Account{
+				Name:               "Savings",
+				Type:               client.ShortAccountTypePropertyAsset,
+				OpeningBalance:     &openingBalance,
+				OpeningBalanceDate: &openingDate,
+				Interest:           &interest,
+				InterestPeriod:     &period,
+			},
+			Type: "accounts",
+		},
+	}
+
+	result := mapAccountSingleToAccount(accountSingle)
+
+	// Verify the mapping
+	assert.NotNil(t, result)
+	assert.Equal(t, &openingBalance, result.OpeningBalance)
+	assert.Equal(t, &openingDate, result.OpeningBalanceDate)
+	assert.Equal(t, &interest, result.Interest)
+	assert.NotNil(t, result.InterestPeriod)
+	assert.Equal(t, "monthly", *result.InterestPeriod)
+}
+
 func TestMapTransactionArrayToTransactionList(t *testing.T) {
 	// Test with nil input
 	result := mapTransactionArrayToTransactionList(nil)
@@ -801,6 +850,33 @@ func TestMapTransactionReadToTransactionGroup_MultipleTransactions(t *testing.T)
 	assert.False(t, transaction2.Reconciled)
 }
 
+func TestMapTransactionReadToTransactionGroup_SortsSplits(t *testing.T) {
+	first, second := int32(0), int32(1)
+	transactionRead := &client.TransactionRead{
+		Id: "1",
+		Attributes: client.Transaction{
+			Transactions: []client.TransactionSplit{
+				{TransactionJournalId: &[]string{"12"}[0], Order: &second, Description: "Second"},
+				{TransactionJournalId: &[]string{"9"}[0], Description: "Unordered"},
+				{TransactionJournalId: &[]string{"10"}[0], Order: &first, Description: "First"},
+			},
+		},
+	}
+
+	result := mapTransactionReadToTransactionGroup(transactionRead)
+
+	assert.Len(t, result.Transactions, 3)
+	// Splits without an order use their position, ties are broken by journal ID
+	assert.Equal(t, []string{"First", "Unordered", "Second"}, []string{
+		result.Transactions[0].Description, result.Transactions[1].Description, result.Transactions[2].Description,
+	})
+	assert.Equal(t, "10", result.Transactions[0].JournalId)
+	assert.Equal(t, 0, result.Transactions[0].Order)
+	assert.Equal(t, "9", result.Transactions[1].JournalId)
+	assert.Equal(t, 1, result.Transactions[1].Order)
+	assert.Equal(t, "12", result.Transactions[2].JournalId)
+}
+
 func TestMapTransactionReadToTransactionGroup_NilFields(t *testing.T) {
 	// Test with nil optional fields
 	transactionRead := &client.TransactionRead{
@@ -874,6 +950,51 @@ func TestMapTransactionReadToTransactionGroup_EmptyTransactions(t *testing.T) {
 	assert.Empty(t, result.Transactions)
 }
 
+func TestMapTransactionReadToTransactionGroup_Metadata(t *testing.T) {
+	created := time.Date(2024, 3, 2, 6, 15, 0, 0, time.UTC)
+	updated := created.Add(time.Hour)
+	count, total := int32(4), int32(12)
+
+	transactionRead := &client.TransactionRead{
+		Id: "1",
+		Attributes: client.Transaction{
+			CreatedAt: &created,
+			UpdatedAt: &updated,
+			Transactions: []client.TransactionSplit{{
+				Amount:          "950.00",
+				Description:     "Rent",
+				Type:            client.Withdrawal,
+				ExternalId:      ptr("bank-123"),
+				OriginalSource:  ptr("ff3-v6.1.0"),
+				ImportHashV2:    ptr("4f2a9c"),
+				RecurrenceId:    ptr("3"),
+				RecurrenceCount: &count,
+				RecurrenceTotal: &total,
+			}, {
+				Amount:      "5.00",
+				Description: "Fee",
+				Type:        client.Withdrawal,
+			}},
+		},
+		Type: "transactions",
+	}
+
+	result := mapTransactionReadToTransactionGroup(transactionRead)
+
+	require.NotNil(t, result)
+	assert.Equal(t, &created, result.CreatedAt)
+	assert.Equal(t, &updated, result.UpdatedAt)
+	split := result.Transactions[0]
+	assert.Equal(t, "bank-123", getStringValue(split.ExternalId))
+	assert.Equal(t, "ff3-v6.1.0", getStringValue(split.OriginalSource))
+	assert.Equal(t, "4f2a9c", getStringValue(split.ImportHashV2))
+	assert.Equal(t, "3", getStringValue(split.RecurrenceId))
+	assert.Equal(t, ptr(4), split.RecurrenceCount)
+	assert.Equal(t, ptr(12), split.RecurrenceTotal)
+	assert.Nil(t, result.Transactions[1].RecurrenceId)
+	assert.Nil(t, result.Transactions[1].RecurrenceCount)
+}
+
 func TestMapBasicSummaryToBasicSummaryList_Success(t *testing.T) {
 	// Test with normal data
 	key1 := "balance-in-EUR"
@@ -1065,6 +1186,11 @@ func TestMapInsightGroupToDTO(t *testing.T) {
 	assert.Equal(t, name2, entry2.Name)
 	assert.Equal(t, difference2, entry2.Amount)
 	assert.Equal(t, currencyCode2, entry2.CurrencyCode)
+
+	// Check shares of the currency total
+	assert.Equal(t, "66.74", entry1.Share)
+	assert.Equal(t, "33.26", entry2.Share)
+	assert.Equal(t, []InsightTotalEntry{{Amount: "-225.50", CurrencyCode: "EUR"}}, result.Totals)
 }
 
 func TestMapInsightGroupToDTO_NilValues(t *testing.T) {
@@ -1089,6 +1215,8 @@ func TestMapInsightGroupToDTO_NilValues(t *testing.T) {
 	assert.Equal(t, "", entry.Name)
 	assert.Equal(t, "", entry.Amount)
 	assert.Equal(t, "", entry.CurrencyCode)
+	assert.Equal(t, "", entry.Share)
+	assert.Empty(t, result.Totals)
 }
 
 func TestMapInsightTotalToDTO(t *testing.T) {
@@ -1548,3 +1676,13 @@ func TestMapTagArrayToTagList_NilPagination(t *testing.T) {
 	assert.Equal(t, 0, result.Pagination.Count)
 	assert.Equal(t, 0, result.Pagination.Total)
 }
+
+func TestAccountTypeKind(t *testing.T) {
+	assert.Equal(t, client.ShortAccountTypePropertyAsset, accountTypeKind("Asset account"))
+	assert.Equal(t, client.ShortAccountTypePropertyAsset, accountTypeKind("asset"))
+	assert.Equal(t, client.ShortAccountTypePropertyLiability, accountTypeKind("Mortgage"))
+	assert.Equal(t, client.ShortAccountTypePropertyRevenue, accountTypeKind("revenue account"))
+	// Localized labels are not guessed
+	assert.Empty(t, accountTypeKind("Bestandskonto"))
+	assert.Empty(t, accountTypeKind(""))
+}
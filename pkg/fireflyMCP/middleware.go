@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -111,6 +112,10 @@ func CORSMiddleware(allowedOrigins []string, logger *slog.Logger) func(http.Hand
 	}
 }
 
+// RateLimitRemainingHeader carries the number of requests a client can still
+// make immediately before it is rate limited
+const RateLimitRemainingHeader = "X-Rate-Limit-Remaining"
+
 // RateLimitMiddleware creates per-IP rate limiting middleware.
 type rateLimiter struct {
 	limiters map[string]*rate.Limiter
@@ -152,7 +157,8 @@ func RateLimitMiddleware(requestsPerSecond float64, burst int, logger *slog.Logg
 			}
 
 			ip := getClientIP(r)
-			if !limiter.getLimiter(ip).Allow() {
+			ipLimiter := limiter.getLimiter(ip)
+			if !ipLimiter.Allow() {
 				logger.Warn("rate limit exceeded",
 					"ip", ip,
 					"path", r.URL.Path)
@@ -160,6 +166,12 @@ func RateLimitMiddleware(requestsPerSecond float64, burst int, logger *slog.Logg
 				return
 			}
 
+			// Pass the remaining budget to the client and, through the request
+			// headers, to get_session_stats
+			remaining := strconv.Itoa(int(ipLimiter.Tokens()))
+			w.Header().Set(RateLimitRemainingHeader, remaining)
+			r.Header.Set(RateLimitRemainingHeader, remaining)
+
 			next.ServeHTTP(w, r)
 		})
 	}
@@ -199,6 +211,19 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Flush sends buffered data to the client; the SSE streams of the MCP handler
+// rely on it to deliver their headers and events.
+func (rw *responseWriter) Flush() {
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the wrapped writer for http.ResponseController
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // RequestLoggingMiddleware creates middleware for structured request logging.
 func RequestLoggingMiddleware(logger *slog.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
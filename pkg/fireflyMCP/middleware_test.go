@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func testLogger() *slog.Logger {
@@ -329,3 +330,17 @@ func TestRequestLoggingMiddleware(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, rr.Code)
 }
+
+func TestRequestLoggingMiddleware_Flush(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		require.True(t, ok, "streaming responses need a flusher")
+		w.Write([]byte("event: message\n\n"))
+		flusher.Flush()
+	})
+
+	rr := httptest.NewRecorder()
+	RequestLoggingMiddleware(testLogger())(handler).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.True(t, rr.Flushed)
+}
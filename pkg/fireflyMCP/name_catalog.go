@@ -0,0 +1,247 @@
+package fireflyMCP
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dezer32/mcp-firefly-iii/pkg/client"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultCatalogTTL is used when catalogs.ttl is not configured (seconds)
+const defaultCatalogTTL = 300
+
+// catalogKind names a list of Firefly III objects kept in the name catalog
+type catalogKind string
+
+const (
+	catalogAccounts   catalogKind = "accounts"
+	catalogBudgets    catalogKind = "budgets"
+	catalogCategories catalogKind = "categories"
+)
+
+// catalogItem is the ID and name of an object, and the type of accounts
+type catalogItem struct {
+	Id   string
+	Name string
+	Type client.ShortAccountTypeProperty // Accounts only
+}
+
+// nameCatalog keeps the IDs and names of accounts, budgets and categories per
+// tenant, so name resolution does not list them for every request. Entries
+// expire after catalogs.ttl and are dropped after every write of the tenant.
+// Every drop bumps a generation, so a listing that was in flight during a
+// write of another session is not kept.
+type nameCatalog struct {
+	mu          sync.Mutex
+	tenants     map[string]map[catalogKind]nameCatalogEntry
+	generations map[string]uint64 // Per tenant, bumped by invalidate
+	flushes     uint64            // Bumped by flush
+}
+
+type nameCatalogEntry struct {
+	items   []catalogItem
+	fetched time.Time
+}
+
+func newNameCatalog() *nameCatalog {
+	return &nameCatalog{
+		tenants:     make(map[string]map[catalogKind]nameCatalogEntry),
+		generations: make(map[string]uint64),
+	}
+}
+
+// get returns a catalog fetched less than ttl ago, and else the generation to
+// pass to put with the catalog fetched instead
+func (c *nameCatalog) get(tenant string, kind catalogKind, ttl time.Duration) ([]catalogItem, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.tenants[tenant][kind]
+	if ok && time.Since(entry.fetched) < ttl {
+		return entry.items, 0, true
+	}
+	return nil, c.generation(tenant), false
+}
+
+// put keeps a catalog unless the tenant's catalogs were dropped since get
+// returned generation
+func (c *nameCatalog) put(tenant string, kind catalogKind, generation uint64, items []catalogItem) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation(tenant) {
+		return
+	}
+	if c.tenants[tenant] == nil {
+		c.tenants[tenant] = make(map[catalogKind]nameCatalogEntry)
+	}
+	c.tenants[tenant][kind] = nameCatalogEntry{items: items, fetched: time.Now()}
+}
+
+// generation changes whenever the catalogs of a tenant are dropped; c.mu must be held
+func (c *nameCatalog) generation(tenant string) uint64 {
+	return c.flushes + c.generations[tenant]
+}
+
+// invalidate drops the catalogs of a tenant
+func (c *nameCatalog) invalidate(tenant string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.tenants, tenant)
+	c.generations[tenant]++
+}
+
+// size returns how many catalogs are kept
+func (c *nameCatalog) size() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	size := 0
+	for _, kinds := range c.tenants {
+		size += len(kinds)
+	}
+	return size
+}
+
+// flush drops all catalogs and returns how many were dropped
+func (c *nameCatalog) flush() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	flushed := 0
+	for _, kinds := range c.tenants {
+		flushed += len(kinds)
+	}
+	c.tenants = make(map[string]map[catalogKind]nameCatalogEntry)
+	c.flushes++
+	return flushed
+}
+
+// catalogTTL returns how long catalogs are kept; 0 fetches them every time
+func (s *FireflyMCPServer) catalogTTL() time.Duration {
+	config := s.currentConfig()
+	if config == nil {
+		return defaultCatalogTTL * time.Second
+	}
+	return time.Duration(config.Catalogs.TTL) * time.Second
+}
+
+// catalog returns the IDs and names of all objects of a kind, from the cache
+// while it is fresh. The items are shared with other calls and must not be
+// changed. Catalogs of large instances stop at compositeMaxPages pages.
+func (s *FireflyMCPServer) catalog(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	apiClient *client.ClientWithResponses,
+	kind catalogKind,
+) ([]catalogItem, error) {
+	tenant := s.tenantKey(req)
+	ttl := s.catalogTTL()
+	var generation uint64
+	if s.catalogs != nil {
+		items, current, ok := s.catalogs.get(tenant, kind, ttl)
+		if ok {
+			return items, nil
+		}
+		generation = current
+	}
+
+	items, _, err := fetchPages(ctx, s.pageParallelism(), func(ctx context.Context, page int) ([]catalogItem, int, error) {
+		return fetchCatalogPage(ctx, apiClient, kind, page)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing %s: %w", kind, err)
+	}
+
+	if s.catalogs != nil && ttl > 0 {
+		s.catalogs.put(tenant, kind, generation, items)
+	}
+	return items, nil
+}
+
+// fetchCatalogPage lists one page of objects of a kind
+func fetchCatalogPage(ctx context.Context, apiClient *client.ClientWithResponses, kind catalogKind, page int) ([]catalogItem, int, error) {
+	limit, pageParam := int32(compositePageSize), int32(page)
+	var items []catalogItem
+	var meta client.Meta
+	switch kind {
+	case catalogAccounts:
+		resp, err := apiClient.ListAccountWithResponse(ctx, &client.ListAccountParams{Limit: &limit, Page: &pageParam})
+		if err != nil {
+			return nil, 0, err
+		}
+		if resp.StatusCode() != 200 || resp.ApplicationvndApiJSON200 == nil {
+			return nil, 0, fmt.Errorf("API error %d", resp.StatusCode())
+		}
+		for _, account := range resp.ApplicationvndApiJSON200.Data {
+			items = append(items, catalogItem{
+				Id:   account.Id,
+				Name: account.Attributes.Name,
+				Type: accountTypeKind(string(account.Attributes.Type)),
+			})
+		}
+		meta = resp.ApplicationvndApiJSON200.Meta
+	case catalogBudgets:
+		resp, err := apiClient.ListBudgetWithResponse(ctx, &client.ListBudgetParams{Limit: &limit, Page: &pageParam})
+		if err != nil {
+			return nil, 0, err
+		}
+		if resp.StatusCode() != 200 || resp.ApplicationvndApiJSON200 == nil {
+			return nil, 0, fmt.Errorf("API error %d", resp.StatusCode())
+		}
+		for _, budget := range resp.ApplicationvndApiJSON200.Data {
+			items = append(items, catalogItem{Id: budget.Id, Name: budget.Attributes.Name})
+		}
+		meta = resp.ApplicationvndApiJSON200.Meta
+	case catalogCategories:
+		resp, err := apiClient.ListCategoryWithResponse(ctx, &client.ListCategoryParams{Limit: &limit, Page: &pageParam})
+		if err != nil {
+			return nil, 0, err
+		}
+		if resp.StatusCode() != 200 || resp.ApplicationvndApiJSON200 == nil {
+			return nil, 0, fmt.Errorf("API error %d", resp.StatusCode())
+		}
+		for _, category := range resp.ApplicationvndApiJSON200.Data {
+			items = append(items, catalogItem{Id: category.Id, Name: category.Attributes.Name})
+		}
+		meta = resp.ApplicationvndApiJSON200.Meta
+	default:
+		return nil, 0, fmt.Errorf("unknown catalog %s", kind)
+	}
+
+	totalPages := 1
+	if meta.Pagination != nil && meta.Pagination.TotalPages != nil {
+		totalPages = *meta.Pagination.TotalPages
+	}
+	return items, totalPages, nil
+}
+
+// catalogNames maps the IDs of a catalog to names. Names are informational,
+// so errors leave the map empty.
+func (s *FireflyMCPServer) catalogNames(ctx context.Context, req *mcp.CallToolRequest, kind catalogKind) map[string]string {
+	names := make(map[string]string)
+	apiClient, err := s.getClient(ctx, req)
+	if err != nil {
+		return names
+	}
+	items, _ := s.catalog(ctx, req, apiClient, kind)
+	for _, item := range items {
+		names[item.Id] = item.Name
+	}
+	return names
+}
+
+// withCatalogInvalidation drops the name catalogs of the tenant after a
+// successful call of a tool that changes data, which may have created, renamed
+// or deleted objects
+func withCatalogInvalidation[In any](s *FireflyMCPServer, tool *mcp.Tool, handler mcp.ToolHandlerFor[In, any]) mcp.ToolHandlerFor[In, any] {
+	if s.catalogs == nil || (tool.Annotations != nil && tool.Annotations.ReadOnlyHint) {
+		return handler
+	}
+	return func(ctx context.Context, req *mcp.CallToolRequest, args In) (*mcp.CallToolResult, any, error) {
+		result, out, err := handler(ctx, req, args)
+		if err == nil && result != nil && !result.IsError {
+			s.catalogs.invalidate(s.tenantKey(req))
+		}
+		return result, out, err
+	}
+}
@@ -0,0 +1,88 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNameCatalog_ResolvesAccountsFromCache(t *testing.T) {
+	var listed, autocompleted atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch r.URL.Path {
+		case "/v1/accounts":
+			listed.Add(1)
+			w.Write([]byte(`{"data":[` +
+				`{"type":"accounts","id":"1","attributes":{"name":"Checking","type":"asset"}},` +
+				`{"type":"accounts","id":"2","attributes":{"name":"Savings","type":"asset"}},` +
+				`{"type":"accounts","id":"3","attributes":{"name":"Savings","type":"expense"}}],` +
+				`"meta":{"pagination":{"total":3,"count":3,"per_page":200,"current_page":1,"total_pages":1}}}`))
+		case "/v1/autocomplete/accounts":
+			autocompleted.Add(1)
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[{"id":"2","name":"Savings","type":"Asset account"}]`))
+		case "/v1/transactions":
+			w.Write([]byte(`{"data":{"type":"transactions","id":"40","attributes":{"transactions":[` +
+				`{"transaction_journal_id":"41","type":"transfer","date":"2024-03-01T00:00:00Z","amount":"100.00","description":"Save"}]}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	config.Catalogs.TTL = 300
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	transfer := func(destination string) string {
+		args, err := json.Marshal(TransactionStoreRequest{Transactions: []TransactionSplitRequest{{
+			Type: "transfer", Date: "2024-03-01", Amount: "100.00", Description: "Save",
+			SourceName: ptr("checking"), DestinationName: &destination,
+		}}})
+		require.NoError(t, err)
+		result, err := server.tools["store_transaction"].invoke(ctx, nil, args)
+		require.NoError(t, err)
+		return result.Content[0].(*mcp.TextContent).Text
+	}
+
+	// Only the asset account counts, so "Savings" is not ambiguous
+	assert.NotContains(t, transfer("Savings"), "Error")
+	assert.Equal(t, int32(1), listed.Load(), "both names resolved from one listing")
+	assert.Equal(t, int32(0), autocompleted.Load())
+
+	// The successful write dropped the catalog
+	assert.NotContains(t, transfer("savings"), "Error")
+	assert.Equal(t, int32(2), listed.Load())
+
+	for range 2 {
+		id, err := server.accountByName(ctx, nil, server.client, "SAVINGS", "asset", "asset")
+		require.NoError(t, err)
+		assert.Equal(t, "2", id)
+	}
+	assert.Equal(t, int32(3), listed.Load(), "reads are served from the catalog")
+
+	assert.Equal(t, 1, server.FlushCaches())
+	_, err = server.accountByName(ctx, nil, server.client, "Savings", "asset", "asset")
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), listed.Load())
+}
+
+func TestNameCatalog_TTL(t *testing.T) {
+	server, err := NewFireflyMCPServer(newPluginTestConfig())
+	require.NoError(t, err)
+	assert.Zero(t, server.catalogTTL(), "0 disables caching")
+
+	server = &FireflyMCPServer{}
+	assert.Equal(t, float64(defaultCatalogTTL), server.catalogTTL().Seconds())
+}
@@ -0,0 +1,167 @@
+package fireflyMCP
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dezer32/mcp-firefly-iii/pkg/client"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// TransferToPiggyArgs represents the arguments for the transfer_to_piggy tool
+type TransferToPiggyArgs struct {
+	PiggyBankId   string `json:"piggy_bank_id" jsonschema:"Piggy bank ID (required)"`
+	SourceId      string `json:"source_id" jsonschema:"Asset account ID (or configured account alias) the money comes from (required)"`
+	DestinationId string `json:"destination_id,omitempty" jsonschema:"Account ID (or alias) of the piggy bank receiving the money (default: the piggy bank's only account)"`
+	Amount        string `json:"amount" jsonschema:"Amount to save (required, must not exceed what is left to save)"`
+	Date          string `json:"date,omitempty" jsonschema:"Transaction date (YYYY-MM-DD or today/yesterday, default: today)"`
+	Description   string `json:"description,omitempty" jsonschema:"Transaction description (default: Transfer to piggy bank <name>)"`
+}
+
+// PiggyTransferResult is the result of transfer_to_piggy
+type PiggyTransferResult struct {
+	PiggyBankId      string            `json:"piggy_bank_id"`
+	PiggyBankName    string            `json:"piggy_bank_name"`
+	Amount           string            `json:"amount"`
+	LeftToSave       *string           `json:"left_to_save,omitempty"`
+	TransactionGroup *TransactionGroup `json:"transaction_group"`
+}
+
+// handleTransferToPiggy creates a transfer from an asset account into a piggy
+// bank's account and links it to the piggy bank, after validating the source
+// account type and the amount left to save
+func (s *FireflyMCPServer) handleTransferToPiggy(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args TransferToPiggyArgs,
+) (*mcp.CallToolResult, any, error) {
+	if args.PiggyBankId == "" || args.SourceId == "" || args.Amount == "" {
+		return newErrorResult("Error: piggy_bank_id, source_id and amount are required")
+	}
+	amount, ok := parseDecimal(args.Amount)
+	if !ok || amount.Sign() <= 0 {
+		return newErrorResult("Error: amount must be a positive number")
+	}
+
+	apiClient, err := s.getClient(ctx, req)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Failed to get API client: %v", err))
+	}
+
+	piggyResp, err := apiClient.GetPiggyBankWithResponse(ctx, args.PiggyBankId, &client.GetPiggyBankParams{})
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error getting piggy bank: %v", err))
+	}
+	if piggyResp.StatusCode() != 200 || piggyResp.ApplicationvndApiJSON200 == nil {
+		return newErrorResult(fmt.Sprintf("API error getting piggy bank: %d", piggyResp.StatusCode()))
+	}
+	piggy := piggyResp.ApplicationvndApiJSON200.Data.Attributes
+
+	if leftToSave := getStringValue(piggy.LeftToSave); piggy.TargetAmount != nil && leftToSave != "" {
+		left, ok := parseDecimal(leftToSave)
+		if ok && amount.Cmp(left) > 0 {
+			return newErrorResult(fmt.Sprintf(
+				"Error: amount %s exceeds the %s left to save for piggy bank %s", args.Amount, leftToSave, piggy.Name,
+			))
+		}
+	}
+
+	destinationID, err := piggyDestinationAccount(piggy, s.resolveAccountRef(args.DestinationId))
+	if err != nil {
+		return newErrorResult(err.Error())
+	}
+
+	sourceID := s.resolveAccountRef(args.SourceId)
+	if sourceID == destinationID {
+		return newErrorResult("Error: source and destination account must be different")
+	}
+	sourceResp, err := apiClient.GetAccountWithResponse(ctx, sourceID, &client.GetAccountParams{})
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error getting source account: %v", err))
+	}
+	if sourceResp.StatusCode() != 200 || sourceResp.ApplicationvndApiJSON200 == nil {
+		return newErrorResult(fmt.Sprintf("API error getting source account: %d", sourceResp.StatusCode()))
+	}
+	source := sourceResp.ApplicationvndApiJSON200.Data.Attributes
+	if source.Type != client.ShortAccountTypePropertyAsset {
+		return newErrorResult(fmt.Sprintf(
+			"Error: source account %s is a %s account, transfers to piggy banks must come from an asset account",
+			source.Name, source.Type,
+		))
+	}
+
+	date := args.Date
+	if date == "" {
+		date = "today"
+	}
+	description := args.Description
+	if description == "" {
+		description = "Transfer to piggy bank " + piggy.Name
+	}
+	piggyBankID := args.PiggyBankId
+
+	group, err := callTool[TransactionStoreRequest, TransactionGroup](
+		ctx, req, s.handleStoreTransaction,
+		TransactionStoreRequest{
+			Transactions: []TransactionSplitRequest{
+				{
+					Type:          "transfer",
+					Date:          date,
+					Amount:        args.Amount,
+					Description:   description,
+					SourceId:      &sourceID,
+					DestinationId: &destinationID,
+					PiggyBankId:   &piggyBankID,
+				},
+			},
+		},
+	)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error creating transfer: %v", err))
+	}
+
+	result := &PiggyTransferResult{
+		PiggyBankId:      piggyBankID,
+		PiggyBankName:    piggy.Name,
+		Amount:           args.Amount,
+		TransactionGroup: group,
+	}
+	if left, ok := parseDecimal(getStringValue(piggy.LeftToSave)); ok && piggy.TargetAmount != nil {
+		remaining := formatAmount(left.Sub(amount))
+		result.LeftToSave = &remaining
+	}
+	return newSuccessResult(result)
+}
+
+// piggyDestinationAccount returns the piggy bank account receiving the transfer.
+// Without an explicit destination, the piggy bank must have exactly one account.
+func piggyDestinationAccount(piggy client.PiggyBank, destinationID string) (string, error) {
+	var ids, names []string
+	if piggy.Accounts != nil {
+		for _, account := range *piggy.Accounts {
+			ids = append(ids, getStringValue(account.Id))
+			names = append(names, fmt.Sprintf("%s (%s)", getStringValue(account.Name), getStringValue(account.Id)))
+		}
+	}
+
+	if destinationID != "" {
+		for _, id := range ids {
+			if id == destinationID {
+				return destinationID, nil
+			}
+		}
+		return "", fmt.Errorf("Error: account %s is not linked to piggy bank %s (accounts: %s)",
+			destinationID, piggy.Name, strings.Join(names, ", "))
+	}
+
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("Error: piggy bank %s has no account", piggy.Name)
+	case 1:
+		return ids[0], nil
+	default:
+		return "", fmt.Errorf("Error: piggy bank %s has several accounts, set destination_id to one of: %s",
+			piggy.Name, strings.Join(names, ", "))
+	}
+}
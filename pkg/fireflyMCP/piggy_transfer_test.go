@@ -0,0 +1,141 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dezer32/mcp-firefly-iii/pkg/client"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPiggyDestinationAccount(t *testing.T) {
+	single := client.PiggyBank{
+		Name:     "Holiday",
+		Accounts: &[]client.PiggyBankAccountRead{{Id: strPtr("5"), Name: strPtr("Savings")}},
+	}
+	id, err := piggyDestinationAccount(single, "")
+	require.NoError(t, err)
+	assert.Equal(t, "5", id)
+
+	_, err = piggyDestinationAccount(single, "6")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Savings (5)")
+
+	several := client.PiggyBank{
+		Name: "Holiday",
+		Accounts: &[]client.PiggyBankAccountRead{
+			{Id: strPtr("5"), Name: strPtr("Savings")},
+			{Id: strPtr("6"), Name: strPtr("Joint savings")},
+		},
+	}
+	_, err = piggyDestinationAccount(several, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "destination_id")
+
+	id, err = piggyDestinationAccount(several, "6")
+	require.NoError(t, err)
+	assert.Equal(t, "6", id)
+
+	_, err = piggyDestinationAccount(client.PiggyBank{Name: "Empty"}, "")
+	assert.Error(t, err)
+}
+
+// newPiggyTestServer serves a piggy bank with 150.00 left to save on account 5,
+// an asset account 1 and an expense account 2, and records stored transactions
+func newPiggyTestServer(t *testing.T, stored *[]string) *FireflyMCPServer {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch {
+		case r.URL.Path == "/v1/piggy-banks/3":
+			w.Write([]byte(`{"data":{"type":"piggy_banks","id":"3","attributes":{"name":"Holiday",` +
+				`"target_amount":"500.00","current_amount":"350.00","left_to_save":"150.00",` +
+				`"accounts":[{"id":"5","name":"Savings","current_amount":"350.00"}]}}}`))
+		case r.URL.Path == "/v1/accounts/1":
+			w.Write([]byte(`{"data":{"type":"accounts","id":"1","attributes":{"name":"Checking","type":"asset"}}}`))
+		case r.URL.Path == "/v1/accounts/2":
+			w.Write([]byte(`{"data":{"type":"accounts","id":"2","attributes":{"name":"Shop","type":"expense"}}}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/transactions":
+			body, _ := io.ReadAll(r.Body)
+			*stored = append(*stored, string(body))
+			w.Write([]byte(`{"data":{"type":"transactions","id":"40","attributes":{"transactions":[` +
+				`{"transaction_journal_id":"41","type":"transfer","date":"2024-03-01T00:00:00Z","amount":"100.00",` +
+				`"description":"Transfer to piggy bank Holiday","source_id":"1","destination_id":"5"}]}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+	return server
+}
+
+func TestTransferToPiggy(t *testing.T) {
+	var stored []string
+	server := newPiggyTestServer(t, &stored)
+
+	result, _, err := server.handleTransferToPiggy(context.Background(), nil, TransferToPiggyArgs{
+		PiggyBankId: "3",
+		SourceId:    "1",
+		Amount:      "100.00",
+		Date:        "2024-03-01",
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content[0].(*mcp.TextContent).Text)
+
+	var transfer PiggyTransferResult
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &transfer))
+	assert.Equal(t, "Holiday", transfer.PiggyBankName)
+	require.NotNil(t, transfer.LeftToSave)
+	assert.Equal(t, "50.00", *transfer.LeftToSave)
+	require.NotNil(t, transfer.TransactionGroup)
+	assert.Equal(t, "40", transfer.TransactionGroup.Id)
+
+	require.Len(t, stored, 1)
+	var body struct {
+		Transactions []map[string]any `json:"transactions"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stored[0]), &body))
+	require.Len(t, body.Transactions, 1)
+	split := body.Transactions[0]
+	assert.Equal(t, "transfer", split["type"])
+	assert.Equal(t, "1", split["source_id"])
+	assert.Equal(t, "5", split["destination_id"])
+	assert.Equal(t, float64(3), split["piggy_bank_id"])
+}
+
+func TestTransferToPiggy_Validation(t *testing.T) {
+	var stored []string
+	server := newPiggyTestServer(t, &stored)
+
+	tests := []struct {
+		name string
+		args TransferToPiggyArgs
+		want string
+	}{
+		{"missing fields", TransferToPiggyArgs{PiggyBankId: "3"}, "required"},
+		{"non-positive amount", TransferToPiggyArgs{PiggyBankId: "3", SourceId: "1", Amount: "-5"}, "positive"},
+		{"exceeds left to save", TransferToPiggyArgs{PiggyBankId: "3", SourceId: "1", Amount: "200.00"}, "left to save"},
+		{"non-asset source", TransferToPiggyArgs{PiggyBankId: "3", SourceId: "2", Amount: "10"}, "asset account"},
+		{"same account", TransferToPiggyArgs{PiggyBankId: "3", SourceId: "5", Amount: "10"}, "different"},
+		{"foreign destination", TransferToPiggyArgs{PiggyBankId: "3", SourceId: "1", DestinationId: "9", Amount: "10"}, "not linked"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := server.handleTransferToPiggy(context.Background(), nil, tt.args)
+			require.NoError(t, err)
+			require.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, tt.want)
+		})
+	}
+	assert.Empty(t, stored, "no transfer may be created when validation fails")
+}
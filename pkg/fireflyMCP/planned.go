@@ -0,0 +1,215 @@
+package fireflyMCP
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// plannedTag marks future-dated transactions that are planned, not booked yet
+const plannedTag = "planned"
+
+// ListPlannedTransactionsArgs represents the arguments for the list_planned_transactions tool
+type ListPlannedTransactionsArgs struct {
+	DateRange
+}
+
+// PlannedTransactions is the result of the list_planned_transactions tool
+type PlannedTransactions struct {
+	Due                   []TransactionGroup `json:"due"`      // Planned for today or earlier, waiting for confirm_planned
+	Upcoming              []TransactionGroup `json:"upcoming"` // Planned for after today
+	TransactionsTruncated bool               `json:"transactions_truncated,omitempty"`
+}
+
+// ConfirmPlannedArgs represents the arguments for the confirm_planned tool
+type ConfirmPlannedArgs struct {
+	ID     string `json:"id" jsonschema:"ID of the planned transaction (required)"`
+	Date   string `json:"date,omitempty" jsonschema:"Date it actually occurred (YYYY-MM-DD or today/yesterday, default: the planned date)"`
+	Amount string `json:"amount,omitempty" jsonschema:"Amount it actually had (single-split transactions only, default: the planned amount)"`
+}
+
+// handleStorePlannedTransaction creates a future-dated transaction tagged as
+// planned, for "what if" planning. Confirm it with confirm_planned once it occurs.
+func (s *FireflyMCPServer) handleStorePlannedTransaction(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args TransactionStoreRequest,
+) (*mcp.CallToolResult, any, error) {
+	if len(args.Transactions) == 0 {
+		return newErrorResult("Error: transactions array is required and must not be empty")
+	}
+	args.Transactions = s.resolveSplitDates(args.Transactions)
+	today := s.today()
+	for i, split := range args.Transactions {
+		date, err := splitDay(split.Date)
+		if err != nil {
+			return newErrorResult(fmt.Sprintf("Error: transaction[%d].date must be in format YYYY-MM-DD or RFC3339", i))
+		}
+		if !date.After(today) {
+			return newErrorResult(fmt.Sprintf(
+				"Error: transaction[%d].date must be after today (%s), use store_transaction for transactions that already occurred",
+				i, today.Format("2006-01-02"),
+			))
+		}
+	}
+	args.Transactions = addSplitTag(args.Transactions, plannedTag)
+	return s.handleStoreTransaction(ctx, req, args)
+}
+
+// handleListPlannedTransactions lists the transactions tagged as planned, split
+// into those that are due for confirmation and those still ahead
+func (s *FireflyMCPServer) handleListPlannedTransactions(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args ListPlannedTransactionsArgs,
+) (*mcp.CallToolResult, any, error) {
+	dates, err := s.resolveDateRange(args.DateRange, dateRangeOpen)
+	if err != nil {
+		return newErrorResult(err.Error())
+	}
+
+	result := &PlannedTransactions{Due: []TransactionGroup{}, Upcoming: []TransactionGroup{}}
+	today := s.today()
+	query := plannedQuery(dates)
+	groups, truncated, err := fetchPages(ctx, s.pageParallelism(), func(ctx context.Context, page int) ([]TransactionGroup, int, error) {
+		list, err := callTool[SearchTransactionsArgs, TransactionList](ctx, req, s.handleSearchTransactions, SearchTransactionsArgs{
+			Query: query,
+			Limit: compositePageSize,
+			Page:  int32(page),
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+		return list.Data, list.Pagination.TotalPages, nil
+	})
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error searching planned transactions: %v", err))
+	}
+	result.TransactionsTruncated = truncated
+	for _, group := range groups {
+		if groupDate(group).After(today) {
+			result.Upcoming = append(result.Upcoming, group)
+		} else {
+			result.Due = append(result.Due, group)
+		}
+	}
+	return newSuccessResult(result)
+}
+
+// handleConfirmPlanned turns a planned transaction into a real one: the planned
+// tag is removed and, when given, the actual date and amount are set
+func (s *FireflyMCPServer) handleConfirmPlanned(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args ConfirmPlannedArgs,
+) (*mcp.CallToolResult, any, error) {
+	if args.ID == "" {
+		return newErrorResult("Error: id is required")
+	}
+	if args.Amount != "" {
+		if amount, ok := parseDecimal(args.Amount); !ok || amount.Sign() <= 0 {
+			return newErrorResult("Error: amount must be a positive number")
+		}
+	}
+	date := s.resolveRelativeDate(args.Date)
+	if date != "" {
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return newErrorResult("Error: date must be in format YYYY-MM-DD")
+		}
+	}
+
+	current, err := callTool[GetTransactionArgs, TransactionGroup](ctx, req, s.handleGetTransaction, GetTransactionArgs{ID: args.ID})
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error getting transaction: %v", err))
+	}
+	if !groupHasTag(*current, plannedTag) {
+		return newErrorResult(fmt.Sprintf("Error: transaction %s is not tagged %q, it is not a planned transaction", args.ID, plannedTag))
+	}
+	if args.Amount != "" && len(current.Transactions) > 1 {
+		return newErrorResult(fmt.Sprintf(
+			"Error: transaction %s has %d splits, change their amounts with update_transaction", args.ID, len(current.Transactions),
+		))
+	}
+	if date == "" {
+		if planned := groupDate(*current); planned.After(s.today()) {
+			return newErrorResult(fmt.Sprintf(
+				"Error: transaction %s is planned for %s, set date to confirm it earlier", args.ID, planned.Format("2006-01-02"),
+			))
+		}
+	}
+
+	var (
+		splits  []map[string]any
+		changed []TransactionSplitRequest
+	)
+	for _, split := range current.Transactions {
+		tags := []string{}
+		for _, tag := range split.Tags {
+			if !strings.EqualFold(tag, plannedTag) {
+				tags = append(tags, tag)
+			}
+		}
+		update := map[string]any{"transaction_journal_id": split.JournalId, "tags": tags}
+		recorded := TransactionSplitRequest{Tags: tags}
+		if date != "" {
+			update["date"] = date
+			recorded.Date = date
+		}
+		if args.Amount != "" {
+			update["amount"] = args.Amount
+			recorded.Amount = args.Amount
+		}
+		splits = append(splits, update)
+		changed = append(changed, recorded)
+	}
+
+	body := map[string]any{"transactions": splits}
+	if current.GroupTitle != "" {
+		body["group_title"] = current.GroupTitle
+	}
+	return s.sendTransactionUpdate(ctx, req, "confirm_planned", args.ID, body, "", changed)
+}
+
+// plannedQuery returns the search query for planned transactions in a range
+func plannedQuery(dates resolvedDateRange) string {
+	return strings.TrimSpace(fmt.Sprintf("tag_is:%s %s", plannedTag, transactionFilterQuery("", dates)))
+}
+
+// splitDay returns the day of a YYYY-MM-DD or RFC3339 split date
+func splitDay(value string) (time.Time, error) {
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		return date, nil
+	}
+	date, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC), nil
+}
+
+// groupDate returns the day of the earliest split of a transaction group
+func groupDate(group TransactionGroup) time.Time {
+	var earliest time.Time
+	for i, split := range group.Transactions {
+		day := time.Date(split.Date.Year(), split.Date.Month(), split.Date.Day(), 0, 0, 0, 0, time.UTC)
+		if i == 0 || day.Before(earliest) {
+			earliest = day
+		}
+	}
+	return earliest
+}
+
+// groupHasTag reports whether any split of a transaction group carries the tag
+func groupHasTag(group TransactionGroup, tag string) bool {
+	for _, split := range group.Transactions {
+		for _, existing := range split.Tags {
+			if strings.EqualFold(existing, tag) {
+				return true
+			}
+		}
+	}
+	return false
+}
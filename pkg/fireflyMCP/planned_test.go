@@ -0,0 +1,145 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func plannedGroupJSON(id, date string, tags string) string {
+	return fmt.Sprintf(`{"type":"transactions","id":"%s","attributes":{"transactions":[`+
+		`{"transaction_journal_id":"%s1","type":"withdrawal","date":"%sT00:00:00Z","amount":"120.00",`+
+		`"description":"Car service","tags":%s}]}}`, id, id, date, tags)
+}
+
+func newPlannedTestServer(t *testing.T, requests *[]map[string]any, queries *[]string) *FireflyMCPServer {
+	future := time.Now().AddDate(0, 1, 0).Format("2006-01-02")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch {
+		case r.URL.Path == "/v1/search/transactions":
+			*queries = append(*queries, r.URL.Query().Get("query"))
+			w.Write([]byte(`{"data":[` + plannedGroupJSON("50", "2024-03-01", `["planned","car"]`) + `,` +
+				plannedGroupJSON("51", future, `["planned"]`) + `],` +
+				`"meta":{"pagination":{"total":2,"count":2,"per_page":200,"current_page":1,"total_pages":1}}}`))
+		case r.Method == http.MethodPost || r.Method == http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			var request map[string]any
+			require.NoError(t, json.Unmarshal(body, &request))
+			*requests = append(*requests, request)
+			w.Write([]byte(`{"data":` + plannedGroupJSON("50", "2024-03-02", `["car"]`) + `}`))
+		case r.URL.Path == "/v1/transactions/50":
+			w.Write([]byte(`{"data":` + plannedGroupJSON("50", "2024-03-01", `["planned","car"]`) + `}`))
+		case r.URL.Path == "/v1/transactions/51":
+			w.Write([]byte(`{"data":` + plannedGroupJSON("51", future, `["planned"]`) + `}`))
+		case r.URL.Path == "/v1/transactions/52":
+			w.Write([]byte(`{"data":` + plannedGroupJSON("52", "2024-03-01", `["car"]`) + `}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"Resource not found"}`))
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+	return server
+}
+
+func TestStorePlannedTransaction(t *testing.T) {
+	var requests []map[string]any
+	var queries []string
+	server := newPlannedTestServer(t, &requests, &queries)
+	ctx := context.Background()
+
+	result, _, err := server.handleStorePlannedTransaction(ctx, nil, TransactionStoreRequest{
+		Transactions: []TransactionSplitRequest{{
+			Type: "withdrawal", Date: "tomorrow", Amount: "120.00", Description: "Car service", Tags: []string{"car"},
+		}},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content[0].(*mcp.TextContent).Text)
+	require.Len(t, requests, 1)
+	split := requests[0]["transactions"].([]any)[0].(map[string]any)
+	assert.Equal(t, []any{"car", "planned"}, split["tags"])
+
+	result, _, err = server.handleStorePlannedTransaction(ctx, nil, TransactionStoreRequest{
+		Transactions: []TransactionSplitRequest{{Type: "withdrawal", Date: "today", Amount: "5", Description: "Coffee"}},
+	})
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "must be after today")
+	assert.Len(t, requests, 1)
+}
+
+func TestListPlannedTransactions(t *testing.T) {
+	var requests []map[string]any
+	var queries []string
+	server := newPlannedTestServer(t, &requests, &queries)
+
+	result, _, err := server.handleListPlannedTransactions(context.Background(), nil, ListPlannedTransactionsArgs{
+		DateRange: DateRange{Start: "2024-01-01"},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content[0].(*mcp.TextContent).Text)
+
+	var planned PlannedTransactions
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &planned))
+	require.Len(t, planned.Due, 1)
+	assert.Equal(t, "50", planned.Due[0].Id)
+	require.Len(t, planned.Upcoming, 1)
+	assert.Equal(t, "51", planned.Upcoming[0].Id)
+	assert.Equal(t, []string{"tag_is:planned date_after:2024-01-01"}, queries)
+}
+
+func TestConfirmPlanned(t *testing.T) {
+	var requests []map[string]any
+	var queries []string
+	server := newPlannedTestServer(t, &requests, &queries)
+	ctx := context.Background()
+
+	result, _, err := server.handleConfirmPlanned(ctx, nil, ConfirmPlannedArgs{ID: "50", Date: "2024-03-02", Amount: "118.40"})
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content[0].(*mcp.TextContent).Text)
+	require.Len(t, requests, 1)
+	assert.Equal(t, map[string]any{
+		"transactions": []any{map[string]any{
+			"transaction_journal_id": "501", "tags": []any{"car"}, "date": "2024-03-02", "amount": "118.40",
+		}},
+	}, requests[0])
+
+	history := server.changes.get(server.tenantKey(nil), "50")
+	require.Len(t, history, 1)
+	assert.Equal(t, "confirm_planned", history[0].Tool)
+
+	tests := []struct {
+		name  string
+		args  ConfirmPlannedArgs
+		error string
+	}{
+		{"not planned", ConfirmPlannedArgs{ID: "52"}, "not a planned transaction"},
+		{"still ahead", ConfirmPlannedArgs{ID: "51"}, "set date to confirm it earlier"},
+		{"invalid amount", ConfirmPlannedArgs{ID: "50", Amount: "-3"}, "amount must be a positive number"},
+		{"missing id", ConfirmPlannedArgs{}, "id is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := server.handleConfirmPlanned(ctx, nil, tt.args)
+			require.NoError(t, err)
+			require.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, tt.error)
+		})
+	}
+	assert.Len(t, requests, 1)
+}
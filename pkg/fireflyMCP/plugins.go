@@ -0,0 +1,108 @@
+package fireflyMCP
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/dezer32/mcp-firefly-iii/pkg/client"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Plugin adds custom tools to the server without forking it. Plugins are
+// regular Go packages that call RegisterPlugin from an init function; a custom
+// build of cmd/mcp-server enables them with a blank import.
+type Plugin interface {
+	// Name identifies the plugin in logs and error messages
+	Name() string
+	// RegisterTools registers the plugin's tools with the host
+	RegisterTools(host *PluginHost) error
+}
+
+// pluginRegistry holds the plugins registered via RegisterPlugin
+var pluginRegistry = struct {
+	mu      sync.Mutex
+	plugins []Plugin
+}{}
+
+// RegisterPlugin registers a plugin for all servers created afterwards.
+// It is intended to be called from a plugin package's init function.
+// Registering a second plugin with the same name panics.
+func RegisterPlugin(p Plugin) {
+	pluginRegistry.mu.Lock()
+	defer pluginRegistry.mu.Unlock()
+
+	for _, existing := range pluginRegistry.plugins {
+		if existing.Name() == p.Name() {
+			panic(fmt.Sprintf("fireflyMCP: plugin %q registered twice", p.Name()))
+		}
+	}
+	pluginRegistry.plugins = append(pluginRegistry.plugins, p)
+}
+
+// RegisteredPlugins returns the names of all registered plugins in sorted order
+func RegisteredPlugins() []string {
+	pluginRegistry.mu.Lock()
+	defer pluginRegistry.mu.Unlock()
+
+	names := make([]string, len(pluginRegistry.plugins))
+	for i, p := range pluginRegistry.plugins {
+		names[i] = p.Name()
+	}
+	sort.Strings(names)
+	return names
+}
+
+// registeredPlugins returns a snapshot of the registered plugins
+func registeredPlugins() []Plugin {
+	pluginRegistry.mu.Lock()
+	defer pluginRegistry.mu.Unlock()
+	return append([]Plugin(nil), pluginRegistry.plugins...)
+}
+
+// PluginHost gives a plugin access to the server's Firefly III client,
+// configuration and tool registration
+type PluginHost struct {
+	server *FireflyMCPServer
+	plugin string
+}
+
+// Client returns the Firefly III API client for the current request, with the
+// same token handling as the built-in tools (static token in stdio mode,
+// per-request token in HTTP mode)
+func (h *PluginHost) Client(ctx context.Context, req mcp.Request) (*client.ClientWithResponses, error) {
+	return h.server.getClient(ctx, req)
+}
+
+// Config returns the server configuration
+func (h *PluginHost) Config() *Config {
+	return h.server.config
+}
+
+// ResolveAccount resolves a configured account alias to its account ID.
+// Any other reference is returned unchanged.
+func (h *PluginHost) ResolveAccount(ref string) string {
+	return h.server.resolveAccountRef(ref)
+}
+
+// AddPluginTool registers a plugin tool through the same path as built-in tools.
+// Returns an error if a tool with the same name is already registered.
+func AddPluginTool[In any](h *PluginHost, tool *mcp.Tool, handler mcp.ToolHandlerFor[In, any]) error {
+	if h.server.hasTool(tool.Name) {
+		return fmt.Errorf("plugin %q: tool %q is already registered", h.plugin, tool.Name)
+	}
+	addTool(h.server, tool, handler)
+	return nil
+}
+
+// registerPlugins lets every registered plugin add its tools
+func (s *FireflyMCPServer) registerPlugins() error {
+	for _, p := range registeredPlugins() {
+		host := &PluginHost{server: s, plugin: p.Name()}
+		if err := p.RegisterTools(host); err != nil {
+			return fmt.Errorf("plugin %q: %w", p.Name(), err)
+		}
+	}
+	return nil
+}
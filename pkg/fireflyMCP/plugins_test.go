@@ -0,0 +1,114 @@
+package fireflyMCP
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testPlugin struct {
+	name     string
+	register func(host *PluginHost) error
+}
+
+func (p *testPlugin) Name() string                         { return p.name }
+func (p *testPlugin) RegisterTools(host *PluginHost) error { return p.register(host) }
+
+type taxTagArgs struct {
+	AccountRef string `json:"account" jsonschema:"Account ID or alias"`
+}
+
+// withPlugins replaces the plugin registry for the duration of a test
+func withPlugins(t *testing.T, plugins ...Plugin) {
+	pluginRegistry.mu.Lock()
+	saved := pluginRegistry.plugins
+	pluginRegistry.plugins = nil
+	pluginRegistry.mu.Unlock()
+
+	t.Cleanup(func() {
+		pluginRegistry.mu.Lock()
+		pluginRegistry.plugins = saved
+		pluginRegistry.mu.Unlock()
+	})
+
+	for _, p := range plugins {
+		RegisterPlugin(p)
+	}
+}
+
+func newPluginTestConfig() *Config {
+	config := &Config{}
+	config.Server.URL = "https://firefly.example.com/api"
+	config.API.Token = "token"
+	config.Client.Timeout = 5
+	config.Accounts.Aliases = map[string]string{"joint card": "12"}
+	config.Accounts.AllowAutocreate = true
+	return config
+}
+
+func TestPluginRegistersTool(t *testing.T) {
+	var host *PluginHost
+	withPlugins(t, &testPlugin{
+		name: "tax",
+		register: func(h *PluginHost) error {
+			host = h
+			return AddPluginTool(h, &mcp.Tool{Name: "tax_tag", Description: "Tag tax relevant transactions"},
+				func(ctx context.Context, req *mcp.CallToolRequest, args taxTagArgs) (*mcp.CallToolResult, any, error) {
+					return newSuccessResult(h.ResolveAccount(args.AccountRef))
+				})
+		},
+	})
+
+	server, err := NewFireflyMCPServer(newPluginTestConfig())
+	require.NoError(t, err)
+
+	assert.True(t, server.hasTool("tax_tag"))
+	assert.True(t, server.hasTool("list_accounts"))
+	assert.Equal(t, []string{"tax"}, RegisteredPlugins())
+
+	require.NotNil(t, host)
+	assert.Equal(t, "12", host.ResolveAccount("Joint Card"))
+	assert.Same(t, server.config, host.Config())
+
+	apiClient, err := host.Client(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Same(t, server.client, apiClient)
+}
+
+func TestPluginToolNameConflict(t *testing.T) {
+	withPlugins(t, &testPlugin{
+		name: "shadow",
+		register: func(h *PluginHost) error {
+			return AddPluginTool(h, &mcp.Tool{Name: "list_accounts", Description: "Shadowed"},
+				func(ctx context.Context, req *mcp.CallToolRequest, args ListAccountsArgs) (*mcp.CallToolResult, any, error) {
+					return newSuccessResult(nil)
+				})
+		},
+	})
+
+	_, err := NewFireflyMCPServer(newPluginTestConfig())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `tool "list_accounts" is already registered`)
+}
+
+func TestPluginRegistrationError(t *testing.T) {
+	withPlugins(t, &testPlugin{
+		name:     "broken",
+		register: func(h *PluginHost) error { return errors.New("missing setting") },
+	})
+
+	_, err := NewFireflyMCPServer(newPluginTestConfig())
+	require.Error(t, err)
+	assert.Equal(t, `plugin "broken": missing setting`, err.Error())
+}
+
+func TestRegisterPluginTwicePanics(t *testing.T) {
+	plugin := &testPlugin{name: "dup", register: func(h *PluginHost) error { return nil }}
+	withPlugins(t, plugin)
+
+	assert.Panics(t, func() { RegisterPlugin(plugin) })
+}
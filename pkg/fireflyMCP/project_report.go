@@ -0,0 +1,240 @@
+package fireflyMCP
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// noCategoryLabel names the category line of uncategorized transactions
+const noCategoryLabel = "(no category)"
+
+// ProjectReportArgs represents the arguments for the project_report tool
+type ProjectReportArgs struct {
+	Tags []string `json:"tags" jsonschema:"Tags of the project or client (required); transactions with any of them are included"`
+	DateRange
+}
+
+// ProjectLine is the total of one category or account in a project report
+type ProjectLine struct {
+	Name   string `json:"name"`
+	Amount string `json:"amount"`
+	Count  int    `json:"count"`
+}
+
+// ProjectTagTotals are the totals of one tag in a project report
+type ProjectTagTotals struct {
+	Tag      string `json:"tag"`
+	Income   string `json:"income"`
+	Expenses string `json:"expenses"`
+	Net      string `json:"net"`
+}
+
+// ProjectStatement is the profit-and-loss statement of a project in one currency
+type ProjectStatement struct {
+	CurrencyCode       string             `json:"currency_code"`
+	Income             string             `json:"income"`
+	Expenses           string             `json:"expenses"`
+	Net                string             `json:"net"` // Income minus expenses
+	IncomeByCategory   []ProjectLine      `json:"income_by_category"`
+	ExpensesByCategory []ProjectLine      `json:"expenses_by_category"`
+	ByAccount          []ProjectLine      `json:"by_account"` // Net per asset account
+	ByTag              []ProjectTagTotals `json:"by_tag,omitempty"`
+}
+
+// ProjectReport is the result of the project_report tool
+type ProjectReport struct {
+	Tags                  []string           `json:"tags"`
+	Start                 string             `json:"start"`
+	End                   string             `json:"end"`
+	Statements            []ProjectStatement `json:"statements"` // One per currency
+	TransactionCount      int                `json:"transaction_count"`
+	TransfersSkipped      int                `json:"transfers_skipped"`
+	TransactionsTruncated bool               `json:"transactions_truncated,omitempty"`
+}
+
+// projectTotals accumulates the amounts of one currency
+type projectTotals struct {
+	income, expenses   decimal
+	incomeByCategory   map[string]*projectLineTotal
+	expensesByCategory map[string]*projectLineTotal
+	byAccount          map[string]*projectLineTotal
+	byTag              map[string]*[2]decimal // Income and expenses
+}
+
+type projectLineTotal struct {
+	amount decimal
+	count  int
+}
+
+// handleProjectReport aggregates the income and expenses of the transactions
+// tagged with a project's tags into a profit-and-loss statement per currency
+func (s *FireflyMCPServer) handleProjectReport(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args ProjectReportArgs,
+) (*mcp.CallToolResult, any, error) {
+	tags := make([]string, 0, len(args.Tags))
+	for _, tag := range args.Tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) == 0 {
+		return newErrorResult("Error: at least one tag is required")
+	}
+	dates, err := s.resolveDateRange(args.DateRange, dateRangeRequired)
+	if err != nil {
+		return newErrorResult(err.Error())
+	}
+
+	transactions, truncated, err := s.fetchTransactions(ctx, req, ListTransactionsArgs{DateRange: DateRange{
+		Start: dates.StartString(),
+		End:   dates.EndString(),
+	}})
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error listing transactions: %v", err))
+	}
+
+	report := buildProjectReport(tags, transactions)
+	report.Start = dates.StartString()
+	report.End = dates.EndString()
+	report.TransactionsTruncated = truncated
+	return newSuccessResult(report)
+}
+
+// buildProjectReport sums the withdrawals and deposits carrying any of the tags.
+// Transfers move money between own accounts and are skipped.
+func buildProjectReport(tags []string, transactions []Transaction) *ProjectReport {
+	report := &ProjectReport{Tags: tags, Statements: []ProjectStatement{}}
+	totals := make(map[string]*projectTotals)
+
+	for _, transaction := range transactions {
+		matched := matchingTags(transaction.Tags, tags)
+		if len(matched) == 0 {
+			continue
+		}
+		if transaction.Type != "withdrawal" && transaction.Type != "deposit" {
+			report.TransfersSkipped++
+			continue
+		}
+		report.TransactionCount++
+
+		currency := totals[transaction.CurrencyCode]
+		if currency == nil {
+			currency = &projectTotals{
+				incomeByCategory:   make(map[string]*projectLineTotal),
+				expensesByCategory: make(map[string]*projectLineTotal),
+				byAccount:          make(map[string]*projectLineTotal),
+				byTag:              make(map[string]*[2]decimal),
+			}
+			totals[transaction.CurrencyCode] = currency
+		}
+
+		amount := parseAmount(transaction.Amount).Abs()
+		category := noCategoryLabel
+		if transaction.CategoryName != nil && *transaction.CategoryName != "" {
+			category = *transaction.CategoryName
+		}
+		for _, tag := range matched {
+			if currency.byTag[tag] == nil {
+				currency.byTag[tag] = &[2]decimal{}
+			}
+		}
+		if transaction.Type == "deposit" {
+			currency.income = currency.income.Add(amount)
+			addProjectLine(currency.incomeByCategory, category, amount)
+			addProjectLine(currency.byAccount, transaction.DestinationName, amount)
+			for _, tag := range matched {
+				currency.byTag[tag][0] = currency.byTag[tag][0].Add(amount)
+			}
+		} else {
+			currency.expenses = currency.expenses.Add(amount)
+			addProjectLine(currency.expensesByCategory, category, amount)
+			addProjectLine(currency.byAccount, transaction.SourceName, amount.Neg())
+			for _, tag := range matched {
+				currency.byTag[tag][1] = currency.byTag[tag][1].Add(amount)
+			}
+		}
+	}
+
+	codes := make([]string, 0, len(totals))
+	for code := range totals {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		currency := totals[code]
+		statement := ProjectStatement{
+			CurrencyCode:       code,
+			Income:             formatAmount(currency.income),
+			Expenses:           formatAmount(currency.expenses),
+			Net:                formatAmount(currency.income.Sub(currency.expenses)),
+			IncomeByCategory:   sortedProjectLines(currency.incomeByCategory),
+			ExpensesByCategory: sortedProjectLines(currency.expensesByCategory),
+			ByAccount:          sortedProjectLines(currency.byAccount),
+		}
+		// Per-tag totals only add information when the project has several tags
+		if len(tags) > 1 {
+			for _, tag := range tags {
+				if amounts, ok := currency.byTag[tag]; ok {
+					statement.ByTag = append(statement.ByTag, ProjectTagTotals{
+						Tag:      tag,
+						Income:   formatAmount(amounts[0]),
+						Expenses: formatAmount(amounts[1]),
+						Net:      formatAmount(amounts[0].Sub(amounts[1])),
+					})
+				}
+			}
+		}
+		report.Statements = append(report.Statements, statement)
+	}
+	return report
+}
+
+// matchingTags returns the report tags a transaction carries, compared without case
+func matchingTags(transactionTags, tags []string) []string {
+	var matched []string
+	for _, tag := range tags {
+		for _, transactionTag := range transactionTags {
+			if strings.EqualFold(transactionTag, tag) {
+				matched = append(matched, tag)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// addProjectLine adds an amount to the line of a category or account
+func addProjectLine(lines map[string]*projectLineTotal, name string, amount decimal) {
+	line := lines[name]
+	if line == nil {
+		line = &projectLineTotal{}
+		lines[name] = line
+	}
+	line.amount = line.amount.Add(amount)
+	line.count++
+}
+
+// sortedProjectLines returns the lines by absolute amount, largest first
+func sortedProjectLines(lines map[string]*projectLineTotal) []ProjectLine {
+	names := make([]string, 0, len(lines))
+	for name := range lines {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(a, b int) bool {
+		if cmp := lines[names[a]].amount.Abs().Cmp(lines[names[b]].amount.Abs()); cmp != 0 {
+			return cmp > 0
+		}
+		return names[a] < names[b]
+	})
+	result := make([]ProjectLine, 0, len(names))
+	for _, name := range names {
+		result = append(result, ProjectLine{Name: name, Amount: formatAmount(lines[name].amount), Count: lines[name].count})
+	}
+	return result
+}
@@ -0,0 +1,90 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildProjectReport(t *testing.T) {
+	consulting := "Consulting"
+	software := "Software"
+	report := buildProjectReport([]string{"acme", "acme-travel"}, []Transaction{
+		{Type: "deposit", Amount: "1500.00", CurrencyCode: "EUR", Tags: []string{"ACME"}, CategoryName: &consulting, DestinationName: "Business"},
+		{Type: "withdrawal", Amount: "200.00", CurrencyCode: "EUR", Tags: []string{"acme-travel"}, SourceName: "Business"},
+		{Type: "withdrawal", Amount: "49.99", CurrencyCode: "EUR", Tags: []string{"acme"}, CategoryName: &software, SourceName: "Credit card"},
+		{Type: "withdrawal", Amount: "30.00", CurrencyCode: "USD", Tags: []string{"acme"}, CategoryName: &software, SourceName: "Credit card"},
+		{Type: "transfer", Amount: "500.00", CurrencyCode: "EUR", Tags: []string{"acme"}},
+		{Type: "withdrawal", Amount: "12.00", CurrencyCode: "EUR", Tags: []string{"other"}},
+	})
+
+	assert.Equal(t, 4, report.TransactionCount)
+	assert.Equal(t, 1, report.TransfersSkipped)
+	require.Len(t, report.Statements, 2)
+
+	eur := report.Statements[0]
+	assert.Equal(t, "EUR", eur.CurrencyCode)
+	assert.Equal(t, "1500.00", eur.Income)
+	assert.Equal(t, "249.99", eur.Expenses)
+	assert.Equal(t, "1250.01", eur.Net)
+	assert.Equal(t, []ProjectLine{{Name: "Consulting", Amount: "1500.00", Count: 1}}, eur.IncomeByCategory)
+	assert.Equal(t, []ProjectLine{
+		{Name: noCategoryLabel, Amount: "200.00", Count: 1},
+		{Name: "Software", Amount: "49.99", Count: 1},
+	}, eur.ExpensesByCategory)
+	assert.Equal(t, []ProjectLine{
+		{Name: "Business", Amount: "1300.00", Count: 2},
+		{Name: "Credit card", Amount: "-49.99", Count: 1},
+	}, eur.ByAccount)
+	assert.Equal(t, []ProjectTagTotals{
+		{Tag: "acme", Income: "1500.00", Expenses: "49.99", Net: "1450.01"},
+		{Tag: "acme-travel", Income: "0.00", Expenses: "200.00", Net: "-200.00"},
+	}, eur.ByTag)
+
+	usd := report.Statements[1]
+	assert.Equal(t, "USD", usd.CurrencyCode)
+	assert.Equal(t, "-30.00", usd.Net)
+}
+
+func TestHandleProjectReport(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		w.Write([]byte(`{"data":[{"type":"transactions","id":"1","attributes":{"transactions":[` +
+			`{"transaction_journal_id":"1","type":"deposit","date":"2024-01-10T00:00:00Z","amount":"800.00",` +
+			`"currency_code":"EUR","description":"Invoice 12","source_id":"5","destination_id":"1",` +
+			`"destination_name":"Business","tags":["acme"]}]}}],` +
+			`"meta":{"pagination":{"total":1,"count":1,"per_page":200,"current_page":1,"total_pages":1}}}`))
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	result, _, err := server.handleProjectReport(ctx, nil, ProjectReportArgs{DateRange: DateRange{Period: "this_year"}})
+	require.NoError(t, err)
+	assert.True(t, result.IsError, "tags are required")
+
+	result, _, err = server.handleProjectReport(ctx, nil, ProjectReportArgs{
+		Tags:      []string{"acme"},
+		DateRange: DateRange{Start: "2024-01-01", End: "2024-03-31"},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var report ProjectReport
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &report))
+	assert.Equal(t, "2024-01-01", report.Start)
+	assert.Equal(t, "2024-03-31", report.End)
+	require.Len(t, report.Statements, 1)
+	assert.Equal(t, "800.00", report.Statements[0].Net)
+	assert.Empty(t, report.Statements[0].ByTag)
+}
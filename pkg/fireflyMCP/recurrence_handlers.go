@@ -4,11 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"time"
 
 	"github.com/dezer32/mcp-firefly-iii/pkg/client"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
-	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
 // Recurrence argument types
@@ -22,12 +20,12 @@ type GetRecurrenceArgs struct {
 }
 
 type ListRecurrenceTransactionsArgs struct {
-	ID    string `json:"id" jsonschema:"Recurrence ID"`
-	Type  string `json:"type,omitempty" jsonschema:"Filter by transaction type"`
-	Start string `json:"start,omitempty" jsonschema:"Start date (YYYY-MM-DD)"`
-	End   string `json:"end,omitempty" jsonschema:"End date (YYYY-MM-DD)"`
-	Limit int    `json:"limit,omitempty" jsonschema:"Maximum number of transactions to return"`
-	Page  int    `json:"page,omitempty" jsonschema:"Page number for pagination (default: 1)"`
+	ID      string `json:"id" jsonschema:"Recurrence ID"`
+	Type    string `json:"type,omitempty" jsonschema:"Filter by transaction type"`
+	Limit   int    `json:"limit,omitempty" jsonschema:"Maximum number of transactions to return"`
+	Page    int    `json:"page,omitempty" jsonschema:"Page number for pagination (default: 1)"`
+	Compact bool   `json:"compact,omitempty" jsonschema:"Return compact transactions: single-split groups flattened and empty fields left out"`
+	DateRange
 }
 
 // handleListRecurrences lists all recurrences in Firefly III
@@ -45,10 +43,7 @@ func (s *FireflyMCPServer) handleListRecurrences(
 	apiParams := &client.ListRecurrenceParams{}
 
 	// Set pagination
-	if args.Limit > 0 {
-		limit := int32(args.Limit)
-		apiParams.Limit = &limit
-	}
+	apiParams.Limit = s.limitParam("list_recurrences", args.Limit)
 	page := int32(args.Page)
 	if page == 0 {
 		page = 1
@@ -69,7 +64,7 @@ func (s *FireflyMCPServer) handleListRecurrences(
 	if resp.StatusCode() != 200 {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
-				&mcp.TextContent{Text: fmt.Sprintf("API error: %s", string(resp.Body))},
+				&mcp.TextContent{Text: fmt.Sprintf("API error: %s", s.upstreamError(resp.Body))},
 			},
 			IsError: true,
 		}, nil, nil
@@ -124,7 +119,7 @@ func (s *FireflyMCPServer) handleGetRecurrence(
 	if resp.StatusCode() != 200 {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
-				&mcp.TextContent{Text: fmt.Sprintf("API error: %s", string(resp.Body))},
+				&mcp.TextContent{Text: fmt.Sprintf("API error: %s", s.upstreamError(resp.Body))},
 			},
 			IsError: true,
 		}, nil, nil
@@ -169,10 +164,7 @@ func (s *FireflyMCPServer) handleListRecurrenceTransactions(
 	apiParams := &client.ListTransactionByRecurrenceParams{}
 
 	// Set pagination
-	if args.Limit > 0 {
-		limit := int32(args.Limit)
-		apiParams.Limit = &limit
-	}
+	apiParams.Limit = s.limitParam("list_recurrence_transactions", args.Limit)
 	page := int32(args.Page)
 	if page == 0 {
 		page = 1
@@ -185,34 +177,12 @@ func (s *FireflyMCPServer) handleListRecurrenceTransactions(
 		apiParams.Type = &filter
 	}
 
-	// Set date filters if provided
-	if args.Start != "" {
-		startDate, err := time.Parse("2006-01-02", args.Start)
-		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: fmt.Sprintf("Invalid start date format: %v", err)},
-				},
-				IsError: true,
-			}, nil, nil
-		}
-		date := openapi_types.Date{Time: startDate}
-		apiParams.Start = &date
-	}
-
-	if args.End != "" {
-		endDate, err := time.Parse("2006-01-02", args.End)
-		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: fmt.Sprintf("Invalid end date format: %v", err)},
-				},
-				IsError: true,
-			}, nil, nil
-		}
-		date := openapi_types.Date{Time: endDate}
-		apiParams.End = &date
+	dates, err := s.resolveDateRange(args.DateRange, dateRangeOpen)
+	if err != nil {
+		return newErrorResult(err.Error())
 	}
+	apiParams.Start = dates.Start
+	apiParams.End = dates.End
 
 	// Call the API
 	resp, err := apiClient.ListTransactionByRecurrenceWithResponse(ctx, args.ID, apiParams)
@@ -228,7 +198,7 @@ func (s *FireflyMCPServer) handleListRecurrenceTransactions(
 	if resp.StatusCode() != 200 {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
-				&mcp.TextContent{Text: fmt.Sprintf("API error: %s", string(resp.Body))},
+				&mcp.TextContent{Text: fmt.Sprintf("API error: %s", s.upstreamError(resp.Body))},
 			},
 			IsError: true,
 		}, nil, nil
@@ -236,21 +206,5 @@ func (s *FireflyMCPServer) handleListRecurrenceTransactions(
 
 	// Map the response
 	transactionList := mapTransactionArrayToTransactionList(resp.ApplicationvndApiJSON200)
-
-	// Convert to JSON for response
-	jsonData, err := json.Marshal(transactionList)
-	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{Text: fmt.Sprintf("Error marshaling response: %v", err)},
-			},
-			IsError: true,
-		}, nil, nil
-	}
-
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			&mcp.TextContent{Text: string(jsonData)},
-		},
-	}, nil, nil
+	return newTransactionListResult(transactionList, args.Compact)
 }
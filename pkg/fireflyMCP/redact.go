@@ -0,0 +1,96 @@
+package fireflyMCP
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// redactedValue replaces the value of redacted log fields
+const redactedValue = "[REDACTED]"
+
+// defaultRedactFields are the log fields redacted when logging.redact_fields is not configured
+var defaultRedactFields = []string{"iban", "bic", "account_number", "notes"}
+
+// ibanPattern matches IBANs in free text, e.g. in search queries
+var ibanPattern = regexp.MustCompile(`\b[A-Z]{2}[0-9]{2}[A-Z0-9]{11,30}\b`)
+
+// Redactor masks sensitive financial identifiers in log records. Fields are
+// matched case-insensitively by name, including prefixed variants such as
+// "source_iban" for "iban". IBANs are additionally masked in every string value.
+type Redactor struct {
+	fields map[string]bool
+}
+
+// NewRedactor creates a Redactor for the given field names
+func NewRedactor(fields []string) *Redactor {
+	r := &Redactor{fields: make(map[string]bool, len(fields))}
+	for _, field := range fields {
+		if field = strings.ToLower(strings.TrimSpace(field)); field != "" {
+			r.fields[field] = true
+		}
+	}
+	return r
+}
+
+// ReplaceAttr redacts a log attribute. It is meant to be used as
+// slog.HandlerOptions.ReplaceAttr.
+func (r *Redactor) ReplaceAttr(_ []string, a slog.Attr) slog.Attr {
+	if r.isRedacted(a.Key) {
+		return slog.String(a.Key, redactedValue)
+	}
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, maskIBANs(a.Value.String()))
+	case slog.KindAny:
+		return slog.Any(a.Key, r.redactValue(a.Value.Any()))
+	}
+	return a
+}
+
+// isRedacted reports whether the field with the given name is redacted
+func (r *Redactor) isRedacted(key string) bool {
+	key = strings.ToLower(key)
+	if r.fields[key] {
+		return true
+	}
+	for field := range r.fields {
+		if strings.HasSuffix(key, "_"+field) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactValue redacts JSON-like values (decoded maps and slices) recursively.
+// Other values are returned unchanged.
+func (r *Redactor) redactValue(value any) any {
+	switch v := value.(type) {
+	case string:
+		return maskIBANs(v)
+	case map[string]any:
+		redacted := make(map[string]any, len(v))
+		for key, item := range v {
+			if r.isRedacted(key) {
+				redacted[key] = redactedValue
+				continue
+			}
+			redacted[key] = r.redactValue(item)
+		}
+		return redacted
+	case []any:
+		redacted := make([]any, len(v))
+		for i, item := range v {
+			redacted[i] = r.redactValue(item)
+		}
+		return redacted
+	}
+	return value
+}
+
+// maskIBANs masks IBANs in a string, keeping the country code and the last four characters
+func maskIBANs(s string) string {
+	return ibanPattern.ReplaceAllStringFunc(s, func(iban string) string {
+		return iban[:2] + "****" + iban[len(iban)-4:]
+	})
+}
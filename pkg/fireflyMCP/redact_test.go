@@ -0,0 +1,92 @@
+package fireflyMCP
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRedactedLogger(buf *bytes.Buffer, fields []string) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{
+		Level:       slog.LevelDebug,
+		ReplaceAttr: NewRedactor(fields).ReplaceAttr,
+	}))
+}
+
+func TestRedactor_ReplaceAttr(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newRedactedLogger(&buf, defaultRedactFields)
+
+	logger.Info("test",
+		"iban", "DE89370400440532013000",
+		"Source_IBAN", "NL91ABNA0417164300",
+		"query", "payments to DE89370400440532013000",
+		"amount", 12.5,
+		"arguments", map[string]any{
+			"notes": "Doctor appointment",
+			"transactions": []any{
+				map[string]any{"description": "Rent", "destination_account_number": "123456789"},
+			},
+		},
+	)
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, redactedValue, record["iban"])
+	assert.Equal(t, redactedValue, record["Source_IBAN"])
+	assert.Equal(t, "payments to DE****3000", record["query"])
+	assert.Equal(t, 12.5, record["amount"])
+
+	arguments := record["arguments"].(map[string]any)
+	assert.Equal(t, redactedValue, arguments["notes"])
+	split := arguments["transactions"].([]any)[0].(map[string]any)
+	assert.Equal(t, "Rent", split["description"])
+	assert.Equal(t, redactedValue, split["destination_account_number"])
+}
+
+func TestRedactor_ConfigurableFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newRedactedLogger(&buf, []string{" Description "})
+
+	logger.Info("test", "description", "Rent", "notes", "visible")
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, redactedValue, record["description"])
+	assert.Equal(t, "visible", record["notes"])
+}
+
+func TestMaskIBANs(t *testing.T) {
+	assert.Equal(t, "GB****1234 and NL****4300", maskIBANs("GB82WEST12345698761234 and NL91ABNA0417164300"))
+	assert.Equal(t, "no identifiers here", maskIBANs("no identifiers here"))
+	assert.Equal(t, "DE123", maskIBANs("DE123"))
+}
+
+func TestToolCallLoggingIsRedacted(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(newRedactedLogger(&buf, defaultRedactFields))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	type args struct {
+		Name  string `json:"name"`
+		Notes string `json:"notes"`
+	}
+	handler := withToolCallLogging("test_tool",
+		func(ctx context.Context, req *mcp.CallToolRequest, args args) (*mcp.CallToolResult, any, error) {
+			return newSuccessResult(args.Name)
+		})
+
+	_, _, err := handler(context.Background(), nil, args{Name: "Checking", Notes: "PIN is 1234"})
+	require.NoError(t, err)
+
+	assert.Contains(t, buf.String(), `"tool":"test_tool"`)
+	assert.Contains(t, buf.String(), `"name":"Checking"`)
+	assert.NotContains(t, buf.String(), "PIN is 1234")
+}
@@ -0,0 +1,211 @@
+package fireflyMCP
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const replHelp = `Commands:
+  tools [filter]          list the tools, optionally those whose name contains filter
+  schema <tool>           show the input and output schema of a tool
+  call <tool> [json]      call a tool with JSON arguments, e.g. call list_accounts {"limit": 5}
+  <tool> [json]           same as call
+  dryrun [on|off]         show the calls of tools that change data instead of making them
+  help                    show this help
+  quit                    leave the REPL
+`
+
+// REPL is an interactive prompt that calls the tools of a server the way an MCP
+// client does, for tool development and troubleshooting
+type REPL struct {
+	session *mcp.ClientSession
+	tools   map[string]*mcp.Tool
+	dryRun  bool
+	out     io.Writer
+}
+
+// RunREPL connects to the server over an in-memory transport and reads commands
+// from in until it ends or quit is entered
+func RunREPL(ctx context.Context, server *FireflyMCPServer, in io.Reader, out io.Writer) error {
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.MCPServer().Connect(ctx, serverTransport, nil)
+	if err != nil {
+		return err
+	}
+	defer serverSession.Close()
+	client := mcp.NewClient(&mcp.Implementation{Name: "firefly-iii-repl", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	repl := &REPL{session: session, tools: make(map[string]*mcp.Tool), out: out}
+	for tool, err := range session.Tools(ctx, nil) {
+		if err != nil {
+			return fmt.Errorf("error listing tools: %w", err)
+		}
+		repl.tools[tool.Name] = tool
+	}
+
+	fmt.Fprintf(out, "Connected to %s, %d tools. Type help for the commands.\n", server.Config().Server.URL, len(repl.tools))
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for {
+		fmt.Fprint(out, "firefly> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+			return scanner.Err()
+		}
+		if !repl.Execute(ctx, scanner.Text()) {
+			return nil
+		}
+	}
+}
+
+// Execute runs one command line. Reports false when the REPL should stop.
+func (r *REPL) Execute(ctx context.Context, line string) bool {
+	command, rest, _ := strings.Cut(strings.TrimSpace(line), " ")
+	rest = strings.TrimSpace(rest)
+	switch command {
+	case "":
+	case "quit", "exit":
+		return false
+	case "help", "?":
+		fmt.Fprint(r.out, replHelp)
+	case "tools":
+		r.listTools(rest)
+	case "schema":
+		r.showSchema(rest)
+	case "dryrun":
+		r.setDryRun(rest)
+	case "call":
+		name, args, _ := strings.Cut(rest, " ")
+		r.call(ctx, name, strings.TrimSpace(args))
+	default:
+		r.call(ctx, command, rest)
+	}
+	return true
+}
+
+func (r *REPL) listTools(filter string) {
+	names := make([]string, 0, len(r.tools))
+	for name := range r.tools {
+		if strings.Contains(name, filter) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		mode := "read"
+		if !isReadOnly(r.tools[name]) {
+			mode = "write"
+		}
+		description, _, _ := strings.Cut(r.tools[name].Description, "\n")
+		fmt.Fprintf(r.out, "%-36s %-5s %s\n", name, mode, description)
+	}
+}
+
+func (r *REPL) showSchema(name string) {
+	tool, ok := r.tool(name)
+	if !ok {
+		return
+	}
+	fmt.Fprintf(r.out, "%s\n\nInput:\n", tool.Description)
+	r.printJSON(tool.InputSchema)
+	if tool.OutputSchema != nil {
+		fmt.Fprintln(r.out, "Output:")
+		r.printJSON(tool.OutputSchema)
+	}
+}
+
+func (r *REPL) setDryRun(value string) {
+	switch value {
+	case "":
+		r.dryRun = !r.dryRun
+	case "on":
+		r.dryRun = true
+	case "off":
+		r.dryRun = false
+	default:
+		fmt.Fprintln(r.out, "usage: dryrun [on|off]")
+		return
+	}
+	state := "off"
+	if r.dryRun {
+		state = "on"
+	}
+	fmt.Fprintf(r.out, "dry-run %s\n", state)
+}
+
+func (r *REPL) call(ctx context.Context, name, rawArgs string) {
+	tool, ok := r.tool(name)
+	if !ok {
+		return
+	}
+	args := map[string]any{}
+	if rawArgs != "" {
+		if err := json.Unmarshal([]byte(rawArgs), &args); err != nil {
+			fmt.Fprintf(r.out, "invalid arguments, expected a JSON object: %v\n", err)
+			return
+		}
+	}
+	if r.dryRun && !isReadOnly(tool) {
+		fmt.Fprintf(r.out, "dry-run: not calling %s, which changes data, with\n", name)
+		r.printJSON(args)
+		return
+	}
+
+	result, err := r.session.CallTool(ctx, &mcp.CallToolParams{Name: name, Arguments: args})
+	if err != nil {
+		fmt.Fprintf(r.out, "error: %v\n", err)
+		return
+	}
+	if result.IsError {
+		fmt.Fprint(r.out, "tool error: ")
+	}
+	for _, content := range result.Content {
+		if text, ok := content.(*mcp.TextContent); ok {
+			r.printText(text.Text)
+		}
+	}
+}
+
+// tool looks up a tool by name, printing an error when there is none
+func (r *REPL) tool(name string) (*mcp.Tool, bool) {
+	tool, ok := r.tools[name]
+	if !ok {
+		fmt.Fprintf(r.out, "unknown tool or command %q, type help for the commands\n", name)
+	}
+	return tool, ok
+}
+
+// printText prints a tool result, indenting it when it is JSON
+func (r *REPL) printText(text string) {
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, []byte(text), "", "  "); err == nil {
+		text = indented.String()
+	}
+	fmt.Fprintln(r.out, text)
+}
+
+func (r *REPL) printJSON(value any) {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		fmt.Fprintf(r.out, "error: %v\n", err)
+		return
+	}
+	fmt.Fprintln(r.out, string(data))
+}
+
+func isReadOnly(tool *mcp.Tool) bool {
+	return tool.Annotations != nil && tool.Annotations.ReadOnlyHint
+}
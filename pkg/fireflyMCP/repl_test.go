@@ -0,0 +1,64 @@
+package fireflyMCP
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunREPL(t *testing.T) {
+	var stored atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/accounts":
+			w.Write([]byte(`{"data":[{"type":"accounts","id":"1","attributes":{"name":"Checking","type":"asset"}}],` +
+				`"meta":{"pagination":{"total":1,"count":1,"per_page":5,"current_page":1,"total_pages":1}}}`))
+		case r.Method == http.MethodPost:
+			stored.Add(1)
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	input := strings.Join([]string{
+		"tools list_acc",
+		"schema list_accounts",
+		`call list_accounts {"limit": 5}`,
+		"list_accounts not-json",
+		"dryrun on",
+		`store_transaction {"transactions": [{"type": "withdrawal", "amount": "4.50", "description": "Coffee"}]}`,
+		"no_such_tool",
+		"quit",
+		"list_accounts",
+	}, "\n")
+	var out bytes.Buffer
+	require.NoError(t, RunREPL(context.Background(), server, strings.NewReader(input), &out))
+	output := out.String()
+
+	assert.Contains(t, output, "list_accounts")
+	assert.Contains(t, output, "read ")
+	assert.Contains(t, output, "Input:")
+	assert.Contains(t, output, `"name": "Checking"`, "results are pretty-printed")
+	assert.Contains(t, output, "invalid arguments, expected a JSON object")
+	assert.Contains(t, output, "dry-run on")
+	assert.Contains(t, output, "dry-run: not calling store_transaction")
+	assert.Contains(t, output, `"description": "Coffee"`)
+	assert.Contains(t, output, `unknown tool or command "no_such_tool"`)
+	assert.Equal(t, int32(0), stored.Load(), "dry-run must not call tools that change data")
+	assert.Equal(t, 1, strings.Count(output, `"name": "Checking"`), "commands after quit are not run")
+}
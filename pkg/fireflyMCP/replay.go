@@ -0,0 +1,203 @@
+package fireflyMCP
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ReplayCall is one recorded tool call, a line of the replay file
+type ReplayCall struct {
+	Time      string          `json:"time"`
+	Tool      string          `json:"tool"`
+	Arguments json.RawMessage `json:"arguments"`
+	IsError   bool            `json:"is_error,omitempty"`
+	Result    string          `json:"result,omitempty"` // Text content of the result, or the error of a failed call
+}
+
+// replayRecorder appends the tool calls of clients to the replay file
+type replayRecorder struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// openReplayRecorder opens the replay file for appending. The file holds the
+// arguments and results of calls unredacted, so it is only readable by its owner.
+func openReplayRecorder(path string) (*replayRecorder, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &replayRecorder{file: file}, nil
+}
+
+// record appends a call; failures are logged by the caller
+func (r *replayRecorder) record(call ReplayCall) error {
+	line, err := json.Marshal(call)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, err = r.file.Write(append(line, '\n'))
+	return err
+}
+
+func (r *replayRecorder) close() error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
+
+// withReplayRecording records the calls of a tool made by clients to the
+// replay file, when replay.record is set
+func withReplayRecording[In any](s *FireflyMCPServer, name string, handler mcp.ToolHandlerFor[In, any]) mcp.ToolHandlerFor[In, any] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, args In) (*mcp.CallToolResult, any, error) {
+		result, out, err := handler(ctx, req, args)
+		if s.replay == nil {
+			return result, out, err
+		}
+		call := ReplayCall{Time: s.now().Format(time.RFC3339), Tool: name}
+		if req != nil && req.Params != nil && len(req.Params.Arguments) > 0 {
+			call.Arguments = req.Params.Arguments
+		} else if raw, marshalErr := json.Marshal(args); marshalErr == nil {
+			call.Arguments = raw
+		}
+		switch {
+		case err != nil:
+			call.IsError, call.Result = true, err.Error()
+		case result != nil:
+			call.IsError, call.Result = result.IsError, resultText(result)
+		}
+		if recordErr := s.replay.record(call); recordErr != nil {
+			slog.Warn("Failed to record a tool call in the replay file", "tool", name, "error", recordErr)
+		}
+		return result, out, err
+	}
+}
+
+// resultText joins the text contents of a tool result
+func resultText(result *mcp.CallToolResult) string {
+	var texts []string
+	for _, content := range result.Content {
+		if text, ok := content.(*mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// ReadReplayFile reads the calls of a replay file, skipping empty lines
+func ReadReplayFile(in io.Reader) ([]ReplayCall, error) {
+	var calls []ReplayCall
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var call ReplayCall
+		if err := json.Unmarshal(scanner.Bytes(), &call); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		calls = append(calls, call)
+	}
+	return calls, scanner.Err()
+}
+
+// ReplayOptions adjust RunReplay
+type ReplayOptions struct {
+	Writes bool // Also replay tools that change data; they are skipped otherwise
+}
+
+// ReplaySummary counts the outcomes of a replay
+type ReplaySummary struct {
+	Same    int
+	Differ  int
+	Skipped int
+}
+
+// RunReplay calls the recorded tools again, the way an MCP client does, and
+// reports for each call whether the result matches the recorded one. Results
+// are compared as JSON when both are JSON, so key order and whitespace do not
+// matter. Point server.url at a mock or a copy of the instance to reproduce a
+// bug; the summary reports differing calls, e.g. for git bisect run.
+func RunReplay(ctx context.Context, server *FireflyMCPServer, calls []ReplayCall, options ReplayOptions, out io.Writer) (ReplaySummary, error) {
+	var summary ReplaySummary
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.MCPServer().Connect(ctx, serverTransport, nil)
+	if err != nil {
+		return summary, err
+	}
+	defer serverSession.Close()
+	client := mcp.NewClient(&mcp.Implementation{Name: "firefly-iii-replay", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		return summary, err
+	}
+	defer session.Close()
+
+	for i, call := range calls {
+		registered, ok := server.tools[call.Tool]
+		switch {
+		case !ok:
+			fmt.Fprintf(out, "#%d %s: skipped, unknown tool\n", i+1, call.Tool)
+			summary.Skipped++
+			continue
+		case !options.Writes && !isReadOnly(registered.tool):
+			fmt.Fprintf(out, "#%d %s: skipped, changes data\n", i+1, call.Tool)
+			summary.Skipped++
+			continue
+		}
+
+		var args any = map[string]any{}
+		if len(call.Arguments) > 0 {
+			args = call.Arguments
+		}
+		replayed := ReplayCall{Tool: call.Tool}
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: call.Tool, Arguments: args})
+		if err != nil {
+			replayed.IsError, replayed.Result = true, err.Error()
+		} else {
+			replayed.IsError, replayed.Result = result.IsError, resultText(result)
+		}
+
+		if replayed.IsError == call.IsError && sameReplayResult(call.Result, replayed.Result) {
+			fmt.Fprintf(out, "#%d %s: same\n", i+1, call.Tool)
+			summary.Same++
+			continue
+		}
+		summary.Differ++
+		fmt.Fprintf(out, "#%d %s: differs\n  recorded (error: %t): %s\n  replayed (error: %t): %s\n",
+			i+1, call.Tool, call.IsError, call.Result, replayed.IsError, replayed.Result)
+	}
+	fmt.Fprintf(out, "%d same, %d differ, %d skipped\n", summary.Same, summary.Differ, summary.Skipped)
+	return summary, nil
+}
+
+// sameReplayResult compares a recorded and a replayed result text
+func sameReplayResult(recorded, replayed string) bool {
+	if recorded == replayed {
+		return true
+	}
+	var a, b any
+	if json.Unmarshal([]byte(recorded), &a) != nil || json.Unmarshal([]byte(replayed), &b) != nil {
+		return false
+	}
+	x, _ := json.Marshal(a)
+	y, _ := json.Marshal(b)
+	return bytes.Equal(x, y)
+}
@@ -0,0 +1,103 @@
+package fireflyMCP
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplay_RecordAndReplay(t *testing.T) {
+	var name atomic.Value
+	name.Store("Checking")
+	var stored atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/accounts":
+			w.Write([]byte(`{"data":[{"type":"accounts","id":"1","attributes":{"name":"` + name.Load().(string) + `","type":"asset"}}],` +
+				`"meta":{"pagination":{"total":1,"count":1,"per_page":5,"current_page":1,"total_pages":1}}}`))
+		case r.Method == http.MethodPost:
+			stored.Add(1)
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	path := filepath.Join(t.TempDir(), "replay.jsonl")
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	config.Replay.Record = path
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	input := strings.Join([]string{
+		`list_accounts {"limit": 5}`,
+		`store_transaction {"transactions": [{"type": "withdrawal", "date": "2024-03-01", "amount": "4.50", "description": "Coffee", "source_id": "1", "destination_name": "Cafe"}]}`,
+		`list_accounts {"limit": 5, "type": "asset"}`,
+	}, "\n")
+	require.NoError(t, RunREPL(context.Background(), server, strings.NewReader(input), &bytes.Buffer{}))
+	require.NoError(t, server.Close())
+	require.Equal(t, int32(1), stored.Load())
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	calls, err := ReadReplayFile(file)
+	file.Close()
+	require.NoError(t, err)
+	require.Len(t, calls, 3)
+	assert.Equal(t, "list_accounts", calls[0].Tool)
+	assert.JSONEq(t, `{"limit": 5}`, string(calls[0].Arguments))
+	assert.Contains(t, calls[0].Result, `"Checking"`)
+	assert.Equal(t, "store_transaction", calls[1].Tool)
+	assert.True(t, calls[1].IsError)
+
+	// Replays do not record themselves and skip tools that change data
+	config = newPluginTestConfig()
+	config.Server.URL = ts.URL
+	server, err = NewFireflyMCPServer(config)
+	require.NoError(t, err)
+	defer server.Close()
+	var out bytes.Buffer
+	summary, err := RunReplay(context.Background(), server, calls, ReplayOptions{}, &out)
+	require.NoError(t, err)
+	assert.Equal(t, ReplaySummary{Same: 2, Skipped: 1}, summary, out.String())
+	assert.Contains(t, out.String(), "#2 store_transaction: skipped, changes data")
+	assert.Equal(t, int32(1), stored.Load())
+
+	summary, err = RunReplay(context.Background(), server, calls[1:2], ReplayOptions{Writes: true}, &bytes.Buffer{})
+	require.NoError(t, err)
+	assert.Equal(t, ReplaySummary{Same: 1}, summary)
+	assert.Equal(t, int32(2), stored.Load())
+
+	// A changed instance shows up as a differing result
+	name.Store("Savings")
+	out.Reset()
+	summary, err = RunReplay(context.Background(), server, calls, ReplayOptions{}, &out)
+	require.NoError(t, err)
+	assert.Equal(t, 2, summary.Differ)
+	assert.Contains(t, out.String(), "#1 list_accounts: differs")
+	assert.Contains(t, out.String(), `"Savings"`)
+}
+
+func TestReadReplayFile_Errors(t *testing.T) {
+	calls, err := ReadReplayFile(strings.NewReader("\n" + `{"tool":"list_tags","arguments":{}}` + "\n\n"))
+	require.NoError(t, err)
+	assert.Len(t, calls, 1)
+
+	_, err = ReadReplayFile(strings.NewReader(`{"tool":"list_tags"}` + "\nnot json\n"))
+	assert.ErrorContains(t, err, "line 2")
+}
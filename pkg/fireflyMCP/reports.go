@@ -0,0 +1,313 @@
+package fireflyMCP
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// reportNamePattern restricts report tool and argument names to snake_case identifiers
+var reportNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// Report argument types. In date arguments, today, yesterday and tomorrow are
+// resolved to dates in the configured timezone.
+const (
+	reportArgString = "string"
+	reportArgDate   = "date"
+)
+
+// reportData is the data available to report templates
+type reportData struct {
+	Args  map[string]string // Report arguments with defaults applied and relative dates resolved
+	Steps map[string]any    // Decoded JSON results of the steps run so far, by step name
+}
+
+// reportFuncs are the helper functions available to report templates
+var reportFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		data, err := json.MarshalIndent(v, "", "  ")
+		return string(data), err
+	},
+	"decimal": templateDecimal,
+	"float":   templateDecimal, // Name of decimal before amounts were exact
+	"add":     func(a, b any) decimal { return templateDecimal(a).Add(templateDecimal(b)) },
+	"sub":     func(a, b any) decimal { return templateDecimal(a).Sub(templateDecimal(b)) },
+	"amount":  func(v any) string { return formatAmount(templateDecimal(v)) },
+}
+
+// templateDecimal converts a template value to an exact decimal: amounts
+// (strings), JSON numbers, integer constants and results of add and sub.
+// Anything else is zero.
+func templateDecimal(v any) decimal {
+	switch value := v.(type) {
+	case decimal:
+		return value
+	case string:
+		return parseAmount(value)
+	case json.Number:
+		return parseAmount(value.String())
+	case float64:
+		// The shortest representation is the number as written in the JSON
+		return parseAmount(strconv.FormatFloat(value, 'f', -1, 64))
+	case int:
+		return decimalFromInt(int64(value))
+	case int64:
+		return decimalFromInt(value)
+	}
+	return decimal{}
+}
+
+// stepName returns the name under which a step's result is available to templates
+func (step ReportStep) stepName() string {
+	if step.Name != "" {
+		return step.Name
+	}
+	return step.Tool
+}
+
+// validateReports checks the report definitions for structural errors.
+// Whether the referenced tools exist is checked when the reports are registered.
+func validateReports(reports []ReportDefinition) error {
+	names := make(map[string]bool)
+	for i, report := range reports {
+		if !reportNamePattern.MatchString(report.Name) {
+			return fmt.Errorf("reports[%d].name %q must be snake_case (a-z, 0-9, _)", i, report.Name)
+		}
+		if names[report.Name] {
+			return fmt.Errorf("reports[%d].name %q is defined twice", i, report.Name)
+		}
+		names[report.Name] = true
+
+		argNames := make(map[string]bool)
+		for j, arg := range report.Arguments {
+			if !reportNamePattern.MatchString(arg.Name) {
+				return fmt.Errorf("reports[%d].arguments[%d].name %q must be snake_case", i, j, arg.Name)
+			}
+			if argNames[arg.Name] {
+				return fmt.Errorf("reports[%d].arguments[%d].name %q is defined twice", i, j, arg.Name)
+			}
+			if arg.Type != "" && arg.Type != reportArgString && arg.Type != reportArgDate {
+				return fmt.Errorf("reports[%d].arguments[%d].type %q must be string or date", i, j, arg.Type)
+			}
+			argNames[arg.Name] = true
+		}
+
+		if len(report.Steps) == 0 {
+			return fmt.Errorf("reports[%d] (%s) must have at least one step", i, report.Name)
+		}
+		stepNames := make(map[string]bool)
+		for j, step := range report.Steps {
+			if step.Tool == "" {
+				return fmt.Errorf("reports[%d].steps[%d].tool is required", i, j)
+			}
+			if stepNames[step.stepName()] {
+				return fmt.Errorf("reports[%d].steps[%d] name %q is used twice", i, j, step.stepName())
+			}
+			stepNames[step.stepName()] = true
+		}
+
+		if _, err := template.New(report.Name).Funcs(reportFuncs).Parse(report.Template); err != nil {
+			return fmt.Errorf("reports[%d].template: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// registerReports registers the custom report tools defined in the configuration
+func (s *FireflyMCPServer) registerReports() error {
+	config := s.currentConfig()
+	if config == nil {
+		return nil
+	}
+	for _, report := range config.Reports {
+		if s.hasTool(report.Name) {
+			return fmt.Errorf("report %q: tool with the same name is already registered", report.Name)
+		}
+		for _, step := range report.Steps {
+			if !s.hasTool(step.Tool) {
+				return fmt.Errorf("report %q: step %q uses unknown tool %q", report.Name, step.stepName(), step.Tool)
+			}
+		}
+
+		tmpl, err := template.New(report.Name).Funcs(reportFuncs).Parse(report.Template)
+		if err != nil {
+			return fmt.Errorf("report %q: %w", report.Name, err)
+		}
+
+		addTool(
+			s, &mcp.Tool{
+				Name:        report.Name,
+				Description: report.Description,
+				InputSchema: reportInputSchema(report),
+				Annotations: s.reportAnnotations(report),
+			}, s.reportHandler(report, tmpl),
+		)
+	}
+	return nil
+}
+
+// reportAnnotations marks a report read-only if all its steps are read-only.
+// Reports with a write step are treated as destructive, since the step could be any tool.
+func (s *FireflyMCPServer) reportAnnotations(report ReportDefinition) *mcp.ToolAnnotations {
+	for _, step := range report.Steps {
+		if !s.isReadOnlyTool(step.Tool) {
+			return destructiveAnnotations(false)
+		}
+	}
+	return readOnlyAnnotations()
+}
+
+// reportInputSchema builds the input schema from the declared report arguments
+func reportInputSchema(report ReportDefinition) *jsonschema.Schema {
+	schema := &jsonschema.Schema{
+		Type:       "object",
+		Properties: make(map[string]*jsonschema.Schema, len(report.Arguments)),
+	}
+	for _, arg := range report.Arguments {
+		description := arg.Description
+		if arg.Type == reportArgDate {
+			description = strings.TrimSpace(description + " (YYYY-MM-DD, today, yesterday or tomorrow)")
+		}
+		if arg.Default != "" {
+			description += fmt.Sprintf(" (default: %s)", arg.Default)
+		}
+		schema.Properties[arg.Name] = &jsonschema.Schema{Type: "string", Description: description}
+		if arg.Required {
+			schema.Required = append(schema.Required, arg.Name)
+		}
+	}
+	return schema
+}
+
+// reportHandler returns the handler running a report's steps and rendering its template
+func (s *FireflyMCPServer) reportHandler(
+	report ReportDefinition,
+	tmpl *template.Template,
+) mcp.ToolHandlerFor[map[string]any, any] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, input map[string]any) (*mcp.CallToolResult, any, error) {
+		data := reportData{
+			Args:  make(map[string]string, len(report.Arguments)),
+			Steps: make(map[string]any, len(report.Steps)),
+		}
+		for _, arg := range report.Arguments {
+			value, _ := input[arg.Name].(string)
+			if value == "" {
+				value = arg.Default
+			}
+			if value == "" && arg.Required {
+				return newErrorResult(fmt.Sprintf("Error: %s is required", arg.Name))
+			}
+			if arg.Type == reportArgDate {
+				value = s.resolveRelativeDate(value)
+			}
+			data.Args[arg.Name] = value
+		}
+
+		for _, step := range report.Steps {
+			result, err := s.runReportStep(ctx, req, step, data)
+			if err != nil {
+				return newErrorResult(fmt.Sprintf("Error in report step %s (%s): %v", step.stepName(), step.Tool, err))
+			}
+			data.Steps[step.stepName()] = result
+		}
+
+		if report.Template == "" {
+			return newSuccessResult(data.Steps)
+		}
+
+		var out bytes.Buffer
+		if err := tmpl.Execute(&out, data); err != nil {
+			return newErrorResult(fmt.Sprintf("Error rendering report: %v", err))
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: out.String()},
+			},
+		}, nil, nil
+	}
+}
+
+// runReportStep renders a step's arguments, calls its tool and decodes the result
+func (s *FireflyMCPServer) runReportStep(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	step ReportStep,
+	data reportData,
+) (any, error) {
+	args, err := renderReportValue(step.Args, data)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(args)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.tools[step.Tool].invoke(ctx, req, raw)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil || len(result.Content) == 0 {
+		return nil, nil
+	}
+	text, ok := result.Content[0].(*mcp.TextContent)
+	if !ok {
+		return nil, fmt.Errorf("unexpected tool result content")
+	}
+	if result.IsError {
+		return nil, fmt.Errorf("%s", text.Text)
+	}
+
+	var decoded any
+	if err := json.Unmarshal([]byte(text.Text), &decoded); err != nil {
+		return text.Text, nil
+	}
+	return decoded, nil
+}
+
+// renderReportValue renders every string in a step argument value as a template.
+// Non-string values (numbers, booleans) are passed through unchanged.
+func renderReportValue(value any, data reportData) (any, error) {
+	switch v := value.(type) {
+	case string:
+		tmpl, err := template.New("arg").Funcs(reportFuncs).Parse(v)
+		if err != nil {
+			return nil, err
+		}
+		var out bytes.Buffer
+		if err := tmpl.Execute(&out, data); err != nil {
+			return nil, err
+		}
+		return out.String(), nil
+	case map[string]any:
+		rendered := make(map[string]any, len(v))
+		for key, item := range v {
+			r, err := renderReportValue(item, data)
+			if err != nil {
+				return nil, err
+			}
+			rendered[key] = r
+		}
+		return rendered, nil
+	case []any:
+		rendered := make([]any, len(v))
+		for i, item := range v {
+			r, err := renderReportValue(item, data)
+			if err != nil {
+				return nil, err
+			}
+			rendered[i] = r
+		}
+		return rendered, nil
+	default:
+		return value, nil
+	}
+}
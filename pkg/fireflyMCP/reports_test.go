@@ -0,0 +1,231 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTotalsArgs struct {
+	Start    string   `json:"start"`
+	Accounts []string `json:"accounts"`
+	Limit    int      `json:"limit"`
+}
+
+// withFakeTotalsTool registers a plugin tool that echoes its arguments with a total
+func withFakeTotalsTool(t *testing.T) {
+	withPlugins(t, &testPlugin{
+		name: "fake",
+		register: func(h *PluginHost) error {
+			return AddPluginTool(h, &mcp.Tool{Name: "fake_totals", Description: "Fake totals"},
+				func(ctx context.Context, req *mcp.CallToolRequest, args fakeTotalsArgs) (*mcp.CallToolResult, any, error) {
+					if args.Start == "fail" {
+						return newErrorResult("upstream failed")
+					}
+					return newSuccessResult(map[string]any{
+						"start":    args.Start,
+						"accounts": args.Accounts,
+						"limit":    args.Limit,
+						"total":    "12.50",
+					})
+				})
+		},
+	})
+}
+
+func newReportTestConfig(reports ...ReportDefinition) *Config {
+	config := newPluginTestConfig()
+	config.Reports = reports
+	return config
+}
+
+func callReport(t *testing.T, server *FireflyMCPServer, name string, args map[string]any) *mcp.CallToolResult {
+	raw, err := json.Marshal(args)
+	require.NoError(t, err)
+	result, err := server.tools[name].invoke(context.Background(), nil, raw)
+	require.NoError(t, err)
+	return result
+}
+
+func TestReportTool(t *testing.T) {
+	withFakeTotalsTool(t)
+	server, err := NewFireflyMCPServer(newReportTestConfig(ReportDefinition{
+		Name:        "grocery_report",
+		Description: "Groceries spending",
+		Arguments: []ReportArgument{
+			{Name: "start", Required: true},
+			{Name: "account", Default: "joint card"},
+		},
+		Steps: []ReportStep{
+			{
+				Name: "totals",
+				Tool: "fake_totals",
+				Args: map[string]any{"start": "{{.Args.start}}", "accounts": []any{"{{.Args.account}}"}, "limit": 5},
+			},
+		},
+		Template: `{{with .Steps.totals}}{{.start}} {{index .accounts 0}} {{.limit}}: {{amount (add (float .total) 1)}}{{end}}`,
+	}))
+	require.NoError(t, err)
+	require.True(t, server.hasTool("grocery_report"))
+
+	result := callReport(t, server, "grocery_report", map[string]any{"start": "2024-01-01"})
+	require.False(t, result.IsError)
+	assert.Equal(t, "2024-01-01 joint card 5: 13.50", result.Content[0].(*mcp.TextContent).Text)
+
+	result = callReport(t, server, "grocery_report", map[string]any{})
+	assert.True(t, result.IsError)
+	assert.Equal(t, "Error: start is required", result.Content[0].(*mcp.TextContent).Text)
+
+	result = callReport(t, server, "grocery_report", map[string]any{"start": "fail"})
+	assert.True(t, result.IsError)
+	assert.Equal(t, "Error in report step totals (fake_totals): upstream failed", result.Content[0].(*mcp.TextContent).Text)
+}
+
+func TestReportToolDateArguments(t *testing.T) {
+	withFakeTotalsTool(t)
+	server, err := NewFireflyMCPServer(newReportTestConfig(ReportDefinition{
+		Name: "dated_report",
+		Arguments: []ReportArgument{
+			{Name: "start", Type: "date"},
+			{Name: "label"},
+		},
+		Steps:    []ReportStep{{Tool: "fake_totals", Args: map[string]any{"start": "{{.Args.start}}"}}},
+		Template: `{{.Steps.fake_totals.start}} {{.Args.label}}`,
+	}))
+	require.NoError(t, err)
+
+	result := callReport(t, server, "dated_report", map[string]any{"start": "today", "label": "today"})
+	require.False(t, result.IsError)
+	today := server.now().Format("2006-01-02")
+	assert.Equal(t, today+" today", result.Content[0].(*mcp.TextContent).Text, "only date arguments are resolved")
+}
+
+func TestReportToolWithoutTemplate(t *testing.T) {
+	withFakeTotalsTool(t)
+	server, err := NewFireflyMCPServer(newReportTestConfig(ReportDefinition{
+		Name:  "raw_report",
+		Steps: []ReportStep{{Tool: "fake_totals", Args: map[string]any{"start": "2024-01-01"}}},
+	}))
+	require.NoError(t, err)
+
+	result := callReport(t, server, "raw_report", nil)
+	require.False(t, result.IsError)
+
+	var steps map[string]map[string]any
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &steps))
+	assert.Equal(t, "12.50", steps["fake_totals"]["total"])
+}
+
+func TestReportFuncs_ExactAmounts(t *testing.T) {
+	render := func(text string, data any) string {
+		var out strings.Builder
+		require.NoError(t, template.Must(template.New("t").Funcs(reportFuncs).Parse(text)).Execute(&out, data))
+		return out.String()
+	}
+
+	// 0.005 + 0.01 is 0.01499... in float64
+	assert.Equal(t, "0.02", render(`{{amount (add .a .b)}}`, map[string]any{"a": "0.005", "b": "0.01"}))
+	assert.Equal(t, "0.30", render(`{{amount (add (float .a) .b)}}`, map[string]any{"a": 0.1, "b": 0.2}))
+	assert.Equal(t, "-1.10", render(`{{sub (decimal .a) 2}}`, map[string]any{"a": "0.90"}))
+	assert.Equal(t, "0.00", render(`{{amount .missing}}`, map[string]any{}))
+}
+
+func TestReportRegistrationErrors(t *testing.T) {
+	withPlugins(t)
+
+	_, err := NewFireflyMCPServer(newReportTestConfig(ReportDefinition{
+		Name:  "list_accounts",
+		Steps: []ReportStep{{Tool: "get_summary"}},
+	}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already registered")
+
+	_, err = NewFireflyMCPServer(newReportTestConfig(ReportDefinition{
+		Name:  "my_report",
+		Steps: []ReportStep{{Tool: "does_not_exist"}},
+	}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown tool "does_not_exist"`)
+}
+
+func TestValidateReports(t *testing.T) {
+	tests := []struct {
+		name        string
+		report      ReportDefinition
+		errorString string
+	}{
+		{"invalid name", ReportDefinition{Name: "My Report", Steps: []ReportStep{{Tool: "get_summary"}}}, "must be snake_case"},
+		{"no steps", ReportDefinition{Name: "empty"}, "at least one step"},
+		{
+			"unknown argument type",
+			ReportDefinition{Name: "r", Arguments: []ReportArgument{{Name: "n", Type: "number"}}, Steps: []ReportStep{{Tool: "get_summary"}}},
+			"must be string or date",
+		},
+		{"missing tool", ReportDefinition{Name: "r", Steps: []ReportStep{{Name: "a"}}}, "tool is required"},
+		{
+			"duplicate step",
+			ReportDefinition{Name: "r", Steps: []ReportStep{{Tool: "get_summary"}, {Tool: "get_summary"}}},
+			"used twice",
+		},
+		{
+			"bad template",
+			ReportDefinition{Name: "r", Steps: []ReportStep{{Tool: "get_summary"}}, Template: "{{.Steps"},
+			"template",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateReports([]ReportDefinition{tt.report})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorString)
+		})
+	}
+
+	valid := ReportDefinition{Name: "r", Steps: []ReportStep{{Tool: "get_summary"}}}
+	assert.NoError(t, validateReports([]ReportDefinition{valid}))
+	assert.Error(t, validateReports([]ReportDefinition{valid, valid}))
+}
+
+func TestLoadConfigReports(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	configContent := `
+server:
+  url: https://test.firefly.com/api
+api:
+  token: test-token
+reports:
+  - name: monthly_spending
+    description: Spending this month
+    arguments:
+      - name: start
+        required: true
+    steps:
+      - name: totals
+        tool: expense_total_insights
+        args:
+          start: "{{.Args.start}}"
+          end: "2024-01-31"
+    template: "{{json .Steps.totals}}"
+`
+	require.NoError(t, os.WriteFile(configFile, []byte(configContent), 0644))
+
+	config, err := LoadConfig(configFile)
+	require.NoError(t, err)
+	require.Len(t, config.Reports, 1)
+
+	report := config.Reports[0]
+	assert.Equal(t, "monthly_spending", report.Name)
+	assert.True(t, report.Arguments[0].Required)
+	require.Len(t, report.Steps, 1)
+	assert.Equal(t, "expense_total_insights", report.Steps[0].Tool)
+	assert.Equal(t, "{{.Args.start}}", report.Steps[0].Args["start"])
+}
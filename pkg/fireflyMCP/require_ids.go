@@ -0,0 +1,53 @@
+package fireflyMCP
+
+import "fmt"
+
+// splitNameRef is a name reference of a split with the ID field that replaces it
+type splitNameRef struct {
+	name   string
+	id     string
+	lookup string
+	value  func(TransactionSplitRequest) *string
+}
+
+// splitNameRefs are the split fields Firefly III matches (or creates) by name
+var splitNameRefs = []splitNameRef{
+	{"source_name", "source_id", "search_accounts", func(s TransactionSplitRequest) *string { return s.SourceName }},
+	{"destination_name", "destination_id", "search_accounts", func(s TransactionSplitRequest) *string { return s.DestinationName }},
+	{"category_name", "category_id", "list_categories", func(s TransactionSplitRequest) *string { return s.CategoryName }},
+	{"budget_name", "budget_id", "list_budgets", func(s TransactionSplitRequest) *string { return s.BudgetName }},
+	{"bill_name", "bill_id", "autocomplete", func(s TransactionSplitRequest) *string { return s.BillName }},
+	{"piggy_bank_name", "piggy_bank_id", "autocomplete", func(s TransactionSplitRequest) *string { return s.PiggyBankName }},
+}
+
+// requireIDs reports whether write tools only accept IDs for referenced objects
+func (s *FireflyMCPServer) requireIDs() bool {
+	config := s.currentConfig()
+	return config != nil && config.Writes.RequireIDs
+}
+
+// checkSplitIDs rejects name references in splits when writes.require_ids is
+// on, so Firefly III never matches an object by a fuzzy name or creates one.
+// Account aliases are resolved to IDs beforehand and pass.
+func (s *FireflyMCPServer) checkSplitIDs(splits []TransactionSplitRequest) error {
+	if !s.requireIDs() {
+		return nil
+	}
+	for i, split := range splits {
+		if err := checkSplitNameRefs(split); err != nil {
+			return fmt.Errorf("transaction[%d].%w", i, err)
+		}
+	}
+	return nil
+}
+
+// checkSplitNameRefs returns an error for the first name reference of a split
+func checkSplitNameRefs(split TransactionSplitRequest) error {
+	for _, ref := range splitNameRefs {
+		if value := ref.value(split); value != nil && *value != "" {
+			return fmt.Errorf("%s is not accepted while writes.require_ids is on; set %s instead (look it up with %s)",
+				ref.name, ref.id, ref.lookup)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package fireflyMCP
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteTools_RequireIDs(t *testing.T) {
+	var requests []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		w.Write([]byte(`{"data":{"type":"transactions","id":"40","attributes":{"transactions":[` +
+			`{"transaction_journal_id":"41","type":"withdrawal","date":"2024-03-01T00:00:00Z","amount":"12.00","description":"Food"}]}}}`))
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	config.Writes.RequireIDs = true
+	config.Accounts.Aliases = map[string]string{"shop": "9"}
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	store := func(split TransactionSplitRequest) *mcp.CallToolResult {
+		split.Type, split.Date, split.Amount, split.Description = "withdrawal", "2024-03-01", "12.00", "Food"
+		result, _, err := server.handleStoreTransaction(ctx, nil, TransactionStoreRequest{
+			Transactions: []TransactionSplitRequest{split},
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	result := store(TransactionSplitRequest{SourceId: ptr("1"), DestinationName: ptr("Shop"), CategoryName: ptr("Food")})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text,
+		"transaction[0].category_name is not accepted while writes.require_ids is on; set category_id instead (look it up with list_categories)")
+	result = store(TransactionSplitRequest{SourceId: ptr("1"), DestinationName: ptr("Grocery store")})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "destination_name is not accepted")
+	assert.Empty(t, requests)
+
+	// Aliases resolve to IDs and pass
+	result = store(TransactionSplitRequest{SourceId: ptr("1"), DestinationName: ptr("shop"), CategoryId: ptr("3")})
+	require.False(t, result.IsError, result.Content[0].(*mcp.TextContent).Text)
+	assert.Contains(t, requests, "POST /v1/transactions")
+	stored := len(requests)
+
+	result, _, err = server.handleUpdateTransaction(ctx, nil, UpdateTransactionArgs{
+		ID: "40",
+		TransactionUpdateRequest: TransactionUpdateRequest{Transactions: []TransactionSplitRequest{
+			{JournalId: ptr("41"), BudgetName: ptr("Groceries")},
+		}},
+	})
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "transaction[0].budget_name is not accepted")
+
+	_, _, err = server.splitPatchFields(TransactionSplitPatch{JournalId: "41", Set: map[string]any{"bill_name": "Rent"}})
+	assert.ErrorContains(t, err, "bill_name is not accepted while writes.require_ids is on; set bill_id instead")
+	_, _, err = server.splitPatchFields(TransactionSplitPatch{JournalId: "41", Set: map[string]any{"bill_id": "5"}})
+	assert.NoError(t, err)
+	assert.Len(t, requests, stored)
+}
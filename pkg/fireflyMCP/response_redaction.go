@@ -0,0 +1,125 @@
+package fireflyMCP
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Response redaction modes (responses.redact_mode)
+const (
+	responseRedactNone  = "none"
+	responseRedactStrip = "strip"
+	responseRedactHash  = "hash"
+)
+
+// defaultResponseRedactFields are the fields redacted when responses.redact_fields is not configured
+var defaultResponseRedactFields = []string{"notes"}
+
+// isResponseRedactMode reports whether mode is a valid responses.redact_mode
+func isResponseRedactMode(mode string) bool {
+	switch mode {
+	case responseRedactNone, responseRedactStrip, responseRedactHash:
+		return true
+	}
+	return false
+}
+
+// responseRedactor strips or hashes free-text fields in tool results. Fields
+// are matched like log fields, so "notes" also matches "bill_notes".
+type responseRedactor struct {
+	fields *Redactor
+	mode   string
+}
+
+// newResponseRedactor returns the redactor configured in responses, or nil when
+// redaction is disabled
+func newResponseRedactor(config *Config) *responseRedactor {
+	if config == nil || config.Responses.RedactMode == "" || config.Responses.RedactMode == responseRedactNone ||
+		len(config.Responses.RedactFields) == 0 {
+		return nil
+	}
+	return &responseRedactor{fields: NewRedactor(config.Responses.RedactFields), mode: config.Responses.RedactMode}
+}
+
+// withResponseRedaction redacts the results of a read-only tool. Results of
+// tools that change data are left alone, so they still confirm what was written.
+func withResponseRedaction[In any](s *FireflyMCPServer, tool *mcp.Tool, handler mcp.ToolHandlerFor[In, any]) mcp.ToolHandlerFor[In, any] {
+	if tool.Annotations == nil || !tool.Annotations.ReadOnlyHint {
+		return handler
+	}
+	return func(ctx context.Context, req *mcp.CallToolRequest, args In) (*mcp.CallToolResult, any, error) {
+		result, out, err := handler(ctx, req, args)
+		redactor := newResponseRedactor(s.currentConfig())
+		if err != nil || result == nil || result.IsError || redactor == nil {
+			return result, out, err
+		}
+		for _, content := range result.Content {
+			if text, ok := content.(*mcp.TextContent); ok {
+				text.Text = redactor.redactJSON(text.Text)
+			}
+		}
+		return result, out, err
+	}
+}
+
+// redactJSON redacts the configured fields in a JSON document. Text that is not
+// JSON, or contains none of the fields, is returned unchanged.
+func (r *responseRedactor) redactJSON(text string) string {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(text)))
+	decoder.UseNumber()
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return text
+	}
+	redacted, changed := r.redactValue(document)
+	if !changed {
+		return text
+	}
+	encoded, err := json.MarshalIndent(redacted, "", "  ")
+	if err != nil {
+		return text
+	}
+	return string(encoded)
+}
+
+// redactValue redacts decoded JSON recursively and reports whether anything changed
+func (r *responseRedactor) redactValue(value any) (any, bool) {
+	changed := false
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if text, ok := item.(string); ok && text != "" && r.fields.isRedacted(key) {
+				v[key] = r.redactString(text)
+				changed = true
+				continue
+			}
+			if redacted, itemChanged := r.redactValue(item); itemChanged {
+				v[key] = redacted
+				changed = true
+			}
+		}
+	case []any:
+		for i, item := range v {
+			if redacted, itemChanged := r.redactValue(item); itemChanged {
+				v[i] = redacted
+				changed = true
+			}
+		}
+	}
+	return value, changed
+}
+
+// redactString replaces a value by a marker or, in hash mode, by a short hash
+// so equal values can still be grouped
+func (r *responseRedactor) redactString(value string) string {
+	if r.mode == responseRedactHash {
+		sum := sha256.Sum256([]byte(value))
+		return "sha256:" + hex.EncodeToString(sum[:6])
+	}
+	return redactedValue
+}
@@ -0,0 +1,57 @@
+package fireflyMCP
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseRedactor(t *testing.T) {
+	config := &Config{}
+	config.Responses.RedactFields = []string{"notes", "description"}
+	config.Responses.RedactMode = responseRedactStrip
+	redactor := newResponseRedactor(config)
+	require.NotNil(t, redactor)
+
+	redacted := redactor.redactJSON(`{"data":[{"id":"1","description":"Dr. Smith","notes":"","amount":12.50,` +
+		`"bill_notes":"policy 123","tags":["health"]}]}`)
+	assert.JSONEq(t, `{"data":[{"id":"1","description":"[REDACTED]","notes":"","amount":12.50,`+
+		`"bill_notes":"[REDACTED]","tags":["health"]}]}`, redacted)
+
+	assert.Equal(t, "not json", redactor.redactJSON("not json"))
+	assert.Equal(t, `{"id": "1"}`, redactor.redactJSON(`{"id": "1"}`), "unchanged documents keep their formatting")
+
+	config.Responses.RedactMode = responseRedactHash
+	hashed := newResponseRedactor(config)
+	first := hashed.redactString("Dr. Smith")
+	assert.Regexp(t, `^sha256:[0-9a-f]{12}$`, first)
+	assert.Equal(t, first, hashed.redactString("Dr. Smith"))
+	assert.NotEqual(t, first, hashed.redactString("Dr. Jones"))
+
+	config.Responses.RedactMode = responseRedactNone
+	assert.Nil(t, newResponseRedactor(config))
+}
+
+func TestWithResponseRedaction(t *testing.T) {
+	config := newPluginTestConfig()
+	config.Responses.RedactFields = []string{"notes"}
+	config.Responses.RedactMode = responseRedactStrip
+	server := &FireflyMCPServer{config: config}
+
+	handler := func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+		return newSuccessResult(map[string]string{"notes": "private"})
+	}
+	read := withResponseRedaction(server, &mcp.Tool{Name: "get_transaction", Annotations: readOnlyAnnotations()}, handler)
+	write := withResponseRedaction(server, &mcp.Tool{Name: "update_transaction", Annotations: destructiveAnnotations(true)}, handler)
+
+	result, _, err := read(context.Background(), nil, struct{}{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"notes":"[REDACTED]"}`, result.Content[0].(*mcp.TextContent).Text)
+
+	result, _, err = write(context.Background(), nil, struct{}{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"notes":"private"}`, result.Content[0].(*mcp.TextContent).Text)
+}
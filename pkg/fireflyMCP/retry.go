@@ -0,0 +1,93 @@
+package fireflyMCP
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Defaults used when client.retry_attempts and client.retry_backoff are not configured
+const (
+	defaultRetryAttempts = 2
+	defaultRetryBackoff  = 250 // Milliseconds
+)
+
+// retryTransport retries read requests that failed with a transient network
+// error (connection reset, timeout), which flaky home networks cause now and
+// then. Requests that reached Firefly III and got an HTTP response, and all
+// writes, are never retried here.
+type retryTransport struct {
+	base     http.RoundTripper
+	attempts int           // Retries after the first attempt
+	backoff  time.Duration // Delay before the first retry, doubled for each further retry
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if isWriteRequest(req) || req.Body != nil && req.Body != http.NoBody {
+		return resp, err
+	}
+
+	delay := t.backoff
+	for retry := 0; retry < t.attempts && err != nil && isTransientNetworkError(req.Context(), err); retry++ {
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, err
+		}
+		delay *= 2
+
+		if counter, ok := req.Context().Value(retryCounterKey{}).(*atomic.Int32); ok {
+			counter.Add(1)
+		}
+		resp, err = t.base.RoundTrip(req)
+	}
+	return resp, err
+}
+
+// isTransientNetworkError reports whether a request failed because of the network
+// rather than because the caller gave up
+func isTransientNetworkError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNABORTED) || errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTimeout || dnsErr.IsTemporary
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// retryCounterKey is the context key of the retry counter of a tool call
+type retryCounterKey struct{}
+
+// withRetryNotes counts the requests retried by retryTransport during a tool
+// call and reports them in the _meta.retries field of the result
+func withRetryNotes[In any](handler mcp.ToolHandlerFor[In, any]) mcp.ToolHandlerFor[In, any] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, args In) (*mcp.CallToolResult, any, error) {
+		counter := &atomic.Int32{}
+		result, out, err := handler(context.WithValue(ctx, retryCounterKey{}, counter), req, args)
+		if retries := counter.Load(); retries > 0 && result != nil {
+			if result.Meta == nil {
+				result.Meta = mcp.Meta{}
+			}
+			result.Meta["retries"] = retries
+		}
+		return result, out, err
+	}
+}
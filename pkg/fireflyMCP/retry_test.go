@@ -0,0 +1,120 @@
+package fireflyMCP
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyTransport fails the first failures requests with err
+type flakyTransport struct {
+	failures int
+	err      error
+	calls    int
+}
+
+func (f *flakyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return nil, f.err
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+}
+
+func TestRetryTransport_RetriesReads(t *testing.T) {
+	base := &flakyTransport{failures: 2, err: fmt.Errorf("read: %w", syscall.ECONNRESET)}
+	transport := &retryTransport{base: base, attempts: 2, backoff: 0}
+
+	counter := &atomic.Int32{}
+	ctx := context.WithValue(context.Background(), retryCounterKey{}, counter)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://firefly.test/api/v1/tags", nil)
+	require.NoError(t, err)
+
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 3, base.calls)
+	assert.Equal(t, int32(2), counter.Load())
+}
+
+func TestRetryTransport_GivesUp(t *testing.T) {
+	base := &flakyTransport{failures: 5, err: io.ErrUnexpectedEOF}
+	transport := &retryTransport{base: base, attempts: 2, backoff: 0}
+
+	req, err := http.NewRequest(http.MethodGet, "http://firefly.test/api/v1/tags", nil)
+	require.NoError(t, err)
+
+	_, err = transport.RoundTrip(req)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.Equal(t, 3, base.calls)
+}
+
+func TestRetryTransport_SkipsWritesAndPermanentErrors(t *testing.T) {
+	base := &flakyTransport{failures: 1, err: syscall.ECONNRESET}
+	transport := &retryTransport{base: base, attempts: 2, backoff: 0}
+	req, err := http.NewRequest(http.MethodPost, "http://firefly.test/api/v1/tags", nil)
+	require.NoError(t, err)
+	_, err = transport.RoundTrip(req)
+	assert.Error(t, err)
+	assert.Equal(t, 1, base.calls)
+
+	base = &flakyTransport{failures: 1, err: errors.New("certificate signed by unknown authority")}
+	transport = &retryTransport{base: base, attempts: 2, backoff: 0}
+	req, err = http.NewRequest(http.MethodGet, "http://firefly.test/api/v1/tags", nil)
+	require.NoError(t, err)
+	_, err = transport.RoundTrip(req)
+	assert.Error(t, err)
+	assert.Equal(t, 1, base.calls)
+}
+
+func TestIsTransientNetworkError(t *testing.T) {
+	ctx := context.Background()
+	assert.True(t, isTransientNetworkError(ctx, &net.OpError{Op: "read", Err: syscall.ECONNRESET}))
+	assert.True(t, isTransientNetworkError(ctx, io.EOF))
+	assert.True(t, isTransientNetworkError(ctx, &net.DNSError{Err: "timeout", IsTimeout: true}))
+	assert.False(t, isTransientNetworkError(ctx, &net.DNSError{Err: "no such host", IsNotFound: true}))
+	assert.False(t, isTransientNetworkError(ctx, context.DeadlineExceeded))
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.False(t, isTransientNetworkError(cancelled, io.EOF))
+}
+
+func TestRetryNotesInToolResult(t *testing.T) {
+	var requests atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			// Drop the connection without a response
+			conn, _, err := w.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			conn.Close()
+			return
+		}
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		w.Write([]byte(`{"data":[],"meta":{"pagination":{"total":0,"count":0,"per_page":50,"current_page":1,"total_pages":1}}}`))
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	config.Client.RetryAttempts = 2
+	config.Client.RetryBackoff = 1
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	result, _, err := withRetryNotes(server.handleListTags)(context.Background(), nil, ListTagsArgs{})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Equal(t, int32(1), result.Meta["retries"])
+	assert.Equal(t, int32(2), requests.Load())
+}
@@ -0,0 +1,122 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxRootFileSize is the largest file import tools read from a client root
+const maxRootFileSize = 10 << 20
+
+// readRootFile reads a file the client granted access to through its MCP roots.
+// path is a file:// URI or an absolute path, and must be inside one of the roots
+// after resolving symlinks. Roots name paths on the client's machine, so files
+// are only read in stdio mode, where the server runs next to the client.
+func (s *FireflyMCPServer) readRootFile(ctx context.Context, req *mcp.CallToolRequest, path string) ([]byte, error) {
+	if config := s.currentConfig(); config == nil || config.HTTP.Enabled {
+		return nil, errors.New("files can only be read in stdio mode")
+	}
+	if req == nil || req.Session == nil {
+		return nil, errors.New("no client session to list roots from")
+	}
+
+	file, err := rootPath(path)
+	if err != nil {
+		return nil, err
+	}
+	rootsResult, err := req.Session.ListRoots(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("the client did not list its roots: %w", err)
+	}
+	file, err = filepath.EvalSymlinks(file)
+	if err != nil {
+		return nil, err
+	}
+	if !insideRoots(file, rootsResult.Roots) {
+		return nil, fmt.Errorf("%s is not inside a root granted by the client", path)
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxRootFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxRootFileSize {
+		return nil, fmt.Errorf("%s is larger than %d bytes", path, maxRootFileSize)
+	}
+	return data, nil
+}
+
+// rootPath converts a file:// URI or an absolute path to a clean absolute path
+func rootPath(value string) (string, error) {
+	if strings.HasPrefix(value, "file://") {
+		u, err := url.Parse(value)
+		if err != nil {
+			return "", err
+		}
+		value = filepath.FromSlash(u.Path)
+	} else if strings.Contains(value, "://") {
+		return "", fmt.Errorf("%s is not a file:// URI", value)
+	}
+	if !filepath.IsAbs(value) {
+		return "", fmt.Errorf("%s is not an absolute path", value)
+	}
+	return filepath.Clean(value), nil
+}
+
+// insideRoots reports whether a resolved path is one of the roots or below one.
+// Roots that are not file:// URIs are ignored.
+func insideRoots(path string, roots []*mcp.Root) bool {
+	for _, root := range roots {
+		dir, err := rootPath(root.URI)
+		if err != nil || !strings.HasPrefix(root.URI, "file://") {
+			continue
+		}
+		if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+			dir = resolved
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// readTransactionGroupsFile reads the transaction groups of a bulk store from a
+// JSON file in a client root: either an array of groups or an object with a
+// transaction_groups array
+func (s *FireflyMCPServer) readTransactionGroupsFile(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	path string,
+) ([]TransactionStoreRequest, error) {
+	data, err := s.readRootFile(ctx, req, path)
+	if err != nil {
+		return nil, err
+	}
+	var groups []TransactionStoreRequest
+	if err := json.Unmarshal(data, &groups); err == nil {
+		return groups, nil
+	}
+	var wrapped struct {
+		TransactionGroups []TransactionStoreRequest `json:"transaction_groups"`
+	}
+	if err := json.Unmarshal(data, &wrapped); err != nil {
+		return nil, fmt.Errorf("%s does not hold transaction groups: %w", path, err)
+	}
+	return wrapped.TransactionGroups, nil
+}
@@ -0,0 +1,94 @@
+package fireflyMCP
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsideRoots(t *testing.T) {
+	roots := []*mcp.Root{{URI: "file:///home/user/imports"}, {URI: "https://example.com/home"}}
+
+	assert.True(t, insideRoots("/home/user/imports", roots))
+	assert.True(t, insideRoots("/home/user/imports/2024/bank.json", roots))
+	assert.False(t, insideRoots("/home/user/imports-old/bank.json", roots))
+	assert.False(t, insideRoots("/home/user/secrets.json", roots))
+	assert.False(t, insideRoots("/home/bank.json", roots), "non-file roots are ignored")
+}
+
+func TestRootPath(t *testing.T) {
+	path, err := rootPath("file:///home/user/imports/../bank.json")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.FromSlash("/home/user/bank.json"), path)
+
+	_, err = rootPath("bank.json")
+	assert.Error(t, err)
+	_, err = rootPath("https://example.com/bank.json")
+	assert.Error(t, err)
+}
+
+func TestStoreTransactionsBulkFromRootFile(t *testing.T) {
+	var stored atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stored.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"message":"The given data was invalid."}`))
+	}))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	root := filepath.Join(dir, "imports")
+	require.NoError(t, os.Mkdir(root, 0o700))
+	groups := `{"transaction_groups": [
+		{"transactions": [{"type": "withdrawal", "date": "2024-01-15", "amount": "10.00", "description": "Coffee"}]},
+		{"transactions": [{"type": "withdrawal", "date": "2024-01-16", "amount": "4.50", "description": "Bread"}]}
+	]}`
+	require.NoError(t, os.WriteFile(filepath.Join(root, "bank.json"), []byte(groups), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "outside.json"), []byte(groups), 0o600))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "outside.json"), filepath.Join(root, "link.json")))
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.MCPServer().Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	defer serverSession.Close()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, nil)
+	client.AddRoots(&mcp.Root{URI: "file://" + filepath.ToSlash(root)})
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	defer session.Close()
+
+	call := func(file string) *mcp.CallToolResult {
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{
+			Name:      "store_transactions_bulk",
+			Arguments: map[string]any{"file": file, "delay_ms": 1},
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	result := call("file://" + filepath.ToSlash(filepath.Join(root, "bank.json")))
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, `"total": 2`)
+	assert.Equal(t, int32(2), stored.Load())
+
+	for _, file := range []string{filepath.Join(dir, "outside.json"), filepath.Join(root, "link.json")} {
+		result = call(file)
+		assert.True(t, result.IsError, file)
+		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "not inside a root")
+	}
+	assert.Equal(t, int32(2), stored.Load())
+}
@@ -0,0 +1,284 @@
+package fireflyMCP
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// minRuleSubstringLength is the shortest common description part used as a trigger
+const minRuleSubstringLength = 3
+
+// defaultRuleAmountMargin is the percentage the amount range of a built rule is
+// widened by, so amounts slightly outside the examples still match
+const defaultRuleAmountMargin = 10
+
+// RuleExample is an example transaction for build_rule_from_examples
+type RuleExample struct {
+	Description     string `json:"description" jsonschema:"Description of the transaction"`
+	Amount          string `json:"amount,omitempty" jsonschema:"Amount of the transaction"`
+	Type            string `json:"type,omitempty" jsonschema:"Transaction type: withdrawal, deposit or transfer"`
+	SourceName      string `json:"source_name,omitempty" jsonschema:"Source account name"`
+	DestinationName string `json:"destination_name,omitempty" jsonschema:"Destination account name"`
+}
+
+// BuildRuleFromExamplesArgs represents the arguments for the build_rule_from_examples tool
+type BuildRuleFromExamplesArgs struct {
+	TransactionIds      []string      `json:"transaction_ids,omitempty" jsonschema:"IDs of example transaction groups; every split is used as an example"`
+	Examples            []RuleExample `json:"examples,omitempty" jsonschema:"Example transactions given inline"`
+	CategoryName        string        `json:"category_name,omitempty" jsonschema:"Category the rule should set"`
+	Tag                 string        `json:"tag,omitempty" jsonschema:"Tag the rule should add"`
+	BudgetName          string        `json:"budget_name,omitempty" jsonschema:"Budget the rule should set"`
+	Title               string        `json:"title,omitempty" jsonschema:"Title of the rule (default: derived from the triggers)"`
+	RuleGroupId         string        `json:"rule_group_id,omitempty" jsonschema:"Rule group of the rule"`
+	RuleGroupTitle      string        `json:"rule_group_title,omitempty" jsonschema:"Rule group title (alternative to rule_group_id)"`
+	AmountMarginPercent *int          `json:"amount_margin_percent,omitempty" jsonschema:"Percentage the amount range of the examples is widened by (default: 10)"`
+	IgnoreAmount        bool          `json:"ignore_amount,omitempty" jsonschema:"Do not add amount triggers"`
+}
+
+// BuiltRule is the result of build_rule_from_examples: a create_rule request
+// to review, and how each trigger was inferred
+type BuiltRule struct {
+	Rule        RuleStoreRequest `json:"rule"`
+	Examples    int              `json:"examples"`
+	Explanation []string         `json:"explanation"`
+	Warnings    []string         `json:"warnings,omitempty"`
+}
+
+// handleBuildRuleFromExamples infers the triggers of a rule from example
+// transactions and returns the rule without creating it
+func (s *FireflyMCPServer) handleBuildRuleFromExamples(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args BuildRuleFromExamplesArgs,
+) (*mcp.CallToolResult, any, error) {
+	if args.CategoryName == "" && args.Tag == "" && args.BudgetName == "" {
+		return newErrorResult("Error: at least one of category_name, tag or budget_name is required")
+	}
+	margin := defaultRuleAmountMargin
+	if args.AmountMarginPercent != nil {
+		margin = *args.AmountMarginPercent
+	}
+	if margin < 0 {
+		return newErrorResult("Error: amount_margin_percent must not be negative")
+	}
+
+	examples := append([]RuleExample(nil), args.Examples...)
+	for _, id := range args.TransactionIds {
+		group, err := callTool[GetTransactionArgs, TransactionGroup](ctx, req, s.handleGetTransaction, GetTransactionArgs{ID: id})
+		if err != nil {
+			return newErrorResult(fmt.Sprintf("Error getting transaction %s: %v", id, err))
+		}
+		for _, split := range group.Transactions {
+			examples = append(examples, RuleExample{
+				Description:     split.Description,
+				Amount:          split.Amount,
+				Type:            split.Type,
+				SourceName:      split.SourceName,
+				DestinationName: split.DestinationName,
+			})
+		}
+	}
+	if len(examples) == 0 {
+		return newErrorResult("Error: transaction_ids or examples are required")
+	}
+
+	built := buildRuleFromExamples(examples, margin, args.IgnoreAmount)
+	built.Rule.Actions = ruleOutcomeActions(args)
+	built.Rule.RuleGroupId = args.RuleGroupId
+	if args.RuleGroupTitle != "" {
+		built.Rule.RuleGroupTitle = &args.RuleGroupTitle
+	}
+	if args.RuleGroupId == "" && args.RuleGroupTitle == "" {
+		built.Warnings = append(built.Warnings, "Set rule_group_id or rule_group_title before calling create_rule")
+	}
+	if args.Title != "" {
+		built.Rule.Title = args.Title
+	}
+	return newSuccessResult(built)
+}
+
+// buildRuleFromExamples infers strict triggers every example matches: the
+// longest common part of the descriptions, a shared type and counterparty, and
+// the amount range widened by margin percent
+func buildRuleFromExamples(examples []RuleExample, margin int, ignoreAmount bool) *BuiltRule {
+	active, strict := true, true
+	built := &BuiltRule{
+		Rule: RuleStoreRequest{
+			Trigger: "store-journal",
+			Active:  &active,
+			Strict:  &strict,
+		},
+		Examples:    len(examples),
+		Explanation: []string{},
+	}
+	addTrigger := func(triggerType, value, why string) {
+		built.Rule.Triggers = append(built.Rule.Triggers, RuleTriggerRequest{Type: triggerType, Value: value})
+		built.Explanation = append(built.Explanation, fmt.Sprintf("%s %q: %s", triggerType, value, why))
+	}
+
+	descriptions := make([]string, len(examples))
+	for i, example := range examples {
+		descriptions[i] = example.Description
+	}
+	common := commonSubstring(descriptions)
+	switch {
+	case common != "" && allEqualFold(descriptions, common):
+		addTrigger("description_is", common, "all examples have this description")
+	case common != "":
+		addTrigger("description_contains", common, "longest text all descriptions contain")
+	default:
+		built.Warnings = append(built.Warnings, "The descriptions have nothing in common; the rule relies on the other triggers")
+	}
+
+	if transactionType, ok := sharedValue(examples, func(e RuleExample) string { return e.Type }); ok {
+		addTrigger("transaction_type", transactionType, "all examples have this type")
+		// The counterparty is the account the money goes to or comes from
+		switch transactionType {
+		case "withdrawal":
+			if name, ok := sharedValue(examples, func(e RuleExample) string { return e.DestinationName }); ok {
+				addTrigger("to_account_is", name, "all examples were paid to this account")
+			}
+		case "deposit":
+			if name, ok := sharedValue(examples, func(e RuleExample) string { return e.SourceName }); ok {
+				addTrigger("from_account_is", name, "all examples came from this account")
+			}
+		}
+	}
+
+	if !ignoreAmount {
+		addAmountTriggers(built, examples, margin, addTrigger)
+	}
+
+	if len(built.Rule.Triggers) == 0 {
+		built.Warnings = append(built.Warnings, "No trigger could be inferred; add triggers before calling create_rule")
+	}
+	built.Rule.Title = "Auto: " + ruleTitle(built.Rule.Triggers)
+	return built
+}
+
+// addAmountTriggers adds amount_exactly when all examples have the same amount,
+// or amount_more and amount_less around their range otherwise
+func addAmountTriggers(built *BuiltRule, examples []RuleExample, margin int, addTrigger func(string, string, string)) {
+	var low, high decimal
+	for i, example := range examples {
+		amount, ok := parseDecimal(example.Amount)
+		if !ok {
+			built.Warnings = append(built.Warnings, "Not every example has an amount; no amount trigger was added")
+			return
+		}
+		amount = amount.Abs()
+		if i == 0 || amount.Cmp(low) < 0 {
+			low = amount
+		}
+		if i == 0 || amount.Cmp(high) > 0 {
+			high = amount
+		}
+	}
+	if low.Cmp(high) == 0 {
+		addTrigger("amount_exactly", formatAmount(low), "all examples have this amount")
+		return
+	}
+
+	hundred := decimalFromInt(100)
+	lower := low.Mul(hundred.Sub(decimalFromInt(int64(margin)))).Div(hundred)
+	upper := high.Mul(hundred.Add(decimalFromInt(int64(margin)))).Div(hundred)
+	why := fmt.Sprintf("examples range from %s to %s, widened by %d%%", formatAmount(low), formatAmount(high), margin)
+	if lower.Sign() > 0 {
+		addTrigger("amount_more", formatAmount(lower), why)
+	}
+	addTrigger("amount_less", formatAmount(upper), why)
+}
+
+// ruleTitle names a rule after its most descriptive trigger
+func ruleTitle(triggers []RuleTriggerRequest) string {
+	for _, trigger := range triggers {
+		switch trigger.Type {
+		case "description_is", "description_contains", "to_account_is", "from_account_is":
+			return trigger.Value
+		}
+	}
+	return "rule from examples"
+}
+
+// ruleOutcomeActions returns the actions setting the desired outcome
+func ruleOutcomeActions(args BuildRuleFromExamplesArgs) []RuleActionRequest {
+	var actions []RuleActionRequest
+	add := func(actionType, value string) {
+		if value != "" {
+			actions = append(actions, RuleActionRequest{Type: actionType, Value: &value})
+		}
+	}
+	add("set_category", args.CategoryName)
+	add("add_tag", args.Tag)
+	add("set_budget", args.BudgetName)
+	return actions
+}
+
+// sharedValue returns the value all examples have, if they agree and it is not empty
+func sharedValue(examples []RuleExample, value func(RuleExample) string) (string, bool) {
+	shared := value(examples[0])
+	for _, example := range examples[1:] {
+		if !strings.EqualFold(value(example), shared) {
+			return "", false
+		}
+	}
+	return shared, shared != ""
+}
+
+// allEqualFold reports whether every value equals target, ignoring case
+func allEqualFold(values []string, target string) bool {
+	for _, value := range values {
+		if !strings.EqualFold(strings.TrimSpace(value), target) {
+			return false
+		}
+	}
+	return true
+}
+
+// commonSubstring returns the longest text, ignoring case, contained in all
+// values, with surrounding spaces and punctuation trimmed. It returns "" when
+// that text is shorter than minRuleSubstringLength.
+func commonSubstring(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	shortest := []rune(values[0])
+	for _, value := range values[1:] {
+		if runes := []rune(value); len(runes) < len(shortest) {
+			shortest = runes
+		}
+	}
+	lowered := make([]string, len(values))
+	for i, value := range values {
+		lowered[i] = strings.ToLower(value)
+	}
+
+	for length := len(shortest); length >= minRuleSubstringLength; length-- {
+		for start := 0; start+length <= len(shortest); start++ {
+			candidate := strings.TrimFunc(string(shortest[start:start+length]), func(r rune) bool {
+				return unicode.IsSpace(r) || unicode.IsPunct(r)
+			})
+			if len([]rune(candidate)) < minRuleSubstringLength {
+				continue
+			}
+			if containedInAll(lowered, strings.ToLower(candidate)) {
+				return candidate
+			}
+		}
+	}
+	return ""
+}
+
+// containedInAll reports whether every value contains part
+func containedInAll(values []string, part string) bool {
+	for _, value := range values {
+		if !strings.Contains(value, part) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommonSubstring(t *testing.T) {
+	assert.Equal(t, "NETFLIX.COM", commonSubstring([]string{"NETFLIX.COM 866-579", "Netflix.com Amsterdam", "PAYPAL *NETFLIX.COM"}))
+	assert.Equal(t, "Spotify", commonSubstring([]string{"Spotify", "spotify p1234"}))
+	assert.Equal(t, "", commonSubstring([]string{"Rent", "Groceries"}))
+	assert.Equal(t, "", commonSubstring([]string{"ab - x", "ab - y"}), "too short after trimming")
+}
+
+func TestBuildRuleFromExamples(t *testing.T) {
+	examples := []RuleExample{
+		{Description: "ALBERT HEIJN 1234 AMSTERDAM", Amount: "23.40", Type: "withdrawal", DestinationName: "Albert Heijn"},
+		{Description: "Albert Heijn 5678 Utrecht", Amount: "-61.10", Type: "withdrawal", DestinationName: "Albert Heijn"},
+		{Description: "albert heijn to go", Amount: "4.99", Type: "withdrawal", DestinationName: "Albert Heijn"},
+	}
+	built := buildRuleFromExamples(examples, 10, false)
+
+	assert.Equal(t, 3, built.Examples)
+	assert.Equal(t, "store-journal", built.Rule.Trigger)
+	assert.True(t, *built.Rule.Strict)
+	assert.Equal(t, []RuleTriggerRequest{
+		{Type: "description_contains", Value: "albert heijn"},
+		{Type: "transaction_type", Value: "withdrawal"},
+		{Type: "to_account_is", Value: "Albert Heijn"},
+		{Type: "amount_more", Value: "4.49"},
+		{Type: "amount_less", Value: "67.21"},
+	}, built.Rule.Triggers)
+	assert.Len(t, built.Explanation, 5)
+	assert.Equal(t, "Auto: albert heijn", built.Rule.Title)
+	assert.Empty(t, built.Warnings)
+
+	same := buildRuleFromExamples([]RuleExample{
+		{Description: "Gym membership", Amount: "29.99"},
+		{Description: "gym membership", Amount: "29.99"},
+	}, 10, false)
+	assert.Equal(t, []RuleTriggerRequest{
+		{Type: "description_is", Value: "Gym membership"},
+		{Type: "amount_exactly", Value: "29.99"},
+	}, same.Rule.Triggers)
+
+	unrelated := buildRuleFromExamples([]RuleExample{{Description: "Rent"}, {Description: "Groceries"}}, 10, true)
+	assert.Empty(t, unrelated.Rule.Triggers)
+	assert.Len(t, unrelated.Warnings, 2)
+}
+
+func TestHandleBuildRuleFromExamples(t *testing.T) {
+	server, err := NewFireflyMCPServer(newPluginTestConfig())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	result, _, err := server.handleBuildRuleFromExamples(ctx, nil, BuildRuleFromExamplesArgs{
+		Examples: []RuleExample{{Description: "Spotify"}},
+	})
+	require.NoError(t, err)
+	assert.True(t, result.IsError, "an outcome is required")
+
+	result, _, err = server.handleBuildRuleFromExamples(ctx, nil, BuildRuleFromExamplesArgs{
+		Examples:       []RuleExample{{Description: "Spotify P1234"}, {Description: "SPOTIFY P5678"}},
+		CategoryName:   "Subscriptions",
+		Tag:            "music",
+		RuleGroupTitle: "Subscriptions",
+		IgnoreAmount:   true,
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var built BuiltRule
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &built))
+	assert.Equal(t, []RuleTriggerRequest{{Type: "description_contains", Value: "Spotify P"}}, built.Rule.Triggers)
+	require.Len(t, built.Rule.Actions, 2)
+	assert.Equal(t, "set_category", built.Rule.Actions[0].Type)
+	assert.Equal(t, "Subscriptions", *built.Rule.Actions[0].Value)
+	assert.Equal(t, "add_tag", built.Rule.Actions[1].Type)
+	assert.Equal(t, "Subscriptions", *built.Rule.RuleGroupTitle)
+	assert.Empty(t, built.Warnings)
+}
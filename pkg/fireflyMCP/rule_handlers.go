@@ -3,11 +3,9 @@ package fireflyMCP
 import (
 	"context"
 	"fmt"
-	"time"
 
 	"github.com/dezer32/mcp-firefly-iii/pkg/client"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
-	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
 // Rule Group argument types
@@ -42,16 +40,14 @@ type ListRulesByGroupArgs struct {
 
 type TestRuleGroupArgs struct {
 	ID       string  `json:"id" jsonschema:"Rule group ID to test (required)"`
-	Start    string  `json:"start,omitempty" jsonschema:"Start date (YYYY-MM-DD)"`
-	End      string  `json:"end,omitempty" jsonschema:"End date (YYYY-MM-DD)"`
 	Accounts []int64 `json:"accounts,omitempty" jsonschema:"Limit to these account IDs"`
+	DateRange
 }
 
 type TriggerRuleGroupArgs struct {
 	ID       string  `json:"id" jsonschema:"Rule group ID to trigger (required)"`
-	Start    string  `json:"start,omitempty" jsonschema:"Start date (YYYY-MM-DD)"`
-	End      string  `json:"end,omitempty" jsonschema:"End date (YYYY-MM-DD)"`
 	Accounts []int64 `json:"accounts,omitempty" jsonschema:"Limit to these account IDs"`
+	DateRange
 }
 
 // Rule argument types
@@ -80,16 +76,14 @@ type DeleteRuleArgs struct {
 
 type TestRuleArgs struct {
 	ID       string  `json:"id" jsonschema:"Rule ID to test (required)"`
-	Start    string  `json:"start,omitempty" jsonschema:"Start date (YYYY-MM-DD)"`
-	End      string  `json:"end,omitempty" jsonschema:"End date (YYYY-MM-DD)"`
 	Accounts []int64 `json:"accounts,omitempty" jsonschema:"Limit to these account IDs"`
+	DateRange
 }
 
 type TriggerRuleArgs struct {
 	ID       string  `json:"id" jsonschema:"Rule ID to trigger (required)"`
-	Start    string  `json:"start,omitempty" jsonschema:"Start date (YYYY-MM-DD)"`
-	End      string  `json:"end,omitempty" jsonschema:"End date (YYYY-MM-DD)"`
 	Accounts []int64 `json:"accounts,omitempty" jsonschema:"Limit to these account IDs"`
+	DateRange
 }
 
 // Rule Group handlers
@@ -106,10 +100,7 @@ func (s *FireflyMCPServer) handleListRuleGroups(
 
 	apiParams := &client.ListRuleGroupParams{}
 
-	if args.Limit > 0 {
-		limit := int32(args.Limit)
-		apiParams.Limit = &limit
-	}
+	apiParams.Limit = s.limitParam("list_rule_groups", args.Limit)
 
 	page := int32(args.Page)
 	if page == 0 {
@@ -123,7 +114,7 @@ func (s *FireflyMCPServer) handleListRuleGroups(
 	}
 
 	if resp.StatusCode() != 200 {
-		return newErrorResult(fmt.Sprintf("API error: %d - %s", resp.StatusCode(), string(resp.Body)))
+		return newErrorResult(fmt.Sprintf("API error: %d - %s", resp.StatusCode(), s.upstreamError(resp.Body)))
 	}
 
 	ruleGroupList := mapRuleGroupArrayToRuleGroupList(resp.ApplicationvndApiJSON200)
@@ -155,7 +146,7 @@ func (s *FireflyMCPServer) handleGetRuleGroup(
 	}
 
 	if resp.StatusCode() != 200 {
-		return newErrorResult(fmt.Sprintf("API error: %d - %s", resp.StatusCode(), string(resp.Body)))
+		return newErrorResult(fmt.Sprintf("API error: %d - %s", resp.StatusCode(), s.upstreamError(resp.Body)))
 	}
 
 	var ruleGroup *RuleGroup
@@ -188,18 +179,20 @@ func (s *FireflyMCPServer) handleCreateRuleGroup(
 	}
 
 	if resp.StatusCode() == 422 {
-		return newErrorResult(fmt.Sprintf("Validation error: %s", string(resp.Body)))
+		return newErrorResult(fmt.Sprintf("Validation error: %s", s.upstreamError(resp.Body)))
 	}
 
-	if resp.StatusCode() != 200 {
-		return newErrorResult(fmt.Sprintf("API error: %d - %s", resp.StatusCode(), string(resp.Body)))
+	if !isSuccessStatus(resp.StatusCode()) {
+		return newErrorResult(fmt.Sprintf("API error: %d - %s", resp.StatusCode(), s.upstreamError(resp.Body)))
 	}
-
-	var ruleGroup *RuleGroup
-	if resp.ApplicationvndApiJSON200 != nil {
-		ruleGroup = mapRuleGroupReadToRuleGroup(&resp.ApplicationvndApiJSON200.Data)
+	single, err := writeResponseBody(resp.StatusCode(), resp.ApplicationvndApiJSON200, resp.Body)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error parsing response: %v (body: %s)", err, s.upstreamError(resp.Body)))
 	}
-	return newSuccessResult(ruleGroup)
+	if single == nil {
+		return newSuccessResult(createdResult())
+	}
+	return newSuccessResult(mapRuleGroupReadToRuleGroup(&single.Data))
 }
 
 func (s *FireflyMCPServer) handleUpdateRuleGroup(
@@ -229,18 +222,20 @@ func (s *FireflyMCPServer) handleUpdateRuleGroup(
 	}
 
 	if resp.StatusCode() == 422 {
-		return newErrorResult(fmt.Sprintf("Validation error: %s", string(resp.Body)))
+		return newErrorResult(fmt.Sprintf("Validation error: %s", s.upstreamError(resp.Body)))
 	}
 
-	if resp.StatusCode() != 200 {
-		return newErrorResult(fmt.Sprintf("API error: %d - %s", resp.StatusCode(), string(resp.Body)))
+	if !isSuccessStatus(resp.StatusCode()) {
+		return newErrorResult(fmt.Sprintf("API error: %d - %s", resp.StatusCode(), s.upstreamError(resp.Body)))
 	}
-
-	var ruleGroup *RuleGroup
-	if resp.ApplicationvndApiJSON200 != nil {
-		ruleGroup = mapRuleGroupReadToRuleGroup(&resp.ApplicationvndApiJSON200.Data)
+	single, err := writeResponseBody(resp.StatusCode(), resp.ApplicationvndApiJSON200, resp.Body)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error parsing response: %v (body: %s)", err, s.upstreamError(resp.Body)))
 	}
-	return newSuccessResult(ruleGroup)
+	if single == nil {
+		return newSuccessResult(updatedResult(args.ID))
+	}
+	return newSuccessResult(mapRuleGroupReadToRuleGroup(&single.Data))
 }
 
 func (s *FireflyMCPServer) handleDeleteRuleGroup(
@@ -251,14 +246,25 @@ func (s *FireflyMCPServer) handleDeleteRuleGroup(
 	if args.ID == "" {
 		return newErrorResult("Rule group ID is required")
 	}
+	if s.trashEnabled() {
+		return s.moveToTrash(ctx, req, trashKindRuleGroup, args.ID)
+	}
+	return s.deleteRuleGroup(ctx, req, args.ID)
+}
 
+// deleteRuleGroup deletes a rule group in Firefly III, bypassing the trash
+func (s *FireflyMCPServer) deleteRuleGroup(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	id string,
+) (*mcp.CallToolResult, any, error) {
 	apiClient, err := s.getClient(ctx, req)
 	if err != nil {
 		return newErrorResult(fmt.Sprintf("Failed to get API client: %v", err))
 	}
 
 	apiParams := &client.DeleteRuleGroupParams{}
-	resp, err := apiClient.DeleteRuleGroupWithResponse(ctx, args.ID, apiParams)
+	resp, err := apiClient.DeleteRuleGroupWithResponse(ctx, id, apiParams)
 	if err != nil {
 		return newErrorResult(fmt.Sprintf("Error deleting rule group: %v", err))
 	}
@@ -267,11 +273,11 @@ func (s *FireflyMCPServer) handleDeleteRuleGroup(
 		return newErrorResult("Rule group not found")
 	}
 
-	if resp.StatusCode() != 204 {
-		return newErrorResult(fmt.Sprintf("API error: %d - %s", resp.StatusCode(), string(resp.Body)))
+	if !isSuccessStatus(resp.StatusCode()) {
+		return newErrorResult(fmt.Sprintf("API error: %d - %s", resp.StatusCode(), s.upstreamError(resp.Body)))
 	}
 
-	return newSuccessResult(map[string]string{"status": "deleted", "id": args.ID})
+	return newSuccessResult(deletedResult(id))
 }
 
 func (s *FireflyMCPServer) handleListRulesByGroup(
@@ -290,10 +296,7 @@ func (s *FireflyMCPServer) handleListRulesByGroup(
 
 	apiParams := &client.ListRuleByGroupParams{}
 
-	if args.Limit > 0 {
-		limit := int32(args.Limit)
-		apiParams.Limit = &limit
-	}
+	apiParams.Limit = s.limitParam("list_rules_by_group", args.Limit)
 
 	page := int32(args.Page)
 	if page == 0 {
@@ -311,7 +314,7 @@ func (s *FireflyMCPServer) handleListRulesByGroup(
 	}
 
 	if resp.StatusCode() != 200 {
-		return newErrorResult(fmt.Sprintf("API error: %d - %s", resp.StatusCode(), string(resp.Body)))
+		return newErrorResult(fmt.Sprintf("API error: %d - %s", resp.StatusCode(), s.upstreamError(resp.Body)))
 	}
 
 	ruleList := mapRuleArrayToRuleList(resp.ApplicationvndApiJSON200)
@@ -334,24 +337,12 @@ func (s *FireflyMCPServer) handleTestRuleGroup(
 
 	apiParams := &client.TestRuleGroupParams{}
 
-	// Parse date filters
-	if args.Start != "" {
-		startDate, err := time.Parse("2006-01-02", args.Start)
-		if err != nil {
-			return newErrorResult(fmt.Sprintf("Invalid start date format: %v", err))
-		}
-		date := openapi_types.Date{Time: startDate}
-		apiParams.Start = &date
-	}
-
-	if args.End != "" {
-		endDate, err := time.Parse("2006-01-02", args.End)
-		if err != nil {
-			return newErrorResult(fmt.Sprintf("Invalid end date format: %v", err))
-		}
-		date := openapi_types.Date{Time: endDate}
-		apiParams.End = &date
+	dates, err := s.resolveDateRange(args.DateRange, dateRangeOpen)
+	if err != nil {
+		return newErrorResult(err.Error())
 	}
+	apiParams.Start = dates.Start
+	apiParams.End = dates.End
 
 	if len(args.Accounts) > 0 {
 		apiParams.Accounts = &args.Accounts
@@ -367,7 +358,7 @@ func (s *FireflyMCPServer) handleTestRuleGroup(
 	}
 
 	if resp.StatusCode() != 200 {
-		return newErrorResult(fmt.Sprintf("API error: %d - %s", resp.StatusCode(), string(resp.Body)))
+		return newErrorResult(fmt.Sprintf("API error: %d - %s", resp.StatusCode(), s.upstreamError(resp.Body)))
 	}
 
 	// Return matched transactions
@@ -391,24 +382,12 @@ func (s *FireflyMCPServer) handleTriggerRuleGroup(
 
 	apiParams := &client.FireRuleGroupParams{}
 
-	// Parse date filters
-	if args.Start != "" {
-		startDate, err := time.Parse("2006-01-02", args.Start)
-		if err != nil {
-			return newErrorResult(fmt.Sprintf("Invalid start date format: %v", err))
-		}
-		date := openapi_types.Date{Time: startDate}
-		apiParams.Start = &date
-	}
-
-	if args.End != "" {
-		endDate, err := time.Parse("2006-01-02", args.End)
-		if err != nil {
-			return newErrorResult(fmt.Sprintf("Invalid end date format: %v", err))
-		}
-		date := openapi_types.Date{Time: endDate}
-		apiParams.End = &date
+	dates, err := s.resolveDateRange(args.DateRange, dateRangeOpen)
+	if err != nil {
+		return newErrorResult(err.Error())
 	}
+	apiParams.Start = dates.Start
+	apiParams.End = dates.End
 
 	if len(args.Accounts) > 0 {
 		apiParams.Accounts = &args.Accounts
@@ -423,11 +402,11 @@ func (s *FireflyMCPServer) handleTriggerRuleGroup(
 		return newErrorResult("Rule group not found")
 	}
 
-	if resp.StatusCode() != 204 {
-		return newErrorResult(fmt.Sprintf("API error: %d - %s", resp.StatusCode(), string(resp.Body)))
+	if !isSuccessStatus(resp.StatusCode()) {
+		return newErrorResult(fmt.Sprintf("API error: %d - %s", resp.StatusCode(), s.upstreamError(resp.Body)))
 	}
 
-	return newSuccessResult(map[string]string{"status": "triggered", "id": args.ID, "message": "Rule group execution started asynchronously"})
+	return newSuccessResult(triggeredResult(args.ID, "Rule group execution started asynchronously"))
 }
 
 // Rule handlers
@@ -444,10 +423,7 @@ func (s *FireflyMCPServer) handleListRules(
 
 	apiParams := &client.ListRuleParams{}
 
-	if args.Limit > 0 {
-		limit := int32(args.Limit)
-		apiParams.Limit = &limit
-	}
+	apiParams.Limit = s.limitParam("list_rules", args.Limit)
 
 	page := int32(args.Page)
 	if page == 0 {
@@ -461,7 +437,7 @@ func (s *FireflyMCPServer) handleListRules(
 	}
 
 	if resp.StatusCode() != 200 {
-		return newErrorResult(fmt.Sprintf("API error: %d - %s", resp.StatusCode(), string(resp.Body)))
+		return newErrorResult(fmt.Sprintf("API error: %d - %s", resp.StatusCode(), s.upstreamError(resp.Body)))
 	}
 
 	ruleList := mapRuleArrayToRuleList(resp.ApplicationvndApiJSON200)
@@ -493,7 +469,7 @@ func (s *FireflyMCPServer) handleGetRule(
 	}
 
 	if resp.StatusCode() != 200 {
-		return newErrorResult(fmt.Sprintf("API error: %d - %s", resp.StatusCode(), string(resp.Body)))
+		return newErrorResult(fmt.Sprintf("API error: %d - %s", resp.StatusCode(), s.upstreamError(resp.Body)))
 	}
 
 	var rule *Rule
@@ -539,18 +515,20 @@ func (s *FireflyMCPServer) handleCreateRule(
 	}
 
 	if resp.StatusCode() == 422 {
-		return newErrorResult(fmt.Sprintf("Validation error: %s", string(resp.Body)))
+		return newErrorResult(fmt.Sprintf("Validation error: %s", s.upstreamError(resp.Body)))
 	}
 
-	if resp.StatusCode() != 200 {
-		return newErrorResult(fmt.Sprintf("API error: %d - %s", resp.StatusCode(), string(resp.Body)))
+	if !isSuccessStatus(resp.StatusCode()) {
+		return newErrorResult(fmt.Sprintf("API error: %d - %s", resp.StatusCode(), s.upstreamError(resp.Body)))
 	}
-
-	var rule *Rule
-	if resp.ApplicationvndApiJSON200 != nil {
-		rule = mapRuleReadToRule(&resp.ApplicationvndApiJSON200.Data)
+	single, err := writeResponseBody(resp.StatusCode(), resp.ApplicationvndApiJSON200, resp.Body)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error parsing response: %v (body: %s)", err, s.upstreamError(resp.Body)))
 	}
-	return newSuccessResult(rule)
+	if single == nil {
+		return newSuccessResult(createdResult())
+	}
+	return newSuccessResult(mapRuleReadToRule(&single.Data))
 }
 
 func (s *FireflyMCPServer) handleUpdateRule(
@@ -580,18 +558,20 @@ func (s *FireflyMCPServer) handleUpdateRule(
 	}
 
 	if resp.StatusCode() == 422 {
-		return newErrorResult(fmt.Sprintf("Validation error: %s", string(resp.Body)))
+		return newErrorResult(fmt.Sprintf("Validation error: %s", s.upstreamError(resp.Body)))
 	}
 
-	if resp.StatusCode() != 200 {
-		return newErrorResult(fmt.Sprintf("API error: %d - %s", resp.StatusCode(), string(resp.Body)))
+	if !isSuccessStatus(resp.StatusCode()) {
+		return newErrorResult(fmt.Sprintf("API error: %d - %s", resp.StatusCode(), s.upstreamError(resp.Body)))
 	}
-
-	var rule *Rule
-	if resp.ApplicationvndApiJSON200 != nil {
-		rule = mapRuleReadToRule(&resp.ApplicationvndApiJSON200.Data)
+	single, err := writeResponseBody(resp.StatusCode(), resp.ApplicationvndApiJSON200, resp.Body)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error parsing response: %v (body: %s)", err, s.upstreamError(resp.Body)))
 	}
-	return newSuccessResult(rule)
+	if single == nil {
+		return newSuccessResult(updatedResult(args.ID))
+	}
+	return newSuccessResult(mapRuleReadToRule(&single.Data))
 }
 
 func (s *FireflyMCPServer) handleDeleteRule(
@@ -602,14 +582,25 @@ func (s *FireflyMCPServer) handleDeleteRule(
 	if args.ID == "" {
 		return newErrorResult("Rule ID is required")
 	}
+	if s.trashEnabled() {
+		return s.moveToTrash(ctx, req, trashKindRule, args.ID)
+	}
+	return s.deleteRule(ctx, req, args.ID)
+}
 
+// deleteRule deletes a rule in Firefly III, bypassing the trash
+func (s *FireflyMCPServer) deleteRule(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	id string,
+) (*mcp.CallToolResult, any, error) {
 	apiClient, err := s.getClient(ctx, req)
 	if err != nil {
 		return newErrorResult(fmt.Sprintf("Failed to get API client: %v", err))
 	}
 
 	apiParams := &client.DeleteRuleParams{}
-	resp, err := apiClient.DeleteRuleWithResponse(ctx, args.ID, apiParams)
+	resp, err := apiClient.DeleteRuleWithResponse(ctx, id, apiParams)
 	if err != nil {
 		return newErrorResult(fmt.Sprintf("Error deleting rule: %v", err))
 	}
@@ -618,11 +609,11 @@ func (s *FireflyMCPServer) handleDeleteRule(
 		return newErrorResult("Rule not found")
 	}
 
-	if resp.StatusCode() != 204 {
-		return newErrorResult(fmt.Sprintf("API error: %d - %s", resp.StatusCode(), string(resp.Body)))
+	if !isSuccessStatus(resp.StatusCode()) {
+		return newErrorResult(fmt.Sprintf("API error: %d - %s", resp.StatusCode(), s.upstreamError(resp.Body)))
 	}
 
-	return newSuccessResult(map[string]string{"status": "deleted", "id": args.ID})
+	return newSuccessResult(deletedResult(id))
 }
 
 func (s *FireflyMCPServer) handleTestRule(
@@ -641,24 +632,12 @@ func (s *FireflyMCPServer) handleTestRule(
 
 	apiParams := &client.TestRuleParams{}
 
-	// Parse date filters
-	if args.Start != "" {
-		startDate, err := time.Parse("2006-01-02", args.Start)
-		if err != nil {
-			return newErrorResult(fmt.Sprintf("Invalid start date format: %v", err))
-		}
-		date := openapi_types.Date{Time: startDate}
-		apiParams.Start = &date
-	}
-
-	if args.End != "" {
-		endDate, err := time.Parse("2006-01-02", args.End)
-		if err != nil {
-			return newErrorResult(fmt.Sprintf("Invalid end date format: %v", err))
-		}
-		date := openapi_types.Date{Time: endDate}
-		apiParams.End = &date
+	dates, err := s.resolveDateRange(args.DateRange, dateRangeOpen)
+	if err != nil {
+		return newErrorResult(err.Error())
 	}
+	apiParams.Start = dates.Start
+	apiParams.End = dates.End
 
 	if len(args.Accounts) > 0 {
 		apiParams.Accounts = &args.Accounts
@@ -674,7 +653,7 @@ func (s *FireflyMCPServer) handleTestRule(
 	}
 
 	if resp.StatusCode() != 200 {
-		return newErrorResult(fmt.Sprintf("API error: %d - %s", resp.StatusCode(), string(resp.Body)))
+		return newErrorResult(fmt.Sprintf("API error: %d - %s", resp.StatusCode(), s.upstreamError(resp.Body)))
 	}
 
 	// Return matched transactions
@@ -698,24 +677,12 @@ func (s *FireflyMCPServer) handleTriggerRule(
 
 	apiParams := &client.FireRuleParams{}
 
-	// Parse date filters
-	if args.Start != "" {
-		startDate, err := time.Parse("2006-01-02", args.Start)
-		if err != nil {
-			return newErrorResult(fmt.Sprintf("Invalid start date format: %v", err))
-		}
-		date := openapi_types.Date{Time: startDate}
-		apiParams.Start = &date
-	}
-
-	if args.End != "" {
-		endDate, err := time.Parse("2006-01-02", args.End)
-		if err != nil {
-			return newErrorResult(fmt.Sprintf("Invalid end date format: %v", err))
-		}
-		date := openapi_types.Date{Time: endDate}
-		apiParams.End = &date
+	dates, err := s.resolveDateRange(args.DateRange, dateRangeOpen)
+	if err != nil {
+		return newErrorResult(err.Error())
 	}
+	apiParams.Start = dates.Start
+	apiParams.End = dates.End
 
 	if len(args.Accounts) > 0 {
 		apiParams.Accounts = &args.Accounts
@@ -730,9 +697,9 @@ func (s *FireflyMCPServer) handleTriggerRule(
 		return newErrorResult("Rule not found")
 	}
 
-	if resp.StatusCode() != 204 {
-		return newErrorResult(fmt.Sprintf("API error: %d - %s", resp.StatusCode(), string(resp.Body)))
+	if !isSuccessStatus(resp.StatusCode()) {
+		return newErrorResult(fmt.Sprintf("API error: %d - %s", resp.StatusCode(), s.upstreamError(resp.Body)))
 	}
 
-	return newSuccessResult(map[string]string{"status": "triggered", "id": args.ID, "message": "Rule execution started asynchronously"})
+	return newSuccessResult(triggeredResult(args.ID, "Rule execution started asynchronously"))
 }
@@ -0,0 +1,237 @@
+package fireflyMCP
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Chunk sizes of summarize_transactions, in transaction splits per sampling request
+const (
+	defaultSummaryChunkSize = 100
+	maxSummaryChunkSize     = 500
+)
+
+// summarySystemPrompt instructs the client's model for every sampling request
+const summarySystemPrompt = "You analyze Firefly III transactions to answer a question about them. " +
+	"Use only the data given. Cite the transactions that support each statement by their ID as [#123]. " +
+	"Be concise and state amounts with their currency."
+
+// summaryCitationPattern finds transaction IDs cited as #123
+var summaryCitationPattern = regexp.MustCompile(`#(\d+)`)
+
+// SummarizeTransactionsArgs represents the arguments for the summarize_transactions tool
+type SummarizeTransactionsArgs struct {
+	Question  string `json:"question" jsonschema:"What to find out about the transactions, e.g. which subscriptions increased in price (required)"`
+	Type      string `json:"type,omitempty" jsonschema:"Only transactions of this type: withdrawal, deposit or transfer"`
+	ChunkSize int    `json:"chunk_size,omitempty" jsonschema:"Transaction splits sent per sampling request (default: 100, max: 500)"`
+	DateRange
+}
+
+// SummaryCitation is a transaction cited by the answer
+type SummaryCitation struct {
+	Id           string `json:"id"` // Transaction group ID, as taken by get_transaction
+	Date         string `json:"date"`
+	Description  string `json:"description"`
+	Amount       string `json:"amount"`
+	CurrencyCode string `json:"currency_code"`
+}
+
+// TransactionSummary is the result of summarize_transactions
+type TransactionSummary struct {
+	Question              string            `json:"question"`
+	Start                 string            `json:"start"`
+	End                   string            `json:"end"`
+	TransactionCount      int               `json:"transaction_count"`
+	TransactionsTruncated bool              `json:"transactions_truncated,omitempty"`
+	Chunks                int               `json:"chunks"`
+	Answer                string            `json:"answer"`
+	Citations             []SummaryCitation `json:"citations"`
+	Model                 string            `json:"model,omitempty"` // Model the client sampled with, as reported by the client
+}
+
+// handleSummarizeTransactions fetches the transactions of a period and has the
+// client's model answer a question about them through MCP sampling: every chunk
+// is summarized on its own, then the summaries are combined into one answer.
+// Only the answer and the cited transactions reach the conversation.
+func (s *FireflyMCPServer) handleSummarizeTransactions(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args SummarizeTransactionsArgs,
+) (*mcp.CallToolResult, any, error) {
+	if strings.TrimSpace(args.Question) == "" {
+		return newErrorResult("Error: question is required")
+	}
+	chunkSize := args.ChunkSize
+	if chunkSize == 0 {
+		chunkSize = defaultSummaryChunkSize
+	}
+	if chunkSize < 1 || chunkSize > maxSummaryChunkSize {
+		return newErrorResult(fmt.Sprintf("Error: chunk_size must be between 1 and %d", maxSummaryChunkSize))
+	}
+	if err := checkSamplingSupport(req); err != nil {
+		return newErrorResult(fmt.Sprintf("Error: %v", err))
+	}
+	dates, err := s.resolveDateRange(args.DateRange, dateRangeRequired)
+	if err != nil {
+		return newErrorResult(err.Error())
+	}
+
+	groups, truncated, err := s.fetchTransactionGroups(ctx, req, ListTransactionsArgs{
+		Type:      args.Type,
+		DateRange: DateRange{Start: dates.StartString(), End: dates.EndString()},
+	})
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error listing transactions: %v", err))
+	}
+
+	summary := &TransactionSummary{
+		Question:              args.Question,
+		Start:                 dates.StartString(),
+		End:                   dates.EndString(),
+		TransactionsTruncated: truncated,
+		Citations:             []SummaryCitation{},
+	}
+	lines := summaryLines(groups)
+	summary.TransactionCount = len(lines)
+	if len(lines) == 0 {
+		summary.Answer = "There are no transactions in the period."
+		return newSuccessResult(summary)
+	}
+
+	var partials []string
+	for start := 0; start < len(lines); start += chunkSize {
+		chunk := lines[start:min(start+chunkSize, len(lines))]
+		prompt := fmt.Sprintf("Question: %s\n\nTransactions %d to %d of %d (ID | date | type | amount | description | "+
+			"source -> destination | category | budget | tags):\n%s",
+			args.Question, start+1, start+len(chunk), len(lines), strings.Join(chunk, "\n"))
+		text, model, err := s.sample(ctx, req, prompt)
+		if err != nil {
+			return newErrorResult(fmt.Sprintf("Error: sampling chunk %d failed: %v", len(partials)+1, err))
+		}
+		partials = append(partials, text)
+		summary.Model = model
+	}
+	summary.Chunks = len(partials)
+
+	summary.Answer = partials[0]
+	if len(partials) > 1 {
+		var prompt strings.Builder
+		fmt.Fprintf(&prompt, "Question: %s\n\nThe transactions were analyzed in %d parts. "+
+			"Combine the findings of the parts into one answer, keeping the cited IDs.\n", args.Question, len(partials))
+		for i, partial := range partials {
+			fmt.Fprintf(&prompt, "\nPart %d:\n%s\n", i+1, partial)
+		}
+		text, model, err := s.sample(ctx, req, prompt.String())
+		if err != nil {
+			return newErrorResult(fmt.Sprintf("Error: sampling the combined answer failed: %v", err))
+		}
+		summary.Answer = text
+		summary.Model = model
+	}
+	summary.Citations = summaryCitations(summary.Answer, groups)
+	return newSuccessResult(summary)
+}
+
+// checkSamplingSupport reports an error unless the client declared the sampling capability
+func checkSamplingSupport(req *mcp.CallToolRequest) error {
+	if req == nil || req.Session == nil {
+		return errors.New("no client session to sample with")
+	}
+	params := req.Session.InitializeParams()
+	if params == nil || params.Capabilities == nil || params.Capabilities.Sampling == nil {
+		return errors.New("the client does not support MCP sampling")
+	}
+	return nil
+}
+
+// sample asks the client's model to answer a prompt and returns its text and model name
+func (s *FireflyMCPServer) sample(ctx context.Context, req *mcp.CallToolRequest, prompt string) (string, string, error) {
+	maxTokens := 1024
+	if config := s.currentConfig(); config != nil && config.Sampling.MaxTokens > 0 {
+		maxTokens = config.Sampling.MaxTokens
+	}
+	result, err := req.Session.CreateMessage(ctx, &mcp.CreateMessageParams{
+		SystemPrompt: summarySystemPrompt,
+		MaxTokens:    int64(maxTokens),
+		Messages:     []*mcp.SamplingMessage{{Role: "user", Content: &mcp.TextContent{Text: prompt}}},
+	})
+	if err != nil {
+		return "", "", err
+	}
+	text, ok := result.Content.(*mcp.TextContent)
+	if !ok || strings.TrimSpace(text.Text) == "" {
+		return "", "", errors.New("the client returned no text")
+	}
+	return strings.TrimSpace(text.Text), result.Model, nil
+}
+
+// fetchTransactionGroups lists the transaction groups matching filters, keeping
+// the group IDs that fetchTransactions flattens away
+func (s *FireflyMCPServer) fetchTransactionGroups(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	filters ListTransactionsArgs,
+) ([]TransactionGroup, bool, error) {
+	filters.Limit = compositePageSize
+	return fetchPages(ctx, s.pageParallelism(), func(ctx context.Context, page int) ([]TransactionGroup, int, error) {
+		pageFilters := filters
+		pageFilters.Page = page
+		list, err := callTool[ListTransactionsArgs, TransactionList](ctx, req, s.handleListTransactions, pageFilters)
+		if err != nil {
+			return nil, 0, err
+		}
+		return list.Data, list.Pagination.TotalPages, nil
+	})
+}
+
+// summaryLines formats every split as one line for the model, prefixed with the ID of its group
+func summaryLines(groups []TransactionGroup) []string {
+	var lines []string
+	for _, group := range groups {
+		for _, split := range group.Transactions {
+			lines = append(lines, fmt.Sprintf("#%s | %s | %s | %s %s | %s | %s -> %s | %s | %s | %s",
+				group.Id, split.Date.Format("2006-01-02"), split.Type, split.Amount, split.CurrencyCode, split.Description,
+				split.SourceName, split.DestinationName, getStringValue(split.CategoryName), getStringValue(split.BudgetName),
+				strings.Join(split.Tags, ", ")))
+		}
+	}
+	return lines
+}
+
+// summaryCitations lists the fetched transactions the answer cites, in ID
+// order. IDs the model made up are dropped.
+func summaryCitations(answer string, groups []TransactionGroup) []SummaryCitation {
+	byId := make(map[string]TransactionGroup, len(groups))
+	for _, group := range groups {
+		byId[group.Id] = group
+	}
+	citations := []SummaryCitation{}
+	seen := make(map[string]bool)
+	for _, match := range summaryCitationPattern.FindAllStringSubmatch(answer, -1) {
+		group, ok := byId[match[1]]
+		if !ok || seen[group.Id] || len(group.Transactions) == 0 {
+			continue
+		}
+		seen[group.Id] = true
+		split := group.Transactions[0]
+		description := split.Description
+		if group.GroupTitle != "" {
+			description = group.GroupTitle
+		}
+		citations = append(citations, SummaryCitation{
+			Id:           group.Id,
+			Date:         split.Date.Format("2006-01-02"),
+			Description:  description,
+			Amount:       split.Amount,
+			CurrencyCode: split.CurrencyCode,
+		})
+	}
+	sort.Slice(citations, func(i, j int) bool { return lessNumericId(citations[i].Id, citations[j].Id) })
+	return citations
+}
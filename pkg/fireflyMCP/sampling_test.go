@@ -0,0 +1,102 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSamplingTestSession(t *testing.T, handler func(context.Context, *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error)) *mcp.ClientSession {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		groups := make([]string, 3)
+		for i, name := range []string{"Netflix", "Spotify", "Bakery"} {
+			groups[i] = fmt.Sprintf(`{"type":"transactions","id":"%d","attributes":{"transactions":[{"type":"withdrawal",`+
+				`"date":"2024-03-0%dT00:00:00+00:00","amount":"1%d.99","currency_code":"EUR","description":%q,`+
+				`"source_name":"Checking","destination_name":%q}]}}`, i+1, i+1, i, name, name)
+		}
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		fmt.Fprintf(w, `{"data":[%s],"meta":{"pagination":{"total":3,"count":3,"per_page":200,"current_page":1,"total_pages":1}}}`,
+			strings.Join(groups, ","))
+	}))
+	t.Cleanup(ts.Close)
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	config.Sampling.Enabled = true
+	config.Sampling.MaxTokens = 500
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.MCPServer().Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { serverSession.Close() })
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, &mcp.ClientOptions{CreateMessageHandler: handler})
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { session.Close() })
+	return session
+}
+
+func TestSummarizeTransactions(t *testing.T) {
+	var mu sync.Mutex
+	var prompts []string
+	session := newSamplingTestSession(t, func(ctx context.Context, req *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+		prompt := req.Params.Messages[0].Content.(*mcp.TextContent).Text
+		mu.Lock()
+		prompts = append(prompts, prompt)
+		mu.Unlock()
+		assert.Equal(t, int64(500), req.Params.MaxTokens)
+		answer := "Streaming costs 21.98 EUR [#1] [#2]"
+		if strings.Contains(prompt, "Part 2:") {
+			answer = "Streaming: Netflix [#1] and Spotify [#2]; #2 rose, see also #99"
+		}
+		return &mcp.CreateMessageResult{Content: &mcp.TextContent{Text: answer}, Model: "test-model", Role: "assistant"}, nil
+	})
+
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "summarize_transactions",
+		Arguments: map[string]any{"question": "What do I spend on streaming?", "period": "last_month", "chunk_size": 2},
+	})
+	require.NoError(t, err)
+	text := result.Content[0].(*mcp.TextContent).Text
+	require.False(t, result.IsError, text)
+	var summary TransactionSummary
+	require.NoError(t, json.Unmarshal([]byte(text), &summary))
+
+	assert.Equal(t, 3, summary.TransactionCount)
+	assert.Equal(t, 2, summary.Chunks)
+	assert.Equal(t, "test-model", summary.Model)
+	assert.Contains(t, summary.Answer, "Netflix [#1]")
+	require.Len(t, summary.Citations, 2, "made-up IDs are dropped")
+	assert.Equal(t, SummaryCitation{Id: "1", Date: "2024-03-01", Description: "Netflix", Amount: "10.99", CurrencyCode: "EUR"},
+		summary.Citations[0])
+	assert.Equal(t, "2", summary.Citations[1].Id)
+
+	require.Len(t, prompts, 3, "two chunks and the combined answer")
+	assert.Contains(t, prompts[0], "Transactions 1 to 2 of 3")
+	assert.Contains(t, prompts[0], "#1 | 2024-03-01 | withdrawal | 10.99 EUR | Netflix | Checking -> Netflix")
+	assert.Contains(t, prompts[1], "Transactions 3 to 3 of 3")
+}
+
+func TestSummarizeTransactions_WithoutSampling(t *testing.T) {
+	session := newSamplingTestSession(t, nil)
+
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "summarize_transactions",
+		Arguments: map[string]any{"question": "What do I spend on streaming?", "period": "last_month"},
+	})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "does not support MCP sampling")
+}
@@ -0,0 +1,291 @@
+package fireflyMCP
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/dezer32/mcp-firefly-iii/pkg/client"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// Steps of plan_savings_goal
+const (
+	savingsStepPiggyBank  = "create_piggy_bank"
+	savingsStepRecurrence = "create_recurrence"
+)
+
+// PlanSavingsGoalArgs represents the arguments for the plan_savings_goal tool
+type PlanSavingsGoalArgs struct {
+	Name             string `json:"name" jsonschema:"Name of the goal, used for the piggy bank and the recurrence (required)"`
+	TargetAmount     string `json:"target_amount" jsonschema:"Amount to save (required)"`
+	TargetDate       string `json:"target_date" jsonschema:"Date the amount must be saved by (YYYY-MM-DD, required)"`
+	CurrentAmount    string `json:"current_amount,omitempty" jsonschema:"Amount already saved (default: 0)"`
+	DayOfMonth       int    `json:"day_of_month,omitempty" jsonschema:"Day of the month of the contributions, 1-28 (default: 1)"`
+	SavingsAccountId string `json:"savings_account_id,omitempty" jsonschema:"Asset account ID (or alias) holding the savings; required to create the piggy bank or the recurrence"`
+	SourceAccountId  string `json:"source_account_id,omitempty" jsonschema:"Asset account ID (or alias) the contributions come from; required to create the recurrence"`
+	CreatePiggyBank  bool   `json:"create_piggy_bank,omitempty" jsonschema:"Create a piggy bank for the goal (default: false)"`
+	CreateRecurrence bool   `json:"create_recurrence,omitempty" jsonschema:"Create a monthly recurring transfer funding the goal (default: false)"`
+	Apply            bool   `json:"apply,omitempty" jsonschema:"Create the piggy bank and recurrence (default: false, only preview the requests)"`
+}
+
+// SavingsGoalStep is a create request of plan_savings_goal and its outcome
+type SavingsGoalStep struct {
+	Step    string `json:"step"`
+	Request any    `json:"request"`
+	Applied bool   `json:"applied"`
+	Id      string `json:"id,omitempty"`
+	Error   string `json:"error,omitempty"`
+	Skipped string `json:"skipped,omitempty"` // Why the step was not run
+}
+
+// SavingsGoalPlan is the result of plan_savings_goal
+type SavingsGoalPlan struct {
+	Name                string            `json:"name"`
+	TargetAmount        string            `json:"target_amount"`
+	TargetDate          string            `json:"target_date"`
+	CurrentAmount       string            `json:"current_amount"`
+	LeftToSave          string            `json:"left_to_save"`
+	Contributions       int               `json:"contributions"`
+	MonthlyContribution string            `json:"monthly_contribution"` // Rounded up to the cent
+	FirstContribution   string            `json:"first_contribution,omitempty"`
+	LastContribution    string            `json:"last_contribution,omitempty"`
+	Apply               bool              `json:"apply"`
+	Steps               []SavingsGoalStep `json:"steps,omitempty"`
+	Notes               []string          `json:"notes,omitempty"`
+}
+
+// handlePlanSavingsGoal computes the monthly contribution reaching a target by
+// a date and optionally creates a piggy bank and a recurring transfer for it
+func (s *FireflyMCPServer) handlePlanSavingsGoal(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args PlanSavingsGoalArgs,
+) (*mcp.CallToolResult, any, error) {
+	if args.Name == "" || args.TargetAmount == "" || args.TargetDate == "" {
+		return newErrorResult("Error: name, target_amount and target_date are required")
+	}
+	target, ok := parseDecimal(args.TargetAmount)
+	if !ok || target.Sign() <= 0 {
+		return newErrorResult("Error: target_amount must be a positive number")
+	}
+	current := decimal{}
+	if args.CurrentAmount != "" {
+		if current, ok = parseDecimal(args.CurrentAmount); !ok || current.Sign() < 0 {
+			return newErrorResult("Error: current_amount must be a number of at least 0")
+		}
+	}
+	targetDate, err := time.Parse("2006-01-02", args.TargetDate)
+	if err != nil {
+		return newErrorResult("Error: target_date must be in format YYYY-MM-DD")
+	}
+	day := args.DayOfMonth
+	if day == 0 {
+		day = 1
+	}
+	if day < 1 || day > 28 {
+		return newErrorResult("Error: day_of_month must be between 1 and 28")
+	}
+
+	left := target.Sub(current)
+	if left.Sign() < 0 {
+		left = decimal{}
+	}
+	plan := &SavingsGoalPlan{
+		Name:          args.Name,
+		TargetAmount:  formatAmount(target),
+		TargetDate:    targetDate.Format("2006-01-02"),
+		CurrentAmount: formatAmount(current),
+		LeftToSave:    formatAmount(left),
+		Apply:         args.Apply,
+	}
+	if left.Sign() == 0 {
+		plan.MonthlyContribution = formatAmount(left)
+		plan.Notes = append(plan.Notes, "The target amount is already saved")
+		return newSuccessResult(plan)
+	}
+
+	first, count := savingsContributions(s.today(), targetDate, day)
+	if count == 0 {
+		return newErrorResult(fmt.Sprintf("Error: target_date must be on or after the first contribution on %s",
+			first.Format("2006-01-02")))
+	}
+	monthly := monthlyContribution(left, count)
+	plan.Contributions = count
+	plan.MonthlyContribution = formatAmount(monthly)
+	plan.FirstContribution = first.Format("2006-01-02")
+	plan.LastContribution = first.AddDate(0, count-1, 0).Format("2006-01-02")
+	if overshoot := monthly.Mul(decimalFromInt(int64(count))).Sub(left); overshoot.Sign() > 0 {
+		plan.Notes = append(plan.Notes, fmt.Sprintf(
+			"Rounding up to the cent saves %s more than the target", formatAmount(overshoot)))
+	}
+
+	if !args.CreatePiggyBank && !args.CreateRecurrence {
+		return newSuccessResult(plan)
+	}
+	if args.SavingsAccountId == "" {
+		return newErrorResult("Error: savings_account_id is required to create a piggy bank or recurrence")
+	}
+	savingsID := s.resolveAccountRef(args.SavingsAccountId)
+	sourceID := s.resolveAccountRef(args.SourceAccountId)
+	if args.CreateRecurrence {
+		if args.SourceAccountId == "" {
+			return newErrorResult("Error: source_account_id is required to create the recurrence")
+		}
+		if sourceID == savingsID {
+			return newErrorResult("Error: source and savings account must be different")
+		}
+	}
+
+	var piggyStep, recurrenceStep *SavingsGoalStep
+	if args.CreatePiggyBank {
+		plan.Steps = append(plan.Steps, SavingsGoalStep{
+			Step:    savingsStepPiggyBank,
+			Request: savingsPiggyBank(plan, savingsID, s.today()),
+		})
+	}
+	if args.CreateRecurrence {
+		plan.Steps = append(plan.Steps, SavingsGoalStep{
+			Step:    savingsStepRecurrence,
+			Request: savingsRecurrence(plan, first, day, sourceID, savingsID),
+		})
+	}
+	for i := range plan.Steps {
+		switch plan.Steps[i].Step {
+		case savingsStepPiggyBank:
+			piggyStep = &plan.Steps[i]
+		case savingsStepRecurrence:
+			recurrenceStep = &plan.Steps[i]
+		}
+	}
+	if !args.Apply {
+		if piggyStep != nil && recurrenceStep != nil {
+			plan.Notes = append(plan.Notes, "The recurring transfers are linked to the piggy bank once it is created")
+		}
+		return newSuccessResult(plan)
+	}
+
+	apiClient, err := s.getClient(ctx, req)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Failed to get API client: %v", err))
+	}
+	if piggyStep != nil {
+		s.createSavingsPiggyBank(ctx, apiClient, piggyStep)
+	}
+	if recurrenceStep != nil {
+		if piggyStep != nil && !piggyStep.Applied {
+			recurrenceStep.Skipped = "the piggy bank could not be created"
+			return newSuccessResult(plan)
+		}
+		if piggyStep != nil {
+			recurrenceStep.Request.(client.RecurrenceStore).Transactions[0].PiggyBankId = &piggyStep.Id
+		}
+		s.createSavingsRecurrence(ctx, apiClient, recurrenceStep)
+	}
+	return newSuccessResult(plan)
+}
+
+// savingsContributions returns the first monthly contribution day after today
+// and the number of contributions up to and including the target date
+func savingsContributions(today, targetDate time.Time, day int) (time.Time, int) {
+	first := time.Date(today.Year(), today.Month(), day, 0, 0, 0, 0, time.UTC)
+	if !first.After(today) {
+		first = first.AddDate(0, 1, 0)
+	}
+	count := 0
+	for date := first; !date.After(targetDate); date = first.AddDate(0, count, 0) {
+		count++
+	}
+	return first, count
+}
+
+// monthlyContribution divides the amount left to save over the contributions,
+// rounded up to the cent so the contributions reach the target
+func monthlyContribution(left decimal, count int) decimal {
+	contributions := decimalFromInt(int64(count))
+	monthly := parseAmount(formatAmount(left.Div(contributions)))
+	if monthly.Mul(contributions).Cmp(left) < 0 {
+		monthly = monthly.Add(parseAmount("0.01"))
+	}
+	return monthly
+}
+
+// savingsPiggyBank returns the request creating the piggy bank of a goal
+func savingsPiggyBank(plan *SavingsGoalPlan, savingsID string, today time.Time) client.PiggyBankStore {
+	account := client.PiggyBankAccountStore{Id: &savingsID}
+	if parseAmount(plan.CurrentAmount).Sign() > 0 {
+		account.CurrentAmount = &plan.CurrentAmount
+	}
+	targetDate, _ := time.Parse("2006-01-02", plan.TargetDate)
+	return client.PiggyBankStore{
+		Name:         plan.Name,
+		Accounts:     &[]client.PiggyBankAccountStore{account},
+		TargetAmount: &plan.TargetAmount,
+		StartDate:    &openapi_types.Date{Time: today},
+		TargetDate:   &openapi_types.Date{Time: targetDate},
+	}
+}
+
+// savingsRecurrence returns the request creating the monthly transfers of a goal
+func savingsRecurrence(plan *SavingsGoalPlan, first time.Time, day int, sourceID, savingsID string) client.RecurrenceStore {
+	repetitions := int32(plan.Contributions)
+	description := "Savings: " + plan.Name
+	return client.RecurrenceStore{
+		Type:            client.RecurrenceTransactionTypeTransfer,
+		Title:           plan.Name,
+		Description:     &description,
+		FirstDate:       openapi_types.Date{Time: first},
+		NrOfRepetitions: &repetitions,
+		Repetitions: []client.RecurrenceRepetitionStore{
+			{Type: client.Monthly, Moment: strconv.Itoa(day)},
+		},
+		Transactions: []client.RecurrenceTransactionStore{{
+			Amount:        plan.MonthlyContribution,
+			Description:   description,
+			SourceId:      sourceID,
+			DestinationId: savingsID,
+		}},
+	}
+}
+
+// createSavingsPiggyBank runs the piggy bank step and records the outcome
+func (s *FireflyMCPServer) createSavingsPiggyBank(
+	ctx context.Context,
+	apiClient *client.ClientWithResponses,
+	step *SavingsGoalStep,
+) {
+	resp, err := apiClient.StorePiggyBankWithResponse(ctx, &client.StorePiggyBankParams{},
+		step.Request.(client.PiggyBankStore))
+	if err != nil {
+		step.Error = fmt.Sprintf("Error creating piggy bank: %v", err)
+		return
+	}
+	if !isSuccessStatus(resp.StatusCode()) || resp.ApplicationvndApiJSON200 == nil {
+		step.Error = fmt.Sprintf("API error %d: %s", resp.StatusCode(), s.upstreamError(resp.Body))
+		return
+	}
+	step.Applied = true
+	step.Id = resp.ApplicationvndApiJSON200.Data.Id
+}
+
+// createSavingsRecurrence runs the recurrence step and records the outcome
+func (s *FireflyMCPServer) createSavingsRecurrence(
+	ctx context.Context,
+	apiClient *client.ClientWithResponses,
+	step *SavingsGoalStep,
+) {
+	resp, err := apiClient.StoreRecurrenceWithResponse(ctx, &client.StoreRecurrenceParams{},
+		step.Request.(client.RecurrenceStore))
+	if err != nil {
+		step.Error = fmt.Sprintf("Error creating recurrence: %v", err)
+		return
+	}
+	if !isSuccessStatus(resp.StatusCode()) || resp.ApplicationvndApiJSON200 == nil {
+		step.Error = fmt.Sprintf("API error %d: %s", resp.StatusCode(), s.upstreamError(resp.Body))
+		return
+	}
+	step.Applied = true
+	step.Id = resp.ApplicationvndApiJSON200.Data.Id
+}
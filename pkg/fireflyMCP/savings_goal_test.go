@@ -0,0 +1,118 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSavingsContributions(t *testing.T) {
+	date := func(value string) time.Time {
+		parsed, err := time.Parse("2006-01-02", value)
+		require.NoError(t, err)
+		return parsed
+	}
+
+	first, count := savingsContributions(date("2024-03-10"), date("2024-06-30"), 1)
+	assert.Equal(t, "2024-04-01", first.Format("2006-01-02"))
+	assert.Equal(t, 3, count)
+
+	first, count = savingsContributions(date("2024-03-10"), date("2024-06-15"), 15)
+	assert.Equal(t, "2024-03-15", first.Format("2006-01-02"))
+	assert.Equal(t, 4, count, "the target date itself is included")
+
+	_, count = savingsContributions(date("2024-03-10"), date("2024-03-31"), 1)
+	assert.Zero(t, count)
+}
+
+func TestMonthlyContribution(t *testing.T) {
+	assert.Equal(t, "600.00", formatAmount(monthlyContribution(parseAmount("1800"), 3)))
+	assert.Equal(t, "33.34", formatAmount(monthlyContribution(parseAmount("100"), 3)), "rounded up to reach the target")
+	assert.Equal(t, "16.67", formatAmount(monthlyContribution(parseAmount("100"), 6)))
+}
+
+func TestPlanSavingsGoal(t *testing.T) {
+	var mu sync.Mutex
+	bodies := map[string]map[string]any{}
+	piggyStatus := http.StatusOK
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		body, _ := io.ReadAll(r.Body)
+		var decoded map[string]any
+		json.Unmarshal(body, &decoded)
+		mu.Lock()
+		bodies[r.URL.Path] = decoded
+		mu.Unlock()
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/piggy-banks":
+			w.WriteHeader(piggyStatus)
+			w.Write([]byte(`{"data":{"type":"piggy_banks","id":"17","attributes":{"name":"Holiday"}}}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/recurrences":
+			w.Write([]byte(`{"data":{"type":"recurrences","id":"8","attributes":{"title":"Holiday"}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	today := server.today()
+	first := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, 0)
+	targetDate := first.AddDate(0, 5, 0).Format("2006-01-02")
+	call := func(arguments string) SavingsGoalPlan {
+		result, err := server.tools["plan_savings_goal"].invoke(context.Background(), nil, []byte(arguments))
+		require.NoError(t, err)
+		require.False(t, result.IsError, result.Content[0].(*mcp.TextContent).Text)
+		var plan SavingsGoalPlan
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &plan))
+		return plan
+	}
+	arguments := `{"name":"Holiday","target_amount":"1000","current_amount":"100","target_date":"` + targetDate + `",` +
+		`"savings_account_id":"3","source_account_id":"1","create_piggy_bank":true,"create_recurrence":true`
+
+	plan := call(arguments + `}`)
+	assert.Equal(t, "900.00", plan.LeftToSave)
+	assert.Equal(t, 6, plan.Contributions)
+	assert.Equal(t, "150.00", plan.MonthlyContribution)
+	assert.Equal(t, first.Format("2006-01-02"), plan.FirstContribution)
+	assert.Equal(t, targetDate, plan.LastContribution)
+	require.Len(t, plan.Steps, 2)
+	assert.False(t, plan.Steps[0].Applied)
+	assert.Empty(t, bodies, "nothing is created without apply")
+
+	plan = call(arguments + `,"apply":true}`)
+	require.Len(t, plan.Steps, 2)
+	assert.True(t, plan.Steps[0].Applied)
+	assert.Equal(t, "17", plan.Steps[0].Id)
+	assert.True(t, plan.Steps[1].Applied)
+	assert.Equal(t, "8", plan.Steps[1].Id)
+	assert.Equal(t, "1000.00", bodies["/v1/piggy-banks"]["target_amount"])
+	recurrence := bodies["/v1/recurrences"]
+	assert.Equal(t, "transfer", recurrence["type"])
+	assert.EqualValues(t, 6, recurrence["nr_of_repetitions"])
+	transaction := recurrence["transactions"].([]any)[0].(map[string]any)
+	assert.Equal(t, "150.00", transaction["amount"])
+	assert.Equal(t, "17", transaction["piggy_bank_id"], "the transfers are linked to the new piggy bank")
+
+	// A failed piggy bank skips the recurrence linked to it
+	delete(bodies, "/v1/recurrences")
+	piggyStatus = http.StatusUnprocessableEntity
+	plan = call(arguments + `,"apply":true}`)
+	assert.NotEmpty(t, plan.Steps[0].Error)
+	assert.False(t, plan.Steps[1].Applied)
+	assert.NotEmpty(t, plan.Steps[1].Skipped)
+	assert.NotContains(t, bodies, "/v1/recurrences")
+}
@@ -0,0 +1,126 @@
+package fireflyMCP
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// schemaVersion is the version of the result shapes of all tools, attached to
+// every tool result as _meta.schema_version. Raise it and add an entry to
+// schemaChangelog whenever a DTO field is renamed, removed or changes meaning;
+// new optional fields and new tools are listed too, so agents can pick them up.
+const schemaVersion = 1
+
+// schemaChangelog lists the changes of every schema version, oldest first
+var schemaChangelog = []SchemaVersionChanges{
+	{
+		Version: 1,
+		Changes: []SchemaChange{
+			{Change: "Results carry _meta.schema_version; results without it predate schema versioning"},
+			{
+				Tools:  []string{"list_budget_limits"},
+				Change: "Limits have remaining, days_remaining and daily_allowance, the list has as_of",
+			},
+			{Change: "With formatting.amount_direction, amounts of read-only tools are absolute with a direction in or out"},
+			{
+				Tools: []string{"list_transactions", "get_transaction", "search_transactions", "list_budget_transactions",
+					"list_bill_transactions", "list_recurrence_transactions"},
+				Change: "Groups have created_at and updated_at; splits have import and recurrence metadata when set",
+			},
+			{Change: "With formatting.web_links (on by default), returned entities have web_url, their page in the Firefly III web UI"},
+			{
+				Tools: []string{"liability_schedule", "plan_savings_goal", "firefly_api_request", "get_schema_changelog",
+					"seed_budget_limits", "grouped_balances", "cleanup_advisor", "summarize_transactions",
+					"find_currency_mismatches", "tag_transactions", "trip_report"},
+				Change: "New tools",
+			},
+		},
+	},
+}
+
+// SchemaChange is one change of a schema version
+type SchemaChange struct {
+	Tools  []string `json:"tools,omitempty"` // Tools whose results changed, empty for all tools
+	Change string   `json:"change"`
+}
+
+// SchemaVersionChanges lists the changes a schema version made
+type SchemaVersionChanges struct {
+	Version int            `json:"version"`
+	Changes []SchemaChange `json:"changes"`
+}
+
+// GetSchemaChangelogArgs represents the arguments for the get_schema_changelog tool
+type GetSchemaChangelogArgs struct {
+	Since int    `json:"since,omitempty" jsonschema:"Schema version the client knows; only later versions are listed (default: all versions)"`
+	Tool  string `json:"tool,omitempty" jsonschema:"Only list changes affecting this tool"`
+}
+
+// SchemaChangelog is the result of get_schema_changelog
+type SchemaChangelog struct {
+	CurrentVersion int                    `json:"current_version"`
+	Since          int                    `json:"since,omitempty"`
+	Versions       []SchemaVersionChanges `json:"versions"`
+	ChangedTools   []string               `json:"changed_tools,omitempty"` // Tools whose results changed after since
+}
+
+// withSchemaVersion attaches the schema version to every tool result
+func withSchemaVersion[In any](handler mcp.ToolHandlerFor[In, any]) mcp.ToolHandlerFor[In, any] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, args In) (*mcp.CallToolResult, any, error) {
+		result, out, err := handler(ctx, req, args)
+		if result == nil {
+			return result, out, err
+		}
+		if result.Meta == nil {
+			result.Meta = mcp.Meta{}
+		}
+		result.Meta["schema_version"] = schemaVersion
+		return result, out, err
+	}
+}
+
+// handleGetSchemaChangelog lists the schema changes after a version
+func (s *FireflyMCPServer) handleGetSchemaChangelog(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args GetSchemaChangelogArgs,
+) (*mcp.CallToolResult, any, error) {
+	if args.Since < 0 || args.Since > schemaVersion {
+		return newErrorResult(fmt.Sprintf("Error: since must be between 0 and the current version %d", schemaVersion))
+	}
+	return newSuccessResult(buildSchemaChangelog(args.Since, args.Tool))
+}
+
+// buildSchemaChangelog collects the changes after a version, optionally only
+// those affecting a tool (changes to all tools are always included)
+func buildSchemaChangelog(since int, tool string) *SchemaChangelog {
+	changelog := &SchemaChangelog{CurrentVersion: schemaVersion, Since: since, Versions: []SchemaVersionChanges{}}
+	changed := map[string]bool{}
+	for _, version := range schemaChangelog {
+		if version.Version <= since {
+			continue
+		}
+		entry := SchemaVersionChanges{Version: version.Version, Changes: []SchemaChange{}}
+		for _, change := range version.Changes {
+			if tool != "" && len(change.Tools) > 0 && !slices.Contains(change.Tools, tool) {
+				continue
+			}
+			entry.Changes = append(entry.Changes, change)
+			for _, name := range change.Tools {
+				if !changed[name] {
+					changed[name] = true
+					changelog.ChangedTools = append(changelog.ChangedTools, name)
+				}
+			}
+		}
+		if len(entry.Changes) > 0 {
+			changelog.Versions = append(changelog.Versions, entry)
+		}
+	}
+	sort.Strings(changelog.ChangedTools)
+	return changelog
+}
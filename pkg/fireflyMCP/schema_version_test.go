@@ -0,0 +1,75 @@
+package fireflyMCP
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaChangelog_Consistent(t *testing.T) {
+	withPlugins(t)
+	config := newPluginTestConfig()
+	config.Trash.Enabled = true
+	config.Demo.Enabled = true
+	config.Proxy.Enabled = true
+	config.Sampling.Enabled = true
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	require.NotEmpty(t, schemaChangelog)
+	assert.Equal(t, schemaVersion, schemaChangelog[len(schemaChangelog)-1].Version, "the last entry is the current version")
+	for i, version := range schemaChangelog {
+		assert.Equal(t, i+1, version.Version, "versions are consecutive")
+		assert.NotEmpty(t, version.Changes)
+		for _, change := range version.Changes {
+			for _, name := range change.Tools {
+				assert.True(t, server.hasTool(name), "changelog names unknown tool %s", name)
+			}
+		}
+	}
+}
+
+func TestBuildSchemaChangelog(t *testing.T) {
+	changelog := buildSchemaChangelog(0, "")
+	assert.Equal(t, schemaVersion, changelog.CurrentVersion)
+	assert.Len(t, changelog.Versions, len(schemaChangelog))
+	assert.Contains(t, changelog.ChangedTools, "list_budget_limits")
+
+	changelog = buildSchemaChangelog(0, "list_budget_limits")
+	for _, version := range changelog.Versions {
+		for _, change := range version.Changes {
+			if len(change.Tools) > 0 {
+				assert.Contains(t, change.Tools, "list_budget_limits")
+			}
+		}
+	}
+	assert.Equal(t, []string{"list_budget_limits"}, changelog.ChangedTools)
+
+	changelog = buildSchemaChangelog(schemaVersion, "")
+	assert.Empty(t, changelog.Versions, "nothing changed after the current version")
+	assert.Empty(t, changelog.ChangedTools)
+}
+
+func TestSchemaVersion_InResults(t *testing.T) {
+	_, session := newCapabilityTestServer(t)
+
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "get_schema_changelog",
+		Arguments: map[string]any{"since": 0},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.EqualValues(t, schemaVersion, result.Meta["schema_version"])
+
+	// Errors carry the version too
+	result, err = session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "get_schema_changelog",
+		Arguments: map[string]any{"since": schemaVersion + 1},
+	})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.EqualValues(t, schemaVersion, result.Meta["schema_version"])
+}
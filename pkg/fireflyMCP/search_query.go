@@ -0,0 +1,296 @@
+package fireflyMCP
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dezer32/mcp-firefly-iii/pkg/client"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// searchOperators lists the Firefly III transaction search operators
+var searchOperators = buildSearchOperators()
+
+// searchOperatorAliases maps short operator names to the operator Firefly III uses for them
+var searchOperatorAliases = map[string]string{
+	"amount":      "amount_is",
+	"less":        "amount_less",
+	"more":        "amount_more",
+	"on":          "date_on",
+	"before":      "date_before",
+	"after":       "date_after",
+	"tag":         "tag_is",
+	"category":    "category_is",
+	"budget":      "budget_is",
+	"bill":        "bill_is",
+	"currency":    "currency_is",
+	"from":        "source_account_is",
+	"to":          "destination_account_is",
+	"source":      "source_account_is",
+	"destination": "destination_account_is",
+}
+
+// searchOperatorPattern matches the operator part of a search token such as -tag_is:
+var searchOperatorPattern = regexp.MustCompile(`^(-?)([a-z_]+):(.*)$`)
+
+func buildSearchOperators() map[string]bool {
+	operators := map[string]bool{}
+	for _, field := range []string{
+		"description", "notes", "category", "budget", "bill", "tag", "external_id", "internal_reference",
+		"external_url", "attachment_name", "attachment_notes", "account", "source_account",
+		"destination_account", "account_nr", "source_account_nr", "destination_account_nr",
+	} {
+		for _, suffix := range []string{"_is", "_contains", "_starts", "_ends"} {
+			operators[field+suffix] = true
+		}
+	}
+	for _, field := range []string{
+		"date", "interest_date", "book_date", "process_date", "due_date", "payment_date", "invoice_date",
+		"created_at", "updated_at",
+	} {
+		for _, suffix := range []string{"_on", "_before", "_after"} {
+			operators[field+suffix] = true
+		}
+	}
+	for _, field := range []string{"amount", "foreign_amount"} {
+		for _, suffix := range []string{"_is", "_less", "_more"} {
+			operators[field+suffix] = true
+		}
+	}
+	for _, operator := range []string{
+		"id", "journal_id", "recurrence_id", "account_id", "source_account_id", "destination_account_id",
+		"type", "currency_is", "foreign_currency_is", "reconciled", "tag_is_not", "user_action", "exists",
+		"sepa_ct_is", "has_attachments", "has_no_attachments", "has_any_category", "has_no_category",
+		"has_any_budget", "has_no_budget", "has_any_bill", "has_no_bill", "has_any_tag", "has_no_tag",
+		"any_notes", "no_notes", "any_external_url", "no_external_url", "any_external_id", "no_external_id",
+		"source_is_cash", "destination_is_cash", "account_is_cash",
+	} {
+		operators[operator] = true
+	}
+	return operators
+}
+
+// searchFilterQuery compiles the filter arguments of search_transactions to
+// search operators, so callers need not know the operator syntax
+func searchFilterQuery(args SearchTransactionsArgs) string {
+	var parts []string
+	if args.Reconciled != nil {
+		parts = append(parts, fmt.Sprintf("reconciled:%t", *args.Reconciled))
+	}
+	if notes := strings.TrimSpace(args.NotesContains); notes != "" {
+		parts = append(parts, "notes_contains:"+quoteSearchValue(strings.ReplaceAll(notes, `"`, "")))
+	}
+	if args.HasAttachments != nil {
+		if *args.HasAttachments {
+			parts = append(parts, "has_attachments:true")
+		} else {
+			parts = append(parts, "has_no_attachments:true")
+		}
+	}
+	if args.HasNoCategory {
+		parts = append(parts, "has_no_category:true")
+	}
+	if args.HasNoBudget {
+		parts = append(parts, "has_no_budget:true")
+	}
+	return strings.Join(parts, " ")
+}
+
+// joinSearchQuery joins the non-empty parts of a search query. Whitespace is
+// trimmed around each part only, so quoted values keep their spacing.
+func joinSearchQuery(parts ...string) string {
+	kept := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			kept = append(kept, part)
+		}
+	}
+	return strings.Join(kept, " ")
+}
+
+// ValidateSearchQueryArgs represents the arguments for the validate_search_query tool
+type ValidateSearchQueryArgs struct {
+	Query    string `json:"query" jsonschema:"Firefly III search query to check (required)"`
+	Estimate *bool  `json:"estimate,omitempty" jsonschema:"Estimate the number of matches with a one-item search (default: true)"`
+}
+
+// SearchQueryOperator is an operator found in a search query
+type SearchQueryOperator struct {
+	Operator string `json:"operator"`
+	Value    string `json:"value"`
+	Negated  bool   `json:"negated,omitempty"`
+	Alias    string `json:"alias,omitempty"` // The alias used in the query, if any
+}
+
+// UnknownSearchOperator is an operator Firefly III does not know
+type UnknownSearchOperator struct {
+	Operator   string `json:"operator"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// SearchQueryValidation is the result of validate_search_query
+type SearchQueryValidation struct {
+	Query            string                  `json:"query"`
+	NormalizedQuery  string                  `json:"normalized_query"`
+	Valid            bool                    `json:"valid"`
+	Operators        []SearchQueryOperator   `json:"operators"`
+	FreeText         []string                `json:"free_text"`
+	UnknownOperators []UnknownSearchOperator `json:"unknown_operators"`
+	Warnings         []string                `json:"warnings,omitempty"`
+	EstimatedMatches *int                    `json:"estimated_matches,omitempty"`
+	EstimateError    string                  `json:"estimate_error,omitempty"`
+}
+
+func (s *FireflyMCPServer) handleValidateSearchQuery(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args ValidateSearchQueryArgs,
+) (*mcp.CallToolResult, any, error) {
+	if strings.TrimSpace(args.Query) == "" {
+		return newErrorResult("Error: query is required")
+	}
+
+	validation := parseSearchQuery(args.Query)
+	if !validation.Valid || validation.NormalizedQuery == "" || (args.Estimate != nil && !*args.Estimate) {
+		return newSuccessResult(validation)
+	}
+
+	apiClient, err := s.getClient(ctx, req)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Failed to get API client: %v", err))
+	}
+	one := int32(1)
+	resp, err := apiClient.SearchTransactionsWithResponse(ctx, &client.SearchTransactionsParams{
+		Query: validation.NormalizedQuery,
+		Limit: &one,
+	})
+	switch {
+	case err != nil:
+		validation.EstimateError = err.Error()
+	case resp.StatusCode() != 200:
+		validation.EstimateError = fmt.Sprintf("API error %d: %s", resp.StatusCode(), s.upstreamError(resp.Body))
+	case resp.ApplicationvndApiJSON200 != nil && resp.ApplicationvndApiJSON200.Meta.Pagination != nil:
+		total := getIntValue(resp.ApplicationvndApiJSON200.Meta.Pagination.Total)
+		validation.EstimatedMatches = &total
+	}
+	return newSuccessResult(validation)
+}
+
+// parseSearchQuery splits a search query into operators and free text, resolves
+// aliases and reports unknown operators
+func parseSearchQuery(query string) *SearchQueryValidation {
+	validation := &SearchQueryValidation{
+		Query:            query,
+		Operators:        []SearchQueryOperator{},
+		FreeText:         []string{},
+		UnknownOperators: []UnknownSearchOperator{},
+	}
+
+	var normalized []string
+	for _, token := range splitSearchQuery(query) {
+		match := searchOperatorPattern.FindStringSubmatch(token)
+		if match == nil {
+			validation.FreeText = append(validation.FreeText, token)
+			normalized = append(normalized, token)
+			continue
+		}
+
+		negation, name, value := match[1], match[2], strings.Trim(match[3], `"`)
+		operator := SearchQueryOperator{Operator: name, Value: value, Negated: negation != ""}
+		if canonical, ok := searchOperatorAliases[name]; ok {
+			operator.Operator, operator.Alias = canonical, name
+		} else if !searchOperators[name] {
+			validation.UnknownOperators = append(validation.UnknownOperators, UnknownSearchOperator{
+				Operator:   name,
+				Suggestion: closestSearchOperator(name),
+			})
+			continue
+		}
+		if value == "" {
+			validation.Warnings = append(validation.Warnings, fmt.Sprintf("%s has no value", operator.Operator))
+		}
+		validation.Operators = append(validation.Operators, operator)
+		normalized = append(normalized, negation+operator.Operator+":"+quoteSearchValue(value))
+	}
+
+	validation.Valid = len(validation.UnknownOperators) == 0
+	validation.NormalizedQuery = strings.Join(normalized, " ")
+	return validation
+}
+
+// splitSearchQuery splits a query on whitespace, keeping double-quoted values together
+func splitSearchQuery(query string) []string {
+	var (
+		tokens  []string
+		current strings.Builder
+		quoted  bool
+	)
+	for _, r := range query {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			current.WriteRune(r)
+		case (r == ' ' || r == '\t' || r == '\n') && !quoted:
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+	return tokens
+}
+
+// quoteSearchValue quotes a value containing whitespace
+func quoteSearchValue(value string) string {
+	if strings.ContainsAny(value, " \t") {
+		return `"` + value + `"`
+	}
+	return value
+}
+
+// closestSearchOperator returns the known operator closest to an unknown one, or
+// "" if none is reasonably close
+func closestSearchOperator(name string) string {
+	candidates := make([]string, 0, len(searchOperators))
+	for operator := range searchOperators {
+		candidates = append(candidates, operator)
+	}
+	sort.Strings(candidates)
+
+	best, bestDistance := "", len(name)/2+1
+	for _, candidate := range candidates {
+		if distance := editDistance(name, candidate); distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
@@ -0,0 +1,94 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	validation := parseSearchQuery(`groceries  tag:card -category_is:Food description_contains:"coffee shop"`)
+	assert.True(t, validation.Valid)
+	assert.Equal(t, `groceries tag_is:card -category_is:Food description_contains:"coffee shop"`, validation.NormalizedQuery)
+	assert.Equal(t, []string{"groceries"}, validation.FreeText)
+	assert.Equal(t, []SearchQueryOperator{
+		{Operator: "tag_is", Value: "card", Alias: "tag"},
+		{Operator: "category_is", Value: "Food", Negated: true},
+		{Operator: "description_contains", Value: "coffee shop"},
+	}, validation.Operators)
+	assert.Empty(t, validation.UnknownOperators)
+
+	validation = parseSearchQuery("amount_mor:50 catgory_is:Food frobnicate:1 date_after:")
+	assert.False(t, validation.Valid)
+	assert.Equal(t, []UnknownSearchOperator{
+		{Operator: "amount_mor", Suggestion: "amount_more"},
+		{Operator: "catgory_is", Suggestion: "category_is"},
+		{Operator: "frobnicate"},
+	}, validation.UnknownOperators)
+	assert.Equal(t, "date_after:", validation.NormalizedQuery)
+	assert.Equal(t, []string{"date_after has no value"}, validation.Warnings)
+}
+
+func TestSearchFilterQuery(t *testing.T) {
+	assert.Equal(t, "", searchFilterQuery(SearchTransactionsArgs{Query: "groceries"}))
+
+	query := searchFilterQuery(SearchTransactionsArgs{
+		NotesContains:  `receipt "missing"`,
+		HasAttachments: ptr(false),
+		HasNoCategory:  true,
+		HasNoBudget:    true,
+	})
+	assert.Equal(t, `notes_contains:"receipt missing" has_no_attachments:true has_no_category:true has_no_budget:true`, query)
+	assert.True(t, parseSearchQuery(query).Valid)
+
+	assert.Equal(t, "reconciled:true has_attachments:true",
+		searchFilterQuery(SearchTransactionsArgs{Reconciled: ptr(true), HasAttachments: ptr(true)}))
+}
+
+func TestJoinSearchQuery(t *testing.T) {
+	assert.Equal(t, `description_contains:"two  spaces" has_no_budget:true`,
+		joinSearchQuery(` description_contains:"two  spaces" `, "", "has_no_budget:true"))
+	assert.Equal(t, "", joinSearchQuery("", " "))
+}
+
+func TestValidateSearchQuery_Estimate(t *testing.T) {
+	var queries []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("query")+" limit="+r.URL.Query().Get("limit"))
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		w.Write([]byte(`{"data":[],"meta":{"pagination":{"total":42,"count":1,"per_page":1,"current_page":1,"total_pages":42}}}`))
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	validate := func(args ValidateSearchQueryArgs) SearchQueryValidation {
+		result, _, err := server.handleValidateSearchQuery(context.Background(), nil, args)
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+		var validation SearchQueryValidation
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &validation))
+		return validation
+	}
+
+	validation := validate(ValidateSearchQueryArgs{Query: "on:2024-03-01 tag:card"})
+	require.NotNil(t, validation.EstimatedMatches)
+	assert.Equal(t, 42, *validation.EstimatedMatches)
+	assert.Equal(t, []string{"date_on:2024-03-01 tag_is:card limit=1"}, queries)
+
+	// No probe for invalid queries or when disabled
+	validation = validate(ValidateSearchQueryArgs{Query: "tags_is:card"})
+	assert.Nil(t, validation.EstimatedMatches)
+	validation = validate(ValidateSearchQueryArgs{Query: "tag:card", Estimate: ptr(false)})
+	assert.Nil(t, validation.EstimatedMatches)
+	assert.Len(t, queries, 1)
+}
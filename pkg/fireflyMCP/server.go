@@ -1,24 +1,52 @@
 package fireflyMCP
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/dezer32/mcp-firefly-iii/pkg/client"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
-	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
 // FireflyMCPServer represents the MCP server for Firefly III
 type FireflyMCPServer struct {
 	server     *mcp.Server
 	client     *client.ClientWithResponses // Used for stdio mode (static token from config)
-	config     *Config
-	httpClient *http.Client // Shared HTTP client for creating per-request API clients
+	config     *Config                     // Replaced by ReloadConfig; read through currentConfig
+	configMu   sync.RWMutex                // Guards config and accountAliases
+	httpClient *http.Client                // Shared HTTP client for creating per-request API clients
+
+	accountAliases       map[string]string          // Normalized alias -> account ID (from accounts.aliases)
+	hiddenAccounts       *hiddenAccounts            // Accounts filtered out of Firefly III responses (accounts.hidden)
+	location             *time.Location             // Timezone used to resolve relative dates such as "today"
+	clock                *serverClock               // Clock skew observed from Firefly III responses
+	tools                map[string]*registeredTool // All registered tools (built-in, plugin and report) by name
+	imports              *importTracker             // Progress of asynchronous bulk imports
+	snapshots            *snapshotStore             // Transaction snapshots taken by diff_periods
+	formatting           *formattingCache           // Formatting hints per API token
+	accountMetadataCache *accountMetadataCache      // Account currencies per tenant, used to fill in split currencies
+	toolCache            *toolResultCache           // Results of tools with tools.<name>.cache_ttl
+	trash                *trashStore                // Objects moved to the trash by delete tools
+	changes              *changeLog                 // Writes made to transactions, for get_change_history
+	journal              *writeJournal              // Write-ahead journal of bulk stores, nil without journal.path or durable storage
+	storage              Storage                    // Backend of the trash, change histories and journal
+	replay               *replayRecorder            // Records client tool calls, nil without replay.record
+	sessionStats         *sessionStatsTracker       // Tool call statistics per MCP session
+	readOnly             atomic.Bool                // Set through the admin API to reject tools that are not read-only
+	capabilities         *capabilityTracker         // Optional Firefly III APIs found missing
+	catalogs             *nameCatalog               // IDs and names of accounts, budgets and categories per tenant
+	started              time.Time                  // When the server was created, for the uptime in Status
 }
 
 // Tool argument types
@@ -29,15 +57,16 @@ type ListAccountsArgs struct {
 }
 
 type GetAccountArgs struct {
-	ID string `json:"id" jsonschema:"Account ID"`
+	ID string `json:"id" jsonschema:"Account ID or configured account alias"`
 }
 
 type ListTransactionsArgs struct {
-	Type  string `json:"type,omitempty" jsonschema:"Filter by transaction type"`
-	Start string `json:"start,omitempty" jsonschema:"Start date (YYYY-MM-DD)"`
-	End   string `json:"end,omitempty" jsonschema:"End date (YYYY-MM-DD)"`
-	Limit int    `json:"limit,omitempty" jsonschema:"Maximum number of transactions to return"`
-	Page  int    `json:"page,omitempty" jsonschema:"Page number for pagination (default: 1)"`
+	Type       string `json:"type,omitempty" jsonschema:"Filter by transaction type"`
+	Limit      int    `json:"limit,omitempty" jsonschema:"Maximum number of transactions to return"`
+	Page       int    `json:"page,omitempty" jsonschema:"Page number for pagination (default: 1)"`
+	Reconciled *bool  `json:"reconciled,omitempty" jsonschema:"Only return reconciled (true) or unreconciled (false) transactions"`
+	Compact    bool   `json:"compact,omitempty" jsonschema:"Return compact transactions: single-split groups flattened and empty fields left out"`
+	DateRange
 }
 
 type GetTransactionArgs struct {
@@ -45,10 +74,9 @@ type GetTransactionArgs struct {
 }
 
 type ListBudgetsArgs struct {
-	Start string `json:"start,omitempty" jsonschema:"Start date (YYYY-MM-DD)"`
-	End   string `json:"end,omitempty" jsonschema:"End date (YYYY-MM-DD)"`
-	Limit int    `json:"limit,omitempty" jsonschema:"Maximum number of budgets to return"`
-	Page  int    `json:"page,omitempty" jsonschema:"Page number for pagination (default: 1)"`
+	Limit int `json:"limit,omitempty" jsonschema:"Maximum number of budgets to return"`
+	Page  int `json:"page,omitempty" jsonschema:"Page number for pagination (default: 1)"`
+	DateRange
 }
 
 type ListCategoriesArgs struct {
@@ -57,8 +85,8 @@ type ListCategoriesArgs struct {
 }
 
 type GetSummaryArgs struct {
-	Start string `json:"start,omitempty" jsonschema:"Start date (YYYY-MM-DD)"`
-	End   string `json:"end,omitempty" jsonschema:"End date (YYYY-MM-DD)"`
+	DateRange
+	WithTrend bool `json:"with_trend,omitempty" jsonschema:"Also return the net change (income minus expenses) of every day of the range, for ranges of up to 92 days"`
 }
 
 type SearchAccountsArgs struct {
@@ -69,38 +97,40 @@ type SearchAccountsArgs struct {
 }
 
 type SearchTransactionsArgs struct {
-	Query string `json:"query" jsonschema:"The search query"`
-	Limit int32  `json:"limit,omitempty" jsonschema:"Maximum number of transactions to return"`
-	Page  int32  `json:"page,omitempty" jsonschema:"Page number for pagination (default: 1)"`
-	Start string `json:"start,omitempty" jsonschema:"Start date (YYYY-MM-DD)"`
-	End   string `json:"end,omitempty" jsonschema:"End date (YYYY-MM-DD)"`
+	Query          string `json:"query" jsonschema:"The search query"`
+	Limit          int32  `json:"limit,omitempty" jsonschema:"Maximum number of transactions to return"`
+	Page           int32  `json:"page,omitempty" jsonschema:"Page number for pagination (default: 1)"`
+	Reconciled     *bool  `json:"reconciled,omitempty" jsonschema:"Only return reconciled (true) or unreconciled (false) transactions"`
+	NotesContains  string `json:"notes_contains,omitempty" jsonschema:"Only return transactions whose notes contain this text"`
+	HasAttachments *bool  `json:"has_attachments,omitempty" jsonschema:"Only return transactions with (true) or without (false) attachments"`
+	HasNoCategory  bool   `json:"has_no_category,omitempty" jsonschema:"Only return transactions without a category"`
+	HasNoBudget    bool   `json:"has_no_budget,omitempty" jsonschema:"Only return transactions without a budget"`
+	Compact        bool   `json:"compact,omitempty" jsonschema:"Return compact transactions: single-split groups flattened and empty fields left out"`
+	DateRange
 }
 
 type ExpenseCategoryInsightsArgs struct {
-	Start    string   `json:"start" jsonschema:"Start date (YYYY-MM-DD)"`
-	End      string   `json:"end" jsonschema:"End date (YYYY-MM-DD)"`
-	Accounts []string `json:"accounts,omitempty" jsonschema:"Account IDs to include in results"`
+	Accounts []string `json:"accounts,omitempty" jsonschema:"Account IDs (or configured account aliases) to include in results"`
+	DateRange
 }
 
 type ExpenseTotalInsightsArgs struct {
-	Start    string   `json:"start" jsonschema:"Start date (YYYY-MM-DD)"`
-	End      string   `json:"end" jsonschema:"End date (YYYY-MM-DD)"`
-	Accounts []string `json:"accounts,omitempty" jsonschema:"Account IDs to include in results"`
+	Accounts []string `json:"accounts,omitempty" jsonschema:"Account IDs (or configured account aliases) to include in results"`
+	DateRange
 }
 
 type ListBudgetLimitsArgs struct {
-	ID    string `json:"id" jsonschema:"Budget ID"`
-	Start string `json:"start,omitempty" jsonschema:"Start date (YYYY-MM-DD)"`
-	End   string `json:"end,omitempty" jsonschema:"End date (YYYY-MM-DD)"`
+	ID string `json:"id" jsonschema:"Budget ID"`
+	DateRange
 }
 
 type ListBudgetTransactionsArgs struct {
-	ID    string `json:"id" jsonschema:"Budget ID"`
-	Type  string `json:"type,omitempty" jsonschema:"Filter by transaction type"`
-	Start string `json:"start,omitempty" jsonschema:"Start date (YYYY-MM-DD)"`
-	End   string `json:"end,omitempty" jsonschema:"End date (YYYY-MM-DD)"`
-	Limit int    `json:"limit,omitempty" jsonschema:"Maximum number of transactions to return"`
-	Page  int    `json:"page,omitempty" jsonschema:"Page number for pagination (default: 1)"`
+	ID      string `json:"id" jsonschema:"Budget ID"`
+	Type    string `json:"type,omitempty" jsonschema:"Filter by transaction type"`
+	Limit   int    `json:"limit,omitempty" jsonschema:"Maximum number of transactions to return"`
+	Page    int    `json:"page,omitempty" jsonschema:"Page number for pagination (default: 1)"`
+	Compact bool   `json:"compact,omitempty" jsonschema:"Return compact transactions: single-split groups flattened and empty fields left out"`
+	DateRange
 }
 
 type ListTagsArgs struct {
@@ -109,25 +139,23 @@ type ListTagsArgs struct {
 }
 
 type ListBillsArgs struct {
-	Start string `json:"start,omitempty" jsonschema:"Start date (YYYY-MM-DD)"`
-	End   string `json:"end,omitempty" jsonschema:"End date (YYYY-MM-DD)"`
-	Limit int    `json:"limit,omitempty" jsonschema:"Maximum number of bills to return"`
-	Page  int    `json:"page,omitempty" jsonschema:"Page number for pagination (default: 1)"`
+	Limit int `json:"limit,omitempty" jsonschema:"Maximum number of bills to return"`
+	Page  int `json:"page,omitempty" jsonschema:"Page number for pagination (default: 1)"`
+	DateRange
 }
 
 type GetBillArgs struct {
-	ID    string `json:"id" jsonschema:"Bill ID"`
-	Start string `json:"start,omitempty" jsonschema:"Start date (YYYY-MM-DD) for payment info"`
-	End   string `json:"end,omitempty" jsonschema:"End date (YYYY-MM-DD) for payment info"`
+	ID string `json:"id" jsonschema:"Bill ID"`
+	DateRange
 }
 
 type ListBillTransactionsArgs struct {
-	ID    string `json:"id" jsonschema:"Bill ID"`
-	Type  string `json:"type,omitempty" jsonschema:"Filter by transaction type"`
-	Start string `json:"start,omitempty" jsonschema:"Start date (YYYY-MM-DD)"`
-	End   string `json:"end,omitempty" jsonschema:"End date (YYYY-MM-DD)"`
-	Limit int    `json:"limit,omitempty" jsonschema:"Maximum number of transactions to return"`
-	Page  int    `json:"page,omitempty" jsonschema:"Page number for pagination (default: 1)"`
+	ID      string `json:"id" jsonschema:"Bill ID"`
+	Type    string `json:"type,omitempty" jsonschema:"Filter by transaction type"`
+	Limit   int    `json:"limit,omitempty" jsonschema:"Maximum number of transactions to return"`
+	Page    int    `json:"page,omitempty" jsonschema:"Page number for pagination (default: 1)"`
+	Compact bool   `json:"compact,omitempty" jsonschema:"Return compact transactions: single-split groups flattened and empty fields left out"`
+	DateRange
 }
 
 type StoreTransactionArgs struct {
@@ -137,12 +165,40 @@ type StoreTransactionArgs struct {
 type UpdateTransactionArgs struct {
 	ID string `json:"id" jsonschema:"Transaction group ID (required)"`
 	TransactionUpdateRequest
+	Patch []TransactionSplitPatch `json:"patch,omitempty" jsonschema:"Patch mode instead of transactions: only the changed fields of specific splits; all other fields and splits are kept"`
 }
 
 func NewFireflyMCPServer(config *Config) (*FireflyMCPServer, error) {
-	// Create shared HTTP client
+	location, err := loadDateLocation(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dates.timezone: %w", err)
+	}
+
+	// Create shared HTTP client, retrying reads after transient network errors,
+	// recording the server time of every response, optionally serializing writes
+	// and pausing requests while Firefly III is in maintenance mode. Optional
+	// APIs answering 404 are recorded, so their tools are marked unavailable.
+	// Accounts configured as hidden are removed from every response.
+	clock := &serverClock{maxSkew: time.Duration(config.Dates.MaxSkewHours) * time.Hour}
+	capabilities := newCapabilityTracker()
+	var transport http.RoundTripper = &retryTransport{
+		base:     http.DefaultTransport,
+		attempts: config.Client.RetryAttempts,
+		backoff:  time.Duration(config.Client.RetryBackoff) * time.Millisecond,
+	}
+	transport = &clockSkewTransport{base: transport, clock: clock}
+	transport = &capabilityTransport{base: transport, tracker: capabilities}
+	hidden := newHiddenAccounts(config)
+	transport = &hiddenAccountsTransport{base: transport, hidden: hidden}
+	if config.Client.SerializeWrites {
+		transport = newWriteQueueTransport(transport, time.Duration(config.Client.WriteInterval)*time.Millisecond)
+	}
 	httpClient := &http.Client{
 		Timeout: config.GetTimeout(),
+		Transport: &maintenanceTransport{
+			base:  transport,
+			guard: &maintenanceGuard{cooldown: time.Duration(config.Client.MaintenanceCooldown) * time.Second},
+		},
 	}
 
 	// Create MCP server
@@ -150,29 +206,76 @@ func NewFireflyMCPServer(config *Config) (*FireflyMCPServer, error) {
 		&mcp.Implementation{
 			Name:    config.MCP.Name,
 			Version: config.MCP.Version,
-		}, nil,
+		}, &mcp.ServerOptions{
+			// Subscriptions are tracked by the SDK; they are used for import progress updates
+			SubscribeHandler:   func(context.Context, *mcp.SubscribeRequest) error { return nil },
+			UnsubscribeHandler: func(context.Context, *mcp.UnsubscribeRequest) error { return nil },
+		},
 	)
 
+	storage, err := OpenStorage(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open storage: %w", err)
+	}
+
 	server := &FireflyMCPServer{
-		server:     mcpServer,
-		config:     config,
-		httpClient: httpClient,
+		server:               mcpServer,
+		config:               config,
+		httpClient:           httpClient,
+		accountAliases:       normalizeAccountAliases(config.Accounts.Aliases),
+		hiddenAccounts:       hidden,
+		location:             location,
+		clock:                clock,
+		imports:              newImportTracker(),
+		snapshots:            newSnapshotStore(),
+		sessionStats:         newSessionStatsTracker(),
+		formatting:           newFormattingCache(),
+		accountMetadataCache: newAccountMetadataCache(),
+		toolCache:            newToolResultCache(sharedStorage(storage)),
+		trash:                newTrashStore(storage),
+		changes:              newChangeLog(storage),
+		storage:              storage,
+		capabilities:         capabilities,
+		catalogs:             newNameCatalog(),
+		started:              time.Now(),
+	}
+	capabilities.onMissing = server.markToolsUnavailable
+
+	// Release the storage, journal and replay file if construction fails below,
+	// so their file locks and connections do not outlive the failed server
+	constructed := false
+	defer func() {
+		if !constructed {
+			server.Close()
+		}
+	}()
+
+	// A journal file takes precedence; durable storage keeps the journal otherwise.
+	// Shared storage does not, as every replica would replay the batches of others.
+	switch {
+	case config.Journal.Path != "":
+		server.journal, err = openWriteJournal(config.Journal.Path)
+	case isDurable(storage) && !isShared(storage):
+		server.journal, err = openStorageJournal(storage)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	if config.Replay.Record != "" {
+		if server.replay, err = openReplayRecorder(config.Replay.Record); err != nil {
+			return nil, fmt.Errorf("failed to open replay file: %w", err)
+		}
+	}
+	if server.journal != nil {
+		if interrupted := len(server.journal.interrupted); interrupted > 0 {
+			slog.Warn("Bulk stores were interrupted by a restart, see list_interrupted_batches",
+				"batches", interrupted, "path", config.Journal.Path, "storage", config.Storage.Backend)
+		}
 	}
 
 	// For stdio mode, create a static client with token from config
 	if !config.HTTP.Enabled && config.API.Token != "" {
-		fireflyClient, err := client.NewClientWithResponses(
-			config.Server.URL,
-			client.WithHTTPClient(httpClient),
-			client.WithRequestEditorFn(
-				func(ctx context.Context, req *http.Request) error {
-					req.Header.Set("Authorization", "Bearer "+config.API.Token)
-					req.Header.Set("Accept", "application/vnd.api+json")
-					req.Header.Set("Content-Type", "application/json")
-					return nil
-				},
-			),
-		)
+		fireflyClient, err := newAPIClient(config.Server.URL, httpClient, config.API.Token, config.Client.AcceptLanguage)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create Firefly III client: %w", err)
 		}
@@ -181,7 +284,19 @@ func NewFireflyMCPServer(config *Config) (*FireflyMCPServer, error) {
 
 	// Register tools
 	server.registerTools()
+	server.registerImportResources()
+	server.registerSnapshotResources()
+	if err := server.registerPlugins(); err != nil {
+		return nil, err
+	}
+	if err := server.registerReports(); err != nil {
+		return nil, err
+	}
+	if err := server.checkToolOverrides(); err != nil {
+		return nil, err
+	}
 
+	constructed = true
 	return server, nil
 }
 
@@ -198,22 +313,35 @@ func (s *FireflyMCPServer) getClient(ctx context.Context, req mcp.Request) (*cli
 	token := extractTokenFromRequest(req)
 
 	// Fallback to config token (from env or yaml) if no header provided
+	config := s.currentConfig()
 	if token == "" {
-		token = s.config.API.Token
+		token = config.API.Token
 	}
 
 	if token == "" {
 		return nil, fmt.Errorf("no API token found: provide Authorization header or set FIREFLY_MCP_API_TOKEN")
 	}
 
+	return newAPIClient(config.Server.URL, s.httpClient, token, config.Client.AcceptLanguage)
+}
+
+// newAPIClient creates a Firefly III API client that authenticates with the given token.
+// An empty token creates an unauthenticated client (used for connectivity checks).
+// A non-empty language is sent as Accept-Language, so Firefly III localizes its messages.
+func newAPIClient(serverURL string, httpClient *http.Client, token, language string) (*client.ClientWithResponses, error) {
 	return client.NewClientWithResponses(
-		s.config.Server.URL,
-		client.WithHTTPClient(s.httpClient),
+		serverURL,
+		client.WithHTTPClient(maintenanceErrorDoer{client: httpClient}),
 		client.WithRequestEditorFn(
 			func(ctx context.Context, httpReq *http.Request) error {
-				httpReq.Header.Set("Authorization", "Bearer "+token)
+				if token != "" {
+					httpReq.Header.Set("Authorization", "Bearer "+token)
+				}
 				httpReq.Header.Set("Accept", "application/vnd.api+json")
 				httpReq.Header.Set("Content-Type", "application/json")
+				if language != "" {
+					httpReq.Header.Set("Accept-Language", language)
+				}
 				return nil
 			},
 		),
@@ -245,11 +373,29 @@ func extractTokenFromRequest(req mcp.Request) string {
 	return strings.TrimSpace(auth[7:])
 }
 
+// requestTokenKey returns the key under which per-user state of a tool call is
+// kept: the request token in HTTP mode, "" for the configured token
+func requestTokenKey(req *mcp.CallToolRequest) string {
+	if req == nil {
+		return ""
+	}
+	return extractTokenFromRequest(req)
+}
+
 // Run starts the MCP server with the given transport
 func (s *FireflyMCPServer) Run(ctx context.Context, transport mcp.Transport) error {
 	return s.server.Run(ctx, transport)
 }
 
+// Close closes the write journal, the replay file and the storage backend
+func (s *FireflyMCPServer) Close() error {
+	err := errors.Join(s.journal.close(), s.replay.close())
+	if s.storage != nil {
+		err = errors.Join(err, s.storage.Close())
+	}
+	return err
+}
+
 // MCPServer returns the underlying MCP server instance.
 // This is needed for HTTP transport which requires direct access to the server.
 func (s *FireflyMCPServer) MCPServer() *mcp.Server {
@@ -258,288 +404,759 @@ func (s *FireflyMCPServer) MCPServer() *mcp.Server {
 
 // Config returns the server configuration.
 func (s *FireflyMCPServer) Config() *Config {
+	return s.currentConfig()
+}
+
+// currentConfig returns the configuration in effect. ReloadConfig replaces it
+// rather than changing it, so a returned configuration never changes.
+func (s *FireflyMCPServer) currentConfig() *Config {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
 	return s.config
 }
 
 // registerTools registers all available MCP tools
 func (s *FireflyMCPServer) registerTools() {
 	// Account tools
-	mcp.AddTool(
-		s.server, &mcp.Tool{
+	addTool(
+		s, &mcp.Tool{
 			Name:        "list_accounts",
 			Description: "List all accounts in Firefly III",
+			Annotations: readOnlyAnnotations(),
 		}, s.handleListAccounts,
 	)
 
-	mcp.AddTool(
-		s.server, &mcp.Tool{
+	addTool(
+		s, &mcp.Tool{
 			Name:        "get_account",
 			Description: "Get details of a specific account",
+			Annotations: readOnlyAnnotations(),
 		}, s.handleGetAccount,
 	)
 
-	mcp.AddTool(
-		s.server, &mcp.Tool{
+	addTool(
+		s, &mcp.Tool{
+			Name:        "account_stats",
+			Description: "Get activity statistics of an account: transaction count, first and last activity, average monthly inflow/outflow and current balance",
+			Annotations: readOnlyAnnotations(),
+		}, s.handleAccountStats,
+	)
+
+	addTool(
+		s, &mcp.Tool{
+			Name:        "liability_schedule",
+			Description: "List the payments into a liability account and project the remaining monthly payments and payoff month at the current average payment",
+			Annotations: readOnlyAnnotations(),
+		}, s.handleLiabilitySchedule,
+	)
+
+	addTool(
+		s, &mcp.Tool{
+			Name: "grouped_balances",
+			Description: "Get the balances of asset and liability accounts rolled up per configured account group " +
+				"(e.g. Cash, Investments, Debt), per currency, optionally with the accounts of each group",
+			Annotations: readOnlyAnnotations(),
+		}, s.handleGroupedBalances,
+	)
+
+	addTool(
+		s, &mcp.Tool{
 			Name:        "search_accounts",
 			Description: "Search for accounts by name, IBAN, or other fields",
+			Annotations: readOnlyAnnotations(),
 		}, s.handleSearchAccounts,
 	)
 
 	// Transaction tools
-	mcp.AddTool(
-		s.server, &mcp.Tool{
+	addTool(
+		s, &mcp.Tool{
 			Name:        "list_transactions",
 			Description: "List transactions in Firefly III",
+			Annotations: readOnlyAnnotations(),
 		}, s.handleListTransactions,
 	)
 
-	mcp.AddTool(
-		s.server, &mcp.Tool{
+	addTool(
+		s, &mcp.Tool{
 			Name:        "get_transaction",
 			Description: "Get details of a specific transaction",
+			Annotations: readOnlyAnnotations(),
 		}, s.handleGetTransaction,
 	)
 
-	mcp.AddTool(
-		s.server, &mcp.Tool{
-			Name:        "search_transactions",
-			Description: "Search for transactions by keyword",
+	addTool(
+		s, &mcp.Tool{
+			Name: "search_transactions",
+			Description: "Search for transactions by keyword and filters such as notes text, attachments " +
+				"or a missing category or budget",
+			Annotations: readOnlyAnnotations(),
 		}, s.handleSearchTransactions,
 	)
 
-	mcp.AddTool(
-		s.server, &mcp.Tool{
+	addTool(
+		s, &mcp.Tool{
+			Name: "validate_search_query",
+			Description: "Check a Firefly III search query before running it: lists its operators, reports unknown ones " +
+				"with suggestions, returns the normalized query and estimates the number of matches",
+			Annotations: readOnlyAnnotations(),
+		}, s.handleValidateSearchQuery,
+	)
+
+	addTool(
+		s, &mcp.Tool{
 			Name:        "store_transaction",
 			Description: "Create a new transaction in Firefly III",
+			Annotations: additiveAnnotations(),
 		}, s.handleStoreTransaction,
 	)
-	mcp.AddTool(
-		s.server, &mcp.Tool{
+	addTool(
+		s, &mcp.Tool{
 			Name:        "store_transactions_bulk",
 			Description: "Create multiple transaction groups in Firefly III (up to 100 at once)",
+			Annotations: additiveAnnotations(),
 		}, s.handleStoreTransactionsBulk,
 	)
+	addTool(
+		s, &mcp.Tool{
+			Name: "transfer_to_piggy",
+			Description: "Transfer money from an asset account into a piggy bank and link the transfer to it " +
+				"in one call. The amount must not exceed what is left to save",
+			Annotations: additiveAnnotations(),
+		}, s.handleTransferToPiggy,
+	)
+
+	addTool(
+		s, &mcp.Tool{
+			Name: "plan_savings_goal",
+			Description: "Compute the monthly contribution needed to save a target amount by a date, and optionally " +
+				"create a piggy bank and a monthly recurring transfer funding it. Only previews the requests unless apply is true",
+			Annotations: additiveAnnotations(),
+		}, s.handlePlanSavingsGoal,
+	)
 
-	mcp.AddTool(
-		s.server, &mcp.Tool{
-			Name:        "update_transaction",
-			Description: "Update an existing transaction in Firefly III",
+	addTool(
+		s, &mcp.Tool{
+			Name: "update_transaction",
+			Description: "Update an existing transaction in Firefly III. Prefer patch to change single fields of " +
+				"splits by journal_id; everything not in the patch is kept",
+			Annotations: destructiveAnnotations(true),
 		}, s.handleUpdateTransaction,
 	)
+	addTool(
+		s, &mcp.Tool{
+			Name: "tag_transactions",
+			Description: "Add tags to all transactions of a date range, optionally only those of one account or category " +
+				"(e.g. to mark a holiday or tax year). Existing tags are kept. Only previews unless apply is true",
+			Annotations: destructiveAnnotations(true),
+		}, s.handleTagTransactions,
+	)
+
+	addTool(
+		s, &mcp.Tool{
+			Name:        "get_change_history",
+			Description: "List the changes this server made to a transaction (when, which tool and client, which fields), oldest first",
+			Annotations: readOnlyAnnotations(),
+		}, s.handleGetChangeHistory,
+	)
+	addTool(
+		s, &mcp.Tool{
+			Name: "store_planned_transaction",
+			Description: "Create a future-dated transaction tagged \"planned\", for planning what-if scenarios. " +
+				"Takes the same arguments as store_transaction; dates must be after today",
+			Annotations: additiveAnnotations(),
+		}, s.handleStorePlannedTransaction,
+	)
+	addTool(
+		s, &mcp.Tool{
+			Name:        "list_planned_transactions",
+			Description: "List planned transactions, split into those due for confirmation (planned for today or earlier) and upcoming ones",
+			Annotations: readOnlyAnnotations(),
+		}, s.handleListPlannedTransactions,
+	)
+	addTool(
+		s, &mcp.Tool{
+			Name: "confirm_planned",
+			Description: "Turn a planned transaction into a real one once it occurred: removes the planned tag and " +
+				"optionally sets the actual date and amount",
+			Annotations: destructiveAnnotations(true),
+		}, s.handleConfirmPlanned,
+	)
 
 	// Budget tools
-	mcp.AddTool(
-		s.server, &mcp.Tool{
+	addTool(
+		s, &mcp.Tool{
 			Name:        "list_budgets",
 			Description: "List all budgets in Firefly III",
+			Annotations: readOnlyAnnotations(),
 		}, s.handleListBudgets,
 	)
 
-	mcp.AddTool(
-		s.server, &mcp.Tool{
-			Name:        "list_budget_limits",
-			Description: "List budget limits for a specific budget with optional date range",
+	addTool(
+		s, &mcp.Tool{
+			Name: "list_budget_limits",
+			Description: "List budget limits for a specific budget with optional date range, with the remaining amount, " +
+				"days left in the period and daily allowance (remaining per day) as of today",
+			Annotations: readOnlyAnnotations(),
 		}, s.handleListBudgetLimits,
 	)
 
-	mcp.AddTool(
-		s.server, &mcp.Tool{
+	addTool(
+		s, &mcp.Tool{
 			Name:        "list_budget_transactions",
 			Description: "List transactions for a specific budget with optional filters",
+			Annotations: readOnlyAnnotations(),
 		}, s.handleListBudgetTransactions,
 	)
 
+	addTool(
+		s, &mcp.Tool{
+			Name:        "store_budget",
+			Description: "Create a budget, optionally with an auto-budget (reset or rollover amount per period)",
+			Annotations: additiveAnnotations(),
+		}, s.handleStoreBudget,
+	)
+
+	addTool(
+		s, &mcp.Tool{
+			Name:        "update_budget",
+			Description: "Update a budget's name, notes, active flag or auto-budget settings (unset fields are kept)",
+			Annotations: destructiveAnnotations(true),
+		}, s.handleUpdateBudget,
+	)
+
+	addTool(
+		s, &mcp.Tool{
+			Name: "budget_rollover",
+			Description: "Compute the unspent amount of each budget in the previous month and carry it over " +
+				"into the limits of the month (strategy: full, capped or none). Only computes unless apply is true",
+			Annotations: destructiveAnnotations(true),
+		}, s.handleBudgetRollover,
+	)
+	addTool(
+		s, &mcp.Tool{
+			Name: "seed_budget_limits",
+			Description: "Copy the monthly budget limits of one month to a range of months (e.g. January to February-" +
+				"December), optionally scaled. Existing limits are kept unless overwrite is true. Only previews unless apply is true",
+			Annotations: destructiveAnnotations(true),
+		}, s.handleSeedBudgetLimits,
+	)
+	addTool(
+		s, &mcp.Tool{
+			Name: "allocate_remaining",
+			Description: "Zero-based budgeting: compute the income of a month not yet allocated by budget limits and " +
+				"distribute it across budgets (strategy: equal or proportional to past spending). Only proposes limits unless apply is true",
+			Annotations: destructiveAnnotations(false),
+		}, s.handleAllocateRemaining,
+	)
+	addTool(
+		s, &mcp.Tool{
+			Name: "move_budget_amount",
+			Description: "Move an amount from one budget's limit to another's for a month (e.g. move 50 from dining to groceries). " +
+				"Both limits change or neither does",
+			Annotations: destructiveAnnotations(false),
+		}, s.handleMoveBudgetAmount,
+	)
+
 	// Category tools
-	mcp.AddTool(
-		s.server, &mcp.Tool{
+	addTool(
+		s, &mcp.Tool{
 			Name:        "list_categories",
 			Description: "List all categories in Firefly III",
+			Annotations: readOnlyAnnotations(),
 		}, s.handleListCategories,
 	)
+	addTool(
+		s, &mcp.Tool{
+			Name: "category_tree",
+			Description: "List all categories as a nested tree, using the configured delimiter in category names " +
+				"(e.g. \"Food: Groceries\") to find parents",
+			Annotations: readOnlyAnnotations(),
+		}, s.handleCategoryTree,
+	)
 
 	// Tag tools
-	mcp.AddTool(
-		s.server, &mcp.Tool{
+	addTool(
+		s, &mcp.Tool{
 			Name:        "list_tags",
 			Description: "List all tags in Firefly III",
+			Annotations: readOnlyAnnotations(),
 		}, s.handleListTags,
 	)
 
 	// Summary tools
-	mcp.AddTool(
-		s.server, &mcp.Tool{
+	addTool(
+		s, &mcp.Tool{
 			Name:        "get_summary",
 			Description: "Get basic financial summary from Firefly III",
+			Annotations: readOnlyAnnotations(),
 		}, s.handleGetSummary,
 	)
 
 	// Insights tools
-	mcp.AddTool(
-		s.server, &mcp.Tool{
+	addTool(
+		s, &mcp.Tool{
 			Name:        "expense_category_insights",
 			Description: "Get expense insights grouped by category for a date range",
+			Annotations: readOnlyAnnotations(),
 		}, s.handleExpenseCategoryInsights,
 	)
+	addTool(
+		s, &mcp.Tool{
+			Name: "category_rollup_insights",
+			Description: "Get expense insights by category for a date range as a category tree, " +
+				"with amounts of subcategories rolled up to their parents",
+			Annotations: readOnlyAnnotations(),
+		}, s.handleCategoryRollupInsights,
+	)
 
-	mcp.AddTool(
-		s.server, &mcp.Tool{
+	addTool(
+		s, &mcp.Tool{
 			Name:        "expense_total_insights",
 			Description: "Get total expense insights for a date range",
+			Annotations: readOnlyAnnotations(),
 		}, s.handleExpenseTotalInsights,
 	)
+	addTool(
+		s, &mcp.Tool{
+			Name: "expense_asset_insights",
+			Description: "Get expenses for a date range grouped by the asset account or liability they were paid from " +
+				"(e.g. checking account vs credit card), with each account's share of the total",
+			Annotations: readOnlyAnnotations(),
+		}, s.handleExpenseAssetInsights,
+	)
+	addTool(
+		s, &mcp.Tool{
+			Name:        "income_asset_insights",
+			Description: "Get income for a date range grouped by the asset account or liability it was paid into, with each account's share of the total",
+			Annotations: readOnlyAnnotations(),
+		}, s.handleIncomeAssetInsights,
+	)
+	addTool(
+		s, &mcp.Tool{
+			Name:        "income_expense_trend",
+			Description: "Get income, expenses and net change per month, quarter or year for a date range. Long ranges are summarized by a coarser granularity, see granularity_note",
+			Annotations: readOnlyAnnotations(),
+		}, s.handleIncomeExpenseTrend,
+	)
 
 	// Bill tools
-	mcp.AddTool(
-		s.server, &mcp.Tool{
+	addTool(
+		s, &mcp.Tool{
 			Name:        "list_bills",
 			Description: "List all bills in Firefly III",
+			Annotations: readOnlyAnnotations(),
 		}, s.handleListBills,
 	)
 
-	mcp.AddTool(
-		s.server, &mcp.Tool{
+	addTool(
+		s, &mcp.Tool{
 			Name:        "get_bill",
 			Description: "Get details of a specific bill",
+			Annotations: readOnlyAnnotations(),
 		}, s.handleGetBill,
 	)
 
-	mcp.AddTool(
-		s.server, &mcp.Tool{
+	addTool(
+		s, &mcp.Tool{
 			Name:        "list_bill_transactions",
 			Description: "List transactions associated with a specific bill",
+			Annotations: readOnlyAnnotations(),
 		}, s.handleListBillTransactions,
 	)
 
+	addTool(
+		s, &mcp.Tool{
+			Name:        "link_transaction_to_bill",
+			Description: "Link an existing transaction (or one of its splits) to a bill without restating the transaction",
+			Annotations: destructiveAnnotations(true),
+		}, s.handleLinkTransactionToBill,
+	)
+
+	addTool(
+		s, &mcp.Tool{
+			Name:        "unlink_transaction_from_bill",
+			Description: "Remove the bill link of an existing transaction (or one of its splits)",
+			Annotations: destructiveAnnotations(true),
+		}, s.handleUnlinkTransactionFromBill,
+	)
+
 	// Recurrence tools
-	mcp.AddTool(
-		s.server, &mcp.Tool{
+	addTool(
+		s, &mcp.Tool{
 			Name:        "list_recurrences",
 			Description: "List all recurrences in Firefly III",
+			Annotations: readOnlyAnnotations(),
 		}, s.handleListRecurrences,
 	)
 
-	mcp.AddTool(
-		s.server, &mcp.Tool{
+	addTool(
+		s, &mcp.Tool{
 			Name:        "get_recurrence",
 			Description: "Get details of a specific recurrence",
+			Annotations: readOnlyAnnotations(),
 		}, s.handleGetRecurrence,
 	)
 
-	mcp.AddTool(
-		s.server, &mcp.Tool{
+	addTool(
+		s, &mcp.Tool{
 			Name:        "list_recurrence_transactions",
 			Description: "List transactions created by a specific recurrence",
+			Annotations: readOnlyAnnotations(),
 		}, s.handleListRecurrenceTransactions,
 	)
 
 	// Rule Group tools
-	mcp.AddTool(
-		s.server, &mcp.Tool{
+	addTool(
+		s, &mcp.Tool{
 			Name:        "list_rule_groups",
 			Description: "List all rule groups in Firefly III",
+			Annotations: readOnlyAnnotations(),
 		}, s.handleListRuleGroups,
 	)
 
-	mcp.AddTool(
-		s.server, &mcp.Tool{
+	addTool(
+		s, &mcp.Tool{
 			Name:        "get_rule_group",
 			Description: "Get details of a specific rule group",
+			Annotations: readOnlyAnnotations(),
 		}, s.handleGetRuleGroup,
 	)
 
-	mcp.AddTool(
-		s.server, &mcp.Tool{
+	addTool(
+		s, &mcp.Tool{
 			Name:        "create_rule_group",
 			Description: "Create a new rule group for organizing automation rules",
+			Annotations: additiveAnnotations(),
 		}, s.handleCreateRuleGroup,
 	)
 
-	mcp.AddTool(
-		s.server, &mcp.Tool{
+	addTool(
+		s, &mcp.Tool{
 			Name:        "update_rule_group",
 			Description: "Update an existing rule group",
+			Annotations: destructiveAnnotations(true),
 		}, s.handleUpdateRuleGroup,
 	)
 
-	mcp.AddTool(
-		s.server, &mcp.Tool{
+	addTool(
+		s, &mcp.Tool{
 			Name:        "delete_rule_group",
 			Description: "Delete a rule group",
+			Annotations: destructiveAnnotations(true),
 		}, s.handleDeleteRuleGroup,
 	)
 
-	mcp.AddTool(
-		s.server, &mcp.Tool{
+	addTool(
+		s, &mcp.Tool{
 			Name:        "list_rules_by_group",
 			Description: "List all rules in a specific rule group",
+			Annotations: readOnlyAnnotations(),
 		}, s.handleListRulesByGroup,
 	)
 
-	mcp.AddTool(
-		s.server, &mcp.Tool{
+	addTool(
+		s, &mcp.Tool{
 			Name:        "test_rule_group",
 			Description: "Test which transactions would be affected by a rule group (dry-run, no changes made)",
+			Annotations: readOnlyAnnotations(),
 		}, s.handleTestRuleGroup,
 	)
 
-	mcp.AddTool(
-		s.server, &mcp.Tool{
+	addTool(
+		s, &mcp.Tool{
 			Name:        "trigger_rule_group",
 			Description: "Execute a rule group on transactions (applies changes asynchronously)",
+			Annotations: destructiveAnnotations(false),
 		}, s.handleTriggerRuleGroup,
 	)
 
 	// Rule tools
-	mcp.AddTool(
-		s.server, &mcp.Tool{
+	addTool(
+		s, &mcp.Tool{
 			Name:        "list_rules",
 			Description: "List all automation rules in Firefly III",
+			Annotations: readOnlyAnnotations(),
 		}, s.handleListRules,
 	)
 
-	mcp.AddTool(
-		s.server, &mcp.Tool{
+	addTool(
+		s, &mcp.Tool{
 			Name:        "get_rule",
 			Description: "Get details of a specific rule including triggers and actions",
+			Annotations: readOnlyAnnotations(),
 		}, s.handleGetRule,
 	)
 
-	mcp.AddTool(
-		s.server, &mcp.Tool{
+	addTool(
+		s, &mcp.Tool{
 			Name: "create_rule",
 			Description: "Create a new automation rule. Triggers: store-journal (on create), update-journal (on update). " +
 				"Trigger types: description_contains, amount_more, from_account_is, category_is, etc. " +
 				"Action types: set_category, add_tag, set_budget, set_description, etc.",
+			Annotations: additiveAnnotations(),
 		}, s.handleCreateRule,
 	)
 
-	mcp.AddTool(
-		s.server, &mcp.Tool{
+	addTool(
+		s, &mcp.Tool{
 			Name:        "update_rule",
 			Description: "Update an existing automation rule",
+			Annotations: destructiveAnnotations(true),
 		}, s.handleUpdateRule,
 	)
 
-	mcp.AddTool(
-		s.server, &mcp.Tool{
+	addTool(
+		s, &mcp.Tool{
 			Name:        "delete_rule",
 			Description: "Delete an automation rule",
+			Annotations: destructiveAnnotations(true),
 		}, s.handleDeleteRule,
 	)
 
-	mcp.AddTool(
-		s.server, &mcp.Tool{
+	addTool(
+		s, &mcp.Tool{
 			Name:        "test_rule",
 			Description: "Test which transactions would be affected by a rule (dry-run, no changes made)",
+			Annotations: readOnlyAnnotations(),
 		}, s.handleTestRule,
 	)
 
-	mcp.AddTool(
-		s.server, &mcp.Tool{
+	addTool(
+		s, &mcp.Tool{
+			Name: "build_rule_from_examples",
+			Description: "Build a create_rule request from example transactions and the desired category, tag or budget. " +
+				"Triggers are inferred from the common description text, type, counterparty and amount range. " +
+				"Nothing is created: review the rule, then call create_rule",
+			Annotations: readOnlyAnnotations(),
+		}, s.handleBuildRuleFromExamples,
+	)
+
+	addTool(
+		s, &mcp.Tool{
 			Name:        "trigger_rule",
 			Description: "Execute a rule on transactions (applies changes asynchronously)",
+			Annotations: destructiveAnnotations(false),
 		}, s.handleTriggerRule,
 	)
+
+	// Trash tools
+	if s.trashEnabled() {
+		addTool(
+			s, &mcp.Tool{
+				Name:        "list_trash",
+				Description: "List objects moved to the trash by delete tools, with their full payload. Purges objects whose grace period is over",
+				Annotations: readOnlyAnnotations(),
+			}, s.handleListTrash,
+		)
+
+		addTool(
+			s, &mcp.Tool{
+				Name:        "restore_from_trash",
+				Description: "Restore an object from the trash, reactivating it in Firefly III if it was active",
+				Annotations: destructiveAnnotations(true),
+			}, s.handleRestoreFromTrash,
+		)
+
+		addTool(
+			s, &mcp.Tool{
+				Name:        "purge_trash",
+				Description: "Permanently delete one trashed object (id) or all of them (all) in Firefly III",
+				Annotations: destructiveAnnotations(true),
+			}, s.handlePurgeTrash,
+		)
+	}
+
+	// Write journal tools
+	if s.journal != nil {
+		addTool(
+			s, &mcp.Tool{
+				Name: "list_interrupted_batches",
+				Description: "List bulk stores interrupted by a server restart, with the groups that were committed, " +
+					"failed, possibly sent (uncertain) or never sent",
+				Annotations: readOnlyAnnotations(),
+			}, s.handleListInterruptedBatches,
+		)
+
+		addTool(
+			s, &mcp.Tool{
+				Name: "resume_batch",
+				Description: "Store the groups of an interrupted bulk store that were never sent, " +
+					"or discard the batch (discard)",
+				Annotations: additiveAnnotations(),
+			}, s.handleResumeBatch,
+		)
+	}
+
+	// Demo tools
+	if config := s.currentConfig(); config != nil && config.Demo.Enabled {
+		addTool(
+			s, &mcp.Tool{
+				Name: "generate_demo_data",
+				Description: "Create demo accounts, categories, budgets with monthly limits, bills and several months of " +
+					"transactions tagged 'demo'. The same seed creates the same data. Refuses instances that already have " +
+					"transactions unless force is set",
+				Annotations: additiveAnnotations(),
+			}, s.handleGenerateDemoData,
+		)
+	}
+
+	if config := s.currentConfig(); config != nil && config.Proxy.Enabled {
+		addTool(
+			s, &mcp.Tool{
+				Name: "firefly_api_request",
+				Description: "Send a GET request to a Firefly III API path (e.g. /v1/accounts/1/piggy-banks) with query " +
+					"parameters and return the raw JSON response. For data no other tool covers; only allowed paths can be read",
+				Annotations: readOnlyAnnotations(),
+			}, s.handleFireflyAPIRequest,
+		)
+	}
+
+	if config := s.currentConfig(); config != nil && config.Sampling.Enabled {
+		addTool(
+			s, &mcp.Tool{
+				Name: "summarize_transactions",
+				Description: "Answer a question about all transactions of a period (e.g. which subscriptions got more " +
+					"expensive) without loading them into the conversation: the client's model summarizes them chunk by " +
+					"chunk via MCP sampling, and only the answer and the cited transactions are returned. Needs a client " +
+					"that supports sampling",
+				Annotations: readOnlyAnnotations(),
+			}, s.handleSummarizeTransactions,
+		)
+	}
+
+	// Workflow tools
+	addTool(
+		s, &mcp.Tool{
+			Name: "close_month",
+			Description: "Run the monthly close workflow: reconcile hints, uncategorized transactions, " +
+				"budget report, net worth snapshot, anomalies and follow-up suggestions in one report",
+			Annotations: readOnlyAnnotations(),
+		}, s.handleCloseMonth,
+	)
+	addTool(
+		s, &mcp.Tool{
+			Name: "diff_periods",
+			Description: "Compare two sets of transactions (snapshot URIs or date filters) and list added, removed " +
+				"and changed transactions. Filtered sides are saved as snapshots for later comparisons, " +
+				"e.g. to verify a bulk operation",
+			Annotations: readOnlyAnnotations(),
+		}, s.handleDiffPeriods,
+	)
+	addTool(
+		s, &mcp.Tool{
+			Name: "verify_consistency",
+			Description: "Compare summary and insight totals (spent, earned) of a period with the sums of its " +
+				"transactions and explain discrepancies, e.g. caused by transfers or excluded accounts",
+			Annotations: readOnlyAnnotations(),
+		}, s.handleVerifyConsistency,
+	)
+
+	addTool(
+		s, &mcp.Tool{
+			Name: "project_report",
+			Description: "Profit-and-loss statement of a project or client tracked by tags: income, expenses and net " +
+				"per currency for a period, broken down by category, asset account and tag. Transfers are skipped",
+			Annotations: readOnlyAnnotations(),
+		}, s.handleProjectReport,
+	)
+	addTool(
+		s, &mcp.Tool{
+			Name: "trip_report",
+			Description: "Cost of a holiday or trip given by its tags or dates, optionally only on some accounts: total, " +
+				"refunds and daily average per currency, broken down by category and day. format markdown returns a " +
+				"report ready to show to the user",
+			Annotations: readOnlyAnnotations(),
+		}, s.handleTripReport,
+	)
+
+	addTool(
+		s, &mcp.Tool{
+			Name: "spend_by_member",
+			Description: "Spending of a period per household member, attributed by member tags (household.tag_prefix, " +
+				"e.g. member:alice), with a category breakdown and the spending without a member tag",
+			Annotations: readOnlyAnnotations(),
+		}, s.handleSpendByMember,
+	)
+
+	if s.taxEnabled() {
+		addTool(
+			s, &mcp.Tool{
+				Name: "tax_report",
+				Description: "Quarterly VAT summary of the tax-relevant categories and tags configured in tax: gross, net " +
+					"and VAT per category or tag, VAT collected, paid and balance, as JSON or CSV (format)",
+				Annotations: readOnlyAnnotations(),
+			}, s.handleTaxReport,
+		)
+	}
+
+	// Autocomplete tools
+	addTool(
+		s, &mcp.Tool{
+			Name: "autocomplete",
+			Description: "Look up bills, tags, piggy banks, transaction types, currencies or rules by name " +
+				"and get their IDs (type argument selects which)",
+			Annotations: readOnlyAnnotations(),
+		}, s.handleAutocomplete,
+	)
+
+	// Meta tools
+	addTool(
+		s, &mcp.Tool{
+			Name: "instance_overview",
+			Description: "Get the size of the Firefly III instance: number of accounts, transactions, budgets, rules " +
+				"and bills and the dates of the oldest and newest transaction. Use it to choose a strategy before " +
+				"fetching large amounts of data",
+			Annotations: readOnlyAnnotations(),
+		}, s.handleInstanceOverview,
+	)
+
+	addTool(
+		s, &mcp.Tool{
+			Name: "cleanup_advisor",
+			Description: "Find clutter to clean up: categories and tags without transactions in the last months, " +
+				"budgets without transactions and limits, inactive accounts with a zero balance and expense accounts " +
+				"whose names look like duplicates. Returns a plan of deletions and merges with the Firefly III API " +
+				"call and caveats of each; nothing is changed",
+			Annotations: readOnlyAnnotations(),
+		}, s.handleCleanupAdvisor,
+	)
+
+	addTool(
+		s, &mcp.Tool{
+			Name: "find_currency_mismatches",
+			Description: "Find currency mismatches that make totals wrong: bills and budget limits with transactions " +
+				"in another currency, and transactions whose currency differs from their account's. Each comes with " +
+				"a suggested fix and the tool or API call to apply it; nothing is changed. Defaults to the current month",
+			Annotations: readOnlyAnnotations(),
+		}, s.handleFindCurrencyMismatches,
+	)
+
+	addTool(
+		s, &mcp.Tool{
+			Name: "explain_tool",
+			Description: "Explain a tool: its parameters, input and output schema, examples and the defaults " +
+				"currently configured for it. Use it before calling a complex tool for the first time",
+			Annotations: readOnlyAnnotations(),
+		}, s.handleExplainTool,
+	)
+
+	addTool(
+		s, &mcp.Tool{
+			Name: "get_session_stats",
+			Description: "Get statistics of this session: tool calls (total and per tool), errors, writes, bytes " +
+				"returned and, in HTTP mode, the remaining rate limit budget. Use it to pace long-running work",
+			Annotations: readOnlyAnnotations(),
+		}, s.handleGetSessionStats,
+	)
+
+	addTool(
+		s, &mcp.Tool{
+			Name: "get_schema_changelog",
+			Description: "List how tool results changed between schema versions. Every result carries the current " +
+				"version in _meta.schema_version; when it differs from the version you know, call this with since",
+			Annotations: readOnlyAnnotations(),
+		}, s.handleGetSchemaChangelog,
+	)
 }
 
 // Tool handlers
@@ -561,10 +1178,7 @@ func (s *FireflyMCPServer) handleListAccounts(
 		apiParams.Type = &filter
 	}
 
-	if args.Limit > 0 {
-		limit := int32(args.Limit)
-		apiParams.Limit = &limit
-	}
+	apiParams.Limit = s.limitParam("list_accounts", args.Limit)
 
 	if args.Page > 0 {
 		page := int32(args.Page)
@@ -600,7 +1214,7 @@ func (s *FireflyMCPServer) handleGetAccount(
 	}
 
 	apiParams := &client.GetAccountParams{}
-	resp, err := apiClient.GetAccountWithResponse(ctx, args.ID, apiParams)
+	resp, err := apiClient.GetAccountWithResponse(ctx, s.resolveAccountRef(args.ID), apiParams)
 	if err != nil {
 		return newErrorResult(fmt.Sprintf("Error getting account: %v", err))
 	}
@@ -649,10 +1263,7 @@ func (s *FireflyMCPServer) handleSearchAccounts(
 		Field: client.AccountSearchFieldFilter(args.Field),
 	}
 
-	if args.Limit > 0 {
-		limit := int32(args.Limit)
-		apiParams.Limit = &limit
-	}
+	apiParams.Limit = s.limitParam("search_accounts", args.Limit)
 
 	if args.Page > 0 {
 		page := int32(args.Page)
@@ -689,6 +1300,22 @@ func (s *FireflyMCPServer) handleListTransactions(
 	req *mcp.CallToolRequest,
 	args ListTransactionsArgs,
 ) (*mcp.CallToolResult, any, error) {
+	dates, err := s.resolveDateRange(args.DateRange, dateRangeOpen)
+	if err != nil {
+		return newErrorResult(err.Error())
+	}
+
+	// The transactions endpoint cannot filter on reconciliation, the search endpoint can
+	if args.Reconciled != nil {
+		return s.handleSearchTransactions(ctx, req, SearchTransactionsArgs{
+			Query:      transactionFilterQuery(args.Type, dates),
+			Limit:      int32(args.Limit),
+			Page:       int32(args.Page),
+			Reconciled: args.Reconciled,
+			Compact:    args.Compact,
+		})
+	}
+
 	apiClient, err := s.getClient(ctx, req)
 	if err != nil {
 		return newErrorResult(fmt.Sprintf("Failed to get API client: %v", err))
@@ -701,24 +1328,10 @@ func (s *FireflyMCPServer) handleListTransactions(
 		apiParams.Type = &filter
 	}
 
-	if args.Start != "" {
-		if startDate, err := time.Parse("2006-01-02", args.Start); err == nil {
-			date := openapi_types.Date{Time: startDate}
-			apiParams.Start = &date
-		}
-	}
-
-	if args.End != "" {
-		if endDate, err := time.Parse("2006-01-02", args.End); err == nil {
-			date := openapi_types.Date{Time: endDate}
-			apiParams.End = &date
-		}
-	}
+	apiParams.Start = dates.Start
+	apiParams.End = dates.End
 
-	if args.Limit > 0 {
-		limit := int32(args.Limit)
-		apiParams.Limit = &limit
-	}
+	apiParams.Limit = s.limitParam("list_transactions", args.Limit)
 
 	if args.Page > 0 {
 		page := int32(args.Page)
@@ -746,7 +1359,7 @@ func (s *FireflyMCPServer) handleListTransactions(
 
 	// Map response to DTO
 	transactionList := mapTransactionArrayToTransactionList(resp.ApplicationvndApiJSON200)
-	return newSuccessResult(transactionList)
+	return newTransactionListResult(transactionList, args.Compact)
 }
 
 func (s *FireflyMCPServer) handleGetTransaction(
@@ -808,7 +1421,8 @@ func (s *FireflyMCPServer) handleSearchTransactions(
 	args SearchTransactionsArgs,
 ) (*mcp.CallToolResult, any, error) {
 	// Validate required arguments
-	if args.Query == "" {
+	filters := searchFilterQuery(args)
+	if args.Query == "" && filters == "" {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
 				&mcp.TextContent{Text: "Query parameter is required"},
@@ -816,6 +1430,11 @@ func (s *FireflyMCPServer) handleSearchTransactions(
 			IsError: true,
 		}, nil, nil
 	}
+	dates, err := s.resolveDateRange(args.DateRange, dateRangeOpen)
+	if err != nil {
+		return newErrorResult(err.Error())
+	}
+	query := joinSearchQuery(args.Query, transactionFilterQuery("", dates), filters)
 
 	apiClient, err := s.getClient(ctx, req)
 	if err != nil {
@@ -824,8 +1443,8 @@ func (s *FireflyMCPServer) handleSearchTransactions(
 
 	// Build API parameters
 	apiParams := &client.SearchTransactionsParams{
-		Query: args.Query,
-		Limit: &args.Limit,
+		Query: query,
+		Limit: s.limitParam("search_transactions", int(args.Limit)),
 		Page:  &args.Page,
 	}
 
@@ -851,7 +1470,7 @@ func (s *FireflyMCPServer) handleSearchTransactions(
 
 	// Map response to DTO - reuse existing mapper since response type is TransactionArray
 	transactionList := mapTransactionArrayToTransactionList(resp.ApplicationvndApiJSON200)
-	return newSuccessResult(transactionList)
+	return newTransactionListResult(transactionList, args.Compact)
 }
 
 func (s *FireflyMCPServer) handleListBudgets(
@@ -866,37 +1485,19 @@ func (s *FireflyMCPServer) handleListBudgets(
 
 	apiParams := &client.ListBudgetParams{}
 
-	// Set default start date to first day of current month
-	now := time.Now()
-	firstDayOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
-	startDate := openapi_types.Date{Time: firstDayOfMonth}
-	apiParams.Start = &startDate
-
-	// Set default end date to last day of current month
-	lastDayOfMonth := time.Date(now.Year(), now.Month()+1, 0, 23, 59, 59, 0, now.Location())
-	endDate := openapi_types.Date{Time: lastDayOfMonth}
-	apiParams.End = &endDate
-
-	// Handle custom start date if provided
-	if args.Start != "" {
-		if customStartDate, err := time.Parse("2006-01-02", args.Start); err == nil {
-			date := openapi_types.Date{Time: customStartDate}
-			apiParams.Start = &date
-		}
+	// Spending is reported for the current month unless a range is given
+	dateRange, err := s.resolveFiscalPeriod(ctx, req, args.DateRange)
+	if err != nil {
+		return newErrorResult(err.Error())
 	}
-
-	// Handle end date if provided
-	if args.End != "" {
-		if endDate, err := time.Parse("2006-01-02", args.End); err == nil {
-			date := openapi_types.Date{Time: endDate}
-			apiParams.End = &date
-		}
+	dates, err := s.resolveDateRange(dateRange, dateRangeCurrentMonth)
+	if err != nil {
+		return newErrorResult(err.Error())
 	}
+	apiParams.Start = dates.Start
+	apiParams.End = dates.End
 
-	if args.Limit > 0 {
-		limit := int32(args.Limit)
-		apiParams.Limit = &limit
-	}
+	apiParams.Limit = s.limitParam("list_budgets", args.Limit)
 
 	if args.Page > 0 {
 		page := int32(args.Page)
@@ -939,10 +1540,7 @@ func (s *FireflyMCPServer) handleListCategories(
 
 	apiParams := &client.ListCategoryParams{}
 
-	if args.Limit > 0 {
-		limit := int32(args.Limit)
-		apiParams.Limit = &limit
-	}
+	apiParams.Limit = s.limitParam("list_categories", args.Limit)
 
 	if args.Page > 0 {
 		page := int32(args.Page)
@@ -985,10 +1583,7 @@ func (s *FireflyMCPServer) handleListTags(
 
 	apiParams := &client.ListTagParams{}
 
-	if args.Limit > 0 {
-		limit := int32(args.Limit)
-		apiParams.Limit = &limit
-	}
+	apiParams.Limit = s.limitParam("list_tags", args.Limit)
 
 	if args.Page > 0 {
 		page := int32(args.Page)
@@ -1031,30 +1626,17 @@ func (s *FireflyMCPServer) handleGetSummary(
 
 	apiParams := &client.GetBasicSummaryParams{}
 
-	// Set default start date to first day of current month
-	now := time.Now()
-	firstDayOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
-	startDate := openapi_types.Date{Time: firstDayOfMonth}
-	apiParams.Start = startDate
-
-	// Set default end date to last day of current month
-	lastDayOfMonth := time.Date(now.Year(), now.Month()+1, 0, 23, 59, 59, 0, now.Location())
-	endDate := openapi_types.Date{Time: lastDayOfMonth}
-	apiParams.End = endDate
-
-	if args.Start != "" {
-		if startDate, err := time.Parse("2006-01-02", args.Start); err == nil {
-			date := openapi_types.Date{Time: startDate}
-			apiParams.Start = date
-		}
+	// The summary covers the current month unless a range is given
+	dateRange, err := s.resolveFiscalPeriod(ctx, req, args.DateRange)
+	if err != nil {
+		return newErrorResult(err.Error())
 	}
-
-	if args.End != "" {
-		if endDate, err := time.Parse("2006-01-02", args.End); err == nil {
-			date := openapi_types.Date{Time: endDate}
-			apiParams.End = date
-		}
+	dates, err := s.resolveDateRange(dateRange, dateRangeCurrentMonth)
+	if err != nil {
+		return newErrorResult(err.Error())
 	}
+	apiParams.Start = *dates.Start
+	apiParams.End = *dates.End
 
 	resp, err := apiClient.GetBasicSummaryWithResponse(ctx, apiParams)
 	if err != nil {
@@ -1077,6 +1659,12 @@ func (s *FireflyMCPServer) handleGetSummary(
 
 	// Map response to DTO
 	summaryList := mapBasicSummaryToBasicSummaryList(resp.ApplicationvndApiJSON200)
+	if args.WithTrend && summaryList != nil {
+		summaryList.Trend, err = s.dailyNetTrend(ctx, apiClient, dates.Start.Time, dates.End.Time)
+		if err != nil {
+			summaryList.TrendNote = err.Error()
+		}
+	}
 	return newSuccessResult(summaryList)
 }
 
@@ -1087,28 +1675,38 @@ func mapBudgetArrayToBudgetList(budgetArray *client.BudgetArray) *BudgetList {
 	}
 
 	budgetList := &BudgetList{
-		Data: make([]Budget, len(budgetArray.Data)),
+		Data:        make([]Budget, len(budgetArray.Data)),
+		SpentTotals: make([]BudgetSpent, 0),
 	}
 
+	// Totals per currency, in the order the currencies first appear
+	totals := make(map[string]decimal)
+	decimals := make(map[string]int)
+
 	// Map budget data
 	for i, budgetRead := range budgetArray.Data {
-		budget := Budget{
-			Id:     budgetRead.Id,
-			Active: budgetRead.Attributes.Active != nil && *budgetRead.Attributes.Active,
-			Name:   budgetRead.Attributes.Name,
-			Notes:  budgetRead.Attributes.Notes,
-		}
+		budgetList.Data[i] = mapBudgetReadToBudget(budgetRead)
 
-		// Map spent information - take only the first value
-		if budgetRead.Attributes.Spent != nil && len(*budgetRead.Attributes.Spent) > 0 {
-			firstSpent := (*budgetRead.Attributes.Spent)[0]
-			budget.Spent = Spent{
-				Sum:          getStringValue(firstSpent.Sum),
-				CurrencyCode: getStringValue(firstSpent.CurrencyCode),
+		if budgetRead.Attributes.Spent == nil {
+			continue
+		}
+		for _, spent := range *budgetRead.Attributes.Spent {
+			code := getStringValue(spent.CurrencyCode)
+			if _, ok := totals[code]; !ok {
+				budgetList.SpentTotals = append(budgetList.SpentTotals, BudgetSpent{
+					CurrencyCode:   code,
+					CurrencySymbol: getStringValue(spent.CurrencySymbol),
+				})
+				decimals[code] = 2
+				if spent.CurrencyDecimalPlaces != nil {
+					decimals[code] = int(*spent.CurrencyDecimalPlaces)
+				}
 			}
+			totals[code] = totals[code].Add(parseAmount(getStringValue(spent.Sum)))
 		}
-
-		budgetList.Data[i] = budget
+	}
+	for i, total := range budgetList.SpentTotals {
+		budgetList.SpentTotals[i].Sum = totals[total.CurrencyCode].StringFixed(decimals[total.CurrencyCode])
 	}
 
 	// Map pagination
@@ -1126,6 +1724,44 @@ func mapBudgetArrayToBudgetList(budgetArray *client.BudgetArray) *BudgetList {
 	return budgetList
 }
 
+// mapBudgetReadToBudget converts a client.BudgetRead to the Budget DTO
+func mapBudgetReadToBudget(budgetRead client.BudgetRead) Budget {
+	attributes := budgetRead.Attributes
+	budget := Budget{
+		Id:     budgetRead.Id,
+		Active: attributes.Active != nil && *attributes.Active,
+		Name:   attributes.Name,
+		Notes:  attributes.Notes,
+		Spent:  make([]BudgetSpent, 0),
+	}
+
+	// Map spent information, one entry per currency
+	if attributes.Spent != nil {
+		for _, spent := range *attributes.Spent {
+			budget.Spent = append(budget.Spent, BudgetSpent{
+				Sum:            getStringValue(spent.Sum),
+				CurrencyCode:   getStringValue(spent.CurrencyCode),
+				CurrencySymbol: getStringValue(spent.CurrencySymbol),
+			})
+		}
+	}
+
+	// Map auto-budget settings; Firefly III reports "none" or null without one
+	if attributes.AutoBudgetType != nil && *attributes.AutoBudgetType != client.AutoBudgetTypeNone &&
+		*attributes.AutoBudgetType != client.AutoBudgetTypeLessThannil {
+		autoBudgetType := string(*attributes.AutoBudgetType)
+		budget.AutoBudgetType = &autoBudgetType
+		budget.AutoBudgetAmount = attributes.AutoBudgetAmount
+		budget.AutoBudgetCurrencyCode = attributes.CurrencyCode
+		if attributes.AutoBudgetPeriod != nil {
+			autoBudgetPeriod := string(*attributes.AutoBudgetPeriod)
+			budget.AutoBudgetPeriod = &autoBudgetPeriod
+		}
+	}
+
+	return budget
+}
+
 // mapCategoryArrayToCategoryList converts client.CategoryArray to CategoryList DTO
 func mapCategoryArrayToCategoryList(categoryArray *client.CategoryArray) *CategoryList {
 	if categoryArray == nil {
@@ -1208,15 +1844,7 @@ func mapAccountArrayToAccountList(accountArray *client.AccountArray) *AccountLis
 
 	// Map account data
 	for i, accountRead := range accountArray.Data {
-		account := Account{
-			Id:     accountRead.Id,
-			Active: accountRead.Attributes.Active != nil && *accountRead.Attributes.Active,
-			Name:   accountRead.Attributes.Name,
-			Notes:  accountRead.Attributes.Notes,
-			Type:   string(accountRead.Attributes.Type),
-		}
-
-		accountList.Data[i] = account
+		accountList.Data[i] = mapAccountReadToAccount(accountRead)
 	}
 
 	// Map pagination
@@ -1240,13 +1868,28 @@ func mapAccountSingleToAccount(accountSingle *client.AccountSingle) *Account {
 		return nil
 	}
 
-	return &Account{
-		Id:     accountSingle.Data.Id,
-		Active: accountSingle.Data.Attributes.Active != nil && *accountSingle.Data.Attributes.Active,
-		Name:   accountSingle.Data.Attributes.Name,
-		Notes:  accountSingle.Data.Attributes.Notes,
-		Type:   string(accountSingle.Data.Attributes.Type),
-	}
+	account := mapAccountReadToAccount(accountSingle.Data)
+	return &account
+}
+
+// mapAccountReadToAccount converts client.AccountRead to Account DTO
+func mapAccountReadToAccount(accountRead client.AccountRead) Account {
+	attributes := accountRead.Attributes
+	account := Account{
+		Id:                 accountRead.Id,
+		Active:             attributes.Active != nil && *attributes.Active,
+		Name:               attributes.Name,
+		Notes:              attributes.Notes,
+		Type:               string(attributes.Type),
+		OpeningBalance:     attributes.OpeningBalance,
+		OpeningBalanceDate: attributes.OpeningBalanceDate,
+		Interest:           attributes.Interest,
+	}
+	if attributes.InterestPeriod != nil {
+		period := string(*attributes.InterestPeriod)
+		account.InterestPeriod = &period
+	}
+	return account
 }
 
 // mapTransactionArrayToTransactionList converts client.TransactionArray to TransactionList DTO
@@ -1289,12 +1932,16 @@ func mapTransactionReadToTransactionGroup(transactionRead *client.TransactionRea
 		Id:           transactionRead.Id,
 		GroupTitle:   getStringValue(transactionRead.Attributes.GroupTitle),
 		Transactions: make([]Transaction, len(transactionRead.Attributes.Transactions)),
+		CreatedAt:    transactionRead.Attributes.CreatedAt,
+		UpdatedAt:    transactionRead.Attributes.UpdatedAt,
 	}
 
 	// Map individual transactions within the group
 	for i, split := range transactionRead.Attributes.Transactions {
 		transaction := Transaction{
 			Id:              getStringValue(split.TransactionJournalId),
+			JournalId:       getStringValue(split.TransactionJournalId),
+			Order:           i,
 			Amount:          split.Amount,
 			BillId:          split.BillId,
 			BillName:        split.BillName,
@@ -1313,6 +1960,14 @@ func mapTransactionReadToTransactionGroup(transactionRead *client.TransactionRea
 			SourceId:        getStringValue(split.SourceId),
 			SourceName:      getStringValue(split.SourceName),
 			Type:            string(split.Type),
+
+			ExternalId:        split.ExternalId,
+			InternalReference: split.InternalReference,
+			OriginalSource:    split.OriginalSource,
+			ImportHashV2:      split.ImportHashV2,
+			RecurrenceId:      split.RecurrenceId,
+			RecurrenceCount:   getInt32AsIntPtr(split.RecurrenceCount),
+			RecurrenceTotal:   getInt32AsIntPtr(split.RecurrenceTotal),
 		}
 
 		// Handle tags
@@ -1322,12 +1977,39 @@ func mapTransactionReadToTransactionGroup(transactionRead *client.TransactionRea
 			transaction.Tags = []string{}
 		}
 
+		if split.Order != nil {
+			transaction.Order = int(*split.Order)
+		}
+
 		group.Transactions[i] = transaction
 	}
+	sortSplits(group.Transactions)
 
 	return group
 }
 
+// sortSplits orders the splits of a group by their order, then by journal ID,
+// so splits are listed the same way on every read
+func sortSplits(splits []Transaction) {
+	sort.SliceStable(splits, func(i, j int) bool {
+		if splits[i].Order != splits[j].Order {
+			return splits[i].Order < splits[j].Order
+		}
+		return compareNumericIDs(splits[i].JournalId, splits[j].JournalId) < 0
+	})
+}
+
+// compareNumericIDs compares two Firefly III IDs numerically, falling back to a
+// string comparison for IDs that are not numbers
+func compareNumericIDs(a, b string) int {
+	x, errA := strconv.Atoi(a)
+	y, errB := strconv.Atoi(b)
+	if errA != nil || errB != nil {
+		return strings.Compare(a, b)
+	}
+	return cmp.Compare(x, y)
+}
+
 // mapBasicSummaryToBasicSummaryList converts client.BasicSummary to BasicSummaryList DTO
 func mapBasicSummaryToBasicSummaryList(basicSummary *client.BasicSummary) *BasicSummaryList {
 	if basicSummary == nil {
@@ -1360,35 +2042,13 @@ func (s *FireflyMCPServer) handleExpenseCategoryInsights(
 	req *mcp.CallToolRequest,
 	args ExpenseCategoryInsightsArgs,
 ) (*mcp.CallToolResult, any, error) {
-	// Validate required dates
-	if args.Start == "" || args.End == "" {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{Text: "Start and End dates are required"},
-			},
-			IsError: true,
-		}, nil, nil
-	}
-
-	// Parse dates
-	startDate, err := time.Parse("2006-01-02", args.Start)
+	dateRange, err := s.resolveFiscalPeriod(ctx, req, args.DateRange)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{Text: fmt.Sprintf("Invalid start date format: %v", err)},
-			},
-			IsError: true,
-		}, nil, nil
+		return newErrorResult(err.Error())
 	}
-
-	endDate, err := time.Parse("2006-01-02", args.End)
+	dates, err := s.resolveDateRange(dateRange, dateRangeRequired)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{Text: fmt.Sprintf("Invalid end date format: %v", err)},
-			},
-			IsError: true,
-		}, nil, nil
+		return newErrorResult(err.Error())
 	}
 
 	apiClient, err := s.getClient(ctx, req)
@@ -1398,14 +2058,14 @@ func (s *FireflyMCPServer) handleExpenseCategoryInsights(
 
 	// Build API parameters
 	apiParams := &client.InsightExpenseCategoryParams{
-		Start: openapi_types.Date{Time: startDate},
-		End:   openapi_types.Date{Time: endDate},
+		Start: *dates.Start,
+		End:   *dates.End,
 	}
 
-	// Convert account IDs from strings to int64
+	// Convert account IDs (or configured aliases) from strings to int64
 	if len(args.Accounts) > 0 {
 		accounts := make([]int64, len(args.Accounts))
-		for i, accStr := range args.Accounts {
+		for i, accStr := range s.resolveAccountRefs(args.Accounts) {
 			var accID int64
 			if _, err := fmt.Sscanf(accStr, "%d", &accID); err != nil {
 				return &mcp.CallToolResult{
@@ -1451,35 +2111,13 @@ func (s *FireflyMCPServer) handleExpenseTotalInsights(
 	req *mcp.CallToolRequest,
 	args ExpenseTotalInsightsArgs,
 ) (*mcp.CallToolResult, any, error) {
-	// Validate required dates
-	if args.Start == "" || args.End == "" {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{Text: "Start and End dates are required"},
-			},
-			IsError: true,
-		}, nil, nil
-	}
-
-	// Parse dates
-	startDate, err := time.Parse("2006-01-02", args.Start)
+	dateRange, err := s.resolveFiscalPeriod(ctx, req, args.DateRange)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{Text: fmt.Sprintf("Invalid start date format: %v", err)},
-			},
-			IsError: true,
-		}, nil, nil
+		return newErrorResult(err.Error())
 	}
-
-	endDate, err := time.Parse("2006-01-02", args.End)
+	dates, err := s.resolveDateRange(dateRange, dateRangeRequired)
 	if err != nil {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{Text: fmt.Sprintf("Invalid end date format: %v", err)},
-			},
-			IsError: true,
-		}, nil, nil
+		return newErrorResult(err.Error())
 	}
 
 	apiClient, err := s.getClient(ctx, req)
@@ -1489,14 +2127,14 @@ func (s *FireflyMCPServer) handleExpenseTotalInsights(
 
 	// Build API parameters
 	apiParams := &client.InsightExpenseTotalParams{
-		Start: openapi_types.Date{Time: startDate},
-		End:   openapi_types.Date{Time: endDate},
+		Start: *dates.Start,
+		End:   *dates.End,
 	}
 
-	// Convert account IDs from strings to int64
+	// Convert account IDs (or configured aliases) from strings to int64
 	if len(args.Accounts) > 0 {
 		accounts := make([]int64, len(args.Accounts))
-		for i, accStr := range args.Accounts {
+		for i, accStr := range s.resolveAccountRefs(args.Accounts) {
 			var accID int64
 			if _, err := fmt.Sscanf(accStr, "%d", &accID); err != nil {
 				return &mcp.CallToolResult{
@@ -1560,35 +2198,16 @@ func (s *FireflyMCPServer) handleListBudgetLimits(
 	// Build API parameters
 	apiParams := &client.ListBudgetLimitByBudgetParams{}
 
-	// Parse optional start date
-	if args.Start != "" {
-		startDate, err := time.Parse("2006-01-02", args.Start)
-		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: fmt.Sprintf("Invalid start date format: %v", err)},
-				},
-				IsError: true,
-			}, nil, nil
-		}
-		date := openapi_types.Date{Time: startDate}
-		apiParams.Start = &date
+	dateRange, err := s.resolveFiscalPeriod(ctx, req, args.DateRange)
+	if err != nil {
+		return newErrorResult(err.Error())
 	}
-
-	// Parse optional end date
-	if args.End != "" {
-		endDate, err := time.Parse("2006-01-02", args.End)
-		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: fmt.Sprintf("Invalid end date format: %v", err)},
-				},
-				IsError: true,
-			}, nil, nil
-		}
-		date := openapi_types.Date{Time: endDate}
-		apiParams.End = &date
+	dates, err := s.resolveDateRange(dateRange, dateRangeOpen)
+	if err != nil {
+		return newErrorResult(err.Error())
 	}
+	apiParams.Start = dates.Start
+	apiParams.End = dates.End
 
 	// Call the API
 	resp, err := apiClient.ListBudgetLimitByBudgetWithResponse(ctx, args.ID, apiParams)
@@ -1612,6 +2231,7 @@ func (s *FireflyMCPServer) handleListBudgetLimits(
 
 	// Map response to DTO
 	budgetLimitList := mapBudgetLimitArrayToBudgetLimitList(resp.ApplicationvndApiJSON200)
+	s.addBudgetAllowances(budgetLimitList)
 	return newSuccessResult(budgetLimitList)
 }
 
@@ -1639,45 +2259,23 @@ func (s *FireflyMCPServer) handleListBudgetTransactions(
 	apiParams := &client.ListTransactionByBudgetParams{}
 
 	// Set pagination parameters
-	if args.Limit > 0 {
-		limit := int32(args.Limit)
-		apiParams.Limit = &limit
-	}
+	apiParams.Limit = s.limitParam("list_budget_transactions", args.Limit)
 
 	if args.Page > 0 {
 		page := int32(args.Page)
 		apiParams.Page = &page
 	}
 
-	// Parse optional start date
-	if args.Start != "" {
-		startDate, err := time.Parse("2006-01-02", args.Start)
-		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: fmt.Sprintf("Invalid start date format: %v", err)},
-				},
-				IsError: true,
-			}, nil, nil
-		}
-		date := openapi_types.Date{Time: startDate}
-		apiParams.Start = &date
+	dateRange, err := s.resolveFiscalPeriod(ctx, req, args.DateRange)
+	if err != nil {
+		return newErrorResult(err.Error())
 	}
-
-	// Parse optional end date
-	if args.End != "" {
-		endDate, err := time.Parse("2006-01-02", args.End)
-		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: fmt.Sprintf("Invalid end date format: %v", err)},
-				},
-				IsError: true,
-			}, nil, nil
-		}
-		date := openapi_types.Date{Time: endDate}
-		apiParams.End = &date
+	dates, err := s.resolveDateRange(dateRange, dateRangeOpen)
+	if err != nil {
+		return newErrorResult(err.Error())
 	}
+	apiParams.Start = dates.Start
+	apiParams.End = dates.End
 
 	// Set transaction type filter if provided
 	if args.Type != "" {
@@ -1707,7 +2305,7 @@ func (s *FireflyMCPServer) handleListBudgetTransactions(
 
 	// Map response to DTO
 	transactionList := mapTransactionArrayToTransactionList(resp.ApplicationvndApiJSON200)
-	return newSuccessResult(transactionList)
+	return newTransactionListResult(transactionList, args.Compact)
 }
 
 // mapInsightGroupToDTO converts client.InsightGroup to InsightCategoryResponse DTO
@@ -1732,10 +2330,39 @@ func mapInsightGroupToDTO(group *client.InsightGroup) *InsightCategoryResponse {
 		}
 		response.Entries = append(response.Entries, categoryEntry)
 	}
+	addInsightShares(response)
 
 	return response
 }
 
+// addInsightShares sums the entries per currency and sets every entry's share
+// of its currency total, so percentages don't have to be computed by the caller
+func addInsightShares(response *InsightCategoryResponse) {
+	totals := make(map[string]decimal)
+	var order []string
+	for _, entry := range response.Entries {
+		amount, ok := parseDecimal(entry.Amount)
+		if !ok {
+			continue
+		}
+		if _, seen := totals[entry.CurrencyCode]; !seen {
+			order = append(order, entry.CurrencyCode)
+		}
+		totals[entry.CurrencyCode] = totals[entry.CurrencyCode].Add(amount)
+	}
+	for i, entry := range response.Entries {
+		if amount, ok := parseDecimal(entry.Amount); ok {
+			response.Entries[i].Share = formatShare(amount, totals[entry.CurrencyCode])
+		}
+	}
+	for _, currencyCode := range order {
+		response.Totals = append(response.Totals, InsightTotalEntry{
+			Amount:       formatAmount(totals[currencyCode]),
+			CurrencyCode: currencyCode,
+		})
+	}
+}
+
 // mapInsightTotalToDTO converts client.InsightTotal to InsightTotalResponse DTO
 func mapInsightTotalToDTO(total *client.InsightTotal) *InsightTotalResponse {
 	if total == nil {
@@ -1779,6 +2406,7 @@ func mapBudgetLimitArrayToBudgetLimitList(budgetLimitArray *client.BudgetLimitAr
 			BudgetId:       getStringValue(budgetLimitRead.Attributes.BudgetId),
 			CurrencyCode:   getStringValue(budgetLimitRead.Attributes.CurrencyCode),
 			CurrencySymbol: getStringValue(budgetLimitRead.Attributes.CurrencySymbol),
+			Notes:          budgetLimitRead.Attributes.Notes,
 			Spent:          make([]BudgetSpent, 0),
 		}
 
@@ -1898,44 +2526,19 @@ func (s *FireflyMCPServer) handleListBills(
 	apiParams := &client.ListBillParams{}
 
 	// Set pagination
-	if args.Limit > 0 {
-		limit := int32(args.Limit)
-		apiParams.Limit = &limit
-	}
+	apiParams.Limit = s.limitParam("list_bills", args.Limit)
 	page := int32(args.Page)
 	if page == 0 {
 		page = 1
 	}
 	apiParams.Page = &page
 
-	// Set date filters if provided
-	if args.Start != "" {
-		startDate, err := time.Parse("2006-01-02", args.Start)
-		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: fmt.Sprintf("Invalid start date format: %v", err)},
-				},
-				IsError: true,
-			}, nil, nil
-		}
-		date := openapi_types.Date{Time: startDate}
-		apiParams.Start = &date
-	}
-
-	if args.End != "" {
-		endDate, err := time.Parse("2006-01-02", args.End)
-		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: fmt.Sprintf("Invalid end date format: %v", err)},
-				},
-				IsError: true,
-			}, nil, nil
-		}
-		date := openapi_types.Date{Time: endDate}
-		apiParams.End = &date
+	dates, err := s.resolveDateRange(args.DateRange, dateRangeOpen)
+	if err != nil {
+		return newErrorResult(err.Error())
 	}
+	apiParams.Start = dates.Start
+	apiParams.End = dates.End
 
 	// Call the API
 	resp, err := apiClient.ListBillWithResponse(ctx, apiParams)
@@ -1951,7 +2554,7 @@ func (s *FireflyMCPServer) handleListBills(
 	if resp.StatusCode() != 200 {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
-				&mcp.TextContent{Text: fmt.Sprintf("API error: %s", string(resp.Body))},
+				&mcp.TextContent{Text: fmt.Sprintf("API error: %s", s.upstreamError(resp.Body))},
 			},
 			IsError: true,
 		}, nil, nil
@@ -1976,34 +2579,12 @@ func (s *FireflyMCPServer) handleGetBill(
 	// Prepare API parameters
 	apiParams := &client.GetBillParams{}
 
-	// Set date filters if provided
-	if args.Start != "" {
-		startDate, err := time.Parse("2006-01-02", args.Start)
-		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: fmt.Sprintf("Invalid start date format: %v", err)},
-				},
-				IsError: true,
-			}, nil, nil
-		}
-		date := openapi_types.Date{Time: startDate}
-		apiParams.Start = &date
-	}
-
-	if args.End != "" {
-		endDate, err := time.Parse("2006-01-02", args.End)
-		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: fmt.Sprintf("Invalid end date format: %v", err)},
-				},
-				IsError: true,
-			}, nil, nil
-		}
-		date := openapi_types.Date{Time: endDate}
-		apiParams.End = &date
+	dates, err := s.resolveDateRange(args.DateRange, dateRangeOpen)
+	if err != nil {
+		return newErrorResult(err.Error())
 	}
+	apiParams.Start = dates.Start
+	apiParams.End = dates.End
 
 	// Call the API
 	resp, err := apiClient.GetBillWithResponse(ctx, args.ID, apiParams)
@@ -2019,7 +2600,7 @@ func (s *FireflyMCPServer) handleGetBill(
 	if resp.StatusCode() != 200 {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
-				&mcp.TextContent{Text: fmt.Sprintf("API error: %s", string(resp.Body))},
+				&mcp.TextContent{Text: fmt.Sprintf("API error: %s", s.upstreamError(resp.Body))},
 			},
 			IsError: true,
 		}, nil, nil
@@ -2048,44 +2629,19 @@ func (s *FireflyMCPServer) handleListBillTransactions(
 	apiParams := &client.ListTransactionByBillParams{}
 
 	// Set pagination
-	if args.Limit > 0 {
-		limit := int32(args.Limit)
-		apiParams.Limit = &limit
-	}
+	apiParams.Limit = s.limitParam("list_bill_transactions", args.Limit)
 	page := int32(args.Page)
 	if page == 0 {
 		page = 1
 	}
 	apiParams.Page = &page
 
-	// Set date filters if provided
-	if args.Start != "" {
-		startDate, err := time.Parse("2006-01-02", args.Start)
-		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: fmt.Sprintf("Invalid start date format: %v", err)},
-				},
-				IsError: true,
-			}, nil, nil
-		}
-		date := openapi_types.Date{Time: startDate}
-		apiParams.Start = &date
-	}
-
-	if args.End != "" {
-		endDate, err := time.Parse("2006-01-02", args.End)
-		if err != nil {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{Text: fmt.Sprintf("Invalid end date format: %v", err)},
-				},
-				IsError: true,
-			}, nil, nil
-		}
-		date := openapi_types.Date{Time: endDate}
-		apiParams.End = &date
+	dates, err := s.resolveDateRange(args.DateRange, dateRangeOpen)
+	if err != nil {
+		return newErrorResult(err.Error())
 	}
+	apiParams.Start = dates.Start
+	apiParams.End = dates.End
 
 	// Set transaction type filter if provided
 	if args.Type != "" {
@@ -2107,7 +2663,7 @@ func (s *FireflyMCPServer) handleListBillTransactions(
 	if resp.StatusCode() != 200 {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
-				&mcp.TextContent{Text: fmt.Sprintf("API error: %s", string(resp.Body))},
+				&mcp.TextContent{Text: fmt.Sprintf("API error: %s", s.upstreamError(resp.Body))},
 			},
 			IsError: true,
 		}, nil, nil
@@ -2115,7 +2671,7 @@ func (s *FireflyMCPServer) handleListBillTransactions(
 
 	// Map the response
 	transactionList := mapTransactionArrayToTransactionList(resp.ApplicationvndApiJSON200)
-	return newSuccessResult(transactionList)
+	return newTransactionListResult(transactionList, args.Compact)
 }
 
 // getAccountTypeValue safely extracts AccountTypeProperty value, returns empty string if nil
@@ -2134,6 +2690,15 @@ func getIntValue(ptr *int) int {
 	return *ptr
 }
 
+// getInt32AsIntPtr converts an optional int32 to an optional int
+func getInt32AsIntPtr(ptr *int32) *int {
+	if ptr == nil {
+		return nil
+	}
+	value := int(*ptr)
+	return &value
+}
+
 // getStringValue safely extracts string value from pointer, returns empty string if nil
 func getStringValue(ptr *string) string {
 	if ptr == nil {
@@ -0,0 +1,83 @@
+package fireflyMCP
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrServerUnreachable is returned by CheckServerURL when the Firefly III server
+// could not be contacted at all (DNS, connection or timeout errors) or answered
+// with a server error or another unexpected status. Callers may treat it as a
+// warning, since the server might simply not be up yet.
+var ErrServerUnreachable = errors.New("firefly III server unreachable")
+
+// ServerInfo holds the Firefly III instance details reported by /v1/about
+type ServerInfo struct {
+	Version    string `json:"version"`
+	ApiVersion string `json:"api_version"`
+}
+
+// CheckServerURL verifies that server.url points at a Firefly III API by calling
+// GET /v1/about. A wrong base path (e.g. a subdirectory install configured without
+// its subdirectory) or a URL pointing at the web UI is reported with a hint on how
+// to fix server.url. The returned ServerInfo is nil when the API answered but the
+// request was not authenticated (no token configured, as in HTTP mode).
+func CheckServerURL(ctx context.Context, config *Config) (*ServerInfo, error) {
+	timeout := time.Duration(config.Client.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	apiClient, err := newAPIClient(config.Server.URL, &http.Client{Timeout: timeout}, config.API.Token, config.Client.AcceptLanguage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+
+	resp, err := apiClient.GetAboutWithResponse(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w at %s: %v", ErrServerUnreachable, config.Server.URL, err)
+	}
+
+	aboutURL := strings.TrimSuffix(config.Server.URL, "/") + "/v1/about"
+	contentType := resp.HTTPResponse.Header.Get("Content-Type")
+
+	switch resp.StatusCode() {
+	case http.StatusOK:
+		if !strings.Contains(contentType, "json") || resp.JSON200 == nil {
+			return nil, fmt.Errorf(
+				"%s returned %q instead of JSON: server.url points at the Firefly III web interface, not the API "+
+					"(for subdirectory installs use e.g. https://example.com/firefly/api)",
+				aboutURL, contentType,
+			)
+		}
+		info := &ServerInfo{}
+		if data := resp.JSON200.Data; data != nil {
+			if data.Version != nil {
+				info.Version = *data.Version
+			}
+			if data.ApiVersion != nil {
+				info.ApiVersion = *data.ApiVersion
+			}
+		}
+		return info, nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		// The API path is correct, only the credentials were rejected
+		if config.API.Token != "" {
+			return nil, fmt.Errorf("%s rejected the configured API token (status %d)", aboutURL, resp.StatusCode())
+		}
+		return nil, nil
+	case http.StatusNotFound:
+		return nil, fmt.Errorf(
+			"%s was not found (status 404): check that server.url includes the subdirectory "+
+				"Firefly III is installed under (e.g. https://example.com/firefly/api)",
+			aboutURL,
+		)
+	default:
+		// 5xx and the like say nothing about server.url, e.g. a database still starting
+		return nil, fmt.Errorf("%w: %s returned unexpected status %d", ErrServerUnreachable, aboutURL, resp.StatusCode())
+	}
+}
@@ -0,0 +1,113 @@
+package fireflyMCP
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCheckConfig(url, token string) *Config {
+	config := &Config{}
+	config.Server.URL = url
+	config.API.Token = token
+	config.Client.Timeout = 5
+	return config
+}
+
+func TestCheckServerURL(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/firefly/api/v1/about", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer good-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		_, _ = w.Write([]byte(`{"data":{"version":"6.1.0","api_version":"2.1.0"}}`))
+	})
+	mux.HandleFunc("/ui/api/v1/about", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=UTF-8")
+		_, _ = w.Write([]byte("<html>login</html>"))
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	t.Run("valid subdirectory URL", func(t *testing.T) {
+		info, err := CheckServerURL(context.Background(), newCheckConfig(ts.URL+"/firefly/api", "good-token"))
+		require.NoError(t, err)
+		require.NotNil(t, info)
+		assert.Equal(t, "6.1.0", info.Version)
+		assert.Equal(t, "2.1.0", info.ApiVersion)
+	})
+
+	t.Run("missing subdirectory", func(t *testing.T) {
+		_, err := CheckServerURL(context.Background(), newCheckConfig(ts.URL+"/api", "good-token"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "404")
+		assert.Contains(t, err.Error(), "subdirectory")
+	})
+
+	t.Run("web UI instead of API", func(t *testing.T) {
+		_, err := CheckServerURL(context.Background(), newCheckConfig(ts.URL+"/ui/api", "good-token"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "web interface")
+	})
+
+	t.Run("no token", func(t *testing.T) {
+		info, err := CheckServerURL(context.Background(), newCheckConfig(ts.URL+"/firefly/api", ""))
+		require.NoError(t, err)
+		assert.Nil(t, info)
+	})
+
+	t.Run("rejected token", func(t *testing.T) {
+		_, err := CheckServerURL(context.Background(), newCheckConfig(ts.URL+"/firefly/api", "bad-token"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "rejected")
+	})
+}
+
+func TestCheckServerURLUnreachable(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	url := ts.URL
+	ts.Close()
+
+	_, err := CheckServerURL(context.Background(), newCheckConfig(url+"/api", "token"))
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrServerUnreachable))
+}
+
+func TestCheckServerURLServerError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	_, err := CheckServerURL(context.Background(), newCheckConfig(ts.URL, "token"))
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrServerUnreachable), "server errors are not a misconfiguration")
+	assert.Contains(t, err.Error(), "503")
+}
+
+func TestAPIClientAcceptLanguage(t *testing.T) {
+	var language string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		language = r.Header.Get("Accept-Language")
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		_, _ = w.Write([]byte(`{"data":{"version":"6.1.0","api_version":"2.1.0"}}`))
+	}))
+	defer ts.Close()
+
+	config := newCheckConfig(ts.URL, "token")
+	_, err := CheckServerURL(context.Background(), config)
+	require.NoError(t, err)
+	assert.Empty(t, language, "no Accept-Language unless configured")
+
+	config.Client.AcceptLanguage = "de-DE"
+	_, err = CheckServerURL(context.Background(), config)
+	require.NoError(t, err)
+	assert.Equal(t, "de-DE", language)
+}
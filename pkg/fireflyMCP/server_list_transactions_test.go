@@ -0,0 +1,65 @@
+package fireflyMCP
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionFilterQuery(t *testing.T) {
+	server, err := NewFireflyMCPServer(newPluginTestConfig())
+	require.NoError(t, err)
+	dates, err := server.resolveDateRange(DateRange{Start: "2024-01-01", End: "2024-01-31"}, dateRangeOpen)
+	require.NoError(t, err)
+
+	assert.Equal(t, "", transactionFilterQuery("", resolvedDateRange{}))
+	assert.Equal(t, "", transactionFilterQuery("all", resolvedDateRange{}))
+	assert.Equal(t,
+		"type:withdrawal date_after:2024-01-01 date_before:2024-01-31",
+		transactionFilterQuery("withdrawal", dates),
+	)
+}
+
+func TestReconciledFilter(t *testing.T) {
+	var paths, queries []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		queries = append(queries, r.URL.Query().Get("query"))
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		w.Write([]byte(`{"data":[],"meta":{"pagination":{"total":0,"count":0,"per_page":50,"current_page":1,"total_pages":1}}}`))
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	unreconciled := false
+	result, _, err := server.handleListTransactions(context.Background(), nil, ListTransactionsArgs{
+		DateRange:  DateRange{Start: "2024-01-01", End: "2024-01-31"},
+		Reconciled: &unreconciled,
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content[0].(*mcp.TextContent).Text)
+
+	result, _, err = server.handleSearchTransactions(context.Background(), nil, SearchTransactionsArgs{
+		Query:      "groceries",
+		Reconciled: ptr(true),
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content[0].(*mcp.TextContent).Text)
+
+	result, _, err = server.handleListTransactions(context.Background(), nil, ListTransactionsArgs{})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	assert.Equal(t, []string{"/v1/search/transactions", "/v1/search/transactions", "/v1/transactions"}, paths)
+	assert.Equal(t, "date_after:2024-01-01 date_before:2024-01-31 reconciled:false", queries[0])
+	assert.Equal(t, "groceries reconciled:true", queries[1])
+}
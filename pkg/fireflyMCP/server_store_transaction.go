@@ -28,6 +28,17 @@ func (s *FireflyMCPServer) handleStoreTransaction(
 		}, nil, nil
 	}
 
+	// Resolve relative dates ("today", "yesterday") in the configured timezone
+	args.Transactions = s.resolveSplitDates(args.Transactions)
+
+	if args.Member != "" {
+		tag, err := s.memberTag(args.Member)
+		if err != nil {
+			return newErrorResult(fmt.Sprintf("Error: %v", err))
+		}
+		args.Transactions = addSplitTag(args.Transactions, tag)
+	}
+
 	// Validate each transaction
 	for i, txn := range args.Transactions {
 		// Validate required fields
@@ -103,8 +114,11 @@ func (s *FireflyMCPServer) handleStoreTransaction(
 		}
 	}
 
-	// Convert DTO to API model
-	apiRequest := mapTransactionStoreRequestToAPI(&args)
+	// Resolve configured account aliases before handing names to Firefly III
+	args.Transactions = s.applyAccountAliases(args.Transactions)
+	if err := s.checkSplitIDs(args.Transactions); err != nil {
+		return newErrorResult(fmt.Sprintf("Error: %v", err))
+	}
 
 	// Get API client
 	apiClient, err := s.getClient(ctx, req)
@@ -112,6 +126,25 @@ func (s *FireflyMCPServer) handleStoreTransaction(
 		return newErrorResult(fmt.Sprintf("Failed to get API client: %v", err))
 	}
 
+	// Transfers must stay between asset accounts; names are resolved to IDs
+	args.Transactions, err = s.resolveTransferAccounts(ctx, req, apiClient, args.Transactions)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error: %v", err))
+	}
+	if !s.allowAccountAutocreate(args.AllowAccountAutocreate) {
+		args.Transactions, err = s.resolveCounterpartyAccounts(ctx, req, apiClient, args.Transactions)
+		if err != nil {
+			return newErrorResult(fmt.Sprintf("Error: %v (set allow_account_autocreate to create it)", err))
+		}
+	}
+
+	// Fill in missing currencies from the accounts, a frequent cause of 422s
+	var warnings []string
+	args.Transactions, warnings = s.fillSplitCurrencies(ctx, req, apiClient, args.Transactions)
+
+	// Convert DTO to API model
+	apiRequest := mapTransactionStoreRequestToAPI(&args)
+
 	// Call the API
 	resp, err := apiClient.StoreTransactionWithResponse(ctx, &client.StoreTransactionParams{}, *apiRequest)
 	if err != nil {
@@ -126,10 +159,7 @@ func (s *FireflyMCPServer) handleStoreTransaction(
 	// Debug: Check content type
 	contentType := resp.HTTPResponse.Header.Get("Content-Type")
 	if !strings.Contains(contentType, "application/json") && !strings.Contains(contentType, "application/vnd.api+json") {
-		bodyPreview := string(resp.Body)
-		if len(bodyPreview) > 500 {
-			bodyPreview = bodyPreview[:500] + "..."
-		}
+		bodyPreview := s.upstreamError(resp.Body)
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
 				&mcp.TextContent{Text: fmt.Sprintf("Error: Expected JSON response but got %s (status: %d, body preview: %s)",
@@ -163,11 +193,7 @@ func (s *FireflyMCPServer) handleStoreTransaction(
 
 			// Try to parse the response body
 			if err := json.Unmarshal(resp.Body, &transactionSingle); err != nil {
-				// Debug: Log the body content for debugging
-				bodyPreview := string(resp.Body)
-				if len(bodyPreview) > 200 {
-					bodyPreview = bodyPreview[:200] + "..."
-				}
+				bodyPreview := s.upstreamError(resp.Body)
 				return &mcp.CallToolResult{
 					Content: []mcp.Content{
 						&mcp.TextContent{Text: fmt.Sprintf("Error parsing response (status %d): %v (body: %s)",
@@ -179,6 +205,8 @@ func (s *FireflyMCPServer) handleStoreTransaction(
 		}
 
 		transactionGroup := mapTransactionReadToTransactionGroup(&transactionSingle.Data)
+		s.recordTransactionChange(req, transactionGroup.Id, "store_transaction", "created",
+			args.GroupTitle, args.Transactions)
 
 		// Marshal to JSON
 		jsonData, err := json.MarshalIndent(transactionGroup, "", "  ")
@@ -191,11 +219,15 @@ func (s *FireflyMCPServer) handleStoreTransaction(
 			}, nil, nil
 		}
 
-		return &mcp.CallToolResult{
+		result := &mcp.CallToolResult{
 			Content: []mcp.Content{
 				&mcp.TextContent{Text: string(jsonData)},
 			},
-		}, nil, nil
+		}
+		if len(warnings) > 0 {
+			result.Meta = mcp.Meta{"warnings": warnings}
+		}
+		return result, nil, nil
 
 	case 422:
 		// Validation error
@@ -203,6 +235,9 @@ func (s *FireflyMCPServer) handleStoreTransaction(
 		if resp.JSON422 != nil && resp.JSON422.Message != nil {
 			errorMsg = *resp.JSON422.Message
 		}
+		if len(warnings) > 0 {
+			errorMsg += " (" + strings.Join(warnings, "; ") + ")"
+		}
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
 				&mcp.TextContent{Text: fmt.Sprintf("Validation error: %s", errorMsg)},
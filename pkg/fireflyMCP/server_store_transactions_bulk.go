@@ -11,8 +11,10 @@ import (
 
 // BulkTransactionStoreRequest represents the request for creating multiple transaction groups
 type BulkTransactionStoreRequest struct {
-	TransactionGroups []TransactionStoreRequest `json:"transaction_groups" jsonschema:"Array of transaction groups to create (required, at least one)"`
+	TransactionGroups []TransactionStoreRequest `json:"transaction_groups,omitempty" jsonschema:"Array of transaction groups to create (required, at least one, unless file is set)"`
+	File              string                    `json:"file,omitempty" jsonschema:"file:// URI or absolute path of a JSON file inside a root granted by the client, holding the transaction groups (stdio mode only)"`
 	DelayMs           int                       `json:"delay_ms,omitempty" jsonschema:"Delay in milliseconds between API calls to avoid rate limiting (default: 100)"`
+	Async             bool                      `json:"async,omitempty" jsonschema:"Return immediately with the URI of a progress resource (firefly://imports/{id}) instead of waiting for all groups to be stored"`
 }
 
 // BulkTransactionStoreResponse represents the response for bulk transaction creation
@@ -42,6 +44,17 @@ func (s *FireflyMCPServer) handleStoreTransactionsBulk(
 	req *mcp.CallToolRequest,
 	args BulkTransactionStoreRequest,
 ) (*mcp.CallToolResult, any, error) {
+	if args.File != "" {
+		if len(args.TransactionGroups) > 0 {
+			return newErrorResult("Error: set either transaction_groups or file, not both")
+		}
+		groups, err := s.readTransactionGroupsFile(ctx, req, args.File)
+		if err != nil {
+			return newErrorResult(fmt.Sprintf("Error: failed to read file: %v", err))
+		}
+		args.TransactionGroups = groups
+	}
+
 	// Validate input
 	if len(args.TransactionGroups) == 0 {
 		return &mcp.CallToolResult{
@@ -71,6 +84,18 @@ func (s *FireflyMCPServer) handleStoreTransactionsBulk(
 		delayMs = 100 // Default 100ms delay between API calls
 	}
 
+	if args.Async {
+		return s.startBulkImport(req, args.TransactionGroups, delayMs)
+	}
+
+	batch, err := newResourceID()
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Failed to start batch: %v", err))
+	}
+	if err := s.journal.begin(batch, s.tenantKey(req), "store_transactions_bulk", args.TransactionGroups); err != nil {
+		return newErrorResult(fmt.Sprintf("Error: failed to write journal: %v", err))
+	}
+
 	// Initialize response
 	response := BulkTransactionStoreResponse{
 		Results: make([]TransactionGroupResult, 0, len(args.TransactionGroups)),
@@ -88,46 +113,11 @@ func (s *FireflyMCPServer) handleStoreTransactionsBulk(
 			time.Sleep(time.Duration(delayMs) * time.Millisecond)
 		}
 
-		// Create individual result
-		result := TransactionGroupResult{
-			Index: i,
-		}
-
-		// Execute the single transaction creation
-		singleResult, _, err := s.handleStoreTransaction(ctx, req, group)
-
-		// Process the result
-		if err != nil {
-			// Actual error in handler (rare, as handler returns errors in result)
-			result.Success = false
-			result.Error = fmt.Sprintf("Internal error: %v", err)
-			response.Summary.Failed++
-		} else if singleResult.IsError {
-			// Transaction creation failed
-			result.Success = false
-			// Extract error message from content
-			if len(singleResult.Content) > 0 {
-				if textContent, ok := singleResult.Content[0].(*mcp.TextContent); ok {
-					result.Error = textContent.Text
-				} else {
-					result.Error = "Unknown error occurred"
-				}
-			}
-			response.Summary.Failed++
-		} else {
-			// Transaction creation succeeded
-			result.Success = true
+		result := s.storeJournaledGroup(ctx, req, batch, i, group)
+		if result.Success {
 			response.Summary.Successful++
-
-			// Parse the transaction group from the response
-			if len(singleResult.Content) > 0 {
-				if textContent, ok := singleResult.Content[0].(*mcp.TextContent); ok {
-					var txGroup TransactionGroup
-					if err := json.Unmarshal([]byte(textContent.Text), &txGroup); err == nil {
-						result.TransactionGroup = &txGroup
-					}
-				}
-			}
+		} else {
+			response.Summary.Failed++
 		}
 
 		response.Results = append(response.Results, result)
@@ -143,6 +133,8 @@ func (s *FireflyMCPServer) handleStoreTransactionsBulk(
 		}
 	}
 
+	s.finishJournaledBatch(batch)
+
 	// Marshal response to JSON
 	jsonData, err := json.MarshalIndent(response, "", "  ")
 	if err != nil {
@@ -165,3 +157,53 @@ func (s *FireflyMCPServer) handleStoreTransactionsBulk(
 		IsError: isError,
 	}, nil, nil
 }
+
+// storeTransactionGroup creates a single transaction group of a bulk request and
+// reports the outcome as a TransactionGroupResult
+func (s *FireflyMCPServer) storeTransactionGroup(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	index int,
+	group TransactionStoreRequest,
+) TransactionGroupResult {
+	// Create individual result
+	result := TransactionGroupResult{
+		Index: index,
+	}
+
+	// Execute the single transaction creation
+	singleResult, _, err := s.handleStoreTransaction(ctx, req, group)
+
+	// Process the result
+	if err != nil {
+		// Actual error in handler (rare, as handler returns errors in result)
+		result.Success = false
+		result.Error = fmt.Sprintf("Internal error: %v", err)
+	} else if singleResult.IsError {
+		// Transaction creation failed
+		result.Success = false
+		// Extract error message from content
+		if len(singleResult.Content) > 0 {
+			if textContent, ok := singleResult.Content[0].(*mcp.TextContent); ok {
+				result.Error = textContent.Text
+			} else {
+				result.Error = "Unknown error occurred"
+			}
+		}
+	} else {
+		// Transaction creation succeeded
+		result.Success = true
+
+		// Parse the transaction group from the response
+		if len(singleResult.Content) > 0 {
+			if textContent, ok := singleResult.Content[0].(*mcp.TextContent); ok {
+				var txGroup TransactionGroup
+				if err := json.Unmarshal([]byte(textContent.Text), &txGroup); err == nil {
+					result.TransactionGroup = &txGroup
+				}
+			}
+		}
+	}
+
+	return result
+}
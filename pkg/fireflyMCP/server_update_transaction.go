@@ -22,29 +22,26 @@ func (s *FireflyMCPServer) handleUpdateTransaction(
 		return newErrorResult("Error: transaction ID is required")
 	}
 
+	if len(args.Patch) > 0 {
+		return s.patchTransaction(ctx, req, args)
+	}
+
+	// Resolve relative dates ("today", "yesterday") in the configured timezone
+	args.Transactions = s.resolveSplitDates(args.Transactions)
+
 	// Validate each transaction if provided
+	journalIds := make(map[string]int)
 	for i, txn := range args.Transactions {
-		// Validate transaction type if provided
-		if txn.Type != "" {
-			validTypes := map[string]bool{
-				"withdrawal": true,
-				"deposit":    true,
-				"transfer":   true,
-			}
-			if !validTypes[txn.Type] {
+		if txn.JournalId != nil {
+			if first, ok := journalIds[*txn.JournalId]; ok {
 				return newErrorResult(fmt.Sprintf(
-					"Error: transaction[%d].type must be one of: withdrawal, deposit, transfer", i))
+					"Error: transaction[%d].journal_id %s is also used by transaction[%d]", i, *txn.JournalId, first))
 			}
+			journalIds[*txn.JournalId] = i
 		}
 
-		// Validate date format if provided
-		if txn.Date != "" {
-			if _, err := time.Parse("2006-01-02", txn.Date); err != nil {
-				if _, err := time.Parse(time.RFC3339, txn.Date); err != nil {
-					return newErrorResult(fmt.Sprintf(
-						"Error: transaction[%d].date must be in format YYYY-MM-DD or RFC3339", i))
-				}
-			}
+		if err := validateSplitUpdate(txn); err != nil {
+			return newErrorResult(fmt.Sprintf("Error: transaction[%d].%v", i, err))
 		}
 	}
 
@@ -54,6 +51,12 @@ func (s *FireflyMCPServer) handleUpdateTransaction(
 		return newErrorResult(fmt.Sprintf("Failed to get API client: %v", err))
 	}
 
+	// Resolve configured account aliases before handing names to Firefly III
+	args.Transactions = s.applyAccountAliases(args.Transactions)
+	if err := s.checkSplitIDs(args.Transactions); err != nil {
+		return newErrorResult(fmt.Sprintf("Error: %v", err))
+	}
+
 	// Convert DTO to API model
 	apiRequest := mapTransactionUpdateRequestToAPI(&args.TransactionUpdateRequest)
 
@@ -63,15 +66,17 @@ func (s *FireflyMCPServer) handleUpdateTransaction(
 		return newErrorResult(fmt.Sprintf("Error updating transaction: %v", err))
 	}
 
+	// An update answered without a body (e.g. 204) still succeeded
+	if isSuccessStatus(resp.StatusCode()) && isEmptyBody(resp.StatusCode(), resp.Body) {
+		s.recordTransactionChange(req, args.ID, "update_transaction", "updated", args.GroupTitle, args.Transactions)
+		return newSuccessResult(updatedResult(args.ID))
+	}
+
 	// Check content type
 	contentType := resp.HTTPResponse.Header.Get("Content-Type")
 	if !strings.Contains(contentType, "application/json") && !strings.Contains(contentType, "application/vnd.api+json") {
-		bodyPreview := string(resp.Body)
-		if len(bodyPreview) > 500 {
-			bodyPreview = bodyPreview[:500] + "..."
-		}
-		return newErrorResult(fmt.Sprintf("Error: Expected JSON response but got %s (status: %d)",
-			contentType, resp.StatusCode()))
+		return newErrorResult(fmt.Sprintf("Error: Expected JSON response but got %s (status: %d, body preview: %s)",
+			contentType, resp.StatusCode(), s.upstreamError(resp.Body)))
 	}
 
 	// Handle response codes
@@ -82,20 +87,14 @@ func (s *FireflyMCPServer) handleUpdateTransaction(
 		if resp.ApplicationvndApiJSON200 != nil {
 			transactionSingle = *resp.ApplicationvndApiJSON200
 		} else {
-			if len(resp.Body) == 0 {
-				return newErrorResult(fmt.Sprintf("Error: empty response body for status %d", resp.StatusCode()))
-			}
-
 			if err := json.Unmarshal(resp.Body, &transactionSingle); err != nil {
-				bodyPreview := string(resp.Body)
-				if len(bodyPreview) > 200 {
-					bodyPreview = bodyPreview[:200] + "..."
-				}
-				return newErrorResult(fmt.Sprintf("Error parsing response: %v (body: %s)", err, bodyPreview))
+				return newErrorResult(fmt.Sprintf("Error parsing response: %v (body: %s)", err, s.upstreamError(resp.Body)))
 			}
 		}
 
 		transactionGroup := mapTransactionReadToTransactionGroup(&transactionSingle.Data)
+		s.recordTransactionChange(req, transactionGroup.Id, "update_transaction", "updated",
+			args.GroupTitle, args.Transactions)
 		return newSuccessResult(transactionGroup)
 
 	case 404:
@@ -116,6 +115,29 @@ func (s *FireflyMCPServer) handleUpdateTransaction(
 	}
 }
 
+// validateSplitUpdate checks the type and date of a split update if they are set
+func validateSplitUpdate(txn TransactionSplitRequest) error {
+	if txn.Type != "" {
+		validTypes := map[string]bool{
+			"withdrawal": true,
+			"deposit":    true,
+			"transfer":   true,
+		}
+		if !validTypes[txn.Type] {
+			return fmt.Errorf("type must be one of: withdrawal, deposit, transfer")
+		}
+	}
+
+	if txn.Date != "" {
+		if _, err := time.Parse("2006-01-02", txn.Date); err != nil {
+			if _, err := time.Parse(time.RFC3339, txn.Date); err != nil {
+				return fmt.Errorf("date must be in format YYYY-MM-DD or RFC3339")
+			}
+		}
+	}
+	return nil
+}
+
 // mapTransactionUpdateRequestToAPI converts DTO to API model for update
 func mapTransactionUpdateRequestToAPI(req *TransactionUpdateRequest) *client.UpdateTransactionJSONRequestBody {
 	apiReq := &client.UpdateTransactionJSONRequestBody{}
@@ -203,6 +225,9 @@ func mapTransactionUpdateRequestToAPI(req *TransactionUpdateRequest) *client.Upd
 				apiTxn.Order = &order
 			}
 
+			// Identify the split to change rather than relying on the split position
+			apiTxn.TransactionJournalId = txn.JournalId
+
 			apiTransactions[i] = apiTxn
 		}
 		apiReq.Transactions = &apiTransactions
@@ -1,8 +1,10 @@
 package fireflyMCP
 
 import (
+	"context"
 	"testing"
 
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -53,15 +55,15 @@ func TestMapTransactionUpdateRequestToAPI_WithTransaction(t *testing.T) {
 	req := &TransactionUpdateRequest{
 		Transactions: []TransactionSplitRequest{
 			{
-				Type:            "withdrawal",
-				Date:            "2024-01-15",
-				Amount:          amount,
-				Description:     "Test transaction",
-				SourceId:        &sourceId,
-				DestinationId:   &destId,
-				CategoryName:    &categoryName,
-				Notes:           &notes,
-				Tags:            []string{"tag1", "tag2"},
+				Type:          "withdrawal",
+				Date:          "2024-01-15",
+				Amount:        amount,
+				Description:   "Test transaction",
+				SourceId:      &sourceId,
+				DestinationId: &destId,
+				CategoryName:  &categoryName,
+				Notes:         &notes,
+				Tags:          []string{"tag1", "tag2"},
 			},
 		},
 	}
@@ -129,6 +131,38 @@ func TestMapTransactionUpdateRequestToAPI_WithOrder(t *testing.T) {
 	assert.Equal(t, int32(5), *txn.Order)
 }
 
+func TestMapTransactionUpdateRequestToAPI_WithJournalId(t *testing.T) {
+	journalId := "124"
+	req := &TransactionUpdateRequest{
+		Transactions: []TransactionSplitRequest{
+			{JournalId: &journalId, Amount: "10.00"},
+			{Amount: "5.00"},
+		},
+	}
+
+	result := mapTransactionUpdateRequestToAPI(req)
+
+	assert.NotNil(t, result.Transactions)
+	assert.Equal(t, &journalId, (*result.Transactions)[0].TransactionJournalId)
+	assert.Nil(t, (*result.Transactions)[1].TransactionJournalId)
+}
+
+func TestHandleUpdateTransaction_DuplicateJournalId(t *testing.T) {
+	journalId := "124"
+	server := &FireflyMCPServer{}
+	result, _, err := server.handleUpdateTransaction(context.Background(), nil, UpdateTransactionArgs{
+		ID: "1",
+		TransactionUpdateRequest: TransactionUpdateRequest{
+			Transactions: []TransactionSplitRequest{{JournalId: &journalId}, {JournalId: &journalId}},
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text,
+		"transaction[1].journal_id 124 is also used by transaction[0]")
+}
+
 func TestMapTransactionUpdateRequestToAPI_WithCurrencyFields(t *testing.T) {
 	currencyId := "1"
 	currencyCode := "USD"
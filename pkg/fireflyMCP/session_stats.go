@@ -0,0 +1,196 @@
+package fireflyMCP
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// GetSessionStatsArgs represents the arguments for the get_session_stats tool
+type GetSessionStatsArgs struct{}
+
+// SessionStats counts the tool calls of one MCP session
+type SessionStats struct {
+	SessionId     string           `json:"session_id,omitempty"`
+	StartedAt     string           `json:"started_at"`
+	ToolCalls     int              `json:"tool_calls"`
+	CallsByTool   map[string]int   `json:"calls_by_tool"`
+	Errors        int              `json:"errors"`
+	Writes        int              `json:"writes"` // Successful calls of tools that change data
+	BytesReturned int              `json:"bytes_returned"`
+	RateLimit     *RateLimitBudget `json:"rate_limit,omitempty"` // HTTP mode only
+}
+
+// RateLimitBudget is the HTTP rate limit of the client and what is left of it
+type RateLimitBudget struct {
+	RequestsPerSecond float64 `json:"requests_per_second"`
+	Burst             int     `json:"burst"`
+	Remaining         int     `json:"remaining"` // Requests that can be made immediately
+}
+
+// maxRecentToolCalls is how many of the last tool calls the admin dashboard shows
+const maxRecentToolCalls = 20
+
+// RecentToolCall is a finished tool call, as listed on the admin dashboard
+type RecentToolCall struct {
+	Time       time.Time `json:"time"`
+	SessionId  string    `json:"session_id,omitempty"`
+	Tool       string    `json:"tool"`
+	DurationMs int64     `json:"duration_ms"`
+	Error      bool      `json:"error,omitempty"`
+}
+
+// sessionStatsTracker keeps the statistics of the connected sessions and the
+// last tool calls of all sessions
+type sessionStatsTracker struct {
+	mu       sync.Mutex
+	sessions map[string]*SessionStats
+	recent   []RecentToolCall // Oldest first, at most maxRecentToolCalls
+}
+
+func newSessionStatsTracker() *sessionStatsTracker {
+	return &sessionStatsTracker{sessions: make(map[string]*SessionStats)}
+}
+
+// record adds a finished tool call to the statistics of its session. Statistics
+// of sessions that are no longer connected are dropped when a new session starts.
+func (t *sessionStatsTracker) record(
+	s *FireflyMCPServer,
+	sessionID, tool string,
+	write bool,
+	duration time.Duration,
+	result *mcp.CallToolResult,
+	err error,
+) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	failed := err != nil || result == nil || result.IsError
+	t.recent = append(t.recent, RecentToolCall{
+		Time:       time.Now().UTC(),
+		SessionId:  sessionID,
+		Tool:       tool,
+		DurationMs: duration.Milliseconds(),
+		Error:      failed,
+	})
+	if len(t.recent) > maxRecentToolCalls {
+		t.recent = t.recent[len(t.recent)-maxRecentToolCalls:]
+	}
+
+	stats, ok := t.sessions[sessionID]
+	if !ok {
+		t.forgetClosedSessions(s)
+		stats = &SessionStats{
+			SessionId:   sessionID,
+			StartedAt:   time.Now().UTC().Format(time.RFC3339),
+			CallsByTool: map[string]int{},
+		}
+		t.sessions[sessionID] = stats
+	}
+
+	stats.ToolCalls++
+	stats.CallsByTool[tool]++
+	if failed {
+		stats.Errors++
+	} else if write {
+		stats.Writes++
+	}
+	if result != nil {
+		for _, content := range result.Content {
+			if text, ok := content.(*mcp.TextContent); ok {
+				stats.BytesReturned += len(text.Text)
+			}
+		}
+	}
+}
+
+// forgetClosedSessions drops the statistics of sessions that are no longer connected
+func (t *sessionStatsTracker) forgetClosedSessions(s *FireflyMCPServer) {
+	if s.server == nil {
+		return
+	}
+	connected := map[string]bool{}
+	for session := range s.server.Sessions() {
+		connected[session.ID()] = true
+	}
+	for id := range t.sessions {
+		if !connected[id] {
+			delete(t.sessions, id)
+		}
+	}
+}
+
+// get returns a copy of the statistics of a session
+func (t *sessionStatsTracker) get(sessionID string) SessionStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats, ok := t.sessions[sessionID]
+	if !ok {
+		return SessionStats{SessionId: sessionID, CallsByTool: map[string]int{}}
+	}
+	copied := *stats
+	copied.CallsByTool = make(map[string]int, len(stats.CallsByTool))
+	for tool, calls := range stats.CallsByTool {
+		copied.CallsByTool[tool] = calls
+	}
+	return copied
+}
+
+// recentCalls returns the last tool calls, newest first
+func (t *sessionStatsTracker) recentCalls() []RecentToolCall {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	calls := make([]RecentToolCall, len(t.recent))
+	for i, call := range t.recent {
+		calls[len(t.recent)-1-i] = call
+	}
+	return calls
+}
+
+// sessionID returns the ID of the MCP session of a tool call ("" in stdio mode)
+func sessionID(req *mcp.CallToolRequest) string {
+	if req == nil || req.Session == nil {
+		return ""
+	}
+	return req.Session.ID()
+}
+
+// withSessionStats counts the calls of a tool in the statistics of the calling session
+func withSessionStats[In any](s *FireflyMCPServer, tool *mcp.Tool, handler mcp.ToolHandlerFor[In, any]) mcp.ToolHandlerFor[In, any] {
+	write := tool.Annotations == nil || !tool.Annotations.ReadOnlyHint
+	return func(ctx context.Context, req *mcp.CallToolRequest, args In) (*mcp.CallToolResult, any, error) {
+		start := time.Now()
+		result, out, err := handler(ctx, req, args)
+		if s.sessionStats != nil {
+			s.sessionStats.record(s, sessionID(req), tool.Name, write, time.Since(start), result, err)
+		}
+		return result, out, err
+	}
+}
+
+// handleGetSessionStats returns the statistics of the calling session. The
+// statistics are read before this call is counted.
+func (s *FireflyMCPServer) handleGetSessionStats(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args GetSessionStatsArgs,
+) (*mcp.CallToolResult, any, error) {
+	stats := s.sessionStats.get(sessionID(req))
+
+	config := s.currentConfig()
+	if config != nil && config.HTTP.Enabled && req != nil && req.Extra != nil && req.Extra.Header != nil {
+		if remaining, err := strconv.Atoi(req.Extra.Header.Get(RateLimitRemainingHeader)); err == nil {
+			stats.RateLimit = &RateLimitBudget{
+				RequestsPerSecond: config.HTTP.RateLimit,
+				Burst:             config.HTTP.RateBurst,
+				Remaining:         remaining,
+			}
+		}
+	}
+	return newSuccessResult(stats)
+}
@@ -0,0 +1,77 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionStats(t *testing.T) {
+	config := newPluginTestConfig()
+	config.HTTP.Enabled = true
+	config.HTTP.RateLimit = 10
+	config.HTTP.RateBurst = 20
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	read := withSessionStats(server, &mcp.Tool{Name: "list_tags", Annotations: readOnlyAnnotations()},
+		func(context.Context, *mcp.CallToolRequest, ListTagsArgs) (*mcp.CallToolResult, any, error) {
+			return newSuccessResult([]string{"card"})
+		})
+	write := withSessionStats(server, &mcp.Tool{Name: "delete_rule", Annotations: destructiveAnnotations(true)},
+		func(_ context.Context, _ *mcp.CallToolRequest, args DeleteRuleArgs) (*mcp.CallToolResult, any, error) {
+			if args.ID == "" {
+				return nil, nil, errors.New("id is required")
+			}
+			return newSuccessResult(map[string]string{"status": "deleted"})
+		})
+	returned := 0
+	for _, call := range []func() (*mcp.CallToolResult, any, error){
+		func() (*mcp.CallToolResult, any, error) { return read(ctx, nil, ListTagsArgs{}) },
+		func() (*mcp.CallToolResult, any, error) { return read(ctx, nil, ListTagsArgs{}) },
+		func() (*mcp.CallToolResult, any, error) { return write(ctx, nil, DeleteRuleArgs{ID: "1"}) },
+		func() (*mcp.CallToolResult, any, error) { return write(ctx, nil, DeleteRuleArgs{}) },
+	} {
+		if result, _, _ := call(); result != nil {
+			returned += len(result.Content[0].(*mcp.TextContent).Text)
+		}
+	}
+
+	req := &mcp.CallToolRequest{Extra: &mcp.RequestExtra{Header: http.Header{RateLimitRemainingHeader: {"17"}}}}
+	result, _, err := server.handleGetSessionStats(ctx, req, GetSessionStatsArgs{})
+	require.NoError(t, err)
+	var stats SessionStats
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &stats))
+
+	assert.Equal(t, 4, stats.ToolCalls)
+	assert.Equal(t, map[string]int{"list_tags": 2, "delete_rule": 2}, stats.CallsByTool)
+	assert.Equal(t, 1, stats.Errors)
+	assert.Equal(t, 1, stats.Writes)
+	assert.Equal(t, returned, stats.BytesReturned)
+	assert.Equal(t, &RateLimitBudget{RequestsPerSecond: 10, Burst: 20, Remaining: 17}, stats.RateLimit)
+	assert.NotEmpty(t, stats.StartedAt)
+}
+
+func TestRateLimitMiddleware_PassesRemainingBudget(t *testing.T) {
+	var remaining string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remaining = r.Header.Get(RateLimitRemainingHeader)
+	})
+	wrapped := RateLimitMiddleware(1, 5, testLogger())(handler)
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set(RateLimitRemainingHeader, "1000")
+	rr := httptest.NewRecorder()
+	wrapped.ServeHTTP(rr, req)
+
+	assert.Equal(t, "4", remaining)
+	assert.Equal(t, "4", rr.Header().Get(RateLimitRemainingHeader))
+}
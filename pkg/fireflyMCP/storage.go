@@ -0,0 +1,374 @@
+package fireflyMCP
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.etcd.io/bbolt"
+	_ "modernc.org/sqlite" // Registers the "sqlite" database/sql driver
+)
+
+// Storage backends selectable with storage.backend
+const (
+	StorageMemory = "memory" // Nothing survives a restart
+	StorageBolt   = "bolt"   // A bbolt database file at storage.path
+	StorageSQLite = "sqlite" // An SQLite database file at storage.path
+	StorageRedis  = "redis"  // A Redis server at storage.url, shared by all replicas
+)
+
+// Buckets of the server state kept in Storage
+const (
+	storageBucketTrash   = "trash"   // Trash entries by ID
+	storageBucketChanges = "changes" // Change histories by tenant and transaction group
+	storageBucketJournal = "journal" // Write journal records by sequence number
+	storageBucketResults = "results" // Cached tool results by tenant and call, only in shared storage
+)
+
+// redisKeyPrefix starts the Redis keys of all buckets
+const redisKeyPrefix = "firefly-mcp:"
+
+// ErrStorageNotFound is returned by Storage.Get for keys that do not exist
+var ErrStorageNotFound = errors.New("storage: key not found")
+
+// Storage keeps the server state that may outlive a process or be shared by
+// replicas: the trash, the change histories, the write journal and, in shared
+// storage, cached tool results. Values are opaque bytes stored under
+// a key in a named bucket. Implementations must be safe for concurrent use.
+type Storage interface {
+	Get(bucket, key string) ([]byte, error)
+	Put(bucket, key string, value []byte) error
+	Delete(bucket, key string) error
+	List(bucket string) ([]StorageItem, error) // Sorted by key
+	Close() error
+}
+
+// expiringStorage is implemented by storages that expire values themselves
+type expiringStorage interface {
+	PutTTL(bucket, key string, value []byte, ttl time.Duration) error
+}
+
+// StorageItem is a key and its value
+type StorageItem struct {
+	Key   string
+	Value []byte
+}
+
+// OpenStorage opens the storage backend selected in the configuration
+func OpenStorage(config *Config) (Storage, error) {
+	switch backend := strings.ToLower(config.Storage.Backend); backend {
+	case "", StorageMemory:
+		return newMemoryStorage(), nil
+	case StorageBolt:
+		return openBoltStorage(config.Storage.Path)
+	case StorageSQLite:
+		return openSQLiteStorage(config.Storage.Path)
+	case StorageRedis:
+		return openRedisStorage(config.Storage.URL)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", backend)
+	}
+}
+
+// isDurable reports whether a storage keeps its data across restarts
+func isDurable(storage Storage) bool {
+	_, inMemory := storage.(*memoryStorage)
+	return storage != nil && !inMemory
+}
+
+// isShared reports whether other replicas of the server use the same storage,
+// so state kept in it must be read from it rather than from memory
+func isShared(storage Storage) bool {
+	_, shared := storage.(*redisStorage)
+	return shared
+}
+
+// sharedStorage returns the storage if it is shared, nil otherwise
+func sharedStorage(storage Storage) Storage {
+	if isShared(storage) {
+		return storage
+	}
+	return nil
+}
+
+// memoryStorage keeps everything in maps
+type memoryStorage struct {
+	mu      sync.Mutex
+	buckets map[string]map[string][]byte
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{buckets: make(map[string]map[string][]byte)}
+}
+
+func (m *memoryStorage) Get(bucket, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	value, ok := m.buckets[bucket][key]
+	if !ok {
+		return nil, ErrStorageNotFound
+	}
+	return append([]byte(nil), value...), nil
+}
+
+func (m *memoryStorage) Put(bucket, key string, value []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.buckets[bucket] == nil {
+		m.buckets[bucket] = make(map[string][]byte)
+	}
+	m.buckets[bucket][key] = append([]byte(nil), value...)
+	return nil
+}
+
+func (m *memoryStorage) Delete(bucket, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.buckets[bucket], key)
+	return nil
+}
+
+func (m *memoryStorage) List(bucket string) ([]StorageItem, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	items := make([]StorageItem, 0, len(m.buckets[bucket]))
+	for key, value := range m.buckets[bucket] {
+		items = append(items, StorageItem{Key: key, Value: append([]byte(nil), value...)})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Key < items[j].Key })
+	return items, nil
+}
+
+func (m *memoryStorage) Close() error {
+	return nil
+}
+
+// boltStorage keeps the buckets in a bbolt database file
+type boltStorage struct {
+	db *bbolt.DB
+}
+
+func openBoltStorage(path string) (*boltStorage, error) {
+	if path == "" {
+		return nil, fmt.Errorf("storage.path is required for the %s backend", StorageBolt)
+	}
+	// Fail instead of waiting forever when another process holds the file
+	db, err := bbolt.Open(path, 0o600, &bbolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	return &boltStorage{db: db}, nil
+}
+
+func (b *boltStorage) Get(bucket, key string) ([]byte, error) {
+	var value []byte
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		if bkt := tx.Bucket([]byte(bucket)); bkt != nil {
+			if v := bkt.Get([]byte(key)); v != nil {
+				value = append([]byte(nil), v...)
+				return nil
+			}
+		}
+		return ErrStorageNotFound
+	})
+	return value, err
+}
+
+func (b *boltStorage) Put(bucket, key string, value []byte) error {
+	return b.db.Update(func(tx *bbolt.Tx) error {
+		bkt, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		if err != nil {
+			return err
+		}
+		return bkt.Put([]byte(key), value)
+	})
+}
+
+func (b *boltStorage) Delete(bucket, key string) error {
+	return b.db.Update(func(tx *bbolt.Tx) error {
+		if bkt := tx.Bucket([]byte(bucket)); bkt != nil {
+			return bkt.Delete([]byte(key))
+		}
+		return nil
+	})
+}
+
+func (b *boltStorage) List(bucket string) ([]StorageItem, error) {
+	items := []StorageItem{}
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		bkt := tx.Bucket([]byte(bucket))
+		if bkt == nil {
+			return nil
+		}
+		// bbolt iterates in byte order of the keys
+		return bkt.ForEach(func(k, v []byte) error {
+			items = append(items, StorageItem{Key: string(k), Value: append([]byte(nil), v...)})
+			return nil
+		})
+	})
+	return items, err
+}
+
+func (b *boltStorage) Close() error {
+	return b.db.Close()
+}
+
+// sqliteStorage keeps the buckets in one table of an SQLite database file
+type sqliteStorage struct {
+	db *sql.DB
+}
+
+func openSQLiteStorage(path string) (*sqliteStorage, error) {
+	if path == "" {
+		return nil, fmt.Errorf("storage.path is required for the %s backend", StorageSQLite)
+	}
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	// SQLite allows a single writer; one connection avoids "database is locked"
+	db.SetMaxOpenConns(1)
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS state (
+		bucket TEXT NOT NULL,
+		key    TEXT NOT NULL,
+		value  BLOB NOT NULL,
+		PRIMARY KEY (bucket, key)
+	)`)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	return &sqliteStorage{db: db}, nil
+}
+
+func (q *sqliteStorage) Get(bucket, key string) ([]byte, error) {
+	var value []byte
+	err := q.db.QueryRow(`SELECT value FROM state WHERE bucket = ? AND key = ?`, bucket, key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrStorageNotFound
+	}
+	return value, err
+}
+
+func (q *sqliteStorage) Put(bucket, key string, value []byte) error {
+	_, err := q.db.Exec(
+		`INSERT INTO state (bucket, key, value) VALUES (?, ?, ?)
+		ON CONFLICT (bucket, key) DO UPDATE SET value = excluded.value`,
+		bucket, key, value,
+	)
+	return err
+}
+
+func (q *sqliteStorage) Delete(bucket, key string) error {
+	_, err := q.db.Exec(`DELETE FROM state WHERE bucket = ? AND key = ?`, bucket, key)
+	return err
+}
+
+func (q *sqliteStorage) List(bucket string) ([]StorageItem, error) {
+	rows, err := q.db.Query(`SELECT key, value FROM state WHERE bucket = ? ORDER BY key`, bucket)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []StorageItem{}
+	for rows.Next() {
+		var item StorageItem
+		if err := rows.Scan(&item.Key, &item.Value); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func (q *sqliteStorage) Close() error {
+	return q.db.Close()
+}
+
+// redisStorage keeps every value under its own Redis key, prefix:bucket:key,
+// so values can expire and several replicas can share the state
+type redisStorage struct {
+	client *redis.Client
+}
+
+func openRedisStorage(url string) (*redisStorage, error) {
+	if url == "" {
+		return nil, fmt.Errorf("storage.url is required for the %s backend", StorageRedis)
+	}
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid storage.url: %w", err)
+	}
+	client := redis.NewClient(options)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis at %s: %w", options.Addr, err)
+	}
+	return &redisStorage{client: client}, nil
+}
+
+func redisKey(bucket, key string) string {
+	return redisKeyPrefix + bucket + ":" + key
+}
+
+func (r *redisStorage) Get(bucket, key string) ([]byte, error) {
+	value, err := r.client.Get(context.Background(), redisKey(bucket, key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrStorageNotFound
+	}
+	return value, err
+}
+
+func (r *redisStorage) Put(bucket, key string, value []byte) error {
+	return r.client.Set(context.Background(), redisKey(bucket, key), value, 0).Err()
+}
+
+func (r *redisStorage) PutTTL(bucket, key string, value []byte, ttl time.Duration) error {
+	return r.client.Set(context.Background(), redisKey(bucket, key), value, ttl).Err()
+}
+
+func (r *redisStorage) Delete(bucket, key string) error {
+	return r.client.Del(context.Background(), redisKey(bucket, key)).Err()
+}
+
+func (r *redisStorage) List(bucket string) ([]StorageItem, error) {
+	ctx := context.Background()
+	prefix := redisKeyPrefix + bucket + ":"
+	var keys []string
+	iter := r.client.Scan(ctx, 0, prefix+"*", 500).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	sort.Strings(keys)
+
+	items := []StorageItem{}
+	for start := 0; start < len(keys); start += 500 {
+		chunk := keys[start:min(start+500, len(keys))]
+		values, err := r.client.MGet(ctx, chunk...).Result()
+		if err != nil {
+			return nil, err
+		}
+		for i, value := range values {
+			// Values deleted or expired since the scan are nil
+			if value, ok := value.(string); ok {
+				items = append(items, StorageItem{Key: strings.TrimPrefix(chunk[i], prefix), Value: []byte(value)})
+			}
+		}
+	}
+	return items, nil
+}
+
+func (r *redisStorage) Close() error {
+	return r.client.Close()
+}
@@ -0,0 +1,251 @@
+package fireflyMCP
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openTestStorage opens a storage backend on a file in a temporary directory,
+// or on an in-process Redis server
+func openTestStorage(t *testing.T, backend, path string) Storage {
+	config := newPluginTestConfig()
+	config.Storage.Backend = backend
+	config.Storage.Path = path
+	if backend == StorageRedis {
+		config.Storage.URL = "redis://" + miniredis.RunT(t).Addr()
+	}
+	storage, err := OpenStorage(config)
+	require.NoError(t, err)
+	return storage
+}
+
+func TestStorage_Backends(t *testing.T) {
+	for _, backend := range []string{StorageMemory, StorageBolt, StorageSQLite, StorageRedis} {
+		t.Run(backend, func(t *testing.T) {
+			storage := openTestStorage(t, backend, filepath.Join(t.TempDir(), "state.db"))
+			defer storage.Close()
+
+			_, err := storage.Get("trash", "a")
+			assert.ErrorIs(t, err, ErrStorageNotFound)
+			items, err := storage.List("trash")
+			require.NoError(t, err)
+			assert.Empty(t, items)
+
+			require.NoError(t, storage.Put("trash", "b", []byte("2")))
+			require.NoError(t, storage.Put("trash", "a", []byte("1")))
+			require.NoError(t, storage.Put("trash", "a", []byte("updated")))
+			require.NoError(t, storage.Put("changes", "a", []byte("other bucket")))
+
+			value, err := storage.Get("trash", "a")
+			require.NoError(t, err)
+			assert.Equal(t, "updated", string(value))
+			items, err = storage.List("trash")
+			require.NoError(t, err)
+			assert.Equal(t, []StorageItem{{Key: "a", Value: []byte("updated")}, {Key: "b", Value: []byte("2")}}, items)
+
+			require.NoError(t, storage.Delete("trash", "a"))
+			require.NoError(t, storage.Delete("trash", "missing"))
+			require.NoError(t, storage.Delete("unknown", "a"))
+			_, err = storage.Get("trash", "a")
+			assert.ErrorIs(t, err, ErrStorageNotFound)
+			value, err = storage.Get("changes", "a")
+			require.NoError(t, err)
+			assert.Equal(t, "other bucket", string(value))
+		})
+	}
+}
+
+func TestStorage_DurableBackendsSurviveReopen(t *testing.T) {
+	for _, backend := range []string{StorageBolt, StorageSQLite} {
+		t.Run(backend, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "state.db")
+			storage := openTestStorage(t, backend, path)
+			require.NoError(t, storage.Put("trash", "a", []byte("1")))
+			require.NoError(t, storage.Close())
+
+			storage = openTestStorage(t, backend, path)
+			defer storage.Close()
+			value, err := storage.Get("trash", "a")
+			require.NoError(t, err)
+			assert.Equal(t, "1", string(value))
+		})
+	}
+}
+
+func TestStorage_Config(t *testing.T) {
+	config := newPluginTestConfig()
+	config.Storage.Backend = "dynamodb"
+	_, err := OpenStorage(config)
+	assert.ErrorContains(t, err, "unknown storage backend")
+
+	config.Storage.Backend = StorageBolt
+	_, err = OpenStorage(config)
+	assert.ErrorContains(t, err, "storage.path is required")
+
+	config.Storage.Backend = StorageRedis
+	_, err = OpenStorage(config)
+	assert.ErrorContains(t, err, "storage.url is required")
+
+	assert.False(t, isDurable(newMemoryStorage()))
+	assert.False(t, isDurable(nil))
+	assert.False(t, isShared(newMemoryStorage()))
+	assert.Nil(t, sharedStorage(newMemoryStorage()))
+}
+
+func TestStorage_ServerStateSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+	storage := openTestStorage(t, StorageSQLite, path)
+
+	trash := newTrashStore(storage)
+	purgeAt := time.Date(2024, 3, 8, 12, 0, 0, 0, time.UTC)
+	entry := &TrashEntry{
+		Kind: trashKindRule, ObjectId: "7", Title: "Groceries", WasActive: true,
+		TrashedAt: "2024-03-01T12:00:00Z", PurgeAfter: purgeAt.Format(time.RFC3339),
+		Payload: []byte(`{"title":"Groceries"}`),
+		owner:   "tenant", purgeAt: purgeAt,
+	}
+	require.NoError(t, trash.add(entry))
+	removed := &TrashEntry{Kind: trashKindRule, ObjectId: "8", owner: "tenant"}
+	require.NoError(t, trash.add(removed))
+	trash.remove(removed.ID)
+
+	changes := newChangeLog(storage)
+	for i := range 4 {
+		changes.add("tenant", strconv.Itoa(i), ChangeRecord{Tool: "update_transaction"}, 3)
+	}
+	changes.add("tenant", "1", ChangeRecord{Tool: "store_transaction"}, 3)
+
+	journal, err := openStorageJournal(storage)
+	require.NoError(t, err)
+	require.NoError(t, journal.begin("finished", "tenant", "store_transactions_bulk", journalTestGroups(1)))
+	require.NoError(t, journal.finish("finished"))
+	require.NoError(t, journal.begin("interrupted", "tenant", "store_transactions_bulk", journalTestGroups(2)))
+	require.NoError(t, journal.pending("interrupted", 0))
+	require.NoError(t, storage.Close())
+
+	storage = openTestStorage(t, StorageSQLite, path)
+	defer storage.Close()
+
+	trash = newTrashStore(storage)
+	entries := trash.list("tenant")
+	require.Len(t, entries, 1)
+	assert.Equal(t, entry.ID, entries[0].ID)
+	assert.Equal(t, "Groceries", entries[0].Title)
+	assert.JSONEq(t, `{"title":"Groceries"}`, string(entries[0].Payload))
+	assert.True(t, purgeAt.Equal(entries[0].purgeAt))
+	assert.Empty(t, trash.list("other"))
+
+	changes = newChangeLog(storage)
+	assert.Empty(t, changes.get("tenant", "0"), "forgotten before the restart")
+	assert.Len(t, changes.get("tenant", "1"), 2)
+	assert.Equal(t, []string{"2", "3", "1"}, changes.tenants["tenant"].order)
+	changes.add("tenant", "4", ChangeRecord{Tool: "update_transaction"}, 3)
+	assert.Empty(t, changes.get("tenant", "2"), "the least recently changed before the restart goes first")
+
+	journal, err = openStorageJournal(storage)
+	require.NoError(t, err)
+	batches := journal.interruptedBatches("tenant")
+	require.Len(t, batches, 1)
+	assert.Equal(t, "interrupted", batches[0].ID)
+	assert.Equal(t, []int{0}, batches[0].Uncertain)
+	items, err := storage.List(storageBucketJournal)
+	require.NoError(t, err)
+	assert.Len(t, items, 2, "records of finished batches are deleted")
+
+	// New records follow the replayed ones
+	require.NoError(t, journal.finish("interrupted"))
+	journal, err = openStorageJournal(storage)
+	require.NoError(t, err)
+	assert.Empty(t, journal.interruptedBatches("tenant"))
+}
+
+func TestStorage_ServerUsesStorageForJournal(t *testing.T) {
+	config := newPluginTestConfig()
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+	assert.Nil(t, server.journal, "no journal without journal.path or durable storage")
+	require.NoError(t, server.Close())
+
+	config.Storage.Backend = StorageBolt
+	config.Storage.Path = filepath.Join(t.TempDir(), "state.db")
+	server, err = NewFireflyMCPServer(config)
+	require.NoError(t, err)
+	defer server.Close()
+	assert.NotNil(t, server.journal)
+	assert.True(t, server.hasTool("list_interrupted_batches"))
+}
+
+func TestStorage_ReleasedWhenConstructionFails(t *testing.T) {
+	config := newPluginTestConfig()
+	config.Storage.Backend = StorageBolt
+	config.Storage.Path = filepath.Join(t.TempDir(), "state.db")
+	config.Replay.Record = filepath.Join(t.TempDir(), "calls.jsonl")
+	config.Tools = map[string]ToolOverride{"no_such_tool": {}}
+	_, err := NewFireflyMCPServer(config)
+	require.Error(t, err)
+
+	// The bolt file lock of the failed server is released
+	config.Tools = nil
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+	require.NoError(t, server.Close())
+}
+
+func TestStorage_ReplicasShareState(t *testing.T) {
+	var listed atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		listed.Add(1)
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		w.Write([]byte(`{"data":[],"meta":{"pagination":{"total":0,"count":0,"per_page":50,"current_page":1,"total_pages":1}}}`))
+	}))
+	defer ts.Close()
+
+	redis := miniredis.RunT(t)
+	replica := func() *FireflyMCPServer {
+		config := newPluginTestConfig()
+		config.Server.URL = ts.URL
+		config.Storage.Backend = StorageRedis
+		config.Storage.URL = "redis://" + redis.Addr()
+		config.Tools = map[string]ToolOverride{"list_tags": {CacheTTL: 60}}
+		server, err := NewFireflyMCPServer(config)
+		require.NoError(t, err)
+		t.Cleanup(func() { server.Close() })
+		return server
+	}
+	a, b := replica(), replica()
+	assert.Nil(t, a.journal, "shared storage does not keep the journal")
+
+	// A result cached by one replica is served by the other
+	ctx := context.Background()
+	for _, server := range []*FireflyMCPServer{a, b} {
+		result, err := server.tools["list_tags"].invoke(ctx, nil, []byte(`{}`))
+		require.NoError(t, err)
+		assert.False(t, result.IsError)
+		assert.IsType(t, &mcp.TextContent{}, result.Content[0])
+	}
+	assert.Equal(t, int32(1), listed.Load())
+	redis.FastForward(2 * time.Minute)
+	_, err := b.tools["list_tags"].invoke(ctx, nil, []byte(`{}`))
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), listed.Load(), "expired in Redis")
+	assert.Equal(t, 1, a.toolCache.flush())
+
+	// Trash entries and change histories written by one replica are seen by the other
+	tenant := a.tenantKey(nil)
+	require.NoError(t, a.trash.add(&TrashEntry{Kind: trashKindRule, ObjectId: "7", owner: tenant}))
+	require.Len(t, b.trash.list(tenant), 1)
+	b.changes.add(tenant, "40", ChangeRecord{Tool: "update_transaction"}, 10)
+	a.changes.add(tenant, "40", ChangeRecord{Tool: "store_transaction"}, 10)
+	assert.Len(t, b.changes.get(tenant, "40"), 2)
+}
@@ -0,0 +1,204 @@
+package fireflyMCP
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// tagBatchSize is the number of transactions tag_transactions updates between
+// two progress notifications
+const tagBatchSize = 20
+
+// Tag actions reported per split
+const (
+	tagActionTag  = "tag"
+	tagActionKeep = "keep" // The split already has all tags
+)
+
+// TagTransactionsArgs represents the arguments for the tag_transactions tool
+type TagTransactionsArgs struct {
+	Tags     []string `json:"tags" jsonschema:"Tags to add to every matching transaction (required); existing tags are kept"`
+	Account  string   `json:"account,omitempty" jsonschema:"Only transactions from or to this account ID (or configured account alias)"`
+	Category string   `json:"category,omitempty" jsonschema:"Only transactions in this category (ID or name)"`
+	Apply    bool     `json:"apply,omitempty" jsonschema:"Add the tags (default: false, only preview the matching transactions)"`
+	DateRange
+}
+
+// TaggedTransaction is a split matched by tag_transactions
+type TaggedTransaction struct {
+	Id           string   `json:"id"` // Transaction group ID
+	JournalId    string   `json:"journal_id"`
+	Date         string   `json:"date"`
+	Description  string   `json:"description"`
+	Amount       string   `json:"amount"`
+	CurrencyCode string   `json:"currency_code"`
+	Tags         []string `json:"tags"` // Tags after the change
+	Action       string   `json:"action"`
+	Applied      bool     `json:"applied"`
+	Error        string   `json:"error,omitempty"`
+}
+
+// TagTransactionsReport is the result of tag_transactions
+type TagTransactionsReport struct {
+	Tags         []string            `json:"tags"`
+	Start        string              `json:"start"`
+	End          string              `json:"end"`
+	Account      string              `json:"account,omitempty"`
+	Category     string              `json:"category,omitempty"`
+	Apply        bool                `json:"apply"`
+	Matched      int                 `json:"matched"`
+	Tagged       int                 `json:"tagged"`
+	Kept         int                 `json:"kept"`
+	Failed       int                 `json:"failed,omitempty"`
+	Transactions []TaggedTransaction `json:"transactions"`
+}
+
+// handleTagTransactions adds tags to all transactions of a period, optionally
+// only those of one account or category, e.g. to mark a holiday or a tax year
+// after the fact. Transactions are updated in batches; callers sending a
+// progress token get a progress notification after every batch.
+func (s *FireflyMCPServer) handleTagTransactions(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args TagTransactionsArgs,
+) (*mcp.CallToolResult, any, error) {
+	var tags []string
+	for _, tag := range args.Tags {
+		if tag = strings.TrimSpace(tag); tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) == 0 {
+		return newErrorResult("Error: tags are required")
+	}
+	dates, err := s.resolveDateRange(args.DateRange, dateRangeRequired)
+	if err != nil {
+		return newErrorResult(err.Error())
+	}
+	account := s.resolveAccountRef(strings.TrimSpace(args.Account))
+	category := strings.TrimSpace(args.Category)
+
+	groups, truncated, err := s.fetchTransactionGroups(ctx, req, ListTransactionsArgs{
+		DateRange: DateRange{Start: dates.StartString(), End: dates.EndString()},
+	})
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error listing transactions: %v", err))
+	}
+	if truncated {
+		return newErrorResult(fmt.Sprintf(
+			"Error: more than %d transactions in the period; use a shorter period or tag it in parts",
+			maxCompositeTransactions,
+		))
+	}
+
+	report := &TagTransactionsReport{
+		Tags:         tags,
+		Start:        dates.StartString(),
+		End:          dates.EndString(),
+		Account:      account,
+		Category:     category,
+		Apply:        args.Apply,
+		Transactions: []TaggedTransaction{},
+	}
+	// Patches of the groups with splits missing a tag, in the order of the groups
+	var patches []UpdateTransactionArgs
+	entries := make(map[string][]int) // Group ID -> indexes of its entries
+	for _, group := range groups {
+		var patch []TransactionSplitPatch
+		for _, split := range group.Transactions {
+			if !tagSplitMatches(split, account, category) {
+				continue
+			}
+			merged := mergeTags(split.Tags, tags)
+			entry := TaggedTransaction{
+				Id:           group.Id,
+				JournalId:    split.JournalId,
+				Date:         split.Date.Format("2006-01-02"),
+				Description:  split.Description,
+				Amount:       split.Amount,
+				CurrencyCode: split.CurrencyCode,
+				Tags:         merged,
+				Action:       tagActionKeep,
+			}
+			if len(merged) > len(split.Tags) {
+				entry.Action = tagActionTag
+				patch = append(patch, TransactionSplitPatch{JournalId: split.JournalId, Set: map[string]any{"tags": merged}})
+				entries[group.Id] = append(entries[group.Id], len(report.Transactions))
+			} else {
+				report.Kept++
+			}
+			report.Transactions = append(report.Transactions, entry)
+		}
+		if len(patch) > 0 {
+			patches = append(patches, UpdateTransactionArgs{ID: group.Id, Patch: patch})
+		}
+	}
+	report.Matched = len(report.Transactions)
+	if !args.Apply || len(patches) == 0 {
+		return newSuccessResult(report)
+	}
+
+	for start := 0; start < len(patches); start += tagBatchSize {
+		for _, patch := range patches[start:min(start+tagBatchSize, len(patches))] {
+			_, err := callTool[UpdateTransactionArgs, TransactionGroup](ctx, req, s.handleUpdateTransaction, patch)
+			for _, i := range entries[patch.ID] {
+				entry := &report.Transactions[i]
+				if err != nil {
+					entry.Error = err.Error()
+					report.Failed++
+					continue
+				}
+				entry.Applied = true
+				report.Tagged++
+			}
+		}
+		done := min(start+tagBatchSize, len(patches))
+		notifyProgress(ctx, req, done, len(patches), fmt.Sprintf("Tagged %d of %d transaction groups", done, len(patches)))
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return newSuccessResult(report)
+}
+
+// tagSplitMatches reports whether a split is from or to the account and in the
+// category, where empty filters match every split
+func tagSplitMatches(split Transaction, account, category string) bool {
+	if account != "" && split.SourceId != account && split.DestinationId != account {
+		return false
+	}
+	if category != "" && getStringValue(split.CategoryId) != category &&
+		!strings.EqualFold(getStringValue(split.CategoryName), category) {
+		return false
+	}
+	return true
+}
+
+// mergeTags appends the tags a split does not have yet to its tags
+func mergeTags(existing, tags []string) []string {
+	merged := slices.Clone(existing)
+	for _, tag := range tags {
+		if !slices.ContainsFunc(merged, func(have string) bool { return strings.EqualFold(have, tag) }) {
+			merged = append(merged, tag)
+		}
+	}
+	return merged
+}
+
+// notifyProgress reports the progress of a long-running tool call to clients
+// that asked for it with a progress token. Failures to notify are ignored.
+func notifyProgress(ctx context.Context, req *mcp.CallToolRequest, done, total int, message string) {
+	if req == nil || req.Session == nil || req.Params == nil || req.Params.GetProgressToken() == nil {
+		return
+	}
+	_ = req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+		ProgressToken: req.Params.GetProgressToken(),
+		Progress:      float64(done),
+		Total:         float64(total),
+		Message:       message,
+	})
+}
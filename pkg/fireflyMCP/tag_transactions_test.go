@@ -0,0 +1,91 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTagTransactions(t *testing.T) {
+	groups := map[string]string{
+		"1": `{"type":"withdrawal","transaction_journal_id":"11","date":"2024-07-02T00:00:00+00:00","amount":"40.00",` +
+			`"currency_code":"EUR","description":"Hotel","source_id":"1","destination_id":"30","category_name":"Travel","tags":["card"]}`,
+		"2": `{"type":"withdrawal","transaction_journal_id":"21","date":"2024-07-03T00:00:00+00:00","amount":"12.00",` +
+			`"currency_code":"EUR","description":"Museum","source_id":"1","destination_id":"31","category_name":"Travel","tags":["trip 2024"]}`,
+		"3": `{"type":"withdrawal","transaction_journal_id":"31","date":"2024-07-04T00:00:00+00:00","amount":"3.00",` +
+			`"currency_code":"EUR","description":"Coffee","source_id":"2","destination_id":"32","category_name":"Travel"}`,
+	}
+	group := func(id string) string {
+		return fmt.Sprintf(`{"type":"transactions","id":"%s","attributes":{"transactions":[%s]}}`, id, groups[id])
+	}
+	var updates []map[string]any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		id := strings.TrimPrefix(r.URL.Path, "/v1/transactions/")
+		switch {
+		case r.URL.Path == "/v1/transactions":
+			w.Write([]byte(`{"data":[` + group("1") + "," + group("2") + "," + group("3") + `],` +
+				`"meta":{"pagination":{"total":3,"count":3,"per_page":200,"current_page":1,"total_pages":1}}}`))
+		case r.Method == http.MethodPut:
+			var update map[string]any
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&update))
+			updates = append(updates, update)
+			w.Write([]byte(`{"data":` + group(id) + `}`))
+		case groups[id] != "":
+			w.Write([]byte(`{"data":` + group(id) + `}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	config.Accounts.Aliases = map[string]string{"joint card": "1"}
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	args := TagTransactionsArgs{
+		Tags:      []string{"Trip 2024", " "},
+		Account:   "joint card",
+		Category:  "travel",
+		DateRange: DateRange{Start: "2024-07-01", End: "2024-07-31"},
+	}
+	var report TagTransactionsReport
+	tag := func(args TagTransactionsArgs) {
+		result, _, err := server.handleTagTransactions(context.Background(), nil, args)
+		require.NoError(t, err)
+		text := result.Content[0].(*mcp.TextContent).Text
+		require.False(t, result.IsError, text)
+		require.NoError(t, json.Unmarshal([]byte(text), &report))
+	}
+
+	tag(args)
+	assert.Equal(t, "1", report.Account)
+	assert.Equal(t, 2, report.Matched, "the coffee is paid from another account")
+	assert.Equal(t, 1, report.Kept, "tags are compared case-insensitively")
+	assert.Zero(t, report.Tagged)
+	assert.Equal(t, []string{"card", "Trip 2024"}, report.Transactions[0].Tags)
+	assert.Equal(t, tagActionTag, report.Transactions[0].Action)
+	assert.Empty(t, updates, "previews change nothing")
+
+	args.Apply = true
+	tag(args)
+	assert.Equal(t, 1, report.Tagged)
+	assert.True(t, report.Transactions[0].Applied)
+	require.Len(t, updates, 1)
+	assert.Equal(t, []any{map[string]any{"transaction_journal_id": "11", "tags": []any{"card", "Trip 2024"}}},
+		updates[0]["transactions"])
+
+	result, _, err := server.handleTagTransactions(context.Background(), nil, TagTransactionsArgs{Tags: []string{"x"}})
+	require.NoError(t, err)
+	assert.True(t, result.IsError, "a date range is required")
+}
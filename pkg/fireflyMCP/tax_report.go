@@ -0,0 +1,283 @@
+package fireflyMCP
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// TaxReportArgs represents the arguments for the tax_report tool
+type TaxReportArgs struct {
+	Year    int    `json:"year,omitempty" jsonschema:"Year of the report (default: the current year)"`
+	Quarter int    `json:"quarter,omitempty" jsonschema:"Quarter 1-4 (default: all quarters of the year)"`
+	Format  string `json:"format,omitempty" jsonschema:"json (default) or csv"`
+}
+
+// TaxLine is the total of one tax-relevant category or tag in a quarter
+type TaxLine struct {
+	Direction string `json:"direction"` // income or expense
+	Source    string `json:"source"`    // category or tag
+	Name      string `json:"name"`
+	Rate      string `json:"rate"` // VAT rate in percent
+	Count     int    `json:"count"`
+	Gross     string `json:"gross"`
+	Net       string `json:"net"`
+	VAT       string `json:"vat"`
+}
+
+// TaxQuarter holds the tax-relevant totals of a quarter in one currency
+type TaxQuarter struct {
+	Quarter      string    `json:"quarter"` // e.g. 2024-Q1
+	Start        string    `json:"start"`
+	End          string    `json:"end"`
+	CurrencyCode string    `json:"currency_code"`
+	Lines        []TaxLine `json:"lines"`
+	VATCollected string    `json:"vat_collected"` // VAT included in income
+	VATPaid      string    `json:"vat_paid"`      // VAT included in expenses
+	VATBalance   string    `json:"vat_balance"`   // Collected minus paid; positive is payable
+}
+
+// TaxReport is the result of the tax_report tool
+type TaxReport struct {
+	Year                  int          `json:"year"`
+	Quarters              []TaxQuarter `json:"quarters"`
+	TransactionCount      int          `json:"transaction_count"`
+	TransactionsTruncated bool         `json:"transactions_truncated,omitempty"`
+}
+
+// taxRule is the VAT rate of a tax-relevant category or tag
+type taxRule struct {
+	source   string
+	name     string
+	rate     decimal
+	rateText string // The rate as configured
+}
+
+// taxLineTotal accumulates the gross and net amounts of a line
+type taxLineTotal struct {
+	rule  taxRule
+	count int
+	gross decimal
+	net   decimal
+}
+
+// validateTaxRates checks that all configured VAT rates are percentages
+func validateTaxRates(section string, rates map[string]string) error {
+	for name, rate := range rates {
+		value, ok := parseDecimal(rate)
+		if !ok || value.Sign() < 0 || value.Cmp(decimalFromInt(100)) >= 0 {
+			return fmt.Errorf("%s.%s: rate %q must be a percentage from 0 to below 100", section, name, rate)
+		}
+	}
+	return nil
+}
+
+// taxEnabled reports whether tax-relevant categories or tags are configured
+func (s *FireflyMCPServer) taxEnabled() bool {
+	config := s.currentConfig()
+	return config != nil && (len(config.Tax.Categories) > 0 || len(config.Tax.Tags) > 0)
+}
+
+// taxRules returns the configured rates by lowercase category name and tag
+func (s *FireflyMCPServer) taxRules() (categories, tags map[string]taxRule) {
+	config := s.currentConfig()
+	categories = make(map[string]taxRule)
+	tags = make(map[string]taxRule)
+	for name, rate := range config.Tax.Categories {
+		categories[strings.ToLower(strings.TrimSpace(name))] = taxRule{source: "category", name: name, rate: parseAmount(rate), rateText: strings.TrimSpace(rate)}
+	}
+	for name, rate := range config.Tax.Tags {
+		tags[strings.ToLower(strings.TrimSpace(name))] = taxRule{source: "tag", name: name, rate: parseAmount(rate), rateText: strings.TrimSpace(rate)}
+	}
+	return categories, tags
+}
+
+// handleTaxReport sums the transactions of tax-relevant categories and tags per
+// quarter and splits them into net amount and VAT
+func (s *FireflyMCPServer) handleTaxReport(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args TaxReportArgs,
+) (*mcp.CallToolResult, any, error) {
+	year := args.Year
+	if year == 0 {
+		year = s.today().Year()
+	}
+	if year < 1900 || year > 9999 {
+		return newErrorResult("Error: year must be between 1900 and 9999")
+	}
+	if args.Quarter < 0 || args.Quarter > 4 {
+		return newErrorResult("Error: quarter must be between 1 and 4")
+	}
+	format := strings.ToLower(args.Format)
+	if format != "" && format != "json" && format != "csv" {
+		return newErrorResult("Error: format must be json or csv")
+	}
+
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(1, 0, -1)
+	if args.Quarter > 0 {
+		start = start.AddDate(0, 3*(args.Quarter-1), 0)
+		end = start.AddDate(0, 3, -1)
+	}
+	transactions, truncated, err := s.fetchTransactions(ctx, req, ListTransactionsArgs{DateRange: DateRange{
+		Start: start.Format("2006-01-02"),
+		End:   end.Format("2006-01-02"),
+	}})
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error listing transactions: %v", err))
+	}
+
+	categories, tags := s.taxRules()
+	report := buildTaxReport(year, transactions, categories, tags)
+	report.TransactionsTruncated = truncated
+
+	if format == "csv" {
+		data, err := taxReportCSV(report)
+		if err != nil {
+			return newErrorResult(fmt.Sprintf("Error writing CSV: %v", err))
+		}
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: data}}}, nil, nil
+	}
+	return newSuccessResult(report)
+}
+
+// buildTaxReport groups withdrawals and deposits by quarter, currency and the
+// rule that makes them tax-relevant. A transaction's category takes precedence
+// over its tags; transactions matching neither are left out.
+func buildTaxReport(year int, transactions []Transaction, categories, tags map[string]taxRule) *TaxReport {
+	report := &TaxReport{Year: year, Quarters: []TaxQuarter{}}
+	type quarterKey struct {
+		quarter  int
+		currency string
+	}
+	quarters := make(map[quarterKey]map[string]*taxLineTotal)
+
+	for _, transaction := range transactions {
+		if transaction.Type != "withdrawal" && transaction.Type != "deposit" {
+			continue
+		}
+		rule, ok := transactionTaxRule(transaction, categories, tags)
+		if !ok {
+			continue
+		}
+		report.TransactionCount++
+
+		key := quarterKey{quarter: (int(transaction.Date.Month())-1)/3 + 1, currency: transaction.CurrencyCode}
+		if quarters[key] == nil {
+			quarters[key] = make(map[string]*taxLineTotal)
+		}
+		direction := "expense"
+		if transaction.Type == "deposit" {
+			direction = "income"
+		}
+		lineKey := direction + "\x00" + rule.source + "\x00" + rule.name
+		line := quarters[key][lineKey]
+		if line == nil {
+			line = &taxLineTotal{rule: rule}
+			quarters[key][lineKey] = line
+		}
+		gross := parseAmount(transaction.Amount).Abs()
+		line.count++
+		line.gross = line.gross.Add(gross)
+		line.net = line.net.Add(gross.Mul(decimalFromInt(100)).Div(decimalFromInt(100).Add(rule.rate)))
+	}
+
+	keys := make([]quarterKey, 0, len(quarters))
+	for key := range quarters {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(a, b int) bool {
+		if keys[a].quarter != keys[b].quarter {
+			return keys[a].quarter < keys[b].quarter
+		}
+		return keys[a].currency < keys[b].currency
+	})
+
+	for _, key := range keys {
+		start := time.Date(year, time.Month(3*(key.quarter-1)+1), 1, 0, 0, 0, 0, time.UTC)
+		quarter := TaxQuarter{
+			Quarter:      fmt.Sprintf("%d-Q%d", year, key.quarter),
+			Start:        start.Format("2006-01-02"),
+			End:          start.AddDate(0, 3, -1).Format("2006-01-02"),
+			CurrencyCode: key.currency,
+			Lines:        []TaxLine{},
+		}
+		lineKeys := make([]string, 0, len(quarters[key]))
+		for lineKey := range quarters[key] {
+			lineKeys = append(lineKeys, lineKey)
+		}
+		sort.Strings(lineKeys)
+
+		var collected, paid decimal
+		for _, lineKey := range lineKeys {
+			line := quarters[key][lineKey]
+			// VAT is what is left of the rounded gross after the rounded net
+			net := parseAmount(formatAmount(line.net))
+			vat := parseAmount(formatAmount(line.gross)).Sub(net)
+			direction := strings.SplitN(lineKey, "\x00", 2)[0]
+			if direction == "income" {
+				collected = collected.Add(vat)
+			} else {
+				paid = paid.Add(vat)
+			}
+			quarter.Lines = append(quarter.Lines, TaxLine{
+				Direction: direction,
+				Source:    line.rule.source,
+				Name:      line.rule.name,
+				Rate:      line.rule.rateText,
+				Count:     line.count,
+				Gross:     formatAmount(line.gross),
+				Net:       formatAmount(net),
+				VAT:       formatAmount(vat),
+			})
+		}
+		quarter.VATCollected = formatAmount(collected)
+		quarter.VATPaid = formatAmount(paid)
+		quarter.VATBalance = formatAmount(collected.Sub(paid))
+		report.Quarters = append(report.Quarters, quarter)
+	}
+	return report
+}
+
+// transactionTaxRule returns the rule of a transaction's category, or else of
+// the first of its tags that is configured
+func transactionTaxRule(transaction Transaction, categories, tags map[string]taxRule) (taxRule, bool) {
+	if transaction.CategoryName != nil {
+		if rule, ok := categories[strings.ToLower(strings.TrimSpace(*transaction.CategoryName))]; ok {
+			return rule, true
+		}
+	}
+	for _, tag := range transaction.Tags {
+		if rule, ok := tags[strings.ToLower(strings.TrimSpace(tag))]; ok {
+			return rule, true
+		}
+	}
+	return taxRule{}, false
+}
+
+// taxReportCSV writes the lines of a tax report as CSV, one line per row
+func taxReportCSV(report *TaxReport) (string, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	rows := [][]string{{"quarter", "currency_code", "direction", "source", "name", "rate", "count", "gross", "net", "vat"}}
+	for _, quarter := range report.Quarters {
+		for _, line := range quarter.Lines {
+			rows = append(rows, []string{
+				quarter.Quarter, quarter.CurrencyCode, line.Direction, line.Source, line.Name,
+				line.Rate, strconv.Itoa(line.Count), line.Gross, line.Net, line.VAT,
+			})
+		}
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,106 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildTaxReport(t *testing.T) {
+	consulting := "Consulting"
+	groceries := "Groceries"
+	categories := map[string]taxRule{"consulting": {source: "category", name: "Consulting", rate: parseAmount("19"), rateText: "19"}}
+	tags := map[string]taxRule{"books": {source: "tag", name: "books", rate: parseAmount("7"), rateText: "7"}}
+	date := func(month time.Month) time.Time { return time.Date(2024, month, 15, 0, 0, 0, 0, time.UTC) }
+
+	report := buildTaxReport(2024, []Transaction{
+		{Type: "deposit", Amount: "1190.00", CurrencyCode: "EUR", Date: date(time.February), CategoryName: &consulting},
+		{Type: "deposit", Amount: "119.00", CurrencyCode: "EUR", Date: date(time.March), CategoryName: &consulting},
+		{Type: "withdrawal", Amount: "10.70", CurrencyCode: "EUR", Date: date(time.March), Tags: []string{"Books"}},
+		{Type: "withdrawal", Amount: "50.00", CurrencyCode: "EUR", Date: date(time.March), CategoryName: &groceries},
+		{Type: "transfer", Amount: "500.00", CurrencyCode: "EUR", Date: date(time.March), CategoryName: &consulting},
+		{Type: "deposit", Amount: "238.00", CurrencyCode: "EUR", Date: date(time.May), CategoryName: &consulting},
+	}, categories, tags)
+
+	assert.Equal(t, 4, report.TransactionCount)
+	require.Len(t, report.Quarters, 2)
+
+	q1 := report.Quarters[0]
+	assert.Equal(t, "2024-Q1", q1.Quarter)
+	assert.Equal(t, "2024-01-01", q1.Start)
+	assert.Equal(t, "2024-03-31", q1.End)
+	assert.Equal(t, []TaxLine{
+		{Direction: "expense", Source: "tag", Name: "books", Rate: "7", Count: 1, Gross: "10.70", Net: "10.00", VAT: "0.70"},
+		{Direction: "income", Source: "category", Name: "Consulting", Rate: "19", Count: 2, Gross: "1309.00", Net: "1100.00", VAT: "209.00"},
+	}, q1.Lines)
+	assert.Equal(t, "209.00", q1.VATCollected)
+	assert.Equal(t, "0.70", q1.VATPaid)
+	assert.Equal(t, "208.30", q1.VATBalance)
+
+	assert.Equal(t, "2024-Q2", report.Quarters[1].Quarter)
+	assert.Equal(t, "38.00", report.Quarters[1].VATBalance)
+
+	data, err := taxReportCSV(report)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(data), "\n")
+	require.Len(t, lines, 4)
+	assert.Equal(t, "quarter,currency_code,direction,source,name,rate,count,gross,net,vat", lines[0])
+	assert.Equal(t, "2024-Q1,EUR,expense,tag,books,7,1,10.70,10.00,0.70", lines[1])
+}
+
+func TestValidateTaxRates(t *testing.T) {
+	assert.NoError(t, validateTaxRates("tax.categories", map[string]string{"food": "7", "export": "0", "services": "5.5"}))
+	assert.Error(t, validateTaxRates("tax.categories", map[string]string{"services": "100"}))
+	assert.Error(t, validateTaxRates("tax.tags", map[string]string{"books": "-7"}))
+	assert.Error(t, validateTaxRates("tax.tags", map[string]string{"books": "seven"}))
+}
+
+func TestHandleTaxReport(t *testing.T) {
+	var query string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		w.Write([]byte(`{"data":[{"type":"transactions","id":"1","attributes":{"transactions":[` +
+			`{"transaction_journal_id":"1","type":"deposit","date":"2024-04-10T00:00:00Z","amount":"119.00",` +
+			`"currency_code":"EUR","description":"Invoice 7","source_id":"5","destination_id":"1",` +
+			`"category_name":"Consulting"}]}}],` +
+			`"meta":{"pagination":{"total":1,"count":1,"per_page":200,"current_page":1,"total_pages":1}}}`))
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	config.Tax.Categories = map[string]string{"consulting": "19"}
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+	_, ok := server.tools["tax_report"]
+	require.True(t, ok)
+	ctx := context.Background()
+
+	result, _, err := server.handleTaxReport(ctx, nil, TaxReportArgs{Year: 2024, Quarter: 2})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Contains(t, query, "start=2024-04-01")
+	assert.Contains(t, query, "end=2024-06-30")
+
+	var report TaxReport
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &report))
+	require.Len(t, report.Quarters, 1)
+	assert.Equal(t, "19.00", report.Quarters[0].VATCollected)
+
+	result, _, err = server.handleTaxReport(ctx, nil, TaxReportArgs{Year: 2024, Format: "csv"})
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "2024-Q2,EUR,income,category,consulting,19,1,119.00,100.00,19.00")
+
+	result, _, err = server.handleTaxReport(ctx, nil, TaxReportArgs{Quarter: 5})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
@@ -0,0 +1,50 @@
+package fireflyMCP
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Default per-tenant quotas of the in-memory state (tenants.*)
+const (
+	defaultTenantCacheEntries    = 500
+	defaultTenantChangeHistories = 1000
+)
+
+// tenantKey returns the key that partitions per-user state of a tool call:
+// cached results, formatting hints, change history, trash and the write journal.
+// A tenant is an API token on a Firefly III instance, so state never bleeds
+// between users, nor between instances after the server URL is changed.
+func (s *FireflyMCPServer) tenantKey(req *mcp.CallToolRequest) string {
+	serverURL := ""
+	if config := s.currentConfig(); config != nil {
+		serverURL = config.Server.URL
+	}
+	return tenantKeyFor(serverURL, requestTokenKey(req))
+}
+
+// tenantKeyFor hashes instance URL and token, so tokens are neither kept as
+// map keys nor written to disk
+func tenantKeyFor(serverURL, token string) string {
+	sum := sha256.Sum256([]byte(serverURL + "\x00" + token))
+	return hex.EncodeToString(sum[:])
+}
+
+// tenantCacheEntries returns how many tool results are cached per tenant
+func (s *FireflyMCPServer) tenantCacheEntries() int {
+	if config := s.currentConfig(); config != nil && config.Tenants.CacheEntries > 0 {
+		return config.Tenants.CacheEntries
+	}
+	return defaultTenantCacheEntries
+}
+
+// tenantChangeHistories returns for how many transactions per tenant the change
+// history is kept
+func (s *FireflyMCPServer) tenantChangeHistories() int {
+	if config := s.currentConfig(); config != nil && config.Tenants.ChangeHistories > 0 {
+		return config.Tenants.ChangeHistories
+	}
+	return defaultTenantChangeHistories
+}
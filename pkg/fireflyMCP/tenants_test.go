@@ -0,0 +1,63 @@
+package fireflyMCP
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantKey(t *testing.T) {
+	config := newPluginTestConfig()
+	config.Server.URL = "https://a.example.com"
+	server := &FireflyMCPServer{config: config}
+	withToken := func(token string) *mcp.CallToolRequest {
+		return &mcp.CallToolRequest{Extra: &mcp.RequestExtra{Header: http.Header{"Authorization": {"Bearer " + token}}}}
+	}
+
+	key := server.tenantKey(withToken("token-a"))
+	assert.Len(t, key, 64)
+	assert.NotContains(t, key, "token-a")
+	assert.Equal(t, key, server.tenantKey(withToken("token-a")))
+	assert.NotEqual(t, key, server.tenantKey(withToken("token-b")), "other token")
+	assert.NotEqual(t, key, server.tenantKey(nil), "configured token")
+
+	config.Server.URL = "https://b.example.com"
+	assert.NotEqual(t, key, server.tenantKey(withToken("token-a")), "other instance")
+}
+
+func TestToolResultCache_TenantQuota(t *testing.T) {
+	cache := newToolResultCache(nil)
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		cache.put("a", strconv.Itoa(i), toolResultCacheEntry{expires: now.Add(time.Duration(i+1) * time.Minute)}, 3)
+	}
+	cache.put("b", "0", toolResultCacheEntry{expires: now.Add(time.Minute)}, 3)
+
+	// A full tenant evicts its entry expiring first, other tenants are untouched
+	cache.put("a", "3", toolResultCacheEntry{expires: now.Add(time.Hour)}, 3)
+	_, ok := cache.get("a", "0")
+	assert.False(t, ok)
+	for _, key := range []string{"1", "2", "3"} {
+		_, ok := cache.get("a", key)
+		assert.True(t, ok, key)
+	}
+	_, ok = cache.get("b", "0")
+	assert.True(t, ok)
+	_, ok = cache.get("b", "1")
+	assert.False(t, ok, "entries of other tenants are not visible")
+
+	// Expired entries are evicted first
+	cache.put("b", "expired", toolResultCacheEntry{expires: now.Add(-time.Second)}, 3)
+	cache.put("b", "1", toolResultCacheEntry{expires: now.Add(time.Minute)}, 3)
+	cache.put("b", "2", toolResultCacheEntry{expires: now.Add(time.Minute)}, 3)
+	_, ok = cache.get("b", "0")
+	assert.True(t, ok)
+	require.Len(t, cache.tenants["b"], 3)
+
+	assert.Equal(t, 6, cache.flush())
+}
@@ -10,6 +10,34 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// e2eEnabled reports whether the integration tests run against the seeded
+// Firefly III instance of test/e2e (FIREFLY_E2E=1). Failures that are only
+// logged against an arbitrary instance fail the test there.
+func e2eEnabled() bool {
+	return os.Getenv("FIREFLY_E2E") == "1"
+}
+
+// integrationFailed reports a failed API or tool call: an error in e2e mode,
+// a log message otherwise
+func integrationFailed(t *testing.T, format string, args ...any) {
+	t.Helper()
+	if e2eEnabled() {
+		t.Errorf(format, args...)
+		return
+	}
+	t.Logf(format, args...)
+}
+
+// skipWithoutFixtures skips a test that needs data the instance does not have.
+// The e2e fixtures provide all such data, so in e2e mode the test fails instead.
+func skipWithoutFixtures(t *testing.T, reason string) {
+	t.Helper()
+	if e2eEnabled() {
+		t.Fatalf("e2e fixtures missing: %s", reason)
+	}
+	t.Skip(reason)
+}
+
 // TestConfig holds test configuration
 type TestConfig struct {
 	ServerURL string
@@ -34,6 +62,9 @@ func loadTestConfig(t *testing.T) *TestConfig {
 	if serverURL == "" || apiToken == "" {
 		// Fallback to config file
 		config, err := LoadConfig("../../config.yaml")
+		if err != nil && e2eEnabled() {
+			t.Fatalf("FIREFLY_E2E is set but no test instance is configured (%v)", err)
+		}
 		if err != nil {
 			t.Skipf("Skipping integration tests: no test config available (%v)", err)
 		}
@@ -56,24 +87,43 @@ func loadTestConfig(t *testing.T) *TestConfig {
 func createTestServer(t *testing.T, testConfig *TestConfig) *FireflyMCPServer {
 	config := &Config{
 		Server: struct {
-			URL string `yaml:"url" mapstructure:"url"`
+			URL            string `yaml:"url" mapstructure:"url"`
+			CheckOnStartup bool   `yaml:"check_on_startup" mapstructure:"check_on_startup"`
 		}{URL: testConfig.ServerURL},
 		API: struct {
 			Token string `yaml:"token" mapstructure:"token"`
 		}{Token: testConfig.APIToken},
 		Client: struct {
-			Timeout int `yaml:"timeout" mapstructure:"timeout"`
+			Timeout             int    `yaml:"timeout" mapstructure:"timeout"`
+			ErrorBodyLimit      int    `yaml:"error_body_limit" mapstructure:"error_body_limit"`
+			MaintenanceCooldown int    `yaml:"maintenance_cooldown" mapstructure:"maintenance_cooldown"`
+			SerializeWrites     bool   `yaml:"serialize_writes" mapstructure:"serialize_writes"`
+			WriteInterval       int    `yaml:"write_interval" mapstructure:"write_interval"`
+			AcceptLanguage      string `yaml:"accept_language" mapstructure:"accept_language"`
+			RetryAttempts       int    `yaml:"retry_attempts" mapstructure:"retry_attempts"`
+			RetryBackoff        int    `yaml:"retry_backoff" mapstructure:"retry_backoff"`
+			PageParallelism     int    `yaml:"page_parallelism" mapstructure:"page_parallelism"`
 		}{Timeout: int(testConfig.Timeout.Seconds())},
 		Limits: struct {
 			Accounts     int `yaml:"accounts" mapstructure:"accounts"`
 			Transactions int `yaml:"transactions" mapstructure:"transactions"`
 			Categories   int `yaml:"categories" mapstructure:"categories"`
 			Budgets      int `yaml:"budgets" mapstructure:"budgets"`
+			Tags         int `yaml:"tags" mapstructure:"tags"`
+			Bills        int `yaml:"bills" mapstructure:"bills"`
+			Recurrences  int `yaml:"recurrences" mapstructure:"recurrences"`
+			Rules        int `yaml:"rules" mapstructure:"rules"`
+			Search       int `yaml:"search" mapstructure:"search"`
 		}{
 			Accounts:     10,
 			Transactions: 5,
 			Categories:   10,
 			Budgets:      10,
+			Tags:         10,
+			Bills:        10,
+			Recurrences:  10,
+			Rules:        10,
+			Search:       5,
 		},
 		MCP: struct {
 			Name         string `yaml:"name" mapstructure:"name"`
@@ -0,0 +1,29 @@
+package fireflyMCP
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// toolExamplesJSON holds worked example arguments per tool. The examples are
+// validated against the tool input schemas by the tests.
+//
+//go:embed tool_examples.json
+var toolExamplesJSON []byte
+
+// ToolExample is a worked example call of a tool
+type ToolExample struct {
+	Description string         `json:"description"`
+	Arguments   map[string]any `json:"arguments"`
+}
+
+// toolExamples returns the embedded examples by tool name
+var toolExamples = sync.OnceValue(func() map[string][]ToolExample {
+	var examples map[string][]ToolExample
+	if err := json.Unmarshal(toolExamplesJSON, &examples); err != nil {
+		panic(fmt.Sprintf("invalid tool_examples.json: %v", err))
+	}
+	return examples
+})
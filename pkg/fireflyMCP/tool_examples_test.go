@@ -0,0 +1,51 @@
+package fireflyMCP
+
+import (
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolExamples_MatchInputSchemas(t *testing.T) {
+	server, err := NewFireflyMCPServer(newPluginTestConfig())
+	require.NoError(t, err)
+
+	examples := toolExamples()
+	require.NotEmpty(t, examples)
+
+	for name, toolExamples := range examples {
+		t.Run(name, func(t *testing.T) {
+			registered, ok := server.tools[name]
+			require.True(t, ok, "examples for unknown tool %s", name)
+			assert.LessOrEqual(t, len(toolExamples), 2, "keep examples short")
+
+			schema, ok := registered.tool.InputSchema.(*jsonschema.Schema)
+			require.True(t, ok)
+			resolved, err := schema.Resolve(nil)
+			require.NoError(t, err)
+
+			for _, example := range toolExamples {
+				assert.NotEmpty(t, example.Description)
+				assert.NoError(t, resolved.Validate(example.Arguments), example.Description)
+			}
+			assert.Equal(t, toolExamples, registered.tool.Meta["examples"])
+		})
+	}
+}
+
+func TestToolExamples_CoverWriteTools(t *testing.T) {
+	server, err := NewFireflyMCPServer(newPluginTestConfig())
+	require.NoError(t, err)
+
+	examples := toolExamples()
+	for _, name := range []string{"store_transaction", "store_transactions_bulk", "update_transaction", "create_rule"} {
+		assert.NotEmpty(t, examples[name], "%s should have examples", name)
+	}
+	for name, registered := range server.tools {
+		if _, ok := examples[name]; !ok {
+			assert.Nil(t, registered.tool.Meta["examples"], name)
+		}
+	}
+}
@@ -0,0 +1,322 @@
+package fireflyMCP
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// validateToolOverrides checks the tools section of the configuration. Whether
+// the named tools exist is checked once they are registered (checkToolOverrides).
+func validateToolOverrides(tools map[string]ToolOverride) error {
+	aliases := make(map[string]string)
+	for _, name := range sortedToolNames(tools) {
+		override := tools[name]
+		if !reportNamePattern.MatchString(name) {
+			return fmt.Errorf("tools.%s: tool name must be snake_case (a-z, 0-9, _)", name)
+		}
+		if override.Timeout < 0 {
+			return fmt.Errorf("tools.%s.timeout must not be negative", name)
+		}
+		if override.CacheTTL < 0 {
+			return fmt.Errorf("tools.%s.cache_ttl must not be negative", name)
+		}
+		if override.MaxLimit < 0 {
+			return fmt.Errorf("tools.%s.max_limit must not be negative", name)
+		}
+		if override.Summary != "" {
+			if _, err := parseSummaryTemplate(name, override.Summary); err != nil {
+				return fmt.Errorf("tools.%s.summary: %w", name, err)
+			}
+		}
+		for _, alias := range override.Aliases {
+			if !reportNamePattern.MatchString(alias) {
+				return fmt.Errorf("tools.%s.aliases: %q must be snake_case (a-z, 0-9, _)", name, alias)
+			}
+			if other, ok := aliases[alias]; ok {
+				return fmt.Errorf("tools.%s.aliases: %q is already an alias of %s", name, alias, other)
+			}
+			if _, ok := tools[alias]; ok {
+				return fmt.Errorf("tools.%s.aliases: %q is configured as a tool itself", name, alias)
+			}
+			aliases[alias] = name
+		}
+	}
+	return nil
+}
+
+// sortedToolNames returns the tool names of the tools section in a stable order
+func sortedToolNames(tools map[string]ToolOverride) []string {
+	names := make([]string, 0, len(tools))
+	for name := range tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// toolOverride returns the tools.<name> configuration of a tool
+func (s *FireflyMCPServer) toolOverride(name string) (ToolOverride, bool) {
+	config := s.currentConfig()
+	if config == nil {
+		return ToolOverride{}, false
+	}
+	override, ok := config.Tools[name]
+	return override, ok
+}
+
+// toolDisabled reports whether a tool is disabled with tools.<name>.enabled
+func (s *FireflyMCPServer) toolDisabled(name string) bool {
+	override, _ := s.toolOverride(name)
+	return override.Enabled != nil && !*override.Enabled
+}
+
+// checkToolOverrides verifies that every tool configured in the tools section
+// was registered, and that its aliases could be registered as well
+func (s *FireflyMCPServer) checkToolOverrides() error {
+	config := s.currentConfig()
+	if config == nil {
+		return nil
+	}
+	for _, name := range sortedToolNames(config.Tools) {
+		override := config.Tools[name]
+		if s.toolDisabled(name) {
+			continue
+		}
+		registered, ok := s.tools[name]
+		if !ok {
+			return fmt.Errorf("tools.%s: unknown tool", name)
+		}
+		if override.CacheTTL > 0 && !s.isReadOnlyTool(name) {
+			return fmt.Errorf("tools.%s.cache_ttl: only read-only tools can be cached", name)
+		}
+		for _, alias := range override.Aliases {
+			if aliased, ok := s.tools[alias]; !ok || aliased.aliasOf != registered.tool.Name {
+				return fmt.Errorf("tools.%s.aliases: %q clashes with another tool", name, alias)
+			}
+		}
+	}
+	return nil
+}
+
+// withToolTimeout limits the duration of a whole tool call. Every request made
+// by the tool is still limited by client.timeout as well.
+func withToolTimeout[In any](timeout time.Duration, handler mcp.ToolHandlerFor[In, any]) mcp.ToolHandlerFor[In, any] {
+	if timeout <= 0 {
+		return handler
+	}
+	return func(ctx context.Context, req *mcp.CallToolRequest, args In) (*mcp.CallToolResult, any, error) {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return handler(ctx, req, args)
+	}
+}
+
+// toolResultCache keeps successful results of tools with a configured cache_ttl,
+// per tenant, tool and arguments. Every tenant has at most
+// tenants.cache_entries results; when it is full, expired results and then the
+// ones expiring first are evicted. With shared storage the results are kept
+// there instead, so all replicas use them, and expire by themselves.
+type toolResultCache struct {
+	mu      sync.Mutex
+	tenants map[string]map[string]toolResultCacheEntry // By tenant, then tool and arguments
+	shared  Storage                                    // Shared storage, nil to keep results in memory
+	flushes uint64                                     // Bumped by flush, so results computed before are not kept
+}
+
+// sharedToolResult is a cached result as kept in shared storage
+type sharedToolResult struct {
+	Result  *mcp.CallToolResult `json:"result"`
+	Expires time.Time           `json:"expires"`
+}
+
+type toolResultCacheEntry struct {
+	result     *mcp.CallToolResult
+	out        any
+	expires    time.Time
+	generation uint64 // Value of generation before the result was computed
+}
+
+// newToolResultCache returns a cache kept in shared storage, or in memory when
+// shared is nil
+func newToolResultCache(shared Storage) *toolResultCache {
+	return &toolResultCache{tenants: make(map[string]map[string]toolResultCacheEntry), shared: shared}
+}
+
+// sharedKey is the storage key of a result: the tenant and a hash of tool and arguments
+func sharedKey(tenant, key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return tenant + "/" + hex.EncodeToString(sum[:])
+}
+
+// get returns a cached result that has not expired
+func (c *toolResultCache) get(tenant, key string) (toolResultCacheEntry, bool) {
+	if c.shared != nil {
+		value, err := c.shared.Get(storageBucketResults, sharedKey(tenant, key))
+		if err != nil {
+			return toolResultCacheEntry{}, false
+		}
+		var cached sharedToolResult
+		if json.Unmarshal(value, &cached) != nil || cached.Result == nil || !time.Now().Before(cached.Expires) {
+			return toolResultCacheEntry{}, false
+		}
+		return toolResultCacheEntry{result: cached.Result, expires: cached.Expires}, true
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.tenants[tenant][key]
+	if !ok || !time.Now().Before(entry.expires) {
+		return toolResultCacheEntry{}, false
+	}
+	return entry, true
+}
+
+// generation changes whenever the cache is flushed
+func (c *toolResultCache) generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.flushes
+}
+
+// put caches a result, evicting others of the tenant to stay within limit.
+// Results computed before the last flush are dropped.
+func (c *toolResultCache) put(tenant, key string, entry toolResultCacheEntry, limit int) {
+	if c.shared != nil {
+		c.putShared(tenant, key, entry)
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry.generation != c.flushes {
+		return
+	}
+	entries := c.tenants[tenant]
+	if entries == nil {
+		entries = make(map[string]toolResultCacheEntry)
+		c.tenants[tenant] = entries
+	}
+	if _, ok := entries[key]; !ok && len(entries) >= limit {
+		now := time.Now()
+		for k, cached := range entries {
+			if !now.Before(cached.expires) {
+				delete(entries, k)
+			}
+		}
+		for len(entries) >= limit {
+			var oldest string
+			for k, cached := range entries {
+				if oldest == "" || cached.expires.Before(entries[oldest].expires) {
+					oldest = k
+				}
+			}
+			delete(entries, oldest)
+		}
+	}
+	entries[key] = entry
+}
+
+// putShared stores a result in shared storage. The structured output is not
+// kept; caching is best effort, so failures are only logged.
+func (c *toolResultCache) putShared(tenant, key string, entry toolResultCacheEntry) {
+	value, err := json.Marshal(sharedToolResult{Result: entry.result, Expires: entry.expires})
+	if err == nil {
+		if expiring, ok := c.shared.(expiringStorage); ok {
+			err = expiring.PutTTL(storageBucketResults, sharedKey(tenant, key), value, time.Until(entry.expires))
+		} else {
+			err = c.shared.Put(storageBucketResults, sharedKey(tenant, key), value)
+		}
+	}
+	if err != nil {
+		slog.Warn("Failed to cache a tool result in shared storage", "error", err)
+	}
+}
+
+// size returns how many results are cached
+func (c *toolResultCache) size() int {
+	if c.shared != nil {
+		items, err := c.shared.List(storageBucketResults)
+		if err != nil {
+			return 0
+		}
+		return len(items)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	size := 0
+	for _, entries := range c.tenants {
+		size += len(entries)
+	}
+	return size
+}
+
+// flush drops all entries and returns how many were dropped
+func (c *toolResultCache) flush() int {
+	if c.shared != nil {
+		items, err := c.shared.List(storageBucketResults)
+		if err != nil {
+			slog.Warn("Failed to flush the tool results in shared storage", "error", err)
+			return 0
+		}
+		for _, item := range items {
+			c.shared.Delete(storageBucketResults, item.Key)
+		}
+		return len(items)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	flushed := 0
+	for _, entries := range c.tenants {
+		flushed += len(entries)
+	}
+	c.tenants = make(map[string]map[string]toolResultCacheEntry)
+	c.flushes++
+	return flushed
+}
+
+// withToolCache returns cached results of a read-only tool for ttl after a
+// successful call by the same tenant with the same arguments
+func withToolCache[In any](s *FireflyMCPServer, tool *mcp.Tool, ttl time.Duration, handler mcp.ToolHandlerFor[In, any]) mcp.ToolHandlerFor[In, any] {
+	if ttl <= 0 || s.toolCache == nil || tool.Annotations == nil || !tool.Annotations.ReadOnlyHint {
+		return handler
+	}
+	return func(ctx context.Context, req *mcp.CallToolRequest, args In) (*mcp.CallToolResult, any, error) {
+		raw, err := json.Marshal(args)
+		if err != nil {
+			return handler(ctx, req, args)
+		}
+		tenant := s.tenantKey(req)
+		key := tool.Name + "\x00" + string(raw)
+
+		if entry, ok := s.toolCache.get(tenant, key); ok {
+			return copyToolResult(entry.result), entry.out, nil
+		}
+
+		generation := s.toolCache.generation()
+		result, out, err := handler(ctx, req, args)
+		if err != nil || result == nil || result.IsError {
+			return result, out, err
+		}
+		s.toolCache.put(tenant, key, toolResultCacheEntry{
+			result: copyToolResult(result), out: out, expires: time.Now().Add(ttl), generation: generation,
+		}, s.tenantCacheEntries())
+		return result, out, nil
+	}
+}
+
+// copyToolResult copies a result so later changes to its metadata (e.g.
+// formatting hints) do not reach the cached result
+func copyToolResult(result *mcp.CallToolResult) *mcp.CallToolResult {
+	copied := *result
+	copied.Meta = maps.Clone(result.Meta)
+	copied.Content = append([]mcp.Content(nil), result.Content...)
+	return &copied
+}
@@ -0,0 +1,115 @@
+package fireflyMCP
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateToolOverrides(t *testing.T) {
+	assert.NoError(t, validateToolOverrides(map[string]ToolOverride{
+		"list_transactions": {Timeout: 10, CacheTTL: 60, MaxLimit: 100, Aliases: []string{"transactions"}},
+	}))
+
+	tests := map[string]map[string]ToolOverride{
+		"must be snake_case":          {"List-Transactions": {}},
+		"timeout must not be":         {"list_tags": {Timeout: -1}},
+		"cache_ttl must not be":       {"list_tags": {CacheTTL: -1}},
+		"max_limit must not be":       {"list_tags": {MaxLimit: -1}},
+		"\"Tags\" must be snake_case": {"list_tags": {Aliases: []string{"Tags"}}},
+		"already an alias of":         {"list_tags": {Aliases: []string{"tags"}}, "search_tags": {Aliases: []string{"tags"}}},
+		"configured as a tool":        {"list_tags": {Aliases: []string{"get_tag"}}, "get_tag": {}},
+		"tools.list_tags.summary":     {"list_tags": {Summary: "{{len .data"}},
+	}
+	for want, tools := range tests {
+		err := validateToolOverrides(tools)
+		require.Error(t, err, want)
+		assert.Contains(t, err.Error(), want)
+	}
+}
+
+func TestToolOverridesAdjustRegistration(t *testing.T) {
+	disabled := false
+	config := newPluginTestConfig()
+	config.Tools = map[string]ToolOverride{
+		"delete_rule": {Enabled: &disabled},
+		"list_tags":   {Aliases: []string{"tags"}, DescriptionSuffix: "Tags are used for trips."},
+	}
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	assert.False(t, server.hasTool("delete_rule"))
+	require.True(t, server.hasTool("tags"))
+	assert.Equal(t, "list_tags", server.tools["tags"].aliasOf)
+	assert.True(t, server.isReadOnlyTool("tags"))
+	assert.Contains(t, server.tools["list_tags"].tool.Description, "Tags are used for trips.")
+	assert.Contains(t, server.tools["tags"].tool.Description, "(alias of list_tags)")
+}
+
+func TestToolOverridesRejectedAtStartup(t *testing.T) {
+	tests := map[string]map[string]ToolOverride{
+		"tools.list_tagz: unknown tool":                 {"list_tagz": {}},
+		"only read-only tools can be cached":            {"delete_rule": {CacheTTL: 60}},
+		"\"get_account\" clashes with another tool":     {"list_tags": {Aliases: []string{"get_account"}}},
+		"\"list_categories\" clashes with another tool": {"list_tags": {Aliases: []string{"list_categories"}}},
+	}
+	for want, tools := range tests {
+		config := newPluginTestConfig()
+		config.Tools = tools
+		_, err := NewFireflyMCPServer(config)
+		require.Error(t, err, want)
+		assert.Contains(t, err.Error(), want)
+	}
+}
+
+func TestToolResultCache(t *testing.T) {
+	var requests atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		w.Write([]byte(`{"data":[],"meta":{"pagination":{"total":0,"count":0,"per_page":50,"current_page":1,"total_pages":1}}}`))
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	config.Tools = map[string]ToolOverride{"list_tags": {CacheTTL: 60}}
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		result, err := server.tools["list_tags"].invoke(context.Background(), nil, []byte(`{"page":1}`))
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+	}
+	assert.Equal(t, int32(1), requests.Load())
+
+	// Other arguments are cached separately
+	_, err = server.tools["list_tags"].invoke(context.Background(), nil, []byte(`{"page":2}`))
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), requests.Load())
+
+	assert.Equal(t, 2, server.FlushCaches())
+	_, err = server.tools["list_tags"].invoke(context.Background(), nil, []byte(`{"page":1}`))
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), requests.Load())
+}
+
+func TestWithToolTimeout(t *testing.T) {
+	var deadline time.Time
+	handler := withToolTimeout(time.Minute, func(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+		deadline, _ = ctx.Deadline()
+		return newSuccessResult("ok")
+	})
+
+	_, _, err := handler(context.Background(), nil, struct{}{})
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, 5*time.Second)
+}
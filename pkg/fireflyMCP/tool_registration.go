@@ -0,0 +1,133 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// toolInvoker calls a registered tool with raw JSON arguments. It lets tools
+// defined at runtime (e.g. config-defined reports) call other tools by name.
+type toolInvoker func(ctx context.Context, req *mcp.CallToolRequest, args json.RawMessage) (*mcp.CallToolResult, error)
+
+// registeredTool is a tool registered through addTool
+type registeredTool struct {
+	tool    *mcp.Tool
+	invoke  toolInvoker
+	aliasOf string               // Name of the aliased tool, for aliases configured in tools.<name>.aliases
+	relist  func(tool *mcp.Tool) // Registers the tool again with changed metadata
+}
+
+// addTool registers a tool on the MCP server after applying server-wide
+// adjustments to its metadata (e.g. documenting effective default limits).
+// All tools should be registered through this function rather than mcp.AddTool.
+// The tools.<name> configuration can disable a tool, register it under aliases
+// and wrap its handler with a timeout or a result cache.
+func addTool[In any](s *FireflyMCPServer, tool *mcp.Tool, handler mcp.ToolHandlerFor[In, any]) {
+	if s.toolDisabled(tool.Name) {
+		return
+	}
+	override, _ := s.toolOverride(tool.Name)
+	tool.Description = s.describeTool(tool.Name, tool.Description)
+	// Infer the input schema here rather than in mcp.AddTool, which only sets it
+	// on its own copy of the tool, so explain_tool can show it
+	if tool.InputSchema == nil {
+		if schema, err := jsonschema.For[In](nil); err == nil {
+			tool.InputSchema = schema
+		}
+	}
+	if examples := toolExamples()[tool.Name]; len(examples) > 0 {
+		if tool.Meta == nil {
+			tool.Meta = mcp.Meta{}
+		}
+		tool.Meta["examples"] = examples
+	}
+	handler = withToolCallLogging(tool.Name, withReadOnlyGuard(s, tool, withCapabilityGuard(s, tool.Name, withCatalogInvalidation(s, tool,
+		withToolCache(s, tool, time.Duration(override.CacheTTL)*time.Second, withAmountDirections(s, tool,
+			withWebLinks(s, tool.Name, withResponseRedaction(s, tool, handler))))))))
+	handler = withToolTimeout(time.Duration(override.Timeout)*time.Second, handler)
+
+	if s.tools == nil {
+		s.tools = make(map[string]*registeredTool)
+	}
+	invoke := func(ctx context.Context, req *mcp.CallToolRequest, raw json.RawMessage) (*mcp.CallToolResult, error) {
+		var args In
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, &args); err != nil {
+				return nil, fmt.Errorf("invalid arguments for %s: %w", tool.Name, err)
+			}
+		}
+		result, _, err := handler(ctx, req, args)
+		return result, err
+	}
+
+	registered := []*mcp.Tool{tool}
+	for _, alias := range override.Aliases {
+		// Aliases never replace other tools; checkToolOverrides reports the clash
+		if s.hasTool(alias) {
+			continue
+		}
+		aliasTool := *tool
+		aliasTool.Name = alias
+		aliasTool.Description = fmt.Sprintf("%s (alias of %s)", tool.Description, tool.Name)
+		registered = append(registered, &aliasTool)
+	}
+	for _, t := range registered {
+		// Formatting hints, summaries, retry notes, session statistics, the schema version and replay
+		// recording only apply to calls made by the client, not to tools invoked by reports and composite tools
+		clientHandler := withReplayRecording(s, t.Name, withSessionStats(s, t, withSchemaVersion(
+			withFormattingHints(s, withToolSummary(s, tool.Name, withRetryNotes(handler))))))
+		mcp.AddTool(s.server, t, clientHandler)
+		relist := func(updated *mcp.Tool) { mcp.AddTool(s.server, updated, clientHandler) }
+		entry := &registeredTool{tool: t, invoke: invoke, relist: relist}
+		if t != tool {
+			entry.aliasOf = tool.Name
+		}
+		s.tools[t.Name] = entry
+	}
+}
+
+// hasTool reports whether a tool with the given name has been registered
+func (s *FireflyMCPServer) hasTool(name string) bool {
+	_, ok := s.tools[name]
+	return ok
+}
+
+// describeTool builds the runtime description for a tool from its static description
+func (s *FireflyMCPServer) describeTool(name, description string) string {
+	if limit := s.defaultLimit(name); limit > 0 {
+		description += fmt.Sprintf(" (returns up to %d items per page unless limit is set)", limit)
+	}
+	if override, ok := s.toolOverride(name); ok && override.MaxLimit > 0 {
+		description += fmt.Sprintf(" (limit is capped at %d)", override.MaxLimit)
+	}
+	if s.trashEnabled() && strings.HasPrefix(name, "delete_") {
+		description += " (moves it to the trash: it is deactivated and only deleted after the grace period or purge_trash)"
+	}
+	if override, ok := s.toolOverride(name); ok && override.DescriptionSuffix != "" {
+		description += " " + override.DescriptionSuffix
+	}
+	return description
+}
+
+// withToolCallLogging logs every call of a tool with its arguments at debug level.
+// Arguments are logged as decoded JSON, so the logger's Redactor can mask
+// sensitive fields in them.
+func withToolCallLogging[In any](name string, handler mcp.ToolHandlerFor[In, any]) mcp.ToolHandlerFor[In, any] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, args In) (*mcp.CallToolResult, any, error) {
+		if slog.Default().Enabled(ctx, slog.LevelDebug) {
+			var decoded any
+			if raw, err := json.Marshal(args); err == nil {
+				_ = json.Unmarshal(raw, &decoded)
+			}
+			slog.DebugContext(ctx, "tool call", "tool", name, "arguments", decoded)
+		}
+		return handler(ctx, req, args)
+	}
+}
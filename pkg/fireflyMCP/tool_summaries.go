@@ -0,0 +1,111 @@
+package fireflyMCP
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"maps"
+	"strings"
+	"text/template"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultSummaryTemplates are the built-in summaries added with
+// formatting.summaries. Templates get the decoded JSON result as dot.
+var defaultSummaryTemplates = map[string]string{
+	"list_accounts":     `{{len .data}} accounts on page {{.pagination.current_page}} of {{.pagination.total_pages}} ({{.pagination.total}} in total)`,
+	"list_budgets":      `{{len .data}} budgets{{range .spent_totals}}, {{amount (abs .sum)}} {{.currency_code}} spent{{end}}`,
+	"list_transactions": `{{len .data}} transaction groups on page {{.pagination.current_page}} of {{.pagination.total_pages}} ({{.pagination.total}} in total)`,
+	"get_summary":       `{{range $i, $entry := .data}}{{if $i}}; {{end}}{{$entry.title}}: {{$entry.monetary_value}}{{end}}`,
+	"expense_category_insights": `{{len .entries}} categories{{range .totals}}, {{amount (abs .amount)}} {{.currency_code}} in total{{end}}` +
+		`{{with largest .entries}}; largest: {{.name}} ({{.share}}%){{end}}`,
+}
+
+// summaryFuncs are the helper functions available to summary templates, on top
+// of the report template functions
+var summaryFuncs = func() template.FuncMap {
+	funcs := maps.Clone(reportFuncs)
+	funcs["abs"] = func(v any) decimal { return templateDecimal(v).Abs() }
+	// largest returns the entry with the largest share, or nil
+	funcs["largest"] = func(entries []any) any {
+		var largest map[string]any
+		var largestShare decimal
+		for _, item := range entries {
+			entry, _ := item.(map[string]any)
+			share, _ := entry["share"].(string)
+			if value, ok := parseDecimal(share); ok && (largest == nil || value.Cmp(largestShare) > 0) {
+				largest, largestShare = entry, value
+			}
+		}
+		if largest == nil {
+			return nil
+		}
+		return largest
+	}
+	return funcs
+}()
+
+// parseSummaryTemplate parses a summary template of a tool
+func parseSummaryTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(summaryFuncs).Option("missingkey=zero").Parse(text)
+}
+
+// summaryTemplate returns the summary template of a tool: the one configured in
+// tools.<name>.summary, else the built-in one if formatting.summaries is set
+func (s *FireflyMCPServer) summaryTemplate(name string) string {
+	if override, ok := s.toolOverride(name); ok && override.Summary != "" {
+		return override.Summary
+	}
+	if config := s.currentConfig(); config != nil && config.Formatting.Summaries {
+		return defaultSummaryTemplates[name]
+	}
+	return ""
+}
+
+// withToolSummary adds a short textual summary after the JSON of successful
+// tool results, for clients that show raw tool output. Results that are not
+// JSON objects, and templates that fail to render, get no summary.
+func withToolSummary[In any](s *FireflyMCPServer, name string, handler mcp.ToolHandlerFor[In, any]) mcp.ToolHandlerFor[In, any] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, args In) (*mcp.CallToolResult, any, error) {
+		result, out, err := handler(ctx, req, args)
+		if err != nil || result == nil || result.IsError || len(result.Content) == 0 {
+			return result, out, err
+		}
+		text := s.summaryTemplate(name)
+		if text == "" {
+			return result, out, err
+		}
+		if summary, renderErr := renderToolSummary(name, text, result); renderErr != nil {
+			slog.DebugContext(ctx, "tool summary not rendered", "tool", name, "error", renderErr)
+		} else if summary != "" {
+			// Copy the result, it may be shared with the result cache
+			summarized := *result
+			summarized.Content = append(append([]mcp.Content(nil), result.Content...), &mcp.TextContent{Text: summary})
+			result = &summarized
+		}
+		return result, out, err
+	}
+}
+
+// renderToolSummary renders a summary template against the JSON of a result
+func renderToolSummary(name, text string, result *mcp.CallToolResult) (string, error) {
+	content, ok := result.Content[0].(*mcp.TextContent)
+	if !ok {
+		return "", nil
+	}
+	var data map[string]any
+	if err := json.Unmarshal([]byte(content.Text), &data); err != nil {
+		return "", nil
+	}
+	tmpl, err := parseSummaryTemplate(name, text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
@@ -0,0 +1,63 @@
+package fireflyMCP
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultSummaryTemplates(t *testing.T) {
+	for name, text := range defaultSummaryTemplates {
+		_, err := parseSummaryTemplate(name, text)
+		assert.NoError(t, err, name)
+	}
+
+	result, _, err := newSuccessResult(BudgetList{
+		Data:        []Budget{{Name: "Food"}, {Name: "Rent"}},
+		SpentTotals: []BudgetSpent{{Sum: "-512.3", CurrencyCode: "EUR"}},
+	})
+	require.NoError(t, err)
+	summary, err := renderToolSummary("list_budgets", defaultSummaryTemplates["list_budgets"], result)
+	require.NoError(t, err)
+	assert.Equal(t, "2 budgets, 512.30 EUR spent", summary)
+
+	result, _, err = newSuccessResult(InsightCategoryResponse{
+		Entries: []InsightCategoryEntry{
+			{Name: "Groceries", Amount: "-150.50", CurrencyCode: "EUR", Share: "66.74"},
+			{Name: "Transport", Amount: "-75.00", CurrencyCode: "EUR", Share: "33.26"},
+		},
+		Totals: []InsightTotalEntry{{Amount: "-225.50", CurrencyCode: "EUR"}},
+	})
+	require.NoError(t, err)
+	summary, err = renderToolSummary("expense_category_insights", defaultSummaryTemplates["expense_category_insights"], result)
+	require.NoError(t, err)
+	assert.Equal(t, "2 categories, 225.50 EUR in total; largest: Groceries (66.74%)", summary)
+}
+
+func TestWithToolSummary(t *testing.T) {
+	config := newPluginTestConfig()
+	config.Tools = map[string]ToolOverride{"list_tags": {Summary: "{{len .data}} tags"}}
+	server := &FireflyMCPServer{config: config}
+
+	shared, _, err := newSuccessResult(map[string]any{"data": []string{"trip", "work"}})
+	require.NoError(t, err)
+	handler := withToolSummary(server, "list_tags", func(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+		return shared, nil, nil
+	})
+
+	for i := 0; i < 2; i++ {
+		result, _, err := handler(context.Background(), nil, struct{}{})
+		require.NoError(t, err)
+		require.Len(t, result.Content, 2)
+		assert.Equal(t, "2 tags", result.Content[1].(*mcp.TextContent).Text)
+	}
+	assert.Len(t, shared.Content, 1, "the handler's result must not be modified")
+
+	// Built-in summaries only with formatting.summaries
+	assert.Empty(t, server.summaryTemplate("list_budgets"))
+	config.Formatting.Summaries = true
+	assert.Equal(t, defaultSummaryTemplates["list_budgets"], server.summaryTemplate("list_budgets"))
+}
@@ -0,0 +1,188 @@
+package fireflyMCP
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// TransactionSplitPatch changes some fields of one split (update_transaction patch mode)
+type TransactionSplitPatch struct {
+	JournalId string         `json:"journal_id" jsonschema:"Journal ID of the split to change (required, see journal_id in results)"`
+	Set       map[string]any `json:"set,omitempty" jsonschema:"Fields to change with their new values, named as in transactions (e.g. category_name, amount, tags)"`
+	Clear     []string       `json:"clear,omitempty" jsonschema:"Fields to remove from the split (e.g. budget_id, bill_id, notes, tags)"`
+}
+
+// requiredSplitFields can be changed by a patch but not cleared
+var requiredSplitFields = map[string]bool{"type": true, "date": true, "amount": true, "description": true}
+
+// patchableSplitFields returns the JSON names of the split fields a patch may change
+func patchableSplitFields() map[string]bool {
+	fields := make(map[string]bool)
+	splitType := reflect.TypeFor[TransactionSplitRequest]()
+	for i := 0; i < splitType.NumField(); i++ {
+		name := strings.Split(splitType.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "journal_id" {
+			fields[name] = true
+		}
+	}
+	return fields
+}
+
+// patchTransaction composes the update of a transaction from patches of single
+// splits. Every split of the group is sent with its journal ID, because Firefly
+// III deletes the splits missing from an update, and only the patched fields are
+// sent, so all other fields keep their values.
+func (s *FireflyMCPServer) patchTransaction(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args UpdateTransactionArgs,
+) (*mcp.CallToolResult, any, error) {
+	if len(args.Transactions) > 0 {
+		return newErrorResult("Error: use either transactions or patch, not both")
+	}
+
+	patches := make(map[string]TransactionSplitPatch, len(args.Patch))
+	for i, patch := range args.Patch {
+		if patch.JournalId == "" {
+			return newErrorResult(fmt.Sprintf("Error: patch[%d].journal_id is required", i))
+		}
+		if _, ok := patches[patch.JournalId]; ok {
+			return newErrorResult(fmt.Sprintf("Error: patch[%d].journal_id %s is patched twice", i, patch.JournalId))
+		}
+		if len(patch.Set) == 0 && len(patch.Clear) == 0 {
+			return newErrorResult(fmt.Sprintf("Error: patch[%d] has neither set nor clear", i))
+		}
+		patches[patch.JournalId] = patch
+	}
+
+	current, err := callTool[GetTransactionArgs, TransactionGroup](ctx, req, s.handleGetTransaction, GetTransactionArgs{ID: args.ID})
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error getting transaction: %v", err))
+	}
+
+	var (
+		splits  []map[string]any
+		changed []TransactionSplitRequest
+		ids     []string
+		found   = make(map[string]bool)
+	)
+	for _, split := range current.Transactions {
+		ids = append(ids, split.JournalId)
+		update := map[string]any{"transaction_journal_id": split.JournalId}
+		recorded := TransactionSplitRequest{}
+		if patch, ok := patches[split.JournalId]; ok {
+			fields, typed, err := s.splitPatchFields(patch)
+			if err != nil {
+				return newErrorResult(fmt.Sprintf("Error: patch for journal_id %s: %v", split.JournalId, err))
+			}
+			for field, value := range fields {
+				update[field] = value
+			}
+			recorded = typed
+			found[split.JournalId] = true
+		}
+		splits = append(splits, update)
+		changed = append(changed, recorded)
+	}
+	for _, patch := range args.Patch {
+		if !found[patch.JournalId] {
+			return newErrorResult(fmt.Sprintf("Error: journal_id %s is not a split of transaction %s (splits: %s)",
+				patch.JournalId, args.ID, strings.Join(ids, ", ")))
+		}
+	}
+
+	body := map[string]any{"transactions": splits}
+	if args.ApplyRules {
+		body["apply_rules"] = true
+	}
+	if args.FireWebhooks {
+		body["fire_webhooks"] = true
+	}
+	if args.GroupTitle != "" {
+		body["group_title"] = args.GroupTitle
+	} else if current.GroupTitle != "" {
+		body["group_title"] = current.GroupTitle
+	}
+	return s.sendTransactionUpdate(ctx, req, "update_transaction", args.ID, body, args.GroupTitle, changed)
+}
+
+// splitPatchFields validates a patch and returns the fields to send for its
+// split, along with the patched values as a split update. Set values get the
+// same treatment as in transactions (relative dates, account aliases); a null
+// value or an empty tag list clears the field.
+func (s *FireflyMCPServer) splitPatchFields(patch TransactionSplitPatch) (map[string]any, TransactionSplitRequest, error) {
+	patchable := patchableSplitFields()
+	set := make(map[string]any, len(patch.Set))
+	clear := make(map[string]bool, len(patch.Clear))
+	for _, field := range patch.Clear {
+		clear[field] = true
+	}
+	for field, value := range patch.Set {
+		if !patchable[field] {
+			return nil, TransactionSplitRequest{}, fmt.Errorf("unknown field %q", field)
+		}
+		if clear[field] {
+			return nil, TransactionSplitRequest{}, fmt.Errorf("%s is both set and cleared", field)
+		}
+		if tags, ok := value.([]any); value == nil || (ok && len(tags) == 0) {
+			clear[field] = true
+			continue
+		}
+		set[field] = value
+	}
+
+	raw, err := json.Marshal(set)
+	if err != nil {
+		return nil, TransactionSplitRequest{}, err
+	}
+	var split TransactionSplitRequest
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&split); err != nil {
+		return nil, TransactionSplitRequest{}, fmt.Errorf("invalid value: %w", err)
+	}
+	split = s.resolveSplitDates([]TransactionSplitRequest{split})[0]
+	split = s.applyAccountAliases([]TransactionSplitRequest{split})[0]
+	if err := validateSplitUpdate(split); err != nil {
+		return nil, TransactionSplitRequest{}, err
+	}
+	if s.requireIDs() {
+		if err := checkSplitNameRefs(split); err != nil {
+			return nil, TransactionSplitRequest{}, err
+		}
+	}
+
+	raw, err = json.Marshal(split)
+	if err != nil {
+		return nil, TransactionSplitRequest{}, err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, TransactionSplitRequest{}, err
+	}
+	for field := range requiredSplitFields {
+		if fields[field] == "" {
+			delete(fields, field)
+		}
+	}
+
+	for field := range clear {
+		switch {
+		case !patchable[field]:
+			return nil, TransactionSplitRequest{}, fmt.Errorf("unknown field %q", field)
+		case requiredSplitFields[field]:
+			return nil, TransactionSplitRequest{}, fmt.Errorf("%s cannot be cleared", field)
+		case field == "tags":
+			fields[field] = []string{}
+		default:
+			fields[field] = nil
+		}
+	}
+	return fields, split, nil
+}
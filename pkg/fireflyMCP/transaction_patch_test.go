@@ -0,0 +1,86 @@
+package fireflyMCP
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleUpdateTransaction_Patch(t *testing.T) {
+	var updates []map[string]any
+	server := newBillLinkTestServer(t, &updates)
+
+	result, _, err := server.handleUpdateTransaction(context.Background(), nil, UpdateTransactionArgs{
+		ID: "40",
+		Patch: []TransactionSplitPatch{{
+			JournalId: "42",
+			Set:       map[string]any{"category_name": "Utilities", "amount": "32.50", "tags": []any{}},
+			Clear:     []string{"budget_id"},
+		}},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content[0].(*mcp.TextContent).Text)
+
+	require.Len(t, updates, 1)
+	assert.Equal(t, map[string]any{
+		"group_title": "Utilities",
+		"transactions": []any{
+			map[string]any{"transaction_journal_id": "41"},
+			map[string]any{
+				"transaction_journal_id": "42", "category_name": "Utilities", "amount": "32.50",
+				"tags": []any{}, "budget_id": nil,
+			},
+		},
+	}, updates[0])
+
+	history := server.changes.get(server.tenantKey(nil), "40")
+	require.Len(t, history, 1)
+	assert.Equal(t, "update_transaction", history[0].Tool)
+	assert.Equal(t, "Utilities", history[0].Fields["transactions[1].category_name"])
+}
+
+func TestHandleUpdateTransaction_PatchErrors(t *testing.T) {
+	var updates []map[string]any
+	server := newBillLinkTestServer(t, &updates)
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		args  UpdateTransactionArgs
+		error string
+	}{
+		{"unknown split", UpdateTransactionArgs{ID: "40", Patch: []TransactionSplitPatch{
+			{JournalId: "99", Set: map[string]any{"notes": "x"}},
+		}}, "journal_id 99 is not a split of transaction 40 (splits: 41, 42)"},
+		{"unknown field", UpdateTransactionArgs{ID: "40", Patch: []TransactionSplitPatch{
+			{JournalId: "41", Set: map[string]any{"colour": "red"}},
+		}}, `unknown field "colour"`},
+		{"required field cleared", UpdateTransactionArgs{ID: "40", Patch: []TransactionSplitPatch{
+			{JournalId: "41", Clear: []string{"amount"}},
+		}}, "amount cannot be cleared"},
+		{"invalid type", UpdateTransactionArgs{ID: "40", Patch: []TransactionSplitPatch{
+			{JournalId: "41", Set: map[string]any{"type": "refund"}},
+		}}, "type must be one of"},
+		{"wrong value type", UpdateTransactionArgs{ID: "40", Patch: []TransactionSplitPatch{
+			{JournalId: "41", Set: map[string]any{"amount": 12.5}},
+		}}, "invalid value"},
+		{"empty patch", UpdateTransactionArgs{ID: "40", Patch: []TransactionSplitPatch{{JournalId: "41"}}}, "neither set nor clear"},
+		{"patch and transactions", UpdateTransactionArgs{
+			ID:                       "40",
+			TransactionUpdateRequest: TransactionUpdateRequest{Transactions: []TransactionSplitRequest{{Amount: "1"}}},
+			Patch:                    []TransactionSplitPatch{{JournalId: "41", Set: map[string]any{"notes": "x"}}},
+		}, "either transactions or patch"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := server.handleUpdateTransaction(ctx, nil, tt.args)
+			require.NoError(t, err)
+			require.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, tt.error)
+		})
+	}
+	assert.Empty(t, updates)
+}
@@ -0,0 +1,169 @@
+package fireflyMCP
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/dezer32/mcp-firefly-iii/pkg/client"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxAccountCandidates bounds the accounts looked up for an account name
+const maxAccountCandidates = 10
+
+// resolveTransferAccounts checks that both sides of transfers are asset
+// accounts. A side given only by name is resolved to the asset account with
+// exactly that name; otherwise Firefly III may silently create an expense or
+// revenue account from the name. Unknown and ambiguous names are reported with
+// the candidates, so the caller can pick one by ID.
+func (s *FireflyMCPServer) resolveTransferAccounts(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	apiClient *client.ClientWithResponses,
+	splits []TransactionSplitRequest,
+) ([]TransactionSplitRequest, error) {
+	resolved := make([]TransactionSplitRequest, len(splits))
+	for i, split := range splits {
+		resolved[i] = split
+		if split.Type != "transfer" {
+			continue
+		}
+		sides := []struct {
+			field string
+			id    **string
+			name  **string
+		}{
+			{"source", &resolved[i].SourceId, &resolved[i].SourceName},
+			{"destination", &resolved[i].DestinationId, &resolved[i].DestinationName},
+		}
+		for _, side := range sides {
+			id, name := getStringValue(*side.id), strings.TrimSpace(getStringValue(*side.name))
+			switch {
+			case id != "":
+				account, ok := s.accountMetadata(ctx, req, apiClient, id)
+				if ok && accountTypeKind(account.accountType) != client.ShortAccountTypePropertyAsset {
+					return nil, fmt.Errorf(
+						"transaction[%d].%s_id %s is the %s account %q; transfers need asset accounts on both sides, "+
+							"use a withdrawal or deposit instead", i, side.field, id, account.accountType, account.name,
+					)
+				}
+			case name != "":
+				accountID, err := s.accountByName(ctx, req, apiClient, name, "asset", client.AccountTypeFilterAsset)
+				if err != nil {
+					return nil, fmt.Errorf("transaction[%d].%s_name: %w", i, side.field, err)
+				}
+				*side.id, *side.name = &accountID, nil
+			default:
+				return nil, fmt.Errorf("transaction[%d] is a transfer and needs %s_id or %s_name", i, side.field, side.field)
+			}
+		}
+	}
+	return resolved, nil
+}
+
+// accountByName returns the ID of the account of the given types with exactly
+// that name (case-insensitive). kind names the account types in errors. Names
+// are looked up in the account catalog; when it has no single match, the
+// autocomplete endpoint finds the candidates to report.
+func (s *FireflyMCPServer) accountByName(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	apiClient *client.ClientWithResponses,
+	name, kind string,
+	types ...client.AccountTypeFilter,
+) (string, error) {
+	if accounts, err := s.catalog(ctx, req, apiClient, catalogAccounts); err == nil {
+		var exact []string
+		for _, account := range accounts {
+			if strings.EqualFold(account.Name, name) && slices.ContainsFunc(types, func(t client.AccountTypeFilter) bool {
+				return accountTypeKind(string(t)) == account.Type
+			}) {
+				exact = append(exact, account.Id)
+			}
+		}
+		if len(exact) == 1 {
+			return exact[0], nil
+		}
+	}
+
+	limit := int32(maxAccountCandidates)
+	resp, err := apiClient.GetAccountsACWithResponse(ctx, &client.GetAccountsACParams{Query: &name, Limit: &limit, Types: &types})
+	if err != nil {
+		return "", fmt.Errorf("error looking up %s account %q: %v", kind, name, err)
+	}
+	if resp.StatusCode() != 200 {
+		return "", fmt.Errorf("API error %d looking up %s account %q: %s", resp.StatusCode(), kind, name, s.upstreamError(resp.Body))
+	}
+
+	var exact, candidates []string
+	if resp.JSON200 != nil {
+		for _, account := range *resp.JSON200 {
+			candidates = append(candidates, fmt.Sprintf("%s (%s)", account.Name, account.Id))
+			if strings.EqualFold(account.Name, name) {
+				exact = append(exact, account.Id)
+			}
+		}
+	}
+	switch {
+	case len(exact) == 1:
+		return exact[0], nil
+	case len(exact) > 1:
+		return "", fmt.Errorf("several %s accounts are named %q, set the ID instead: %s", kind, name, strings.Join(candidates, ", "))
+	case len(candidates) > 0:
+		return "", fmt.Errorf("no %s account is named %q; did you mean: %s", kind, name, strings.Join(candidates, ", "))
+	default:
+		return "", fmt.Errorf("no %s account is named %q", kind, name)
+	}
+}
+
+// allowAccountAutocreate reports whether Firefly III may create expense and
+// revenue accounts from unknown names. The argument overrides the setting.
+func (s *FireflyMCPServer) allowAccountAutocreate(override *bool) bool {
+	if override != nil {
+		return *override
+	}
+	config := s.currentConfig()
+	return config == nil || config.Accounts.AllowAutocreate
+}
+
+// resolveCounterpartyAccounts resolves the destination name of withdrawals and
+// the source name of deposits to an existing expense or revenue account (or a
+// liability), instead of letting Firefly III create an account from the name.
+func (s *FireflyMCPServer) resolveCounterpartyAccounts(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	apiClient *client.ClientWithResponses,
+	splits []TransactionSplitRequest,
+) ([]TransactionSplitRequest, error) {
+	resolved := make([]TransactionSplitRequest, len(splits))
+	for i, split := range splits {
+		resolved[i] = split
+		var (
+			field, kind string
+			id, name    **string
+			accountType client.AccountTypeFilter
+		)
+		switch split.Type {
+		case "withdrawal":
+			field, kind, accountType = "destination", "expense", client.AccountTypeFilterExpense
+			id, name = &resolved[i].DestinationId, &resolved[i].DestinationName
+		case "deposit":
+			field, kind, accountType = "source", "revenue", client.AccountTypeFilterRevenue
+			id, name = &resolved[i].SourceId, &resolved[i].SourceName
+		default:
+			continue
+		}
+		accountName := strings.TrimSpace(getStringValue(*name))
+		if getStringValue(*id) != "" || accountName == "" {
+			continue
+		}
+		accountID, err := s.accountByName(ctx, req, apiClient, accountName, kind, accountType, client.AccountTypeFilterLiabilities)
+		if err != nil {
+			return nil, fmt.Errorf("transaction[%d].%s_name: %w", i, field, err)
+		}
+		*id, *name = &accountID, nil
+	}
+	return resolved, nil
+}
@@ -0,0 +1,169 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTransferTestServer(t *testing.T, stored *[]map[string]any) *FireflyMCPServer {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch r.URL.Path {
+		case "/v1/accounts/1":
+			w.Write([]byte(`{"data":{"type":"accounts","id":"1","attributes":{"name":"Checking","type":"asset"}}}`))
+		case "/v1/accounts/5":
+			w.Write([]byte(`{"data":{"type":"accounts","id":"5","attributes":{"name":"Savings","type":"expense"}}}`))
+		case "/v1/autocomplete/accounts":
+			w.Header().Set("Content-Type", "application/json")
+			assert.Equal(t, "asset", r.URL.Query().Get("types"))
+			switch r.URL.Query().Get("query") {
+			case "savings":
+				w.Write([]byte(`[{"id":"2","name":"Savings","type":"Asset account"},{"id":"3","name":"Savings goal","type":"Asset account"}]`))
+			case "card":
+				w.Write([]byte(`[{"id":"6","name":"Card","type":"Asset account"},{"id":"7","name":"card","type":"Asset account"}]`))
+			case "holiday":
+				w.Write([]byte(`[{"id":"8","name":"Holiday fund","type":"Asset account"}]`))
+			default:
+				w.Write([]byte(`[]`))
+			}
+		case "/v1/transactions":
+			body, _ := io.ReadAll(r.Body)
+			var request map[string]any
+			require.NoError(t, json.Unmarshal(body, &request))
+			*stored = append(*stored, request)
+			w.Write([]byte(`{"data":{"type":"transactions","id":"40","attributes":{"transactions":[` +
+				`{"transaction_journal_id":"41","type":"transfer","date":"2024-03-01T00:00:00Z","amount":"100.00","description":"Save"}]}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+	return server
+}
+
+func TestStoreTransaction_ResolvesTransferAccounts(t *testing.T) {
+	var stored []map[string]any
+	server := newTransferTestServer(t, &stored)
+
+	checking, savings := "1", "savings"
+	result, _, err := server.handleStoreTransaction(context.Background(), nil, TransactionStoreRequest{
+		Transactions: []TransactionSplitRequest{{
+			Type: "transfer", Date: "2024-03-01", Amount: "100.00", Description: "Save", SourceId: &checking, DestinationName: &savings,
+		}},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content[0].(*mcp.TextContent).Text)
+
+	require.Len(t, stored, 1)
+	split := stored[0]["transactions"].([]any)[0].(map[string]any)
+	assert.Equal(t, "2", split["destination_id"])
+	assert.Nil(t, split["destination_name"])
+}
+
+func TestStoreTransaction_TransferAccountErrors(t *testing.T) {
+	var stored []map[string]any
+	server := newTransferTestServer(t, &stored)
+
+	tests := []struct {
+		name  string
+		split TransactionSplitRequest
+		error string
+	}{
+		{"expense account", TransactionSplitRequest{SourceId: ptr("1"), DestinationId: ptr("5")},
+			`destination_id 5 is the expense account "Savings"`},
+		{"ambiguous name", TransactionSplitRequest{SourceId: ptr("1"), DestinationName: ptr("card")},
+			"several asset accounts are named"},
+		{"no exact match", TransactionSplitRequest{SourceName: ptr("holiday"), DestinationId: ptr("1")},
+			"did you mean: Holiday fund (8)"},
+		{"unknown name", TransactionSplitRequest{SourceName: ptr("pension"), DestinationId: ptr("1")},
+			`no asset account is named "pension"`},
+		{"missing side", TransactionSplitRequest{SourceId: ptr("1")}, "needs destination_id or destination_name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			split := tt.split
+			split.Type, split.Date, split.Amount, split.Description = "transfer", "2024-03-01", "100.00", "Save"
+			result, _, err := server.handleStoreTransaction(context.Background(), nil, TransactionStoreRequest{
+				Transactions: []TransactionSplitRequest{split},
+			})
+			require.NoError(t, err)
+			require.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, tt.error)
+		})
+	}
+	assert.Empty(t, stored)
+}
+
+func TestStoreTransaction_AccountAutocreatePolicy(t *testing.T) {
+	var stored []map[string]any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/autocomplete/accounts":
+			w.Header().Set("Content-Type", "application/json")
+			assert.Equal(t, "expense,liabilities", r.URL.Query().Get("types"))
+			if r.URL.Query().Get("query") == "Groceries" {
+				w.Write([]byte(`[{"id":"9","name":"groceries","type":"Expense account"}]`))
+				return
+			}
+			w.Write([]byte(`[{"id":"10","name":"Grocery store","type":"Expense account"}]`))
+		case "/v1/transactions":
+			body, _ := io.ReadAll(r.Body)
+			var request map[string]any
+			require.NoError(t, json.Unmarshal(body, &request))
+			stored = append(stored, request)
+			w.Header().Set("Content-Type", "application/vnd.api+json")
+			w.Write([]byte(`{"data":{"type":"transactions","id":"40","attributes":{"transactions":[` +
+				`{"transaction_journal_id":"41","type":"withdrawal","date":"2024-03-01T00:00:00Z","amount":"12.00","description":"Food"}]}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	config.Accounts.AllowAutocreate = false
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	store := func(destination string, allow *bool) *mcp.CallToolResult {
+		result, _, err := server.handleStoreTransaction(context.Background(), nil, TransactionStoreRequest{
+			AllowAccountAutocreate: allow,
+			Transactions: []TransactionSplitRequest{{
+				Type: "withdrawal", Date: "2024-03-01", Amount: "12.00", Description: "Food", SourceId: ptr("1"), DestinationName: &destination,
+			}},
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	result := store("Groceries", nil)
+	require.False(t, result.IsError, result.Content[0].(*mcp.TextContent).Text)
+	split := stored[0]["transactions"].([]any)[0].(map[string]any)
+	assert.Equal(t, "9", split["destination_id"])
+	assert.Nil(t, split["destination_name"])
+
+	result = store("Grocerys", nil)
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "did you mean: Grocery store (10)")
+	assert.Len(t, stored, 1)
+
+	// The argument overrides the setting and leaves the name to Firefly III
+	result = store("Grocerys", ptr(true))
+	require.False(t, result.IsError)
+	require.Len(t, stored, 2)
+	assert.Equal(t, "Grocerys", stored[1]["transactions"].([]any)[0].(map[string]any)["destination_name"])
+}
@@ -0,0 +1,392 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Kinds of objects the delete tools can move to the trash
+const (
+	trashKindRule      = "rule"
+	trashKindRuleGroup = "rule_group"
+)
+
+// TrashEntry is an object a delete tool moved to the trash. The object is
+// deactivated in Firefly III and only deleted when the entry is purged.
+type TrashEntry struct {
+	ID         string          `json:"id"`
+	Kind       string          `json:"kind"`
+	ObjectId   string          `json:"object_id"`
+	Title      string          `json:"title"`
+	WasActive  bool            `json:"was_active"`
+	TrashedAt  string          `json:"trashed_at"`
+	PurgeAfter string          `json:"purge_after,omitempty"`
+	Payload    json.RawMessage `json:"payload"` // The object as it was trashed, enough to recreate it
+
+	owner   string    // Tenant key of the user who trashed the object
+	purgeAt time.Time // Zero if the entry is only purged by purge_trash
+}
+
+// ListTrashArgs represents the arguments for the list_trash tool
+type ListTrashArgs struct{}
+
+// RestoreFromTrashArgs represents the arguments for the restore_from_trash tool
+type RestoreFromTrashArgs struct {
+	ID string `json:"id" jsonschema:"Trash entry ID (required)"`
+}
+
+// PurgeTrashArgs represents the arguments for the purge_trash tool
+type PurgeTrashArgs struct {
+	ID  string `json:"id,omitempty" jsonschema:"Trash entry ID to delete permanently"`
+	All bool   `json:"all,omitempty" jsonschema:"Delete all trashed objects permanently"`
+}
+
+// TrashResult is the result of a delete tool when the trash is enabled, and of restore_from_trash
+type TrashResult struct {
+	Status string      `json:"status"`
+	Entry  *TrashEntry `json:"entry"`
+}
+
+// TrashList is the result of list_trash and purge_trash
+type TrashList struct {
+	Items  []TrashEntry `json:"items"`
+	Purged []TrashEntry `json:"purged"`
+	Errors []string     `json:"errors,omitempty"`
+}
+
+// trashStore keeps trashed objects, written through to the storage backend.
+// With the memory backend entries are lost on restart, which leaves the
+// objects deactivated in Firefly III rather than deleted. Shared storage is
+// read again on every lookup, so entries trashed by other replicas are seen.
+type trashStore struct {
+	mu      sync.Mutex
+	entries map[string]*TrashEntry
+	storage Storage
+}
+
+// storedTrashEntry is a trash entry as kept in the storage
+type storedTrashEntry struct {
+	TrashEntry
+	Owner string `json:"owner"`
+}
+
+// newTrashStore loads the entries kept in a storage, which may be nil
+func newTrashStore(storage Storage) *trashStore {
+	st := &trashStore{entries: make(map[string]*TrashEntry), storage: storage}
+	st.load()
+	return st
+}
+
+// load replaces the entries with those kept in the storage. The caller must
+// hold the lock once the store is in use.
+func (st *trashStore) load() {
+	if st.storage == nil {
+		return
+	}
+	items, err := st.storage.List(storageBucketTrash)
+	if err != nil {
+		slog.Warn("Failed to load the trash from storage", "error", err)
+		return
+	}
+	st.entries = make(map[string]*TrashEntry, len(items))
+	for _, item := range items {
+		var stored storedTrashEntry
+		if err := json.Unmarshal(item.Value, &stored); err != nil {
+			slog.Warn("Skipping unreadable trash entry in storage", "id", item.Key, "error", err)
+			continue
+		}
+		entry := stored.TrashEntry
+		entry.owner = stored.Owner
+		if entry.PurgeAfter != "" {
+			entry.purgeAt, _ = time.Parse(time.RFC3339, entry.PurgeAfter)
+		}
+		st.entries[entry.ID] = &entry
+	}
+}
+
+// refresh loads the entries again from shared storage. The caller must hold the lock.
+func (st *trashStore) refresh() {
+	if isShared(st.storage) {
+		st.load()
+	}
+}
+
+// add stores an entry under a new ID
+func (st *trashStore) add(entry *TrashEntry) error {
+	id, err := newResourceID()
+	if err != nil {
+		return err
+	}
+	entry.ID = id
+
+	if st.storage != nil {
+		value, err := json.Marshal(storedTrashEntry{TrashEntry: *entry, Owner: entry.owner})
+		if err != nil {
+			return err
+		}
+		if err := st.storage.Put(storageBucketTrash, id, value); err != nil {
+			return fmt.Errorf("failed to store trash entry: %w", err)
+		}
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.entries[id] = entry
+	return nil
+}
+
+// list returns the entries of an owner, oldest first
+func (st *trashStore) list(owner string) []*TrashEntry {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.refresh()
+	var entries []*TrashEntry
+	for _, entry := range st.entries {
+		if entry.owner == owner {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].TrashedAt != entries[j].TrashedAt {
+			return entries[i].TrashedAt < entries[j].TrashedAt
+		}
+		return entries[i].ID < entries[j].ID
+	})
+	return entries
+}
+
+// get returns an entry of an owner
+func (st *trashStore) get(owner, id string) (*TrashEntry, bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.refresh()
+	entry, ok := st.entries[id]
+	if !ok || entry.owner != owner {
+		return nil, false
+	}
+	return entry, true
+}
+
+// remove forgets an entry
+func (st *trashStore) remove(id string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	delete(st.entries, id)
+	if st.storage != nil {
+		if err := st.storage.Delete(storageBucketTrash, id); err != nil {
+			slog.Warn("Failed to delete trash entry from storage", "id", id, "error", err)
+		}
+	}
+}
+
+// trashEnabled reports whether delete tools move objects to the trash
+func (s *FireflyMCPServer) trashEnabled() bool {
+	config := s.currentConfig()
+	return config != nil && config.Trash.Enabled && s.trash != nil
+}
+
+// moveToTrash deactivates an object and records it in the trash instead of deleting it
+func (s *FireflyMCPServer) moveToTrash(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	kind, id string,
+) (*mcp.CallToolResult, any, error) {
+	entry, err := s.fetchTrashEntry(ctx, req, kind, id)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error reading %s before moving it to the trash: %v", kind, err))
+	}
+	if entry.WasActive {
+		if err := s.setTrashedActive(ctx, req, entry, false); err != nil {
+			return newErrorResult(fmt.Sprintf("Error deactivating %s: %v", kind, err))
+		}
+	}
+
+	now := time.Now().UTC()
+	entry.owner = s.tenantKey(req)
+	entry.TrashedAt = now.Format(time.RFC3339)
+	if hours := s.currentConfig().Trash.GracePeriod; hours > 0 {
+		entry.purgeAt = now.Add(time.Duration(hours) * time.Hour)
+		entry.PurgeAfter = entry.purgeAt.Format(time.RFC3339)
+	}
+	if err := s.trash.add(entry); err != nil {
+		return newErrorResult(fmt.Sprintf("Failed to create trash entry: %v", err))
+	}
+
+	s.purgeExpiredTrash(ctx, req)
+	return newSuccessResult(&TrashResult{Status: "trashed", Entry: entry})
+}
+
+// fetchTrashEntry reads the object to be trashed and builds its trash entry
+func (s *FireflyMCPServer) fetchTrashEntry(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	kind, id string,
+) (*TrashEntry, error) {
+	entry := &TrashEntry{Kind: kind, ObjectId: id}
+	var payload any
+	switch kind {
+	case trashKindRule:
+		rule, err := callTool[GetRuleArgs, Rule](ctx, req, s.handleGetRule, GetRuleArgs{ID: id})
+		if err != nil {
+			return nil, err
+		}
+		entry.Title, entry.WasActive, payload = rule.Title, rule.Active, rule
+	case trashKindRuleGroup:
+		group, err := callTool[GetRuleGroupArgs, RuleGroup](ctx, req, s.handleGetRuleGroup, GetRuleGroupArgs{ID: id})
+		if err != nil {
+			return nil, err
+		}
+		entry.Title, entry.WasActive, payload = group.Title, group.Active, group
+	default:
+		return nil, fmt.Errorf("unknown trash kind %q", kind)
+	}
+
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	entry.Payload = raw
+	return entry, nil
+}
+
+// setTrashedActive activates or deactivates a trashed object in Firefly III
+func (s *FireflyMCPServer) setTrashedActive(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	entry *TrashEntry,
+	active bool,
+) error {
+	var err error
+	switch entry.Kind {
+	case trashKindRule:
+		_, err = callTool[UpdateRuleArgs, Rule](ctx, req, s.handleUpdateRule, UpdateRuleArgs{
+			ID:                entry.ObjectId,
+			RuleUpdateRequest: RuleUpdateRequest{Active: &active},
+		})
+	case trashKindRuleGroup:
+		_, err = callTool[UpdateRuleGroupArgs, RuleGroup](ctx, req, s.handleUpdateRuleGroup, UpdateRuleGroupArgs{
+			ID:                     entry.ObjectId,
+			RuleGroupUpdateRequest: RuleGroupUpdateRequest{Active: &active},
+		})
+	default:
+		err = fmt.Errorf("unknown trash kind %q", entry.Kind)
+	}
+	return err
+}
+
+// purgeTrashEntry deletes a trashed object in Firefly III and forgets the entry
+func (s *FireflyMCPServer) purgeTrashEntry(ctx context.Context, req *mcp.CallToolRequest, entry *TrashEntry) error {
+	var err error
+	switch entry.Kind {
+	case trashKindRule:
+		_, err = callTool[string, WriteResult](ctx, req, s.deleteRule, entry.ObjectId)
+	case trashKindRuleGroup:
+		_, err = callTool[string, WriteResult](ctx, req, s.deleteRuleGroup, entry.ObjectId)
+	default:
+		err = fmt.Errorf("unknown trash kind %q", entry.Kind)
+	}
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", entry.Kind, entry.ObjectId, err)
+	}
+	s.trash.remove(entry.ID)
+	return nil
+}
+
+// purgeExpiredTrash deletes the caller's trashed objects whose grace period is
+// over. Entries that fail to purge are kept and retried on the next call.
+func (s *FireflyMCPServer) purgeExpiredTrash(ctx context.Context, req *mcp.CallToolRequest) ([]TrashEntry, []string) {
+	purged := []TrashEntry{}
+	var errs []string
+	now := time.Now()
+	for _, entry := range s.trash.list(s.tenantKey(req)) {
+		if entry.purgeAt.IsZero() || now.Before(entry.purgeAt) {
+			continue
+		}
+		if err := s.purgeTrashEntry(ctx, req, entry); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		purged = append(purged, *entry)
+	}
+	return purged, errs
+}
+
+// trashItems returns the caller's trash entries
+func (s *FireflyMCPServer) trashItems(req *mcp.CallToolRequest) []TrashEntry {
+	items := []TrashEntry{}
+	for _, entry := range s.trash.list(s.tenantKey(req)) {
+		items = append(items, *entry)
+	}
+	return items
+}
+
+func (s *FireflyMCPServer) handleListTrash(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args ListTrashArgs,
+) (*mcp.CallToolResult, any, error) {
+	purged, errs := s.purgeExpiredTrash(ctx, req)
+	return newSuccessResult(&TrashList{Items: s.trashItems(req), Purged: purged, Errors: errs})
+}
+
+func (s *FireflyMCPServer) handleRestoreFromTrash(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args RestoreFromTrashArgs,
+) (*mcp.CallToolResult, any, error) {
+	if args.ID == "" {
+		return newErrorResult("Trash entry ID is required")
+	}
+	entry, ok := s.trash.get(s.tenantKey(req), args.ID)
+	if !ok {
+		return newErrorResult("Trash entry not found")
+	}
+
+	if entry.WasActive {
+		if err := s.setTrashedActive(ctx, req, entry, true); err != nil {
+			return newErrorResult(fmt.Sprintf("Error reactivating %s %s: %v (its payload is kept in the trash)",
+				entry.Kind, entry.ObjectId, err))
+		}
+	}
+	s.trash.remove(entry.ID)
+	return newSuccessResult(&TrashResult{Status: "restored", Entry: entry})
+}
+
+func (s *FireflyMCPServer) handlePurgeTrash(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args PurgeTrashArgs,
+) (*mcp.CallToolResult, any, error) {
+	if args.ID == "" && !args.All {
+		return newErrorResult("Error: either id or all is required")
+	}
+
+	var entries []*TrashEntry
+	if args.All {
+		entries = s.trash.list(s.tenantKey(req))
+	} else {
+		entry, ok := s.trash.get(s.tenantKey(req), args.ID)
+		if !ok {
+			return newErrorResult("Trash entry not found")
+		}
+		entries = []*TrashEntry{entry}
+	}
+
+	result := &TrashList{Purged: []TrashEntry{}}
+	for _, entry := range entries {
+		if err := s.purgeTrashEntry(ctx, req, entry); err != nil {
+			result.Errors = append(result.Errors, err.Error())
+			continue
+		}
+		result.Purged = append(result.Purged, *entry)
+	}
+	result.Items = s.trashItems(req)
+	return newSuccessResult(result)
+}
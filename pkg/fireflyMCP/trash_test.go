@@ -0,0 +1,151 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const trashRuleJSON = `{"data":{"id":"7","type":"rules","attributes":{
+	"title":"Streaming","rule_group_id":"1","trigger":"store-journal","active":%s,
+	"triggers":[{"type":"description_contains","value":"netflix"}],
+	"actions":[{"type":"set_category","value":"Subscriptions"}]}}}`
+
+func newTrashTestServer(t *testing.T) (*FireflyMCPServer, *[]string) {
+	var calls []string
+	active := "true"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch {
+		case r.URL.Path != "/v1/rules/7":
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodPut:
+			var update map[string]any
+			require.NoError(t, json.Unmarshal(body, &update))
+			if update["active"] == true {
+				active = "true"
+			} else {
+				active = "false"
+			}
+			w.Write([]byte(fmt.Sprintf(trashRuleJSON, active)))
+		default:
+			w.Write([]byte(fmt.Sprintf(trashRuleJSON, active)))
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	config.Trash.Enabled = true
+	config.Trash.GracePeriod = 24
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+	return server, &calls
+}
+
+func decodeTrashResult[T any](t *testing.T, result *mcp.CallToolResult) T {
+	t.Helper()
+	require.False(t, result.IsError, result.Content[0].(*mcp.TextContent).Text)
+	var decoded T
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &decoded))
+	return decoded
+}
+
+func TestTrash_DeleteAndRestore(t *testing.T) {
+	server, calls := newTrashTestServer(t)
+	ctx := context.Background()
+
+	assert.Contains(t, server.tools["delete_rule"].tool.Description, "trash")
+
+	result, _, err := server.handleDeleteRule(ctx, nil, DeleteRuleArgs{ID: "7"})
+	require.NoError(t, err)
+	trashed := decodeTrashResult[TrashResult](t, result)
+	assert.Equal(t, "trashed", trashed.Status)
+	assert.Equal(t, "rule", trashed.Entry.Kind)
+	assert.Equal(t, "Streaming", trashed.Entry.Title)
+	assert.True(t, trashed.Entry.WasActive)
+	assert.NotEmpty(t, trashed.Entry.PurgeAfter)
+	assert.Contains(t, string(trashed.Entry.Payload), "netflix")
+	assert.Equal(t, []string{"GET /v1/rules/7", "PUT /v1/rules/7"}, *calls)
+
+	result, _, err = server.handleListTrash(ctx, nil, ListTrashArgs{})
+	require.NoError(t, err)
+	list := decodeTrashResult[TrashList](t, result)
+	require.Len(t, list.Items, 1)
+	assert.Empty(t, list.Purged)
+
+	*calls = nil
+	result, _, err = server.handleRestoreFromTrash(ctx, nil, RestoreFromTrashArgs{ID: trashed.Entry.ID})
+	require.NoError(t, err)
+	restored := decodeTrashResult[TrashResult](t, result)
+	assert.Equal(t, "restored", restored.Status)
+	assert.Equal(t, []string{"PUT /v1/rules/7"}, *calls)
+	assert.Empty(t, server.trashItems(nil))
+
+	result, _, err = server.handleRestoreFromTrash(ctx, nil, RestoreFromTrashArgs{ID: trashed.Entry.ID})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestTrash_Purge(t *testing.T) {
+	server, calls := newTrashTestServer(t)
+	ctx := context.Background()
+
+	result, _, err := server.handleDeleteRule(ctx, nil, DeleteRuleArgs{ID: "7"})
+	require.NoError(t, err)
+	trashed := decodeTrashResult[TrashResult](t, result)
+
+	result, _, err = server.handlePurgeTrash(ctx, nil, PurgeTrashArgs{})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	*calls = nil
+	result, _, err = server.handlePurgeTrash(ctx, nil, PurgeTrashArgs{ID: trashed.Entry.ID})
+	require.NoError(t, err)
+	purged := decodeTrashResult[TrashList](t, result)
+	require.Len(t, purged.Purged, 1)
+	assert.Empty(t, purged.Items)
+	assert.Equal(t, []string{"DELETE /v1/rules/7"}, *calls)
+}
+
+func TestTrash_GracePeriodExpiry(t *testing.T) {
+	server, calls := newTrashTestServer(t)
+	ctx := context.Background()
+
+	result, _, err := server.handleDeleteRule(ctx, nil, DeleteRuleArgs{ID: "7"})
+	require.NoError(t, err)
+	trashed := decodeTrashResult[TrashResult](t, result)
+
+	entry, ok := server.trash.get(server.tenantKey(nil), trashed.Entry.ID)
+	require.True(t, ok)
+	entry.purgeAt = time.Now().Add(-time.Minute)
+
+	*calls = nil
+	result, _, err = server.handleListTrash(ctx, nil, ListTrashArgs{})
+	require.NoError(t, err)
+	list := decodeTrashResult[TrashList](t, result)
+	assert.Empty(t, list.Items)
+	require.Len(t, list.Purged, 1)
+	assert.Equal(t, "7", list.Purged[0].ObjectId)
+	assert.Equal(t, []string{"DELETE /v1/rules/7"}, *calls)
+}
+
+func TestTrash_Disabled(t *testing.T) {
+	server, err := NewFireflyMCPServer(newPluginTestConfig())
+	require.NoError(t, err)
+	assert.False(t, server.hasTool("list_trash"))
+	assert.NotContains(t, server.tools["delete_rule"].tool.Description, "trash")
+}
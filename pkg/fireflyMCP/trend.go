@@ -0,0 +1,244 @@
+package fireflyMCP
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dezer32/mcp-firefly-iii/pkg/client"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultTrendMaxPoints is used when trends.max_points is not configured
+const defaultTrendMaxPoints = 18
+
+// maxDailyTrendDays is the longest range get_summary returns a daily trend for
+const maxDailyTrendDays = 92
+
+// trendGranularities are the supported granularities, finest first
+var trendGranularities = []string{"month", "quarter", "year"}
+
+// IncomeExpenseTrendArgs represents the arguments for the income_expense_trend tool
+type IncomeExpenseTrendArgs struct {
+	DateRange
+	Granularity string `json:"granularity,omitempty" jsonschema:"month, quarter or year (default: the finest one that stays within the configured number of points)"`
+}
+
+// TrendPoint holds the income and expenses of one period of a trend
+type TrendPoint struct {
+	Period  string              `json:"period"` // 2024-03, 2024-Q1 or 2024
+	Start   string              `json:"start"`
+	End     string              `json:"end"`
+	Income  []InsightTotalEntry `json:"income"`
+	Expense []InsightTotalEntry `json:"expense"`
+	Net     []InsightTotalEntry `json:"net"` // Income minus expenses per currency
+}
+
+// IncomeExpenseTrend is the result of the income_expense_trend tool
+type IncomeExpenseTrend struct {
+	Start           string                `json:"start"`
+	End             string                `json:"end"`
+	Granularity     string                `json:"granularity"`
+	GranularityNote string                `json:"granularity_note,omitempty"` // Set when the granularity was coarsened
+	Points          []TrendPoint          `json:"points"`
+	Steps           []CompositeStepStatus `json:"steps"`
+}
+
+// trendBucket is one period of a trend, clipped to the requested range
+type trendBucket struct {
+	label      string
+	start, end time.Time
+}
+
+// trendMaxPoints returns the configured maximum number of trend points
+func (s *FireflyMCPServer) trendMaxPoints() int {
+	if config := s.currentConfig(); config != nil && config.Trends.MaxPoints > 0 {
+		return config.Trends.MaxPoints
+	}
+	return defaultTrendMaxPoints
+}
+
+// handleIncomeExpenseTrend returns income, expenses and net change per month,
+// quarter or year. Long ranges are summarized with a coarser granularity, so
+// the result stays bounded.
+func (s *FireflyMCPServer) handleIncomeExpenseTrend(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args IncomeExpenseTrendArgs,
+) (*mcp.CallToolResult, any, error) {
+	dateRange, err := s.resolveFiscalPeriod(ctx, req, args.DateRange)
+	if err != nil {
+		return newErrorResult(err.Error())
+	}
+	dates, err := s.resolveDateRange(dateRange, dateRangeRequired)
+	if err != nil {
+		return newErrorResult(err.Error())
+	}
+	granularity, note, err := chooseTrendGranularity(
+		dates.Start.Time, dates.End.Time, strings.ToLower(strings.TrimSpace(args.Granularity)), s.trendMaxPoints(),
+	)
+	if err != nil {
+		return newErrorResult(err.Error())
+	}
+
+	apiClient, err := s.getClient(ctx, req)
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Failed to get API client: %v", err))
+	}
+
+	buckets := trendBuckets(dates.Start.Time, dates.End.Time, granularity)
+	report := &IncomeExpenseTrend{
+		Start:           dates.StartString(),
+		End:             dates.EndString(),
+		Granularity:     granularity,
+		GranularityNote: note,
+		Points:          []TrendPoint{},
+	}
+	run := newCompositeRun(ctx, len(buckets))
+	for _, bucket := range buckets {
+		var income, expense []InsightTotalEntry
+		ok := run.step(bucket.label, func(ctx context.Context) error {
+			income, expense, err = insightTotals(ctx, apiClient, bucket.start, bucket.end, nil)
+			return err
+		})
+		if !ok {
+			continue
+		}
+		report.Points = append(report.Points, TrendPoint{
+			Period:  bucket.label,
+			Start:   bucket.start.Format("2006-01-02"),
+			End:     bucket.end.Format("2006-01-02"),
+			Income:  income,
+			Expense: expense,
+			Net:     trendNet(income, expense),
+		})
+	}
+	report.Steps = run.Steps
+	return newSuccessResult(report)
+}
+
+// dailyNetTrend returns the net change of every day of a range from insight
+// totals, requested with up to client.page_parallelism days at a time
+func (s *FireflyMCPServer) dailyNetTrend(
+	ctx context.Context,
+	apiClient *client.ClientWithResponses,
+	start, end time.Time,
+) ([]DailyNet, error) {
+	days := int(end.Sub(start).Hours()/24) + 1
+	if days > maxDailyTrendDays {
+		return nil, fmt.Errorf("The range has %d days, more than the %d of a daily trend; use income_expense_trend instead", days, maxDailyTrendDays)
+	}
+
+	trend := make([]DailyNet, days)
+	errs := make([]error, days)
+	sem := make(chan struct{}, s.pageParallelism())
+	var wg sync.WaitGroup
+	for i := range trend {
+		day := start.AddDate(0, 0, i)
+		trend[i].Date = day.Format("2006-01-02")
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			income, expense, err := insightTotals(ctx, apiClient, day, day, nil)
+			trend[i].Net, errs[i] = trendNet(income, expense), err
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("The daily trend is unavailable: %w", err)
+		}
+	}
+	return trend, nil
+}
+
+// chooseTrendGranularity returns the requested granularity, or the finest one
+// with at most maxPoints points. A granularity with more points is coarsened,
+// with a note saying so.
+func chooseTrendGranularity(start, end time.Time, requested string, maxPoints int) (string, string, error) {
+	first := 0
+	if requested != "" {
+		first = -1
+		for i, granularity := range trendGranularities {
+			if granularity == requested {
+				first = i
+			}
+		}
+		if first < 0 {
+			return "", "", fmt.Errorf("Error: granularity must be one of: %s", strings.Join(trendGranularities, ", "))
+		}
+	}
+
+	chosen := trendGranularities[len(trendGranularities)-1]
+	for _, granularity := range trendGranularities[first:] {
+		if len(trendBuckets(start, end, granularity)) <= maxPoints {
+			chosen = granularity
+			break
+		}
+	}
+	finest := trendGranularities[first]
+	if chosen == finest {
+		return chosen, "", nil
+	}
+	return chosen, fmt.Sprintf("The range has %d %ss, more than the limit of %d points, so it is summarized by %s",
+		len(trendBuckets(start, end, finest)), finest, maxPoints, chosen), nil
+}
+
+// trendBuckets splits a date range into calendar months, quarters or years;
+// the first and last bucket are clipped to the range
+func trendBuckets(start, end time.Time, granularity string) []trendBucket {
+	var buckets []trendBucket
+	for from := start; !from.After(end); {
+		var periodStart time.Time
+		var label string
+		months := 1
+		switch granularity {
+		case "quarter":
+			periodStart = time.Date(from.Year(), from.Month()-(from.Month()-1)%3, 1, 0, 0, 0, 0, time.UTC)
+			label = fmt.Sprintf("%d-Q%d", from.Year(), (int(from.Month())-1)/3+1)
+			months = 3
+		case "year":
+			periodStart = time.Date(from.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+			label = fmt.Sprintf("%d", from.Year())
+			months = 12
+		default:
+			periodStart = time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC)
+			label = periodStart.Format("2006-01")
+		}
+		next := periodStart.AddDate(0, months, 0)
+		to := next.AddDate(0, 0, -1)
+		if to.After(end) {
+			to = end
+		}
+		buckets = append(buckets, trendBucket{label: label, start: from, end: to})
+		from = next
+	}
+	return buckets
+}
+
+// trendNet returns income minus expenses per currency. Insight totals of
+// expenses are negative, so absolute amounts are used.
+func trendNet(income, expense []InsightTotalEntry) []InsightTotalEntry {
+	net := make(map[string]decimal)
+	var order []string
+	add := func(entries []InsightTotalEntry, sign int64) {
+		for _, entry := range entries {
+			if _, ok := net[entry.CurrencyCode]; !ok {
+				order = append(order, entry.CurrencyCode)
+			}
+			net[entry.CurrencyCode] = net[entry.CurrencyCode].Add(parseAmount(entry.Amount).Abs().Mul(decimalFromInt(sign)))
+		}
+	}
+	add(income, 1)
+	add(expense, -1)
+
+	entries := make([]InsightTotalEntry, 0, len(order))
+	for _, currencyCode := range order {
+		entries = append(entries, InsightTotalEntry{Amount: formatAmount(net[currencyCode]), CurrencyCode: currencyCode})
+	}
+	return entries
+}
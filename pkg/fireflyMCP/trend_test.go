@@ -0,0 +1,176 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrendBuckets(t *testing.T) {
+	start := time.Date(2024, time.February, 15, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, time.May, 10, 0, 0, 0, 0, time.UTC)
+
+	months := trendBuckets(start, end, "month")
+	require.Len(t, months, 4)
+	assert.Equal(t, "2024-02", months[0].label)
+	assert.Equal(t, start, months[0].start)
+	assert.Equal(t, "2024-02-29", months[0].end.Format("2006-01-02"))
+	assert.Equal(t, "2024-05", months[3].label)
+	assert.Equal(t, end, months[3].end)
+
+	quarters := trendBuckets(start, end, "quarter")
+	require.Len(t, quarters, 2)
+	assert.Equal(t, "2024-Q1", quarters[0].label)
+	assert.Equal(t, "2024-03-31", quarters[0].end.Format("2006-01-02"))
+	assert.Equal(t, "2024-Q2", quarters[1].label)
+	assert.Equal(t, "2024-04-01", quarters[1].start.Format("2006-01-02"))
+
+	years := trendBuckets(start, time.Date(2026, time.January, 31, 0, 0, 0, 0, time.UTC), "year")
+	require.Len(t, years, 3)
+	assert.Equal(t, []string{"2024", "2025", "2026"}, []string{years[0].label, years[1].label, years[2].label})
+}
+
+func TestChooseTrendGranularity(t *testing.T) {
+	date := func(value string) time.Time {
+		parsed, _ := time.Parse("2006-01-02", value)
+		return parsed
+	}
+	tests := []struct {
+		name        string
+		start, end  string
+		requested   string
+		granularity string
+		noted       bool
+	}{
+		{"short range by month", "2024-01-01", "2024-12-31", "", "month", false},
+		{"at the limit", "2024-01-01", "2025-06-30", "", "month", false},
+		{"long range by quarter", "2022-01-01", "2024-12-31", "", "quarter", true},
+		{"very long range by year", "2000-01-01", "2024-12-31", "", "year", true},
+		{"requested quarter", "2024-01-01", "2024-12-31", "quarter", "quarter", false},
+		{"requested month coarsened", "2021-01-01", "2024-12-31", "month", "quarter", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			granularity, note, err := chooseTrendGranularity(date(tt.start), date(tt.end), tt.requested, 18)
+			require.NoError(t, err)
+			assert.Equal(t, tt.granularity, granularity)
+			assert.Equal(t, tt.noted, note != "", note)
+		})
+	}
+
+	_, _, err := chooseTrendGranularity(date("2024-01-01"), date("2024-12-31"), "week", 18)
+	assert.ErrorContains(t, err, "granularity must be one of")
+}
+
+func TestIncomeExpenseTrend(t *testing.T) {
+	var requests []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/insight/income/total":
+			requests = append(requests, r.URL.Query().Get("start")+".."+r.URL.Query().Get("end"))
+			w.Write([]byte(`[{"currency_code":"EUR","difference":"3000.00"}]`))
+		case "/v1/insight/expense/total":
+			w.Write([]byte(`[{"currency_code":"EUR","difference":"-1200.50"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	config.Trends.MaxPoints = 6
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	result, _, err := server.handleIncomeExpenseTrend(context.Background(), nil, IncomeExpenseTrendArgs{
+		DateRange: DateRange{Start: "2024-01-15", End: "2024-12-31"},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content[0].(*mcp.TextContent).Text)
+
+	var trend IncomeExpenseTrend
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &trend))
+	assert.Equal(t, "quarter", trend.Granularity)
+	assert.Contains(t, trend.GranularityNote, "12 months")
+	require.Len(t, trend.Points, 4)
+	assert.Equal(t, "2024-Q1", trend.Points[0].Period)
+	assert.Equal(t, "2024-01-15", trend.Points[0].Start)
+	assert.Equal(t, []InsightTotalEntry{{Amount: "1799.50", CurrencyCode: "EUR"}}, trend.Points[0].Net)
+	assert.Equal(t, []string{
+		"2024-01-15..2024-03-31", "2024-04-01..2024-06-30", "2024-07-01..2024-09-30", "2024-10-01..2024-12-31",
+	}, requests)
+	for _, step := range trend.Steps {
+		assert.True(t, step.Success, step.Error)
+	}
+}
+
+func TestIncomeExpenseTrend_RequiresDates(t *testing.T) {
+	server := &FireflyMCPServer{}
+	result, _, err := server.handleIncomeExpenseTrend(context.Background(), nil, IncomeExpenseTrendArgs{})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestGetSummary_WithTrend(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/summary/basic":
+			w.Write([]byte(`{"balance-in-EUR":{"key":"balance-in-EUR","title":"Balance (EUR)","currency_code":"EUR","monetary_value":"100.00"}}`))
+		case "/v1/insight/income/total":
+			if r.URL.Query().Get("start") == "2024-03-02" {
+				w.Write([]byte(`[{"difference":"50.00","currency_code":"EUR"}]`))
+				return
+			}
+			w.Write([]byte(`[]`))
+		case "/v1/insight/expense/total":
+			w.Write([]byte(`[{"difference":"-20.00","currency_code":"EUR"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	result, _, err := server.handleGetSummary(ctx, nil, GetSummaryArgs{
+		DateRange: DateRange{Start: "2024-03-01", End: "2024-03-03"},
+		WithTrend: true,
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content[0].(*mcp.TextContent).Text)
+	var summary BasicSummaryList
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &summary))
+	assert.Len(t, summary.Data, 1)
+	assert.Empty(t, summary.TrendNote)
+	assert.Equal(t, []DailyNet{
+		{Date: "2024-03-01", Net: []InsightTotalEntry{{Amount: "-20.00", CurrencyCode: "EUR"}}},
+		{Date: "2024-03-02", Net: []InsightTotalEntry{{Amount: "30.00", CurrencyCode: "EUR"}}},
+		{Date: "2024-03-03", Net: []InsightTotalEntry{{Amount: "-20.00", CurrencyCode: "EUR"}}},
+	}, summary.Trend)
+
+	// Long ranges keep the summary and explain the missing trend
+	result, _, err = server.handleGetSummary(ctx, nil, GetSummaryArgs{
+		DateRange: DateRange{Start: "2024-01-01", End: "2024-12-31"},
+		WithTrend: true,
+	})
+	require.NoError(t, err)
+	var long BasicSummaryList
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &long))
+	assert.Len(t, long.Data, 1)
+	assert.Nil(t, long.Trend)
+	assert.Contains(t, long.TrendNote, "use income_expense_trend")
+}
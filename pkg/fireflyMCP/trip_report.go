@@ -0,0 +1,305 @@
+package fireflyMCP
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// TripReportArgs represents the arguments for the trip_report tool
+type TripReportArgs struct {
+	Tags     []string `json:"tags,omitempty" jsonschema:"Tags of the trip; transactions with any of them are included (tags or a date range is required)"`
+	Accounts []string `json:"accounts,omitempty" jsonschema:"Only transactions from or to these account IDs (or configured account aliases), e.g. a travel card"`
+	Format   string   `json:"format,omitempty" jsonschema:"json (default) or markdown, a report ready to show to the user"`
+	DateRange
+}
+
+// TripDay is the spending of one day of a trip
+type TripDay struct {
+	Date   string `json:"date"`
+	Amount string `json:"amount"`
+	Count  int    `json:"count"`
+}
+
+// TripCurrencyTotals is the cost of a trip in one currency
+type TripCurrencyTotals struct {
+	CurrencyCode     string        `json:"currency_code"`
+	Cost             string        `json:"cost"` // Expenses minus refunds
+	Expenses         string        `json:"expenses"`
+	Refunds          string        `json:"refunds"` // Deposits of the trip, e.g. refunds and reimbursements
+	DailyAverage     string        `json:"daily_average"`
+	TransactionCount int           `json:"transaction_count"`
+	ByCategory       []ProjectLine `json:"by_category"` // Cost per category, largest first
+	ByDay            []TripDay     `json:"by_day"`      // Cost per day with transactions, oldest first
+}
+
+// TripReport is the result of the trip_report tool
+type TripReport struct {
+	Tags                  []string             `json:"tags,omitempty"`
+	Accounts              []string             `json:"accounts,omitempty"`
+	Start                 string               `json:"start"` // The date range, or the first and last day with transactions
+	End                   string               `json:"end"`
+	Days                  int                  `json:"days"`
+	Currencies            []TripCurrencyTotals `json:"currencies"` // Largest cost first
+	TransactionCount      int                  `json:"transaction_count"`
+	TransfersSkipped      int                  `json:"transfers_skipped"`
+	TransactionsTruncated bool                 `json:"transactions_truncated,omitempty"`
+}
+
+// tripTotals accumulates the amounts of one currency
+type tripTotals struct {
+	expenses, refunds decimal
+	count             int
+	byCategory        map[string]*projectLineTotal
+	byDay             map[string]*projectLineTotal
+}
+
+// handleTripReport sums up the cost of a trip, given by its tags or its dates,
+// per currency, category and day
+func (s *FireflyMCPServer) handleTripReport(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args TripReportArgs,
+) (*mcp.CallToolResult, any, error) {
+	var tags []string
+	for _, tag := range args.Tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	format := strings.ToLower(args.Format)
+	if format != "" && format != "json" && format != "markdown" {
+		return newErrorResult("Error: format must be json or markdown")
+	}
+	if len(tags) == 0 && args.Start == "" && args.End == "" && args.Period == "" {
+		return newErrorResult("Error: tags or a date range is required")
+	}
+	requirement := dateRangeRequired
+	if len(tags) > 0 {
+		requirement = dateRangeOpen
+	}
+	dates, err := s.resolveDateRange(args.DateRange, requirement)
+	if err != nil {
+		return newErrorResult(err.Error())
+	}
+	dateRange := DateRange{Start: dates.StartString(), End: dates.EndString()}
+
+	var (
+		transactions []Transaction
+		truncated    bool
+	)
+	if len(tags) == 0 {
+		transactions, truncated, err = s.fetchTransactions(ctx, req, ListTransactionsArgs{DateRange: dateRange})
+	} else {
+		transactions, truncated, err = s.fetchTaggedTransactions(ctx, req, tags, dateRange)
+	}
+	if err != nil {
+		return newErrorResult(fmt.Sprintf("Error listing transactions: %v", err))
+	}
+
+	accounts := s.resolveAccountRefs(args.Accounts)
+	report := buildTripReport(tags, accounts, transactions)
+	report.TransactionsTruncated = truncated
+	if dates.Start != nil {
+		report.Start = dates.StartString()
+	}
+	if dates.End != nil {
+		report.End = dates.EndString()
+	}
+	if report.Start != "" && report.End != "" {
+		start, _ := time.Parse("2006-01-02", report.Start)
+		end, _ := time.Parse("2006-01-02", report.End)
+		report.Days = int(end.Sub(start).Hours()/24) + 1
+	}
+	for i := range report.Currencies {
+		currency := &report.Currencies[i]
+		if report.Days > 0 {
+			currency.DailyAverage = formatAmount(parseAmount(currency.Cost).Div(decimalFromInt(int64(report.Days))))
+		}
+	}
+
+	if format == "markdown" {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: tripReportMarkdown(report)}}}, nil, nil
+	}
+	return newSuccessResult(report)
+}
+
+// fetchTaggedTransactions searches the splits carrying any of the tags, each
+// split once, optionally within a date range
+func (s *FireflyMCPServer) fetchTaggedTransactions(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	tags []string,
+	dateRange DateRange,
+) ([]Transaction, bool, error) {
+	var (
+		transactions []Transaction
+		truncated    bool
+	)
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		query := "tag_is:" + quoteSearchValue(strings.ReplaceAll(tag, `"`, ""))
+		groups, tagTruncated, err := fetchPages(ctx, s.pageParallelism(), func(ctx context.Context, page int) ([]TransactionGroup, int, error) {
+			list, err := callTool[SearchTransactionsArgs, TransactionList](ctx, req, s.handleSearchTransactions, SearchTransactionsArgs{
+				Query:     query,
+				Limit:     compositePageSize,
+				Page:      int32(page),
+				DateRange: dateRange,
+			})
+			if err != nil {
+				return nil, 0, err
+			}
+			return list.Data, list.Pagination.TotalPages, nil
+		})
+		if err != nil {
+			return nil, false, fmt.Errorf("tag %s: %w", tag, err)
+		}
+		truncated = truncated || tagTruncated
+		for _, group := range groups {
+			for _, split := range group.Transactions {
+				// Search results hold whole groups, including splits without the tag
+				if seen[split.JournalId] || len(matchingTags(split.Tags, tags)) == 0 {
+					continue
+				}
+				seen[split.JournalId] = true
+				transactions = append(transactions, split)
+			}
+		}
+	}
+	return transactions, truncated, nil
+}
+
+// buildTripReport sums the withdrawals and deposits of a trip, optionally only
+// those from or to the accounts. Transfers move money between own accounts,
+// e.g. to a travel card, and are skipped. Without tags every transaction counts.
+func buildTripReport(tags, accounts []string, transactions []Transaction) *TripReport {
+	report := &TripReport{Tags: tags, Accounts: accounts, Currencies: []TripCurrencyTotals{}}
+	totals := make(map[string]*tripTotals)
+	var first, last string
+
+	for _, transaction := range transactions {
+		if len(tags) > 0 && len(matchingTags(transaction.Tags, tags)) == 0 {
+			continue
+		}
+		if len(accounts) > 0 && !slices.Contains(accounts, transaction.SourceId) &&
+			!slices.Contains(accounts, transaction.DestinationId) {
+			continue
+		}
+		if transaction.Type != "withdrawal" && transaction.Type != "deposit" {
+			report.TransfersSkipped++
+			continue
+		}
+		report.TransactionCount++
+
+		currency := totals[transaction.CurrencyCode]
+		if currency == nil {
+			currency = &tripTotals{
+				byCategory: make(map[string]*projectLineTotal),
+				byDay:      make(map[string]*projectLineTotal),
+			}
+			totals[transaction.CurrencyCode] = currency
+		}
+		currency.count++
+
+		amount := parseAmount(transaction.Amount).Abs()
+		if transaction.Type == "deposit" {
+			currency.refunds = currency.refunds.Add(amount)
+			amount = amount.Neg()
+		} else {
+			currency.expenses = currency.expenses.Add(amount)
+		}
+		category := noCategoryLabel
+		if transaction.CategoryName != nil && *transaction.CategoryName != "" {
+			category = *transaction.CategoryName
+		}
+		day := transaction.Date.Format("2006-01-02")
+		addProjectLine(currency.byCategory, category, amount)
+		addProjectLine(currency.byDay, day, amount)
+		if first == "" || day < first {
+			first = day
+		}
+		if day > last {
+			last = day
+		}
+	}
+	report.Start, report.End = first, last
+
+	for code, currency := range totals {
+		days := make([]string, 0, len(currency.byDay))
+		for day := range currency.byDay {
+			days = append(days, day)
+		}
+		sort.Strings(days)
+		byDay := make([]TripDay, 0, len(days))
+		for _, day := range days {
+			byDay = append(byDay, TripDay{Date: day, Amount: formatAmount(currency.byDay[day].amount), Count: currency.byDay[day].count})
+		}
+		report.Currencies = append(report.Currencies, TripCurrencyTotals{
+			CurrencyCode:     code,
+			Cost:             formatAmount(currency.expenses.Sub(currency.refunds)),
+			Expenses:         formatAmount(currency.expenses),
+			Refunds:          formatAmount(currency.refunds),
+			TransactionCount: currency.count,
+			ByCategory:       sortedProjectLines(currency.byCategory),
+			ByDay:            byDay,
+		})
+	}
+	sort.Slice(report.Currencies, func(a, b int) bool {
+		if cmp := parseAmount(report.Currencies[a].Cost).Cmp(parseAmount(report.Currencies[b].Cost)); cmp != 0 {
+			return cmp > 0
+		}
+		return report.Currencies[a].CurrencyCode < report.Currencies[b].CurrencyCode
+	})
+	return report
+}
+
+// tripReportMarkdown renders a trip report for the user: totals per currency,
+// then the cost per category and per day of every currency
+func tripReportMarkdown(report *TripReport) string {
+	var b strings.Builder
+	title := "Trip report"
+	if len(report.Tags) > 0 {
+		title += ": " + strings.Join(report.Tags, ", ")
+	}
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	if report.TransactionCount == 0 {
+		b.WriteString("No expenses found.\n")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "%s to %s (%d days), %d transactions", report.Start, report.End, report.Days, report.TransactionCount)
+	if report.TransfersSkipped > 0 {
+		fmt.Fprintf(&b, ", %d transfers left out", report.TransfersSkipped)
+	}
+	b.WriteString(".\n")
+	if report.TransactionsTruncated {
+		fmt.Fprintf(&b, "\nOnly the first %d transactions are included; use a shorter period.\n", maxCompositeTransactions)
+	}
+
+	b.WriteString("\n| Currency | Cost | Expenses | Refunds | Per day | Transactions |\n|---|---:|---:|---:|---:|---:|\n")
+	for _, currency := range report.Currencies {
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %d |\n", currency.CurrencyCode, currency.Cost, currency.Expenses,
+			currency.Refunds, currency.DailyAverage, currency.TransactionCount)
+	}
+
+	for _, currency := range report.Currencies {
+		fmt.Fprintf(&b, "\n## %s by category\n\n| Category | Cost | Transactions |\n|---|---:|---:|\n", currency.CurrencyCode)
+		for _, line := range currency.ByCategory {
+			fmt.Fprintf(&b, "| %s | %s | %d |\n", markdownCell(line.Name), line.Amount, line.Count)
+		}
+		fmt.Fprintf(&b, "\n## %s by day\n\n| Day | Cost | Transactions |\n|---|---:|---:|\n", currency.CurrencyCode)
+		for _, day := range currency.ByDay {
+			fmt.Fprintf(&b, "| %s | %s | %d |\n", day.Date, day.Amount, day.Count)
+		}
+	}
+	return b.String()
+}
+
+// markdownCell escapes the pipes of a table cell
+func markdownCell(text string) string {
+	return strings.ReplaceAll(text, "|", `\|`)
+}
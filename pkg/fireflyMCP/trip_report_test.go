@@ -0,0 +1,110 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTripReport(t *testing.T) {
+	var queries []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		if r.URL.Path != "/v1/search/transactions" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		queries = append(queries, r.URL.Query().Get("query"))
+		split := func(journal, kind, date, amount, currency, category, tags string) string {
+			return `{"type":"` + kind + `","transaction_journal_id":"` + journal + `","date":"` + date + `T12:00:00+00:00",` +
+				`"amount":"` + amount + `","currency_code":"` + currency + `","category_name":"` + category + `",` +
+				`"source_id":"1","destination_id":"30","tags":[` + tags + `]}`
+		}
+		w.Write([]byte(`{"data":[` +
+			`{"type":"transactions","id":"1","attributes":{"transactions":[` +
+			split("11", "withdrawal", "2024-05-01", "100.00", "EUR", "Hotel", `"Rome 2024"`) + `]}},` +
+			`{"type":"transactions","id":"2","attributes":{"transactions":[` +
+			split("21", "withdrawal", "2024-05-02", "20.00", "EUR", "Food", `"rome 2024"`) + `,` +
+			split("22", "withdrawal", "2024-05-02", "5.00", "EUR", "Food", ``) + `]}},` +
+			`{"type":"transactions","id":"3","attributes":{"transactions":[` +
+			split("31", "deposit", "2024-05-03", "30.00", "EUR", "Hotel", `"Rome 2024"`) + `]}},` +
+			`{"type":"transactions","id":"4","attributes":{"transactions":[` +
+			split("41", "transfer", "2024-04-28", "500.00", "EUR", "", `"Rome 2024"`) + `]}},` +
+			`{"type":"transactions","id":"5","attributes":{"transactions":[` +
+			split("51", "withdrawal", "2024-05-02", "12.00", "USD", "", `"Rome 2024"`) + `]}}],` +
+			`"meta":{"pagination":{"total":5,"count":5,"per_page":200,"current_page":1,"total_pages":1}}}`))
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	result, _, err := server.handleTripReport(context.Background(), nil, TripReportArgs{Tags: []string{"Rome 2024"}})
+	require.NoError(t, err)
+	text := result.Content[0].(*mcp.TextContent).Text
+	require.False(t, result.IsError, text)
+	var report TripReport
+	require.NoError(t, json.Unmarshal([]byte(text), &report))
+
+	assert.Equal(t, []string{`tag_is:"Rome 2024"`}, queries)
+	assert.Equal(t, "2024-05-01", report.Start, "without a date range the trip spans its transactions")
+	assert.Equal(t, "2024-05-03", report.End)
+	assert.Equal(t, 3, report.Days)
+	assert.Equal(t, 4, report.TransactionCount, "the untagged split of a tagged group is left out")
+	assert.Equal(t, 1, report.TransfersSkipped)
+
+	require.Len(t, report.Currencies, 2)
+	eur := report.Currencies[0]
+	assert.Equal(t, "EUR", eur.CurrencyCode)
+	assert.Equal(t, "90.00", eur.Cost)
+	assert.Equal(t, "120.00", eur.Expenses)
+	assert.Equal(t, "30.00", eur.Refunds)
+	assert.Equal(t, "30.00", eur.DailyAverage)
+	assert.Equal(t, []ProjectLine{{Name: "Hotel", Amount: "70.00", Count: 2}, {Name: "Food", Amount: "20.00", Count: 1}}, eur.ByCategory)
+	assert.Equal(t, []TripDay{
+		{Date: "2024-05-01", Amount: "100.00", Count: 1},
+		{Date: "2024-05-02", Amount: "20.00", Count: 1},
+		{Date: "2024-05-03", Amount: "-30.00", Count: 1},
+	}, eur.ByDay)
+	assert.Equal(t, "USD", report.Currencies[1].CurrencyCode)
+
+	result, _, err = server.handleTripReport(context.Background(), nil, TripReportArgs{
+		Tags:      []string{"Rome 2024"},
+		Format:    "markdown",
+		DateRange: DateRange{Start: "2024-04-30", End: "2024-05-09"},
+	})
+	require.NoError(t, err)
+	markdown := result.Content[0].(*mcp.TextContent).Text
+	assert.Contains(t, markdown, "# Trip report: Rome 2024\n")
+	assert.Contains(t, markdown, "2024-04-30 to 2024-05-09 (10 days), 4 transactions, 1 transfers left out.")
+	assert.Contains(t, markdown, "| EUR | 90.00 | 120.00 | 30.00 | 9.00 | 3 |")
+	assert.Contains(t, markdown, "## USD by category")
+	assert.Contains(t, queries[1], "date_after:2024-04-30")
+
+	result, _, err = server.handleTripReport(context.Background(), nil, TripReportArgs{})
+	require.NoError(t, err)
+	assert.True(t, result.IsError, "tags or a date range is required")
+}
+
+func TestBuildTripReport_Accounts(t *testing.T) {
+	date := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	transactions := []Transaction{
+		{Type: "withdrawal", Amount: "10", CurrencyCode: "EUR", SourceId: "1", DestinationId: "30", Date: date},
+		{Type: "withdrawal", Amount: "99", CurrencyCode: "EUR", SourceId: "2", DestinationId: "30", Date: date},
+		{Type: "deposit", Amount: "4", CurrencyCode: "EUR", SourceId: "40", DestinationId: "1", Date: date},
+	}
+	report := buildTripReport(nil, []string{"1"}, transactions)
+	assert.Equal(t, 2, report.TransactionCount)
+	require.Len(t, report.Currencies, 1)
+	assert.Equal(t, "6.00", report.Currencies[0].Cost)
+	assert.Equal(t, []ProjectLine{{Name: noCategoryLabel, Amount: "6.00", Count: 2}}, report.Currencies[0].ByCategory)
+}
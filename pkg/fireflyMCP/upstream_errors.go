@@ -0,0 +1,124 @@
+package fireflyMCP
+
+import (
+	"encoding/json"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// defaultErrorBodyLimit is used when no client.error_body_limit is configured
+const defaultErrorBodyLimit = 300
+
+var (
+	htmlBlockPattern = regexp.MustCompile(`(?is)<(script|style|head)[^>]*>.*?</(script|style|head)>`)
+	htmlTagPattern   = regexp.MustCompile(`(?s)<[^>]*>`)
+	htmlTitlePattern = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+)
+
+// upstreamErrorBody holds the fields of a Firefly III JSON error response
+type upstreamErrorBody struct {
+	Message   string              `json:"message"`
+	Exception string              `json:"exception"`
+	Errors    map[string][]string `json:"errors"`
+}
+
+// sanitizeErrorBody turns an upstream error response body into a short message
+// that is safe to include in tool results. JSON error messages (including field
+// validation errors) are extracted, HTML pages are reduced to their text, and the
+// result is truncated to maxLen characters (0 disables truncation).
+func sanitizeErrorBody(body []byte, maxLen int) string {
+	text := strings.TrimSpace(string(body))
+	if text == "" {
+		return ""
+	}
+
+	if message, ok := extractJSONErrorMessage(text); ok {
+		text = message
+	} else if looksLikeHTML(text) {
+		text = stripHTML(text)
+	}
+
+	text = strings.Join(strings.Fields(text), " ")
+	return truncateText(text, maxLen)
+}
+
+// extractJSONErrorMessage extracts the message and validation errors from a JSON error body
+func extractJSONErrorMessage(text string) (string, bool) {
+	var parsed upstreamErrorBody
+	if err := json.Unmarshal([]byte(text), &parsed); err != nil {
+		return "", false
+	}
+	if parsed.Message == "" && len(parsed.Errors) == 0 {
+		return "", false
+	}
+
+	message := parsed.Message
+	if len(parsed.Errors) > 0 {
+		fields := make([]string, 0, len(parsed.Errors))
+		for field := range parsed.Errors {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+
+		details := make([]string, 0, len(fields))
+		for _, field := range fields {
+			details = append(details, field+": "+strings.Join(parsed.Errors[field], ", "))
+		}
+		if message != "" {
+			message += " "
+		}
+		message += "(" + strings.Join(details, "; ") + ")"
+	}
+	return message, true
+}
+
+// looksLikeHTML reports whether the text appears to be an HTML document or fragment
+func looksLikeHTML(text string) bool {
+	lower := strings.ToLower(text)
+	return strings.HasPrefix(lower, "<!doctype html") || strings.Contains(lower, "<html") ||
+		strings.Contains(lower, "<body") || strings.Contains(lower, "</")
+}
+
+// stripHTML reduces an HTML page to its visible text, preferring the page title
+// of full documents (error pages usually put the error there)
+func stripHTML(text string) string {
+	title := ""
+	if match := htmlTitlePattern.FindStringSubmatch(text); match != nil {
+		title = strings.TrimSpace(html.UnescapeString(match[1]))
+	}
+
+	text = htmlBlockPattern.ReplaceAllString(text, " ")
+	text = htmlTagPattern.ReplaceAllString(text, " ")
+	text = strings.TrimSpace(html.UnescapeString(text))
+
+	if title != "" && !strings.HasPrefix(text, title) {
+		return title + ": " + text
+	}
+	return text
+}
+
+// truncateText truncates text to maxLen characters without splitting UTF-8 sequences
+func truncateText(text string, maxLen int) string {
+	if maxLen <= 0 || utf8.RuneCountInString(text) <= maxLen {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:maxLen]) + "..."
+}
+
+// errorBodyLimit returns the configured maximum length of upstream error text
+func (s *FireflyMCPServer) errorBodyLimit() int {
+	config := s.currentConfig()
+	if config == nil || config.Client.ErrorBodyLimit == 0 {
+		return defaultErrorBodyLimit
+	}
+	return config.Client.ErrorBodyLimit
+}
+
+// upstreamError returns the sanitized error text of an upstream response body
+func (s *FireflyMCPServer) upstreamError(body []byte) string {
+	return sanitizeErrorBody(body, s.errorBodyLimit())
+}
@@ -0,0 +1,85 @@
+package fireflyMCP
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeErrorBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		maxLen   int
+		expected string
+	}{
+		{
+			name:     "empty body",
+			body:     "",
+			maxLen:   100,
+			expected: "",
+		},
+		{
+			name:     "json message",
+			body:     `{"message":"Resource not found","exception":"NotFoundHttpException"}`,
+			maxLen:   100,
+			expected: "Resource not found",
+		},
+		{
+			name:     "json validation errors",
+			body:     `{"message":"The given data was invalid.","errors":{"name":["The name has already been taken."],"amount":["Must be positive."]}}`,
+			maxLen:   200,
+			expected: "The given data was invalid. (amount: Must be positive.; name: The name has already been taken.)",
+		},
+		{
+			name:     "json without message is kept",
+			body:     `{"data":[]}`,
+			maxLen:   100,
+			expected: `{"data":[]}`,
+		},
+		{
+			name: "html error page",
+			body: `<!DOCTYPE html><html><head><title>Server Error</title><style>body{color:red}</style></head>` +
+				`<body><h1>500</h1><p>Something &amp; went wrong</p><script>var token="secret";</script></body></html>`,
+			maxLen:   100,
+			expected: "Server Error: 500 Something & went wrong",
+		},
+		{
+			name:     "plain text is truncated",
+			body:     strings.Repeat("a", 50),
+			maxLen:   10,
+			expected: "aaaaaaaaaa...",
+		},
+		{
+			name:     "truncation keeps utf-8 intact",
+			body:     "ошибка сервера",
+			maxLen:   6,
+			expected: "ошибка...",
+		},
+		{
+			name:     "zero limit disables truncation",
+			body:     strings.Repeat("b", 20),
+			maxLen:   0,
+			expected: strings.Repeat("b", 20),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, sanitizeErrorBody([]byte(tt.body), tt.maxLen))
+		})
+	}
+}
+
+func TestUpstreamErrorUsesConfiguredLimit(t *testing.T) {
+	body := []byte(strings.Repeat("x", 500))
+
+	s := &FireflyMCPServer{}
+	assert.Len(t, s.upstreamError(body), defaultErrorBodyLimit+len("..."))
+
+	config := &Config{}
+	config.Client.ErrorBodyLimit = 20
+	s = &FireflyMCPServer{config: config}
+	assert.Equal(t, strings.Repeat("x", 20)+"...", s.upstreamError(body))
+}
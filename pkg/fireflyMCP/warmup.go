@@ -0,0 +1,134 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/dezer32/mcp-firefly-iii/pkg/client"
+)
+
+// Defaults of the warmup section
+const (
+	defaultWarmupDelay    = 10   // Seconds after startup
+	defaultWarmupInterval = 1000 // Milliseconds between two requests
+)
+
+// warmupTool is a tool call that fills the tool result cache, made only when
+// the tool has a cache_ttl
+type warmupTool struct {
+	name string
+	args string
+}
+
+// warmupTools are the calls a session typically starts with
+var warmupTools = []warmupTool{
+	{name: "get_summary", args: `{"period":"this_month"}`},
+	{name: "list_accounts", args: `{}`},
+	{name: "list_categories", args: `{}`},
+	{name: "list_budgets", args: `{"period":"this_month"}`},
+}
+
+// Warmup fills the caches of the configured API token in the background: the
+// name catalogs, the formatting hints, the currencies of asset accounts and
+// the results of common tools with a cache_ttl. Requests are made one at a
+// time, warmup.interval apart, so users are not slowed down. The caches are
+// filled again whenever the name catalogs expire, until ctx is done. Without
+// a configured API token there is nothing to warm up.
+func (s *FireflyMCPServer) Warmup(ctx context.Context) {
+	config := s.currentConfig()
+	if config.API.Token == "" {
+		slog.Info("Skipping cache warmup without a configured API token")
+		return
+	}
+	if !sleepContext(ctx, time.Duration(config.Warmup.Delay)*time.Second) {
+		return
+	}
+	for {
+		started := time.Now()
+		warmed := s.warmupPass(ctx)
+		slog.Info("Caches warmed up", "steps", warmed, "duration", time.Since(started).Round(time.Millisecond))
+
+		// Nothing stays cached without a catalog TTL, so there is nothing to refresh
+		ttl := s.catalogTTL()
+		if ttl <= 0 || !sleepContext(ctx, ttl) {
+			return
+		}
+	}
+}
+
+// warmupPass fills every cache once and returns the names of the steps that succeeded
+func (s *FireflyMCPServer) warmupPass(ctx context.Context) []string {
+	warmed := []string{}
+	apiClient, err := s.getClient(ctx, nil)
+	if err != nil {
+		slog.Warn("Cache warmup failed", "error", err)
+		return warmed
+	}
+	interval := time.Duration(s.currentConfig().Warmup.Interval) * time.Millisecond
+	first := true
+	step := func(name string, warm func() bool) {
+		if ctx.Err() != nil {
+			return
+		}
+		if !first && !sleepContext(ctx, interval) {
+			return
+		}
+		first = false
+		if warm() {
+			warmed = append(warmed, name)
+		}
+	}
+
+	for _, kind := range []catalogKind{catalogAccounts, catalogBudgets, catalogCategories} {
+		step("catalog:"+string(kind), func() bool {
+			_, err := s.catalog(ctx, nil, apiClient, kind)
+			return err == nil
+		})
+	}
+	step("formatting", func() bool {
+		return s.formattingHints(ctx, nil) != nil
+	})
+
+	// Write tools complete split currencies from the asset accounts
+	if accounts, err := s.catalog(ctx, nil, apiClient, catalogAccounts); err == nil {
+		for _, account := range accounts {
+			if account.Type != client.ShortAccountTypePropertyAsset {
+				continue
+			}
+			step("account:"+account.Id, func() bool {
+				_, ok := s.accountMetadata(ctx, nil, apiClient, account.Id)
+				return ok
+			})
+		}
+	}
+
+	for _, call := range warmupTools {
+		override, _ := s.toolOverride(call.name)
+		registered, ok := s.tools[call.name]
+		if override.CacheTTL <= 0 || !ok {
+			continue
+		}
+		step("tool:"+call.name, func() bool {
+			result, err := registered.invoke(ctx, nil, json.RawMessage(call.args))
+			return err == nil && result != nil && !result.IsError
+		})
+	}
+	return warmed
+}
+
+// sleepContext waits for d and reports whether ctx is still active
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,91 @@
+package fireflyMCP
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWarmup(t *testing.T) {
+	var mu sync.Mutex
+	requests := map[string]int{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch r.URL.Path {
+		case "/v1/accounts":
+			w.Write([]byte(`{"data":[` +
+				`{"type":"accounts","id":"1","attributes":{"name":"Checking","type":"asset"}},` +
+				`{"type":"accounts","id":"5","attributes":{"name":"Shop","type":"expense"}}],` +
+				`"meta":{"pagination":{"total":2,"count":2,"per_page":200,"current_page":1,"total_pages":1}}}`))
+		case "/v1/accounts/1":
+			w.Write([]byte(`{"data":{"type":"accounts","id":"1","attributes":{"name":"Checking","type":"asset","currency_code":"EUR"}}}`))
+		case "/v1/summary/basic":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{}`))
+		default:
+			w.Write([]byte(`{"data":[],"meta":{"pagination":{"total":0,"count":0,"per_page":200,"current_page":1,"total_pages":1}}}`))
+		}
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	config.Catalogs.TTL = 300
+	config.Accounts.MetadataTTL = 300
+	config.Warmup.Interval = 1
+	config.Tools = map[string]ToolOverride{"get_summary": {CacheTTL: 60}}
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	warmed := server.warmupPass(ctx)
+	assert.Equal(t, []string{
+		"catalog:accounts", "catalog:budgets", "catalog:categories", "account:1", "tool:get_summary",
+	}, warmed, "formatting hints are disabled and only get_summary has a cache_ttl")
+	assert.Equal(t, 1, requests["/v1/accounts"], "the second lookup is served from the catalog")
+	assert.Equal(t, 1, requests["/v1/accounts/1"])
+	assert.Zero(t, requests["/v1/accounts/5"], "only asset account currencies are needed")
+
+	// The first user queries hit warm caches
+	_, err = server.catalog(ctx, nil, server.client, catalogBudgets)
+	require.NoError(t, err)
+	_, err = server.tools["get_summary"].invoke(ctx, nil, []byte(`{"period":"this_month"}`))
+	require.NoError(t, err)
+	assert.Equal(t, 1, requests["/v1/budgets"])
+	assert.Equal(t, 1, requests["/v1/summary/basic"])
+}
+
+func TestWarmup_StopsWithContext(t *testing.T) {
+	config := newPluginTestConfig()
+	config.Warmup.Delay = 3600
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		server.Warmup(ctx)
+		close(done)
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Warmup did not stop")
+	}
+
+	config.API.Token = ""
+	config.HTTP.Enabled = true
+	server, err = NewFireflyMCPServer(config)
+	require.NoError(t, err)
+	server.Warmup(context.Background()) // Returns at once without a token
+}
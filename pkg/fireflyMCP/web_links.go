@@ -0,0 +1,127 @@
+package fireflyMCP
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Firefly III web UI pages of the entities tools return
+const (
+	webPathAccount     = "/accounts/show/"
+	webPathTransaction = "/transactions/show/"
+	webPathBudget      = "/budgets/show/"
+	webPathCategory    = "/categories/show/"
+	webPathTag         = "/tags/show/"
+	webPathBill        = "/bills/show/"
+	webPathRecurrence  = "/recurring/show/"
+	webPathRule        = "/rules/edit/" // Rules and rule groups have no page of their own
+	webPathRuleGroup   = "/rule-groups/edit/"
+)
+
+// webLinkTools maps tools to the web UI page of the entities they return, either
+// as the result itself or as the items of its data list
+var webLinkTools = map[string]string{
+	"list_accounts":                webPathAccount,
+	"get_account":                  webPathAccount,
+	"search_accounts":              webPathAccount,
+	"list_transactions":            webPathTransaction,
+	"get_transaction":              webPathTransaction,
+	"search_transactions":          webPathTransaction,
+	"store_transaction":            webPathTransaction,
+	"update_transaction":           webPathTransaction,
+	"list_budget_transactions":     webPathTransaction,
+	"list_bill_transactions":       webPathTransaction,
+	"list_recurrence_transactions": webPathTransaction,
+	"list_budgets":                 webPathBudget,
+	"store_budget":                 webPathBudget,
+	"update_budget":                webPathBudget,
+	"list_categories":              webPathCategory,
+	"list_tags":                    webPathTag,
+	"list_bills":                   webPathBill,
+	"get_bill":                     webPathBill,
+	"list_recurrences":             webPathRecurrence,
+	"get_recurrence":               webPathRecurrence,
+	"list_rules":                   webPathRule,
+	"list_rules_by_group":          webPathRule,
+	"get_rule":                     webPathRule,
+	"create_rule":                  webPathRule,
+	"update_rule":                  webPathRule,
+	"list_rule_groups":             webPathRuleGroup,
+	"get_rule_group":               webPathRuleGroup,
+	"create_rule_group":            webPathRuleGroup,
+	"update_rule_group":            webPathRuleGroup,
+}
+
+// webBaseURL returns the URL of the Firefly III web UI: the configured API URL
+// without its /api suffix. Empty when web links are disabled.
+func (s *FireflyMCPServer) webBaseURL() string {
+	config := s.currentConfig()
+	if config == nil || !config.Formatting.WebLinks || config.Server.URL == "" {
+		return ""
+	}
+	return strings.TrimSuffix(strings.TrimRight(config.Server.URL, "/"), "/api")
+}
+
+// withWebLinks adds the web_url of the returned entities to the results of a
+// tool when formatting.web_links is on, so the user can open them in Firefly III
+func withWebLinks[In any](s *FireflyMCPServer, name string, handler mcp.ToolHandlerFor[In, any]) mcp.ToolHandlerFor[In, any] {
+	page, ok := webLinkTools[name]
+	if !ok {
+		return handler
+	}
+	return func(ctx context.Context, req *mcp.CallToolRequest, args In) (*mcp.CallToolResult, any, error) {
+		result, out, err := handler(ctx, req, args)
+		base := s.webBaseURL()
+		if err != nil || result == nil || result.IsError || base == "" {
+			return result, out, err
+		}
+		for _, content := range result.Content {
+			if text, ok := content.(*mcp.TextContent); ok {
+				text.Text = webLinksJSON(text.Text, base+page)
+			}
+		}
+		return result, out, err
+	}
+}
+
+// webLinksJSON sets web_url on the entity of a JSON document, or on the items
+// of its data list. Text that is not a JSON object is returned unchanged.
+func webLinksJSON(text, page string) string {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(text)))
+	decoder.UseNumber()
+	var document map[string]any
+	if err := decoder.Decode(&document); err != nil {
+		return text
+	}
+	changed := setWebURL(document, page)
+	if items, ok := document["data"].([]any); ok {
+		for _, item := range items {
+			if entity, ok := item.(map[string]any); ok {
+				changed = setWebURL(entity, page) || changed
+			}
+		}
+	}
+	if !changed {
+		return text
+	}
+	encoded, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return text
+	}
+	return string(encoded)
+}
+
+// setWebURL sets web_url of an entity with a string ID
+func setWebURL(entity map[string]any, page string) bool {
+	id, ok := entity["id"].(string)
+	if !ok || id == "" {
+		return false
+	}
+	entity["web_url"] = page + url.PathEscape(id)
+	return true
+}
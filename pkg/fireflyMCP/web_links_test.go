@@ -0,0 +1,57 @@
+package fireflyMCP
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebLinksJSON(t *testing.T) {
+	page := "https://firefly.example.com/transactions/show/"
+	assert.JSONEq(t,
+		`{"id":"12","group_title":"","web_url":"https://firefly.example.com/transactions/show/12"}`,
+		webLinksJSON(`{"id":"12","group_title":""}`, page))
+	assert.JSONEq(t,
+		`{"data":[{"id":"1","web_url":"https://firefly.example.com/transactions/show/1"},{"name":"no id"}],"pagination":{"total":2}}`,
+		webLinksJSON(`{"data":[{"id":"1"},{"name":"no id"}],"pagination":{"total":2}}`, page))
+	assert.Equal(t, `[{"id":"1"}]`, webLinksJSON(`[{"id":"1"}]`, page), "only objects and their data lists are linked")
+	assert.Equal(t, "not json", webLinksJSON("not json", page))
+}
+
+func TestWebBaseURL(t *testing.T) {
+	config := newPluginTestConfig()
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	assert.Empty(t, server.webBaseURL(), "off unless formatting.web_links is set")
+	config.Formatting.WebLinks = true
+	assert.Equal(t, "https://firefly.example.com", server.webBaseURL())
+	config.Server.URL = "https://example.com/firefly/api"
+	assert.Equal(t, "https://example.com/firefly", server.webBaseURL(), "subdirectory installs keep their path")
+}
+
+func TestWebLinks_Tools(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		w.Write([]byte(`{"data":[{"type":"accounts","id":"7","attributes":{"name":"Checking","type":"asset"}}],` +
+			`"meta":{"pagination":{"total":1,"count":1,"per_page":50,"current_page":1,"total_pages":1}}}`))
+	}))
+	defer ts.Close()
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL + "/api"
+	config.Formatting.WebLinks = true
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	result, err := server.tools["list_accounts"].invoke(context.Background(), nil, []byte(`{}`))
+	require.NoError(t, err)
+	text := result.Content[0].(*mcp.TextContent).Text
+	require.False(t, result.IsError, text)
+	assert.Contains(t, text, `"web_url": "`+ts.URL+`/accounts/show/7"`)
+}
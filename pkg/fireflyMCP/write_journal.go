@@ -0,0 +1,500 @@
+package fireflyMCP
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Operations recorded in the write journal
+const (
+	journalOpStart   = "start"   // A batch was accepted, with all its transaction groups
+	journalOpPending = "pending" // A group is about to be sent to Firefly III
+	journalOpDone    = "done"    // Firefly III answered for a group
+	journalOpFinish  = "finish"  // The batch ended or was discarded
+)
+
+// Statuses reported by resume_batch
+const (
+	JournalStatusCompleted  = "completed"
+	JournalStatusIncomplete = "incomplete"
+	JournalStatusDiscarded  = "discarded"
+)
+
+// resumeDelay is the pause between two groups stored by resume_batch
+const resumeDelay = 100 * time.Millisecond
+
+// journalRecord is one line of the write journal
+type journalRecord struct {
+	Batch         string                    `json:"batch"`
+	Op            string                    `json:"op"`
+	At            string                    `json:"at"`
+	Owner         string                    `json:"owner,omitempty"` // SHA-256 of the API token, never the token itself
+	Tool          string                    `json:"tool,omitempty"`
+	Groups        []TransactionStoreRequest `json:"groups,omitempty"`
+	Index         int                       `json:"index,omitempty"`
+	Success       bool                      `json:"success,omitempty"`
+	TransactionId string                    `json:"transaction_id,omitempty"`
+	Error         string                    `json:"error,omitempty"`
+}
+
+// JournalItem is the outcome of one transaction group of an interrupted batch
+type JournalItem struct {
+	Index         int    `json:"index"`
+	TransactionId string `json:"transaction_id,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// JournalBatch is a bulk store that did not finish before the server stopped
+type JournalBatch struct {
+	ID        string        `json:"id"`
+	Tool      string        `json:"tool"`
+	StartedAt string        `json:"started_at"`
+	Total     int           `json:"total"`
+	Committed []JournalItem `json:"committed"` // Stored in Firefly III
+	Failed    []JournalItem `json:"failed"`    // Rejected by Firefly III
+	Uncertain []int         `json:"uncertain"` // Sent, but the server stopped before the answer was recorded
+	Remaining []int         `json:"remaining"` // Never sent
+}
+
+// ListInterruptedBatchesArgs represents the arguments for the list_interrupted_batches tool
+type ListInterruptedBatchesArgs struct{}
+
+// InterruptedBatchList is the result of list_interrupted_batches
+type InterruptedBatchList struct {
+	Batches []JournalBatch `json:"batches"`
+	Note    string         `json:"note"`
+}
+
+// ResumeBatchArgs represents the arguments for the resume_batch tool
+type ResumeBatchArgs struct {
+	BatchId        string `json:"batch_id" jsonschema:"ID of the interrupted batch (required)"`
+	RetryUncertain bool   `json:"retry_uncertain,omitempty" jsonschema:"Also send the uncertain groups again. Only set after checking in Firefly III that they were not stored"`
+	Discard        bool   `json:"discard,omitempty" jsonschema:"Forget the batch without storing anything"`
+}
+
+// ResumeBatchResult is the result of resume_batch
+type ResumeBatchResult struct {
+	Status  string                   `json:"status"`
+	Results []TransactionGroupResult `json:"results"`
+	Batch   JournalBatch             `json:"batch"`
+}
+
+// journalBatch is the state of an unfinished batch rebuilt from the journal
+type journalBatch struct {
+	records  []journalRecord // Records of the batch in journal order, kept on compaction
+	owner    string
+	tool     string
+	started  string
+	groups   []TransactionStoreRequest
+	sent     map[int]bool
+	outcomes map[int]TransactionGroupResult
+}
+
+// apply updates the batch with one of its journal records
+func (b *journalBatch) apply(record journalRecord) {
+	b.records = append(b.records, record)
+	switch record.Op {
+	case journalOpPending:
+		b.sent[record.Index] = true
+	case journalOpDone:
+		result := TransactionGroupResult{Index: record.Index, Success: record.Success, Error: record.Error}
+		if record.TransactionId != "" {
+			result.TransactionGroup = &TransactionGroup{Id: record.TransactionId}
+		}
+		b.record(result)
+	}
+}
+
+// record stores the outcome of a group
+func (b *journalBatch) record(result TransactionGroupResult) {
+	b.sent[result.Index] = true
+	b.outcomes[result.Index] = result
+}
+
+// report summarizes which groups of the batch were committed, failed, possibly
+// sent or never sent
+func (b *journalBatch) report(id string) JournalBatch {
+	report := JournalBatch{
+		ID:        id,
+		Tool:      b.tool,
+		StartedAt: b.started,
+		Total:     len(b.groups),
+		Committed: []JournalItem{},
+		Failed:    []JournalItem{},
+		Uncertain: []int{},
+		Remaining: []int{},
+	}
+	for index := range b.groups {
+		result, done := b.outcomes[index]
+		switch {
+		case done && result.Success:
+			item := JournalItem{Index: index}
+			if result.TransactionGroup != nil {
+				item.TransactionId = result.TransactionGroup.Id
+			}
+			report.Committed = append(report.Committed, item)
+		case done:
+			report.Failed = append(report.Failed, JournalItem{Index: index, Error: result.Error})
+		case b.sent[index]:
+			report.Uncertain = append(report.Uncertain, index)
+		default:
+			report.Remaining = append(report.Remaining, index)
+		}
+	}
+	return report
+}
+
+// writeJournal records bulk stores before and after every group is sent to
+// Firefly III, so that a batch interrupted by a restart can be reported and
+// resumed. Records go to an append-only file (journal.path) or to the storage
+// backend. A nil journal records nothing.
+type writeJournal struct {
+	mu          sync.Mutex
+	sink        journalSink
+	interrupted map[string]*journalBatch // Batches left unfinished by a previous run, by ID
+}
+
+// journalSink is where the records of the write journal are kept
+type journalSink interface {
+	write(line []byte) error // Must be durable when it returns
+	close() error
+}
+
+// fileJournalSink appends records as lines to a file
+type fileJournalSink struct {
+	file *os.File
+}
+
+func (f *fileJournalSink) write(line []byte) error {
+	if _, err := f.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return f.file.Sync()
+}
+
+func (f *fileJournalSink) close() error {
+	return f.file.Close()
+}
+
+// storageJournalSink puts records in the journal bucket of a storage, keyed by
+// a zero-padded sequence number so they list in the order they were written
+type storageJournalSink struct {
+	storage Storage
+	next    uint64
+}
+
+func (st *storageJournalSink) write(line []byte) error {
+	st.next++
+	return st.storage.Put(storageBucketJournal, fmt.Sprintf("%020d", st.next), line)
+}
+
+func (st *storageJournalSink) close() error {
+	return nil
+}
+
+// replayJournal rebuilds the unfinished batches from journal records in the
+// order they were written. It also returns the IDs of the batches in the order
+// they started.
+func replayJournal(lines [][]byte) (map[string]*journalBatch, []string) {
+	batches := make(map[string]*journalBatch)
+	var order []string
+	for _, line := range lines {
+		var record journalRecord
+		// A torn last line, written when the server stopped, is skipped
+		if len(bytes.TrimSpace(line)) == 0 || json.Unmarshal(line, &record) != nil {
+			continue
+		}
+		if record.Op == journalOpStart {
+			batches[record.Batch] = &journalBatch{
+				owner:    record.Owner,
+				tool:     record.Tool,
+				started:  record.At,
+				groups:   record.Groups,
+				sent:     make(map[int]bool),
+				outcomes: make(map[int]TransactionGroupResult),
+			}
+			order = append(order, record.Batch)
+		}
+		batch, ok := batches[record.Batch]
+		if !ok {
+			continue
+		}
+		if record.Op == journalOpFinish {
+			delete(batches, record.Batch)
+			continue
+		}
+		batch.apply(record)
+	}
+	return batches, order
+}
+
+// openWriteJournal replays the journal at path, keeps only the records of
+// unfinished batches and opens it for appending
+func openWriteJournal(path string) (*writeJournal, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	batches, order := replayJournal(bytes.Split(data, []byte("\n")))
+
+	// Compact the journal: finished batches are dropped
+	var compacted bytes.Buffer
+	for _, id := range order {
+		if batch, ok := batches[id]; ok {
+			for _, record := range batch.records {
+				line, err := json.Marshal(record)
+				if err != nil {
+					return nil, err
+				}
+				compacted.Write(append(line, '\n'))
+			}
+		}
+	}
+	if err := os.WriteFile(path+".tmp", compacted.Bytes(), 0o600); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &writeJournal{sink: &fileJournalSink{file: file}, interrupted: batches}, nil
+}
+
+// openStorageJournal replays the journal kept in a storage and deletes the
+// records of finished batches
+func openStorageJournal(storage Storage) (*writeJournal, error) {
+	items, err := storage.List(storageBucketJournal)
+	if err != nil {
+		return nil, err
+	}
+	lines := make([][]byte, len(items))
+	for i, item := range items {
+		lines[i] = item.Value
+	}
+	batches, _ := replayJournal(lines)
+
+	sink := &storageJournalSink{storage: storage}
+	for i, item := range items {
+		var record journalRecord
+		if json.Unmarshal(item.Value, &record) != nil || batches[record.Batch] == nil {
+			if err := storage.Delete(storageBucketJournal, item.Key); err != nil {
+				return nil, err
+			}
+		}
+		if i == len(items)-1 {
+			fmt.Sscanf(item.Key, "%d", &sink.next)
+		}
+	}
+	return &writeJournal{sink: sink, interrupted: batches}, nil
+}
+
+// close closes the journal
+func (j *writeJournal) close() error {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.sink.close()
+}
+
+// append writes a record and makes it durable before returning
+func (j *writeJournal) append(record journalRecord) error {
+	record.At = time.Now().UTC().Format(time.RFC3339)
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.sink.write(line)
+}
+
+// begin records a new batch with all of its groups
+func (j *writeJournal) begin(id, owner, tool string, groups []TransactionStoreRequest) error {
+	if j == nil {
+		return nil
+	}
+	return j.append(journalRecord{Batch: id, Op: journalOpStart, Owner: owner, Tool: tool, Groups: groups})
+}
+
+// pending records that a group is about to be sent
+func (j *writeJournal) pending(id string, index int) error {
+	if j == nil {
+		return nil
+	}
+	return j.append(journalRecord{Batch: id, Op: journalOpPending, Index: index})
+}
+
+// done records the outcome of a group
+func (j *writeJournal) done(id string, result TransactionGroupResult) error {
+	if j == nil {
+		return nil
+	}
+	record := journalRecord{Batch: id, Op: journalOpDone, Index: result.Index, Success: result.Success, Error: result.Error}
+	if result.TransactionGroup != nil {
+		record.TransactionId = result.TransactionGroup.Id
+	}
+	return j.append(record)
+}
+
+// finish records that a batch ended, so it is not reported after a restart
+func (j *writeJournal) finish(id string) error {
+	if j == nil {
+		return nil
+	}
+	return j.append(journalRecord{Batch: id, Op: journalOpFinish})
+}
+
+// interruptedBatches returns the unfinished batches of an owner, oldest first
+func (j *writeJournal) interruptedBatches(owner string) []JournalBatch {
+	batches := []JournalBatch{}
+	if j == nil {
+		return batches
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for id, batch := range j.interrupted {
+		if batch.owner == owner {
+			batches = append(batches, batch.report(id))
+		}
+	}
+	sort.Slice(batches, func(a, b int) bool {
+		if batches[a].StartedAt != batches[b].StartedAt {
+			return batches[a].StartedAt < batches[b].StartedAt
+		}
+		return batches[a].ID < batches[b].ID
+	})
+	return batches
+}
+
+// take removes an unfinished batch of an owner, so it is resumed only once at a time
+func (j *writeJournal) take(owner, id string) (*journalBatch, bool) {
+	if j == nil {
+		return nil, false
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	batch, ok := j.interrupted[id]
+	if !ok || batch.owner != owner {
+		return nil, false
+	}
+	delete(j.interrupted, id)
+	return batch, true
+}
+
+// putBack returns a batch that is still unfinished after resume_batch
+func (j *writeJournal) putBack(id string, batch *journalBatch) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.interrupted[id] = batch
+}
+
+// storeJournaledGroup stores a group of a batch, recording in the journal that
+// it is about to be sent before sending it, and its outcome afterwards
+func (s *FireflyMCPServer) storeJournaledGroup(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	batch string,
+	index int,
+	group TransactionStoreRequest,
+) TransactionGroupResult {
+	if err := s.journal.pending(batch, index); err != nil {
+		return TransactionGroupResult{Index: index, Error: fmt.Sprintf("Not stored: failed to write journal: %v", err)}
+	}
+	result := s.storeTransactionGroup(ctx, req, index, group)
+	if err := s.journal.done(batch, result); err != nil {
+		slog.Warn("Failed to record a stored transaction group in the journal",
+			"batch", batch, "index", index, "error", err)
+	}
+	return result
+}
+
+// finishJournaledBatch records the end of a batch
+func (s *FireflyMCPServer) finishJournaledBatch(batch string) {
+	if err := s.journal.finish(batch); err != nil {
+		slog.Warn("Failed to record the end of a batch in the journal", "batch", batch, "error", err)
+	}
+}
+
+// handleListInterruptedBatches reports the bulk stores a restart interrupted
+func (s *FireflyMCPServer) handleListInterruptedBatches(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args ListInterruptedBatchesArgs,
+) (*mcp.CallToolResult, any, error) {
+	return newSuccessResult(&InterruptedBatchList{
+		Batches: s.journal.interruptedBatches(s.tenantKey(req)),
+		Note: "Uncertain groups were sent to Firefly III but the server stopped before the answer was recorded; " +
+			"check whether they exist before resuming with retry_uncertain",
+	})
+}
+
+// handleResumeBatch stores the groups of an interrupted batch that were never
+// sent, or discards the batch
+func (s *FireflyMCPServer) handleResumeBatch(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args ResumeBatchArgs,
+) (*mcp.CallToolResult, any, error) {
+	if args.BatchId == "" {
+		return newErrorResult("Error: batch_id is required")
+	}
+	batch, ok := s.journal.take(s.tenantKey(req), args.BatchId)
+	if !ok {
+		return newErrorResult(fmt.Sprintf("Error: no interrupted batch with ID %s", args.BatchId))
+	}
+
+	if args.Discard {
+		s.finishJournaledBatch(args.BatchId)
+		return newSuccessResult(&ResumeBatchResult{
+			Status:  JournalStatusDiscarded,
+			Results: []TransactionGroupResult{},
+			Batch:   batch.report(args.BatchId),
+		})
+	}
+
+	before := batch.report(args.BatchId)
+	indexes := before.Remaining
+	if args.RetryUncertain {
+		indexes = append(indexes, before.Uncertain...)
+		sort.Ints(indexes)
+	}
+
+	results := make([]TransactionGroupResult, 0, len(indexes))
+	for i, index := range indexes {
+		if ctx.Err() != nil {
+			break
+		}
+		if i > 0 {
+			time.Sleep(resumeDelay)
+		}
+		result := s.storeJournaledGroup(ctx, req, args.BatchId, index, batch.groups[index])
+		batch.record(result)
+		results = append(results, result)
+	}
+
+	after := batch.report(args.BatchId)
+	status := JournalStatusCompleted
+	if len(after.Remaining) > 0 || len(after.Uncertain) > 0 {
+		status = JournalStatusIncomplete
+		s.journal.putBack(args.BatchId, batch)
+	} else {
+		s.finishJournaledBatch(args.BatchId)
+	}
+	return newSuccessResult(&ResumeBatchResult{Status: status, Results: results, Batch: after})
+}
@@ -0,0 +1,139 @@
+package fireflyMCP
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func journalTestGroups(n int) []TransactionStoreRequest {
+	groups := make([]TransactionStoreRequest, n)
+	for i := range groups {
+		groups[i] = TransactionStoreRequest{
+			Transactions: []TransactionSplitRequest{
+				{Type: "withdrawal", Date: "2024-01-15", Amount: "10.00", Description: "Coffee"},
+			},
+		}
+	}
+	return groups
+}
+
+func TestWriteJournal_ReplaysUnfinishedBatches(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	owner := tenantKeyFor("https://firefly.example.com", "")
+
+	journal, err := openWriteJournal(path)
+	require.NoError(t, err)
+	require.NoError(t, journal.begin("finished", owner, "store_transactions_bulk", journalTestGroups(1)))
+	require.NoError(t, journal.pending("finished", 0))
+	require.NoError(t, journal.done("finished", TransactionGroupResult{Index: 0, Success: true}))
+	require.NoError(t, journal.finish("finished"))
+
+	require.NoError(t, journal.begin("interrupted", owner, "store_transactions_bulk", journalTestGroups(4)))
+	require.NoError(t, journal.pending("interrupted", 0))
+	require.NoError(t, journal.done("interrupted", TransactionGroupResult{
+		Index: 0, Success: true, TransactionGroup: &TransactionGroup{Id: "42"},
+	}))
+	require.NoError(t, journal.pending("interrupted", 1))
+	require.NoError(t, journal.done("interrupted", TransactionGroupResult{Index: 1, Error: "invalid"}))
+	require.NoError(t, journal.pending("interrupted", 2))
+	require.NoError(t, journal.close())
+
+	// A record torn by the restart is skipped
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"batch":"interrupted","op":"do`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	journal, err = openWriteJournal(path)
+	require.NoError(t, err)
+	defer journal.close()
+
+	batches := journal.interruptedBatches(owner)
+	require.Len(t, batches, 1)
+	batch := batches[0]
+	assert.Equal(t, "interrupted", batch.ID)
+	assert.Equal(t, 4, batch.Total)
+	assert.Equal(t, []JournalItem{{Index: 0, TransactionId: "42"}}, batch.Committed)
+	assert.Equal(t, []JournalItem{{Index: 1, Error: "invalid"}}, batch.Failed)
+	assert.Equal(t, []int{2}, batch.Uncertain)
+	assert.Equal(t, []int{3}, batch.Remaining)
+
+	assert.Empty(t, journal.interruptedBatches(tenantKeyFor("https://firefly.example.com", "other-token")))
+
+	// Finished batches and torn records are compacted away
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), `"finished"`)
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		assert.True(t, json.Valid([]byte(line)), line)
+	}
+}
+
+func TestResumeBatch(t *testing.T) {
+	var stored atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stored.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"message":"The given data was invalid."}`))
+	}))
+	defer ts.Close()
+
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	journal, err := openWriteJournal(path)
+	require.NoError(t, err)
+	require.NoError(t, journal.begin("batch", tenantKeyFor(ts.URL, ""), "store_transactions_bulk", journalTestGroups(3)))
+	require.NoError(t, journal.pending("batch", 0))
+	require.NoError(t, journal.close())
+
+	config := newPluginTestConfig()
+	config.Server.URL = ts.URL
+	config.Journal.Path = path
+	server, err := NewFireflyMCPServer(config)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	result, _, err := server.handleResumeBatch(ctx, nil, ResumeBatchArgs{BatchId: "batch"})
+	require.NoError(t, err)
+	var resumed ResumeBatchResult
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &resumed))
+	assert.Equal(t, JournalStatusIncomplete, resumed.Status)
+	assert.Len(t, resumed.Results, 2)
+	assert.Len(t, resumed.Batch.Failed, 2)
+	assert.Equal(t, []int{0}, resumed.Batch.Uncertain)
+	assert.Equal(t, int32(2), stored.Load())
+
+	result, _, err = server.handleResumeBatch(ctx, nil, ResumeBatchArgs{BatchId: "batch", Discard: true})
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &resumed))
+	assert.Equal(t, JournalStatusDiscarded, resumed.Status)
+	assert.Equal(t, int32(2), stored.Load())
+
+	result, _, err = server.handleResumeBatch(ctx, nil, ResumeBatchArgs{BatchId: "batch"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	// Batches stored through store_transactions_bulk are finished in the journal
+	_, _, err = server.handleStoreTransactionsBulk(ctx, nil, BulkTransactionStoreRequest{
+		TransactionGroups: journalTestGroups(1),
+		DelayMs:           1,
+	})
+	require.NoError(t, err)
+	require.NoError(t, server.journal.close())
+	reopened, err := openWriteJournal(path)
+	require.NoError(t, err)
+	defer reopened.close()
+	assert.Empty(t, reopened.interruptedBatches(server.tenantKey(nil)))
+}
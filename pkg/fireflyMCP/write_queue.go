@@ -0,0 +1,64 @@
+package fireflyMCP
+
+import (
+	"net/http"
+	"time"
+)
+
+// defaultWriteInterval is used when no client.write_interval is configured (milliseconds)
+const defaultWriteInterval = 100
+
+// writeQueueTransport sends write requests one at a time, with a minimum pause
+// between them. Firefly III instances backed by SQLite fail with HTTP 500 when
+// writes arrive in parallel. Read requests are passed through unqueued.
+type writeQueueTransport struct {
+	base     http.RoundTripper
+	interval time.Duration
+
+	slot      chan struct{} // Holds a token for the duration of a write
+	lastWrite time.Time
+}
+
+// newWriteQueueTransport returns a transport sending the writes of base one at
+// a time, at least interval apart
+func newWriteQueueTransport(base http.RoundTripper, interval time.Duration) *writeQueueTransport {
+	return &writeQueueTransport{base: base, interval: interval, slot: make(chan struct{}, 1)}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *writeQueueTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isWriteRequest(req) {
+		return t.base.RoundTrip(req)
+	}
+
+	// Waiting for the previous write gives up when the request is cancelled
+	select {
+	case t.slot <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	defer func() { <-t.slot }()
+
+	if wait := time.Until(t.lastWrite.Add(t.interval)); wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	t.lastWrite = time.Now()
+	return resp, err
+}
+
+// isWriteRequest reports whether a request may modify data in Firefly III
+func isWriteRequest(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,122 @@
+package fireflyMCP
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// concurrencyRecorder is a test server handler recording the maximum number of
+// requests handled at the same time
+type concurrencyRecorder struct {
+	active atomic.Int32
+	max    atomic.Int32
+}
+
+func (c *concurrencyRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	active := c.active.Add(1)
+	defer c.active.Add(-1)
+	for {
+		max := c.max.Load()
+		if active <= max || c.max.CompareAndSwap(max, active) {
+			break
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	w.WriteHeader(http.StatusOK)
+}
+
+func sendConcurrently(t *testing.T, httpClient *http.Client, method, url string, n int) {
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequest(method, url, nil)
+			resp, err := httpClient.Do(req)
+			if assert.NoError(t, err) {
+				resp.Body.Close()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestWriteQueueTransport_SerializesWrites(t *testing.T) {
+	recorder := &concurrencyRecorder{}
+	ts := httptest.NewServer(recorder)
+	defer ts.Close()
+
+	httpClient := &http.Client{Transport: newWriteQueueTransport(http.DefaultTransport, 0)}
+	sendConcurrently(t, httpClient, http.MethodPost, ts.URL, 5)
+	assert.Equal(t, int32(1), recorder.max.Load())
+}
+
+func TestWriteQueueTransport_ReadsStayParallel(t *testing.T) {
+	recorder := &concurrencyRecorder{}
+	ts := httptest.NewServer(recorder)
+	defer ts.Close()
+
+	httpClient := &http.Client{Transport: newWriteQueueTransport(http.DefaultTransport, 0)}
+	sendConcurrently(t, httpClient, http.MethodGet, ts.URL, 5)
+	assert.Greater(t, recorder.max.Load(), int32(1))
+}
+
+func TestWriteQueueTransport_Pacing(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	httpClient := &http.Client{
+		Transport: newWriteQueueTransport(http.DefaultTransport, 50*time.Millisecond),
+	}
+	start := time.Now()
+	sendConcurrently(t, httpClient, http.MethodPut, ts.URL, 3)
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+}
+
+func TestWriteQueueTransport_CancelledWhileWaiting(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	transport := newWriteQueueTransport(http.DefaultTransport, time.Hour)
+	transport.lastWrite = time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, ts.URL, nil)
+	require.NoError(t, err)
+	_, err = transport.RoundTrip(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestWriteQueueTransport_CancelledWhileQueued(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	transport := newWriteQueueTransport(http.DefaultTransport, 0)
+	go func() {
+		req, _ := http.NewRequest(http.MethodPost, ts.URL, nil)
+		if resp, err := transport.RoundTrip(req); err == nil {
+			resp.Body.Close()
+		}
+	}()
+	require.Eventually(t, func() bool { return len(transport.slot) == 1 }, time.Second, time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ts.URL, nil)
+	require.NoError(t, err)
+	_, err = transport.RoundTrip(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "a queued write gives up when its request is cancelled")
+}
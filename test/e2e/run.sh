@@ -0,0 +1,52 @@
+#!/usr/bin/env bash
+# Runs the integration tests against a fresh Firefly III in containers:
+# starts Firefly III and its database, creates a user with a personal access
+# token, and runs the tests with FIREFLY_E2E=1, which seeds fixtures through
+# the API and turns logged failures into test failures.
+#
+# FIREFLY_E2E_PORT  host port of Firefly III (default: 8089)
+# FIREFLY_E2E_KEEP  keep the containers running after the tests when set
+# FIREFLY_E2E_RUN   tests to run (default: TestIntegration)
+set -euo pipefail
+
+cd "$(dirname "$0")"
+port="${FIREFLY_E2E_PORT:-8089}"
+compose=(docker compose -p firefly-mcp-e2e -f docker-compose.yml)
+
+cleanup() {
+	if [ -z "${FIREFLY_E2E_KEEP:-}" ]; then
+		"${compose[@]}" down -v >/dev/null 2>&1 || true
+	fi
+}
+trap cleanup EXIT
+
+"${compose[@]}" up -d
+
+echo "Waiting for Firefly III on port ${port}..."
+for attempt in $(seq 1 90); do
+	if curl -fsS "http://localhost:${port}/health" >/dev/null 2>&1; then
+		break
+	fi
+	if [ "${attempt}" -eq 90 ]; then
+		echo "Firefly III did not become healthy" >&2
+		"${compose[@]}" logs firefly >&2
+		exit 1
+	fi
+	sleep 2
+done
+
+# The first user owns the instance; its token is used by the tests
+"${compose[@]}" exec -T firefly php artisan firefly-iii:create-first-user e2e@example.com >/dev/null
+"${compose[@]}" exec -T firefly php artisan passport:client --personal --name=e2e --no-interaction >/dev/null
+token="$("${compose[@]}" exec -T firefly php artisan tinker \
+	--execute="echo FireflyIII\\User::first()->createToken('e2e')->accessToken;" | tail -n 1 | tr -d '\r')"
+if [ -z "${token}" ]; then
+	echo "Could not create an API token" >&2
+	exit 1
+fi
+
+cd ../..
+FIREFLY_E2E=1 \
+	FIREFLY_MCP_SERVER_URL="http://localhost:${port}/api" \
+	FIREFLY_MCP_API_TOKEN="${token}" \
+	go test -count=1 -v -timeout 10m -run "${FIREFLY_E2E_RUN:-TestIntegration}" ./pkg/fireflyMCP